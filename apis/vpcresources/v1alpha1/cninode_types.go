@@ -23,6 +23,7 @@ type FeatureName string
 const (
 	SecurityGroupsForPods FeatureName = "SecurityGroupsForPods"
 	CustomNetworking      FeatureName = "CustomNetworking"
+	IPv6PrefixDelegation  FeatureName = "IPv6PrefixDelegation"
 )
 
 // Feature is a type of feature being supported by VPC resource controller and other AWS Services
@@ -31,21 +32,208 @@ type Feature struct {
 	Value string      `json:"value,omitempty"`
 }
 
+// SubnetSelectionStrategy is the strategy used to select the subnet of a new branch network interface from the
+// candidate subnets
+type SubnetSelectionStrategy string
+
+const (
+	// SubnetSelectionStrategyMostFreeIPs selects the candidate subnet with the most available IPv4 addresses
+	SubnetSelectionStrategyMostFreeIPs SubnetSelectionStrategy = "MostFreeIPs"
+	// SubnetSelectionStrategyRoundRobin selects the candidate subnets in turn
+	SubnetSelectionStrategyRoundRobin SubnetSelectionStrategy = "RoundRobin"
+)
+
+// BranchENISubnets defines the candidate subnets for the branch network interfaces of the node. The candidate subnets
+// must be in the VPC and availability zone of the node, other subnets are ignored
+type BranchENISubnets struct {
+	// SubnetIDs is the list of candidate subnet IDs
+	// +optional
+	SubnetIDs []string `json:"subnetIDs,omitempty"`
+	// SubnetTags selects the candidate subnets having all the tag key/value, if SubnetIDs is also set only the
+	// listed subnets having the tags are candidates
+	// +optional
+	SubnetTags map[string]string `json:"subnetTags,omitempty"`
+	// Strategy is the strategy used to select the subnet of a new branch network interface
+	// +kubebuilder:validation:Enum=MostFreeIPs;RoundRobin
+	// +kubebuilder:default=MostFreeIPs
+	// +optional
+	Strategy SubnetSelectionStrategy `json:"strategy,omitempty"`
+}
+
 // Important: Run "make" to regenerate code after modifying this file
 // CNINodeSpec defines the desired state of CNINode
 type CNINodeSpec struct {
 	Features []Feature `json:"features,omitempty"`
 	// Additional tag key/value added to all network interfaces provisioned by the vpc-resource-controller and VPC-CNI
 	Tags map[string]string `json:"tags,omitempty"`
+	// WarmIPTarget overrides the cluster wide warm IP target from the amazon-vpc-cni ConfigMap for this node
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	WarmIPTarget *int `json:"warmIPTarget,omitempty"`
+	// MinIPTarget overrides the cluster wide minimum IP target from the amazon-vpc-cni ConfigMap for this node
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinIPTarget *int `json:"minIPTarget,omitempty"`
+	// WarmPrefixTarget overrides the cluster wide warm prefix target from the amazon-vpc-cni ConfigMap for this node,
+	// it only takes effect when prefix delegation is enabled
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	WarmPrefixTarget *int `json:"warmPrefixTarget,omitempty"`
+	// BranchENISubnets overrides the subnet of the branch network interfaces of this node, which is the node's subnet
+	// or the ENIConfig subnet by default
+	// +optional
+	BranchENISubnets *BranchENISubnets `json:"branchENISubnets,omitempty"`
 }
 
 // CNINodeStatus defines the managed VPC resources.
 type CNINodeStatus struct {
-	//TODO: add VPC resources which will be managed by this CRD and its finalizer
+	// TrunkENIID is the ID of the trunk network interface attached to the node
+	// +optional
+	TrunkENIID string `json:"trunkENIID,omitempty"`
+	// BranchENICount is the number of branch network interfaces associated with pods running on the node
+	// +optional
+	BranchENICount int `json:"branchENICount,omitempty"`
+	// UsedVlanIDs is the number of VLAN IDs of the trunk network interface in use by branch network interfaces,
+	// including the ones waiting to be deleted
+	// +optional
+	UsedVlanIDs int `json:"usedVlanIDs,omitempty"`
+	// WarmIPCount is the number of IPv4 addresses in the node's warm pool
+	// +optional
+	WarmIPCount int `json:"warmIPCount,omitempty"`
+	// UsedIPCount is the number of IPv4 addresses assigned to pods running on the node
+	// +optional
+	UsedIPCount int `json:"usedIPCount,omitempty"`
+	// CoolingIPCount is the number of IPv4 addresses released by pods and cooling down before reuse
+	// +optional
+	CoolingIPCount int `json:"coolingIPCount,omitempty"`
+	// LastReconcileError is the error encountered on the last reconcile of the node's resources, it is empty
+	// if the last reconcile succeeded
+	// +optional
+	LastReconcileError string `json:"lastReconcileError,omitempty"`
+	// Checkpoint is the state of the node's resources held by the controller, a new controller leader initializes
+	// the node from the checkpoint instead of EC2 and verifies it against EC2 later
+	// +optional
+	Checkpoint *CNINodeCheckpoint `json:"checkpoint,omitempty"`
+}
+
+type CNINodeCheckpoint struct {
+	// Time is the time the checkpointed state last changed
+	Time metav1.Time `json:"time"`
+	// InstanceID is the ID of the EC2 instance the checkpoint was taken for, the checkpoint is ignored if the node
+	// is backed by a different instance
+	InstanceID string `json:"instanceID"`
+	// IPv4Pools is the checkpoint of the Windows IPv4 address pools keyed by the resource name
+	// +optional
+	IPv4Pools map[string]IPv4PoolCheckpoint `json:"ipv4Pools,omitempty"`
+	// Trunk is the checkpoint of the trunk network interface and its branch network interfaces
+	// +optional
+	Trunk *TrunkCheckpoint `json:"trunk,omitempty"`
+}
+
+type IPv4PoolCheckpoint struct {
+	// ENIs is the list of network interfaces the IPv4 addresses or prefixes of the pool are assigned to
+	// +optional
+	ENIs []ENICheckpoint `json:"enis,omitempty"`
+	// UsedResources is the map of pod UID to the IPv4 address assigned to the pod
+	// +optional
+	UsedResources map[string]PoolResource `json:"usedResources,omitempty"`
+	// WarmResources is the list of IPv4 addresses free to be assigned to pods
+	// +optional
+	WarmResources []PoolResource `json:"warmResources,omitempty"`
+	// CoolingResources is the list of IPv4 addresses released by pods and cooling down before reuse
+	// +optional
+	CoolingResources []CoolingPoolResource `json:"coolingResources,omitempty"`
+}
+
+type ENICheckpoint struct {
+	// ID is the ID of the network interface
+	ID string `json:"id"`
+	// AttachmentID is the ID of the network interface's attachment to the instance
+	// +optional
+	AttachmentID string `json:"attachmentID,omitempty"`
+	// DeviceIndex is the device index of the network interface's attachment
+	// +optional
+	DeviceIndex int32 `json:"deviceIndex,omitempty"`
+	// RemainingCapacity is the number of IPv4 addresses or prefixes that can still be assigned to the network
+	// interface
+	// +optional
+	RemainingCapacity int `json:"remainingCapacity,omitempty"`
+	// Resources is the list of secondary IPv4 addresses and prefixes assigned to the network interface
+	// +optional
+	Resources []string `json:"resources,omitempty"`
+}
+
+type PoolResource struct {
+	// GroupID is the IPv4 prefix the address belongs to, or the address itself for secondary IPv4 addresses
+	GroupID string `json:"groupID"`
+	// ResourceID is the IPv4 address
+	ResourceID string `json:"resourceID"`
+}
+
+type CoolingPoolResource struct {
+	PoolResource `json:",inline"`
+	// DeletionTimestamp is the time the pod using the IPv4 address was deleted
+	DeletionTimestamp metav1.Time `json:"deletionTimestamp"`
+}
+
+type TrunkCheckpoint struct {
+	// TrunkENIID is the ID of the trunk network interface
+	TrunkENIID string `json:"trunkENIID"`
+	// BranchENIs is the map of pod UID to the branch network interfaces associated with the pod
+	// +optional
+	BranchENIs map[string][]BranchENICheckpoint `json:"branchENIs,omitempty"`
+	// DeleteQueue is the list of branch network interfaces cooling down before being deleted
+	// +optional
+	DeleteQueue []BranchENICheckpoint `json:"deleteQueue,omitempty"`
+}
+
+type BranchENICheckpoint struct {
+	// ID is the ID of the branch network interface
+	ID string `json:"id"`
+	// MACAddress is the MAC address of the branch network interface
+	// +optional
+	MACAddress string `json:"macAddress,omitempty"`
+	// IPv4Address is the IPv4 address of the branch network interface
+	// +optional
+	IPv4Address string `json:"ipv4Address,omitempty"`
+	// IPv6Address is the IPv6 address of the branch network interface
+	// +optional
+	IPv6Address string `json:"ipv6Address,omitempty"`
+	// VlanID is the VLAN ID of the branch network interface's association with the trunk
+	VlanID int `json:"vlanID"`
+	// AssociationID is the ID of the branch network interface's association with the trunk
+	// +optional
+	AssociationID string `json:"associationID,omitempty"`
+	// SubnetID is the subnet of the branch network interface
+	// +optional
+	SubnetID string `json:"subnetID,omitempty"`
+	// SubnetCIDR is the IPv4 CIDR block of the subnet
+	// +optional
+	SubnetCIDR string `json:"subnetCIDR,omitempty"`
+	// SubnetV6CIDR is the IPv6 CIDR block of the subnet
+	// +optional
+	SubnetV6CIDR string `json:"subnetV6CIDR,omitempty"`
+	// SecurityGroups is the sorted list of security groups of the branch network interface, if known
+	// +optional
+	SecurityGroups []string `json:"securityGroups,omitempty"`
+	// DeletionTimestamp is the time the branch network interface was pushed to the delete queue
+	// +optional
+	DeletionTimestamp *metav1.Time `json:"deletionTimestamp,omitempty"`
+	// DeleteRetryCount is the number of failed attempts to delete the branch network interface
+	// +optional
+	DeleteRetryCount int `json:"deleteRetryCount,omitempty"`
 }
 
 // +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Features",type=string,JSONPath=`.spec.features`,description="The features delegated to VPC resource controller"
+// +kubebuilder:printcolumn:name="Trunk ENI",type=string,JSONPath=`.status.trunkENIID`,priority=1,description="The trunk network interface attached to the node"
+// +kubebuilder:printcolumn:name="Branch ENIs",type=integer,JSONPath=`.status.branchENICount`,priority=1,description="The number of branch network interfaces associated with pods"
+// +kubebuilder:printcolumn:name="VLANs",type=integer,JSONPath=`.status.usedVlanIDs`,priority=1,description="The number of VLAN IDs in use on the trunk network interface"
+// +kubebuilder:printcolumn:name="Warm IPs",type=integer,JSONPath=`.status.warmIPCount`,priority=1,description="The number of IPv4 addresses in the warm pool"
+// +kubebuilder:printcolumn:name="Used IPs",type=integer,JSONPath=`.status.usedIPCount`,priority=1,description="The number of IPv4 addresses assigned to pods"
+// +kubebuilder:printcolumn:name="Cooling IPs",type=integer,JSONPath=`.status.coolingIPCount`,priority=1,description="The number of IPv4 addresses cooling down"
+// +kubebuilder:printcolumn:name="Error",type=string,JSONPath=`.status.lastReconcileError`,priority=1,description="The error encountered on the last reconcile"
 // +kubebuilder:resource:shortName=cnd,scope=Cluster
 
 // +kubebuilder:object:root=true
@@ -21,13 +21,64 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BranchENICheckpoint) DeepCopyInto(out *BranchENICheckpoint) {
+	*out = *in
+	if in.SecurityGroups != nil {
+		in, out := &in.SecurityGroups, &out.SecurityGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeletionTimestamp != nil {
+		in, out := &in.DeletionTimestamp, &out.DeletionTimestamp
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BranchENICheckpoint.
+func (in *BranchENICheckpoint) DeepCopy() *BranchENICheckpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(BranchENICheckpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BranchENISubnets) DeepCopyInto(out *BranchENISubnets) {
+	*out = *in
+	if in.SubnetIDs != nil {
+		in, out := &in.SubnetIDs, &out.SubnetIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SubnetTags != nil {
+		in, out := &in.SubnetTags, &out.SubnetTags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BranchENISubnets.
+func (in *BranchENISubnets) DeepCopy() *BranchENISubnets {
+	if in == nil {
+		return nil
+	}
+	out := new(BranchENISubnets)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CNINode) DeepCopyInto(out *CNINode) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CNINode.
@@ -48,6 +99,34 @@ func (in *CNINode) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CNINodeCheckpoint) DeepCopyInto(out *CNINodeCheckpoint) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.IPv4Pools != nil {
+		in, out := &in.IPv4Pools, &out.IPv4Pools
+		*out = make(map[string]IPv4PoolCheckpoint, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Trunk != nil {
+		in, out := &in.Trunk, &out.Trunk
+		*out = new(TrunkCheckpoint)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CNINodeCheckpoint.
+func (in *CNINodeCheckpoint) DeepCopy() *CNINodeCheckpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(CNINodeCheckpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CNINodeList) DeepCopyInto(out *CNINodeList) {
 	*out = *in
@@ -95,6 +174,26 @@ func (in *CNINodeSpec) DeepCopyInto(out *CNINodeSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.WarmIPTarget != nil {
+		in, out := &in.WarmIPTarget, &out.WarmIPTarget
+		*out = new(int)
+		**out = **in
+	}
+	if in.MinIPTarget != nil {
+		in, out := &in.MinIPTarget, &out.MinIPTarget
+		*out = new(int)
+		**out = **in
+	}
+	if in.WarmPrefixTarget != nil {
+		in, out := &in.WarmPrefixTarget, &out.WarmPrefixTarget
+		*out = new(int)
+		**out = **in
+	}
+	if in.BranchENISubnets != nil {
+		in, out := &in.BranchENISubnets, &out.BranchENISubnets
+		*out = new(BranchENISubnets)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CNINodeSpec.
@@ -110,6 +209,11 @@ func (in *CNINodeSpec) DeepCopy() *CNINodeSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CNINodeStatus) DeepCopyInto(out *CNINodeStatus) {
 	*out = *in
+	if in.Checkpoint != nil {
+		in, out := &in.Checkpoint, &out.Checkpoint
+		*out = new(CNINodeCheckpoint)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CNINodeStatus.
@@ -122,6 +226,43 @@ func (in *CNINodeStatus) DeepCopy() *CNINodeStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoolingPoolResource) DeepCopyInto(out *CoolingPoolResource) {
+	*out = *in
+	out.PoolResource = in.PoolResource
+	in.DeletionTimestamp.DeepCopyInto(&out.DeletionTimestamp)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoolingPoolResource.
+func (in *CoolingPoolResource) DeepCopy() *CoolingPoolResource {
+	if in == nil {
+		return nil
+	}
+	out := new(CoolingPoolResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ENICheckpoint) DeepCopyInto(out *ENICheckpoint) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ENICheckpoint.
+func (in *ENICheckpoint) DeepCopy() *ENICheckpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(ENICheckpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Feature) DeepCopyInto(out *Feature) {
 	*out = *in
@@ -136,3 +277,99 @@ func (in *Feature) DeepCopy() *Feature {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPv4PoolCheckpoint) DeepCopyInto(out *IPv4PoolCheckpoint) {
+	*out = *in
+	if in.ENIs != nil {
+		in, out := &in.ENIs, &out.ENIs
+		*out = make([]ENICheckpoint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UsedResources != nil {
+		in, out := &in.UsedResources, &out.UsedResources
+		*out = make(map[string]PoolResource, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.WarmResources != nil {
+		in, out := &in.WarmResources, &out.WarmResources
+		*out = make([]PoolResource, len(*in))
+		copy(*out, *in)
+	}
+	if in.CoolingResources != nil {
+		in, out := &in.CoolingResources, &out.CoolingResources
+		*out = make([]CoolingPoolResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPv4PoolCheckpoint.
+func (in *IPv4PoolCheckpoint) DeepCopy() *IPv4PoolCheckpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(IPv4PoolCheckpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolResource) DeepCopyInto(out *PoolResource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolResource.
+func (in *PoolResource) DeepCopy() *PoolResource {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrunkCheckpoint) DeepCopyInto(out *TrunkCheckpoint) {
+	*out = *in
+	if in.BranchENIs != nil {
+		in, out := &in.BranchENIs, &out.BranchENIs
+		*out = make(map[string][]BranchENICheckpoint, len(*in))
+		for key, val := range *in {
+			var outVal []BranchENICheckpoint
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]BranchENICheckpoint, len(*in))
+				for i := range *in {
+					(*in)[i].DeepCopyInto(&(*out)[i])
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.DeleteQueue != nil {
+		in, out := &in.DeleteQueue, &out.DeleteQueue
+		*out = make([]BranchENICheckpoint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrunkCheckpoint.
+func (in *TrunkCheckpoint) DeepCopy() *TrunkCheckpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(TrunkCheckpoint)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,56 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Important: Run "make" to regenerate code after modifying this file
+
+// ClusterSecurityGroupPolicySpec defines the desired state of ClusterSecurityGroupPolicy
+type ClusterSecurityGroupPolicySpec struct {
+	// NamespaceSelector selects the namespaces of the pods the policy applies to. An empty selector matches
+	// all namespaces.
+	// +kubebuilder:validation:Required
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector"`
+
+	SecurityGroupPolicySpec `json:",inline"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Security-Group-Ids",type=string,JSONPath=`.spec.securityGroups.groupIds`,description="The security group IDs to apply to the elastic network interface of pods that match this policy"
+// +kubebuilder:printcolumn:name="Priority",type=integer,JSONPath=`.spec.priority`,description="The priority of the policy when multiple policies match the same pod"
+// +kubebuilder:resource:scope=Cluster,shortName=csgp
+
+// Custom Resource Definition for applying security groups to pods across the namespaces matching the namespace selector
+type ClusterSecurityGroupPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ClusterSecurityGroupPolicySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterSecurityGroupPolicyList contains a list of ClusterSecurityGroupPolicy
+type ClusterSecurityGroupPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterSecurityGroupPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterSecurityGroupPolicy{}, &ClusterSecurityGroupPolicyList{})
+}
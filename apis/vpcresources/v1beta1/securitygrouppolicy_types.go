@@ -19,11 +19,32 @@ import (
 
 // Important: Run "make" to regenerate code after modifying this file
 
+// MergeMode defines how the security groups of a policy are merged with the other policies matching the same pod
+// +kubebuilder:validation:Enum=Union;HighestPriorityWins
+type MergeMode string
+
+const (
+	// MergeModeUnion merges the security groups of all the policies matching the pod
+	MergeModeUnion MergeMode = "Union"
+	// MergeModeHighestPriorityWins applies only the security groups of the highest priority policies matching the
+	// pod when the policy is one of them
+	MergeModeHighestPriorityWins MergeMode = "HighestPriorityWins"
+)
+
 // SecurityGroupPolicySpec defines the desired state of SecurityGroupPolicy
 type SecurityGroupPolicySpec struct {
-	PodSelector            *metav1.LabelSelector `json:"podSelector,omitempty"`
-	ServiceAccountSelector *metav1.LabelSelector `json:"serviceAccountSelector,omitempty"`
-	SecurityGroups         GroupIds              `json:"securityGroups,omitempty"`
+	PodSelector            *metav1.LabelSelector   `json:"podSelector,omitempty"`
+	ServiceAccountSelector *ServiceAccountSelector `json:"serviceAccountSelector,omitempty"`
+	SecurityGroups         GroupIds                `json:"securityGroups,omitempty"`
+	// Priority of the policy when multiple policies match the same pod, the policy with the higher value takes
+	// precedence.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+	// MergeMode defines how the security groups of the policy are merged with the other policies matching the
+	// same pod. Defaults to Union.
+	// +optional
+	// +kubebuilder:default=Union
+	MergeMode MergeMode `json:"mergeMode,omitempty"`
 }
 
 // GroupIds contains the list of security groups that will be applied to the network interface of the pod matching the criteria.
@@ -37,13 +58,41 @@ type GroupIds struct {
 // requirement and the exact name of the service account.
 type ServiceAccountSelector struct {
 	*metav1.LabelSelector `json:",omitempty"`
-	// matchNames is the list of service account names. The requirements are ANDed
+	// matchNames is the list of service account names. The names are ORed, and ANDed with the label selector if set
 	// +kubebuilder:validation:MinItems=1
 	MatchNames []string `json:"matchNames,omitempty"`
 }
 
+// SecurityGroupPolicy condition types
+const (
+	// SecurityGroupPolicyConditionReady is true when every security group in the policy exists in the cluster VPC
+	SecurityGroupPolicyConditionReady = "Ready"
+	// SecurityGroupPolicyConditionInvalid is true when one or more security groups in the policy don't exist or
+	// belong to a different VPC
+	SecurityGroupPolicyConditionInvalid = "Invalid"
+)
+
+// SecurityGroupPolicyStatus defines the observed state of SecurityGroupPolicy
+type SecurityGroupPolicyStatus struct {
+	// Conditions contains the Ready and Invalid conditions of the policy.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// InvalidGroupIds is the list of security groups that were not found in the cluster VPC.
+	// +optional
+	InvalidGroupIds []string `json:"invalidGroupIds,omitempty"`
+	// MatchedPods is the number of running pods in the namespace that get the security groups of the policy.
+	// +optional
+	MatchedPods int `json:"matchedPods"`
+}
+
 // +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Security-Group-Ids",type=string,JSONPath=`.spec.securityGroups.groupIds`,description="The security group IDs to apply to the elastic network interface of pods that match this policy"
+// +kubebuilder:printcolumn:name="Priority",type=integer,JSONPath=`.spec.priority`,description="The priority of the policy when multiple policies match the same pod"
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`,description="Whether all the security groups in the policy are valid"
+// +kubebuilder:printcolumn:name="Matched-Pods",type=integer,JSONPath=`.status.matchedPods`,description="The number of pods matched by this policy"
 // +kubebuilder:resource:shortName=sgp
 
 // Custom Resource Definition for applying security groups to pods
@@ -51,7 +100,8 @@ type SecurityGroupPolicy struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec SecurityGroupPolicySpec `json:"spec,omitempty"`
+	Spec   SecurityGroupPolicySpec   `json:"spec,omitempty"`
+	Status SecurityGroupPolicyStatus `json:"status,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -22,6 +22,85 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterSecurityGroupPolicy) DeepCopyInto(out *ClusterSecurityGroupPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSecurityGroupPolicy.
+func (in *ClusterSecurityGroupPolicy) DeepCopy() *ClusterSecurityGroupPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterSecurityGroupPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterSecurityGroupPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterSecurityGroupPolicyList) DeepCopyInto(out *ClusterSecurityGroupPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterSecurityGroupPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSecurityGroupPolicyList.
+func (in *ClusterSecurityGroupPolicyList) DeepCopy() *ClusterSecurityGroupPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterSecurityGroupPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterSecurityGroupPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterSecurityGroupPolicySpec) DeepCopyInto(out *ClusterSecurityGroupPolicySpec) {
+	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.SecurityGroupPolicySpec.DeepCopyInto(&out.SecurityGroupPolicySpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSecurityGroupPolicySpec.
+func (in *ClusterSecurityGroupPolicySpec) DeepCopy() *ClusterSecurityGroupPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterSecurityGroupPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GroupIds) DeepCopyInto(out *GroupIds) {
 	*out = *in
@@ -48,6 +127,7 @@ func (in *SecurityGroupPolicy) DeepCopyInto(out *SecurityGroupPolicy) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecurityGroupPolicy.
@@ -110,7 +190,7 @@ func (in *SecurityGroupPolicySpec) DeepCopyInto(out *SecurityGroupPolicySpec) {
 	}
 	if in.ServiceAccountSelector != nil {
 		in, out := &in.ServiceAccountSelector, &out.ServiceAccountSelector
-		*out = new(v1.LabelSelector)
+		*out = new(ServiceAccountSelector)
 		(*in).DeepCopyInto(*out)
 	}
 	in.SecurityGroups.DeepCopyInto(&out.SecurityGroups)
@@ -126,6 +206,33 @@ func (in *SecurityGroupPolicySpec) DeepCopy() *SecurityGroupPolicySpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecurityGroupPolicyStatus) DeepCopyInto(out *SecurityGroupPolicyStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InvalidGroupIds != nil {
+		in, out := &in.InvalidGroupIds, &out.InvalidGroupIds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecurityGroupPolicyStatus.
+func (in *SecurityGroupPolicyStatus) DeepCopy() *SecurityGroupPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SecurityGroupPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceAccountSelector) DeepCopyInto(out *ServiceAccountSelector) {
 	*out = *in
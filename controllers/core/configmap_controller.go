@@ -45,9 +45,11 @@ type ConfigMapReconciler struct {
 	Condition                         condition.Conditions
 	curWinIPAMEnabledCond             bool
 	curWinPrefixDelegationEnabledCond bool
+	curWinSGPEnabledCond              bool
 	curWinWarmIPTarget                int
 	curWinMinIPTarget                 int
 	curWinPDWarmPrefixTarget          int
+	curWinPrefixCompaction            bool
 	Context                           context.Context
 }
 
@@ -115,6 +117,17 @@ func (r *ConfigMapReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		isPrefixFlagUpdated = true
 	}
 
+	// Check if the Windows security groups for pods flag has changed
+	newWinSGPEnabledCond := r.Condition.IsWindowsSecurityGroupsForPodsEnabled()
+
+	var isSGPFlagUpdated bool
+	if r.curWinSGPEnabledCond != newWinSGPEnabledCond {
+		r.curWinSGPEnabledCond = newWinSGPEnabledCond
+		logger.Info("updated configmap", config.EnableWindowsSecurityGroupsForPodsKey, r.curWinSGPEnabledCond)
+
+		isSGPFlagUpdated = true
+	}
+
 	// Check if Windows IP target configurations in ConfigMap have changed
 	var isWinIPConfigsUpdated bool
 
@@ -131,17 +144,23 @@ func (r *ConfigMapReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		r.curWinPDWarmPrefixTarget = warmPrefixTarget
 		isWinIPConfigsUpdated = true
 	}
+	if prefixCompaction := config.ParseWinPrefixCompaction(r.Log, configmap); isPDEnabled &&
+		r.curWinPrefixCompaction != prefixCompaction {
+		r.curWinPrefixCompaction = prefixCompaction
+		isWinIPConfigsUpdated = true
+	}
 	if isWinIPConfigsUpdated {
 		logger.Info(
 			"Detected update in Windows IP configuration parameter values in ConfigMap",
 			config.WinWarmIPTarget, r.curWinWarmIPTarget,
 			config.WinMinimumIPTarget, r.curWinMinIPTarget,
 			config.WinWarmPrefixTarget, r.curWinPDWarmPrefixTarget,
+			config.WinPrefixCompactionKey, r.curWinPrefixCompaction,
 			config.EnableWindowsPrefixDelegationKey, isPDEnabled,
 		)
 	}
 
-	if isIPAMFlagUpdated || isPrefixFlagUpdated || isWinIPConfigsUpdated {
+	if isIPAMFlagUpdated || isPrefixFlagUpdated || isSGPFlagUpdated || isWinIPConfigsUpdated {
 		err := UpdateNodesOnConfigMapChanges(r.K8sAPI, r.NodeManager)
 		if err != nil {
 			// Error in updating nodes
@@ -27,10 +27,13 @@ import (
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/node"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/node/manager"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/resource"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/timetonetwork"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/tracing"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/utils"
 	"github.com/google/uuid"
 
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/trace"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
@@ -53,6 +56,9 @@ type PodReconciler struct {
 	// DataStore is the cache with memory optimized Pod Objects
 	DataStore cache.Indexer
 	Condition condition.Conditions
+	// TimeToNetwork tracks the resources of the pods that are waiting to be annotated, the handlers observe the wait
+	// once they annotate the pods
+	TimeToNetwork *timetonetwork.Tracker
 }
 
 var (
@@ -61,7 +67,7 @@ var (
 
 // Reconcile handles create/update/delete event by delegating the request to the  handler
 // if the resource is supported by the controller.
-func (r *PodReconciler) Reconcile(request custom.Request) (ctrl.Result, error) {
+func (r *PodReconciler) Reconcile(request custom.Request) (result ctrl.Result, err error) {
 	var isDeleteEvent bool
 	var hasPodCompleted bool
 
@@ -90,6 +96,10 @@ func (r *PodReconciler) Reconcile(request custom.Request) (ctrl.Result, error) {
 	// given the container will not be restarted again
 	hasPodCompleted = pod.Status.Phase == v1.PodSucceeded ||
 		pod.Status.Phase == v1.PodFailed
+	if isDeleteEvent || hasPodCompleted {
+		// The resources of the pod are not tracked anymore even if the request is skipped before they are handled
+		defer r.TimeToNetwork.Forget(pod.UID)
+	}
 
 	logger := r.Log.WithValues("UID", pod.UID, "pod", request.NamespacedName,
 		"node", pod.Spec.NodeName)
@@ -132,9 +142,20 @@ func (r *PodReconciler) Reconcile(request custom.Request) (ctrl.Result, error) {
 	// container level resources
 	aggregateResources := getAggregateResources(pod)
 	logger.V(1).Info("Pod controller logs local variables", "isDeleteEvent", isDeleteEvent, "hasPodCompleted", hasPodCompleted, "nodeDeletedInCluster", nodeDeletedInCluster)
+	// Only the pods with the resources managed by the controller are traced, the span is started with the first handler
+	ctx := context.Background()
+	var span trace.Span
+	defer func() {
+		if span != nil {
+			tracing.End(span, err)
+		}
+	}()
+
 	// For each resource, if a handler can allocate/de-allocate a resource then delegate the
 	// allocation/de-allocation task to the respective handler
 	for resourceName, totalCount := range aggregateResources {
+		// The pod is annotated with the requested resource name
+		annotationName := resourceName
 		// Pod annotation ResourceNameIPAddress has two resource managers: secondary IP and prefix IP;
 		// backend needs to distinguish which resource provider and handler should be used here accordingly
 		if resourceName == config.ResourceNameIPAddress {
@@ -145,13 +166,15 @@ func (r *PodReconciler) Reconcile(request custom.Request) (ctrl.Result, error) {
 		if !isSupported {
 			continue
 		}
+		if span == nil {
+			ctx, span = tracing.Start(ctx, "PodReconciler.Reconcile", tracing.PodAttributes(pod)...)
+		}
+		r.TimeToNetwork.Track(pod, annotationName, isDeleteEvent || hasPodCompleted || nodeDeletedInCluster)
 
-		var err error
-		var result ctrl.Result
 		if isDeleteEvent || hasPodCompleted || nodeDeletedInCluster {
 			result, err = resourceHandler.HandleDelete(pod)
 		} else {
-			result, err = resourceHandler.HandleCreate(int(totalCount), pod)
+			result, err = resourceHandler.HandleCreate(ctx, int(totalCount), pod)
 		}
 		if err != nil || result.Requeue {
 			return result, err
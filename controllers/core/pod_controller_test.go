@@ -29,6 +29,7 @@ import (
 	mock_resource "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/resource"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/k8s/pod"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/timetonetwork"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
@@ -110,7 +111,7 @@ func NewMock(ctrl *gomock.Controller, mockPod *v1.Pod) Mock {
 	mockNode := mock_node.NewMockNode(ctrl)
 	mockK8sWrapper := mock_k8s.NewMockK8sWrapper(ctrl)
 	converter := pod.PodConverter{}
-	mockIndexer := cache.NewIndexer(converter.Indexer, pod.NodeNameIndexer())
+	mockIndexer := cache.NewIndexer(converter.Indexer, pod.Indexers())
 	mockIndexer.Add(mockPod)
 	mockCondition := mock_condition.NewMockConditions(ctrl)
 
@@ -145,7 +146,7 @@ func TestPodReconciler_Reconcile_Create(t *testing.T) {
 	mock.MockNode.EXPECT().IsManaged().Return(true)
 	mock.MockNode.EXPECT().IsReady().Return(true)
 	mock.MockResourceManager.EXPECT().GetResourceHandler(mockResourceName).Return(mock.MockHandler, true)
-	mock.MockHandler.EXPECT().HandleCreate(3, gomock.Any()).Return(reconcile.Result{}, nil)
+	mock.MockHandler.EXPECT().HandleCreate(gomock.Any(), 3, gomock.Any()).Return(reconcile.Result{}, nil)
 	mock.MockResourceManager.EXPECT().GetResourceHandler(mockUnsupportedResourceName).Return(nil, false)
 
 	result, err := mock.PodReconciler.Reconcile(mockReq)
@@ -192,6 +193,27 @@ func TestPodReconciler_Reconcile_NonManaged(t *testing.T) {
 	assert.Equal(t, controllerruntime.Result{Requeue: true, RequeueAfter: time.Second}, result)
 }
 
+// TestPodReconciler_Reconcile_NonManaged_Delete tests the resources of a pod deleted on a node that is not managed
+// anymore are not tracked though the request is ignored
+func TestPodReconciler_Reconcile_NonManaged_Delete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := NewMock(ctrl, mockPod)
+	mock.PodReconciler.TimeToNetwork = timetonetwork.NewTracker(mock.MockK8sAPI, 0)
+	mock.PodReconciler.TimeToNetwork.Track(mockPod, mockResourceName, false)
+
+	mock.MockNodeManager.EXPECT().GetNode(mockNodeName).Return(mock.MockNode, true)
+	mock.MockNode.EXPECT().IsManaged().Return(false)
+
+	result, err := mock.PodReconciler.Reconcile(custom.Request{DeletedObject: mockPod})
+	assert.NoError(t, err)
+	assert.Equal(t, controllerruntime.Result{}, result)
+
+	// The pod is not observed as abandoned since it's not tracked anymore
+	mock.PodReconciler.TimeToNetwork.Track(mockPod, mockResourceName, true)
+}
+
 // TestPodReconciler_Reconcile_NoNodeAssigned tests that the request for a Pod with no Node assigned
 // should be ignored
 func TestPodReconciler_Reconcile_NoNodeAssigned(t *testing.T) {
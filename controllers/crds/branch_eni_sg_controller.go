@@ -0,0 +1,353 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package crds
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/amazon-vpc-resource-controller-k8s/apis/vpcresources/v1beta1"
+	ec2API "github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/ec2/api"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/condition"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/k8s"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/k8s/pod"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider/branch/trunk"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/resource"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/go-logr/logr"
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	ReasonSecurityGroupsUpdated      = "SecurityGroupsUpdated"
+	ReasonSecurityGroupsUpdateFailed = "SecurityGroupsUpdateFailed"
+	ReasonSecurityGroupPolicyRemoved = "SecurityGroupPolicyRemoved"
+)
+
+var (
+	// allNamespacesRequest reconciles the pods in all the namespaces, it's enqueued for a ClusterSecurityGroupPolicy
+	// change seen before the pod data store has synced, when the namespaces of the pods are not known yet
+	allNamespacesRequest = reconcile.Request{}
+)
+
+// BranchENISecurityGroupReconciler updates the security groups of the branch ENIs of running pods when the
+// SecurityGroupPolicy or ClusterSecurityGroupPolicy matching the pods change. The reconcile request of a policy
+// change is keyed on the namespace name as the change to a policy can affect any pod in the namespace, the pods
+// that failed to update are retried with a request keyed on the pod's namespace and name
+type BranchENISecurityGroupReconciler struct {
+	client.Client
+	// podAPI reads the pods from the pod data store of the pod controller, as the manager cache doesn't store the
+	// pod objects
+	podAPI pod.PodClientAPIWrapper
+	// conditions has the sync status of the pod data store
+	conditions      condition.Conditions
+	log             logr.Logger
+	ec2APIHelper    ec2API.EC2APIHelper
+	k8sAPI          k8s.K8sWrapper
+	sgpAPI          utils.SecurityGroupForPodsAPI
+	resourceManager resource.ResourceManager
+	// rateLimiter limits the rate of the EC2 calls to modify the branch ENIs across all the namespaces
+	rateLimiter *rate.Limiter
+	// retryPods enqueues the pods whose branch ENIs failed to update, so that only the failed pods are retried
+	// instead of the whole namespace
+	retryPods chan event.GenericEvent
+}
+
+// podBranchENIs is the branch ENIs of a pod and the security groups resolved for the pod
+type podBranchENIs struct {
+	pod            *corev1.Pod
+	branchENIs     []trunk.ENIDetails
+	securityGroups []string
+}
+
+func NewBranchENISecurityGroupReconciler(
+	client client.Client,
+	podAPI pod.PodClientAPIWrapper,
+	conditions condition.Conditions,
+	logger logr.Logger,
+	ec2APIHelper ec2API.EC2APIHelper,
+	k8sAPI k8s.K8sWrapper,
+	sgpAPI utils.SecurityGroupForPodsAPI,
+	resourceManager resource.ResourceManager,
+) *BranchENISecurityGroupReconciler {
+	return &BranchENISecurityGroupReconciler{
+		Client:          client,
+		podAPI:          podAPI,
+		conditions:      conditions,
+		log:             logger,
+		ec2APIHelper:    ec2APIHelper,
+		k8sAPI:          k8sAPI,
+		sgpAPI:          sgpAPI,
+		resourceManager: resourceManager,
+		rateLimiter: rate.NewLimiter(rate.Limit(config.BranchENISecurityGroupUpdateQPS),
+			config.BranchENISecurityGroupUpdateBurst),
+		retryPods: make(chan event.GenericEvent, config.BranchENISecurityGroupUpdateRetryBufferSize),
+	}
+}
+
+// Reconcile modifies the security groups of the branch ENIs that don't match the security groups currently resolved
+// from the policies. A request with the namespace set reconciles the single pod, a request with only the name set
+// reconciles all the pods in the namespace of the name, and an empty request reconciles the pods in all the namespaces
+func (r *BranchENISecurityGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if !r.conditions.GetPodDataStoreSyncStatus() {
+		// The pods are read from the pod data store, requeue until the pod controller has synced it
+		r.log.V(1).Info("waiting for pod datastore to sync", "request", req.NamespacedName)
+		return ctrl.Result{RequeueAfter: time.Second}, nil
+	}
+	if req.Namespace != "" {
+		return ctrl.Result{}, r.reconcilePod(ctx, req.NamespacedName)
+	}
+	if req == allNamespacesRequest {
+		for _, namespace := range r.podAPI.ListNamespaces() {
+			if err := r.reconcileNamespace(ctx, namespace); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{}, r.reconcileNamespace(ctx, req.Name)
+}
+
+// reconcileNamespace modifies the security groups of the branch ENIs of all the pods in the namespace, the pods that
+// failed to update are enqueued to be retried individually
+func (r *BranchENISecurityGroupReconciler) reconcileNamespace(ctx context.Context, namespace string) error {
+	log := r.log.WithValues("namespace", namespace)
+
+	podList, err := r.podAPI.ListPodsInNamespace(namespace)
+	if err != nil {
+		log.Error(err, "failed to list pods")
+		return err
+	}
+
+	pods := make([]*corev1.Pod, 0, len(podList.Items))
+	for i := range podList.Items {
+		pods = append(pods, &podList.Items[i])
+	}
+	failedPods, err := r.reconcilePods(ctx, pods)
+	if err != nil {
+		log.Error(err, "failed to update security groups of branch ENIs")
+		return err
+	}
+	for _, pod := range failedPods {
+		r.retryPods <- event.GenericEvent{Object: pod}
+	}
+	return nil
+}
+
+// reconcilePod modifies the security groups of the branch ENIs of the single pod, the error is returned so that the
+// pod is retried with backoff
+func (r *BranchENISecurityGroupReconciler) reconcilePod(ctx context.Context, name types.NamespacedName) error {
+	pod, err := r.podAPI.GetPod(name.Namespace, name.Name)
+	if err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	failedPods, err := r.reconcilePods(ctx, []*corev1.Pod{pod})
+	if err != nil {
+		return err
+	}
+	if len(failedPods) > 0 {
+		return fmt.Errorf("failed to update security groups of branch ENIs of pod %s", name)
+	}
+	return nil
+}
+
+// reconcilePods modifies the stale security groups of the branch ENIs of the pods and returns the pods that failed
+// to update. The current security groups of the branch ENIs of all the pods are described in batches, the error is
+// returned if the describe calls fail
+func (r *BranchENISecurityGroupReconciler) reconcilePods(ctx context.Context, pods []*corev1.Pod) ([]*corev1.Pod,
+	error) {
+	var failedPods []*corev1.Pod
+	var podsBranchENIs []podBranchENIs
+	var eniIDs []string
+	for _, pod := range pods {
+		podENIs, err := r.getPodBranchENIs(pod)
+		if err != nil {
+			r.log.Error(err, "failed to resolve security groups of pod", "namespace", pod.Namespace, "pod", pod.Name)
+			failedPods = append(failedPods, pod)
+			continue
+		}
+		if podENIs == nil {
+			continue
+		}
+		podsBranchENIs = append(podsBranchENIs, *podENIs)
+		for _, branchENI := range podENIs.branchENIs {
+			eniIDs = append(eniIDs, branchENI.ID)
+		}
+	}
+	if len(eniIDs) == 0 {
+		return failedPods, nil
+	}
+
+	currentSecurityGroupsByENI, err := r.ec2APIHelper.GetNetworkInterfaceSecurityGroups(ctx, eniIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, podENIs := range podsBranchENIs {
+		if err := r.updateBranchENIs(ctx, podENIs, currentSecurityGroupsByENI); err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			r.log.Error(err, "failed to update security groups of branch ENIs", "namespace", podENIs.pod.Namespace,
+				"pod", podENIs.pod.Name)
+			failedPods = append(failedPods, podENIs.pod)
+		}
+	}
+	return failedPods, nil
+}
+
+// getPodBranchENIs returns the branch ENIs of the pod and the security groups resolved for the pod, nil is returned if
+// the branch ENIs of the pod don't need to be updated
+func (r *BranchENISecurityGroupReconciler) getPodBranchENIs(pod *corev1.Pod) (*podBranchENIs, error) {
+	annotation, ok := pod.Annotations[config.ResourceNamePodENI]
+	if !ok || !pod.DeletionTimestamp.IsZero() ||
+		pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return nil, nil
+	}
+
+	var branchENIs []trunk.ENIDetails
+	if err := json.Unmarshal([]byte(annotation), &branchENIs); err != nil {
+		// The annotation is owned by the controller, ignore the pod instead of retrying
+		r.log.Error(err, "failed to parse branch ENI annotation", "namespace", pod.Namespace, "pod", pod.Name)
+		return nil, nil
+	}
+	if len(branchENIs) == 0 {
+		return nil, nil
+	}
+
+	securityGroups, err := r.sgpAPI.GetMatchingSecurityGroupForPods(pod)
+	if err != nil {
+		limitErr := &utils.SecurityGroupLimitExceededError{}
+		if errors.As(err, &limitErr) {
+			r.k8sAPI.BroadcastEvent(pod, ReasonSecurityGroupsUpdateFailed, limitErr.Error(), corev1.EventTypeWarning)
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(securityGroups) == 0 {
+		// The branch ENI of pods not matching any policy use the instance security groups, which are not
+		// known here. The pod has to be recreated to get the instance security groups
+		r.log.V(1).Info("pod doesn't match any policy, skipping update of branch ENIs",
+			"namespace", pod.Namespace, "pod", pod.Name)
+		r.k8sAPI.BroadcastEvent(pod, ReasonSecurityGroupPolicyRemoved, "Pod no longer matches any security "+
+			"group policy, the security groups of its branch ENI are not updated and the pod must be recreated to "+
+			"remove them", corev1.EventTypeWarning)
+		return nil, nil
+	}
+	return &podBranchENIs{pod: pod, branchENIs: branchENIs, securityGroups: securityGroups}, nil
+}
+
+// updateBranchENIs modifies the security groups of the branch ENIs of the pod if they are stale and records the
+// modified security groups in the branch ENI provider
+func (r *BranchENISecurityGroupReconciler) updateBranchENIs(ctx context.Context, podENIs podBranchENIs,
+	currentSecurityGroupsByENI map[string][]string) error {
+	pod, securityGroups := podENIs.pod, podENIs.securityGroups
+	desiredSecurityGroups := sets.New(securityGroups...)
+	for _, branchENI := range podENIs.branchENIs {
+		groups, found := currentSecurityGroupsByENI[branchENI.ID]
+		if !found {
+			// The branch ENI is deleted along with the pod
+			continue
+		}
+		currentSecurityGroups := sets.New(groups...)
+		if currentSecurityGroups.Equal(desiredSecurityGroups) {
+			continue
+		}
+
+		if err := r.rateLimiter.Wait(ctx); err != nil {
+			return err
+		}
+
+		eniID := branchENI.ID
+		if err := r.ec2APIHelper.ModifyNetworkInterfaceSecurityGroups(ctx, aws.String(eniID), securityGroups); err != nil {
+			r.k8sAPI.BroadcastEvent(pod, ReasonSecurityGroupsUpdateFailed, fmt.Sprintf("failed to update security "+
+				"groups of branch ENI %s to %v: %v", eniID, securityGroups, err), corev1.EventTypeWarning)
+			return err
+		}
+		r.recordSecurityGroups(pod, eniID, securityGroups)
+
+		r.log.Info("updated security groups of branch ENI", "namespace", pod.Namespace, "pod", pod.Name,
+			"eni id", eniID, "old security groups", sets.List(currentSecurityGroups), "security groups", securityGroups)
+		r.k8sAPI.BroadcastEvent(pod, ReasonSecurityGroupsUpdated, fmt.Sprintf("Security Groups of branch ENI %s "+
+			"updated from %v to %v", eniID, sets.List(currentSecurityGroups), securityGroups), corev1.EventTypeNormal)
+	}
+	return nil
+}
+
+// recordSecurityGroups records the modified security groups of the branch ENI in the branch ENI provider, so that
+// the branch ENI is not reused for pods with the security groups the branch ENI was created with
+func (r *BranchENISecurityGroupReconciler) recordSecurityGroups(pod *corev1.Pod, eniID string,
+	securityGroups []string) {
+	resourceProvider, found := r.resourceManager.GetResourceProvider(config.ResourceNamePodENI)
+	if !found {
+		return
+	}
+	updater, ok := resourceProvider.(provider.BranchENISecurityGroupUpdater)
+	if !ok {
+		return
+	}
+	updater.UpdateBranchENISecurityGroups(pod.Spec.NodeName, string(pod.UID), eniID, securityGroups)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *BranchENISecurityGroupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("branch-eni-security-group").
+		// Status updates of the policy don't change the security groups
+		Watches(&v1beta1.SecurityGroupPolicy{}, handler.EnqueueRequestsFromMapFunc(r.mapPolicyToNamespace),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&v1beta1.ClusterSecurityGroupPolicy{}, handler.EnqueueRequestsFromMapFunc(r.mapClusterPolicyToNamespaces),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		WatchesRawSource(source.Channel(r.retryPods, &handler.EnqueueRequestForObject{})).
+		Complete(r)
+}
+
+// mapPolicyToNamespace enqueues the namespace of the SecurityGroupPolicy
+func (r *BranchENISecurityGroupReconciler) mapPolicyToNamespace(_ context.Context, obj client.Object) []reconcile.Request {
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: obj.GetNamespace()}}}
+}
+
+// mapClusterPolicyToNamespaces enqueues the namespaces of all the pods in the pod data store as the
+// ClusterSecurityGroupPolicy can match pods in any namespace. All the namespaces are reconciled in a single request
+// if the pod data store has not synced yet
+func (r *BranchENISecurityGroupReconciler) mapClusterPolicyToNamespaces(_ context.Context, _ client.Object) []reconcile.Request {
+	if !r.conditions.GetPodDataStoreSyncStatus() {
+		return []reconcile.Request{allNamespacesRequest}
+	}
+
+	namespaces := r.podAPI.ListNamespaces()
+	requests := make([]reconcile.Request, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: namespace}})
+	}
+	return requests
+}
@@ -0,0 +1,385 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package crds
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-vpc-resource-controller-k8s/apis/vpcresources/v1beta1"
+	mock_api "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/aws/ec2/api"
+	mock_condition "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/condition"
+	mock_k8s "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/k8s"
+	mock_provider "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/provider"
+	mock_resource "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/resource"
+	mock_utils "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/utils"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/k8s/pod"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var (
+	mockBranchENIID           = "eni-00000000000000001"
+	mockBranchENINodeName     = "node-1"
+	mockBranchENIAnnotation   = `[{"eniId":"eni-00000000000000001","ifAddress":"0e:00:00:00:00:01","privateIp":"192.168.0.1","vlanId":1,"subnetCidr":"192.168.0.0/16"}]`
+	mockBranchENIID2          = "eni-00000000000000002"
+	mockBranchENIAnnotation2  = `[{"eniId":"eni-00000000000000002","ifAddress":"0e:00:00:00:00:02","privateIp":"192.168.0.2","vlanId":2,"subnetCidr":"192.168.0.0/16"}]`
+	namespaceReconcileRequest = reconcile.Request{
+		NamespacedName: types.NamespacedName{Name: mockSGPNamespace},
+	}
+)
+
+type branchENISecurityGroupReconcilerMocks struct {
+	conditions      *mock_condition.MockConditions
+	ec2APIHelper    *mock_api.MockEC2APIHelper
+	k8sAPI          *mock_k8s.MockK8sWrapper
+	sgpAPI          *mock_utils.MockSecurityGroupForPodsAPI
+	resourceManager *mock_resource.MockResourceManager
+	updater         *mock_provider.MockBranchENISecurityGroupUpdater
+}
+
+// mockBranchProvider is the branch ENI provider that records the modified security groups of the branch ENIs
+type mockBranchProvider struct {
+	*mock_provider.MockResourceProvider
+	*mock_provider.MockBranchENISecurityGroupUpdater
+}
+
+func newBranchENIPod(name string, annotations map[string]string, phase corev1.PodPhase) *corev1.Pod {
+	pod := newMockPod(name, map[string]string{"role": "db"}, "db-sa", phase)
+	pod.Annotations = annotations
+	pod.UID = types.UID(name + "-uid")
+	pod.Spec.NodeName = mockBranchENINodeName
+	return pod
+}
+
+func NewBranchENISecurityGroupReconcilerMock(ctrl *gomock.Controller, mockObjects ...client.Object) (
+	*BranchENISecurityGroupReconciler, branchENISecurityGroupReconcilerMocks) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = v1beta1.AddToScheme(scheme)
+	client := fakeClient.NewClientBuilder().WithScheme(scheme).WithObjects(mockObjects...).Build()
+	mocks := branchENISecurityGroupReconcilerMocks{
+		conditions:      mock_condition.NewMockConditions(ctrl),
+		ec2APIHelper:    mock_api.NewMockEC2APIHelper(ctrl),
+		k8sAPI:          mock_k8s.NewMockK8sWrapper(ctrl),
+		sgpAPI:          mock_utils.NewMockSecurityGroupForPodsAPI(ctrl),
+		resourceManager: mock_resource.NewMockResourceManager(ctrl),
+		updater:         mock_provider.NewMockBranchENISecurityGroupUpdater(ctrl),
+	}
+	branchProvider := &mockBranchProvider{
+		MockResourceProvider:              mock_provider.NewMockResourceProvider(ctrl),
+		MockBranchENISecurityGroupUpdater: mocks.updater,
+	}
+	mocks.resourceManager.EXPECT().GetResourceProvider(config.ResourceNamePodENI).Return(branchProvider, true).AnyTimes()
+	podAPI := pod.NewPodAPIWrapper(newPodDataStore(mockObjects...), client, nil)
+	return NewBranchENISecurityGroupReconciler(client, podAPI, mocks.conditions, zap.New(), mocks.ec2APIHelper,
+		mocks.k8sAPI, mocks.sgpAPI, mocks.resourceManager), mocks
+}
+
+func TestBranchENISecurityGroupReconcile(t *testing.T) {
+	branchENIAnnotation := map[string]string{config.ResourceNamePodENI: mockBranchENIAnnotation}
+	mockErr := errors.New("mock error")
+
+	tests := []struct {
+		name    string
+		objects []client.Object
+		prepare func(mocks branchENISecurityGroupReconcilerMocks)
+		asserts func(res reconcile.Result, err error)
+		// retriedPods is the pods enqueued to be retried individually
+		retriedPods []string
+	}{
+		{
+			name:    "verify stale security groups of branch ENI are updated and event is sent to the pod",
+			objects: []client.Object{newBranchENIPod("db-1", branchENIAnnotation, corev1.PodRunning)},
+			prepare: func(mocks branchENISecurityGroupReconcilerMocks) {
+				mocks.sgpAPI.EXPECT().GetMatchingSecurityGroupForPods(gomock.Any()).Return([]string{"sg-1", "sg-2"}, nil)
+				mocks.ec2APIHelper.EXPECT().GetNetworkInterfaceSecurityGroups(gomock.Any(), []string{mockBranchENIID}).
+					Return(map[string][]string{mockBranchENIID: {"sg-1"}}, nil)
+				mocks.ec2APIHelper.EXPECT().ModifyNetworkInterfaceSecurityGroups(gomock.Any(), aws.String(mockBranchENIID),
+					[]string{"sg-1", "sg-2"}).Return(nil)
+				mocks.updater.EXPECT().UpdateBranchENISecurityGroups(mockBranchENINodeName, "db-1-uid", mockBranchENIID,
+					[]string{"sg-1", "sg-2"})
+				mocks.k8sAPI.EXPECT().BroadcastEvent(gomock.Any(), ReasonSecurityGroupsUpdated,
+					"Security Groups of branch ENI eni-00000000000000001 updated from [sg-1] to [sg-1 sg-2]",
+					corev1.EventTypeNormal)
+			},
+			asserts: func(res reconcile.Result, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, reconcile.Result{}, res)
+			},
+		},
+		{
+			name:    "verify branch ENI with up to date security groups is not modified",
+			objects: []client.Object{newBranchENIPod("db-1", branchENIAnnotation, corev1.PodRunning)},
+			prepare: func(mocks branchENISecurityGroupReconcilerMocks) {
+				mocks.sgpAPI.EXPECT().GetMatchingSecurityGroupForPods(gomock.Any()).Return([]string{"sg-1", "sg-2"}, nil)
+				mocks.ec2APIHelper.EXPECT().GetNetworkInterfaceSecurityGroups(gomock.Any(), []string{mockBranchENIID}).
+					Return(map[string][]string{mockBranchENIID: {"sg-2", "sg-1"}}, nil)
+			},
+			asserts: func(res reconcile.Result, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "verify pods without branch ENI or not running are ignored",
+			objects: []client.Object{
+				newBranchENIPod("db-1", nil, corev1.PodRunning),
+				newBranchENIPod("db-2", branchENIAnnotation, corev1.PodSucceeded),
+				newBranchENIPod("db-3", branchENIAnnotation, corev1.PodFailed),
+				newBranchENIPod("db-4", map[string]string{config.ResourceNamePodENI: "invalid"}, corev1.PodRunning),
+			},
+			asserts: func(res reconcile.Result, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:    "verify pod no longer matching any policy is not modified and warning event is sent to the pod",
+			objects: []client.Object{newBranchENIPod("db-1", branchENIAnnotation, corev1.PodRunning)},
+			prepare: func(mocks branchENISecurityGroupReconcilerMocks) {
+				mocks.sgpAPI.EXPECT().GetMatchingSecurityGroupForPods(gomock.Any()).Return(nil, nil)
+				mocks.k8sAPI.EXPECT().BroadcastEvent(gomock.Any(), ReasonSecurityGroupPolicyRemoved, gomock.Any(),
+					corev1.EventTypeWarning)
+			},
+			asserts: func(res reconcile.Result, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:    "verify warning event is sent to the pod when the security groups exceed the limit",
+			objects: []client.Object{newBranchENIPod("db-1", branchENIAnnotation, corev1.PodRunning)},
+			prepare: func(mocks branchENISecurityGroupReconcilerMocks) {
+				mocks.sgpAPI.EXPECT().GetMatchingSecurityGroupForPods(gomock.Any()).
+					Return(nil, &utils.SecurityGroupLimitExceededError{Limit: 1})
+				mocks.k8sAPI.EXPECT().BroadcastEvent(gomock.Any(), ReasonSecurityGroupsUpdateFailed, gomock.Any(),
+					corev1.EventTypeWarning)
+			},
+			asserts: func(res reconcile.Result, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name: "verify only the pod that failed to update is retried and warning event is sent to the pod",
+			objects: []client.Object{
+				newBranchENIPod("db-1", branchENIAnnotation, corev1.PodRunning),
+				newBranchENIPod("db-2", map[string]string{config.ResourceNamePodENI: mockBranchENIAnnotation2},
+					corev1.PodRunning),
+			},
+			prepare: func(mocks branchENISecurityGroupReconcilerMocks) {
+				mocks.sgpAPI.EXPECT().GetMatchingSecurityGroupForPods(gomock.Any()).Return([]string{"sg-2"}, nil).Times(2)
+				mocks.ec2APIHelper.EXPECT().GetNetworkInterfaceSecurityGroups(gomock.Any(), []string{mockBranchENIID, mockBranchENIID2}).
+					Return(map[string][]string{mockBranchENIID: {"sg-1"}, mockBranchENIID2: {"sg-1"}}, nil)
+				mocks.ec2APIHelper.EXPECT().ModifyNetworkInterfaceSecurityGroups(gomock.Any(), aws.String(mockBranchENIID),
+					[]string{"sg-2"}).Return(mockErr)
+				mocks.k8sAPI.EXPECT().BroadcastEvent(gomock.Any(), ReasonSecurityGroupsUpdateFailed, gomock.Any(),
+					corev1.EventTypeWarning)
+				mocks.ec2APIHelper.EXPECT().ModifyNetworkInterfaceSecurityGroups(gomock.Any(), aws.String(mockBranchENIID2),
+					[]string{"sg-2"}).Return(nil)
+				mocks.updater.EXPECT().UpdateBranchENISecurityGroups(mockBranchENINodeName, "db-2-uid",
+					mockBranchENIID2, []string{"sg-2"})
+				mocks.k8sAPI.EXPECT().BroadcastEvent(gomock.Any(), ReasonSecurityGroupsUpdated, gomock.Any(),
+					corev1.EventTypeNormal)
+			},
+			asserts: func(res reconcile.Result, err error) {
+				assert.NoError(t, err)
+			},
+			retriedPods: []string{"db-1"},
+		},
+		{
+			name:    "verify error is returned when describing the branch ENIs fails",
+			objects: []client.Object{newBranchENIPod("db-1", branchENIAnnotation, corev1.PodRunning)},
+			prepare: func(mocks branchENISecurityGroupReconcilerMocks) {
+				mocks.sgpAPI.EXPECT().GetMatchingSecurityGroupForPods(gomock.Any()).Return([]string{"sg-2"}, nil)
+				mocks.ec2APIHelper.EXPECT().GetNetworkInterfaceSecurityGroups(gomock.Any(), []string{mockBranchENIID}).
+					Return(nil, mockErr)
+			},
+			asserts: func(res reconcile.Result, err error) {
+				assert.ErrorIs(t, err, mockErr)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			reconciler, mocks := NewBranchENISecurityGroupReconcilerMock(ctrl, tt.objects...)
+			mocks.conditions.EXPECT().GetPodDataStoreSyncStatus().Return(true)
+			if tt.prepare != nil {
+				tt.prepare(mocks)
+			}
+			res, err := reconciler.Reconcile(context.Background(), namespaceReconcileRequest)
+			tt.asserts(res, err)
+
+			var retriedPods []string
+			for len(reconciler.retryPods) > 0 {
+				retriedPods = append(retriedPods, (<-reconciler.retryPods).Object.GetName())
+			}
+			assert.Equal(t, tt.retriedPods, retriedPods)
+		})
+	}
+}
+
+func TestBranchENISecurityGroupReconcile_Pod(t *testing.T) {
+	branchENIAnnotation := map[string]string{config.ResourceNamePodENI: mockBranchENIAnnotation}
+	podReconcileRequest := reconcile.Request{
+		NamespacedName: types.NamespacedName{Namespace: mockSGPNamespace, Name: "db-1"},
+	}
+	mockErr := errors.New("mock error")
+
+	tests := []struct {
+		name    string
+		objects []client.Object
+		prepare func(mocks branchENISecurityGroupReconcilerMocks)
+		asserts func(res reconcile.Result, err error)
+	}{
+		{
+			name:    "verify security groups of branch ENI of the pod are updated",
+			objects: []client.Object{newBranchENIPod("db-1", branchENIAnnotation, corev1.PodRunning)},
+			prepare: func(mocks branchENISecurityGroupReconcilerMocks) {
+				mocks.sgpAPI.EXPECT().GetMatchingSecurityGroupForPods(gomock.Any()).Return([]string{"sg-2"}, nil)
+				mocks.ec2APIHelper.EXPECT().GetNetworkInterfaceSecurityGroups(gomock.Any(), []string{mockBranchENIID}).
+					Return(map[string][]string{mockBranchENIID: {"sg-1"}}, nil)
+				mocks.ec2APIHelper.EXPECT().ModifyNetworkInterfaceSecurityGroups(gomock.Any(), aws.String(mockBranchENIID),
+					[]string{"sg-2"}).Return(nil)
+				mocks.updater.EXPECT().UpdateBranchENISecurityGroups(mockBranchENINodeName, "db-1-uid",
+					mockBranchENIID, []string{"sg-2"})
+				mocks.k8sAPI.EXPECT().BroadcastEvent(gomock.Any(), ReasonSecurityGroupsUpdated, gomock.Any(),
+					corev1.EventTypeNormal)
+			},
+			asserts: func(res reconcile.Result, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:    "verify error is returned to retry the pod when the update fails",
+			objects: []client.Object{newBranchENIPod("db-1", branchENIAnnotation, corev1.PodRunning)},
+			prepare: func(mocks branchENISecurityGroupReconcilerMocks) {
+				mocks.sgpAPI.EXPECT().GetMatchingSecurityGroupForPods(gomock.Any()).Return([]string{"sg-2"}, nil)
+				mocks.ec2APIHelper.EXPECT().GetNetworkInterfaceSecurityGroups(gomock.Any(), []string{mockBranchENIID}).
+					Return(map[string][]string{mockBranchENIID: {"sg-1"}}, nil)
+				mocks.ec2APIHelper.EXPECT().ModifyNetworkInterfaceSecurityGroups(gomock.Any(), aws.String(mockBranchENIID),
+					[]string{"sg-2"}).Return(mockErr)
+				mocks.k8sAPI.EXPECT().BroadcastEvent(gomock.Any(), ReasonSecurityGroupsUpdateFailed, gomock.Any(),
+					corev1.EventTypeWarning)
+			},
+			asserts: func(res reconcile.Result, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "verify deleted pod is ignored",
+			asserts: func(res reconcile.Result, err error) {
+				assert.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			reconciler, mocks := NewBranchENISecurityGroupReconcilerMock(ctrl, tt.objects...)
+			mocks.conditions.EXPECT().GetPodDataStoreSyncStatus().Return(true)
+			if tt.prepare != nil {
+				tt.prepare(mocks)
+			}
+			res, err := reconciler.Reconcile(context.Background(), podReconcileRequest)
+			tt.asserts(res, err)
+		})
+	}
+}
+
+func TestBranchENISecurityGroupReconciler_MapClusterPolicyToNamespaces(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	otherNamespacePod := newBranchENIPod("db-2", nil, corev1.PodRunning)
+	otherNamespacePod.Namespace = "ns-2"
+	reconciler, mocks := NewBranchENISecurityGroupReconcilerMock(ctrl,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-without-pods"}},
+		newBranchENIPod("db-1", nil, corev1.PodRunning), otherNamespacePod,
+	)
+
+	// Only the namespaces of the pods in the pod data store are enqueued
+	mocks.conditions.EXPECT().GetPodDataStoreSyncStatus().Return(true)
+	requests := reconciler.mapClusterPolicyToNamespaces(context.Background(), &v1beta1.ClusterSecurityGroupPolicy{})
+	assert.ElementsMatch(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: mockSGPNamespace}},
+		{NamespacedName: types.NamespacedName{Name: "ns-2"}},
+	}, requests)
+
+	// All the namespaces are reconciled in a single request until the pod data store has synced
+	mocks.conditions.EXPECT().GetPodDataStoreSyncStatus().Return(false)
+	requests = reconciler.mapClusterPolicyToNamespaces(context.Background(), &v1beta1.ClusterSecurityGroupPolicy{})
+	assert.Equal(t, []reconcile.Request{allNamespacesRequest}, requests)
+
+	requests = reconciler.mapPolicyToNamespace(context.Background(), mockSGP)
+	assert.Equal(t, []reconcile.Request{{NamespacedName: types.NamespacedName{Name: mockSGPNamespace}}}, requests)
+}
+
+// TestBranchENISecurityGroupReconcile_DataStoreNotSynced tests the request is requeued until the pod data store has
+// synced
+func TestBranchENISecurityGroupReconcile_DataStoreNotSynced(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reconciler, mocks := NewBranchENISecurityGroupReconcilerMock(ctrl,
+		newBranchENIPod("db-1", map[string]string{config.ResourceNamePodENI: mockBranchENIAnnotation},
+			corev1.PodRunning))
+	mocks.conditions.EXPECT().GetPodDataStoreSyncStatus().Return(false)
+
+	res, err := reconciler.Reconcile(context.Background(), namespaceReconcileRequest)
+	assert.NoError(t, err)
+	assert.Equal(t, reconcile.Result{RequeueAfter: time.Second}, res)
+}
+
+// TestBranchENISecurityGroupReconcile_AllNamespaces tests the pods in all the namespaces of the pod data store are
+// reconciled by the all namespaces request
+func TestBranchENISecurityGroupReconcile_AllNamespaces(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	otherNamespacePod := newBranchENIPod("db-2", map[string]string{config.ResourceNamePodENI: mockBranchENIAnnotation2},
+		corev1.PodRunning)
+	otherNamespacePod.Namespace = "ns-2"
+	reconciler, mocks := NewBranchENISecurityGroupReconcilerMock(ctrl,
+		newBranchENIPod("db-1", map[string]string{config.ResourceNamePodENI: mockBranchENIAnnotation},
+			corev1.PodRunning), otherNamespacePod)
+
+	mocks.conditions.EXPECT().GetPodDataStoreSyncStatus().Return(true)
+	mocks.sgpAPI.EXPECT().GetMatchingSecurityGroupForPods(gomock.Any()).Return([]string{"sg-1"}, nil).Times(2)
+	mocks.ec2APIHelper.EXPECT().GetNetworkInterfaceSecurityGroups(gomock.Any(), []string{mockBranchENIID}).
+		Return(map[string][]string{mockBranchENIID: {"sg-1"}}, nil)
+	mocks.ec2APIHelper.EXPECT().GetNetworkInterfaceSecurityGroups(gomock.Any(), []string{mockBranchENIID2}).
+		Return(map[string][]string{mockBranchENIID2: {"sg-1"}}, nil)
+
+	res, err := reconciler.Reconcile(context.Background(), allNamespacesRequest)
+	assert.NoError(t, err)
+	assert.Equal(t, reconcile.Result{}, res)
+}
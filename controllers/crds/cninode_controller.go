@@ -22,11 +22,14 @@ import (
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/ec2/api/cleanup"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/k8s"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/resource"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/utils"
 	"github.com/go-logr/logr"
 	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/sync/semaphore"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -34,10 +37,13 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
 var (
@@ -79,6 +85,7 @@ type CNINodeReconciler struct {
 	finalizerManager   k8s.FinalizerManager
 	deletePool         *semaphore.Weighted
 	newResourceCleaner func(nodeID string, eC2Wrapper ec2API.EC2Wrapper, vpcID string, log logr.Logger) cleanup.ResourceCleaner
+	resourceManager    resource.ResourceManager
 }
 
 func NewCNINodeReconciler(
@@ -93,6 +100,7 @@ func NewCNINodeReconciler(
 	finalizerManager k8s.FinalizerManager,
 	maxConcurrentWorkers int,
 	newResourceCleaner func(nodeID string, eC2Wrapper ec2API.EC2Wrapper, vpcID string, log logr.Logger) cleanup.ResourceCleaner,
+	resourceManager resource.ResourceManager,
 ) *CNINodeReconciler {
 	return &CNINodeReconciler{
 		Client:             client,
@@ -106,6 +114,7 @@ func NewCNINodeReconciler(
 		finalizerManager:   finalizerManager,
 		deletePool:         semaphore.NewWeighted(int64(maxConcurrentWorkers)),
 		newResourceCleaner: newResourceCleaner,
+		resourceManager:    resourceManager,
 	}
 }
 
@@ -133,6 +142,9 @@ func (r *CNINodeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	}
 
 	if cniNode.GetDeletionTimestamp().IsZero() {
+		// Update the candidate subnets of the branch ENIs on spec changes instead of waiting for the next node event
+		r.setBranchENISubnets(cniNode)
+
 		cniNodeCopy := cniNode.DeepCopy()
 		shouldPatch, err := r.ensureTagsAndLabels(cniNodeCopy, node)
 		shouldPatch = controllerutil.AddFinalizer(cniNodeCopy, config.NodeTerminationFinalizer) || shouldPatch
@@ -231,11 +243,40 @@ func (r *CNINodeReconciler) SetupWithManager(mgr ctrl.Manager, maxNodeConcurrent
 		prometheusRegister()
 	}
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&v1alpha1.CNINode{}).
+		For(&v1alpha1.CNINode{}, builder.WithPredicates(statusUpdatePredicate)).
 		WithOptions(controller.Options{MaxConcurrentReconciles: maxNodeConcurrentReconciles}).
 		Complete(r)
 }
 
+// statusUpdatePredicate filters out the updates of the CNINode that only change the status. The inventory and checkpoint
+// written to the status by the node manager must not trigger a reconcile, while the changes to the spec, the labels or
+// the finalizers of the CNINode must be reconciled to repair the CNINode
+var statusUpdatePredicate = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		if e.ObjectOld == nil || e.ObjectNew == nil {
+			return true
+		}
+		if e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration() {
+			return true
+		}
+		oldCNINode, okOld := e.ObjectOld.(*v1alpha1.CNINode)
+		newCNINode, okNew := e.ObjectNew.(*v1alpha1.CNINode)
+		if !okOld || !okNew {
+			return true
+		}
+		return !equality.Semantic.DeepEqual(getComparableObjectMeta(oldCNINode.ObjectMeta),
+			getComparableObjectMeta(newCNINode.ObjectMeta))
+	},
+}
+
+// getComparableObjectMeta returns a copy of the object meta without the fields changed by every update
+func getComparableObjectMeta(objectMeta metav1.ObjectMeta) metav1.ObjectMeta {
+	comparable := *objectMeta.DeepCopy()
+	comparable.ResourceVersion = ""
+	comparable.ManagedFields = nil
+	return comparable
+}
+
 // waitTillCNINodeDeleted waits for CNINode to be deleted with timeout and returns error
 func (r *CNINodeReconciler) waitTillCNINodeDeleted(nameSpacedCNINode types.NamespacedName) error {
 	oldCNINode := &v1alpha1.CNINode{}
@@ -288,6 +329,17 @@ func (r *CNINodeReconciler) ensureTagsAndLabels(cniNode *v1alpha1.CNINode, node
 	return shouldPatch, err
 }
 
+// setBranchENISubnets sets the candidate subnets of the branch ENIs configured on the CNINode on the branch ENI provider
+func (r *CNINodeReconciler) setBranchENISubnets(cniNode *v1alpha1.CNINode) {
+	resourceProvider, found := r.resourceManager.GetResourceProvider(config.ResourceNamePodENI)
+	if !found {
+		return
+	}
+	if setter, ok := resourceProvider.(provider.BranchENISubnetsSetter); ok {
+		setter.SetBranchENISubnets(cniNode.Name, cniNode.Spec.BranchENISubnets)
+	}
+}
+
 func (r *CNINodeReconciler) removeFinalizer(ctx context.Context, cniNode *v1alpha1.CNINode, finalizer string) error {
 	cniNodeCopy := cniNode.DeepCopy()
 
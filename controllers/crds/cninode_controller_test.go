@@ -8,6 +8,8 @@ import (
 	mock_api "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/aws/ec2/api"
 	mock_cleanup "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/aws/ec2/api/cleanup"
 	mock_k8s "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/k8s"
+	mock_provider "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/provider"
+	mock_resource "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/resource"
 	ec2API "github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/ec2/api"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/ec2/api/cleanup"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
@@ -21,6 +23,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -48,8 +51,18 @@ var (
 			Name: mockName,
 		},
 	}
+	mockBranchENISubnets = &v1alpha1.BranchENISubnets{
+		SubnetIDs: []string{"subnet-00000000000000001"},
+		Strategy:  v1alpha1.SubnetSelectionStrategyRoundRobin,
+	}
 )
 
+// mockBranchSubnetsProvider is the branch ENI provider that sets the candidate subnets of the branch ENIs
+type mockBranchSubnetsProvider struct {
+	*mock_provider.MockResourceProvider
+	*mock_provider.MockBranchENISubnetsSetter
+}
+
 func NewCNINodeMock(ctrl *gomock.Controller, mockObjects ...client.Object) *CNINodeMock {
 	scheme := runtime.NewScheme()
 	_ = corev1.AddToScheme(scheme)
@@ -77,6 +90,8 @@ func TestCNINodeReconcile(t *testing.T) {
 		mockK8sApi           *mock_k8s.MockK8sWrapper
 		mockFinalizerManager *mock_k8s.MockFinalizerManager
 		mockEC2API           *mock_api.MockEC2APIHelper
+		mockResourceManager  *mock_resource.MockResourceManager
+		mockBranchProvider   *mockBranchSubnetsProvider
 		mockCNINode          *CNINodeMock
 	}
 	tests := []struct {
@@ -188,6 +203,36 @@ func TestCNINodeReconcile(t *testing.T) {
 				assert.Contains(t, cniNode.Finalizers, config.NodeTerminationFinalizer)
 			},
 		},
+		{
+			name: "verify branch ENI subnets are set on the branch ENI provider",
+			args: args{
+				mockNode: mockNodeWithLabel,
+				mockCNINode: &v1alpha1.CNINode{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: mockName,
+						Labels: map[string]string{
+							config.NodeLabelOS: "linux",
+						},
+						Finalizers: []string{config.NodeTerminationFinalizer},
+					},
+					Spec: v1alpha1.CNINodeSpec{
+						Tags: map[string]string{
+							config.VPCCNIClusterNameKey:      mockClusterName,
+							config.NetworkInterfaceNodeIDKey: "i-0123456789abcdef0",
+						},
+						BranchENISubnets: mockBranchENISubnets,
+					},
+				},
+			},
+			prepare: func(f *fields) {
+				f.mockResourceManager.EXPECT().GetResourceProvider(config.ResourceNamePodENI).Return(f.mockBranchProvider, true)
+				f.mockBranchProvider.MockBranchENISubnetsSetter.EXPECT().SetBranchENISubnets(mockName, mockBranchENISubnets)
+			},
+			asserts: func(res reconcile.Result, err error, cniNode *v1alpha1.CNINode) {
+				assert.NoError(t, err)
+				assert.Equal(t, res, reconcile.Result{})
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -203,12 +248,20 @@ func TestCNINodeReconcile(t *testing.T) {
 				mockK8sApi:           mock_k8s.NewMockK8sWrapper(ctrl),
 				mockFinalizerManager: mock_k8s.NewMockFinalizerManager(ctrl),
 				mockEC2API:           mock_api.NewMockEC2APIHelper(ctrl),
-				mockCNINode:          mock,
+				mockResourceManager:  mock_resource.NewMockResourceManager(ctrl),
+				mockBranchProvider: &mockBranchSubnetsProvider{
+					MockResourceProvider:       mock_provider.NewMockResourceProvider(ctrl),
+					MockBranchENISubnetsSetter: mock_provider.NewMockBranchENISubnetsSetter(ctrl),
+				},
+				mockCNINode: mock,
 			}
 			mock.Reconciler.finalizerManager = f.mockFinalizerManager
 			mock.Reconciler.k8sAPI = f.mockK8sApi
+			mock.Reconciler.resourceManager = f.mockResourceManager
 			if tt.prepare != nil {
 				tt.prepare(&f)
+			} else {
+				f.mockResourceManager.EXPECT().GetResourceProvider(config.ResourceNamePodENI).Return(nil, false).AnyTimes()
 			}
 			res, err := mock.Reconciler.Reconcile(context.Background(), reconcileRequest)
 
@@ -224,3 +277,63 @@ func TestCNINodeReconcile(t *testing.T) {
 		})
 	}
 }
+
+// TestStatusUpdatePredicate tests the updates of the CNINode are reconciled unless they only change the status
+func TestStatusUpdatePredicate(t *testing.T) {
+	cniNode := &v1alpha1.CNINode{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            mockName,
+			Generation:      1,
+			ResourceVersion: "1",
+			Labels:          map[string]string{config.NodeLabelOS: "linux"},
+			Finalizers:      []string{config.NodeTerminationFinalizer},
+		},
+		Spec: v1alpha1.CNINodeSpec{Tags: map[string]string{config.VPCCNIClusterNameKey: mockClusterName}},
+	}
+	tests := []struct {
+		name            string
+		update          func(*v1alpha1.CNINode)
+		expectReconcile bool
+	}{
+		{
+			name: "status only",
+			update: func(cniNode *v1alpha1.CNINode) {
+				cniNode.Status = v1alpha1.CNINodeStatus{TrunkENIID: "eni-000000000000", BranchENICount: 1}
+				cniNode.ResourceVersion = "2"
+			},
+		},
+		{
+			name: "labels removed",
+			update: func(cniNode *v1alpha1.CNINode) {
+				cniNode.Labels = nil
+				cniNode.ResourceVersion = "2"
+			},
+			expectReconcile: true,
+		},
+		{
+			name: "finalizer removed",
+			update: func(cniNode *v1alpha1.CNINode) {
+				cniNode.Finalizers = nil
+				cniNode.ResourceVersion = "2"
+			},
+			expectReconcile: true,
+		},
+		{
+			name: "spec changed",
+			update: func(cniNode *v1alpha1.CNINode) {
+				cniNode.Spec.Tags = nil
+				cniNode.Generation = 2
+				cniNode.ResourceVersion = "2"
+			},
+			expectReconcile: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			newCNINode := cniNode.DeepCopy()
+			test.update(newCNINode)
+			assert.Equal(t, test.expectReconcile,
+				statusUpdatePredicate.Update(event.UpdateEvent{ObjectOld: cniNode, ObjectNew: newCNINode}))
+		})
+	}
+}
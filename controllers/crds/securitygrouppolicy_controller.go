@@ -0,0 +1,257 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package crds
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/amazon-vpc-resource-controller-k8s/apis/vpcresources/v1beta1"
+	ec2API "github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/ec2/api"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/condition"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/k8s/pod"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	ReasonSecurityGroupsValid   = "SecurityGroupsValid"
+	ReasonInvalidSecurityGroups = "InvalidSecurityGroups"
+)
+
+// SecurityGroupPolicyReconciler reconciles a SecurityGroupPolicy object
+type SecurityGroupPolicyReconciler struct {
+	client.Client
+	// podAPI reads the pods from the pod data store of the pod controller, as the manager cache doesn't store the
+	// pod objects
+	podAPI pod.PodClientAPIWrapper
+	// conditions has the sync status of the pod data store
+	conditions   condition.Conditions
+	log          logr.Logger
+	ec2APIHelper ec2API.EC2APIHelper
+	sgpAPI       utils.SecurityGroupForPodsAPI
+	vpcId        string
+}
+
+func NewSecurityGroupPolicyReconciler(
+	client client.Client,
+	podAPI pod.PodClientAPIWrapper,
+	conditions condition.Conditions,
+	logger logr.Logger,
+	ec2APIHelper ec2API.EC2APIHelper,
+	sgpAPI utils.SecurityGroupForPodsAPI,
+	vpcId string,
+) *SecurityGroupPolicyReconciler {
+	return &SecurityGroupPolicyReconciler{
+		Client:       client,
+		podAPI:       podAPI,
+		conditions:   conditions,
+		log:          logger,
+		ec2APIHelper: ec2APIHelper,
+		sgpAPI:       sgpAPI,
+		vpcId:        vpcId,
+	}
+}
+
+//+kubebuilder:rbac:groups=vpcresources.k8s.aws,resources=securitygrouppolicies/status,verbs=get;patch
+
+// Reconcile validates the security groups of the SecurityGroupPolicy against EC2 and updates the
+// policy status with the validation conditions and the number of pods matched by the policy.
+// The policy is requeued periodically as security groups and pods can change without an update to the policy
+func (r *SecurityGroupPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	sgp := &v1beta1.SecurityGroupPolicy{}
+	if err := r.Client.Get(ctx, req.NamespacedName, sgp); err != nil {
+		// Ignore not found error
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !sgp.GetDeletionTimestamp().IsZero() {
+		return ctrl.Result{}, nil
+	}
+	if !r.conditions.GetPodDataStoreSyncStatus() {
+		// The matched pods are counted from the pod data store, requeue until the pod controller has synced it
+		r.log.V(1).Info("waiting for pod datastore to sync", "sgp", req.NamespacedName)
+		return ctrl.Result{RequeueAfter: time.Second}, nil
+	}
+
+	invalidGroupIDs, err := r.getInvalidSecurityGroups(ctx, sgp.Spec.SecurityGroups.Groups)
+	if err != nil {
+		r.log.Error(err, "failed to validate security groups, will retry", "sgp", req.NamespacedName)
+		return ctrl.Result{}, err
+	}
+
+	matchedPods, err := r.getMatchedPodCount(ctx, sgp)
+	if err != nil {
+		r.log.Error(err, "failed to count pods matching policy, will retry", "sgp", req.NamespacedName)
+		return ctrl.Result{}, err
+	}
+
+	sgpCopy := sgp.DeepCopy()
+	sgpCopy.Status.InvalidGroupIds = invalidGroupIDs
+	sgpCopy.Status.MatchedPods = matchedPods
+	setSecurityGroupPolicyConditions(sgpCopy, invalidGroupIDs, r.vpcId)
+
+	if !equality.Semantic.DeepEqual(sgp.Status, sgpCopy.Status) {
+		if err := r.Client.Status().Patch(ctx, sgpCopy, client.MergeFrom(sgp)); err != nil {
+			r.log.Error(err, "failed to patch security group policy status", "sgp", req.NamespacedName)
+			return ctrl.Result{}, err
+		}
+		r.log.V(1).Info("updated security group policy status", "sgp", req.NamespacedName,
+			"invalid security groups", invalidGroupIDs, "matched pods", matchedPods)
+	}
+
+	return ctrl.Result{RequeueAfter: config.SecurityGroupPolicyStatusUpdateInterval}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SecurityGroupPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		// Status updates must not trigger another reconcile of the policy
+		For(&v1beta1.SecurityGroupPolicy{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
+
+// getInvalidSecurityGroups returns the list of security groups that don't exist or don't belong to the cluster VPC
+func (r *SecurityGroupPolicyReconciler) getInvalidSecurityGroups(ctx context.Context, groupIDs []string) ([]string, error) {
+	groupIDs = utils.RemoveDuplicatedSg(groupIDs)
+	if len(groupIDs) == 0 {
+		return nil, nil
+	}
+
+	securityGroups, err := r.ec2APIHelper.GetSecurityGroups(ctx, groupIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	validGroupIDs := make(map[string]struct{})
+	for _, sg := range securityGroups {
+		if aws.ToString(sg.VpcId) == r.vpcId {
+			validGroupIDs[aws.ToString(sg.GroupId)] = struct{}{}
+		}
+	}
+
+	var invalidGroupIDs []string
+	for _, groupID := range groupIDs {
+		if _, ok := validGroupIDs[groupID]; !ok {
+			invalidGroupIDs = append(invalidGroupIDs, groupID)
+		}
+	}
+	return invalidGroupIDs, nil
+}
+
+// getMatchedPodCount returns the number of running pods in the policy namespace that get the security groups of the
+// policy, the pods matching the policy selectors are not counted if a higher priority policy with the
+// HighestPriorityWins merge mode overrides the policy
+func (r *SecurityGroupPolicyReconciler) getMatchedPodCount(ctx context.Context, sgp *v1beta1.SecurityGroupPolicy) (int, error) {
+	// Policy without any selector doesn't match any pod
+	if sgp.Spec.PodSelector == nil && sgp.Spec.ServiceAccountSelector == nil {
+		return 0, nil
+	}
+
+	podList, err := r.podAPI.ListPodsInNamespace(sgp.Namespace)
+	if err != nil {
+		return 0, err
+	}
+
+	policyName := client.ObjectKeyFromObject(sgp).String()
+	matchedPods := 0
+	serviceAccounts := make(map[string]*corev1.ServiceAccount)
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+
+		sa, ok := serviceAccounts[pod.Spec.ServiceAccountName]
+		if !ok {
+			sa = &corev1.ServiceAccount{}
+			key := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Spec.ServiceAccountName}
+			if err := r.Client.Get(ctx, key, sa); err != nil && !apierrors.IsNotFound(err) {
+				return 0, err
+			}
+			serviceAccounts[pod.Spec.ServiceAccountName] = sa
+		}
+
+		// Only the pods matching the policy selectors are resolved against all the policies
+		matched, err := utils.IsPodMatchingSecurityGroupPolicy(&sgp.Spec, pod, sa)
+		if err != nil {
+			r.log.Info("policy has an invalid selector and will not match any pod",
+				"sgp", client.ObjectKeyFromObject(sgp), "error", err)
+			return 0, nil
+		}
+		if !matched {
+			continue
+		}
+
+		// The resolution is returned along with the error if the security groups exceed the limit
+		resolution, err := r.sgpAPI.ResolveSecurityGroupsForPod(pod)
+		if resolution == nil {
+			if apierrors.IsNotFound(err) {
+				// The service account of the pod is deleted
+				continue
+			}
+			return 0, err
+		}
+		for _, policy := range resolution.AppliedPolicies {
+			if policy.Name == policyName {
+				matchedPods++
+				break
+			}
+		}
+	}
+	return matchedPods, nil
+}
+
+// setSecurityGroupPolicyConditions sets the Ready and Invalid conditions on the policy status
+func setSecurityGroupPolicyConditions(sgp *v1beta1.SecurityGroupPolicy, invalidGroupIDs []string, vpcID string) {
+	readyCondition := metav1.Condition{
+		Type:               v1beta1.SecurityGroupPolicyConditionReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: sgp.Generation,
+		Reason:             ReasonSecurityGroupsValid,
+		Message:            fmt.Sprintf("all security groups exist in VPC %s", vpcID),
+	}
+	invalidCondition := metav1.Condition{
+		Type:               v1beta1.SecurityGroupPolicyConditionInvalid,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: sgp.Generation,
+		Reason:             ReasonSecurityGroupsValid,
+		Message:            readyCondition.Message,
+	}
+
+	if len(invalidGroupIDs) > 0 {
+		message := fmt.Sprintf("security groups %v do not exist in VPC %s", invalidGroupIDs, vpcID)
+		readyCondition.Status = metav1.ConditionFalse
+		readyCondition.Reason = ReasonInvalidSecurityGroups
+		readyCondition.Message = message
+		invalidCondition.Status = metav1.ConditionTrue
+		invalidCondition.Reason = ReasonInvalidSecurityGroups
+		invalidCondition.Message = message
+	}
+
+	meta.SetStatusCondition(&sgp.Status.Conditions, readyCondition)
+	meta.SetStatusCondition(&sgp.Status.Conditions, invalidCondition)
+}
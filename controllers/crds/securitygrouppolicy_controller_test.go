@@ -0,0 +1,262 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package crds
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-vpc-resource-controller-k8s/apis/vpcresources/v1beta1"
+	mock_api "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/aws/ec2/api"
+	mock_condition "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/condition"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/k8s/pod"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/utils"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var (
+	mockVPCID        = "vpc-000000000000"
+	mockSGPNamespace = "default"
+	mockSGPName      = "sgp"
+	mockSGP          = &v1beta1.SecurityGroupPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       mockSGPName,
+			Namespace:  mockSGPNamespace,
+			Generation: 2,
+		},
+		Spec: v1beta1.SecurityGroupPolicySpec{
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"role": "db"}},
+			ServiceAccountSelector: &v1beta1.ServiceAccountSelector{
+				LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"role": "db"}},
+			},
+			SecurityGroups: v1beta1.GroupIds{Groups: []string{"sg-1", "sg-2", "sg-3"}},
+		},
+	}
+	mockSA = &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "db-sa",
+			Namespace: mockSGPNamespace,
+			Labels:    map[string]string{"role": "db"},
+		},
+	}
+	sgpReconcileRequest = reconcile.Request{
+		NamespacedName: types.NamespacedName{Namespace: mockSGPNamespace, Name: mockSGPName},
+	}
+	mockSecurityGroupIDs = []string{"sg-1", "sg-2", "sg-3"}
+)
+
+func newMockPod(name string, labels map[string]string, serviceAccount string, phase corev1.PodPhase) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: mockSGPNamespace,
+			Labels:    labels,
+		},
+		Spec:   corev1.PodSpec{ServiceAccountName: serviceAccount},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+}
+
+// newPodDataStore returns the pod data store of the pod controller with the stripped down pods of the objects
+func newPodDataStore(mockObjects ...client.Object) cache.Indexer {
+	converter := pod.PodConverter{}
+	dataStore := cache.NewIndexer(converter.Indexer, pod.Indexers())
+	for _, obj := range mockObjects {
+		if mockPod, ok := obj.(*corev1.Pod); ok {
+			_ = dataStore.Add(converter.StripDownPod(mockPod))
+		}
+	}
+	return dataStore
+}
+
+func NewSecurityGroupPolicyReconcilerMock(ctrl *gomock.Controller, mockObjects ...client.Object) (*SecurityGroupPolicyReconciler,
+	*mock_api.MockEC2APIHelper) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = v1beta1.AddToScheme(scheme)
+	client := fakeClient.NewClientBuilder().WithScheme(scheme).WithObjects(mockObjects...).
+		WithStatusSubresource(&v1beta1.SecurityGroupPolicy{}).Build()
+	mockEC2APIHelper := mock_api.NewMockEC2APIHelper(ctrl)
+	mockConditions := mock_condition.NewMockConditions(ctrl)
+	mockConditions.EXPECT().GetPodDataStoreSyncStatus().Return(true).AnyTimes()
+	sgpAPI := utils.NewSecurityGroupForPodsAPI(client, config.DefaultMaxSecurityGroupsPerENI, zap.New())
+	return NewSecurityGroupPolicyReconciler(client, pod.NewPodAPIWrapper(newPodDataStore(mockObjects...), client, nil),
+		mockConditions, zap.New(), mockEC2APIHelper, sgpAPI, mockVPCID), mockEC2APIHelper
+}
+
+func TestSecurityGroupPolicyReconcile(t *testing.T) {
+	matchingPods := []client.Object{
+		newMockPod("db-1", map[string]string{"role": "db"}, "db-sa", corev1.PodRunning),
+		newMockPod("db-2", map[string]string{"role": "db"}, "db-sa", corev1.PodPending),
+		// Completed pod, labels mismatch and service account mismatch must not be counted
+		newMockPod("db-3", map[string]string{"role": "db"}, "db-sa", corev1.PodSucceeded),
+		newMockPod("web-1", map[string]string{"role": "web"}, "db-sa", corev1.PodRunning),
+		newMockPod("db-4", map[string]string{"role": "db"}, "default", corev1.PodRunning),
+	}
+
+	tests := []struct {
+		name    string
+		objects []client.Object
+		prepare func(mockEC2APIHelper *mock_api.MockEC2APIHelper)
+		asserts func(res reconcile.Result, err error, sgp *v1beta1.SecurityGroupPolicy)
+	}{
+		{
+			name:    "verify policy is ready when all security groups are in the cluster VPC",
+			objects: append([]client.Object{mockSGP.DeepCopy(), mockSA}, matchingPods...),
+			prepare: func(mockEC2APIHelper *mock_api.MockEC2APIHelper) {
+				mockEC2APIHelper.EXPECT().GetSecurityGroups(gomock.Any(), mockSecurityGroupIDs).Return(
+					[]ec2types.SecurityGroup{
+						{GroupId: aws.String("sg-1"), VpcId: aws.String(mockVPCID)},
+						{GroupId: aws.String("sg-2"), VpcId: aws.String(mockVPCID)},
+						{GroupId: aws.String("sg-3"), VpcId: aws.String(mockVPCID)},
+					}, nil)
+			},
+			asserts: func(res reconcile.Result, err error, sgp *v1beta1.SecurityGroupPolicy) {
+				assert.NoError(t, err)
+				assert.Equal(t, config.SecurityGroupPolicyStatusUpdateInterval, res.RequeueAfter)
+				assert.Empty(t, sgp.Status.InvalidGroupIds)
+				assert.Equal(t, 2, sgp.Status.MatchedPods)
+				assert.True(t, meta.IsStatusConditionTrue(sgp.Status.Conditions, v1beta1.SecurityGroupPolicyConditionReady))
+				assert.True(t, meta.IsStatusConditionFalse(sgp.Status.Conditions, v1beta1.SecurityGroupPolicyConditionInvalid))
+				assert.Equal(t, int64(2), meta.FindStatusCondition(sgp.Status.Conditions,
+					v1beta1.SecurityGroupPolicyConditionReady).ObservedGeneration)
+			},
+		},
+		{
+			name: "verify pods whose security groups are overridden by a higher priority policy are not counted",
+			objects: append([]client.Object{mockSGP.DeepCopy(), mockSA, &v1beta1.SecurityGroupPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "sgp-override", Namespace: mockSGPNamespace},
+				Spec: v1beta1.SecurityGroupPolicySpec{
+					PodSelector:    &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "critical"}},
+					Priority:       10,
+					MergeMode:      v1beta1.MergeModeHighestPriorityWins,
+					SecurityGroups: v1beta1.GroupIds{Groups: []string{"sg-4"}},
+				},
+			}, &v1beta1.SecurityGroupPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "sgp-union", Namespace: mockSGPNamespace},
+				Spec: v1beta1.SecurityGroupPolicySpec{
+					PodSelector:    &metav1.LabelSelector{MatchLabels: map[string]string{"role": "db"}},
+					Priority:       5,
+					SecurityGroups: v1beta1.GroupIds{Groups: []string{"sg-5"}},
+				},
+			}, newMockPod("db-5", map[string]string{"role": "db", "tier": "critical"}, "db-sa", corev1.PodRunning)},
+				matchingPods...),
+			prepare: func(mockEC2APIHelper *mock_api.MockEC2APIHelper) {
+				mockEC2APIHelper.EXPECT().GetSecurityGroups(gomock.Any(), mockSecurityGroupIDs).Return(
+					[]ec2types.SecurityGroup{
+						{GroupId: aws.String("sg-1"), VpcId: aws.String(mockVPCID)},
+						{GroupId: aws.String("sg-2"), VpcId: aws.String(mockVPCID)},
+						{GroupId: aws.String("sg-3"), VpcId: aws.String(mockVPCID)},
+					}, nil)
+			},
+			asserts: func(res reconcile.Result, err error, sgp *v1beta1.SecurityGroupPolicy) {
+				assert.NoError(t, err)
+				// db-5 only gets the security groups of sgp-override, db-1 and db-2 get the merged security groups
+				// of sgp-union and the policy
+				assert.Equal(t, 2, sgp.Status.MatchedPods)
+			},
+		},
+		{
+			name:    "verify policy is invalid when security groups are missing or in a different VPC",
+			objects: []client.Object{mockSGP.DeepCopy(), mockSA},
+			prepare: func(mockEC2APIHelper *mock_api.MockEC2APIHelper) {
+				mockEC2APIHelper.EXPECT().GetSecurityGroups(gomock.Any(), mockSecurityGroupIDs).Return(
+					[]ec2types.SecurityGroup{
+						{GroupId: aws.String("sg-1"), VpcId: aws.String(mockVPCID)},
+						{GroupId: aws.String("sg-2"), VpcId: aws.String("vpc-111111111111")},
+					}, nil)
+			},
+			asserts: func(res reconcile.Result, err error, sgp *v1beta1.SecurityGroupPolicy) {
+				assert.NoError(t, err)
+				assert.Equal(t, []string{"sg-2", "sg-3"}, sgp.Status.InvalidGroupIds)
+				assert.Equal(t, 0, sgp.Status.MatchedPods)
+				assert.True(t, meta.IsStatusConditionFalse(sgp.Status.Conditions, v1beta1.SecurityGroupPolicyConditionReady))
+				invalidCondition := meta.FindStatusCondition(sgp.Status.Conditions, v1beta1.SecurityGroupPolicyConditionInvalid)
+				assert.Equal(t, metav1.ConditionTrue, invalidCondition.Status)
+				assert.Equal(t, ReasonInvalidSecurityGroups, invalidCondition.Reason)
+			},
+		},
+		{
+			name:    "verify status is not updated and request is retried when EC2 call fails",
+			objects: []client.Object{mockSGP.DeepCopy(), mockSA},
+			prepare: func(mockEC2APIHelper *mock_api.MockEC2APIHelper) {
+				mockEC2APIHelper.EXPECT().GetSecurityGroups(gomock.Any(), mockSecurityGroupIDs).Return(nil, errors.New("mock error"))
+			},
+			asserts: func(res reconcile.Result, err error, sgp *v1beta1.SecurityGroupPolicy) {
+				assert.Error(t, err)
+				assert.Empty(t, sgp.Status.Conditions)
+			},
+		},
+		{
+			name:    "verify not found policy is ignored",
+			objects: []client.Object{},
+			prepare: func(mockEC2APIHelper *mock_api.MockEC2APIHelper) {},
+			asserts: func(res reconcile.Result, err error, sgp *v1beta1.SecurityGroupPolicy) {
+				assert.NoError(t, err)
+				assert.Equal(t, reconcile.Result{}, res)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			reconciler, mockEC2APIHelper := NewSecurityGroupPolicyReconcilerMock(ctrl, tt.objects...)
+			tt.prepare(mockEC2APIHelper)
+
+			res, err := reconciler.Reconcile(context.Background(), sgpReconcileRequest)
+			sgp := &v1beta1.SecurityGroupPolicy{}
+			_ = reconciler.Client.Get(context.Background(), sgpReconcileRequest.NamespacedName, sgp)
+			tt.asserts(res, err, sgp)
+		})
+	}
+}
+
+// TestSecurityGroupPolicyReconcile_DataStoreNotSynced tests the policy is requeued without updating the status until
+// the pod data store has synced
+func TestSecurityGroupPolicyReconcile_DataStoreNotSynced(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	reconciler, _ := NewSecurityGroupPolicyReconcilerMock(ctrl, mockSGP.DeepCopy(), mockSA)
+	mockConditions := mock_condition.NewMockConditions(ctrl)
+	mockConditions.EXPECT().GetPodDataStoreSyncStatus().Return(false)
+	reconciler.conditions = mockConditions
+
+	res, err := reconciler.Reconcile(context.Background(), sgpReconcileRequest)
+	assert.NoError(t, err)
+	assert.Equal(t, reconcile.Result{RequeueAfter: time.Second}, res)
+
+	sgp := &v1beta1.SecurityGroupPolicy{}
+	assert.NoError(t, reconciler.Client.Get(context.Background(), sgpReconcileRequest.NamespacedName, sgp))
+	assert.Empty(t, sgp.Status.Conditions)
+}
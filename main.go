@@ -14,6 +14,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"net/http"
@@ -36,9 +37,13 @@ import (
 	rcHealthz "github.com/aws/amazon-vpc-resource-controller-k8s/pkg/healthz"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/k8s"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/k8s/pod"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/lookup"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/node/manager"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider/branch/cooldown"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/resource"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/subnet"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/timetonetwork"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/tracing"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/utils"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/version"
 	asyncWorkers "github.com/aws/amazon-vpc-resource-controller-k8s/pkg/worker"
@@ -72,6 +77,8 @@ var (
 	setupLog          = ctrl.Log.WithName("setup")
 	syncPeriod        = time.Minute * 30
 	regionPlaceHolder = "CLUSTER_REGION"
+	// tracingShutdownTimeout is how long the remaining spans are exported for when the controller stops
+	tracingShutdownTimeout = time.Second * 5
 )
 
 func init() {
@@ -90,7 +97,12 @@ func init() {
 // +kubebuilder:rbac:groups=apps,resources=deployments,namespace=kube-system,resourceNames=vpc-resource-controller,verbs=get;list;watch
 // +kubebuilder:rbac:groups=crd.k8s.amazonaws.com,resources=eniconfigs,verbs=get;list;watch
 // +kubebuilder:rbac:groups=vpcresources.k8s.aws,resources=securitygrouppolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=vpcresources.k8s.aws,resources=clustersecuritygrouppolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
 // +kubebuilder:rbac:groups=vpcresources.k8s.aws,resources=cninodes,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=vpcresources.k8s.aws,resources=cninodes/status,verbs=get;patch
+// +kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
 
 // Migration to leases based leader election
 // +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,namespace=kube-system,verbs=create
@@ -109,8 +121,13 @@ func main() {
 	var leaderLeaseRetryPeriod int
 	var outputPath string
 	var introspectBindAddr string
+	var introspectCertFile string
+	var introspectKeyFile string
+	var introspectEnableAuth bool
 	var healthCheckTimeout int
 	var enableWindowsPrefixDelegation bool
+	var enableIPv6PrefixDelegation bool
+	var ipv6AddressesPerPrefix int
 	var region string
 	var vpcID string
 	var nodeWorkerCount int
@@ -123,6 +140,13 @@ func main() {
 	var maxPodConcurrentReconciles int
 	var maxNodeConcurrentReconciles int
 	var disableController bool
+	var maxSecurityGroupsPerENI int
+	var branchENIWarmPoolSize int
+	var branchENIWarmPoolMaxDeviation int
+	var ec2DescribeBatchWindow time.Duration
+	var ec2CacheConfig ec2API.CacheConfig
+	var tracingConfig tracing.Config
+	var timeToNetworkSLO time.Duration
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080",
 		"The address the metric endpoint binds to.")
@@ -154,8 +178,19 @@ func main() {
 		"How long healthz check waits before failing the attempt")
 	flag.StringVar(&introspectBindAddr, "introspect-bind-addr", ":22775",
 		"Port for serving the introspection API")
+	flag.StringVar(&introspectCertFile, "introspect-tls-cert-file", "",
+		"The TLS certificate file for serving the introspection API over HTTPS, requires introspect-tls-key-file")
+	flag.StringVar(&introspectKeyFile, "introspect-tls-key-file", "",
+		"The TLS key file for serving the introspection API over HTTPS, requires introspect-tls-cert-file")
+	flag.BoolVar(&introspectEnableAuth, "introspect-enable-auth", false,
+		"Authenticate the bearer token of the introspection API requests with a Kubernetes TokenReview and authorize "+
+			"the user to get the request path with a SubjectAccessReview")
 	flag.BoolVar(&enableWindowsPrefixDelegation, "enable-windows-prefix-delegation", false,
 		"Enable the feature flag for Windows prefix delegation")
+	flag.BoolVar(&enableIPv6PrefixDelegation, "enable-ipv6-prefix-delegation", false,
+		"Enable the feature flag for IPv6 prefix delegation on nodes with the IPv6PrefixDelegation CNINode feature")
+	flag.IntVar(&ipv6AddressesPerPrefix, "ipv6-addresses-per-prefix", config.IPv6PDDefaultAddressesPerPrefix,
+		"The number of IPv6 addresses handed out to pods from each /80 IPv6 prefix")
 	flag.StringVar(&region, "aws-region", "", "The aws region of the k8s cluster")
 	flag.StringVar(&vpcID, "vpc-id", "", "The VPC ID where EKS cluster is deployed")
 	flag.IntVar(&nodeWorkerCount, "node-mgr-workers", 10, "The number of node workers")
@@ -170,6 +205,29 @@ func main() {
 	flag.IntVar(&maxPodConcurrentReconciles, "max-pod-reconcile", 20, "The maximum number of concurrent reconciles for pod controller")
 	flag.IntVar(&maxNodeConcurrentReconciles, "max-node-reconcile", 10, "The maximum number of concurrent reconciles for node controller")
 	flag.BoolVar(&disableController, "disable-controller", false, "A flag to disable the controller.")
+	flag.IntVar(&maxSecurityGroupsPerENI, "max-security-groups-per-eni", config.DefaultMaxSecurityGroupsPerENI,
+		"The maximum number of security groups that can be resolved for a pod, defaults to the EC2 quota of security "+
+			"groups per network interface. Set to the quota of the account if it was increased, or to 0 to disable the check")
+	flag.IntVar(&branchENIWarmPoolSize, "branch-eni-warm-pool-size", 0,
+		"The number of warm branch ENIs to keep per set of security groups on each trunk ENI, set to 0 to disable the warm pool")
+	flag.IntVar(&branchENIWarmPoolMaxDeviation, "branch-eni-warm-pool-max-deviation", 0,
+		"The maximum number by which the branch ENI warm pool can deviate from the warm pool size before it's reconciled")
+	flag.DurationVar(&ec2DescribeBatchWindow, "ec2-describe-batch-window", 20*time.Millisecond,
+		"How long describe requests for instances, subnets and branch ENIs wait to be batched into a single EC2 call, set to 0 to disable batching")
+	flag.DurationVar(&ec2CacheConfig.SubnetTTL, "ec2-subnet-cache-ttl", 5*time.Minute,
+		"How long the described subnets are cached, set to 0 to disable caching")
+	flag.DurationVar(&ec2CacheConfig.SecurityGroupTTL, "ec2-security-group-cache-ttl", time.Minute,
+		"How long the described security groups are cached, set to 0 to disable caching")
+	flag.DurationVar(&ec2CacheConfig.InstanceTTL, "ec2-instance-cache-ttl", 30*time.Second,
+		"How long the described instances are cached, set to 0 to disable caching")
+	flag.StringVar(&tracingConfig.Endpoint, "otlp-endpoint", "",
+		"The host:port of the OTLP HTTP collector the allocation spans are exported to, tracing is disabled if not set")
+	flag.BoolVar(&tracingConfig.Insecure, "otlp-insecure", false,
+		"Export the spans to the OTLP collector over HTTP instead of HTTPS")
+	flag.Float64Var(&tracingConfig.SampleRatio, "trace-sample-ratio", 0.1,
+		"The ratio of the pod reconciles that are traced, between 0 and 1")
+	flag.DurationVar(&timeToNetworkSLO, "time-to-network-slo", 30*time.Second,
+		"The time from a pod being scheduled to being annotated with its resources after which a warning event is broadcast on the pod, set to 0 to disable the events")
 
 	flag.Parse()
 
@@ -217,6 +275,28 @@ func main() {
 		os.Exit(1)
 	}
 
+	if (introspectCertFile == "") != (introspectKeyFile == "") {
+		setupLog.Error(fmt.Errorf("introspect-tls-cert-file and introspect-tls-key-file must be set together"),
+			"unable to start the controller")
+		os.Exit(1)
+	}
+
+	if ipv6AddressesPerPrefix < 1 || ipv6AddressesPerPrefix > config.IPv6PDMaxAddressesPerPrefix {
+		setupLog.Error(fmt.Errorf("ipv6-addresses-per-prefix must be between 1 and %d",
+			config.IPv6PDMaxAddressesPerPrefix), "unable to start the controller")
+		os.Exit(1)
+	}
+
+	if maxSecurityGroupsPerENI < 0 {
+		setupLog.Error(fmt.Errorf("max-security-groups-per-eni must not be negative"), "unable to start the controller")
+		os.Exit(1)
+	}
+
+	if tracingConfig.SampleRatio < 0 || tracingConfig.SampleRatio > 1 {
+		setupLog.Error(fmt.Errorf("trace-sample-ratio must be between 0 and 1"), "unable to start the controller")
+		os.Exit(1)
+	}
+
 	// Profiler disabled by default, to enable set the enableProfiling argument
 	if enableProfiling {
 		// To use the profiler - https://golang.org/pkg/net/http/pprof/
@@ -293,6 +373,12 @@ func main() {
 
 	ctx := ctrl.SetupSignalHandler()
 
+	shutdownTracing, err := tracing.Init(ctx, tracingConfig)
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+
 	// if the region wasn't replaced from place holder
 	// we need to make it to empty
 	if region == regionPlaceHolder {
@@ -320,16 +406,19 @@ func main() {
 		setupLog.Info("vpc resource controller is disabled")
 		featureGauge.Set(float64(0))
 	} else {
-		ec2APIHelper := ec2API.NewEC2APIHelper(ec2Wrapper, clusterName)
+		batchingEC2APIHelper := ec2API.NewBatchingEC2APIHelper(ec2API.NewEC2APIHelper(ec2Wrapper, clusterName),
+			ec2Wrapper, ec2DescribeBatchWindow)
+		ec2APIHelper := ec2API.NewCachingEC2APIHelper(batchingEC2APIHelper, ec2CacheConfig)
 
 		sgpAPI := utils.NewSecurityGroupForPodsAPI(
 			mgr.GetClient(),
+			maxSecurityGroupsPerENI,
 			ctrl.Log.WithName("sgp api"))
 
 		// Custom data store, with optimized Pod Object. The data store must be
 		// accessed only after the Pod Reconciler has started
 		podConverter := pod.PodConverter{}
-		dataStore := clientgocache.NewIndexer(podConverter.Indexer, pod.NodeNameIndexer())
+		dataStore := clientgocache.NewIndexer(podConverter.Indexer, pod.Indexers())
 
 		apiWrapper := api.Wrapper{
 			EC2API: ec2APIHelper,
@@ -352,8 +441,32 @@ func main() {
 		} else {
 			supportedResources = []string{config.ResourceNamePodENI, config.ResourceNameIPAddress}
 		}
+		// when IPv6 PD feature flag is ON, initialize resource for IPv6 addresses from /80 prefixes
+		if enableIPv6PrefixDelegation {
+			supportedResources = append(supportedResources, config.ResourceNameIPv6Address)
+		}
+		resourceConfig := config.LoadResourceConfig()
+		resourceConfig[config.ResourceNameIPv6Address].WarmPoolConfig.AddressesPerPrefix = ipv6AddressesPerPrefix
+		if branchENIWarmPoolSize > 0 {
+			podENIConfig := resourceConfig[config.ResourceNamePodENI]
+			podENIConfig.WarmPoolConfig = &config.WarmPoolConfig{
+				DesiredSize:  branchENIWarmPoolSize,
+				MaxDeviation: branchENIWarmPoolMaxDeviation,
+			}
+			resourceConfig[config.ResourceNamePodENI] = podENIConfig
+		}
+		// The lookup index of the introspection API records the pods the resources are assigned to by the providers
+		lookupIndex := lookup.NewIndex(config.LookupIndexRetention)
+		if err := mgr.Add(lookupIndex); err != nil {
+			setupLog.Error(err, "unable to add the lookup index")
+			os.Exit(1)
+		}
+		// The pod controller tracks the pods waiting for the resources and the handlers observe the wait once they
+		// annotate the pods
+		timeToNetwork := timetonetwork.NewTracker(k8sApi, timeToNetworkSLO)
 		resourceManager, err := resource.NewResourceManager(
-			ctx, supportedResources, apiWrapper, ctrl.Log.WithName("managers").WithName("resource"), healthzHandler, controllerConditions)
+			ctx, supportedResources, resourceConfig, apiWrapper, ctrl.Log.WithName("managers").WithName("resource"), healthzHandler, controllerConditions,
+			lookupIndex, timeToNetwork)
 		if err != nil {
 			ctrl.Log.Error(err, "failed to init resources", "resources", supportedResources)
 			os.Exit(1)
@@ -378,6 +491,7 @@ func main() {
 			K8sAPI:          k8sApi,
 			DataStore:       dataStore,
 			Condition:       controllerConditions,
+			TimeToNetwork:   timeToNetwork,
 		}).SetupWithManager(ctx, mgr, clientSet, listPageLimit, syncPeriod, maxPodConcurrentReconciles, healthzHandler); err != nil {
 			setupLog.Error(err, "unable to create controller", "controller", "pod")
 			os.Exit(1)
@@ -419,11 +533,21 @@ func main() {
 			os.Exit(1)
 		}
 
-		if err := (&resource.IntrospectHandler{
+		introspectHandler := &resource.IntrospectHandler{
 			Log:             ctrl.Log.WithName("introspect"),
 			BindAddress:     introspectBindAddr,
 			ResourceManager: resourceManager,
-		}).SetupWithManager(mgr, healthzHandler); err != nil {
+			LookupIndex:     lookupIndex,
+			K8sAPI:          k8sApi,
+			PodAPI:          apiWrapper.PodAPI,
+			CertFile:        introspectCertFile,
+			KeyFile:         introspectKeyFile,
+		}
+		if introspectEnableAuth {
+			introspectHandler.TokenReviewer = clientSet.AuthenticationV1().TokenReviews()
+			introspectHandler.AccessReviewer = clientSet.AuthorizationV1().SubjectAccessReviews()
+		}
+		if err := introspectHandler.SetupWithManager(mgr, healthzHandler); err != nil {
 			setupLog.Error(err, "unable to create introspect API")
 			os.Exit(1)
 		}
@@ -441,17 +565,57 @@ func main() {
 			finalizerManager,
 			maxNodeConcurrentReconciles,
 			cleanup.NewNodeResourceCleaner,
+			resourceManager,
 		).SetupWithManager(mgr, maxNodeConcurrentReconciles)); err != nil {
 			setupLog.Error(err, "unable to create controller", "controller", "CNINode")
 			os.Exit(1)
 		}
+
+		if err = (crdcontroller.NewSecurityGroupPolicyReconciler(
+			mgr.GetClient(),
+			apiWrapper.PodAPI,
+			controllerConditions,
+			ctrl.Log.WithName("controllers").WithName("SecurityGroupPolicy"),
+			ec2APIHelper,
+			sgpAPI,
+			vpcID,
+		).SetupWithManager(mgr)); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "SecurityGroupPolicy")
+			os.Exit(1)
+		}
+
+		if err = (crdcontroller.NewBranchENISecurityGroupReconciler(
+			mgr.GetClient(),
+			apiWrapper.PodAPI,
+			controllerConditions,
+			ctrl.Log.WithName("controllers").WithName("BranchENISecurityGroup"),
+			ec2APIHelper,
+			k8sApi,
+			sgpAPI,
+			resourceManager,
+		).SetupWithManager(mgr)); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "BranchENISecurityGroup")
+			os.Exit(1)
+		}
+
+		if err := (&subnet.Monitor{
+			Log:             ctrl.Log.WithName("subnet monitor"),
+			EC2APIHelper:    ec2APIHelper,
+			K8sAPI:          k8sApi,
+			NodeLister:      nodeManager,
+			ResourceManager: resourceManager,
+		}).SetupWithManager(mgr, healthzHandler); err != nil {
+			setupLog.Error(err, "unable to start subnet monitor")
+			os.Exit(1)
+		}
 		// +kubebuilder:scaffold:builder
 		setupLog.Info("setting up webhook server")
 		webhookServer := mgr.GetWebhookServer()
 
 		setupLog.Info("registering webhooks to the webhook server")
 		podMutationWebhook := webhookcore.NewPodMutationWebHook(
-			sgpAPI, ctrl.Log.WithName("resource mutating webhook"), controllerConditions, admission.NewDecoder(mgr.GetScheme()), healthzHandler)
+			sgpAPI, ctrl.Log.WithName("resource mutating webhook"), controllerConditions, admission.NewDecoder(mgr.GetScheme()), healthzHandler,
+			enableIPv6PrefixDelegation)
 		webhookServer.Register("/mutate-v1-pod", &webhook.Admission{
 			Handler: podMutationWebhook,
 		})
@@ -495,7 +659,16 @@ func main() {
 	}
 
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctx); err != nil {
+	err = mgr.Start(ctx)
+
+	// Flush the spans that are not exported yet, the manager context is already cancelled
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+	if errShutdown := shutdownTracing(shutdownCtx); errShutdown != nil {
+		setupLog.Error(errShutdown, "failed to export the remaining spans")
+	}
+	cancel()
+
+	if err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
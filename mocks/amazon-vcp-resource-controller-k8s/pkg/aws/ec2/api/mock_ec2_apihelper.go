@@ -18,6 +18,7 @@
 package mock_api
 
 import (
+	context "context"
 	reflect "reflect"
 
 	config "github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
@@ -50,168 +51,183 @@ func (m *MockEC2APIHelper) EXPECT() *MockEC2APIHelperMockRecorder {
 }
 
 // AssignIPv4ResourcesAndWaitTillReady mocks base method.
-func (m *MockEC2APIHelper) AssignIPv4ResourcesAndWaitTillReady(arg0 string, arg1 config.ResourceType, arg2 int) ([]string, error) {
+func (m *MockEC2APIHelper) AssignIPv4ResourcesAndWaitTillReady(arg0 context.Context, arg1 string, arg2 config.ResourceType, arg3 int) ([]string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AssignIPv4ResourcesAndWaitTillReady", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "AssignIPv4ResourcesAndWaitTillReady", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].([]string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AssignIPv4ResourcesAndWaitTillReady indicates an expected call of AssignIPv4ResourcesAndWaitTillReady.
-func (mr *MockEC2APIHelperMockRecorder) AssignIPv4ResourcesAndWaitTillReady(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockEC2APIHelperMockRecorder) AssignIPv4ResourcesAndWaitTillReady(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignIPv4ResourcesAndWaitTillReady", reflect.TypeOf((*MockEC2APIHelper)(nil).AssignIPv4ResourcesAndWaitTillReady), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignIPv4ResourcesAndWaitTillReady", reflect.TypeOf((*MockEC2APIHelper)(nil).AssignIPv4ResourcesAndWaitTillReady), arg0, arg1, arg2, arg3)
+}
+
+// AssignIPv6PrefixesAndWaitTillReady mocks base method.
+func (m *MockEC2APIHelper) AssignIPv6PrefixesAndWaitTillReady(arg0 context.Context, arg1 string, arg2 int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssignIPv6PrefixesAndWaitTillReady", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AssignIPv6PrefixesAndWaitTillReady indicates an expected call of AssignIPv6PrefixesAndWaitTillReady.
+func (mr *MockEC2APIHelperMockRecorder) AssignIPv6PrefixesAndWaitTillReady(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignIPv6PrefixesAndWaitTillReady", reflect.TypeOf((*MockEC2APIHelper)(nil).AssignIPv6PrefixesAndWaitTillReady), arg0, arg1, arg2)
 }
 
 // AssociateBranchToTrunk mocks base method.
-func (m *MockEC2APIHelper) AssociateBranchToTrunk(arg0, arg1 *string, arg2 int) (*ec2.AssociateTrunkInterfaceOutput, error) {
+func (m *MockEC2APIHelper) AssociateBranchToTrunk(arg0 context.Context, arg1, arg2 *string, arg3 int) (*ec2.AssociateTrunkInterfaceOutput, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AssociateBranchToTrunk", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "AssociateBranchToTrunk", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(*ec2.AssociateTrunkInterfaceOutput)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AssociateBranchToTrunk indicates an expected call of AssociateBranchToTrunk.
-func (mr *MockEC2APIHelperMockRecorder) AssociateBranchToTrunk(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockEC2APIHelperMockRecorder) AssociateBranchToTrunk(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssociateBranchToTrunk", reflect.TypeOf((*MockEC2APIHelper)(nil).AssociateBranchToTrunk), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssociateBranchToTrunk", reflect.TypeOf((*MockEC2APIHelper)(nil).AssociateBranchToTrunk), arg0, arg1, arg2, arg3)
 }
 
 // AttachNetworkInterfaceToInstance mocks base method.
-func (m *MockEC2APIHelper) AttachNetworkInterfaceToInstance(arg0, arg1 *string, arg2 *int32) (*string, error) {
+func (m *MockEC2APIHelper) AttachNetworkInterfaceToInstance(arg0 context.Context, arg1, arg2 *string, arg3 *int32) (*string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AttachNetworkInterfaceToInstance", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "AttachNetworkInterfaceToInstance", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(*string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AttachNetworkInterfaceToInstance indicates an expected call of AttachNetworkInterfaceToInstance.
-func (mr *MockEC2APIHelperMockRecorder) AttachNetworkInterfaceToInstance(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockEC2APIHelperMockRecorder) AttachNetworkInterfaceToInstance(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttachNetworkInterfaceToInstance", reflect.TypeOf((*MockEC2APIHelper)(nil).AttachNetworkInterfaceToInstance), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttachNetworkInterfaceToInstance", reflect.TypeOf((*MockEC2APIHelper)(nil).AttachNetworkInterfaceToInstance), arg0, arg1, arg2, arg3)
 }
 
 // CreateAndAttachNetworkInterface mocks base method.
-func (m *MockEC2APIHelper) CreateAndAttachNetworkInterface(arg0, arg1 *string, arg2 []string, arg3 []types.Tag, arg4 *int32, arg5, arg6 *string, arg7 *config.IPResourceCount) (*types.NetworkInterface, error) {
+func (m *MockEC2APIHelper) CreateAndAttachNetworkInterface(arg0 context.Context, arg1, arg2 *string, arg3 []string, arg4 []types.Tag, arg5 *int32, arg6, arg7 *string, arg8 *config.IPResourceCount) (*types.NetworkInterface, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateAndAttachNetworkInterface", arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7)
+	ret := m.ctrl.Call(m, "CreateAndAttachNetworkInterface", arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8)
 	ret0, _ := ret[0].(*types.NetworkInterface)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateAndAttachNetworkInterface indicates an expected call of CreateAndAttachNetworkInterface.
-func (mr *MockEC2APIHelperMockRecorder) CreateAndAttachNetworkInterface(arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7 interface{}) *gomock.Call {
+func (mr *MockEC2APIHelperMockRecorder) CreateAndAttachNetworkInterface(arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAndAttachNetworkInterface", reflect.TypeOf((*MockEC2APIHelper)(nil).CreateAndAttachNetworkInterface), arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAndAttachNetworkInterface", reflect.TypeOf((*MockEC2APIHelper)(nil).CreateAndAttachNetworkInterface), arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8)
 }
 
 // CreateNetworkInterface mocks base method.
-func (m *MockEC2APIHelper) CreateNetworkInterface(arg0, arg1 *string, arg2 []string, arg3 []types.Tag, arg4 *config.IPResourceCount, arg5 *string) (*types.NetworkInterface, error) {
+func (m *MockEC2APIHelper) CreateNetworkInterface(arg0 context.Context, arg1, arg2 *string, arg3 []string, arg4 []types.Tag, arg5 *config.IPResourceCount, arg6 *string) (*types.NetworkInterface, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateNetworkInterface", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret := m.ctrl.Call(m, "CreateNetworkInterface", arg0, arg1, arg2, arg3, arg4, arg5, arg6)
 	ret0, _ := ret[0].(*types.NetworkInterface)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateNetworkInterface indicates an expected call of CreateNetworkInterface.
-func (mr *MockEC2APIHelperMockRecorder) CreateNetworkInterface(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+func (mr *MockEC2APIHelperMockRecorder) CreateNetworkInterface(arg0, arg1, arg2, arg3, arg4, arg5, arg6 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNetworkInterface", reflect.TypeOf((*MockEC2APIHelper)(nil).CreateNetworkInterface), arg0, arg1, arg2, arg3, arg4, arg5)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNetworkInterface", reflect.TypeOf((*MockEC2APIHelper)(nil).CreateNetworkInterface), arg0, arg1, arg2, arg3, arg4, arg5, arg6)
 }
 
 // DeleteNetworkInterface mocks base method.
-func (m *MockEC2APIHelper) DeleteNetworkInterface(arg0 *string) error {
+func (m *MockEC2APIHelper) DeleteNetworkInterface(arg0 context.Context, arg1 *string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteNetworkInterface", arg0)
+	ret := m.ctrl.Call(m, "DeleteNetworkInterface", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteNetworkInterface indicates an expected call of DeleteNetworkInterface.
-func (mr *MockEC2APIHelperMockRecorder) DeleteNetworkInterface(arg0 interface{}) *gomock.Call {
+func (mr *MockEC2APIHelperMockRecorder) DeleteNetworkInterface(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNetworkInterface", reflect.TypeOf((*MockEC2APIHelper)(nil).DeleteNetworkInterface), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNetworkInterface", reflect.TypeOf((*MockEC2APIHelper)(nil).DeleteNetworkInterface), arg0, arg1)
 }
 
 // DescribeNetworkInterfaces mocks base method.
-func (m *MockEC2APIHelper) DescribeNetworkInterfaces(arg0 []string) ([]types.NetworkInterface, error) {
+func (m *MockEC2APIHelper) DescribeNetworkInterfaces(arg0 context.Context, arg1 []string) ([]types.NetworkInterface, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DescribeNetworkInterfaces", arg0)
+	ret := m.ctrl.Call(m, "DescribeNetworkInterfaces", arg0, arg1)
 	ret0, _ := ret[0].([]types.NetworkInterface)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // DescribeNetworkInterfaces indicates an expected call of DescribeNetworkInterfaces.
-func (mr *MockEC2APIHelperMockRecorder) DescribeNetworkInterfaces(arg0 interface{}) *gomock.Call {
+func (mr *MockEC2APIHelperMockRecorder) DescribeNetworkInterfaces(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeNetworkInterfaces", reflect.TypeOf((*MockEC2APIHelper)(nil).DescribeNetworkInterfaces), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeNetworkInterfaces", reflect.TypeOf((*MockEC2APIHelper)(nil).DescribeNetworkInterfaces), arg0, arg1)
 }
 
 // DescribeTrunkInterfaceAssociation mocks base method.
-func (m *MockEC2APIHelper) DescribeTrunkInterfaceAssociation(arg0 *string) ([]types.TrunkInterfaceAssociation, error) {
+func (m *MockEC2APIHelper) DescribeTrunkInterfaceAssociation(arg0 context.Context, arg1 *string) ([]types.TrunkInterfaceAssociation, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DescribeTrunkInterfaceAssociation", arg0)
+	ret := m.ctrl.Call(m, "DescribeTrunkInterfaceAssociation", arg0, arg1)
 	ret0, _ := ret[0].([]types.TrunkInterfaceAssociation)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // DescribeTrunkInterfaceAssociation indicates an expected call of DescribeTrunkInterfaceAssociation.
-func (mr *MockEC2APIHelperMockRecorder) DescribeTrunkInterfaceAssociation(arg0 interface{}) *gomock.Call {
+func (mr *MockEC2APIHelperMockRecorder) DescribeTrunkInterfaceAssociation(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeTrunkInterfaceAssociation", reflect.TypeOf((*MockEC2APIHelper)(nil).DescribeTrunkInterfaceAssociation), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeTrunkInterfaceAssociation", reflect.TypeOf((*MockEC2APIHelper)(nil).DescribeTrunkInterfaceAssociation), arg0, arg1)
 }
 
 // DetachAndDeleteNetworkInterface mocks base method.
-func (m *MockEC2APIHelper) DetachAndDeleteNetworkInterface(arg0, arg1 *string) error {
+func (m *MockEC2APIHelper) DetachAndDeleteNetworkInterface(arg0 context.Context, arg1, arg2 *string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DetachAndDeleteNetworkInterface", arg0, arg1)
+	ret := m.ctrl.Call(m, "DetachAndDeleteNetworkInterface", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DetachAndDeleteNetworkInterface indicates an expected call of DetachAndDeleteNetworkInterface.
-func (mr *MockEC2APIHelperMockRecorder) DetachAndDeleteNetworkInterface(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockEC2APIHelperMockRecorder) DetachAndDeleteNetworkInterface(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetachAndDeleteNetworkInterface", reflect.TypeOf((*MockEC2APIHelper)(nil).DetachAndDeleteNetworkInterface), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetachAndDeleteNetworkInterface", reflect.TypeOf((*MockEC2APIHelper)(nil).DetachAndDeleteNetworkInterface), arg0, arg1, arg2)
 }
 
 // DetachNetworkInterfaceFromInstance mocks base method.
-func (m *MockEC2APIHelper) DetachNetworkInterfaceFromInstance(arg0 *string) error {
+func (m *MockEC2APIHelper) DetachNetworkInterfaceFromInstance(arg0 context.Context, arg1 *string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DetachNetworkInterfaceFromInstance", arg0)
+	ret := m.ctrl.Call(m, "DetachNetworkInterfaceFromInstance", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DetachNetworkInterfaceFromInstance indicates an expected call of DetachNetworkInterfaceFromInstance.
-func (mr *MockEC2APIHelperMockRecorder) DetachNetworkInterfaceFromInstance(arg0 interface{}) *gomock.Call {
+func (mr *MockEC2APIHelperMockRecorder) DetachNetworkInterfaceFromInstance(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetachNetworkInterfaceFromInstance", reflect.TypeOf((*MockEC2APIHelper)(nil).DetachNetworkInterfaceFromInstance), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetachNetworkInterfaceFromInstance", reflect.TypeOf((*MockEC2APIHelper)(nil).DetachNetworkInterfaceFromInstance), arg0, arg1)
 }
 
 // DisassociateTrunkInterface mocks base method.
-func (m *MockEC2APIHelper) DisassociateTrunkInterface(arg0 *string) error {
+func (m *MockEC2APIHelper) DisassociateTrunkInterface(arg0 context.Context, arg1 *string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DisassociateTrunkInterface", arg0)
+	ret := m.ctrl.Call(m, "DisassociateTrunkInterface", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DisassociateTrunkInterface indicates an expected call of DisassociateTrunkInterface.
-func (mr *MockEC2APIHelperMockRecorder) DisassociateTrunkInterface(arg0 interface{}) *gomock.Call {
+func (mr *MockEC2APIHelperMockRecorder) DisassociateTrunkInterface(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisassociateTrunkInterface", reflect.TypeOf((*MockEC2APIHelper)(nil).DisassociateTrunkInterface), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisassociateTrunkInterface", reflect.TypeOf((*MockEC2APIHelper)(nil).DisassociateTrunkInterface), arg0, arg1)
 }
 
 // GetBranchNetworkInterface mocks base method.
-func (m *MockEC2APIHelper) GetBranchNetworkInterface(arg0, arg1 *string) ([]*types.NetworkInterface, error) {
+func (m *MockEC2APIHelper) GetBranchNetworkInterface(arg0 context.Context, arg1 *string) ([]*types.NetworkInterface, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetBranchNetworkInterface", arg0, arg1)
 	ret0, _ := ret[0].([]*types.NetworkInterface)
@@ -226,88 +242,161 @@ func (mr *MockEC2APIHelperMockRecorder) GetBranchNetworkInterface(arg0, arg1 int
 }
 
 // GetInstanceDetails mocks base method.
-func (m *MockEC2APIHelper) GetInstanceDetails(arg0 *string) (*types.Instance, error) {
+func (m *MockEC2APIHelper) GetInstanceDetails(arg0 context.Context, arg1 *string) (*types.Instance, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetInstanceDetails", arg0)
+	ret := m.ctrl.Call(m, "GetInstanceDetails", arg0, arg1)
 	ret0, _ := ret[0].(*types.Instance)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetInstanceDetails indicates an expected call of GetInstanceDetails.
-func (mr *MockEC2APIHelperMockRecorder) GetInstanceDetails(arg0 interface{}) *gomock.Call {
+func (mr *MockEC2APIHelperMockRecorder) GetInstanceDetails(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInstanceDetails", reflect.TypeOf((*MockEC2APIHelper)(nil).GetInstanceDetails), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInstanceDetails", reflect.TypeOf((*MockEC2APIHelper)(nil).GetInstanceDetails), arg0, arg1)
 }
 
 // GetInstanceNetworkInterface mocks base method.
-func (m *MockEC2APIHelper) GetInstanceNetworkInterface(arg0 *string) ([]types.InstanceNetworkInterface, error) {
+func (m *MockEC2APIHelper) GetInstanceNetworkInterface(arg0 context.Context, arg1 *string) ([]types.InstanceNetworkInterface, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetInstanceNetworkInterface", arg0)
+	ret := m.ctrl.Call(m, "GetInstanceNetworkInterface", arg0, arg1)
 	ret0, _ := ret[0].([]types.InstanceNetworkInterface)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetInstanceNetworkInterface indicates an expected call of GetInstanceNetworkInterface.
-func (mr *MockEC2APIHelperMockRecorder) GetInstanceNetworkInterface(arg0 interface{}) *gomock.Call {
+func (mr *MockEC2APIHelperMockRecorder) GetInstanceNetworkInterface(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInstanceNetworkInterface", reflect.TypeOf((*MockEC2APIHelper)(nil).GetInstanceNetworkInterface), arg0, arg1)
+}
+
+// GetNetworkInterfaceSecurityGroups mocks base method.
+func (m *MockEC2APIHelper) GetNetworkInterfaceSecurityGroups(arg0 context.Context, arg1 []string) (map[string][]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNetworkInterfaceSecurityGroups", arg0, arg1)
+	ret0, _ := ret[0].(map[string][]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNetworkInterfaceSecurityGroups indicates an expected call of GetNetworkInterfaceSecurityGroups.
+func (mr *MockEC2APIHelperMockRecorder) GetNetworkInterfaceSecurityGroups(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNetworkInterfaceSecurityGroups", reflect.TypeOf((*MockEC2APIHelper)(nil).GetNetworkInterfaceSecurityGroups), arg0, arg1)
+}
+
+// GetSecurityGroups mocks base method.
+func (m *MockEC2APIHelper) GetSecurityGroups(arg0 context.Context, arg1 []string) ([]types.SecurityGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSecurityGroups", arg0, arg1)
+	ret0, _ := ret[0].([]types.SecurityGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSecurityGroups indicates an expected call of GetSecurityGroups.
+func (mr *MockEC2APIHelperMockRecorder) GetSecurityGroups(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInstanceNetworkInterface", reflect.TypeOf((*MockEC2APIHelper)(nil).GetInstanceNetworkInterface), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecurityGroups", reflect.TypeOf((*MockEC2APIHelper)(nil).GetSecurityGroups), arg0, arg1)
 }
 
 // GetSubnet mocks base method.
-func (m *MockEC2APIHelper) GetSubnet(arg0 *string) (*types.Subnet, error) {
+func (m *MockEC2APIHelper) GetSubnet(arg0 context.Context, arg1 *string) (*types.Subnet, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSubnet", arg0)
+	ret := m.ctrl.Call(m, "GetSubnet", arg0, arg1)
 	ret0, _ := ret[0].(*types.Subnet)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetSubnet indicates an expected call of GetSubnet.
-func (mr *MockEC2APIHelperMockRecorder) GetSubnet(arg0 interface{}) *gomock.Call {
+func (mr *MockEC2APIHelperMockRecorder) GetSubnet(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubnet", reflect.TypeOf((*MockEC2APIHelper)(nil).GetSubnet), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubnet", reflect.TypeOf((*MockEC2APIHelper)(nil).GetSubnet), arg0, arg1)
+}
+
+// GetSubnets mocks base method.
+func (m *MockEC2APIHelper) GetSubnets(arg0 context.Context, arg1 []string, arg2 map[string]string, arg3, arg4 string) ([]types.Subnet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubnets", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]types.Subnet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubnets indicates an expected call of GetSubnets.
+func (mr *MockEC2APIHelperMockRecorder) GetSubnets(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubnets", reflect.TypeOf((*MockEC2APIHelper)(nil).GetSubnets), arg0, arg1, arg2, arg3, arg4)
+}
+
+// ModifyNetworkInterfaceSecurityGroups mocks base method.
+func (m *MockEC2APIHelper) ModifyNetworkInterfaceSecurityGroups(arg0 context.Context, arg1 *string, arg2 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ModifyNetworkInterfaceSecurityGroups", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ModifyNetworkInterfaceSecurityGroups indicates an expected call of ModifyNetworkInterfaceSecurityGroups.
+func (mr *MockEC2APIHelperMockRecorder) ModifyNetworkInterfaceSecurityGroups(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ModifyNetworkInterfaceSecurityGroups", reflect.TypeOf((*MockEC2APIHelper)(nil).ModifyNetworkInterfaceSecurityGroups), arg0, arg1, arg2)
 }
 
 // SetDeleteOnTermination mocks base method.
-func (m *MockEC2APIHelper) SetDeleteOnTermination(arg0, arg1 *string) error {
+func (m *MockEC2APIHelper) SetDeleteOnTermination(arg0 context.Context, arg1, arg2 *string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetDeleteOnTermination", arg0, arg1)
+	ret := m.ctrl.Call(m, "SetDeleteOnTermination", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SetDeleteOnTermination indicates an expected call of SetDeleteOnTermination.
-func (mr *MockEC2APIHelperMockRecorder) SetDeleteOnTermination(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockEC2APIHelperMockRecorder) SetDeleteOnTermination(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDeleteOnTermination", reflect.TypeOf((*MockEC2APIHelper)(nil).SetDeleteOnTermination), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDeleteOnTermination", reflect.TypeOf((*MockEC2APIHelper)(nil).SetDeleteOnTermination), arg0, arg1, arg2)
 }
 
 // UnassignIPv4Resources mocks base method.
-func (m *MockEC2APIHelper) UnassignIPv4Resources(arg0 string, arg1 config.ResourceType, arg2 []string) error {
+func (m *MockEC2APIHelper) UnassignIPv4Resources(arg0 context.Context, arg1 string, arg2 config.ResourceType, arg3 []string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UnassignIPv4Resources", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "UnassignIPv4Resources", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UnassignIPv4Resources indicates an expected call of UnassignIPv4Resources.
-func (mr *MockEC2APIHelperMockRecorder) UnassignIPv4Resources(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockEC2APIHelperMockRecorder) UnassignIPv4Resources(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnassignIPv4Resources", reflect.TypeOf((*MockEC2APIHelper)(nil).UnassignIPv4Resources), arg0, arg1, arg2, arg3)
+}
+
+// UnassignIPv6Prefixes mocks base method.
+func (m *MockEC2APIHelper) UnassignIPv6Prefixes(arg0 context.Context, arg1 string, arg2 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnassignIPv6Prefixes", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnassignIPv6Prefixes indicates an expected call of UnassignIPv6Prefixes.
+func (mr *MockEC2APIHelperMockRecorder) UnassignIPv6Prefixes(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnassignIPv4Resources", reflect.TypeOf((*MockEC2APIHelper)(nil).UnassignIPv4Resources), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnassignIPv6Prefixes", reflect.TypeOf((*MockEC2APIHelper)(nil).UnassignIPv6Prefixes), arg0, arg1, arg2)
 }
 
 // WaitForNetworkInterfaceStatusChange mocks base method.
-func (m *MockEC2APIHelper) WaitForNetworkInterfaceStatusChange(arg0 *string, arg1 string) error {
+func (m *MockEC2APIHelper) WaitForNetworkInterfaceStatusChange(arg0 context.Context, arg1 *string, arg2 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "WaitForNetworkInterfaceStatusChange", arg0, arg1)
+	ret := m.ctrl.Call(m, "WaitForNetworkInterfaceStatusChange", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // WaitForNetworkInterfaceStatusChange indicates an expected call of WaitForNetworkInterfaceStatusChange.
-func (mr *MockEC2APIHelperMockRecorder) WaitForNetworkInterfaceStatusChange(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockEC2APIHelperMockRecorder) WaitForNetworkInterfaceStatusChange(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForNetworkInterfaceStatusChange", reflect.TypeOf((*MockEC2APIHelper)(nil).WaitForNetworkInterfaceStatusChange), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForNetworkInterfaceStatusChange", reflect.TypeOf((*MockEC2APIHelper)(nil).WaitForNetworkInterfaceStatusChange), arg0, arg1, arg2)
 }
@@ -18,6 +18,7 @@
 package mock_ec2
 
 import (
+	context "context"
 	reflect "reflect"
 
 	api "github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/ec2/api"
@@ -47,6 +48,20 @@ func (m *MockEC2Instance) EXPECT() *MockEC2InstanceMockRecorder {
 	return m.recorder
 }
 
+// AvailabilityZone mocks base method.
+func (m *MockEC2Instance) AvailabilityZone() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AvailabilityZone")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// AvailabilityZone indicates an expected call of AvailabilityZone.
+func (mr *MockEC2InstanceMockRecorder) AvailabilityZone() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AvailabilityZone", reflect.TypeOf((*MockEC2Instance)(nil).AvailabilityZone))
+}
+
 // CurrentInstanceSecurityGroups mocks base method.
 func (m *MockEC2Instance) CurrentInstanceSecurityGroups() []string {
 	m.ctrl.T.Helper()
@@ -118,17 +133,17 @@ func (mr *MockEC2InstanceMockRecorder) InstanceID() *gomock.Call {
 }
 
 // LoadDetails mocks base method.
-func (m *MockEC2Instance) LoadDetails(arg0 api.EC2APIHelper) error {
+func (m *MockEC2Instance) LoadDetails(arg0 context.Context, arg1 api.EC2APIHelper) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "LoadDetails", arg0)
+	ret := m.ctrl.Call(m, "LoadDetails", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // LoadDetails indicates an expected call of LoadDetails.
-func (mr *MockEC2InstanceMockRecorder) LoadDetails(arg0 interface{}) *gomock.Call {
+func (mr *MockEC2InstanceMockRecorder) LoadDetails(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadDetails", reflect.TypeOf((*MockEC2Instance)(nil).LoadDetails), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadDetails", reflect.TypeOf((*MockEC2Instance)(nil).LoadDetails), arg0, arg1)
 }
 
 // Name mocks base method.
@@ -270,15 +285,29 @@ func (mr *MockEC2InstanceMockRecorder) Type() *gomock.Call {
 }
 
 // UpdateCurrentSubnetAndCidrBlock mocks base method.
-func (m *MockEC2Instance) UpdateCurrentSubnetAndCidrBlock(arg0 api.EC2APIHelper) error {
+func (m *MockEC2Instance) UpdateCurrentSubnetAndCidrBlock(arg0 context.Context, arg1 api.EC2APIHelper) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateCurrentSubnetAndCidrBlock", arg0)
+	ret := m.ctrl.Call(m, "UpdateCurrentSubnetAndCidrBlock", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateCurrentSubnetAndCidrBlock indicates an expected call of UpdateCurrentSubnetAndCidrBlock.
-func (mr *MockEC2InstanceMockRecorder) UpdateCurrentSubnetAndCidrBlock(arg0 interface{}) *gomock.Call {
+func (mr *MockEC2InstanceMockRecorder) UpdateCurrentSubnetAndCidrBlock(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCurrentSubnetAndCidrBlock", reflect.TypeOf((*MockEC2Instance)(nil).UpdateCurrentSubnetAndCidrBlock), arg0, arg1)
+}
+
+// VpcID mocks base method.
+func (m *MockEC2Instance) VpcID() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VpcID")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// VpcID indicates an expected call of VpcID.
+func (mr *MockEC2InstanceMockRecorder) VpcID() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCurrentSubnetAndCidrBlock", reflect.TypeOf((*MockEC2Instance)(nil).UpdateCurrentSubnetAndCidrBlock), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VpcID", reflect.TypeOf((*MockEC2Instance)(nil).VpcID))
 }
@@ -102,6 +102,20 @@ func (mr *MockConditionsMockRecorder) IsWindowsPrefixDelegationEnabled() *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsWindowsPrefixDelegationEnabled", reflect.TypeOf((*MockConditions)(nil).IsWindowsPrefixDelegationEnabled))
 }
 
+// IsWindowsSecurityGroupsForPodsEnabled mocks base method.
+func (m *MockConditions) IsWindowsSecurityGroupsForPodsEnabled() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsWindowsSecurityGroupsForPodsEnabled")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsWindowsSecurityGroupsForPodsEnabled indicates an expected call of IsWindowsSecurityGroupsForPodsEnabled.
+func (mr *MockConditionsMockRecorder) IsWindowsSecurityGroupsForPodsEnabled() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsWindowsSecurityGroupsForPodsEnabled", reflect.TypeOf((*MockConditions)(nil).IsWindowsSecurityGroupsForPodsEnabled))
+}
+
 // SetPodDataStoreSyncStatus mocks base method.
 func (m *MockConditions) SetPodDataStoreSyncStatus(arg0 bool) {
 	m.ctrl.T.Helper()
@@ -271,3 +271,17 @@ func (mr *MockK8sWrapperMockRecorder) PatchCNINode(oldCNINode, newCNINode interf
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchCNINode", reflect.TypeOf((*MockK8sWrapper)(nil).PatchCNINode), oldCNINode, newCNINode)
 }
+
+// PatchCNINodeStatus mocks base method.
+func (m *MockK8sWrapper) PatchCNINodeStatus(arg0, arg1 *v1alpha10.CNINode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchCNINodeStatus", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PatchCNINodeStatus indicates an expected call of PatchCNINodeStatus.
+func (mr *MockK8sWrapperMockRecorder) PatchCNINodeStatus(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchCNINodeStatus", reflect.TypeOf((*MockK8sWrapper)(nil).PatchCNINodeStatus), arg0, arg1)
+}
@@ -63,6 +63,20 @@ func (mr *MockPodClientAPIWrapperMockRecorder) AnnotatePod(arg0, arg1, arg2, arg
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnnotatePod", reflect.TypeOf((*MockPodClientAPIWrapper)(nil).AnnotatePod), arg0, arg1, arg2, arg3, arg4)
 }
 
+// AnnotatePodWithAnnotations mocks base method.
+func (m *MockPodClientAPIWrapper) AnnotatePodWithAnnotations(arg0, arg1 string, arg2 types.UID, arg3 map[string]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnnotatePodWithAnnotations", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AnnotatePodWithAnnotations indicates an expected call of AnnotatePodWithAnnotations.
+func (mr *MockPodClientAPIWrapperMockRecorder) AnnotatePodWithAnnotations(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnnotatePodWithAnnotations", reflect.TypeOf((*MockPodClientAPIWrapper)(nil).AnnotatePodWithAnnotations), arg0, arg1, arg2, arg3)
+}
+
 // GetPod mocks base method.
 func (m *MockPodClientAPIWrapper) GetPod(arg0, arg1 string) (*v1.Pod, error) {
 	m.ctrl.T.Helper()
@@ -108,6 +122,20 @@ func (mr *MockPodClientAPIWrapperMockRecorder) GetRunningPodsOnNode(arg0 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRunningPodsOnNode", reflect.TypeOf((*MockPodClientAPIWrapper)(nil).GetRunningPodsOnNode), arg0)
 }
 
+// ListNamespaces mocks base method.
+func (m *MockPodClientAPIWrapper) ListNamespaces() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNamespaces")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// ListNamespaces indicates an expected call of ListNamespaces.
+func (mr *MockPodClientAPIWrapperMockRecorder) ListNamespaces() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNamespaces", reflect.TypeOf((*MockPodClientAPIWrapper)(nil).ListNamespaces))
+}
+
 // ListPods mocks base method.
 func (m *MockPodClientAPIWrapper) ListPods(arg0 string) (*v1.PodList, error) {
 	m.ctrl.T.Helper()
@@ -122,3 +150,18 @@ func (mr *MockPodClientAPIWrapperMockRecorder) ListPods(arg0 interface{}) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPods", reflect.TypeOf((*MockPodClientAPIWrapper)(nil).ListPods), arg0)
 }
+
+// ListPodsInNamespace mocks base method.
+func (m *MockPodClientAPIWrapper) ListPodsInNamespace(arg0 string) (*v1.PodList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPodsInNamespace", arg0)
+	ret0, _ := ret[0].(*v1.PodList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPodsInNamespace indicates an expected call of ListPodsInNamespace.
+func (mr *MockPodClientAPIWrapperMockRecorder) ListPodsInNamespace(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPodsInNamespace", reflect.TypeOf((*MockPodClientAPIWrapper)(nil).ListPodsInNamespace), arg0)
+}
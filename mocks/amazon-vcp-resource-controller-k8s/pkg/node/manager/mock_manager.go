@@ -102,6 +102,20 @@ func (mr *MockManagerMockRecorder) GetNode(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNode", reflect.TypeOf((*MockManager)(nil).GetNode), arg0)
 }
 
+// GetNodesBySubnet mocks base method.
+func (m *MockManager) GetNodesBySubnet() map[string][]string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNodesBySubnet")
+	ret0, _ := ret[0].(map[string][]string)
+	return ret0
+}
+
+// GetNodesBySubnet indicates an expected call of GetNodesBySubnet.
+func (mr *MockManagerMockRecorder) GetNodesBySubnet() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNodesBySubnet", reflect.TypeOf((*MockManager)(nil).GetNodesBySubnet))
+}
+
 // SkipHealthCheck mocks base method.
 func (m *MockManager) SkipHealthCheck() bool {
 	m.ctrl.T.Helper()
@@ -18,6 +18,7 @@
 package mock_node
 
 import (
+	context "context"
 	reflect "reflect"
 	time "time"
 
@@ -104,6 +105,20 @@ func (mr *MockNodeMockRecorder) GetReconciliationInterval() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReconciliationInterval", reflect.TypeOf((*MockNode)(nil).GetReconciliationInterval))
 }
 
+// GetSubnetID mocks base method.
+func (m *MockNode) GetSubnetID() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubnetID")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetSubnetID indicates an expected call of GetSubnetID.
+func (mr *MockNodeMockRecorder) GetSubnetID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubnetID", reflect.TypeOf((*MockNode)(nil).GetSubnetID))
+}
+
 // HasInstance mocks base method.
 func (m *MockNode) HasInstance() bool {
 	m.ctrl.T.Helper()
@@ -119,17 +134,17 @@ func (mr *MockNodeMockRecorder) HasInstance() *gomock.Call {
 }
 
 // InitResources mocks base method.
-func (m *MockNode) InitResources(arg0 resource.ResourceManager) error {
+func (m *MockNode) InitResources(arg0 context.Context, arg1 resource.ResourceManager) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "InitResources", arg0)
+	ret := m.ctrl.Call(m, "InitResources", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // InitResources indicates an expected call of InitResources.
-func (mr *MockNodeMockRecorder) InitResources(arg0 interface{}) *gomock.Call {
+func (mr *MockNodeMockRecorder) InitResources(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InitResources", reflect.TypeOf((*MockNode)(nil).InitResources), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InitResources", reflect.TypeOf((*MockNode)(nil).InitResources), arg0, arg1)
 }
 
 // IsManaged mocks base method.
@@ -211,15 +226,15 @@ func (mr *MockNodeMockRecorder) UpdateCustomNetworkingSpecs(arg0, arg1 interface
 }
 
 // UpdateResources mocks base method.
-func (m *MockNode) UpdateResources(arg0 resource.ResourceManager) error {
+func (m *MockNode) UpdateResources(arg0 context.Context, arg1 resource.ResourceManager) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateResources", arg0)
+	ret := m.ctrl.Call(m, "UpdateResources", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateResources indicates an expected call of UpdateResources.
-func (mr *MockNodeMockRecorder) UpdateResources(arg0 interface{}) *gomock.Call {
+func (mr *MockNodeMockRecorder) UpdateResources(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateResources", reflect.TypeOf((*MockNode)(nil).UpdateResources), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateResources", reflect.TypeOf((*MockNode)(nil).UpdateResources), arg0, arg1)
 }
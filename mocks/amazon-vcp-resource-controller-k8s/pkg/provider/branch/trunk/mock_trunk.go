@@ -18,8 +18,10 @@
 package mock_trunk
 
 import (
+	context "context"
 	reflect "reflect"
 
+	v1alpha1 "github.com/aws/amazon-vpc-resource-controller-k8s/apis/vpcresources/v1alpha1"
 	ec2 "github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/ec2"
 	trunk "github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider/branch/trunk"
 	gomock "github.com/golang/mock/gomock"
@@ -49,57 +51,99 @@ func (m *MockTrunkENI) EXPECT() *MockTrunkENIMockRecorder {
 	return m.recorder
 }
 
+// Checkpoint mocks base method.
+func (m *MockTrunkENI) Checkpoint() *v1alpha1.TrunkCheckpoint {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Checkpoint")
+	ret0, _ := ret[0].(*v1alpha1.TrunkCheckpoint)
+	return ret0
+}
+
+// Checkpoint indicates an expected call of Checkpoint.
+func (mr *MockTrunkENIMockRecorder) Checkpoint() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Checkpoint", reflect.TypeOf((*MockTrunkENI)(nil).Checkpoint))
+}
+
 // CreateAndAssociateBranchENIs mocks base method.
-func (m *MockTrunkENI) CreateAndAssociateBranchENIs(arg0 *v1.Pod, arg1 []string, arg2 int) ([]*trunk.ENIDetails, error) {
+func (m *MockTrunkENI) CreateAndAssociateBranchENIs(arg0 context.Context, arg1 *v1.Pod, arg2 []string, arg3 int) ([]*trunk.ENIDetails, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateAndAssociateBranchENIs", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "CreateAndAssociateBranchENIs", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].([]*trunk.ENIDetails)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateAndAssociateBranchENIs indicates an expected call of CreateAndAssociateBranchENIs.
-func (mr *MockTrunkENIMockRecorder) CreateAndAssociateBranchENIs(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockTrunkENIMockRecorder) CreateAndAssociateBranchENIs(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAndAssociateBranchENIs", reflect.TypeOf((*MockTrunkENI)(nil).CreateAndAssociateBranchENIs), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAndAssociateBranchENIs", reflect.TypeOf((*MockTrunkENI)(nil).CreateAndAssociateBranchENIs), arg0, arg1, arg2, arg3)
 }
 
 // DeleteAllBranchENIs mocks base method.
-func (m *MockTrunkENI) DeleteAllBranchENIs() {
+func (m *MockTrunkENI) DeleteAllBranchENIs(arg0 context.Context) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "DeleteAllBranchENIs")
+	m.ctrl.Call(m, "DeleteAllBranchENIs", arg0)
 }
 
 // DeleteAllBranchENIs indicates an expected call of DeleteAllBranchENIs.
-func (mr *MockTrunkENIMockRecorder) DeleteAllBranchENIs() *gomock.Call {
+func (mr *MockTrunkENIMockRecorder) DeleteAllBranchENIs(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAllBranchENIs", reflect.TypeOf((*MockTrunkENI)(nil).DeleteAllBranchENIs))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAllBranchENIs", reflect.TypeOf((*MockTrunkENI)(nil).DeleteAllBranchENIs), arg0)
 }
 
 // DeleteCooledDownENIs mocks base method.
-func (m *MockTrunkENI) DeleteCooledDownENIs() {
+func (m *MockTrunkENI) DeleteCooledDownENIs(arg0 context.Context) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "DeleteCooledDownENIs")
+	m.ctrl.Call(m, "DeleteCooledDownENIs", arg0)
 }
 
 // DeleteCooledDownENIs indicates an expected call of DeleteCooledDownENIs.
-func (mr *MockTrunkENIMockRecorder) DeleteCooledDownENIs() *gomock.Call {
+func (mr *MockTrunkENIMockRecorder) DeleteCooledDownENIs(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCooledDownENIs", reflect.TypeOf((*MockTrunkENI)(nil).DeleteCooledDownENIs), arg0)
+}
+
+// GetBranchENISubnetIDs mocks base method.
+func (m *MockTrunkENI) GetBranchENISubnetIDs() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBranchENISubnetIDs")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// GetBranchENISubnetIDs indicates an expected call of GetBranchENISubnetIDs.
+func (mr *MockTrunkENIMockRecorder) GetBranchENISubnetIDs() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCooledDownENIs", reflect.TypeOf((*MockTrunkENI)(nil).DeleteCooledDownENIs))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBranchENISubnetIDs", reflect.TypeOf((*MockTrunkENI)(nil).GetBranchENISubnetIDs))
 }
 
 // InitTrunk mocks base method.
-func (m *MockTrunkENI) InitTrunk(arg0 ec2.EC2Instance, arg1 []v1.Pod) error {
+func (m *MockTrunkENI) InitTrunk(arg0 context.Context, arg1 ec2.EC2Instance, arg2 []v1.Pod) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "InitTrunk", arg0, arg1)
+	ret := m.ctrl.Call(m, "InitTrunk", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // InitTrunk indicates an expected call of InitTrunk.
-func (mr *MockTrunkENIMockRecorder) InitTrunk(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockTrunkENIMockRecorder) InitTrunk(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InitTrunk", reflect.TypeOf((*MockTrunkENI)(nil).InitTrunk), arg0, arg1, arg2)
+}
+
+// InitTrunkFromCheckpoint mocks base method.
+func (m *MockTrunkENI) InitTrunkFromCheckpoint(arg0 *v1alpha1.TrunkCheckpoint, arg1 []v1.Pod) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InitTrunkFromCheckpoint", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InitTrunkFromCheckpoint indicates an expected call of InitTrunkFromCheckpoint.
+func (mr *MockTrunkENIMockRecorder) InitTrunkFromCheckpoint(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InitTrunk", reflect.TypeOf((*MockTrunkENI)(nil).InitTrunk), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InitTrunkFromCheckpoint", reflect.TypeOf((*MockTrunkENI)(nil).InitTrunkFromCheckpoint), arg0, arg1)
 }
 
 // Introspect mocks base method.
@@ -153,3 +197,53 @@ func (mr *MockTrunkENIMockRecorder) Reconcile(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reconcile", reflect.TypeOf((*MockTrunkENI)(nil).Reconcile), arg0)
 }
+
+// ReconcileWarmPool mocks base method.
+func (m *MockTrunkENI) ReconcileWarmPool(arg0 context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReconcileWarmPool", arg0)
+}
+
+// ReconcileWarmPool indicates an expected call of ReconcileWarmPool.
+func (mr *MockTrunkENIMockRecorder) ReconcileWarmPool(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReconcileWarmPool", reflect.TypeOf((*MockTrunkENI)(nil).ReconcileWarmPool), arg0)
+}
+
+// SetBranchENISubnets mocks base method.
+func (m *MockTrunkENI) SetBranchENISubnets(arg0 *v1alpha1.BranchENISubnets) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetBranchENISubnets", arg0)
+}
+
+// SetBranchENISubnets indicates an expected call of SetBranchENISubnets.
+func (mr *MockTrunkENIMockRecorder) SetBranchENISubnets(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBranchENISubnets", reflect.TypeOf((*MockTrunkENI)(nil).SetBranchENISubnets), arg0)
+}
+
+// UpdateBranchENISecurityGroups mocks base method.
+func (m *MockTrunkENI) UpdateBranchENISecurityGroups(arg0, arg1 string, arg2 []string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdateBranchENISecurityGroups", arg0, arg1, arg2)
+}
+
+// UpdateBranchENISecurityGroups indicates an expected call of UpdateBranchENISecurityGroups.
+func (mr *MockTrunkENIMockRecorder) UpdateBranchENISecurityGroups(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBranchENISecurityGroups", reflect.TypeOf((*MockTrunkENI)(nil).UpdateBranchENISecurityGroups), arg0, arg1, arg2)
+}
+
+// VerifyBranchENIs mocks base method.
+func (m *MockTrunkENI) VerifyBranchENIs(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyBranchENIs", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyBranchENIs indicates an expected call of VerifyBranchENIs.
+func (mr *MockTrunkENIMockRecorder) VerifyBranchENIs(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyBranchENIs", reflect.TypeOf((*MockTrunkENI)(nil).VerifyBranchENIs), arg0)
+}
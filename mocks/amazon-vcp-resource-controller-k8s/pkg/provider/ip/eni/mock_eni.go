@@ -18,8 +18,10 @@
 package mock_eni
 
 import (
+	context "context"
 	reflect "reflect"
 
+	v1alpha1 "github.com/aws/amazon-vpc-resource-controller-k8s/apis/vpcresources/v1alpha1"
 	api "github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/ec2/api"
 	config "github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
 	eni "github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider/ip/eni"
@@ -50,47 +52,75 @@ func (m *MockENIManager) EXPECT() *MockENIManagerMockRecorder {
 	return m.recorder
 }
 
+// Checkpoint mocks base method.
+func (m *MockENIManager) Checkpoint() []v1alpha1.ENICheckpoint {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Checkpoint")
+	ret0, _ := ret[0].([]v1alpha1.ENICheckpoint)
+	return ret0
+}
+
+// Checkpoint indicates an expected call of Checkpoint.
+func (mr *MockENIManagerMockRecorder) Checkpoint() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Checkpoint", reflect.TypeOf((*MockENIManager)(nil).Checkpoint))
+}
+
 // CreateIPV4Resource mocks base method.
-func (m *MockENIManager) CreateIPV4Resource(arg0 int, arg1 config.ResourceType, arg2 api.EC2APIHelper, arg3 logr.Logger) ([]string, error) {
+func (m *MockENIManager) CreateIPV4Resource(arg0 context.Context, arg1 int, arg2 config.ResourceType, arg3 api.EC2APIHelper, arg4 logr.Logger) ([]string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateIPV4Resource", arg0, arg1, arg2, arg3)
+	ret := m.ctrl.Call(m, "CreateIPV4Resource", arg0, arg1, arg2, arg3, arg4)
 	ret0, _ := ret[0].([]string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateIPV4Resource indicates an expected call of CreateIPV4Resource.
-func (mr *MockENIManagerMockRecorder) CreateIPV4Resource(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+func (mr *MockENIManagerMockRecorder) CreateIPV4Resource(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIPV4Resource", reflect.TypeOf((*MockENIManager)(nil).CreateIPV4Resource), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIPV4Resource", reflect.TypeOf((*MockENIManager)(nil).CreateIPV4Resource), arg0, arg1, arg2, arg3, arg4)
 }
 
 // DeleteIPV4Resource mocks base method.
-func (m *MockENIManager) DeleteIPV4Resource(arg0 []string, arg1 config.ResourceType, arg2 api.EC2APIHelper, arg3 logr.Logger) ([]string, error) {
+func (m *MockENIManager) DeleteIPV4Resource(arg0 context.Context, arg1 []string, arg2 config.ResourceType, arg3 api.EC2APIHelper, arg4 logr.Logger) ([]string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteIPV4Resource", arg0, arg1, arg2, arg3)
+	ret := m.ctrl.Call(m, "DeleteIPV4Resource", arg0, arg1, arg2, arg3, arg4)
 	ret0, _ := ret[0].([]string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // DeleteIPV4Resource indicates an expected call of DeleteIPV4Resource.
-func (mr *MockENIManagerMockRecorder) DeleteIPV4Resource(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+func (mr *MockENIManagerMockRecorder) DeleteIPV4Resource(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIPV4Resource", reflect.TypeOf((*MockENIManager)(nil).DeleteIPV4Resource), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIPV4Resource", reflect.TypeOf((*MockENIManager)(nil).DeleteIPV4Resource), arg0, arg1, arg2, arg3, arg4)
 }
 
 // InitResources mocks base method.
-func (m *MockENIManager) InitResources(arg0 api.EC2APIHelper) (*eni.IPv4Resource, error) {
+func (m *MockENIManager) InitResources(arg0 context.Context, arg1 api.EC2APIHelper) (*eni.IPv4Resource, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "InitResources", arg0)
+	ret := m.ctrl.Call(m, "InitResources", arg0, arg1)
 	ret0, _ := ret[0].(*eni.IPv4Resource)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // InitResources indicates an expected call of InitResources.
-func (mr *MockENIManagerMockRecorder) InitResources(arg0 interface{}) *gomock.Call {
+func (mr *MockENIManagerMockRecorder) InitResources(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InitResources", reflect.TypeOf((*MockENIManager)(nil).InitResources), arg0, arg1)
+}
+
+// InitResourcesFromCheckpoint mocks base method.
+func (m *MockENIManager) InitResourcesFromCheckpoint(arg0 []v1alpha1.ENICheckpoint) *eni.IPv4Resource {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InitResourcesFromCheckpoint", arg0)
+	ret0, _ := ret[0].(*eni.IPv4Resource)
+	return ret0
+}
+
+// InitResourcesFromCheckpoint indicates an expected call of InitResourcesFromCheckpoint.
+func (mr *MockENIManagerMockRecorder) InitResourcesFromCheckpoint(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InitResources", reflect.TypeOf((*MockENIManager)(nil).InitResources), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InitResourcesFromCheckpoint", reflect.TypeOf((*MockENIManager)(nil).InitResourcesFromCheckpoint), arg0)
 }
@@ -0,0 +1,59 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider (interfaces: BranchENISecurityGroupUpdater)
+
+// Package mock_provider is a generated GoMock package.
+package mock_provider
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockBranchENISecurityGroupUpdater is a mock of BranchENISecurityGroupUpdater interface.
+type MockBranchENISecurityGroupUpdater struct {
+	ctrl     *gomock.Controller
+	recorder *MockBranchENISecurityGroupUpdaterMockRecorder
+}
+
+// MockBranchENISecurityGroupUpdaterMockRecorder is the mock recorder for MockBranchENISecurityGroupUpdater.
+type MockBranchENISecurityGroupUpdaterMockRecorder struct {
+	mock *MockBranchENISecurityGroupUpdater
+}
+
+// NewMockBranchENISecurityGroupUpdater creates a new mock instance.
+func NewMockBranchENISecurityGroupUpdater(ctrl *gomock.Controller) *MockBranchENISecurityGroupUpdater {
+	mock := &MockBranchENISecurityGroupUpdater{ctrl: ctrl}
+	mock.recorder = &MockBranchENISecurityGroupUpdaterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBranchENISecurityGroupUpdater) EXPECT() *MockBranchENISecurityGroupUpdaterMockRecorder {
+	return m.recorder
+}
+
+// UpdateBranchENISecurityGroups mocks base method.
+func (m *MockBranchENISecurityGroupUpdater) UpdateBranchENISecurityGroups(arg0, arg1, arg2 string, arg3 []string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdateBranchENISecurityGroups", arg0, arg1, arg2, arg3)
+}
+
+// UpdateBranchENISecurityGroups indicates an expected call of UpdateBranchENISecurityGroups.
+func (mr *MockBranchENISecurityGroupUpdaterMockRecorder) UpdateBranchENISecurityGroups(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBranchENISecurityGroups", reflect.TypeOf((*MockBranchENISecurityGroupUpdater)(nil).UpdateBranchENISecurityGroups), arg0, arg1, arg2, arg3)
+}
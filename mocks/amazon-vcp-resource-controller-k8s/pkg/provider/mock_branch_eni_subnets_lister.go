@@ -0,0 +1,61 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider (interfaces: BranchENISubnetsLister)
+
+// Package mock_provider is a generated GoMock package.
+package mock_provider
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockBranchENISubnetsLister is a mock of BranchENISubnetsLister interface.
+type MockBranchENISubnetsLister struct {
+	ctrl     *gomock.Controller
+	recorder *MockBranchENISubnetsListerMockRecorder
+}
+
+// MockBranchENISubnetsListerMockRecorder is the mock recorder for MockBranchENISubnetsLister.
+type MockBranchENISubnetsListerMockRecorder struct {
+	mock *MockBranchENISubnetsLister
+}
+
+// NewMockBranchENISubnetsLister creates a new mock instance.
+func NewMockBranchENISubnetsLister(ctrl *gomock.Controller) *MockBranchENISubnetsLister {
+	mock := &MockBranchENISubnetsLister{ctrl: ctrl}
+	mock.recorder = &MockBranchENISubnetsListerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBranchENISubnetsLister) EXPECT() *MockBranchENISubnetsListerMockRecorder {
+	return m.recorder
+}
+
+// GetBranchENISubnets mocks base method.
+func (m *MockBranchENISubnetsLister) GetBranchENISubnets() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBranchENISubnets")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// GetBranchENISubnets indicates an expected call of GetBranchENISubnets.
+func (mr *MockBranchENISubnetsListerMockRecorder) GetBranchENISubnets() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBranchENISubnets", reflect.TypeOf((*MockBranchENISubnetsLister)(nil).GetBranchENISubnets))
+}
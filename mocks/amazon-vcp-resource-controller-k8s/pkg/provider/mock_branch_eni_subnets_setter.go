@@ -0,0 +1,60 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider (interfaces: BranchENISubnetsSetter)
+
+// Package mock_provider is a generated GoMock package.
+package mock_provider
+
+import (
+	reflect "reflect"
+
+	v1alpha1 "github.com/aws/amazon-vpc-resource-controller-k8s/apis/vpcresources/v1alpha1"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockBranchENISubnetsSetter is a mock of BranchENISubnetsSetter interface.
+type MockBranchENISubnetsSetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockBranchENISubnetsSetterMockRecorder
+}
+
+// MockBranchENISubnetsSetterMockRecorder is the mock recorder for MockBranchENISubnetsSetter.
+type MockBranchENISubnetsSetterMockRecorder struct {
+	mock *MockBranchENISubnetsSetter
+}
+
+// NewMockBranchENISubnetsSetter creates a new mock instance.
+func NewMockBranchENISubnetsSetter(ctrl *gomock.Controller) *MockBranchENISubnetsSetter {
+	mock := &MockBranchENISubnetsSetter{ctrl: ctrl}
+	mock.recorder = &MockBranchENISubnetsSetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBranchENISubnetsSetter) EXPECT() *MockBranchENISubnetsSetterMockRecorder {
+	return m.recorder
+}
+
+// SetBranchENISubnets mocks base method.
+func (m *MockBranchENISubnetsSetter) SetBranchENISubnets(arg0 string, arg1 *v1alpha1.BranchENISubnets) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetBranchENISubnets", arg0, arg1)
+}
+
+// SetBranchENISubnets indicates an expected call of SetBranchENISubnets.
+func (mr *MockBranchENISubnetsSetterMockRecorder) SetBranchENISubnets(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBranchENISubnets", reflect.TypeOf((*MockBranchENISubnetsSetter)(nil).SetBranchENISubnets), arg0, arg1)
+}
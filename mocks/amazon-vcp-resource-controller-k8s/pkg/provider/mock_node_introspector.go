@@ -0,0 +1,75 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider (interfaces: NodeIntrospector)
+
+// Package mock_provider is a generated GoMock package.
+package mock_provider
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockNodeIntrospector is a mock of NodeIntrospector interface.
+type MockNodeIntrospector struct {
+	ctrl     *gomock.Controller
+	recorder *MockNodeIntrospectorMockRecorder
+}
+
+// MockNodeIntrospectorMockRecorder is the mock recorder for MockNodeIntrospector.
+type MockNodeIntrospectorMockRecorder struct {
+	mock *MockNodeIntrospector
+}
+
+// NewMockNodeIntrospector creates a new mock instance.
+func NewMockNodeIntrospector(ctrl *gomock.Controller) *MockNodeIntrospector {
+	mock := &MockNodeIntrospector{ctrl: ctrl}
+	mock.recorder = &MockNodeIntrospectorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNodeIntrospector) EXPECT() *MockNodeIntrospectorMockRecorder {
+	return m.recorder
+}
+
+// IntrospectNodeNames mocks base method.
+func (m *MockNodeIntrospector) IntrospectNodeNames() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IntrospectNodeNames")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// IntrospectNodeNames indicates an expected call of IntrospectNodeNames.
+func (mr *MockNodeIntrospectorMockRecorder) IntrospectNodeNames() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IntrospectNodeNames", reflect.TypeOf((*MockNodeIntrospector)(nil).IntrospectNodeNames))
+}
+
+// IntrospectNodeSummary mocks base method.
+func (m *MockNodeIntrospector) IntrospectNodeSummary(arg0 string) interface{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IntrospectNodeSummary", arg0)
+	ret0, _ := ret[0].(interface{})
+	return ret0
+}
+
+// IntrospectNodeSummary indicates an expected call of IntrospectNodeSummary.
+func (mr *MockNodeIntrospectorMockRecorder) IntrospectNodeSummary(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IntrospectNodeSummary", reflect.TypeOf((*MockNodeIntrospector)(nil).IntrospectNodeSummary), arg0)
+}
@@ -18,6 +18,7 @@
 package mock_provider
 
 import (
+	context "context"
 	reflect "reflect"
 
 	ec2 "github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/ec2"
@@ -94,17 +95,17 @@ func (mr *MockResourceProviderMockRecorder) GetPool(arg0 interface{}) *gomock.Ca
 }
 
 // InitResource mocks base method.
-func (m *MockResourceProvider) InitResource(arg0 ec2.EC2Instance) error {
+func (m *MockResourceProvider) InitResource(arg0 context.Context, arg1 ec2.EC2Instance) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "InitResource", arg0)
+	ret := m.ctrl.Call(m, "InitResource", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // InitResource indicates an expected call of InitResource.
-func (mr *MockResourceProviderMockRecorder) InitResource(arg0 interface{}) *gomock.Call {
+func (mr *MockResourceProviderMockRecorder) InitResource(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InitResource", reflect.TypeOf((*MockResourceProvider)(nil).InitResource), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InitResource", reflect.TypeOf((*MockResourceProvider)(nil).InitResource), arg0, arg1)
 }
 
 // Introspect mocks base method.
@@ -164,18 +165,18 @@ func (mr *MockResourceProviderMockRecorder) IsInstanceSupported(arg0 interface{}
 }
 
 // ProcessAsyncJob mocks base method.
-func (m *MockResourceProvider) ProcessAsyncJob(arg0 interface{}) (reconcile.Result, error) {
+func (m *MockResourceProvider) ProcessAsyncJob(arg0 context.Context, arg1 interface{}) (reconcile.Result, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ProcessAsyncJob", arg0)
+	ret := m.ctrl.Call(m, "ProcessAsyncJob", arg0, arg1)
 	ret0, _ := ret[0].(reconcile.Result)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ProcessAsyncJob indicates an expected call of ProcessAsyncJob.
-func (mr *MockResourceProviderMockRecorder) ProcessAsyncJob(arg0 interface{}) *gomock.Call {
+func (mr *MockResourceProviderMockRecorder) ProcessAsyncJob(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessAsyncJob", reflect.TypeOf((*MockResourceProvider)(nil).ProcessAsyncJob), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessAsyncJob", reflect.TypeOf((*MockResourceProvider)(nil).ProcessAsyncJob), arg0, arg1)
 }
 
 // ReconcileNode mocks base method.
@@ -204,16 +205,28 @@ func (mr *MockResourceProviderMockRecorder) SubmitAsyncJob(arg0 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitAsyncJob", reflect.TypeOf((*MockResourceProvider)(nil).SubmitAsyncJob), arg0)
 }
 
+// SubmitAsyncJobWithContext mocks base method.
+func (m *MockResourceProvider) SubmitAsyncJobWithContext(arg0 context.Context, arg1 interface{}) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SubmitAsyncJobWithContext", arg0, arg1)
+}
+
+// SubmitAsyncJobWithContext indicates an expected call of SubmitAsyncJobWithContext.
+func (mr *MockResourceProviderMockRecorder) SubmitAsyncJobWithContext(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitAsyncJobWithContext", reflect.TypeOf((*MockResourceProvider)(nil).SubmitAsyncJobWithContext), arg0, arg1)
+}
+
 // UpdateResourceCapacity mocks base method.
-func (m *MockResourceProvider) UpdateResourceCapacity(arg0 ec2.EC2Instance) error {
+func (m *MockResourceProvider) UpdateResourceCapacity(arg0 context.Context, arg1 ec2.EC2Instance) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateResourceCapacity", arg0)
+	ret := m.ctrl.Call(m, "UpdateResourceCapacity", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateResourceCapacity indicates an expected call of UpdateResourceCapacity.
-func (mr *MockResourceProviderMockRecorder) UpdateResourceCapacity(arg0 interface{}) *gomock.Call {
+func (mr *MockResourceProviderMockRecorder) UpdateResourceCapacity(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateResourceCapacity", reflect.TypeOf((*MockResourceProvider)(nil).UpdateResourceCapacity), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateResourceCapacity", reflect.TypeOf((*MockResourceProvider)(nil).UpdateResourceCapacity), arg0, arg1)
 }
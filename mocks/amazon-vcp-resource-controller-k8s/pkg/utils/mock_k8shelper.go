@@ -20,6 +20,7 @@ package mock_utils
 import (
 	reflect "reflect"
 
+	utils "github.com/aws/amazon-vpc-resource-controller-k8s/pkg/utils"
 	gomock "github.com/golang/mock/gomock"
 	v1 "k8s.io/api/core/v1"
 )
@@ -61,3 +62,18 @@ func (mr *MockSecurityGroupForPodsAPIMockRecorder) GetMatchingSecurityGroupForPo
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMatchingSecurityGroupForPods", reflect.TypeOf((*MockSecurityGroupForPodsAPI)(nil).GetMatchingSecurityGroupForPods), arg0)
 }
+
+// ResolveSecurityGroupsForPod mocks base method.
+func (m *MockSecurityGroupForPodsAPI) ResolveSecurityGroupsForPod(arg0 *v1.Pod) (*utils.SecurityGroupResolution, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveSecurityGroupsForPod", arg0)
+	ret0, _ := ret[0].(*utils.SecurityGroupResolution)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveSecurityGroupsForPod indicates an expected call of ResolveSecurityGroupsForPod.
+func (mr *MockSecurityGroupForPodsAPIMockRecorder) ResolveSecurityGroupsForPod(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveSecurityGroupsForPod", reflect.TypeOf((*MockSecurityGroupForPodsAPI)(nil).ResolveSecurityGroupsForPod), arg0)
+}
@@ -18,11 +18,12 @@
 package mock_worker
 
 import (
+	context "context"
 	reflect "reflect"
 	time "time"
 
+	worker "github.com/aws/amazon-vpc-resource-controller-k8s/pkg/worker"
 	gomock "github.com/golang/mock/gomock"
-	reconcile "sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 // MockWorker is a mock of Worker interface.
@@ -49,7 +50,7 @@ func (m *MockWorker) EXPECT() *MockWorkerMockRecorder {
 }
 
 // StartWorkerPool mocks base method.
-func (m *MockWorker) StartWorkerPool(arg0 func(interface{}) (reconcile.Result, error)) error {
+func (m *MockWorker) StartWorkerPool(arg0 worker.WorkerFunc) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "StartWorkerPool", arg0)
 	ret0, _ := ret[0].(error)
@@ -85,3 +86,15 @@ func (mr *MockWorkerMockRecorder) SubmitJobAfter(arg0, arg1 interface{}) *gomock
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitJobAfter", reflect.TypeOf((*MockWorker)(nil).SubmitJobAfter), arg0, arg1)
 }
+
+// SubmitJobWithContext mocks base method.
+func (m *MockWorker) SubmitJobWithContext(arg0 context.Context, arg1 interface{}) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SubmitJobWithContext", arg0, arg1)
+}
+
+// SubmitJobWithContext indicates an expected call of SubmitJobWithContext.
+func (mr *MockWorkerMockRecorder) SubmitJobWithContext(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitJobWithContext", reflect.TypeOf((*MockWorker)(nil).SubmitJobWithContext), arg0, arg1)
+}
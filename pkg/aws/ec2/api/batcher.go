@@ -0,0 +1,309 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
+)
+
+const (
+	// maxDescribeBatchSize is the maximum number of IDs in a batched describe call, EC2 allows up to 200 values per
+	// filter
+	maxDescribeBatchSize = 100
+
+	operationDescribeInstances         = "describe_instances"
+	operationDescribeSubnets           = "describe_subnets"
+	operationDescribeBranchInterfaces  = "describe_branch_network_interfaces"
+	describeInstancesFilterInstanceID  = "instance-id"
+	describeSubnetsFilterSubnetID      = "subnet-id"
+	describeInterfacesFilterTrunkENIID = "tag:" + config.TrunkENIIDTag
+)
+
+// describeResult is the result of a batched describe call for a single ID
+type describeResult[T any] struct {
+	value T
+	found bool
+	err   error
+}
+
+// describeBatcher coalesces the describe requests for single IDs received within the batch window into a describe
+// call for multiple IDs, and fans the results of the call out to the callers
+type describeBatcher[T any] struct {
+	// operation is the name of the describe operation
+	operation string
+	// window is the time a request waits for other requests to batch with
+	window time.Duration
+	// describe describes the resources of the IDs and returns the resources found by ID
+	describe func(ids []string) (map[string]T, error)
+
+	lock sync.Mutex
+	// pending is the callers waiting for the result of the next batch by ID
+	pending map[string][]chan describeResult[T]
+	// scheduled is set if the next batch is scheduled to be flushed after the window
+	scheduled bool
+}
+
+func newDescribeBatcher[T any](operation string, window time.Duration,
+	describe func(ids []string) (map[string]T, error)) *describeBatcher[T] {
+	return &describeBatcher[T]{
+		operation: operation,
+		window:    window,
+		describe:  describe,
+		pending:   make(map[string][]chan describeResult[T]),
+	}
+}
+
+// get returns the resource of the ID from the next batch, found is false if the resource doesn't exist. The batch is
+// shared with the other callers, so the caller stops waiting for the batch when the context is done but the batch
+// isn't canceled
+func (b *describeBatcher[T]) get(ctx context.Context, id string) (value T, found bool, err error) {
+	result := make(chan describeResult[T], 1)
+
+	b.lock.Lock()
+	b.pending[id] = append(b.pending[id], result)
+	if len(b.pending) >= maxDescribeBatchSize {
+		// Flush the full batch immediately, the scheduled flush picks up the requests received later
+		pending := b.pending
+		b.pending = make(map[string][]chan describeResult[T])
+		go b.flush(pending)
+	} else if !b.scheduled {
+		b.scheduled = true
+		time.AfterFunc(b.window, b.flushScheduled)
+	}
+	b.lock.Unlock()
+
+	select {
+	case r := <-result:
+		return r.value, r.found, r.err
+	case <-ctx.Done():
+		return value, false, ctx.Err()
+	}
+}
+
+// flushScheduled flushes the batch at the end of the batch window
+func (b *describeBatcher[T]) flushScheduled() {
+	b.lock.Lock()
+	pending := b.pending
+	b.pending = make(map[string][]chan describeResult[T])
+	b.scheduled = false
+	b.lock.Unlock()
+
+	b.flush(pending)
+}
+
+// flush describes the resources of the pending IDs with a single call and sends the results to the callers
+func (b *describeBatcher[T]) flush(pending map[string][]chan describeResult[T]) {
+	if len(pending) == 0 {
+		return
+	}
+	ec2DescribeBatchSize.WithLabelValues(b.operation).Observe(float64(len(pending)))
+
+	values, err := b.describe(slices.Sorted(maps.Keys(pending)))
+	for id, callers := range pending {
+		result := describeResult[T]{err: err}
+		if err == nil {
+			result.value, result.found = values[id]
+		}
+		for _, caller := range callers {
+			caller <- result
+		}
+	}
+}
+
+// batchingEC2APIHelper is the EC2 API helper that batches the concurrent describe requests of the node initialization
+// into describe calls for multiple IDs. The other calls are passed through to the helper
+type batchingEC2APIHelper struct {
+	EC2APIHelper
+	ec2Wrapper EC2Wrapper
+
+	instances        *describeBatcher[*ec2types.Instance]
+	subnets          *describeBatcher[*ec2types.Subnet]
+	branchInterfaces *describeBatcher[[]*ec2types.NetworkInterface]
+}
+
+// NewBatchingEC2APIHelper returns an EC2 API helper that coalesces the describe requests for instances, subnets and the
+// branch network interfaces of trunks received within the batch window into multi-ID filtered describe calls. The
+// helper is returned as is if the batch window is not positive
+func NewBatchingEC2APIHelper(helper EC2APIHelper, ec2Wrapper EC2Wrapper, window time.Duration) EC2APIHelper {
+	if window <= 0 {
+		return helper
+	}
+	h := &batchingEC2APIHelper{EC2APIHelper: helper, ec2Wrapper: ec2Wrapper}
+	h.instances = newDescribeBatcher(operationDescribeInstances, window, h.describeInstances)
+	h.subnets = newDescribeBatcher(operationDescribeSubnets, window, h.describeSubnets)
+	h.branchInterfaces = newDescribeBatcher(operationDescribeBranchInterfaces, window, h.describeBranchInterfaces)
+	return h
+}
+
+// GetInstanceDetails returns the details of the instance from a batched describe call
+func (h *batchingEC2APIHelper) GetInstanceDetails(ctx context.Context, instanceId *string) (*ec2types.Instance, error) {
+	instance, found, err := h.instances.get(ctx, *instanceId)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("failed to find instance details for instance %s", *instanceId)
+	}
+	return instance, nil
+}
+
+// GetInstanceNetworkInterface returns all the network interface associated with an instance id from a batched
+// describe call
+func (h *batchingEC2APIHelper) GetInstanceNetworkInterface(ctx context.Context, instanceId *string) ([]ec2types.InstanceNetworkInterface, error) {
+	instanceDetails, err := h.GetInstanceDetails(ctx, instanceId)
+	if err != nil {
+		return nil, err
+	}
+
+	if instanceDetails.NetworkInterfaces != nil {
+		return instanceDetails.NetworkInterfaces, nil
+	}
+
+	return nil, fmt.Errorf("failed to find network interfaces for instance %s",
+		*instanceId)
+}
+
+// GetSubnet returns the subnet details of the given subnet from a batched describe call
+func (h *batchingEC2APIHelper) GetSubnet(ctx context.Context, subnetId *string) (*ec2types.Subnet, error) {
+	subnet, found, err := h.subnets.get(ctx, *subnetId)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("subnet not found %s", *subnetId)
+	}
+	return subnet, nil
+}
+
+// GetBranchNetworkInterface returns the branch network interfaces associated with the trunk from a batched describe
+// call
+func (h *batchingEC2APIHelper) GetBranchNetworkInterface(ctx context.Context, trunkID *string) ([]*ec2types.NetworkInterface, error) {
+	nwInterfaces, _, err := h.branchInterfaces.get(ctx, *trunkID)
+	return nwInterfaces, err
+}
+
+// describeInstances describes the instances filtered by the instance IDs, the instances that don't exist are not
+// returned instead of failing the call for all the instances
+func (h *batchingEC2APIHelper) describeInstances(instanceIDs []string) (map[string]*ec2types.Instance, error) {
+	describeInstancesInput := &ec2.DescribeInstancesInput{
+		Filters: []ec2types.Filter{{Name: aws.String(describeInstancesFilterInstanceID), Values: instanceIDs}},
+	}
+	instances := make(map[string]*ec2types.Instance, len(instanceIDs))
+	for {
+		describeInstancesOutput, err := h.ec2Wrapper.DescribeInstances(context.TODO(), describeInstancesInput)
+		if err != nil {
+			return nil, err
+		}
+		if describeInstancesOutput == nil {
+			break
+		}
+
+		for _, reservation := range describeInstancesOutput.Reservations {
+			for i := range reservation.Instances {
+				instance := &reservation.Instances[i]
+				instances[aws.ToString(instance.InstanceId)] = instance
+			}
+		}
+
+		if describeInstancesOutput.NextToken == nil {
+			break
+		}
+		describeInstancesInput.NextToken = describeInstancesOutput.NextToken
+	}
+	return instances, nil
+}
+
+// describeSubnets describes the subnets filtered by the subnet IDs
+func (h *batchingEC2APIHelper) describeSubnets(subnetIDs []string) (map[string]*ec2types.Subnet, error) {
+	describeSubnetsInput := &ec2.DescribeSubnetsInput{
+		Filters: []ec2types.Filter{{Name: aws.String(describeSubnetsFilterSubnetID), Values: subnetIDs}},
+	}
+	subnets := make(map[string]*ec2types.Subnet, len(subnetIDs))
+	for {
+		describeSubnetsOutput, err := h.ec2Wrapper.DescribeSubnets(context.TODO(), describeSubnetsInput)
+		if err != nil {
+			return nil, err
+		}
+		if describeSubnetsOutput == nil {
+			break
+		}
+
+		for i := range describeSubnetsOutput.Subnets {
+			subnet := &describeSubnetsOutput.Subnets[i]
+			subnets[aws.ToString(subnet.SubnetId)] = subnet
+		}
+
+		if describeSubnetsOutput.NextToken == nil {
+			break
+		}
+		describeSubnetsInput.NextToken = describeSubnetsOutput.NextToken
+	}
+	return subnets, nil
+}
+
+// describeBranchInterfaces describes the branch network interfaces filtered by the trunk ENI ID tag and groups them by
+// the trunk
+func (h *batchingEC2APIHelper) describeBranchInterfaces(trunkIDs []string) (map[string][]*ec2types.NetworkInterface,
+	error) {
+	describeNetworkInterfacesInput := &ec2.DescribeNetworkInterfacesInput{
+		Filters: []ec2types.Filter{{Name: aws.String(describeInterfacesFilterTrunkENIID), Values: trunkIDs}},
+	}
+	nwInterfaces := make(map[string][]*ec2types.NetworkInterface, len(trunkIDs))
+	for {
+		describeNetworkInterfaceOutput, err := h.ec2Wrapper.DescribeNetworkInterfaces(context.TODO(), describeNetworkInterfacesInput)
+		if err != nil {
+			return nil, err
+		}
+		if describeNetworkInterfaceOutput == nil {
+			break
+		}
+
+		for _, nwInterface := range describeNetworkInterfaceOutput.NetworkInterfaces {
+			trunkID := getTagValue(nwInterface.TagSet, config.TrunkENIIDTag)
+			// Only attach the required details to avoid consuming extra memory
+			nwInterfaces[trunkID] = append(nwInterfaces[trunkID], &ec2types.NetworkInterface{
+				NetworkInterfaceId: nwInterface.NetworkInterfaceId,
+				SubnetId:           nwInterface.SubnetId,
+				TagSet:             nwInterface.TagSet,
+			})
+		}
+
+		if describeNetworkInterfaceOutput.NextToken == nil {
+			break
+		}
+		describeNetworkInterfacesInput.NextToken = describeNetworkInterfaceOutput.NextToken
+	}
+	return nwInterfaces, nil
+}
+
+// getTagValue returns the value of the tag with the key, or an empty string if the tag is not present
+func getTagValue(tags []ec2types.Tag, key string) string {
+	for _, tag := range tags {
+		if aws.ToString(tag.Key) == key {
+			return aws.ToString(tag.Value)
+		}
+	}
+	return ""
+}
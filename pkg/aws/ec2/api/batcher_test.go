@@ -0,0 +1,249 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	mock_api "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/aws/ec2/api"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	instanceId2       = "i-00000000000000001"
+	missingInstanceId = "i-00000000000000002"
+	subnetId2         = "subnet-00000000000000001"
+	trunkInterfaceId2 = "eni-00000000000000004"
+
+	batchWindow = 100 * time.Millisecond
+)
+
+// getMockBatchingHelper returns the batching EC2APIHelper and the mock EC2Wrapper it makes the batched calls with
+func getMockBatchingHelper(ctrl *gomock.Controller) (EC2APIHelper, *mock_api.MockEC2Wrapper) {
+	helper, mockWrapper := getMockWrapper(ctrl)
+	return NewBatchingEC2APIHelper(helper, mockWrapper, batchWindow), mockWrapper
+}
+
+// getConcurrently calls get for all the IDs concurrently and returns the results and errors by ID
+func getConcurrently[T any](ids []string, get func(ctx context.Context, id *string) (T, error)) (map[string]T,
+	map[string]error) {
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	values, errs := make(map[string]T), make(map[string]error)
+	for _, id := range ids {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			value, err := get(context.TODO(), &id)
+			lock.Lock()
+			defer lock.Unlock()
+			values[id], errs[id] = value, err
+		}(id)
+	}
+	wg.Wait()
+	return values, errs
+}
+
+// TestNewBatchingEC2APIHelper_Disabled tests the helper is returned as is if the batch window is not positive
+func TestNewBatchingEC2APIHelper_Disabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	helper, mockWrapper := getMockWrapper(ctrl)
+	assert.Equal(t, helper, NewBatchingEC2APIHelper(helper, mockWrapper, 0))
+}
+
+// TestBatchingEC2APIHelper_GetInstanceDetails tests the concurrent requests for instance details are made with a single
+// filtered describe call, and the instances not returned by the call fail without failing the other requests
+func TestBatchingEC2APIHelper_GetInstanceDetails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	helper, mockWrapper := getMockBatchingHelper(ctrl)
+
+	mockWrapper.EXPECT().DescribeInstances(gomock.Any(), &ec2.DescribeInstancesInput{
+		Filters: []ec2types.Filter{{Name: aws.String("instance-id"),
+			Values: []string{instanceId, instanceId2, missingInstanceId}}},
+	}).Return(&ec2.DescribeInstancesOutput{
+		Reservations: []ec2types.Reservation{
+			{Instances: []ec2types.Instance{{InstanceId: &instanceId, SubnetId: &subnetId}}},
+			{Instances: []ec2types.Instance{{InstanceId: &instanceId2, SubnetId: &subnetId2}}},
+		},
+	}, nil)
+
+	instances, errs := getConcurrently([]string{instanceId, instanceId, instanceId2, missingInstanceId},
+		helper.GetInstanceDetails)
+
+	assert.NoError(t, errs[instanceId])
+	assert.Equal(t, subnetId, *instances[instanceId].SubnetId)
+	assert.NoError(t, errs[instanceId2])
+	assert.Equal(t, subnetId2, *instances[instanceId2].SubnetId)
+	assert.Error(t, errs[missingInstanceId])
+}
+
+// TestBatchingEC2APIHelper_GetInstanceNetworkInterface tests the network interfaces are returned from the batched
+// instance details
+func TestBatchingEC2APIHelper_GetInstanceNetworkInterface(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	helper, mockWrapper := getMockBatchingHelper(ctrl)
+	networkInterfaces := []ec2types.InstanceNetworkInterface{{NetworkInterfaceId: &trunkInterfaceId}}
+
+	mockWrapper.EXPECT().DescribeInstances(gomock.Any(), gomock.Any()).Return(&ec2.DescribeInstancesOutput{
+		Reservations: []ec2types.Reservation{{Instances: []ec2types.Instance{
+			{InstanceId: &instanceId, NetworkInterfaces: networkInterfaces},
+			{InstanceId: &instanceId2},
+		}}},
+	}, nil)
+
+	interfaces, errs := getConcurrently([]string{instanceId, instanceId2}, helper.GetInstanceNetworkInterface)
+
+	assert.NoError(t, errs[instanceId])
+	assert.Equal(t, networkInterfaces, interfaces[instanceId])
+	assert.Error(t, errs[instanceId2])
+}
+
+// TestBatchingEC2APIHelper_GetSubnet tests the concurrent requests for subnets are made with a single paginated
+// describe call
+func TestBatchingEC2APIHelper_GetSubnet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	helper, mockWrapper := getMockBatchingHelper(ctrl)
+	input := &ec2.DescribeSubnetsInput{
+		Filters: []ec2types.Filter{{Name: aws.String("subnet-id"), Values: []string{subnetId, subnetId2}}},
+	}
+	nextToken := "next-token"
+	nextInput := &ec2.DescribeSubnetsInput{Filters: input.Filters, NextToken: &nextToken}
+
+	gomock.InOrder(
+		mockWrapper.EXPECT().DescribeSubnets(gomock.Any(), input).Return(&ec2.DescribeSubnetsOutput{
+			Subnets: []ec2types.Subnet{{SubnetId: &subnetId}}, NextToken: &nextToken}, nil),
+		mockWrapper.EXPECT().DescribeSubnets(gomock.Any(), nextInput).Return(&ec2.DescribeSubnetsOutput{
+			Subnets: []ec2types.Subnet{{SubnetId: &subnetId2}}}, nil),
+	)
+
+	subnets, errs := getConcurrently([]string{subnetId, subnetId2}, helper.GetSubnet)
+
+	assert.NoError(t, errs[subnetId])
+	assert.Equal(t, subnetId, *subnets[subnetId].SubnetId)
+	assert.NoError(t, errs[subnetId2])
+	assert.Equal(t, subnetId2, *subnets[subnetId2].SubnetId)
+}
+
+// TestBatchingEC2APIHelper_GetBranchNetworkInterface tests the branch interfaces returned by a single describe call are
+// grouped by the trunk they belong to
+func TestBatchingEC2APIHelper_GetBranchNetworkInterface(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	helper, mockWrapper := getMockBatchingHelper(ctrl)
+	trunkTag := func(trunkID string) []ec2types.Tag {
+		return []ec2types.Tag{{Key: aws.String(config.TrunkENIIDTag), Value: aws.String(trunkID)}}
+	}
+
+	mockWrapper.EXPECT().DescribeNetworkInterfaces(gomock.Any(), &ec2.DescribeNetworkInterfacesInput{
+		Filters: []ec2types.Filter{{Name: aws.String("tag:" + config.TrunkENIIDTag),
+			Values: []string{trunkInterfaceId, trunkInterfaceId2}}},
+	}).Return(&ec2.DescribeNetworkInterfacesOutput{
+		NetworkInterfaces: []ec2types.NetworkInterface{
+			{NetworkInterfaceId: &branchInterfaceId, SubnetId: &subnetId, TagSet: trunkTag(trunkInterfaceId),
+				Description: &eniDescription},
+			{NetworkInterfaceId: &branchInterfaceId2, SubnetId: &subnetId, TagSet: trunkTag(trunkInterfaceId)},
+		},
+	}, nil)
+
+	branchInterfaces, errs := getConcurrently([]string{trunkInterfaceId, trunkInterfaceId2},
+		helper.GetBranchNetworkInterface)
+
+	assert.NoError(t, errs[trunkInterfaceId])
+	assert.Equal(t, []*ec2types.NetworkInterface{
+		{NetworkInterfaceId: &branchInterfaceId, SubnetId: &subnetId, TagSet: trunkTag(trunkInterfaceId)},
+		{NetworkInterfaceId: &branchInterfaceId2, SubnetId: &subnetId, TagSet: trunkTag(trunkInterfaceId)},
+	}, branchInterfaces[trunkInterfaceId])
+	assert.NoError(t, errs[trunkInterfaceId2])
+	assert.Empty(t, branchInterfaces[trunkInterfaceId2])
+}
+
+// TestBatchingEC2APIHelper_Error tests the error of the batched describe call is returned to all the requests
+func TestBatchingEC2APIHelper_Error(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	helper, mockWrapper := getMockBatchingHelper(ctrl)
+
+	mockWrapper.EXPECT().DescribeInstances(gomock.Any(), gomock.Any()).Return(nil, errMock)
+
+	_, errs := getConcurrently([]string{instanceId, instanceId2}, helper.GetInstanceDetails)
+
+	assert.ErrorIs(t, errs[instanceId], errMock)
+	assert.ErrorIs(t, errs[instanceId2], errMock)
+}
+
+// TestDescribeBatcher_FullBatch tests the batch is described without waiting for the window once it's full
+func TestDescribeBatcher_FullBatch(t *testing.T) {
+	var lock sync.Mutex
+	var batchSizes []int
+	batcher := newDescribeBatcher("test", time.Hour, func(ids []string) (map[string]string, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		batchSizes = append(batchSizes, len(ids))
+		values := make(map[string]string, len(ids))
+		for _, id := range ids {
+			values[id] = id
+		}
+		return values, nil
+	})
+
+	ids := make([]string, maxDescribeBatchSize)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("id-%d", i)
+	}
+	values, errs := getConcurrently(ids, func(ctx context.Context, id *string) (string, error) {
+		value, found, err := batcher.get(ctx, *id)
+		assert.True(t, found)
+		return value, err
+	})
+
+	assert.Equal(t, []int{maxDescribeBatchSize}, batchSizes)
+	for _, id := range ids {
+		assert.NoError(t, errs[id])
+		assert.Equal(t, id, values[id])
+	}
+}
+
+// TestDescribeBatcher_ContextDone tests the caller stops waiting for the batch once its context is done
+func TestDescribeBatcher_ContextDone(t *testing.T) {
+	batcher := newDescribeBatcher("test", time.Hour, func(ids []string) (map[string]string, error) {
+		return nil, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+	_, found, err := batcher.get(ctx, "id")
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.False(t, found)
+}
@@ -0,0 +1,284 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	ec2Errors "github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/errors"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/smithy-go"
+)
+
+const (
+	cacheResourceSubnet        = "subnet"
+	cacheResourceSecurityGroup = "security_group"
+	cacheResourceInstance      = "instance"
+)
+
+// CacheConfig is the time to live of the cached describe results of each resource, the resource is not cached if
+// its time to live is not positive
+type CacheConfig struct {
+	SubnetTTL        time.Duration
+	SecurityGroupTTL time.Duration
+	InstanceTTL      time.Duration
+}
+
+// cacheEntry is the cached describe result of a single resource, found is false if the resource doesn't exist
+type cacheEntry[T any] struct {
+	value  T
+	found  bool
+	expiry time.Time
+}
+
+// ttlCache caches the describe results of a resource by ID until their time to live expires
+type ttlCache[T any] struct {
+	// resource is the name of the cached resource
+	resource string
+	ttl      time.Duration
+
+	lock    sync.Mutex
+	entries map[string]cacheEntry[T]
+	// lastPruned is the time the expired entries were last removed
+	lastPruned time.Time
+}
+
+func newTTLCache[T any](resource string, ttl time.Duration) *ttlCache[T] {
+	return &ttlCache[T]{
+		resource:   resource,
+		ttl:        ttl,
+		entries:    make(map[string]cacheEntry[T]),
+		lastPruned: time.Now(),
+	}
+}
+
+// get returns the cached describe result of the ID, ok is false if the result is not cached or has expired
+func (c *ttlCache[T]) get(id string) (value T, found bool, ok bool) {
+	if c == nil {
+		return value, false, false
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry, ok := c.entries[id]
+	if ok && time.Now().Before(entry.expiry) {
+		ec2DescribeCacheHitCnt.WithLabelValues(c.resource).Inc()
+		return entry.value, entry.found, true
+	}
+	ec2DescribeCacheMissCnt.WithLabelValues(c.resource).Inc()
+	return value, false, false
+}
+
+// set caches the describe result of the ID
+func (c *ttlCache[T]) set(id string, value T, found bool) {
+	if c == nil {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := time.Now()
+	// Remove the expired entries of the resources that are no longer described, like the instances of deleted nodes
+	if now.Sub(c.lastPruned) > c.ttl {
+		for cachedID, entry := range c.entries {
+			if !now.Before(entry.expiry) {
+				delete(c.entries, cachedID)
+			}
+		}
+		c.lastPruned = now
+	}
+	c.entries[id] = cacheEntry[T]{value: value, found: found, expiry: now.Add(c.ttl)}
+}
+
+// invalidate removes the cached describe results of the IDs
+func (c *ttlCache[T]) invalidate(ids ...string) {
+	if c == nil {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, id := range ids {
+		delete(c.entries, id)
+	}
+}
+
+// cachingEC2APIHelper is the EC2 API helper that caches the describe results of subnets, security groups and
+// instances, the cached results are invalidated when their time to live expires or when an EC2 call fails with an
+// error indicating the results are stale. The other calls are passed through to the helper
+type cachingEC2APIHelper struct {
+	EC2APIHelper
+
+	subnets        *ttlCache[*ec2types.Subnet]
+	securityGroups *ttlCache[ec2types.SecurityGroup]
+	instances      *ttlCache[*ec2types.Instance]
+}
+
+// NewCachingEC2APIHelper returns an EC2 API helper that caches the describe results of the helper for the resources
+// with a positive time to live. The helper is returned as is if none of the resources are cached
+func NewCachingEC2APIHelper(helper EC2APIHelper, cacheConfig CacheConfig) EC2APIHelper {
+	if cacheConfig.SubnetTTL <= 0 && cacheConfig.SecurityGroupTTL <= 0 && cacheConfig.InstanceTTL <= 0 {
+		return helper
+	}
+	h := &cachingEC2APIHelper{EC2APIHelper: helper}
+	if cacheConfig.SubnetTTL > 0 {
+		h.subnets = newTTLCache[*ec2types.Subnet](cacheResourceSubnet, cacheConfig.SubnetTTL)
+	}
+	if cacheConfig.SecurityGroupTTL > 0 {
+		h.securityGroups = newTTLCache[ec2types.SecurityGroup](cacheResourceSecurityGroup, cacheConfig.SecurityGroupTTL)
+	}
+	if cacheConfig.InstanceTTL > 0 {
+		h.instances = newTTLCache[*ec2types.Instance](cacheResourceInstance, cacheConfig.InstanceTTL)
+	}
+	return h
+}
+
+// GetSubnet returns the subnet details of the given subnet from the cache, or describes and caches the subnet
+func (h *cachingEC2APIHelper) GetSubnet(ctx context.Context, subnetId *string) (*ec2types.Subnet, error) {
+	if subnet, _, ok := h.subnets.get(*subnetId); ok {
+		return subnet, nil
+	}
+
+	subnet, err := h.EC2APIHelper.GetSubnet(ctx, subnetId)
+	if err != nil {
+		return nil, err
+	}
+	h.subnets.set(*subnetId, subnet, true)
+	return subnet, nil
+}
+
+// GetSecurityGroups returns the security groups with the given group ids from the cache, and describes and caches
+// the security groups that are not cached. The groups that don't exist are cached as well, so that invalid groups
+// are not described repeatedly
+func (h *cachingEC2APIHelper) GetSecurityGroups(ctx context.Context, groupIDs []string) ([]ec2types.SecurityGroup, error) {
+	if h.securityGroups == nil {
+		return h.EC2APIHelper.GetSecurityGroups(ctx, groupIDs)
+	}
+
+	var securityGroups []ec2types.SecurityGroup
+	var uncachedGroupIDs []string
+	for _, groupID := range groupIDs {
+		securityGroup, found, ok := h.securityGroups.get(groupID)
+		if !ok {
+			uncachedGroupIDs = append(uncachedGroupIDs, groupID)
+		} else if found {
+			securityGroups = append(securityGroups, securityGroup)
+		}
+	}
+	if len(uncachedGroupIDs) == 0 {
+		return securityGroups, nil
+	}
+
+	describedGroups, err := h.EC2APIHelper.GetSecurityGroups(ctx, uncachedGroupIDs)
+	if err != nil {
+		return nil, err
+	}
+	describedGroupIDs := make(map[string]struct{}, len(describedGroups))
+	for _, securityGroup := range describedGroups {
+		describedGroupIDs[aws.ToString(securityGroup.GroupId)] = struct{}{}
+		h.securityGroups.set(aws.ToString(securityGroup.GroupId), securityGroup, true)
+	}
+	for _, groupID := range uncachedGroupIDs {
+		if _, ok := describedGroupIDs[groupID]; !ok {
+			h.securityGroups.set(groupID, ec2types.SecurityGroup{}, false)
+		}
+	}
+	return append(securityGroups, describedGroups...), nil
+}
+
+// GetInstanceDetails returns the details of the instance from the cache, or describes and caches the instance
+func (h *cachingEC2APIHelper) GetInstanceDetails(ctx context.Context, instanceId *string) (*ec2types.Instance, error) {
+	if instance, _, ok := h.instances.get(*instanceId); ok {
+		return instance, nil
+	}
+
+	instance, err := h.EC2APIHelper.GetInstanceDetails(ctx, instanceId)
+	if err != nil {
+		return nil, err
+	}
+	h.instances.set(*instanceId, instance, true)
+	return instance, nil
+}
+
+// CreateNetworkInterface creates the network interface and invalidates the cached subnet and security groups if the
+// call fails because they are stale
+func (h *cachingEC2APIHelper) CreateNetworkInterface(ctx context.Context, description *string, subnetId *string,
+	securityGroups []string, tags []ec2types.Tag, ipResourceCount *config.IPResourceCount, interfaceType *string,
+) (*ec2types.NetworkInterface, error) {
+	nwInterface, err := h.EC2APIHelper.CreateNetworkInterface(ctx, description, subnetId, securityGroups, tags,
+		ipResourceCount, interfaceType)
+	if err != nil {
+		h.invalidateOnError(err, subnetId, securityGroups)
+	}
+	return nwInterface, err
+}
+
+// CreateAndAttachNetworkInterface creates and attaches the network interface and invalidates the cached instance, as
+// its network interfaces change, along with the cached subnet and security groups if the call fails because they
+// are stale
+func (h *cachingEC2APIHelper) CreateAndAttachNetworkInterface(ctx context.Context, instanceId *string, subnetId *string,
+	securityGroups []string, tags []ec2types.Tag, deviceIndex *int32, description *string, interfaceType *string,
+	ipResourceCount *config.IPResourceCount) (*ec2types.NetworkInterface, error) {
+	nwInterface, err := h.EC2APIHelper.CreateAndAttachNetworkInterface(ctx, instanceId, subnetId, securityGroups, tags,
+		deviceIndex, description, interfaceType, ipResourceCount)
+	h.instances.invalidate(*instanceId)
+	if err != nil {
+		h.invalidateOnError(err, subnetId, securityGroups)
+	}
+	return nwInterface, err
+}
+
+// AttachNetworkInterfaceToInstance attaches the network interface to the instance and invalidates the cached instance
+func (h *cachingEC2APIHelper) AttachNetworkInterfaceToInstance(ctx context.Context, instanceId *string,
+	nwInterfaceId *string, deviceIndex *int32) (*string, error) {
+	attachmentId, err := h.EC2APIHelper.AttachNetworkInterfaceToInstance(ctx, instanceId, nwInterfaceId, deviceIndex)
+	h.instances.invalidate(*instanceId)
+	return attachmentId, err
+}
+
+// ModifyNetworkInterfaceSecurityGroups replaces the security groups of the network interface and invalidates the
+// cached security groups if the call fails because they don't exist
+func (h *cachingEC2APIHelper) ModifyNetworkInterfaceSecurityGroups(ctx context.Context, eniId *string, securityGroups []string) error {
+	err := h.EC2APIHelper.ModifyNetworkInterfaceSecurityGroups(ctx, eniId, securityGroups)
+	if err != nil {
+		h.invalidateOnError(err, nil, securityGroups)
+	}
+	return err
+}
+
+// invalidateOnError invalidates the cached subnet and security groups if the error indicates they are stale
+func (h *cachingEC2APIHelper) invalidateOnError(err error, subnetId *string, securityGroups []string) {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return
+	}
+
+	switch apiErr.ErrorCode() {
+	case ec2Errors.NotFoundSubnetID, ec2Errors.InsufficientFreeAddressesInSubnet:
+		if subnetId != nil {
+			h.subnets.invalidate(*subnetId)
+		}
+	case ec2Errors.NotFoundGroupID:
+		h.securityGroups.invalidate(securityGroups...)
+	}
+}
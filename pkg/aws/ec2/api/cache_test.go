@@ -0,0 +1,223 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mock_api "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/aws/ec2/api"
+	ec2Errors "github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/errors"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/smithy-go"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	cacheConfig = CacheConfig{SubnetTTL: time.Minute, SecurityGroupTTL: time.Minute, InstanceTTL: time.Minute}
+)
+
+// getMockCachingHelper returns the caching EC2APIHelper and the mock EC2APIHelper it caches the results of
+func getMockCachingHelper(ctrl *gomock.Controller) (*cachingEC2APIHelper, *mock_api.MockEC2APIHelper) {
+	mockHelper := mock_api.NewMockEC2APIHelper(ctrl)
+	return NewCachingEC2APIHelper(mockHelper, cacheConfig).(*cachingEC2APIHelper), mockHelper
+}
+
+func getCounterValue(counter *prometheus.CounterVec, resource string) float64 {
+	metric := &dto.Metric{}
+	_ = counter.WithLabelValues(resource).Write(metric)
+	return metric.GetCounter().GetValue()
+}
+
+// TestNewCachingEC2APIHelper_Disabled tests the helper is returned as is if none of the resources are cached
+func TestNewCachingEC2APIHelper_Disabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHelper := mock_api.NewMockEC2APIHelper(ctrl)
+	assert.Equal(t, mockHelper, NewCachingEC2APIHelper(mockHelper, CacheConfig{}))
+}
+
+// TestCachingEC2APIHelper_GetSubnet tests the subnet is described once until the cached subnet expires, and the hits
+// and misses are counted
+func TestCachingEC2APIHelper_GetSubnet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	helper, mockHelper := getMockCachingHelper(ctrl)
+	subnet := &ec2types.Subnet{SubnetId: &subnetId}
+	hits := getCounterValue(ec2DescribeCacheHitCnt, cacheResourceSubnet)
+	misses := getCounterValue(ec2DescribeCacheMissCnt, cacheResourceSubnet)
+
+	mockHelper.EXPECT().GetSubnet(gomock.Any(), &subnetId).Return(subnet, nil).Times(2)
+
+	for i := 0; i < 3; i++ {
+		cachedSubnet, err := helper.GetSubnet(context.TODO(), &subnetId)
+		assert.NoError(t, err)
+		assert.Equal(t, subnet, cachedSubnet)
+	}
+	assert.Equal(t, hits+2, getCounterValue(ec2DescribeCacheHitCnt, cacheResourceSubnet))
+	assert.Equal(t, misses+1, getCounterValue(ec2DescribeCacheMissCnt, cacheResourceSubnet))
+
+	// Expire the cached subnet
+	helper.subnets.entries[subnetId] = cacheEntry[*ec2types.Subnet]{value: subnet, found: true,
+		expiry: time.Now().Add(-time.Second)}
+	_, err := helper.GetSubnet(context.TODO(), &subnetId)
+	assert.NoError(t, err)
+}
+
+// TestCachingEC2APIHelper_GetSubnet_Error tests the error is not cached
+func TestCachingEC2APIHelper_GetSubnet_Error(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	helper, mockHelper := getMockCachingHelper(ctrl)
+
+	mockHelper.EXPECT().GetSubnet(gomock.Any(), &subnetId).Return(nil, errMock).Times(2)
+
+	for i := 0; i < 2; i++ {
+		_, err := helper.GetSubnet(context.TODO(), &subnetId)
+		assert.ErrorIs(t, err, errMock)
+	}
+}
+
+// TestCachingEC2APIHelper_GetSecurityGroups tests only the security groups that are not cached are described, and the
+// security groups that don't exist are cached
+func TestCachingEC2APIHelper_GetSecurityGroups(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	helper, mockHelper := getMockCachingHelper(ctrl)
+	securityGroup3 := "sg-00000000000000003"
+
+	gomock.InOrder(
+		mockHelper.EXPECT().GetSecurityGroups(gomock.Any(), securityGroups).Return(
+			[]ec2types.SecurityGroup{{GroupId: &securityGroup1}}, nil),
+		mockHelper.EXPECT().GetSecurityGroups(gomock.Any(), []string{securityGroup3}).Return(
+			[]ec2types.SecurityGroup{{GroupId: &securityGroup3}}, nil),
+	)
+
+	groups, err := helper.GetSecurityGroups(context.TODO(), securityGroups)
+	assert.NoError(t, err)
+	assert.Equal(t, []ec2types.SecurityGroup{{GroupId: &securityGroup1}}, groups)
+
+	groups, err = helper.GetSecurityGroups(context.TODO(), []string{securityGroup1, securityGroup2, securityGroup3})
+	assert.NoError(t, err)
+	assert.Equal(t, []ec2types.SecurityGroup{{GroupId: &securityGroup1}, {GroupId: &securityGroup3}}, groups)
+}
+
+// TestCachingEC2APIHelper_GetInstanceDetails tests the cached instance is invalidated when a network interface is
+// attached to the instance
+func TestCachingEC2APIHelper_GetInstanceDetails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	helper, mockHelper := getMockCachingHelper(ctrl)
+	instance := &ec2types.Instance{InstanceId: &instanceId}
+
+	mockHelper.EXPECT().GetInstanceDetails(gomock.Any(), &instanceId).Return(instance, nil).Times(2)
+	mockHelper.EXPECT().AttachNetworkInterfaceToInstance(gomock.Any(), &instanceId, &branchInterfaceId, &deviceIndex).
+		Return(&attachmentId, nil)
+
+	for i := 0; i < 2; i++ {
+		cachedInstance, err := helper.GetInstanceDetails(context.TODO(), &instanceId)
+		assert.NoError(t, err)
+		assert.Equal(t, instance, cachedInstance)
+	}
+
+	_, err := helper.AttachNetworkInterfaceToInstance(context.TODO(), &instanceId, &branchInterfaceId, &deviceIndex)
+	assert.NoError(t, err)
+
+	_, err = helper.GetInstanceDetails(context.TODO(), &instanceId)
+	assert.NoError(t, err)
+}
+
+// TestCachingEC2APIHelper_CreateNetworkInterface_Invalidate tests the cached subnet and security groups are invalidated
+// only if the network interface cannot be created because they are stale
+func TestCachingEC2APIHelper_CreateNetworkInterface_Invalidate(t *testing.T) {
+	tests := []struct {
+		name                 string
+		err                  error
+		subnetCached         bool
+		securityGroupsCached bool
+	}{
+		{
+			name:                 "subnet not found",
+			err:                  &smithy.GenericAPIError{Code: ec2Errors.NotFoundSubnetID},
+			securityGroupsCached: true,
+		},
+		{
+			name:                 "insufficient free addresses in subnet",
+			err:                  &smithy.GenericAPIError{Code: ec2Errors.InsufficientFreeAddressesInSubnet},
+			securityGroupsCached: true,
+		},
+		{
+			name:         "security group not found",
+			err:          &smithy.GenericAPIError{Code: ec2Errors.NotFoundGroupID},
+			subnetCached: true,
+		},
+		{
+			name:                 "other error",
+			err:                  errMock,
+			subnetCached:         true,
+			securityGroupsCached: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			helper, mockHelper := getMockCachingHelper(ctrl)
+			helper.subnets.set(subnetId, &ec2types.Subnet{SubnetId: &subnetId}, true)
+			for _, groupID := range securityGroups {
+				helper.securityGroups.set(groupID, ec2types.SecurityGroup{GroupId: &groupID}, true)
+			}
+
+			mockHelper.EXPECT().CreateNetworkInterface(gomock.Any(), &eniDescription, &subnetId, securityGroups, nil, nil, nil).
+				Return(nil, test.err)
+
+			_, err := helper.CreateNetworkInterface(context.TODO(), &eniDescription, &subnetId, securityGroups, nil, nil, nil)
+			assert.ErrorIs(t, err, test.err)
+
+			_, _, ok := helper.subnets.get(subnetId)
+			assert.Equal(t, test.subnetCached, ok)
+			for _, groupID := range securityGroups {
+				_, _, ok = helper.securityGroups.get(groupID)
+				assert.Equal(t, test.securityGroupsCached, ok)
+			}
+		})
+	}
+}
+
+// TestTTLCache_Prune tests the expired entries are removed once the time to live has passed since they were last
+// removed
+func TestTTLCache_Prune(t *testing.T) {
+	cache := newTTLCache[string](cacheResourceInstance, time.Minute)
+	cache.entries[instanceId] = cacheEntry[string]{value: instanceId, found: true, expiry: time.Now().Add(-time.Second)}
+
+	cache.set(eniID, eniID, true)
+	assert.Contains(t, cache.entries, instanceId)
+
+	cache.lastPruned = time.Now().Add(-2 * time.Minute)
+	cache.set(eniID, eniID, true)
+	assert.NotContains(t, cache.entries, instanceId)
+	assert.Contains(t, cache.entries, eniID)
+}
@@ -16,6 +16,8 @@ package api
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -77,30 +79,36 @@ func NewEC2APIHelper(ec2Wrapper EC2Wrapper, clusterName string) EC2APIHelper {
 }
 
 type EC2APIHelper interface {
-	AssociateBranchToTrunk(trunkInterfaceId *string, branchInterfaceId *string, vlanId int) (*ec2.AssociateTrunkInterfaceOutput, error)
-	CreateNetworkInterface(description *string, subnetId *string, securityGroups []string, tags []ec2types.Tag,
+	AssociateBranchToTrunk(ctx context.Context, trunkInterfaceId *string, branchInterfaceId *string, vlanId int) (*ec2.AssociateTrunkInterfaceOutput, error)
+	CreateNetworkInterface(ctx context.Context, description *string, subnetId *string, securityGroups []string, tags []ec2types.Tag,
 		ipResourceCount *config.IPResourceCount, interfaceType *string) (*ec2types.NetworkInterface, error)
-	DeleteNetworkInterface(interfaceId *string) error
-	GetSubnet(subnetId *string) (*ec2types.Subnet, error)
-	GetBranchNetworkInterface(trunkID, subnetID *string) ([]*ec2types.NetworkInterface, error)
-	GetInstanceNetworkInterface(instanceId *string) ([]ec2types.InstanceNetworkInterface, error)
-	DescribeNetworkInterfaces(nwInterfaceIds []string) ([]ec2types.NetworkInterface, error)
-	DescribeTrunkInterfaceAssociation(trunkInterfaceId *string) ([]ec2types.TrunkInterfaceAssociation, error)
-	CreateAndAttachNetworkInterface(instanceId *string, subnetId *string, securityGroups []string, tags []ec2types.Tag, deviceIndex *int32,
-		description *string, interfaceType *string, ipResourceCount *config.IPResourceCount) (*ec2types.NetworkInterface, error)
-	AttachNetworkInterfaceToInstance(instanceId *string, nwInterfaceId *string, deviceIndex *int32) (*string, error)
-	SetDeleteOnTermination(attachmentId *string, eniId *string) error
-	DetachNetworkInterfaceFromInstance(attachmentId *string) error
-	DetachAndDeleteNetworkInterface(attachmentId *string, nwInterfaceId *string) error
-	WaitForNetworkInterfaceStatusChange(networkInterfaceId *string, desiredStatus string) error
-	GetInstanceDetails(instanceId *string) (*ec2types.Instance, error)
-	AssignIPv4ResourcesAndWaitTillReady(eniID string, resourceType config.ResourceType, count int) ([]string, error)
-	UnassignIPv4Resources(eniID string, resourceType config.ResourceType, resources []string) error
-	DisassociateTrunkInterface(associationID *string) error
+	DeleteNetworkInterface(ctx context.Context, interfaceId *string) error
+	GetSubnet(ctx context.Context, subnetId *string) (*ec2types.Subnet, error)
+	GetSubnets(ctx context.Context, subnetIDs []string, tags map[string]string, vpcID string, availabilityZone string) ([]ec2types.Subnet, error)
+	GetSecurityGroups(ctx context.Context, groupIDs []string) ([]ec2types.SecurityGroup, error)
+	GetBranchNetworkInterface(ctx context.Context, trunkID *string) ([]*ec2types.NetworkInterface, error)
+	GetInstanceNetworkInterface(ctx context.Context, instanceId *string) ([]ec2types.InstanceNetworkInterface, error)
+	DescribeNetworkInterfaces(ctx context.Context, nwInterfaceIds []string) ([]ec2types.NetworkInterface, error)
+	GetNetworkInterfaceSecurityGroups(ctx context.Context, nwInterfaceIDs []string) (map[string][]string, error)
+	DescribeTrunkInterfaceAssociation(ctx context.Context, trunkInterfaceId *string) ([]ec2types.TrunkInterfaceAssociation, error)
+	CreateAndAttachNetworkInterface(ctx context.Context, instanceId *string, subnetId *string, securityGroups []string, tags []ec2types.Tag,
+		deviceIndex *int32, description *string, interfaceType *string, ipResourceCount *config.IPResourceCount) (*ec2types.NetworkInterface, error)
+	AttachNetworkInterfaceToInstance(ctx context.Context, instanceId *string, nwInterfaceId *string, deviceIndex *int32) (*string, error)
+	SetDeleteOnTermination(ctx context.Context, attachmentId *string, eniId *string) error
+	ModifyNetworkInterfaceSecurityGroups(ctx context.Context, eniId *string, securityGroups []string) error
+	DetachNetworkInterfaceFromInstance(ctx context.Context, attachmentId *string) error
+	DetachAndDeleteNetworkInterface(ctx context.Context, attachmentId *string, nwInterfaceId *string) error
+	WaitForNetworkInterfaceStatusChange(ctx context.Context, networkInterfaceId *string, desiredStatus string) error
+	GetInstanceDetails(ctx context.Context, instanceId *string) (*ec2types.Instance, error)
+	AssignIPv4ResourcesAndWaitTillReady(ctx context.Context, eniID string, resourceType config.ResourceType, count int) ([]string, error)
+	UnassignIPv4Resources(ctx context.Context, eniID string, resourceType config.ResourceType, resources []string) error
+	AssignIPv6PrefixesAndWaitTillReady(ctx context.Context, eniID string, count int) ([]string, error)
+	UnassignIPv6Prefixes(ctx context.Context, eniID string, prefixes []string) error
+	DisassociateTrunkInterface(ctx context.Context, associationID *string) error
 }
 
 // CreateNetworkInterface creates a new network interface
-func (h *ec2APIHelper) CreateNetworkInterface(description *string, subnetId *string, securityGroups []string, tags []ec2types.Tag,
+func (h *ec2APIHelper) CreateNetworkInterface(ctx context.Context, description *string, subnetId *string, securityGroups []string, tags []ec2types.Tag,
 	ipResourceCount *config.IPResourceCount, interfaceType *string,
 ) (*ec2types.NetworkInterface, error) {
 	eniDescription := CreateENIDescriptionPrefix + *description
@@ -152,7 +160,7 @@ func (h *ec2APIHelper) CreateNetworkInterface(description *string, subnetId *str
 		createInput.InterfaceType = ec2types.NetworkInterfaceCreationType(*interfaceType)
 	}
 
-	createOutput, err := h.ec2Wrapper.CreateNetworkInterface(createInput)
+	createOutput, err := h.ec2Wrapper.CreateNetworkInterface(ctx, createInput)
 	if err != nil {
 		return nil, err
 	}
@@ -172,9 +180,9 @@ func (h *ec2APIHelper) CreateNetworkInterface(description *string, subnetId *str
 			Permission:         ec2types.InterfacePermissionType(ec2types.InterfacePermissionTypeInstanceAttach),
 		}
 
-		_, err = h.ec2Wrapper.CreateNetworkInterfacePermission(input)
+		_, err = h.ec2Wrapper.CreateNetworkInterfacePermission(ctx, input)
 		if err != nil {
-			errDelete := h.DeleteNetworkInterface(nwInterface.NetworkInterfaceId)
+			errDelete := h.DeleteNetworkInterface(ctx, nwInterface.NetworkInterfaceId)
 			if errDelete != nil {
 				return nwInterface, fmt.Errorf("failed to attach the network interface permissions %v: failed to delete the nw interfac %v",
 					err, errDelete)
@@ -186,12 +194,12 @@ func (h *ec2APIHelper) CreateNetworkInterface(description *string, subnetId *str
 }
 
 // GetSubnet returns the subnet details of the given subnet
-func (h *ec2APIHelper) GetSubnet(subnetId *string) (*ec2types.Subnet, error) {
+func (h *ec2APIHelper) GetSubnet(ctx context.Context, subnetId *string) (*ec2types.Subnet, error) {
 	describeSubnetInput := &ec2.DescribeSubnetsInput{
 		SubnetIds: []string{*subnetId},
 	}
 
-	describeSubnetOutput, err := h.ec2Wrapper.DescribeSubnets(describeSubnetInput)
+	describeSubnetOutput, err := h.ec2Wrapper.DescribeSubnets(ctx, describeSubnetInput)
 	if err != nil {
 		return nil, err
 	}
@@ -202,14 +210,92 @@ func (h *ec2APIHelper) GetSubnet(subnetId *string) (*ec2types.Subnet, error) {
 	return &describeSubnetOutput.Subnets[0], nil
 }
 
+// GetSubnets returns the subnets in the VPC and availability zone with the given subnet ids and all the given tags,
+// the subnets are not filtered by ids or tags if they are empty
+func (h *ec2APIHelper) GetSubnets(ctx context.Context, subnetIDs []string, tags map[string]string, vpcID string,
+	availabilityZone string) ([]ec2types.Subnet, error) {
+	filters := []ec2types.Filter{
+		{
+			Name:   aws.String("vpc-id"),
+			Values: []string{vpcID},
+		},
+		{
+			Name:   aws.String("availability-zone"),
+			Values: []string{availabilityZone},
+		},
+	}
+	for _, key := range slices.Sorted(maps.Keys(tags)) {
+		filters = append(filters, ec2types.Filter{
+			Name:   aws.String("tag:" + key),
+			Values: []string{tags[key]},
+		})
+	}
+
+	describeSubnetsInput := &ec2.DescribeSubnetsInput{
+		SubnetIds: subnetIDs,
+		Filters:   filters,
+	}
+	var subnets []ec2types.Subnet
+	for {
+		describeSubnetsOutput, err := h.ec2Wrapper.DescribeSubnets(ctx, describeSubnetsInput)
+		if err != nil {
+			return nil, err
+		}
+		if describeSubnetsOutput == nil {
+			break
+		}
+
+		subnets = append(subnets, describeSubnetsOutput.Subnets...)
+
+		if describeSubnetsOutput.NextToken == nil {
+			break
+		}
+		describeSubnetsInput.NextToken = describeSubnetsOutput.NextToken
+	}
+	return subnets, nil
+}
+
+// GetSecurityGroups returns the security groups with the given group ids, the groups that don't exist are not
+// returned
+func (h *ec2APIHelper) GetSecurityGroups(ctx context.Context, groupIDs []string) ([]ec2types.SecurityGroup, error) {
+	// Filter on the group id instead of passing the group ids in the request, as EC2 fails the entire
+	// request if any of the group ids is not found
+	describeSecurityGroupsInput := &ec2.DescribeSecurityGroupsInput{
+		Filters: []ec2types.Filter{
+			{
+				Name:   aws.String("group-id"),
+				Values: groupIDs,
+			},
+		},
+	}
+	var securityGroups []ec2types.SecurityGroup
+	for {
+		describeSecurityGroupsOutput, err := h.ec2Wrapper.DescribeSecurityGroups(ctx, describeSecurityGroupsInput)
+		if err != nil {
+			return nil, err
+		}
+		if describeSecurityGroupsOutput == nil {
+			break
+		}
+
+		securityGroups = append(securityGroups, describeSecurityGroupsOutput.SecurityGroups...)
+
+		if describeSecurityGroupsOutput.NextToken == nil {
+			break
+		}
+		describeSecurityGroupsInput.NextToken = describeSecurityGroupsOutput.NextToken
+	}
+	return securityGroups, nil
+}
+
 // DeleteNetworkInterface deletes a network interface with retries with exponential back offs
-func (h *ec2APIHelper) DeleteNetworkInterface(interfaceId *string) error {
+func (h *ec2APIHelper) DeleteNetworkInterface(ctx context.Context, interfaceId *string) error {
 	deleteNetworkInterface := &ec2.DeleteNetworkInterfaceInput{
 		NetworkInterfaceId: interfaceId,
 	}
 
 	err := retry.OnError(defaultBackOff, func(err error) bool { return true }, func() error {
-		_, err := h.ec2Wrapper.DeleteNetworkInterface(context.TODO(), deleteNetworkInterface)
+		_, err := h.ec2Wrapper.DeleteNetworkInterface(ctx, deleteNetworkInterface)
 		return err
 	})
 
@@ -217,8 +303,8 @@ func (h *ec2APIHelper) DeleteNetworkInterface(interfaceId *string) error {
 }
 
 // GetInstanceNetworkInterface returns all the network interface associated with an instance id
-func (h *ec2APIHelper) GetInstanceNetworkInterface(instanceId *string) ([]ec2types.InstanceNetworkInterface, error) {
-	instanceDetails, err := h.GetInstanceDetails(instanceId)
+func (h *ec2APIHelper) GetInstanceNetworkInterface(ctx context.Context, instanceId *string) ([]ec2types.InstanceNetworkInterface, error) {
+	instanceDetails, err := h.GetInstanceDetails(ctx, instanceId)
 	if err != nil {
 		return nil, err
 	}
@@ -232,11 +318,11 @@ func (h *ec2APIHelper) GetInstanceNetworkInterface(instanceId *string) ([]ec2typ
 }
 
 // DescribeNetworkInterfaces returns the network interface details of the given network interface ids
-func (h *ec2APIHelper) DescribeNetworkInterfaces(nwInterfaceIds []string) ([]ec2types.NetworkInterface, error) {
+func (h *ec2APIHelper) DescribeNetworkInterfaces(ctx context.Context, nwInterfaceIds []string) ([]ec2types.NetworkInterface, error) {
 	describeNetworkInterfacesInput := &ec2.DescribeNetworkInterfacesInput{
 		NetworkInterfaceIds: nwInterfaceIds,
 	}
-	describeNetworkInterfaceOutput, err := h.ec2Wrapper.DescribeNetworkInterfaces(describeNetworkInterfacesInput)
+	describeNetworkInterfaceOutput, err := h.ec2Wrapper.DescribeNetworkInterfaces(ctx, describeNetworkInterfacesInput)
 	if err != nil {
 		return nil, err
 	}
@@ -249,9 +335,32 @@ func (h *ec2APIHelper) DescribeNetworkInterfaces(nwInterfaceIds []string) ([]ec2
 		*describeNetworkInterfacesInput)
 }
 
+// GetNetworkInterfaceSecurityGroups returns the security groups of the network interfaces by network interface id. The
+// network interfaces are described with a filter in batches of maxDescribeBatchSize IDs, so unlike describing by IDs
+// the network interfaces that don't exist are left out of the result instead of failing the call
+func (h *ec2APIHelper) GetNetworkInterfaceSecurityGroups(ctx context.Context, nwInterfaceIDs []string) (map[string][]string, error) {
+	securityGroups := make(map[string][]string, len(nwInterfaceIDs))
+	for ids := range slices.Chunk(nwInterfaceIDs, maxDescribeBatchSize) {
+		nwInterfaces, err := h.ec2Wrapper.DescribeNetworkInterfacesPages(ctx, &ec2.DescribeNetworkInterfacesInput{
+			Filters: []ec2types.Filter{{Name: aws.String("network-interface-id"), Values: ids}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, nwInterface := range nwInterfaces {
+			groups := make([]string, 0, len(nwInterface.Groups))
+			for _, group := range nwInterface.Groups {
+				groups = append(groups, aws.ToString(group.GroupId))
+			}
+			securityGroups[aws.ToString(nwInterface.NetworkInterfaceId)] = groups
+		}
+	}
+	return securityGroups, nil
+}
+
 // TODO: Not used currently as the API is not publicly available with assumed role
 // DescribeTrunkInterfaceAssociation describes all the association of the given trunk interface id
-func (h *ec2APIHelper) DescribeTrunkInterfaceAssociation(trunkInterfaceId *string) ([]ec2types.TrunkInterfaceAssociation, error) {
+func (h *ec2APIHelper) DescribeTrunkInterfaceAssociation(ctx context.Context, trunkInterfaceId *string) ([]ec2types.TrunkInterfaceAssociation, error) {
 	describeTrunkInterfaceAssociationInput := &ec2.DescribeTrunkInterfaceAssociationsInput{
 		Filters: []ec2types.Filter{
 			{
@@ -260,7 +369,7 @@ func (h *ec2APIHelper) DescribeTrunkInterfaceAssociation(trunkInterfaceId *strin
 			},
 		},
 	}
-	describeTrunkInterfaceAssociationOutput, err := h.ec2Wrapper.DescribeTrunkInterfaceAssociations(describeTrunkInterfaceAssociationInput)
+	describeTrunkInterfaceAssociationOutput, err := h.ec2Wrapper.DescribeTrunkInterfaceAssociations(ctx, describeTrunkInterfaceAssociationInput)
 	if err != nil {
 		return nil, err
 	}
@@ -275,7 +384,7 @@ func (h *ec2APIHelper) DescribeTrunkInterfaceAssociation(trunkInterfaceId *strin
 }
 
 // AssociateBranchToTrunk associates a branch network interface to a trunk network interface
-func (h *ec2APIHelper) AssociateBranchToTrunk(trunkInterfaceId *string, branchInterfaceId *string,
+func (h *ec2APIHelper) AssociateBranchToTrunk(ctx context.Context, trunkInterfaceId *string, branchInterfaceId *string,
 	vlanId int,
 ) (*ec2.AssociateTrunkInterfaceOutput, error) {
 	// Get attach permission from User's Service Linked Role. Account ID will be added by the EC2 API Wrapper
@@ -284,7 +393,7 @@ func (h *ec2APIHelper) AssociateBranchToTrunk(trunkInterfaceId *string, branchIn
 		Permission:         ec2types.InterfacePermissionType(ec2types.InterfacePermissionTypeInstanceAttach),
 	}
 
-	_, err := h.ec2Wrapper.CreateNetworkInterfacePermission(input)
+	_, err := h.ec2Wrapper.CreateNetworkInterfacePermission(ctx, input)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get attach network interface permissions for branch %v", err)
 	}
@@ -300,7 +409,7 @@ func (h *ec2APIHelper) AssociateBranchToTrunk(trunkInterfaceId *string, branchIn
 		VlanId:            aws.Int32(vlanId32),
 	}
 
-	associateTrunkInterfaceOutput, err := h.ec2Wrapper.AssociateTrunkInterface(associateTrunkInterfaceIP)
+	associateTrunkInterfaceOutput, err := h.ec2Wrapper.AssociateTrunkInterface(ctx, associateTrunkInterfaceIP)
 	if err != nil {
 		return associateTrunkInterfaceOutput, err
 	}
@@ -317,19 +426,20 @@ func (h *ec2APIHelper) AssociateBranchToTrunk(trunkInterfaceId *string, branchIn
 
 // CreateAndAttachNetworkInterface creates and attaches the network interface to the instance. The function will
 // wait till the interface is successfully attached
-func (h *ec2APIHelper) CreateAndAttachNetworkInterface(instanceId *string, subnetId *string, securityGroups []string,
-	tags []ec2types.Tag, deviceIndex *int32, description *string, interfaceType *string, ipResourceCount *config.IPResourceCount,
+func (h *ec2APIHelper) CreateAndAttachNetworkInterface(ctx context.Context, instanceId *string, subnetId *string,
+	securityGroups []string, tags []ec2types.Tag, deviceIndex *int32, description *string, interfaceType *string,
+	ipResourceCount *config.IPResourceCount,
 ) (*ec2types.NetworkInterface, error) {
-	nwInterface, err := h.CreateNetworkInterface(description, subnetId, securityGroups, tags, ipResourceCount, interfaceType)
+	nwInterface, err := h.CreateNetworkInterface(ctx, description, subnetId, securityGroups, tags, ipResourceCount, interfaceType)
 	if err != nil {
 		return nil, fmt.Errorf("creating network interface, %w", err)
 	}
 
 	var attachmentId *string
 
-	attachmentId, err = h.AttachNetworkInterfaceToInstance(instanceId, nwInterface.NetworkInterfaceId, deviceIndex)
+	attachmentId, err = h.AttachNetworkInterfaceToInstance(ctx, instanceId, nwInterface.NetworkInterfaceId, deviceIndex)
 	if err != nil {
-		errDelete := h.DeleteNetworkInterface(nwInterface.NetworkInterfaceId)
+		errDelete := h.DeleteNetworkInterface(ctx, nwInterface.NetworkInterfaceId)
 		if errDelete != nil {
 			return nwInterface, fmt.Errorf("failed to attach the network interface %v: failed to delete the nw interfac %v",
 				err, errDelete)
@@ -337,9 +447,9 @@ func (h *ec2APIHelper) CreateAndAttachNetworkInterface(instanceId *string, subne
 		return nil, fmt.Errorf("attaching network interface, %w", err)
 	}
 
-	err = h.SetDeleteOnTermination(attachmentId, nwInterface.NetworkInterfaceId)
+	err = h.SetDeleteOnTermination(ctx, attachmentId, nwInterface.NetworkInterfaceId)
 	if err != nil {
-		errDelete := h.DetachAndDeleteNetworkInterface(attachmentId, nwInterface.NetworkInterfaceId)
+		errDelete := h.DetachAndDeleteNetworkInterface(ctx, attachmentId, nwInterface.NetworkInterfaceId)
 		if errDelete != nil {
 			return nwInterface, fmt.Errorf("failed to set deletion on termination: %v: failed to delete nw interface: %v",
 				err, errDelete)
@@ -347,9 +457,9 @@ func (h *ec2APIHelper) CreateAndAttachNetworkInterface(instanceId *string, subne
 		return nil, fmt.Errorf("enabling delete on termination, %w", err)
 	}
 
-	err = h.WaitForNetworkInterfaceStatusChange(nwInterface.NetworkInterfaceId, string(ec2types.AttachmentStatusAttached))
+	err = h.WaitForNetworkInterfaceStatusChange(ctx, nwInterface.NetworkInterfaceId, string(ec2types.AttachmentStatusAttached))
 	if err != nil {
-		errDelete := h.DetachAndDeleteNetworkInterface(attachmentId, nwInterface.NetworkInterfaceId)
+		errDelete := h.DetachAndDeleteNetworkInterface(ctx, attachmentId, nwInterface.NetworkInterfaceId)
 		if errDelete != nil {
 			return nwInterface, fmt.Errorf("failed to verify status attached: %v: failed to delete nw interface: %v",
 				err, errDelete)
@@ -357,11 +467,19 @@ func (h *ec2APIHelper) CreateAndAttachNetworkInterface(instanceId *string, subne
 		return nil, fmt.Errorf("waiting for network attachement, %w", err)
 	}
 
+	// The created network interface doesn't have the attachment details, set them so the caller can detach it later
+	nwInterface.Attachment = &ec2types.NetworkInterfaceAttachment{
+		AttachmentId: attachmentId,
+		DeviceIndex:  deviceIndex,
+		InstanceId:   instanceId,
+		Status:       ec2types.AttachmentStatusAttached,
+	}
+
 	return nwInterface, nil
 }
 
 // SetDeleteOnTermination sets the deletion on termination of the network interface to true
-func (h *ec2APIHelper) SetDeleteOnTermination(attachmentId *string, eniId *string) error {
+func (h *ec2APIHelper) SetDeleteOnTermination(ctx context.Context, attachmentId *string, eniId *string) error {
 	modifyNetworkInterfaceInput := &ec2.ModifyNetworkInterfaceAttributeInput{
 		Attachment: &ec2types.NetworkInterfaceAttachmentChanges{
 			AttachmentId:        attachmentId,
@@ -370,20 +488,33 @@ func (h *ec2APIHelper) SetDeleteOnTermination(attachmentId *string, eniId *strin
 		NetworkInterfaceId: eniId,
 	}
 
-	_, err := h.ec2Wrapper.ModifyNetworkInterfaceAttribute(modifyNetworkInterfaceInput)
+	_, err := h.ec2Wrapper.ModifyNetworkInterfaceAttribute(ctx, modifyNetworkInterfaceInput)
+
+	return err
+}
+
+// ModifyNetworkInterfaceSecurityGroups replaces the security groups of the network interface with the given
+// security groups
+func (h *ec2APIHelper) ModifyNetworkInterfaceSecurityGroups(ctx context.Context, eniId *string, securityGroups []string) error {
+	modifyNetworkInterfaceInput := &ec2.ModifyNetworkInterfaceAttributeInput{
+		Groups:             securityGroups,
+		NetworkInterfaceId: eniId,
+	}
+
+	_, err := h.ec2Wrapper.ModifyNetworkInterfaceAttribute(ctx, modifyNetworkInterfaceInput)
 
 	return err
 }
 
 // AttachNetworkInterfaceToInstance attaches the network interface to the instance
-func (h *ec2APIHelper) AttachNetworkInterfaceToInstance(instanceId *string, nwInterfaceId *string, deviceIndex *int32) (*string, error) {
+func (h *ec2APIHelper) AttachNetworkInterfaceToInstance(ctx context.Context, instanceId *string, nwInterfaceId *string, deviceIndex *int32) (*string, error) {
 	attachNetworkInterfaceInput := &ec2.AttachNetworkInterfaceInput{
 		DeviceIndex:        deviceIndex,
 		InstanceId:         instanceId,
 		NetworkInterfaceId: nwInterfaceId,
 	}
 
-	attachNetworkInterfaceOutput, err := h.ec2Wrapper.AttachNetworkInterface(attachNetworkInterfaceInput)
+	attachNetworkInterfaceOutput, err := h.ec2Wrapper.AttachNetworkInterface(ctx, attachNetworkInterfaceInput)
 	if err != nil {
 		return nil, err
 	}
@@ -395,18 +526,18 @@ func (h *ec2APIHelper) AttachNetworkInterfaceToInstance(instanceId *string, nwIn
 }
 
 // DetachNetworkInterfaceFromInstance detaches a network interface using the attachment id
-func (h *ec2APIHelper) DetachNetworkInterfaceFromInstance(attachmentId *string) error {
+func (h *ec2APIHelper) DetachNetworkInterfaceFromInstance(ctx context.Context, attachmentId *string) error {
 	input := &ec2.DetachNetworkInterfaceInput{
 		AttachmentId: attachmentId,
 	}
 
-	_, err := h.ec2Wrapper.DetachNetworkInterface(input)
+	_, err := h.ec2Wrapper.DetachNetworkInterface(ctx, input)
 	return err
 }
 
 // WaitForNetworkInterfaceStatusChange checks if the current network interface attachment status
 // equals the desired status with backoff
-func (h *ec2APIHelper) WaitForNetworkInterfaceStatusChange(networkInterfaceId *string, desiredStatus string) error {
+func (h *ec2APIHelper) WaitForNetworkInterfaceStatusChange(ctx context.Context, networkInterfaceId *string, desiredStatus string) error {
 	ErrRetryAttachmentStatusCheck := fmt.Errorf("interface not in desired status yet %s, interface id %s",
 		desiredStatus, *networkInterfaceId)
 
@@ -414,7 +545,7 @@ func (h *ec2APIHelper) WaitForNetworkInterfaceStatusChange(networkInterfaceId *s
 		func(err error) bool {
 			return err == ErrRetryAttachmentStatusCheck
 		}, func() error {
-			interfaces, err := h.DescribeNetworkInterfaces([]string{*networkInterfaceId})
+			interfaces, err := h.DescribeNetworkInterfaces(ctx, []string{*networkInterfaceId})
 			if err == nil && len(interfaces) == 1 {
 				attachment := interfaces[0].Attachment
 				if attachment != nil && attachment.Status == ec2types.AttachmentStatus(desiredStatus) {
@@ -430,12 +561,12 @@ func (h *ec2APIHelper) WaitForNetworkInterfaceStatusChange(networkInterfaceId *s
 }
 
 // GetInstanceDetails returns the details of the instance
-func (h *ec2APIHelper) GetInstanceDetails(instanceId *string) (*ec2types.Instance, error) {
+func (h *ec2APIHelper) GetInstanceDetails(ctx context.Context, instanceId *string) (*ec2types.Instance, error) {
 	describeInstanceInput := &ec2.DescribeInstancesInput{
 		InstanceIds: []string{*instanceId},
 	}
 
-	describeInstanceOutput, err := h.ec2Wrapper.DescribeInstances(describeInstanceInput)
+	describeInstanceOutput, err := h.ec2Wrapper.DescribeInstances(ctx, describeInstanceInput)
 	if err != nil {
 		return nil, err
 	}
@@ -449,7 +580,7 @@ func (h *ec2APIHelper) GetInstanceDetails(instanceId *string) (*ec2types.Instanc
 	return nil, fmt.Errorf("failed to find instance details for input %v", *describeInstanceInput)
 }
 
-func (h *ec2APIHelper) AssignIPv4ResourcesAndWaitTillReady(eniID string, resourceType config.ResourceType, count int) ([]string, error) {
+func (h *ec2APIHelper) AssignIPv4ResourcesAndWaitTillReady(ctx context.Context, eniID string, resourceType config.ResourceType, count int) ([]string, error) {
 	var assignedResources []string
 	input := &ec2.AssignPrivateIpAddressesInput{}
 
@@ -471,7 +602,7 @@ func (h *ec2APIHelper) AssignIPv4ResourcesAndWaitTillReady(eniID string, resourc
 		}
 	}
 
-	assignPrivateIPOutput, err := h.ec2Wrapper.AssignPrivateIPAddresses(input)
+	assignPrivateIPOutput, err := h.ec2Wrapper.AssignPrivateIPAddresses(ctx, input)
 	if err != nil {
 		return assignedResources, err
 	}
@@ -494,7 +625,7 @@ func (h *ec2APIHelper) AssignIPv4ResourcesAndWaitTillReady(eniID string, resourc
 			return false
 		}, func() error {
 			// Describe the network interface on which the new IP or prefixes are assigned
-			interfaces, err := h.DescribeNetworkInterfaces([]string{eniID})
+			interfaces, err := h.DescribeNetworkInterfaces(ctx, []string{eniID})
 			// Re-initialize the slice so that we don't add IP resources multiple times
 			assignedResources = []string{}
 
@@ -549,7 +680,7 @@ func (h *ec2APIHelper) AssignIPv4ResourcesAndWaitTillReady(eniID string, resourc
 }
 
 // UnassignIPv4Resources un-assigns IPv4 address or prefix from the interface and waits till it succeeds
-func (h *ec2APIHelper) UnassignIPv4Resources(eniID string, resourceType config.ResourceType, resources []string) error {
+func (h *ec2APIHelper) UnassignIPv4Resources(ctx context.Context, eniID string, resourceType config.ResourceType, resources []string) error {
 	unassignPrivateIpAddressesInput := &ec2.UnassignPrivateIpAddressesInput{}
 
 	// Use respective input param depending on which resource type is being unassigned
@@ -566,26 +697,92 @@ func (h *ec2APIHelper) UnassignIPv4Resources(eniID string, resourceType config.R
 		}
 	}
 
-	_, err := h.ec2Wrapper.UnassignPrivateIPAddresses(unassignPrivateIpAddressesInput)
+	_, err := h.ec2Wrapper.UnassignPrivateIPAddresses(ctx, unassignPrivateIpAddressesInput)
 	return err
 }
 
-func (h *ec2APIHelper) GetBranchNetworkInterface(trunkID, subnetID *string) ([]*ec2types.NetworkInterface, error) {
+// AssignIPv6PrefixesAndWaitTillReady assigns the count of /80 IPv6 prefixes to the interface and returns the list of
+// prefixes once they are reflected on the interface
+func (h *ec2APIHelper) AssignIPv6PrefixesAndWaitTillReady(ctx context.Context, eniID string, count int) ([]string, error) {
+	var assignedPrefixes []string
+
+	count32, err := utils.IntToInt32(count)
+	if err != nil {
+		return nil, fmt.Errorf("invalid count: %v", err)
+	}
+
+	assignIPv6Output, err := h.ec2Wrapper.AssignIPv6Addresses(ctx, &ec2.AssignIpv6AddressesInput{
+		NetworkInterfaceId: &eniID,
+		Ipv6PrefixCount:    aws.Int32(count32),
+	})
+	if err != nil {
+		return assignedPrefixes, err
+	}
+
+	if assignIPv6Output == nil || len(assignIPv6Output.AssignedIpv6Prefixes) == 0 {
+		return assignedPrefixes, fmt.Errorf("failed to create %v %s to eni %s", count, config.ResourceTypeIPv6Prefix, eniID)
+	}
+
+	ErrPrefixNotAttachedYet := fmt.Errorf("IPv6 prefix is not attached yet")
+
+	err = retry.OnError(waitForIPAttachment,
+		func(err error) bool {
+			// Retry in case IPv6 prefixes are not attached yet
+			return err == ErrPrefixNotAttachedYet
+		}, func() error {
+			// Describe the network interface on which the new prefixes are assigned
+			interfaces, err := h.DescribeNetworkInterfaces(ctx, []string{eniID})
+			// Re-initialize the slice so that we don't add prefixes multiple times
+			assignedPrefixes = []string{}
+
+			if err == nil && len(interfaces) == 1 && interfaces[0].Ipv6Prefixes != nil {
+				ipv6Prefixes := map[string]bool{}
+				for _, ipv6Prefix := range interfaces[0].Ipv6Prefixes {
+					ipv6Prefixes[*ipv6Prefix.Ipv6Prefix] = true
+				}
+				// Verify describe network interface returns all the IPv6 prefixes that were assigned in the
+				// AssignIpv6Addresses call
+				for _, prefix := range assignIPv6Output.AssignedIpv6Prefixes {
+					if _, ok := ipv6Prefixes[prefix]; !ok {
+						err = ErrPrefixNotAttachedYet
+					} else {
+						assignedPrefixes = append(assignedPrefixes, prefix)
+					}
+				}
+			}
+			return err
+		})
+	if err != nil {
+		// If some of the assigned prefixes were not yet returned in the describe network interface call,
+		// returns the list of prefixes that were returned
+		return assignedPrefixes, err
+	}
+
+	return assignedPrefixes, nil
+}
+
+// UnassignIPv6Prefixes un-assigns the IPv6 prefixes from the interface
+func (h *ec2APIHelper) UnassignIPv6Prefixes(ctx context.Context, eniID string, prefixes []string) error {
+	_, err := h.ec2Wrapper.UnassignIPv6Addresses(ctx, &ec2.UnassignIpv6AddressesInput{
+		NetworkInterfaceId: &eniID,
+		Ipv6Prefixes:       prefixes,
+	})
+	return err
+}
+
+// GetBranchNetworkInterface returns the branch network interfaces associated with the trunk across all subnets
+func (h *ec2APIHelper) GetBranchNetworkInterface(ctx context.Context, trunkID *string) ([]*ec2types.NetworkInterface, error) {
 	filters := []ec2types.Filter{
 		{
 			Name:   aws.String("tag:" + config.TrunkENIIDTag),
 			Values: []string{*trunkID},
 		},
-		{
-			Name:   aws.String("subnet-id"),
-			Values: []string{*subnetID},
-		},
 	}
 
 	describeNetworkInterfacesInput := &ec2.DescribeNetworkInterfacesInput{Filters: filters}
 	var nwInterfaces []*ec2types.NetworkInterface
 	for {
-		describeNetworkInterfaceOutput, err := h.ec2Wrapper.DescribeNetworkInterfaces(describeNetworkInterfacesInput)
+		describeNetworkInterfaceOutput, err := h.ec2Wrapper.DescribeNetworkInterfaces(ctx, describeNetworkInterfacesInput)
 		if err != nil {
 			return nil, err
 		}
@@ -601,6 +798,7 @@ func (h *ec2APIHelper) GetBranchNetworkInterface(trunkID, subnetID *string) ([]*
 			// Only attach the required details to avoid consuming extra memory
 			nwInterfaces = append(nwInterfaces, &ec2types.NetworkInterface{
 				NetworkInterfaceId: nwInterface.NetworkInterfaceId,
+				SubnetId:           nwInterface.SubnetId,
 				TagSet:             nwInterface.TagSet,
 			})
 		}
@@ -615,25 +813,25 @@ func (h *ec2APIHelper) GetBranchNetworkInterface(trunkID, subnetID *string) ([]*
 }
 
 // DetachAndDeleteNetworkInterface detaches the network interface first and then deletes it
-func (h *ec2APIHelper) DetachAndDeleteNetworkInterface(attachmentID *string, nwInterfaceID *string) error {
-	err := h.DetachNetworkInterfaceFromInstance(attachmentID)
+func (h *ec2APIHelper) DetachAndDeleteNetworkInterface(ctx context.Context, attachmentID *string, nwInterfaceID *string) error {
+	err := h.DetachNetworkInterfaceFromInstance(ctx, attachmentID)
 	if err != nil {
 		return err
 	}
-	err = h.WaitForNetworkInterfaceStatusChange(nwInterfaceID, string(ec2types.AttachmentStatusDetached))
+	err = h.WaitForNetworkInterfaceStatusChange(ctx, nwInterfaceID, string(ec2types.AttachmentStatusDetached))
 	if err != nil {
 		return err
 	}
-	err = h.DeleteNetworkInterface(nwInterfaceID)
+	err = h.DeleteNetworkInterface(ctx, nwInterfaceID)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (h *ec2APIHelper) DisassociateTrunkInterface(associationID *string) error {
+func (h *ec2APIHelper) DisassociateTrunkInterface(ctx context.Context, associationID *string) error {
 	input := &ec2.DisassociateTrunkInterfaceInput{
 		AssociationId: associationID,
 	}
-	return h.ec2Wrapper.DisassociateTrunkInterface(input)
+	return h.ec2Wrapper.DisassociateTrunkInterface(ctx, input)
 }
@@ -51,6 +51,9 @@ var (
 	ipPrefix1 = "192.168.1.0/28"
 	ipPrefix2 = "192.168.2.0/28"
 
+	ipv6Prefix1 = "2600:1f14:abc:de00:1234::/80"
+	ipv6Prefix2 = "2600:1f14:abc:de00:5678::/80"
+
 	// branch to trunk association id
 	branchAssociationId = "association-00000000000000"
 	vlanId              = 0
@@ -188,10 +191,6 @@ var (
 				Name:   aws.String("tag:" + config.TrunkENIIDTag),
 				Values: []string{trunkInterfaceId},
 			},
-			{
-				Name:   aws.String("subnet-id"),
-				Values: []string{subnetId},
-			},
 		},
 	}
 
@@ -310,6 +309,26 @@ var (
 		},
 	}
 
+	assignIPv6InputPrefix = &ec2.AssignIpv6AddressesInput{
+		NetworkInterfaceId: &eniID,
+		Ipv6PrefixCount:    aws.Int32(int32(2)),
+	}
+
+	assignIPv6OutputPrefix = &ec2.AssignIpv6AddressesOutput{
+		AssignedIpv6Prefixes: []string{ipv6Prefix1, ipv6Prefix2},
+	}
+
+	describeNetworkInterfaceOutputIPv6Prefix = &ec2.DescribeNetworkInterfacesOutput{
+		NetworkInterfaces: []ec2types.NetworkInterface{
+			{
+				Ipv6Prefixes: []ec2types.Ipv6PrefixSpecification{
+					{Ipv6Prefix: &ipv6Prefix1},
+					{Ipv6Prefix: &ipv6Prefix2},
+				},
+			},
+		},
+	}
+
 	createNetworkInterfacePermissionInputBranch = &ec2.CreateNetworkInterfacePermissionInput{
 		NetworkInterfaceId: &branchInterfaceId,
 		Permission:         ec2types.InterfacePermissionTypeInstanceAttach,
@@ -361,12 +380,12 @@ func TestEc2APIHelper_AssociateBranchToTrunk(t *testing.T) {
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
 	// Return response with association id
-	mockWrapper.EXPECT().AssociateTrunkInterface(associateTrunkInterfaceInput).
+	mockWrapper.EXPECT().AssociateTrunkInterface(gomock.Any(), associateTrunkInterfaceInput).
 		Return(associateTrunkInterfaceOutput, nil)
-	mockWrapper.EXPECT().CreateNetworkInterfacePermission(createNetworkInterfacePermissionInputBranch).
+	mockWrapper.EXPECT().CreateNetworkInterfacePermission(gomock.Any(), createNetworkInterfacePermissionInputBranch).
 		Return(nil, nil)
 
-	_, err := ec2ApiHelper.AssociateBranchToTrunk(&trunkInterfaceId, &branchInterfaceId, vlanId)
+	_, err := ec2ApiHelper.AssociateBranchToTrunk(context.TODO(), &trunkInterfaceId, &branchInterfaceId, vlanId)
 
 	assert.NoError(t, err)
 }
@@ -380,12 +399,12 @@ func TestEc2APIHelper_AssociateBranchToTrunk_AssociationIdMissing(t *testing.T)
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
 	// Return empty association response
-	mockWrapper.EXPECT().AssociateTrunkInterface(associateTrunkInterfaceInput).
+	mockWrapper.EXPECT().AssociateTrunkInterface(gomock.Any(), associateTrunkInterfaceInput).
 		Return(&ec2.AssociateTrunkInterfaceOutput{}, nil)
-	mockWrapper.EXPECT().CreateNetworkInterfacePermission(createNetworkInterfacePermissionInputBranch).
+	mockWrapper.EXPECT().CreateNetworkInterfacePermission(gomock.Any(), createNetworkInterfacePermissionInputBranch).
 		Return(nil, nil)
 
-	_, err := ec2ApiHelper.AssociateBranchToTrunk(&trunkInterfaceId, &branchInterfaceId, vlanId)
+	_, err := ec2ApiHelper.AssociateBranchToTrunk(context.TODO(), &trunkInterfaceId, &branchInterfaceId, vlanId)
 
 	assert.NotNil(t, err)
 }
@@ -398,11 +417,11 @@ func TestEc2APIHelper_AssociateBranchToTrunk_Error(t *testing.T) {
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
 	// Return empty association response
-	mockWrapper.EXPECT().AssociateTrunkInterface(associateTrunkInterfaceInput).Return(nil, errMock)
-	mockWrapper.EXPECT().CreateNetworkInterfacePermission(createNetworkInterfacePermissionInputBranch).
+	mockWrapper.EXPECT().AssociateTrunkInterface(gomock.Any(), associateTrunkInterfaceInput).Return(nil, errMock)
+	mockWrapper.EXPECT().CreateNetworkInterfacePermission(gomock.Any(), createNetworkInterfacePermissionInputBranch).
 		Return(nil, nil)
 
-	_, err := ec2ApiHelper.AssociateBranchToTrunk(&trunkInterfaceId, &branchInterfaceId, vlanId)
+	_, err := ec2ApiHelper.AssociateBranchToTrunk(context.TODO(), &trunkInterfaceId, &branchInterfaceId, vlanId)
 
 	assert.Error(t, errMock, err)
 }
@@ -415,9 +434,9 @@ func TestEc2APIHelper_CreateNetworkInterface_NoSecondaryIP(t *testing.T) {
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
-	mockWrapper.EXPECT().CreateNetworkInterface(createNetworkInterfaceInput).Return(createNetworkInterfaceOutput, nil)
+	mockWrapper.EXPECT().CreateNetworkInterface(gomock.Any(), createNetworkInterfaceInput).Return(createNetworkInterfaceOutput, nil)
 	count := &config.IPResourceCount{SecondaryIPv4Count: 0, IPv4PrefixCount: 0}
-	output, err := ec2ApiHelper.CreateNetworkInterface(&eniDescription, &subnetId, securityGroups, tags, count, nil)
+	output, err := ec2ApiHelper.CreateNetworkInterface(context.TODO(), &eniDescription, &subnetId, securityGroups, tags, count, nil)
 
 	assert.NoError(t, err)
 	assert.Equal(t, branchInterfaceId, *output.NetworkInterfaceId)
@@ -434,11 +453,11 @@ func TestEc2APIHelper_CreateNetworkInterface_WithSecondaryIP(t *testing.T) {
 
 	createNetworkInterfaceInput.SecondaryPrivateIpAddressCount = &ipCount
 
-	mockWrapper.EXPECT().CreateNetworkInterface(createNetworkInterfaceInput).
+	mockWrapper.EXPECT().CreateNetworkInterface(gomock.Any(), createNetworkInterfaceInput).
 		Return(createNetworkInterfaceOutput, nil)
 
 	count := &config.IPResourceCount{SecondaryIPv4Count: 5, IPv4PrefixCount: 0}
-	output, err := ec2ApiHelper.CreateNetworkInterface(&eniDescription, &subnetId, securityGroups, tags, count, nil)
+	output, err := ec2ApiHelper.CreateNetworkInterface(context.TODO(), &eniDescription, &subnetId, securityGroups, tags, count, nil)
 
 	createNetworkInterfaceInput.SecondaryPrivateIpAddressCount = nil
 
@@ -457,11 +476,11 @@ func TestEc2APIHelper_CreateNetworkInterface_WithPrefixCount(t *testing.T) {
 
 	createNetworkInterfaceInput.Ipv4PrefixCount = &prefixCount
 
-	mockWrapper.EXPECT().CreateNetworkInterface(createNetworkInterfaceInput).
+	mockWrapper.EXPECT().CreateNetworkInterface(gomock.Any(), createNetworkInterfaceInput).
 		Return(createNetworkInterfaceOutput, nil)
 
 	count := &config.IPResourceCount{SecondaryIPv4Count: 0, IPv4PrefixCount: 5}
-	output, err := ec2ApiHelper.CreateNetworkInterface(&eniDescription, &subnetId, securityGroups, tags, count, nil)
+	output, err := ec2ApiHelper.CreateNetworkInterface(context.TODO(), &eniDescription, &subnetId, securityGroups, tags, count, nil)
 
 	createNetworkInterfaceInput.Ipv4PrefixCount = nil
 
@@ -482,7 +501,7 @@ func TestEc2APIHelper_CreateNetworkInterface_WithSecondaryIPAndPrefixCount_Error
 	createNetworkInterfaceInput.Ipv4PrefixCount = &ipCount
 
 	count := &config.IPResourceCount{SecondaryIPv4Count: 5, IPv4PrefixCount: 5}
-	_, err := ec2ApiHelper.CreateNetworkInterface(&eniDescription, &subnetId, securityGroups, tags, count, nil)
+	_, err := ec2ApiHelper.CreateNetworkInterface(context.TODO(), &eniDescription, &subnetId, securityGroups, tags, count, nil)
 
 	createNetworkInterfaceInput.SecondaryPrivateIpAddressCount = nil
 	createNetworkInterfaceInput.Ipv4PrefixCount = nil
@@ -501,14 +520,14 @@ func TestEc2APIHelper_CreateNetworkInterface_TypeTrunk(t *testing.T) {
 	interfaceTypeTrunkString := string(interfaceTypeTrunk)
 
 	createNetworkInterfaceInput.InterfaceType = interfaceTypeTrunk
-	mockWrapper.EXPECT().CreateNetworkInterface(createNetworkInterfaceInput).Return(
+	mockWrapper.EXPECT().CreateNetworkInterface(gomock.Any(), createNetworkInterfaceInput).Return(
 		&ec2.CreateNetworkInterfaceOutput{
 			NetworkInterface: &ec2types.NetworkInterface{NetworkInterfaceId: &trunkInterfaceId},
 		}, nil)
-	mockWrapper.EXPECT().CreateNetworkInterfacePermission(createNetworkInterfacePermissionInputTrunk).
+	mockWrapper.EXPECT().CreateNetworkInterfacePermission(gomock.Any(), createNetworkInterfacePermissionInputTrunk).
 		Return(nil, nil)
 
-	output, err := ec2ApiHelper.CreateNetworkInterface(&eniDescription, &subnetId, securityGroups, tags, nil, &interfaceTypeTrunkString)
+	output, err := ec2ApiHelper.CreateNetworkInterface(context.TODO(), &eniDescription, &subnetId, securityGroups, tags, nil, &interfaceTypeTrunkString)
 
 	createNetworkInterfaceInput.InterfaceType = ""
 
@@ -524,9 +543,9 @@ func TestEc2APIHelper_CreateNetworkInterface_EmptyResponse(t *testing.T) {
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
-	mockWrapper.EXPECT().CreateNetworkInterface(createNetworkInterfaceInput).Return(nil, nil)
+	mockWrapper.EXPECT().CreateNetworkInterface(gomock.Any(), createNetworkInterfaceInput).Return(nil, nil)
 
-	_, err := ec2ApiHelper.CreateNetworkInterface(&eniDescription, &subnetId, securityGroups, tags, nil, nil)
+	_, err := ec2ApiHelper.CreateNetworkInterface(context.TODO(), &eniDescription, &subnetId, securityGroups, tags, nil, nil)
 
 	assert.NotNil(t, err)
 }
@@ -538,9 +557,9 @@ func TestEc2APIHelper_CreateNetworkInterface_Error(t *testing.T) {
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
-	mockWrapper.EXPECT().CreateNetworkInterface(createNetworkInterfaceInput).Return(nil, errMock)
+	mockWrapper.EXPECT().CreateNetworkInterface(gomock.Any(), createNetworkInterfaceInput).Return(nil, errMock)
 
-	_, err := ec2ApiHelper.CreateNetworkInterface(&eniDescription, &subnetId, securityGroups, tags, nil, nil)
+	_, err := ec2ApiHelper.CreateNetworkInterface(context.TODO(), &eniDescription, &subnetId, securityGroups, tags, nil, nil)
 
 	assert.Error(t, errMock, err)
 }
@@ -555,7 +574,7 @@ func TestEc2APIHelper_DeleteNetworkInterface(t *testing.T) {
 
 	mockWrapper.EXPECT().DeleteNetworkInterface(context.TODO(), deleteNetworkInterfaceInput).Return(nil, nil)
 
-	err := ec2ApiHelper.DeleteNetworkInterface(&branchInterfaceId)
+	err := ec2ApiHelper.DeleteNetworkInterface(context.TODO(), &branchInterfaceId)
 	assert.NoError(t, err)
 }
 
@@ -569,7 +588,7 @@ func TestEc2APIHelper_DeleteNetworkInterface_Error(t *testing.T) {
 
 	mockWrapper.EXPECT().DeleteNetworkInterface(context.TODO(), deleteNetworkInterfaceInput).Return(nil, errMock).Times(maxRetryOnError)
 
-	err := ec2ApiHelper.DeleteNetworkInterface(&branchInterfaceId)
+	err := ec2ApiHelper.DeleteNetworkInterface(context.TODO(), &branchInterfaceId)
 	assert.Error(t, errMock, err)
 }
 
@@ -586,7 +605,7 @@ func TestEc2APIHelper_DeleteNetworkInterface_ErrorThenSuccess(t *testing.T) {
 		mockWrapper.EXPECT().DeleteNetworkInterface(context.TODO(), deleteNetworkInterfaceInput).Return(nil, nil).Times(1),
 	)
 
-	err := ec2ApiHelper.DeleteNetworkInterface(&branchInterfaceId)
+	err := ec2ApiHelper.DeleteNetworkInterface(context.TODO(), &branchInterfaceId)
 	assert.NoError(t, err)
 }
 
@@ -596,9 +615,9 @@ func TestEc2APIHelper_GetSubnet(t *testing.T) {
 	defer ctrl.Finish()
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
-	mockWrapper.EXPECT().DescribeSubnets(describeSubnetInput).Return(describeSubnetOutput, nil)
+	mockWrapper.EXPECT().DescribeSubnets(gomock.Any(), describeSubnetInput).Return(describeSubnetOutput, nil)
 
-	subnet, err := ec2ApiHelper.GetSubnet(&subnetId)
+	subnet, err := ec2ApiHelper.GetSubnet(context.TODO(), &subnetId)
 	assert.NoError(t, err)
 	assert.Equal(t, subnetId, *subnet.SubnetId)
 }
@@ -610,9 +629,9 @@ func TestEc2APIHelper_GetSubnet_NoSubnetReturned(t *testing.T) {
 	defer ctrl.Finish()
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
-	mockWrapper.EXPECT().DescribeSubnets(describeSubnetInput).Return(&ec2.DescribeSubnetsOutput{Subnets: []ec2types.Subnet{}}, nil)
+	mockWrapper.EXPECT().DescribeSubnets(gomock.Any(), describeSubnetInput).Return(&ec2.DescribeSubnetsOutput{Subnets: []ec2types.Subnet{}}, nil)
 
-	_, err := ec2ApiHelper.GetSubnet(&subnetId)
+	_, err := ec2ApiHelper.GetSubnet(context.TODO(), &subnetId)
 	assert.NotNil(t, err)
 }
 
@@ -622,12 +641,86 @@ func TestEc2APIHelper_GetSubnet_Error(t *testing.T) {
 	defer ctrl.Finish()
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
-	mockWrapper.EXPECT().DescribeSubnets(describeSubnetInput).Return(nil, errMock)
+	mockWrapper.EXPECT().DescribeSubnets(gomock.Any(), describeSubnetInput).Return(nil, errMock)
 
-	_, err := ec2ApiHelper.GetSubnet(&subnetId)
+	_, err := ec2ApiHelper.GetSubnet(context.TODO(), &subnetId)
 	assert.Error(t, errMock, err)
 }
 
+// TestEc2APIHelper_GetSubnets tests that the subnets are filtered by VPC, availability zone and tags and the paginated
+// results are returned
+func TestEc2APIHelper_GetSubnets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
+
+	subnetId2 := "subnet-2"
+	expectedInput := &ec2.DescribeSubnetsInput{
+		SubnetIds: []string{subnetId, subnetId2},
+		Filters: []ec2types.Filter{
+			{Name: aws.String("vpc-id"), Values: []string{"vpc-1"}},
+			{Name: aws.String("availability-zone"), Values: []string{"us-west-2a"}},
+			{Name: aws.String("tag:a"), Values: []string{"1"}},
+			{Name: aws.String("tag:b"), Values: []string{"2"}},
+		},
+	}
+	expectedInputWithToken := *expectedInput
+	expectedInputWithToken.NextToken = &tokenID
+
+	gomock.InOrder(
+		mockWrapper.EXPECT().DescribeSubnets(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, input *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+				assert.Equal(t, expectedInput.Filters, input.Filters)
+				assert.Nil(t, input.NextToken)
+				return &ec2.DescribeSubnetsOutput{Subnets: []ec2types.Subnet{{SubnetId: &subnetId}}, NextToken: &tokenID}, nil
+			}),
+		mockWrapper.EXPECT().DescribeSubnets(gomock.Any(), &expectedInputWithToken).Return(
+			&ec2.DescribeSubnetsOutput{Subnets: []ec2types.Subnet{{SubnetId: &subnetId2}}}, nil),
+	)
+
+	subnets, err := ec2ApiHelper.GetSubnets(context.TODO(), []string{subnetId, subnetId2}, map[string]string{"b": "2", "a": "1"},
+		"vpc-1", "us-west-2a")
+	assert.NoError(t, err)
+	assert.Equal(t, []ec2types.Subnet{{SubnetId: &subnetId}, {SubnetId: &subnetId2}}, subnets)
+}
+
+// TestEc2APIHelper_GetSubnets_Error tests that the error from ec2 api call is propagated to the caller
+func TestEc2APIHelper_GetSubnets_Error(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
+	mockWrapper.EXPECT().DescribeSubnets(gomock.Any(), gomock.Any()).Return(nil, errMock)
+
+	_, err := ec2ApiHelper.GetSubnets(context.TODO(), nil, map[string]string{"a": "1"}, "vpc-1", "us-west-2a")
+	assert.ErrorIs(t, err, errMock)
+}
+
+// TestEc2APIHelper_GetSecurityGroups tests the security groups are described by filtering on the group ids
+func TestEc2APIHelper_GetSecurityGroups(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
+
+	expectedInput := &ec2.DescribeSecurityGroupsInput{
+		Filters: []ec2types.Filter{{Name: aws.String("group-id"), Values: securityGroups}},
+	}
+	expectedInputWithToken := &ec2.DescribeSecurityGroupsInput{Filters: expectedInput.Filters, NextToken: &tokenID}
+
+	gomock.InOrder(
+		mockWrapper.EXPECT().DescribeSecurityGroups(gomock.Any(), expectedInput).Return(&ec2.DescribeSecurityGroupsOutput{
+			SecurityGroups: []ec2types.SecurityGroup{{GroupId: &securityGroup1}}, NextToken: &tokenID}, nil),
+		mockWrapper.EXPECT().DescribeSecurityGroups(gomock.Any(), expectedInputWithToken).Return(
+			&ec2.DescribeSecurityGroupsOutput{SecurityGroups: []ec2types.SecurityGroup{{GroupId: &securityGroup2}}}, nil),
+	)
+
+	groups, err := ec2ApiHelper.GetSecurityGroups(context.TODO(), securityGroups)
+	assert.NoError(t, err)
+	assert.Equal(t, []ec2types.SecurityGroup{{GroupId: &securityGroup1}, {GroupId: &securityGroup2}}, groups)
+}
+
 // TestEc2APIHelper_GetNetworkInterfaceOfInstance tests that describe network interface returns no errors
 // under valid input
 func TestEc2APIHelper_GetNetworkInterfaceOfInstance(t *testing.T) {
@@ -636,10 +729,10 @@ func TestEc2APIHelper_GetNetworkInterfaceOfInstance(t *testing.T) {
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
-	mockWrapper.EXPECT().DescribeInstances(describeNetworkInterfaceInputUsingInstanceId).
+	mockWrapper.EXPECT().DescribeInstances(gomock.Any(), describeNetworkInterfaceInputUsingInstanceId).
 		Return(describeNetworkInterfaceOutputUsingInstanceId, nil)
 
-	nwInterfaces, err := ec2ApiHelper.GetInstanceNetworkInterface(&instanceId)
+	nwInterfaces, err := ec2ApiHelper.GetInstanceNetworkInterface(context.TODO(), &instanceId)
 	assert.NoError(t, err)
 	assert.Equal(t, branchInterfaceId, *nwInterfaces[0].NetworkInterfaceId)
 }
@@ -652,9 +745,9 @@ func TestEc2APIHelper_GetNetworkInterfaceOfInstance_Error(t *testing.T) {
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
-	mockWrapper.EXPECT().DescribeInstances(describeNetworkInterfaceInputUsingInstanceId).Return(nil, errMock)
+	mockWrapper.EXPECT().DescribeInstances(gomock.Any(), describeNetworkInterfaceInputUsingInstanceId).Return(nil, errMock)
 
-	_, err := ec2ApiHelper.GetInstanceNetworkInterface(&instanceId)
+	_, err := ec2ApiHelper.GetInstanceNetworkInterface(context.TODO(), &instanceId)
 	assert.Error(t, errMock, err)
 }
 
@@ -666,10 +759,10 @@ func TestEc2APIHelper_DescribeNetworkInterfaces(t *testing.T) {
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
-	mockWrapper.EXPECT().DescribeNetworkInterfaces(describeNetworkInterfaceInputUsingInterfaceId).
+	mockWrapper.EXPECT().DescribeNetworkInterfaces(gomock.Any(), describeNetworkInterfaceInputUsingInterfaceId).
 		Return(describeNetworkInterfaceOutputUsingInterfaceId, nil)
 
-	nwInterfaces, err := ec2ApiHelper.DescribeNetworkInterfaces([]string{branchInterfaceId, branchInterfaceId2})
+	nwInterfaces, err := ec2ApiHelper.DescribeNetworkInterfaces(context.TODO(), []string{branchInterfaceId, branchInterfaceId2})
 	assert.NoError(t, err)
 	assert.Equal(t, 2, len(nwInterfaces))
 }
@@ -682,13 +775,43 @@ func TestEc2APIHelper_DescribeNetworkInterfaces_Error(t *testing.T) {
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
-	mockWrapper.EXPECT().DescribeNetworkInterfaces(describeNetworkInterfaceInputUsingInterfaceId).
+	mockWrapper.EXPECT().DescribeNetworkInterfaces(gomock.Any(), describeNetworkInterfaceInputUsingInterfaceId).
 		Return(nil, errMock)
 
-	_, err := ec2ApiHelper.DescribeNetworkInterfaces([]string{branchInterfaceId, branchInterfaceId2})
+	_, err := ec2ApiHelper.DescribeNetworkInterfaces(context.TODO(), []string{branchInterfaceId, branchInterfaceId2})
 	assert.Error(t, errMock, err)
 }
 
+// TestEc2APIHelper_GetNetworkInterfaceSecurityGroups tests the network interfaces are described with a filter in
+// batches and the security groups are returned by network interface id
+func TestEc2APIHelper_GetNetworkInterfaceSecurityGroups(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
+
+	var ids []string
+	for i := 0; i < maxDescribeBatchSize+1; i++ {
+		ids = append(ids, fmt.Sprintf("eni-%d", i))
+	}
+
+	mockWrapper.EXPECT().DescribeNetworkInterfacesPages(gomock.Any(), &ec2.DescribeNetworkInterfacesInput{
+		Filters: []ec2types.Filter{{Name: aws.String("network-interface-id"), Values: ids[:maxDescribeBatchSize]}},
+	}).Return([]*ec2types.NetworkInterface{{NetworkInterfaceId: aws.String(ids[0]),
+		Groups: []ec2types.GroupIdentifier{{GroupId: aws.String(securityGroup1)}, {GroupId: aws.String(securityGroup2)}}}}, nil)
+	mockWrapper.EXPECT().DescribeNetworkInterfacesPages(gomock.Any(), &ec2.DescribeNetworkInterfacesInput{
+		Filters: []ec2types.Filter{{Name: aws.String("network-interface-id"), Values: ids[maxDescribeBatchSize:]}},
+	}).Return(nil, nil)
+
+	securityGroups, err := ec2ApiHelper.GetNetworkInterfaceSecurityGroups(context.TODO(), ids)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{ids[0]: {securityGroup1, securityGroup2}}, securityGroups)
+
+	mockWrapper.EXPECT().DescribeNetworkInterfacesPages(gomock.Any(), gomock.Any()).Return(nil, errMock)
+	_, err = ec2ApiHelper.GetNetworkInterfaceSecurityGroups(context.TODO(), ids[:1])
+	assert.ErrorIs(t, err, errMock)
+}
+
 // TestEc2APIHelper_DescribeTrunkInterfaceAssociation tests that the describe trunk interface association returns
 // no errors under valid input
 func TestEc2APIHelper_DescribeTrunkInterfaceAssociation(t *testing.T) {
@@ -697,10 +820,10 @@ func TestEc2APIHelper_DescribeTrunkInterfaceAssociation(t *testing.T) {
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
-	mockWrapper.EXPECT().DescribeTrunkInterfaceAssociations(describeTrunkInterfaceAssociationsInput).
+	mockWrapper.EXPECT().DescribeTrunkInterfaceAssociations(gomock.Any(), describeTrunkInterfaceAssociationsInput).
 		Return(describeTrunkInterfaceAssociationsOutput, nil)
 
-	output, err := ec2ApiHelper.DescribeTrunkInterfaceAssociation(&trunkInterfaceId)
+	output, err := ec2ApiHelper.DescribeTrunkInterfaceAssociation(context.TODO(), &trunkInterfaceId)
 	assert.NoError(t, err)
 	assert.Equal(t, describeTrunkInterfaceAssociationsOutput.InterfaceAssociations, output)
 }
@@ -713,10 +836,10 @@ func TestEc2APIHelper_DescribeTrunkInterfaceAssociation_EmptyResponse(t *testing
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
-	mockWrapper.EXPECT().DescribeTrunkInterfaceAssociations(describeTrunkInterfaceAssociationsInput).
+	mockWrapper.EXPECT().DescribeTrunkInterfaceAssociations(gomock.Any(), describeTrunkInterfaceAssociationsInput).
 		Return(nil, nil)
 
-	_, err := ec2ApiHelper.DescribeTrunkInterfaceAssociation(&trunkInterfaceId)
+	_, err := ec2ApiHelper.DescribeTrunkInterfaceAssociation(context.TODO(), &trunkInterfaceId)
 	assert.NoError(t, err)
 }
 
@@ -728,10 +851,10 @@ func TestEc2APIHelper_DescribeTrunkInterfaceAssociation_Error(t *testing.T) {
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
-	mockWrapper.EXPECT().DescribeTrunkInterfaceAssociations(describeTrunkInterfaceAssociationsInput).
+	mockWrapper.EXPECT().DescribeTrunkInterfaceAssociations(gomock.Any(), describeTrunkInterfaceAssociationsInput).
 		Return(nil, errMock)
 
-	_, err := ec2ApiHelper.DescribeTrunkInterfaceAssociation(&trunkInterfaceId)
+	_, err := ec2ApiHelper.DescribeTrunkInterfaceAssociation(context.TODO(), &trunkInterfaceId)
 	assert.Error(t, errMock, err)
 }
 
@@ -744,13 +867,13 @@ func TestEc2APIHelper_CreateAndAttachNetworkInterface(t *testing.T) {
 	oldStatus := describeNetworkInterfaceOutputUsingOneInterfaceId.NetworkInterfaces[0].Attachment.Status
 	describeNetworkInterfaceOutputUsingOneInterfaceId.NetworkInterfaces[0].Attachment.Status = ec2types.AttachmentStatusAttached
 
-	mockWrapper.EXPECT().CreateNetworkInterface(createNetworkInterfaceInput).Return(createNetworkInterfaceOutput, nil)
-	mockWrapper.EXPECT().AttachNetworkInterface(attachNetworkInterfaceInput).Return(attachNetworkInterfaceOutput, nil)
-	mockWrapper.EXPECT().ModifyNetworkInterfaceAttribute(modifyNetworkInterfaceAttributeInput).Return(nil, nil)
-	mockWrapper.EXPECT().DescribeNetworkInterfaces(describeNetworkInterfaceInputUsingOneInterfaceId).
+	mockWrapper.EXPECT().CreateNetworkInterface(gomock.Any(), createNetworkInterfaceInput).Return(createNetworkInterfaceOutput, nil)
+	mockWrapper.EXPECT().AttachNetworkInterface(gomock.Any(), attachNetworkInterfaceInput).Return(attachNetworkInterfaceOutput, nil)
+	mockWrapper.EXPECT().ModifyNetworkInterfaceAttribute(gomock.Any(), modifyNetworkInterfaceAttributeInput).Return(nil, nil)
+	mockWrapper.EXPECT().DescribeNetworkInterfaces(gomock.Any(), describeNetworkInterfaceInputUsingOneInterfaceId).
 		Return(describeNetworkInterfaceOutputUsingOneInterfaceId, nil)
 
-	nwInterface, err := ec2ApiHelper.CreateAndAttachNetworkInterface(&instanceId, &subnetId, securityGroups, tags,
+	nwInterface, err := ec2ApiHelper.CreateAndAttachNetworkInterface(context.TODO(), &instanceId, &subnetId, securityGroups, tags,
 		&deviceIndex, &eniDescription, nil, nil)
 
 	// Clean up
@@ -758,6 +881,8 @@ func TestEc2APIHelper_CreateAndAttachNetworkInterface(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.Equal(t, branchInterfaceId, *nwInterface.NetworkInterfaceId)
+	assert.Equal(t, attachNetworkInterfaceOutput.AttachmentId, nwInterface.Attachment.AttachmentId)
+	assert.Equal(t, deviceIndex, *nwInterface.Attachment.DeviceIndex)
 }
 
 // TestEc2APIHelper_CreateAndAttachNetworkInterface_DeleteOnAttachFailed tests that delete is invoked if the attach
@@ -768,13 +893,13 @@ func TestEc2APIHelper_CreateAndAttachNetworkInterface_DeleteOnAttachFailed(t *te
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
-	mockWrapper.EXPECT().CreateNetworkInterface(createNetworkInterfaceInput).Return(createNetworkInterfaceOutput, nil)
-	mockWrapper.EXPECT().AttachNetworkInterface(attachNetworkInterfaceInput).Return(attachNetworkInterfaceOutput, errMock)
+	mockWrapper.EXPECT().CreateNetworkInterface(gomock.Any(), createNetworkInterfaceInput).Return(createNetworkInterfaceOutput, nil)
+	mockWrapper.EXPECT().AttachNetworkInterface(gomock.Any(), attachNetworkInterfaceInput).Return(attachNetworkInterfaceOutput, errMock)
 
 	// Test delete is called
 	mockWrapper.EXPECT().DeleteNetworkInterface(context.TODO(), deleteNetworkInterfaceInput).Return(nil, nil)
 
-	nwInterface, err := ec2ApiHelper.CreateAndAttachNetworkInterface(&instanceId, &subnetId, securityGroups, tags,
+	nwInterface, err := ec2ApiHelper.CreateAndAttachNetworkInterface(context.TODO(), &instanceId, &subnetId, securityGroups, tags,
 		&deviceIndex, &eniDescription, nil, nil)
 
 	assert.NotNil(t, err)
@@ -789,17 +914,17 @@ func TestEc2APIHelper_CreateAndAttachNetworkInterface_DeleteOnSetTerminationFail
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
-	mockWrapper.EXPECT().CreateNetworkInterface(createNetworkInterfaceInput).Return(createNetworkInterfaceOutput, nil)
-	mockWrapper.EXPECT().AttachNetworkInterface(attachNetworkInterfaceInput).Return(attachNetworkInterfaceOutput, nil)
-	mockWrapper.EXPECT().ModifyNetworkInterfaceAttribute(modifyNetworkInterfaceAttributeInput).Return(nil, errMock)
+	mockWrapper.EXPECT().CreateNetworkInterface(gomock.Any(), createNetworkInterfaceInput).Return(createNetworkInterfaceOutput, nil)
+	mockWrapper.EXPECT().AttachNetworkInterface(gomock.Any(), attachNetworkInterfaceInput).Return(attachNetworkInterfaceOutput, nil)
+	mockWrapper.EXPECT().ModifyNetworkInterfaceAttribute(gomock.Any(), modifyNetworkInterfaceAttributeInput).Return(nil, errMock)
 
 	// Test detach and delete is called
-	mockWrapper.EXPECT().DetachNetworkInterface(detachNetworkInterfaceInput).Return(nil, nil)
-	mockWrapper.EXPECT().DescribeNetworkInterfaces(describeNetworkInterfaceInputUsingOneInterfaceId).
+	mockWrapper.EXPECT().DetachNetworkInterface(gomock.Any(), detachNetworkInterfaceInput).Return(nil, nil)
+	mockWrapper.EXPECT().DescribeNetworkInterfaces(gomock.Any(), describeNetworkInterfaceInputUsingOneInterfaceId).
 		Return(describeNetworkInterfaceOutputUsingOneInterfaceId, nil)
 	mockWrapper.EXPECT().DeleteNetworkInterface(context.TODO(), deleteNetworkInterfaceInput).Return(nil, nil)
 
-	nwInterface, err := ec2ApiHelper.CreateAndAttachNetworkInterface(&instanceId, &subnetId, securityGroups, tags,
+	nwInterface, err := ec2ApiHelper.CreateAndAttachNetworkInterface(context.TODO(), &instanceId, &subnetId, securityGroups, tags,
 		&deviceIndex, &eniDescription, nil, nil)
 
 	assert.NotNil(t, err)
@@ -813,10 +938,10 @@ func TestEc2APIHelper_SetDeleteOnTermination(t *testing.T) {
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
-	mockWrapper.EXPECT().ModifyNetworkInterfaceAttribute(modifyNetworkInterfaceAttributeInput).
+	mockWrapper.EXPECT().ModifyNetworkInterfaceAttribute(gomock.Any(), modifyNetworkInterfaceAttributeInput).
 		Return(nil, nil)
 
-	err := ec2ApiHelper.SetDeleteOnTermination(&attachmentId, &branchInterfaceId)
+	err := ec2ApiHelper.SetDeleteOnTermination(context.TODO(), &attachmentId, &branchInterfaceId)
 	assert.NoError(t, err)
 }
 
@@ -827,13 +952,43 @@ func TestEc2APIHelper_SetDeleteOnTermination_Error(t *testing.T) {
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
-	mockWrapper.EXPECT().ModifyNetworkInterfaceAttribute(modifyNetworkInterfaceAttributeInput).
+	mockWrapper.EXPECT().ModifyNetworkInterfaceAttribute(gomock.Any(), modifyNetworkInterfaceAttributeInput).
 		Return(nil, errMock)
 
-	err := ec2ApiHelper.SetDeleteOnTermination(&attachmentId, &branchInterfaceId)
+	err := ec2ApiHelper.SetDeleteOnTermination(context.TODO(), &attachmentId, &branchInterfaceId)
 	assert.Error(t, errMock, err)
 }
 
+// TestEc2APIHelper_ModifyNetworkInterfaceSecurityGroups tests that ec2 api call is made with the security groups
+func TestEc2APIHelper_ModifyNetworkInterfaceSecurityGroups(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
+
+	mockWrapper.EXPECT().ModifyNetworkInterfaceAttribute(gomock.Any(), &ec2.ModifyNetworkInterfaceAttributeInput{
+		Groups:             securityGroups,
+		NetworkInterfaceId: &branchInterfaceId,
+	}).Return(nil, nil)
+
+	err := ec2ApiHelper.ModifyNetworkInterfaceSecurityGroups(context.TODO(), &branchInterfaceId, securityGroups)
+	assert.NoError(t, err)
+}
+
+// TestEc2APIHelper_ModifyNetworkInterfaceSecurityGroups_Error tests when ec2 api call return errors it is propagated
+// to the caller
+func TestEc2APIHelper_ModifyNetworkInterfaceSecurityGroups_Error(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
+
+	mockWrapper.EXPECT().ModifyNetworkInterfaceAttribute(gomock.Any(), gomock.Any()).Return(nil, errMock)
+
+	err := ec2ApiHelper.ModifyNetworkInterfaceSecurityGroups(context.TODO(), &branchInterfaceId, securityGroups)
+	assert.ErrorIs(t, err, errMock)
+}
+
 // TestEC2APIHelper_AttachNetworkInterfaceToInstance no error is returned when valid inputs are passed
 func TestEC2APIHelper_AttachNetworkInterfaceToInstance(t *testing.T) {
 	ctrl := gomock.NewController(t)
@@ -841,10 +996,10 @@ func TestEC2APIHelper_AttachNetworkInterfaceToInstance(t *testing.T) {
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
-	mockWrapper.EXPECT().AttachNetworkInterface(attachNetworkInterfaceInput).
+	mockWrapper.EXPECT().AttachNetworkInterface(gomock.Any(), attachNetworkInterfaceInput).
 		Return(attachNetworkInterfaceOutput, nil)
 
-	id, err := ec2ApiHelper.AttachNetworkInterfaceToInstance(&instanceId, &branchInterfaceId, &deviceIndex)
+	id, err := ec2ApiHelper.AttachNetworkInterfaceToInstance(context.TODO(), &instanceId, &branchInterfaceId, &deviceIndex)
 	assert.NoError(t, err)
 	assert.Equal(t, attachmentId, *id)
 }
@@ -857,10 +1012,10 @@ func TestEC2APIHelper_AttachNetworkInterfaceToInstance_NoAttachmentId(t *testing
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
-	mockWrapper.EXPECT().AttachNetworkInterface(attachNetworkInterfaceInput).
+	mockWrapper.EXPECT().AttachNetworkInterface(gomock.Any(), attachNetworkInterfaceInput).
 		Return(&ec2.AttachNetworkInterfaceOutput{AttachmentId: nil}, nil)
 
-	_, err := ec2ApiHelper.AttachNetworkInterfaceToInstance(&instanceId, &branchInterfaceId, &deviceIndex)
+	_, err := ec2ApiHelper.AttachNetworkInterfaceToInstance(context.TODO(), &instanceId, &branchInterfaceId, &deviceIndex)
 	assert.NotNil(t, err)
 }
 
@@ -871,9 +1026,9 @@ func TestEC2APIHelper_AttachNetworkInterfaceToInstance_Error(t *testing.T) {
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
-	mockWrapper.EXPECT().AttachNetworkInterface(attachNetworkInterfaceInput).Return(nil, errMock)
+	mockWrapper.EXPECT().AttachNetworkInterface(gomock.Any(), attachNetworkInterfaceInput).Return(nil, errMock)
 
-	_, err := ec2ApiHelper.AttachNetworkInterfaceToInstance(&instanceId, &branchInterfaceId, &deviceIndex)
+	_, err := ec2ApiHelper.AttachNetworkInterfaceToInstance(context.TODO(), &instanceId, &branchInterfaceId, &deviceIndex)
 	assert.Error(t, errMock, err)
 }
 
@@ -884,9 +1039,9 @@ func TestEc2APIHelper_DetachNetworkInterfaceFromInstance(t *testing.T) {
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
-	mockWrapper.EXPECT().DetachNetworkInterface(detachNetworkInterfaceInput).Return(nil, nil)
+	mockWrapper.EXPECT().DetachNetworkInterface(gomock.Any(), detachNetworkInterfaceInput).Return(nil, nil)
 
-	err := ec2ApiHelper.DetachNetworkInterfaceFromInstance(&attachmentId)
+	err := ec2ApiHelper.DetachNetworkInterfaceFromInstance(context.TODO(), &attachmentId)
 	assert.NoError(t, err)
 }
 
@@ -902,16 +1057,16 @@ func TestEC2APIHelper_WaitForNetworkInterfaceStatusChange(t *testing.T) {
 
 	gomock.InOrder(
 		// Initially in detached state, must retry
-		mockWrapper.EXPECT().DescribeNetworkInterfaces(describeNetworkInterfaceInputUsingOneInterfaceId).
+		mockWrapper.EXPECT().DescribeNetworkInterfaces(gomock.Any(), describeNetworkInterfaceInputUsingOneInterfaceId).
 			Return(describeNetworkInterfaceOutputUsingOneInterfaceId, nil).Times(2),
 		// Status changed to attached state, must return
-		mockWrapper.EXPECT().DescribeNetworkInterfaces(describeNetworkInterfaceInputUsingOneInterfaceId).
+		mockWrapper.EXPECT().DescribeNetworkInterfaces(gomock.Any(), describeNetworkInterfaceInputUsingOneInterfaceId).
 			Return(&ec2.DescribeNetworkInterfacesOutput{
 				NetworkInterfaces: []ec2types.NetworkInterface{{Attachment: &ec2types.NetworkInterfaceAttachment{Status: ec2types.AttachmentStatusAttached}}},
 			}, nil).Times(1),
 	)
 
-	err := ec2ApiHelper.WaitForNetworkInterfaceStatusChange(&branchInterfaceId, statusAttached)
+	err := ec2ApiHelper.WaitForNetworkInterfaceStatusChange(context.TODO(), &branchInterfaceId, statusAttached)
 	assert.NoError(t, err)
 }
 
@@ -925,10 +1080,10 @@ func TestEC2ADIHelper_WaitForNetworkInterfaceStatusChange_NonRetryableError(t *t
 
 	statusAvailable := "available"
 
-	mockWrapper.EXPECT().DescribeNetworkInterfaces(describeNetworkInterfaceInputUsingOneInterfaceId).
+	mockWrapper.EXPECT().DescribeNetworkInterfaces(gomock.Any(), describeNetworkInterfaceInputUsingOneInterfaceId).
 		Return(nil, errMock)
 
-	err := ec2ApiHelper.WaitForNetworkInterfaceStatusChange(&branchInterfaceId, statusAvailable)
+	err := ec2ApiHelper.WaitForNetworkInterfaceStatusChange(context.TODO(), &branchInterfaceId, statusAvailable)
 	assert.Error(t, errMock, err)
 }
 
@@ -942,12 +1097,12 @@ func TestEc2APIHelper_DetachAndDeleteNetworkInterface(t *testing.T) {
 	oldStatus := describeNetworkInterfaceOutputUsingOneInterfaceId.NetworkInterfaces[0].Attachment.Status
 	describeNetworkInterfaceOutputUsingOneInterfaceId.NetworkInterfaces[0].Attachment.Status = ec2types.AttachmentStatusDetached
 
-	mockWrapper.EXPECT().DetachNetworkInterface(detachNetworkInterfaceInput).Return(nil, nil)
-	mockWrapper.EXPECT().DescribeNetworkInterfaces(describeNetworkInterfaceInputUsingOneInterfaceId).
+	mockWrapper.EXPECT().DetachNetworkInterface(gomock.Any(), detachNetworkInterfaceInput).Return(nil, nil)
+	mockWrapper.EXPECT().DescribeNetworkInterfaces(gomock.Any(), describeNetworkInterfaceInputUsingOneInterfaceId).
 		Return(describeNetworkInterfaceOutputUsingOneInterfaceId, nil)
 	mockWrapper.EXPECT().DeleteNetworkInterface(context.TODO(), deleteNetworkInterfaceInput).Return(nil, nil)
 
-	err := ec2ApiHelper.DetachAndDeleteNetworkInterface(&attachmentId, &branchInterfaceId)
+	err := ec2ApiHelper.DetachAndDeleteNetworkInterface(context.TODO(), &attachmentId, &branchInterfaceId)
 	assert.NoError(t, err)
 
 	// clean up
@@ -961,9 +1116,9 @@ func TestEc2APIHelper_DetachAndDeleteNetworkInterface_Error(t *testing.T) {
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
-	mockWrapper.EXPECT().DetachNetworkInterface(detachNetworkInterfaceInput).Return(nil, errMock)
+	mockWrapper.EXPECT().DetachNetworkInterface(gomock.Any(), detachNetworkInterfaceInput).Return(nil, errMock)
 
-	err := ec2ApiHelper.DetachAndDeleteNetworkInterface(&attachmentId, &branchInterfaceId)
+	err := ec2ApiHelper.DetachAndDeleteNetworkInterface(context.TODO(), &attachmentId, &branchInterfaceId)
 	assert.Error(t, errMock, err)
 }
 
@@ -974,9 +1129,9 @@ func TestEC2APIHelper_GetInstanceDetails(t *testing.T) {
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
-	mockWrapper.EXPECT().DescribeInstances(describeInstanceInput).Return(describeInstanceOutput, nil)
+	mockWrapper.EXPECT().DescribeInstances(gomock.Any(), describeInstanceInput).Return(describeInstanceOutput, nil)
 
-	instance, err := ec2ApiHelper.GetInstanceDetails(&instanceId)
+	instance, err := ec2ApiHelper.GetInstanceDetails(context.TODO(), &instanceId)
 
 	assert.NoError(t, err)
 	assert.Equal(t, instanceId, *instance.InstanceId)
@@ -991,10 +1146,10 @@ func TestEC2APIHelper_GetInstanceDetails_InstanceNotFound(t *testing.T) {
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
-	mockWrapper.EXPECT().DescribeInstances(describeInstanceInput).
+	mockWrapper.EXPECT().DescribeInstances(gomock.Any(), describeInstanceInput).
 		Return(&ec2.DescribeInstancesOutput{Reservations: nil}, nil)
 
-	_, err := ec2ApiHelper.GetInstanceDetails(&instanceId)
+	_, err := ec2ApiHelper.GetInstanceDetails(context.TODO(), &instanceId)
 	assert.NotNil(t, err)
 }
 
@@ -1005,9 +1160,9 @@ func TestEC2APIHelper_GetInstanceDetails_Error(t *testing.T) {
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
-	mockWrapper.EXPECT().DescribeInstances(describeInstanceInput).Return(nil, errMock)
+	mockWrapper.EXPECT().DescribeInstances(gomock.Any(), describeInstanceInput).Return(nil, errMock)
 
-	_, err := ec2ApiHelper.GetInstanceDetails(&instanceId)
+	_, err := ec2ApiHelper.GetInstanceDetails(context.TODO(), &instanceId)
 	assert.Error(t, errMock, err)
 }
 
@@ -1019,10 +1174,10 @@ func TestEC2APIHelper_AssignIPv4ResourcesAndWaitTillReady_TypeIPv4Address(t *tes
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
-	mockWrapper.EXPECT().AssignPrivateIPAddresses(assignPrivateIPInput).Return(assignPrivateIPOutput, nil)
-	mockWrapper.EXPECT().DescribeNetworkInterfaces(describeNetworkInterfaceInput).Return(describeNetworkInterfaceOutput, nil)
+	mockWrapper.EXPECT().AssignPrivateIPAddresses(gomock.Any(), assignPrivateIPInput).Return(assignPrivateIPOutput, nil)
+	mockWrapper.EXPECT().DescribeNetworkInterfaces(gomock.Any(), describeNetworkInterfaceInput).Return(describeNetworkInterfaceOutput, nil)
 
-	createdIPs, err := ec2ApiHelper.AssignIPv4ResourcesAndWaitTillReady(eniID, config.ResourceTypeIPv4Address, 2)
+	createdIPs, err := ec2ApiHelper.AssignIPv4ResourcesAndWaitTillReady(context.TODO(), eniID, config.ResourceTypeIPv4Address, 2)
 
 	assert.NoError(t, err)
 	assert.Equal(t, []string{ipAddress1, ipAddress2}, createdIPs)
@@ -1036,10 +1191,10 @@ func TestEC2APIHelper_AssignIPv4ResourcesAndWaitTillReady_TypeIPv4Prefix(t *test
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
-	mockWrapper.EXPECT().AssignPrivateIPAddresses(assignPrivateIPInputPrefix).Return(assignPrivateIPOutputPrefix, nil)
-	mockWrapper.EXPECT().DescribeNetworkInterfaces(describeNetworkInterfaceInputPrefix).Return(describeNetworkInterfaceOutputPrefix, nil)
+	mockWrapper.EXPECT().AssignPrivateIPAddresses(gomock.Any(), assignPrivateIPInputPrefix).Return(assignPrivateIPOutputPrefix, nil)
+	mockWrapper.EXPECT().DescribeNetworkInterfaces(gomock.Any(), describeNetworkInterfaceInputPrefix).Return(describeNetworkInterfaceOutputPrefix, nil)
 
-	createdPrefixes, err := ec2ApiHelper.AssignIPv4ResourcesAndWaitTillReady(eniID, config.ResourceTypeIPv4Prefix, 2)
+	createdPrefixes, err := ec2ApiHelper.AssignIPv4ResourcesAndWaitTillReady(context.TODO(), eniID, config.ResourceTypeIPv4Prefix, 2)
 
 	assert.NoError(t, err)
 	assert.Equal(t, []string{ipPrefix1, ipPrefix2}, createdPrefixes)
@@ -1053,9 +1208,9 @@ func TestEC2APIHelper_AssignIPv4ResourcesAndWaitTillReady_TypeIPv4Address_Error(
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
-	mockWrapper.EXPECT().AssignPrivateIPAddresses(assignPrivateIPInput).Return(nil, errMock)
+	mockWrapper.EXPECT().AssignPrivateIPAddresses(gomock.Any(), assignPrivateIPInput).Return(nil, errMock)
 
-	_, err := ec2ApiHelper.AssignIPv4ResourcesAndWaitTillReady(eniID, config.ResourceTypeIPv4Address, 2)
+	_, err := ec2ApiHelper.AssignIPv4ResourcesAndWaitTillReady(context.TODO(), eniID, config.ResourceTypeIPv4Address, 2)
 
 	assert.Error(t, errMock, err)
 }
@@ -1068,9 +1223,9 @@ func TestEC2APIHelper_AssignIPv4ResourcesAndWaitTillReady_TypeIPv4Prefix_Error(t
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
-	mockWrapper.EXPECT().AssignPrivateIPAddresses(assignPrivateIPInputPrefix).Return(nil, errMock)
+	mockWrapper.EXPECT().AssignPrivateIPAddresses(gomock.Any(), assignPrivateIPInputPrefix).Return(nil, errMock)
 
-	_, err := ec2ApiHelper.AssignIPv4ResourcesAndWaitTillReady(eniID, config.ResourceTypeIPv4Prefix, 2)
+	_, err := ec2ApiHelper.AssignIPv4ResourcesAndWaitTillReady(context.TODO(), eniID, config.ResourceTypeIPv4Prefix, 2)
 
 	assert.Error(t, errMock, err)
 }
@@ -1083,10 +1238,10 @@ func TestEC2APIHelper_AssignIPv4ResourcesAndWaitTillReady_TypeIPv4Address_Attach
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
-	mockWrapper.EXPECT().AssignPrivateIPAddresses(assignPrivateIPInput).Return(assignPrivateIPOutput, nil)
+	mockWrapper.EXPECT().AssignPrivateIPAddresses(gomock.Any(), assignPrivateIPInput).Return(assignPrivateIPOutput, nil)
 	gomock.InOrder(
 		// First call returns just one ip address
-		mockWrapper.EXPECT().DescribeNetworkInterfaces(describeNetworkInterfaceInput).Return(&ec2.DescribeNetworkInterfacesOutput{
+		mockWrapper.EXPECT().DescribeNetworkInterfaces(gomock.Any(), describeNetworkInterfaceInput).Return(&ec2.DescribeNetworkInterfacesOutput{
 			NetworkInterfaces: []ec2types.NetworkInterface{
 				{PrivateIpAddresses: []ec2types.NetworkInterfacePrivateIpAddress{
 					{PrivateIpAddress: &ipAddress1},
@@ -1094,10 +1249,10 @@ func TestEC2APIHelper_AssignIPv4ResourcesAndWaitTillReady_TypeIPv4Address_Attach
 			},
 		}, nil),
 		// Second call all created IPs returned
-		mockWrapper.EXPECT().DescribeNetworkInterfaces(describeNetworkInterfaceInput).Return(describeNetworkInterfaceOutput, nil),
+		mockWrapper.EXPECT().DescribeNetworkInterfaces(gomock.Any(), describeNetworkInterfaceInput).Return(describeNetworkInterfaceOutput, nil),
 	)
 
-	createdIPs, err := ec2ApiHelper.AssignIPv4ResourcesAndWaitTillReady(eniID, config.ResourceTypeIPv4Address, 2)
+	createdIPs, err := ec2ApiHelper.AssignIPv4ResourcesAndWaitTillReady(context.TODO(), eniID, config.ResourceTypeIPv4Address, 2)
 
 	assert.NoError(t, err)
 	assert.Equal(t, []string{ipAddress1, ipAddress2}, createdIPs)
@@ -1111,10 +1266,10 @@ func TestEC2APIHelper_AssignIPv4ResourcesAndWaitTillReady_TypeIPv4Prefix_Attache
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
-	mockWrapper.EXPECT().AssignPrivateIPAddresses(assignPrivateIPInputPrefix).Return(assignPrivateIPOutputPrefix, nil)
+	mockWrapper.EXPECT().AssignPrivateIPAddresses(gomock.Any(), assignPrivateIPInputPrefix).Return(assignPrivateIPOutputPrefix, nil)
 	gomock.InOrder(
 		// First call returns just one ip prefix
-		mockWrapper.EXPECT().DescribeNetworkInterfaces(describeNetworkInterfaceInputPrefix).Return(&ec2.DescribeNetworkInterfacesOutput{
+		mockWrapper.EXPECT().DescribeNetworkInterfaces(gomock.Any(), describeNetworkInterfaceInputPrefix).Return(&ec2.DescribeNetworkInterfacesOutput{
 			NetworkInterfaces: []ec2types.NetworkInterface{
 				{Ipv4Prefixes: []ec2types.Ipv4PrefixSpecification{
 					{Ipv4Prefix: &ipPrefix1},
@@ -1122,10 +1277,10 @@ func TestEC2APIHelper_AssignIPv4ResourcesAndWaitTillReady_TypeIPv4Prefix_Attache
 			},
 		}, nil),
 		// Second call all created prefixes returned
-		mockWrapper.EXPECT().DescribeNetworkInterfaces(describeNetworkInterfaceInputPrefix).Return(describeNetworkInterfaceOutputPrefix, nil),
+		mockWrapper.EXPECT().DescribeNetworkInterfaces(gomock.Any(), describeNetworkInterfaceInputPrefix).Return(describeNetworkInterfaceOutputPrefix, nil),
 	)
 
-	createdPrefixes, err := ec2ApiHelper.AssignIPv4ResourcesAndWaitTillReady(eniID, config.ResourceTypeIPv4Prefix, 2)
+	createdPrefixes, err := ec2ApiHelper.AssignIPv4ResourcesAndWaitTillReady(context.TODO(), eniID, config.ResourceTypeIPv4Prefix, 2)
 
 	assert.NoError(t, err)
 	assert.Equal(t, []string{ipPrefix1, ipPrefix2}, createdPrefixes)
@@ -1138,10 +1293,10 @@ func TestEC2APIHelper_AssignIPv4ResourcesAndWaitTillReady_TypeIPv4Address_Descri
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
-	mockWrapper.EXPECT().AssignPrivateIPAddresses(assignPrivateIPInput).Return(assignPrivateIPOutput, nil)
+	mockWrapper.EXPECT().AssignPrivateIPAddresses(gomock.Any(), assignPrivateIPInput).Return(assignPrivateIPOutput, nil)
 	gomock.InOrder(
 		// First call returns just one ip address
-		mockWrapper.EXPECT().DescribeNetworkInterfaces(describeNetworkInterfaceInput).Return(&ec2.DescribeNetworkInterfacesOutput{
+		mockWrapper.EXPECT().DescribeNetworkInterfaces(gomock.Any(), describeNetworkInterfaceInput).Return(&ec2.DescribeNetworkInterfacesOutput{
 			NetworkInterfaces: []ec2types.NetworkInterface{
 				{PrivateIpAddresses: []ec2types.NetworkInterfacePrivateIpAddress{
 					{PrivateIpAddress: &ipAddress1},
@@ -1150,7 +1305,7 @@ func TestEC2APIHelper_AssignIPv4ResourcesAndWaitTillReady_TypeIPv4Address_Descri
 		}, nil).Times(maxRetryOnError),
 	)
 
-	createdIPs, err := ec2ApiHelper.AssignIPv4ResourcesAndWaitTillReady(eniID, config.ResourceTypeIPv4Address, 2)
+	createdIPs, err := ec2ApiHelper.AssignIPv4ResourcesAndWaitTillReady(context.TODO(), eniID, config.ResourceTypeIPv4Address, 2)
 
 	assert.NotNil(t, err)
 	// Assert that even though 2 IPs were assigned, only 1 is returned because the describe call doesn't contain the second IP
@@ -1164,10 +1319,10 @@ func TestEC2APIHelper_AssignIPv4ResourcesAndWaitTillReady_TypeIPv4Prefix_Describ
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
-	mockWrapper.EXPECT().AssignPrivateIPAddresses(assignPrivateIPInputPrefix).Return(assignPrivateIPOutputPrefix, nil)
+	mockWrapper.EXPECT().AssignPrivateIPAddresses(gomock.Any(), assignPrivateIPInputPrefix).Return(assignPrivateIPOutputPrefix, nil)
 	gomock.InOrder(
 		// First call returns just one ip address
-		mockWrapper.EXPECT().DescribeNetworkInterfaces(describeNetworkInterfaceInputPrefix).Return(&ec2.DescribeNetworkInterfacesOutput{
+		mockWrapper.EXPECT().DescribeNetworkInterfaces(gomock.Any(), describeNetworkInterfaceInputPrefix).Return(&ec2.DescribeNetworkInterfacesOutput{
 			NetworkInterfaces: []ec2types.NetworkInterface{
 				{Ipv4Prefixes: []ec2types.Ipv4PrefixSpecification{
 					{Ipv4Prefix: &ipPrefix1},
@@ -1176,13 +1331,72 @@ func TestEC2APIHelper_AssignIPv4ResourcesAndWaitTillReady_TypeIPv4Prefix_Describ
 		}, nil).Times(maxRetryOnError),
 	)
 
-	createdPrefixes, err := ec2ApiHelper.AssignIPv4ResourcesAndWaitTillReady(eniID, config.ResourceTypeIPv4Prefix, 2)
+	createdPrefixes, err := ec2ApiHelper.AssignIPv4ResourcesAndWaitTillReady(context.TODO(), eniID, config.ResourceTypeIPv4Prefix, 2)
 
 	assert.NotNil(t, err)
 	// Assert that even though 2 prefixes were assigned, only 1 is returned because the describe call doesn't contain the second prefix
 	assert.Equal(t, []string{ipPrefix1}, createdPrefixes)
 }
 
+// TestEC2APIHelper_AssignIPv6PrefixesAndWaitTillReady tests that once new IPv6 prefixes are assigned they are returned
+// only when the prefixes are attached to the interface
+func TestEC2APIHelper_AssignIPv6PrefixesAndWaitTillReady(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
+
+	mockWrapper.EXPECT().AssignIPv6Addresses(gomock.Any(), assignIPv6InputPrefix).Return(assignIPv6OutputPrefix, nil)
+	gomock.InOrder(
+		// First call returns just one prefix
+		mockWrapper.EXPECT().DescribeNetworkInterfaces(gomock.Any(), describeNetworkInterfaceInputPrefix).Return(&ec2.DescribeNetworkInterfacesOutput{
+			NetworkInterfaces: []ec2types.NetworkInterface{
+				{Ipv6Prefixes: []ec2types.Ipv6PrefixSpecification{
+					{Ipv6Prefix: &ipv6Prefix1},
+				}},
+			},
+		}, nil),
+		// Second call all created prefixes returned
+		mockWrapper.EXPECT().DescribeNetworkInterfaces(gomock.Any(), describeNetworkInterfaceInputPrefix).Return(describeNetworkInterfaceOutputIPv6Prefix, nil),
+	)
+
+	createdPrefixes, err := ec2ApiHelper.AssignIPv6PrefixesAndWaitTillReady(context.TODO(), eniID, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{ipv6Prefix1, ipv6Prefix2}, createdPrefixes)
+}
+
+// TestEC2APIHelper_AssignIPv6PrefixesAndWaitTillReady_Error tests that error is returned if the assign IPv6 call fails
+func TestEC2APIHelper_AssignIPv6PrefixesAndWaitTillReady_Error(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
+
+	mockWrapper.EXPECT().AssignIPv6Addresses(gomock.Any(), assignIPv6InputPrefix).Return(nil, errMock)
+
+	createdPrefixes, err := ec2ApiHelper.AssignIPv6PrefixesAndWaitTillReady(context.TODO(), eniID, 2)
+
+	assert.Equal(t, errMock, err)
+	assert.Empty(t, createdPrefixes)
+}
+
+// TestEC2APIHelper_UnassignIPv6Prefixes tests the IPv6 prefixes are unassigned from the interface
+func TestEC2APIHelper_UnassignIPv6Prefixes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
+
+	mockWrapper.EXPECT().UnassignIPv6Addresses(gomock.Any(), &ec2.UnassignIpv6AddressesInput{
+		NetworkInterfaceId: &eniID,
+		Ipv6Prefixes:       []string{ipv6Prefix1},
+	}).Return(nil, nil)
+
+	err := ec2ApiHelper.UnassignIPv6Prefixes(context.TODO(), eniID, []string{ipv6Prefix1})
+	assert.NoError(t, err)
+}
+
 // TestEc2APIHelper_GetBranchNetworkInterface_PaginatedResults returns the branch interface when paginated results is returned
 func TestEc2APIHelper_GetBranchNetworkInterface(t *testing.T) {
 	ctrl := gomock.NewController(t)
@@ -1190,9 +1404,9 @@ func TestEc2APIHelper_GetBranchNetworkInterface(t *testing.T) {
 
 	ec2ApiHelper, mockWrapper := getMockWrapper(ctrl)
 
-	mockWrapper.EXPECT().DescribeNetworkInterfaces(describeTrunkInterfaceInput).Return(describeTrunkInterfaceOutput, nil)
+	mockWrapper.EXPECT().DescribeNetworkInterfaces(gomock.Any(), describeTrunkInterfaceInput).Return(describeTrunkInterfaceOutput, nil)
 
-	branchInterfaces, err := ec2ApiHelper.GetBranchNetworkInterface(&trunkInterfaceId, &subnetId)
+	branchInterfaces, err := ec2ApiHelper.GetBranchNetworkInterface(context.TODO(), &trunkInterfaceId)
 	assert.NoError(t, err)
 	assert.ElementsMatch(t, []*ec2types.NetworkInterface{&networkInterface1, &networkInterface2}, branchInterfaces)
 }
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	vpc_rc_config "github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/tracing"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/utils"
 
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/version"
@@ -40,6 +41,7 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
@@ -51,22 +53,25 @@ const (
 )
 
 type EC2Wrapper interface {
-	DescribeInstances(input *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error)
-	CreateNetworkInterface(input *ec2.CreateNetworkInterfaceInput) (*ec2.CreateNetworkInterfaceOutput, error)
-	AttachNetworkInterface(input *ec2.AttachNetworkInterfaceInput) (*ec2.AttachNetworkInterfaceOutput, error)
-	DetachNetworkInterface(input *ec2.DetachNetworkInterfaceInput) (*ec2.DetachNetworkInterfaceOutput, error)
+	DescribeInstances(ctx context.Context, input *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error)
+	CreateNetworkInterface(ctx context.Context, input *ec2.CreateNetworkInterfaceInput) (*ec2.CreateNetworkInterfaceOutput, error)
+	AttachNetworkInterface(ctx context.Context, input *ec2.AttachNetworkInterfaceInput) (*ec2.AttachNetworkInterfaceOutput, error)
+	DetachNetworkInterface(ctx context.Context, input *ec2.DetachNetworkInterfaceInput) (*ec2.DetachNetworkInterfaceOutput, error)
 	DeleteNetworkInterface(ctx context.Context, input *ec2.DeleteNetworkInterfaceInput) (*ec2.DeleteNetworkInterfaceOutput, error)
-	AssignPrivateIPAddresses(input *ec2.AssignPrivateIpAddressesInput) (*ec2.AssignPrivateIpAddressesOutput, error)
-	UnassignPrivateIPAddresses(input *ec2.UnassignPrivateIpAddressesInput) (*ec2.UnassignPrivateIpAddressesOutput, error)
-	DescribeNetworkInterfaces(input *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error)
+	AssignPrivateIPAddresses(ctx context.Context, input *ec2.AssignPrivateIpAddressesInput) (*ec2.AssignPrivateIpAddressesOutput, error)
+	UnassignPrivateIPAddresses(ctx context.Context, input *ec2.UnassignPrivateIpAddressesInput) (*ec2.UnassignPrivateIpAddressesOutput, error)
+	AssignIPv6Addresses(ctx context.Context, input *ec2.AssignIpv6AddressesInput) (*ec2.AssignIpv6AddressesOutput, error)
+	UnassignIPv6Addresses(ctx context.Context, input *ec2.UnassignIpv6AddressesInput) (*ec2.UnassignIpv6AddressesOutput, error)
+	DescribeNetworkInterfaces(ctx context.Context, input *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error)
 	DescribeNetworkInterfacesPages(ctx context.Context, input *ec2.DescribeNetworkInterfacesInput) ([]*ec2types.NetworkInterface, error)
-	CreateTags(input *ec2.CreateTagsInput) (*ec2.CreateTagsOutput, error)
-	DescribeSubnets(input *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error)
-	AssociateTrunkInterface(input *ec2.AssociateTrunkInterfaceInput) (*ec2.AssociateTrunkInterfaceOutput, error)
-	DescribeTrunkInterfaceAssociations(input *ec2.DescribeTrunkInterfaceAssociationsInput) (*ec2.DescribeTrunkInterfaceAssociationsOutput, error)
-	ModifyNetworkInterfaceAttribute(input *ec2.ModifyNetworkInterfaceAttributeInput) (*ec2.ModifyNetworkInterfaceAttributeOutput, error)
-	CreateNetworkInterfacePermission(input *ec2.CreateNetworkInterfacePermissionInput) (*ec2.CreateNetworkInterfacePermissionOutput, error)
-	DisassociateTrunkInterface(input *ec2.DisassociateTrunkInterfaceInput) error
+	CreateTags(ctx context.Context, input *ec2.CreateTagsInput) (*ec2.CreateTagsOutput, error)
+	DescribeSubnets(ctx context.Context, input *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error)
+	DescribeSecurityGroups(ctx context.Context, input *ec2.DescribeSecurityGroupsInput) (*ec2.DescribeSecurityGroupsOutput, error)
+	AssociateTrunkInterface(ctx context.Context, input *ec2.AssociateTrunkInterfaceInput) (*ec2.AssociateTrunkInterfaceOutput, error)
+	DescribeTrunkInterfaceAssociations(ctx context.Context, input *ec2.DescribeTrunkInterfaceAssociationsInput) (*ec2.DescribeTrunkInterfaceAssociationsOutput, error)
+	ModifyNetworkInterfaceAttribute(ctx context.Context, input *ec2.ModifyNetworkInterfaceAttributeInput) (*ec2.ModifyNetworkInterfaceAttributeOutput, error)
+	CreateNetworkInterfacePermission(ctx context.Context, input *ec2.CreateNetworkInterfacePermissionInput) (*ec2.CreateNetworkInterfacePermissionOutput, error)
+	DisassociateTrunkInterface(ctx context.Context, input *ec2.DisassociateTrunkInterfaceInput) error
 }
 
 var (
@@ -210,6 +215,48 @@ var (
 		},
 	)
 
+	numAssignedIPv6Prefixes = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "num_assigned_ipv6_prefixes",
+			Help: "The number of ipv6 prefixes allocated",
+		},
+	)
+
+	ec2AssignIPv6AddressAPICallCnt = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "ec2_assign_ipv6_address_api_req_count",
+			Help: "The number calls made to ec2 for assigning ipv6 addresses on network interface",
+		},
+	)
+
+	ec2AssignIPv6AddressAPIErrCnt = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "ec2_assign_ipv6_address_api_err_count",
+			Help: "The number of errors encountered while assigning ipv6 addresses on network interface",
+		},
+	)
+
+	numUnassignedIPv6Prefixes = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "num_unassigned_ipv6_prefixes",
+			Help: "The number of ipv6 prefixes unassigned",
+		},
+	)
+
+	ec2UnassignIPv6AddressAPICallCnt = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "ec2_unassign_ipv6_address_api_req_count",
+			Help: "The number calls made to ec2 for unassigning ipv6 addresses on network interface",
+		},
+	)
+
+	ec2UnassignIPv6AddressAPIErrCnt = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "ec2_unassign_ipv6_address_api_err_count",
+			Help: "The number of errors encountered while unassigning ipv6 addresses on network interface",
+		},
+	)
+
 	ec2DetachNetworkInterfaceAPICallCnt = prometheus.NewCounter(
 		prometheus.CounterOpts{
 			Name: "ec2_detach_network_interface_api_req_count",
@@ -252,6 +299,20 @@ var (
 		},
 	)
 
+	ec2DescribeSecurityGroupsAPICallCnt = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "ec2_describe_security_groups_api_req_count",
+			Help: "The number of calls made to EC2 for describing security groups",
+		},
+	)
+
+	ec2DescribeSecurityGroupsAPIErrCnt = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "ec2_describe_security_groups_api_err_count",
+			Help: "The number of errors encountered while describing security groups",
+		},
+	)
+
 	ec2AssociateTrunkInterfaceAPICallCnt = prometheus.NewCounter(
 		prometheus.CounterOpts{
 			Name: "ec2_associate_trunk_interface_api_req_count",
@@ -370,6 +431,30 @@ var (
 		},
 	)
 
+	ec2DescribeBatchSize = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "ec2_describe_batch_size",
+			Help:    "The number of IDs coalesced into a single batched describe call",
+			Buckets: []float64{1, 2, 5, 10, 20, 50, 100},
+		},
+		[]string{"operation"},
+	)
+
+	ec2DescribeCacheHitCnt = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ec2_describe_cache_hit_count",
+			Help: "The number of describe requests served from the cache by resource",
+		},
+		[]string{"resource"},
+	)
+	ec2DescribeCacheMissCnt = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ec2_describe_cache_miss_count",
+			Help: "The number of describe requests not found in the cache by resource",
+		},
+		[]string{"resource"},
+	)
+
 	prometheusRegistered = false
 )
 
@@ -395,12 +480,20 @@ func prometheusRegister() {
 			numUnassignedIPv4Prefixes,
 			ec2AssignPrivateIPAddressAPICallCnt,
 			ec2AssignPrivateIPAddressAPIErrCnt,
+			numAssignedIPv6Prefixes,
+			numUnassignedIPv6Prefixes,
+			ec2AssignIPv6AddressAPICallCnt,
+			ec2AssignIPv6AddressAPIErrCnt,
+			ec2UnassignIPv6AddressAPICallCnt,
+			ec2UnassignIPv6AddressAPIErrCnt,
 			ec2DetachNetworkInterfaceAPICallCnt,
 			ec2DetachNetworkInterfaceAPIErrCnt,
 			ec2DeleteNetworkInterfaceAPICallCnt,
 			ec2DeleteNetworkInterfaceAPIErrCnt,
 			ec2DescribeSubnetsAPICallCnt,
 			ec2DescribeSubnetsAPIErrCnt,
+			ec2DescribeSecurityGroupsAPICallCnt,
+			ec2DescribeSecurityGroupsAPIErrCnt,
 			ec2AssociateTrunkInterfaceAPICallCnt,
 			ec2AssociateTrunkInterfaceAPIErrCnt,
 			ec2describeTrunkInterfaceAssociationAPICallCnt,
@@ -416,6 +509,9 @@ func prometheusRegister() {
 			ec2DescribeNetworkInterfacesPagesAPICallCnt,
 			ec2DescribeNetworkInterfacesPagesAPIErrCnt,
 			NodeTerminationENICleanupFailure,
+			ec2DescribeBatchSize,
+			ec2DescribeCacheHitCnt,
+			ec2DescribeCacheMissCnt,
 		)
 
 		prometheusRegistered = true
@@ -609,13 +705,20 @@ func (e *ec2Wrapper) createSTSClient(
 	return stsClient
 }
 
+// startSpan starts the span of the EC2 API call
+func startSpan(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "ec2."+operation, tracing.AttributeEC2Operation.String(operation))
+}
+
 func timeSinceMs(start time.Time) float64 {
 	return float64(time.Since(start).Milliseconds())
 }
 
-func (e *ec2Wrapper) DescribeInstances(input *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
+func (e *ec2Wrapper) DescribeInstances(ctx context.Context, input *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
+	ctx, span := startSpan(ctx, "DescribeInstances")
 	start := time.Now()
-	describeInstancesOutput, err := e.userServiceClient.DescribeInstances(context.TODO(), input)
+	describeInstancesOutput, err := e.userServiceClient.DescribeInstances(ctx, input)
+	tracing.End(span, err)
 	ec2APICallLatencies.WithLabelValues("describe_instances").Observe(timeSinceMs(start))
 
 	// Metric updates
@@ -630,9 +733,11 @@ func (e *ec2Wrapper) DescribeInstances(input *ec2.DescribeInstancesInput) (*ec2.
 	return describeInstancesOutput, err
 }
 
-func (e *ec2Wrapper) CreateNetworkInterface(input *ec2.CreateNetworkInterfaceInput) (*ec2.CreateNetworkInterfaceOutput, error) {
+func (e *ec2Wrapper) CreateNetworkInterface(ctx context.Context, input *ec2.CreateNetworkInterfaceInput) (*ec2.CreateNetworkInterfaceOutput, error) {
+	ctx, span := startSpan(ctx, "CreateNetworkInterface")
 	start := time.Now()
-	createNetworkInterfaceOutput, err := e.userServiceClient.CreateNetworkInterface(context.TODO(), input)
+	createNetworkInterfaceOutput, err := e.userServiceClient.CreateNetworkInterface(ctx, input)
+	tracing.End(span, err)
 	ec2APICallLatencies.WithLabelValues("create_network_interface").Observe(timeSinceMs(start))
 
 	// Metric updates
@@ -651,9 +756,11 @@ func (e *ec2Wrapper) CreateNetworkInterface(input *ec2.CreateNetworkInterfaceInp
 	return createNetworkInterfaceOutput, err
 }
 
-func (e *ec2Wrapper) AttachNetworkInterface(input *ec2.AttachNetworkInterfaceInput) (*ec2.AttachNetworkInterfaceOutput, error) {
+func (e *ec2Wrapper) AttachNetworkInterface(ctx context.Context, input *ec2.AttachNetworkInterfaceInput) (*ec2.AttachNetworkInterfaceOutput, error) {
+	ctx, span := startSpan(ctx, "AttachNetworkInterface")
 	start := time.Now()
-	attachNetworkInterfaceOutput, err := e.userServiceClient.AttachNetworkInterface(context.TODO(), input)
+	attachNetworkInterfaceOutput, err := e.userServiceClient.AttachNetworkInterface(ctx, input)
+	tracing.End(span, err)
 	ec2APICallLatencies.WithLabelValues("attach_network_interface").Observe(timeSinceMs(start))
 
 	// Metric updates
@@ -669,8 +776,10 @@ func (e *ec2Wrapper) AttachNetworkInterface(input *ec2.AttachNetworkInterfaceInp
 }
 
 func (e *ec2Wrapper) DeleteNetworkInterface(ctx context.Context, input *ec2.DeleteNetworkInterfaceInput) (*ec2.DeleteNetworkInterfaceOutput, error) {
+	ctx, span := startSpan(ctx, "DeleteNetworkInterface")
 	start := time.Now()
 	deleteNetworkInterfaceOutput, err := e.userServiceClient.DeleteNetworkInterface(ctx, input)
+	tracing.End(span, err)
 	ec2APICallLatencies.WithLabelValues("delete_network_interface").Observe(timeSinceMs(start))
 
 	// Metric updates
@@ -685,9 +794,11 @@ func (e *ec2Wrapper) DeleteNetworkInterface(ctx context.Context, input *ec2.Dele
 	return deleteNetworkInterfaceOutput, err
 }
 
-func (e *ec2Wrapper) DetachNetworkInterface(input *ec2.DetachNetworkInterfaceInput) (*ec2.DetachNetworkInterfaceOutput, error) {
+func (e *ec2Wrapper) DetachNetworkInterface(ctx context.Context, input *ec2.DetachNetworkInterfaceInput) (*ec2.DetachNetworkInterfaceOutput, error) {
+	ctx, span := startSpan(ctx, "DetachNetworkInterface")
 	start := time.Now()
-	detachNetworkInterfaceOutput, err := e.userServiceClient.DetachNetworkInterface(context.TODO(), input)
+	detachNetworkInterfaceOutput, err := e.userServiceClient.DetachNetworkInterface(ctx, input)
+	tracing.End(span, err)
 	ec2APICallLatencies.WithLabelValues("detach_network_interface").Observe(timeSinceMs(start))
 
 	// Metric updates
@@ -702,9 +813,11 @@ func (e *ec2Wrapper) DetachNetworkInterface(input *ec2.DetachNetworkInterfaceInp
 	return detachNetworkInterfaceOutput, err
 }
 
-func (e *ec2Wrapper) DescribeNetworkInterfaces(input *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+func (e *ec2Wrapper) DescribeNetworkInterfaces(ctx context.Context, input *ec2.DescribeNetworkInterfacesInput) (*ec2.DescribeNetworkInterfacesOutput, error) {
+	ctx, span := startSpan(ctx, "DescribeNetworkInterfaces")
 	start := time.Now()
-	describeNetworkInterfacesOutput, err := e.userServiceClient.DescribeNetworkInterfaces(context.TODO(), input)
+	describeNetworkInterfacesOutput, err := e.userServiceClient.DescribeNetworkInterfaces(ctx, input)
+	tracing.End(span, err)
 	ec2APICallLatencies.WithLabelValues("describe_network_interface").Observe(timeSinceMs(start))
 
 	// Metric updates
@@ -726,6 +839,7 @@ func (e *ec2Wrapper) DescribeNetworkInterfacesPages(ctx context.Context, input *
 		input.MaxResults = aws.Int32(int32(vpc_rc_config.DescribeNetworkInterfacesMaxResults))
 	}
 
+	ctx, span := startSpan(ctx, "DescribeNetworkInterfacesPages")
 	start := time.Now()
 	defer func() {
 		ec2APICallLatencies.WithLabelValues("describe_network_interfaces_pages").Observe(timeSinceMs(start))
@@ -740,6 +854,7 @@ func (e *ec2Wrapper) DescribeNetworkInterfacesPages(ctx context.Context, input *
 		if err != nil {
 			ec2APIErrCnt.Inc()
 			ec2DescribeNetworkInterfacesPagesAPIErrCnt.Inc()
+			tracing.End(span, err)
 			return nil, err
 		}
 		ec2APICallCnt.Inc()
@@ -753,12 +868,15 @@ func (e *ec2Wrapper) DescribeNetworkInterfacesPages(ctx context.Context, input *
 			})
 		}
 	}
+	tracing.End(span, nil)
 	return nwInterfaces, nil
 }
 
-func (e *ec2Wrapper) AssignPrivateIPAddresses(input *ec2.AssignPrivateIpAddressesInput) (*ec2.AssignPrivateIpAddressesOutput, error) {
+func (e *ec2Wrapper) AssignPrivateIPAddresses(ctx context.Context, input *ec2.AssignPrivateIpAddressesInput) (*ec2.AssignPrivateIpAddressesOutput, error) {
+	ctx, span := startSpan(ctx, "AssignPrivateIPAddresses")
 	start := time.Now()
-	assignPrivateIPAddressesOutput, err := e.userServiceClient.AssignPrivateIpAddresses(context.TODO(), input)
+	assignPrivateIPAddressesOutput, err := e.userServiceClient.AssignPrivateIpAddresses(ctx, input)
+	tracing.End(span, err)
 	ec2APICallLatencies.WithLabelValues("assign_private_ip").Observe(timeSinceMs(start))
 
 	// Metric updates
@@ -781,9 +899,11 @@ func (e *ec2Wrapper) AssignPrivateIPAddresses(input *ec2.AssignPrivateIpAddresse
 	return assignPrivateIPAddressesOutput, err
 }
 
-func (e *ec2Wrapper) UnassignPrivateIPAddresses(input *ec2.UnassignPrivateIpAddressesInput) (*ec2.UnassignPrivateIpAddressesOutput, error) {
+func (e *ec2Wrapper) UnassignPrivateIPAddresses(ctx context.Context, input *ec2.UnassignPrivateIpAddressesInput) (*ec2.UnassignPrivateIpAddressesOutput, error) {
+	ctx, span := startSpan(ctx, "UnassignPrivateIPAddresses")
 	start := time.Now()
-	unAssignPrivateIPAddressesOutput, err := e.userServiceClient.UnassignPrivateIpAddresses(context.TODO(), input)
+	unAssignPrivateIPAddressesOutput, err := e.userServiceClient.UnassignPrivateIpAddresses(ctx, input)
+	tracing.End(span, err)
 	ec2APICallLatencies.WithLabelValues("unassign_private_ip").Observe(timeSinceMs(start))
 
 	// Metric updates
@@ -803,9 +923,55 @@ func (e *ec2Wrapper) UnassignPrivateIPAddresses(input *ec2.UnassignPrivateIpAddr
 	return unAssignPrivateIPAddressesOutput, err
 }
 
-func (e *ec2Wrapper) CreateTags(input *ec2.CreateTagsInput) (*ec2.CreateTagsOutput, error) {
+func (e *ec2Wrapper) AssignIPv6Addresses(ctx context.Context, input *ec2.AssignIpv6AddressesInput) (*ec2.AssignIpv6AddressesOutput, error) {
+	ctx, span := startSpan(ctx, "AssignIPv6Addresses")
+	start := time.Now()
+	assignIPv6AddressesOutput, err := e.userServiceClient.AssignIpv6Addresses(ctx, input)
+	tracing.End(span, err)
+	ec2APICallLatencies.WithLabelValues("assign_ipv6_address").Observe(timeSinceMs(start))
+
+	// Metric updates
+	ec2APICallCnt.Inc()
+	ec2AssignIPv6AddressAPICallCnt.Inc()
+	if input.Ipv6PrefixCount != nil && *input.Ipv6PrefixCount != 0 {
+		numAssignedIPv6Prefixes.Add(float64(*input.Ipv6PrefixCount))
+	}
+
+	if err != nil {
+		ec2APIErrCnt.Inc()
+		ec2AssignIPv6AddressAPIErrCnt.Inc()
+	}
+
+	return assignIPv6AddressesOutput, err
+}
+
+func (e *ec2Wrapper) UnassignIPv6Addresses(ctx context.Context, input *ec2.UnassignIpv6AddressesInput) (*ec2.UnassignIpv6AddressesOutput, error) {
+	ctx, span := startSpan(ctx, "UnassignIPv6Addresses")
+	start := time.Now()
+	unassignIPv6AddressesOutput, err := e.userServiceClient.UnassignIpv6Addresses(ctx, input)
+	tracing.End(span, err)
+	ec2APICallLatencies.WithLabelValues("unassign_ipv6_address").Observe(timeSinceMs(start))
+
+	// Metric updates
+	ec2APICallCnt.Inc()
+	ec2UnassignIPv6AddressAPICallCnt.Inc()
+	if len(input.Ipv6Prefixes) > 0 {
+		numUnassignedIPv6Prefixes.Add(float64(len(input.Ipv6Prefixes)))
+	}
+
+	if err != nil {
+		ec2APIErrCnt.Inc()
+		ec2UnassignIPv6AddressAPIErrCnt.Inc()
+	}
+
+	return unassignIPv6AddressesOutput, err
+}
+
+func (e *ec2Wrapper) CreateTags(ctx context.Context, input *ec2.CreateTagsInput) (*ec2.CreateTagsOutput, error) {
+	ctx, span := startSpan(ctx, "CreateTags")
 	start := time.Now()
-	createTagsOutput, err := e.userServiceClient.CreateTags(context.TODO(), input)
+	createTagsOutput, err := e.userServiceClient.CreateTags(ctx, input)
+	tracing.End(span, err)
 	ec2APICallLatencies.WithLabelValues("create_tags").Observe(timeSinceMs(start))
 
 	// Metric updates
@@ -820,9 +986,11 @@ func (e *ec2Wrapper) CreateTags(input *ec2.CreateTagsInput) (*ec2.CreateTagsOutp
 	return createTagsOutput, err
 }
 
-func (e *ec2Wrapper) DescribeSubnets(input *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+func (e *ec2Wrapper) DescribeSubnets(ctx context.Context, input *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+	ctx, span := startSpan(ctx, "DescribeSubnets")
 	start := time.Now()
-	output, err := e.userServiceClient.DescribeSubnets(context.TODO(), input)
+	output, err := e.userServiceClient.DescribeSubnets(ctx, input)
+	tracing.End(span, err)
 	ec2APICallLatencies.WithLabelValues("describe_subnets").Observe(timeSinceMs(start))
 
 	// Metric updates
@@ -837,10 +1005,31 @@ func (e *ec2Wrapper) DescribeSubnets(input *ec2.DescribeSubnetsInput) (*ec2.Desc
 	return output, err
 }
 
+func (e *ec2Wrapper) DescribeSecurityGroups(ctx context.Context, input *ec2.DescribeSecurityGroupsInput) (*ec2.DescribeSecurityGroupsOutput, error) {
+	ctx, span := startSpan(ctx, "DescribeSecurityGroups")
+	start := time.Now()
+	output, err := e.userServiceClient.DescribeSecurityGroups(ctx, input)
+	tracing.End(span, err)
+	ec2APICallLatencies.WithLabelValues("describe_security_groups").Observe(timeSinceMs(start))
+
+	// Metric updates
+	ec2APICallCnt.Inc()
+	ec2DescribeSecurityGroupsAPICallCnt.Inc()
+
+	if err != nil {
+		ec2APIErrCnt.Inc()
+		ec2DescribeSecurityGroupsAPIErrCnt.Inc()
+	}
+
+	return output, err
+}
+
 // DescribeTrunkInterfaceAssociations cannot be used as it's not public yet.
-func (e *ec2Wrapper) DescribeTrunkInterfaceAssociations(input *ec2.DescribeTrunkInterfaceAssociationsInput) (*ec2.DescribeTrunkInterfaceAssociationsOutput, error) {
+func (e *ec2Wrapper) DescribeTrunkInterfaceAssociations(ctx context.Context, input *ec2.DescribeTrunkInterfaceAssociationsInput) (*ec2.DescribeTrunkInterfaceAssociationsOutput, error) {
+	ctx, span := startSpan(ctx, "DescribeTrunkInterfaceAssociations")
 	start := time.Now()
-	describeTrunkInterfaceAssociationInput, err := e.instanceServiceClient.DescribeTrunkInterfaceAssociations(context.TODO(), input)
+	describeTrunkInterfaceAssociationInput, err := e.instanceServiceClient.DescribeTrunkInterfaceAssociations(ctx, input)
+	tracing.End(span, err)
 	ec2APICallLatencies.WithLabelValues("describe_trunk_association").Observe(timeSinceMs(start))
 
 	// Metric Update
@@ -855,9 +1044,11 @@ func (e *ec2Wrapper) DescribeTrunkInterfaceAssociations(input *ec2.DescribeTrunk
 	return describeTrunkInterfaceAssociationInput, err
 }
 
-func (e *ec2Wrapper) AssociateTrunkInterface(input *ec2.AssociateTrunkInterfaceInput) (*ec2.AssociateTrunkInterfaceOutput, error) {
+func (e *ec2Wrapper) AssociateTrunkInterface(ctx context.Context, input *ec2.AssociateTrunkInterfaceInput) (*ec2.AssociateTrunkInterfaceOutput, error) {
+	ctx, span := startSpan(ctx, "AssociateTrunkInterface")
 	start := time.Now()
-	associateTrunkInterfaceOutput, err := e.instanceServiceClient.AssociateTrunkInterface(context.TODO(), input)
+	associateTrunkInterfaceOutput, err := e.instanceServiceClient.AssociateTrunkInterface(ctx, input)
+	tracing.End(span, err)
 	ec2APICallLatencies.WithLabelValues("associate_trunk_to_branch").Observe(timeSinceMs(start))
 
 	// Metric Update
@@ -872,9 +1063,11 @@ func (e *ec2Wrapper) AssociateTrunkInterface(input *ec2.AssociateTrunkInterfaceI
 	return associateTrunkInterfaceOutput, err
 }
 
-func (e *ec2Wrapper) ModifyNetworkInterfaceAttribute(input *ec2.ModifyNetworkInterfaceAttributeInput) (*ec2.ModifyNetworkInterfaceAttributeOutput, error) {
+func (e *ec2Wrapper) ModifyNetworkInterfaceAttribute(ctx context.Context, input *ec2.ModifyNetworkInterfaceAttributeInput) (*ec2.ModifyNetworkInterfaceAttributeOutput, error) {
+	ctx, span := startSpan(ctx, "ModifyNetworkInterfaceAttribute")
 	start := time.Now()
-	modifyNetworkInterfaceAttributeOutput, err := e.userServiceClient.ModifyNetworkInterfaceAttribute(context.TODO(), input)
+	modifyNetworkInterfaceAttributeOutput, err := e.userServiceClient.ModifyNetworkInterfaceAttribute(ctx, input)
+	tracing.End(span, err)
 	ec2APICallLatencies.WithLabelValues("modify_network_interface_attribute").Observe(timeSinceMs(start))
 
 	// Metric Update
@@ -889,10 +1082,12 @@ func (e *ec2Wrapper) ModifyNetworkInterfaceAttribute(input *ec2.ModifyNetworkInt
 	return modifyNetworkInterfaceAttributeOutput, err
 }
 
-func (e *ec2Wrapper) CreateNetworkInterfacePermission(input *ec2.CreateNetworkInterfacePermissionInput) (*ec2.CreateNetworkInterfacePermissionOutput, error) {
+func (e *ec2Wrapper) CreateNetworkInterfacePermission(ctx context.Context, input *ec2.CreateNetworkInterfacePermissionInput) (*ec2.CreateNetworkInterfacePermissionOutput, error) {
+	ctx, span := startSpan(ctx, "CreateNetworkInterfacePermission")
 	// Add the account ID of the instance running the controller
 	input.AwsAccountId = &e.accountID
-	output, err := e.userServiceClient.CreateNetworkInterfacePermission(context.TODO(), input)
+	output, err := e.userServiceClient.CreateNetworkInterfacePermission(ctx, input)
+	tracing.End(span, err)
 
 	// Metric Update
 	ec2APICallCnt.Inc()
@@ -916,10 +1111,12 @@ func (e *ec2Wrapper) getRegionalStsEndpoint(region string) (string, error) {
 	return ep.URI.String(), nil
 }
 
-func (e *ec2Wrapper) DisassociateTrunkInterface(input *ec2.DisassociateTrunkInterfaceInput) error {
+func (e *ec2Wrapper) DisassociateTrunkInterface(ctx context.Context, input *ec2.DisassociateTrunkInterfaceInput) error {
+	ctx, span := startSpan(ctx, "DisassociateTrunkInterface")
 	start := time.Now()
 	// Using the instance role
-	_, err := e.instanceServiceClient.DisassociateTrunkInterface(context.TODO(), input)
+	_, err := e.instanceServiceClient.DisassociateTrunkInterface(ctx, input)
+	tracing.End(span, err)
 	ec2APICallLatencies.WithLabelValues("disassociate_branch_from_trunk").Observe(timeSinceMs(start))
 
 	ec2APICallCnt.Inc()
@@ -14,6 +14,7 @@
 package ec2
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
@@ -21,6 +22,8 @@ import (
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/ec2/api"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/vpc"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
 )
 
 // ec2Instance stores all the information that can be shared across the providers for an instance
@@ -35,6 +38,10 @@ type ec2Instance struct {
 	instanceID string
 	// instanceType is the EC2 instance type
 	instanceType string
+	// vpcID is the VPC of the instance
+	vpcID string
+	// availabilityZone is the availability zone of the instance
+	availabilityZone string
 	// subnetId is the instance's subnet id
 	instanceSubnetID string
 	// instanceSubnetCidrBlock is the cidr block of the instance's subnet
@@ -64,7 +71,7 @@ type ec2Instance struct {
 
 // EC2Instance exposes the immutable details of an ec2 instance and common operations on an EC2 Instance
 type EC2Instance interface {
-	LoadDetails(ec2APIHelper api.EC2APIHelper) error
+	LoadDetails(ctx context.Context, ec2APIHelper api.EC2APIHelper) error
 	GetHighestUnusedDeviceIndex() (int32, error)
 	FreeDeviceIndex(index int32)
 	Name() string
@@ -72,6 +79,8 @@ type EC2Instance interface {
 	Type() string
 	InstanceID() string
 	SubnetID() string
+	VpcID() string
+	AvailabilityZone() string
 	SubnetMask() string
 	SubnetV6Mask() string
 	SubnetCidrBlock() string
@@ -80,7 +89,7 @@ type EC2Instance interface {
 	CurrentInstanceSecurityGroups() []string
 	SetNewCustomNetworkingSpec(subnetID string, securityGroup []string)
 	GetCustomNetworkingSpec() (subnetID string, securityGroup []string)
-	UpdateCurrentSubnetAndCidrBlock(helper api.EC2APIHelper) error
+	UpdateCurrentSubnetAndCidrBlock(ctx context.Context, helper api.EC2APIHelper) error
 }
 
 // NewEC2Instance returns a new EC2 Instance type
@@ -93,11 +102,11 @@ func NewEC2Instance(nodeName string, instanceID string, os string) EC2Instance {
 }
 
 // LoadDetails loads the instance details by making an EC2 API call
-func (i *ec2Instance) LoadDetails(ec2APIHelper api.EC2APIHelper) error {
+func (i *ec2Instance) LoadDetails(ctx context.Context, ec2APIHelper api.EC2APIHelper) error {
 	i.lock.Lock()
 	defer i.lock.Unlock()
 
-	instance, err := ec2APIHelper.GetInstanceDetails(&i.instanceID)
+	instance, err := ec2APIHelper.GetInstanceDetails(ctx, &i.instanceID)
 	if err != nil {
 		return err
 	}
@@ -107,7 +116,7 @@ func (i *ec2Instance) LoadDetails(ec2APIHelper api.EC2APIHelper) error {
 
 	// Set instance subnet and cidr during node initialization
 	i.instanceSubnetID = *instance.SubnetId
-	instanceSubnet, err := ec2APIHelper.GetSubnet(&i.instanceSubnetID)
+	instanceSubnet, err := ec2APIHelper.GetSubnet(ctx, &i.instanceSubnetID)
 	if err != nil {
 		return err
 	}
@@ -127,6 +136,10 @@ func (i *ec2Instance) LoadDetails(ec2APIHelper api.EC2APIHelper) error {
 	}
 
 	i.instanceType = string(instance.InstanceType)
+	i.vpcID = aws.ToString(instance.VpcId)
+	if instance.Placement != nil {
+		i.availabilityZone = aws.ToString(instance.Placement.AvailabilityZone)
+	}
 	limits, ok := vpc.Limits[i.instanceType]
 	if !ok {
 		return fmt.Errorf("unsupported instance type, couldn't find ENI Limit for instance %s, error: %w", i.instanceType, utils.ErrNotFound)
@@ -163,7 +176,7 @@ func (i *ec2Instance) LoadDetails(ec2APIHelper api.EC2APIHelper) error {
 		}
 	}
 
-	return i.updateCurrentSubnetAndCidrBlock(ec2APIHelper)
+	return i.updateCurrentSubnetAndCidrBlock(ctx, ec2APIHelper)
 }
 
 // Os returns the os of the instance
@@ -184,6 +197,16 @@ func (i *ec2Instance) SubnetID() string {
 	return i.currentSubnetID
 }
 
+// VpcID returns the VPC id of the instance
+func (i *ec2Instance) VpcID() string {
+	return i.vpcID
+}
+
+// AvailabilityZone returns the availability zone of the instance
+func (i *ec2Instance) AvailabilityZone() string {
+	return i.availabilityZone
+}
+
 // SubnetCidrBlock returns the subnet cidr block of the instance
 func (i *ec2Instance) SubnetCidrBlock() string {
 	i.lock.RLock()
@@ -269,16 +292,16 @@ func (i *ec2Instance) SetNewCustomNetworkingSpec(subnet string, securityGroups [
 }
 
 // UpdateCurrentSubnetAndCidrBlock updates the subnet details under a write lock
-func (i *ec2Instance) UpdateCurrentSubnetAndCidrBlock(ec2APIHelper api.EC2APIHelper) error {
+func (i *ec2Instance) UpdateCurrentSubnetAndCidrBlock(ctx context.Context, ec2APIHelper api.EC2APIHelper) error {
 	i.lock.Lock()
 	defer i.lock.Unlock()
 
-	return i.updateCurrentSubnetAndCidrBlock(ec2APIHelper)
+	return i.updateCurrentSubnetAndCidrBlock(ctx, ec2APIHelper)
 }
 
 // updateCurrentSubnetAndCidrBlock updates subnet details and security group if the node is
 // using custom networking
-func (i *ec2Instance) updateCurrentSubnetAndCidrBlock(ec2APIHelper api.EC2APIHelper) error {
+func (i *ec2Instance) updateCurrentSubnetAndCidrBlock(ctx context.Context, ec2APIHelper api.EC2APIHelper) error {
 	// Custom networking is being used on node, point the current subnet ID, CIDR block and
 	// instance security group to the one's present in the Custom networking spec
 	if i.newCustomNetworkingSubnetID != "" {
@@ -290,7 +313,7 @@ func (i *ec2Instance) updateCurrentSubnetAndCidrBlock(ec2APIHelper api.EC2APIHel
 		}
 		// Only get the subnet CIDR block again if the subnet ID has changed
 		if i.newCustomNetworkingSubnetID != i.currentSubnetID {
-			customSubnet, err := ec2APIHelper.GetSubnet(&i.newCustomNetworkingSubnetID)
+			customSubnet, err := ec2APIHelper.GetSubnet(ctx, &i.newCustomNetworkingSubnetID)
 			if err != nil {
 				return err
 			}
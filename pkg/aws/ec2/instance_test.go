@@ -14,6 +14,7 @@
 package ec2
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -110,10 +111,10 @@ func TestEc2Instance_LoadDetails(t *testing.T) {
 
 	ec2Instance, mockEC2ApiHelper := getMockInstance(ctrl)
 
-	mockEC2ApiHelper.EXPECT().GetInstanceDetails(&instanceID).Return(nwInterfaces, nil)
-	mockEC2ApiHelper.EXPECT().GetSubnet(&subnetID).Return(&subnet, nil)
+	mockEC2ApiHelper.EXPECT().GetInstanceDetails(gomock.Any(), &instanceID).Return(nwInterfaces, nil)
+	mockEC2ApiHelper.EXPECT().GetSubnet(gomock.Any(), &subnetID).Return(&subnet, nil)
 
-	err := ec2Instance.LoadDetails(mockEC2ApiHelper)
+	err := ec2Instance.LoadDetails(context.TODO(), mockEC2ApiHelper)
 	assert.NoError(t, err)
 	assert.Equal(t, subnetID, ec2Instance.SubnetID())
 	assert.Equal(t, subnetCidrBlock, ec2Instance.SubnetCidrBlock())
@@ -131,9 +132,9 @@ func TestEc2Instance_LoadDetails_InstanceDetailsIsNull(t *testing.T) {
 
 	ec2Instance, mockEC2ApiHelper := getMockInstance(ctrl)
 
-	mockEC2ApiHelper.EXPECT().GetInstanceDetails(&instanceID).Return(nil, nil)
+	mockEC2ApiHelper.EXPECT().GetInstanceDetails(gomock.Any(), &instanceID).Return(nil, nil)
 
-	err := ec2Instance.LoadDetails(mockEC2ApiHelper)
+	err := ec2Instance.LoadDetails(context.TODO(), mockEC2ApiHelper)
 	assert.NotNil(t, err)
 }
 
@@ -145,9 +146,9 @@ func TestEc2Instance_LoadDetails_InstanceDetails_SubnetID_IsNull(t *testing.T) {
 
 	ec2Instance, mockEC2ApiHelper := getMockInstance(ctrl)
 
-	mockEC2ApiHelper.EXPECT().GetInstanceDetails(&instanceID).Return(&ec2types.Instance{}, nil)
+	mockEC2ApiHelper.EXPECT().GetInstanceDetails(gomock.Any(), &instanceID).Return(&ec2types.Instance{}, nil)
 
-	err := ec2Instance.LoadDetails(mockEC2ApiHelper)
+	err := ec2Instance.LoadDetails(context.TODO(), mockEC2ApiHelper)
 	assert.NotNil(t, err)
 }
 
@@ -159,10 +160,10 @@ func TestEc2Instance_LoadDetails_InstanceSubnet_IsNull(t *testing.T) {
 
 	ec2Instance, mockEC2ApiHelper := getMockInstance(ctrl)
 
-	mockEC2ApiHelper.EXPECT().GetInstanceDetails(&instanceID).Return(nwInterfaces, nil)
-	mockEC2ApiHelper.EXPECT().GetSubnet(&subnetID).Return(nil, nil)
+	mockEC2ApiHelper.EXPECT().GetInstanceDetails(gomock.Any(), &instanceID).Return(nwInterfaces, nil)
+	mockEC2ApiHelper.EXPECT().GetSubnet(gomock.Any(), &subnetID).Return(nil, nil)
 
-	err := ec2Instance.LoadDetails(mockEC2ApiHelper)
+	err := ec2Instance.LoadDetails(context.TODO(), mockEC2ApiHelper)
 	assert.NotNil(t, err)
 }
 
@@ -174,10 +175,10 @@ func TestEc2Instance_LoadDetails_InstanceSubnet_CidrBlock_IsNull(t *testing.T) {
 
 	ec2Instance, mockEC2ApiHelper := getMockInstance(ctrl)
 
-	mockEC2ApiHelper.EXPECT().GetInstanceDetails(&instanceID).Return(nwInterfaces, nil)
-	mockEC2ApiHelper.EXPECT().GetSubnet(&subnetID).Return(&ec2types.Subnet{}, nil)
+	mockEC2ApiHelper.EXPECT().GetInstanceDetails(gomock.Any(), &instanceID).Return(nwInterfaces, nil)
+	mockEC2ApiHelper.EXPECT().GetSubnet(gomock.Any(), &subnetID).Return(&ec2types.Subnet{}, nil)
 
-	err := ec2Instance.LoadDetails(mockEC2ApiHelper)
+	err := ec2Instance.LoadDetails(context.TODO(), mockEC2ApiHelper)
 	assert.NotNil(t, err)
 }
 
@@ -202,11 +203,11 @@ func TestEc2Instance_LoadDetails_SubnetPreLoaded(t *testing.T) {
 
 	customSubnet := &ec2types.Subnet{CidrBlock: &customNWSubnetCidr}
 
-	mockEC2ApiHelper.EXPECT().GetInstanceDetails(&instanceID).Return(nwInterfaces, nil)
-	mockEC2ApiHelper.EXPECT().GetSubnet(&subnetID).Return(&subnet, nil)
-	mockEC2ApiHelper.EXPECT().GetSubnet(&customNWSubnetID).Return(customSubnet, nil)
+	mockEC2ApiHelper.EXPECT().GetInstanceDetails(gomock.Any(), &instanceID).Return(nwInterfaces, nil)
+	mockEC2ApiHelper.EXPECT().GetSubnet(gomock.Any(), &subnetID).Return(&subnet, nil)
+	mockEC2ApiHelper.EXPECT().GetSubnet(gomock.Any(), &customNWSubnetID).Return(customSubnet, nil)
 
-	err := ec2Instance.LoadDetails(mockEC2ApiHelper)
+	err := ec2Instance.LoadDetails(context.TODO(), mockEC2ApiHelper)
 	assert.NoError(t, err)
 	assert.Equal(t, customNWSubnetID, ec2Instance.currentSubnetID)
 	assert.Equal(t, customNWSecurityGroups, ec2Instance.currentInstanceSecurityGroups)
@@ -221,9 +222,9 @@ func TestEc2Instance_LoadDetails_ErrInstanceDetails(t *testing.T) {
 
 	ec2Instance, mockEC2ApiHelper := getMockInstance(ctrl)
 
-	mockEC2ApiHelper.EXPECT().GetInstanceDetails(&instanceID).Return(nil, mockError)
+	mockEC2ApiHelper.EXPECT().GetInstanceDetails(gomock.Any(), &instanceID).Return(nil, mockError)
 
-	err := ec2Instance.LoadDetails(mockEC2ApiHelper)
+	err := ec2Instance.LoadDetails(context.TODO(), mockEC2ApiHelper)
 	assert.Error(t, mockError, err)
 }
 
@@ -235,10 +236,10 @@ func TestEc2Instance_LoadDetails_ErrGetSubnet(t *testing.T) {
 
 	ec2Instance, mockEC2ApiHelper := getMockInstance(ctrl)
 
-	mockEC2ApiHelper.EXPECT().GetInstanceDetails(&instanceID).Return(nwInterfaces, nil)
-	mockEC2ApiHelper.EXPECT().GetSubnet(&subnetID).Return(nil, mockError)
+	mockEC2ApiHelper.EXPECT().GetInstanceDetails(gomock.Any(), &instanceID).Return(nwInterfaces, nil)
+	mockEC2ApiHelper.EXPECT().GetSubnet(gomock.Any(), &subnetID).Return(nil, mockError)
 
-	err := ec2Instance.LoadDetails(mockEC2ApiHelper)
+	err := ec2Instance.LoadDetails(context.TODO(), mockEC2ApiHelper)
 	assert.Error(t, mockError, err)
 }
 
@@ -254,10 +255,10 @@ func TestEc2Instance_LoadDetails_InstanceENILimitNotFound(t *testing.T) {
 
 	nwInterfaces.InstanceType = unsupportedInstance
 
-	mockEC2ApiHelper.EXPECT().GetInstanceDetails(&instanceID).Return(nwInterfaces, nil)
-	mockEC2ApiHelper.EXPECT().GetSubnet(&subnetID).Return(&subnet, nil)
+	mockEC2ApiHelper.EXPECT().GetInstanceDetails(gomock.Any(), &instanceID).Return(nwInterfaces, nil)
+	mockEC2ApiHelper.EXPECT().GetSubnet(gomock.Any(), &subnetID).Return(&subnet, nil)
 
-	err := ec2Instance.LoadDetails(mockEC2ApiHelper)
+	err := ec2Instance.LoadDetails(context.TODO(), mockEC2ApiHelper)
 	assert.NotNil(t, err)
 	// ensure the expected error is returned to trigger a node event
 	assert.ErrorIs(t, err, utils.ErrNotFound)
@@ -313,11 +314,11 @@ func TestEc2Instance_E2E(t *testing.T) {
 
 	ec2Instance, mockEC2ApiHelper := getMockInstance(ctrl)
 
-	mockEC2ApiHelper.EXPECT().GetInstanceDetails(&instanceID).Return(nwInterfaces, nil)
-	mockEC2ApiHelper.EXPECT().GetSubnet(&subnetID).Return(&subnet, nil)
+	mockEC2ApiHelper.EXPECT().GetInstanceDetails(gomock.Any(), &instanceID).Return(nwInterfaces, nil)
+	mockEC2ApiHelper.EXPECT().GetSubnet(gomock.Any(), &subnetID).Return(&subnet, nil)
 
 	// Assert no error on loading the instance details
-	err := ec2Instance.LoadDetails(mockEC2ApiHelper)
+	err := ec2Instance.LoadDetails(context.TODO(), mockEC2ApiHelper)
 	assert.NoError(t, err)
 
 	// Check index is not used, assign index and verify index is used now
@@ -352,11 +353,11 @@ func TestEc2Instance_LoadDetails_InvalidCustomNetworkingConfiguration(t *testing
 	customNWSubnetCidr := "192.2.0.0/24"
 	customSubnet := &ec2types.Subnet{CidrBlock: &customNWSubnetCidr}
 
-	mockEC2ApiHelper.EXPECT().GetInstanceDetails(&instanceID).Return(nwInterfaces, nil)
-	mockEC2ApiHelper.EXPECT().GetSubnet(&subnetID).Return(&subnet, nil)
-	mockEC2ApiHelper.EXPECT().GetSubnet(&customNWSubnetID).Return(customSubnet, nil)
+	mockEC2ApiHelper.EXPECT().GetInstanceDetails(gomock.Any(), &instanceID).Return(nwInterfaces, nil)
+	mockEC2ApiHelper.EXPECT().GetSubnet(gomock.Any(), &subnetID).Return(&subnet, nil)
+	mockEC2ApiHelper.EXPECT().GetSubnet(gomock.Any(), &customNWSubnetID).Return(customSubnet, nil)
 
-	err := ec2Instance.LoadDetails(mockEC2ApiHelper)
+	err := ec2Instance.LoadDetails(context.TODO(), mockEC2ApiHelper)
 	assert.NoError(t, err)
 	assert.Equal(t, customNWSubnetID, ec2Instance.currentSubnetID)
 	// Expect the primary network interface security groups when ENIConfig SG is missing
@@ -3,4 +3,9 @@ package errors
 const (
 	NotFoundAssociationID = "InvalidAssociationID.NotFound"
 	NotFoundInterfaceID   = "InvalidNetworkInterfaceID.NotFound"
+	NotFoundSubnetID      = "InvalidSubnetID.NotFound"
+	NotFoundGroupID       = "InvalidGroup.NotFound"
+	NotFoundInstanceID    = "InvalidInstanceID.NotFound"
+
+	InsufficientFreeAddressesInSubnet = "InsufficientFreeAddressesInSubnet"
 )
@@ -44,6 +44,10 @@ type Conditions interface {
 	// IsWindowsPrefixDelegationEnabled to process events only when Windows Prefix Delegation is enabled
 	IsWindowsPrefixDelegationEnabled() bool
 
+	// IsWindowsSecurityGroupsForPodsEnabled to process Windows pods matching a SecurityGroupPolicy with
+	// branch ENIs, only when Windows IPAM and Windows Security Groups for Pods are enabled
+	IsWindowsSecurityGroupsForPodsEnabled() bool
+
 	// IsPodSGPEnabled to process events only when Security Group for Pods feature
 	// is enabled by the user
 	// IsPodSGPEnabled() bool We need to check if SGP is enabled via ConfigMap + Environment variables
@@ -72,6 +76,12 @@ var (
 			Help: "Binary value to indicate whether user has set enable-windows-prefix-delegation to true",
 		})
 
+	conditionWindowsSecurityGroupsForPodsEnabled = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "windows_security_groups_for_pods_enabled",
+			Help: "Binary value to indicate whether user has set enable-windows-security-groups-for-pods to true",
+		})
+
 	prometheusRegistered = false
 )
 
@@ -80,6 +90,7 @@ func prometheusRegister() {
 		metrics.Registry.MustRegister(
 			conditionWindowsIPAMEnabled,
 			conditionWindowsPrefixDelegationEnabled,
+			conditionWindowsSecurityGroupsForPodsEnabled,
 		)
 	}
 
@@ -90,6 +101,7 @@ func NewControllerConditions(log logr.Logger, k8sApi k8s.K8sWrapper, windowsPDFe
 	prometheusRegister()
 	conditionWindowsIPAMEnabled.Set(0)
 	conditionWindowsPrefixDelegationEnabled.Set(0)
+	conditionWindowsSecurityGroupsForPodsEnabled.Set(0)
 
 	return &condition{
 		log:                  log,
@@ -154,6 +166,34 @@ func (c *condition) IsWindowsPrefixDelegationEnabled() bool {
 	return false
 }
 
+func (c *condition) IsWindowsSecurityGroupsForPodsEnabled() bool {
+	if c.IsOldVPCControllerDeploymentPresent() {
+		return false
+	}
+
+	// Return false if configmap not present/any errors
+	vpcCniConfigMap, err := c.K8sAPI.GetConfigMap(config.VpcCniConfigMapName, config.KubeSystemNamespace)
+
+	if err == nil && vpcCniConfigMap.Data != nil {
+		if ipamVal, ok := vpcCniConfigMap.Data[config.EnableWindowsIPAMKey]; ok {
+			// Branch ENIs on Windows are managed by the controller, so Windows IPAM must be enabled
+			enableWinIpamVal, err := strconv.ParseBool(ipamVal)
+			if err == nil && enableWinIpamVal {
+				if sgpVal, ok := vpcCniConfigMap.Data[config.EnableWindowsSecurityGroupsForPodsKey]; ok {
+					enableWinSGPVal, err := strconv.ParseBool(sgpVal)
+					if err == nil && enableWinSGPVal {
+						conditionWindowsSecurityGroupsForPodsEnabled.Set(1)
+						return true
+					}
+				}
+			}
+		}
+	}
+
+	conditionWindowsSecurityGroupsForPodsEnabled.Set(0)
+	return false
+}
+
 // Watch for deployments of old VPC Resource controller, new controller will block
 // till the user deletes the old controller deployment. Ideally we should block till
 // old WebHook is deleted too. But the Field selectors don't support IN operator for
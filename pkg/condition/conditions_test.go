@@ -154,6 +154,62 @@ func TestCondition_IsWindowsIPAMEnabled(t *testing.T) {
 	}
 }
 
+func TestCondition_IsWindowsSecurityGroupsForPodsEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected bool
+		data     map[string]string
+	}{
+		{
+			name:     "windows ipam not enabled",
+			expected: false,
+			data: map[string]string{
+				config.EnableWindowsSecurityGroupsForPodsKey: "true",
+			},
+		},
+		{
+			name:     "flag not set",
+			expected: false,
+			data: map[string]string{
+				config.EnableWindowsIPAMKey: "true",
+			},
+		},
+		{
+			name:     "flag set to non boolean value",
+			expected: false,
+			data: map[string]string{
+				config.EnableWindowsIPAMKey:                  "true",
+				config.EnableWindowsSecurityGroupsForPodsKey: "trued",
+			},
+		},
+		{
+			name:     "flag set to true",
+			expected: true,
+			data: map[string]string{
+				config.EnableWindowsIPAMKey:                  "true",
+				config.EnableWindowsSecurityGroupsForPodsKey: "true",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockK8s := mock_k8s.NewMockK8sWrapper(ctrl)
+			conditions := NewControllerConditions(zap.New(), mockK8s, false)
+
+			mockK8s.EXPECT().GetDeployment(config.KubeSystemNamespace,
+				config.OldVPCControllerDeploymentName).Return(nil, notFoundErr)
+			mockK8s.EXPECT().GetConfigMap(config.VpcCniConfigMapName,
+				config.KubeSystemNamespace).Return(&v1.ConfigMap{Data: test.data}, nil)
+
+			assert.Equal(t, test.expected, conditions.IsWindowsSecurityGroupsForPodsEnabled())
+		})
+	}
+}
+
 // TestCondition_GetPodDataStoreSyncStatus tests two group of routines which are setting (write) the sync flag field
 // and are getting (read) the field.
 // In real case, pod controller routines keep checking the cache status and set the sync field to true if cache is ready.
@@ -43,6 +43,21 @@ const (
 	IPv4PDDefaultWarmIPTargetSize     = 1
 	IPv4PDDefaultMinIPTargetSize      = 3
 	IPv4PDDefaultWarmPrefixTargetSize = 0
+
+	// Default Configuration for IPv6 prefix resource type
+	IPv6PDDefaultWorker               = 2
+	IPv6PDDefaultWPSize               = 1
+	IPv6PDDefaultMaxDev               = 0
+	IPv6PDDefaultResSize              = 0
+	IPv6PDDefaultWarmIPTargetSize     = 1
+	IPv6PDDefaultMinIPTargetSize      = 3
+	IPv6PDDefaultWarmPrefixTargetSize = 0
+	// IPv6PDDefaultAddressesPerPrefix is the default number of addresses handed out from each /80 IPv6 prefix. A /80
+	// prefix holds 2^48 addresses, far more than a node can run pods, so the count only bounds the pool granularity.
+	// It's kept same as the 16 addresses of an IPv4 /28 prefix so the prefix pool targets mean the same for both
+	IPv6PDDefaultAddressesPerPrefix = 16
+	// IPv6PDMaxAddressesPerPrefix is the upper bound of the configurable number of addresses per /80 IPv6 prefix
+	IPv6PDMaxAddressesPerPrefix = 256
 )
 
 // LoadResourceConfig returns the Resource Configuration for all resources managed by the VPC Resource Controller. Currently
@@ -55,6 +70,10 @@ func LoadResourceConfigFromConfigMap(log logr.Logger, vpcCniConfigMap *v1.Config
 	resourceConfig := getDefaultResourceConfig()
 
 	warmIPTarget, minIPTarget, warmPrefixTarget, isPDEnabled := ParseWinIPTargetConfigs(log, vpcCniConfigMap)
+	if isPDEnabled {
+		resourceConfig[ResourceNameIPAddressFromPrefix].WarmPoolConfig.PrefixCompaction =
+			ParseWinPrefixCompaction(log, vpcCniConfigMap)
+	}
 
 	// If no PD configuration is set in configMap or none is valid, return default resource config
 	if warmIPTarget == 0 && minIPTarget == 0 && warmPrefixTarget == 0 {
@@ -163,17 +182,34 @@ func ParseWinIPTargetConfigs(log logr.Logger, vpcCniConfigMap *v1.ConfigMap) (wa
 	return warmIPTarget, minIPTarget, warmPrefixTarget, isPDEnabled
 }
 
+// ParseWinPrefixCompaction parses the Windows prefix compaction flag in the amazon-vpc-cni ConfigMap, compaction is
+// disabled if the flag is unset or invalid
+func ParseWinPrefixCompaction(log logr.Logger, vpcCniConfigMap *v1.ConfigMap) bool {
+	compactionStr, found := vpcCniConfigMap.Data[WinPrefixCompactionKey]
+	if !found {
+		return false
+	}
+
+	isCompactionEnabled, err := strconv.ParseBool(compactionStr)
+	if err != nil {
+		log.Info("Could not parse prefix compaction flag from ConfigMap, defaulting to disabled",
+			"prefix compaction", compactionStr)
+		return false
+	}
+	return isCompactionEnabled
+}
+
 // getDefaultResourceConfig returns the default Resource Configuration.
 func getDefaultResourceConfig() map[string]ResourceConfig {
 
 	config := make(map[string]ResourceConfig)
 
 	// Create default configuration for Pod ENI Resource
+	// The warm pools of branch ENIs are disabled by default
 	podENIConfig := ResourceConfig{
-		Name:           ResourceNamePodENI,
-		WorkerCount:    PodENIDefaultWorker,
-		SupportedOS:    map[string]bool{OSWindows: false, OSLinux: true},
-		WarmPoolConfig: nil,
+		Name:        ResourceNamePodENI,
+		WorkerCount: PodENIDefaultWorker,
+		SupportedOS: map[string]bool{OSWindows: true, OSLinux: true},
 	}
 	config[ResourceNamePodENI] = podENIConfig
 
@@ -210,5 +246,23 @@ func getDefaultResourceConfig() map[string]ResourceConfig {
 	}
 	config[ResourceNameIPAddressFromPrefix] = prefixIPv4Config
 
+	// Create default configuration for prefix-deconstructed IPv6 resource pool
+	prefixIPv6WarmPoolConfig := WarmPoolConfig{
+		DesiredSize:        IPv6PDDefaultWPSize,
+		MaxDeviation:       IPv6PDDefaultMaxDev,
+		ReservedSize:       IPv6PDDefaultResSize,
+		WarmIPTarget:       IPv6PDDefaultWarmIPTargetSize,
+		MinIPTarget:        IPv6PDDefaultMinIPTargetSize,
+		WarmPrefixTarget:   IPv6PDDefaultWarmPrefixTargetSize,
+		AddressesPerPrefix: IPv6PDDefaultAddressesPerPrefix,
+	}
+	prefixIPv6Config := ResourceConfig{
+		Name:           ResourceNameIPv6Address,
+		WorkerCount:    IPv6PDDefaultWorker,
+		SupportedOS:    map[string]bool{OSWindows: true, OSLinux: true},
+		WarmPoolConfig: &prefixIPv6WarmPoolConfig,
+	}
+	config[ResourceNameIPv6Address] = prefixIPv6Config
+
 	return config
 }
@@ -30,7 +30,7 @@ func TestLoadResourceConfig(t *testing.T) {
 	podENIConfig := defaultResourceConfig[ResourceNamePodENI]
 	assert.Equal(t, ResourceNamePodENI, podENIConfig.Name)
 	assert.Equal(t, PodENIDefaultWorker, podENIConfig.WorkerCount)
-	assert.Equal(t, map[string]bool{OSLinux: true, OSWindows: false}, podENIConfig.SupportedOS)
+	assert.Equal(t, map[string]bool{OSLinux: true, OSWindows: true}, podENIConfig.SupportedOS)
 	assert.Nil(t, podENIConfig.WarmPoolConfig)
 
 	// Verify default resource configuration for resource IPv4 Address
@@ -60,6 +60,21 @@ func TestLoadResourceConfig(t *testing.T) {
 	assert.Equal(t, IPv4PDDefaultWarmIPTargetSize, prefixIPv4WPConfig.WarmIPTarget)
 	assert.Equal(t, IPv4PDDefaultMinIPTargetSize, prefixIPv4WPConfig.MinIPTarget)
 	assert.Equal(t, IPv4PDDefaultWarmPrefixTargetSize, prefixIPv4WPConfig.WarmPrefixTarget)
+
+	// Verify default resource configuration for prefix-deconstructed IPv6 Address
+	prefixIPv6Config := defaultResourceConfig[ResourceNameIPv6Address]
+	assert.Equal(t, ResourceNameIPv6Address, prefixIPv6Config.Name)
+	assert.Equal(t, IPv6PDDefaultWorker, prefixIPv6Config.WorkerCount)
+	assert.Equal(t, map[string]bool{OSLinux: true, OSWindows: true}, prefixIPv6Config.SupportedOS)
+
+	// Verify default Warm pool configuration for prefix-deconstructed IPv6 Address
+	prefixIPv6WPConfig := prefixIPv6Config.WarmPoolConfig
+	assert.Equal(t, IPv6PDDefaultWPSize, prefixIPv6WPConfig.DesiredSize)
+	assert.Equal(t, IPv6PDDefaultMaxDev, prefixIPv6WPConfig.MaxDeviation)
+	assert.Equal(t, IPv6PDDefaultResSize, prefixIPv6WPConfig.ReservedSize)
+	assert.Equal(t, IPv6PDDefaultWarmIPTargetSize, prefixIPv6WPConfig.WarmIPTarget)
+	assert.Equal(t, IPv6PDDefaultMinIPTargetSize, prefixIPv6WPConfig.MinIPTarget)
+	assert.Equal(t, IPv6PDDefaultWarmPrefixTargetSize, prefixIPv6WPConfig.WarmPrefixTarget)
 }
 
 // TestParseWinIPTargetConfigs_PDEnabledWithDefaultTargets parses prefix delegation configurations from a vpc cni config map
@@ -361,7 +376,7 @@ func TestLoadResourceConfigFromConfigMap(t *testing.T) {
 	podENIConfig := resourceConfig[ResourceNamePodENI]
 	assert.Equal(t, ResourceNamePodENI, podENIConfig.Name)
 	assert.Equal(t, PodENIDefaultWorker, podENIConfig.WorkerCount)
-	assert.Equal(t, map[string]bool{OSLinux: true, OSWindows: false}, podENIConfig.SupportedOS)
+	assert.Equal(t, map[string]bool{OSLinux: true, OSWindows: true}, podENIConfig.SupportedOS)
 	assert.Nil(t, podENIConfig.WarmPoolConfig)
 
 	// Verify default resource configuration for resource IPv4 Address
@@ -390,4 +405,34 @@ func TestLoadResourceConfigFromConfigMap(t *testing.T) {
 	assert.Equal(t, warmIPTarget, prefixIPv4WPConfig.WarmIPTarget)
 	assert.Equal(t, minimumIPTarget, prefixIPv4WPConfig.MinIPTarget)
 	assert.Equal(t, warmPrefixTarget, prefixIPv4WPConfig.WarmPrefixTarget)
+	assert.False(t, prefixIPv4WPConfig.PrefixCompaction)
+}
+
+// TestParseWinPrefixCompaction tests the prefix compaction flag is parsed and defaults to disabled
+func TestParseWinPrefixCompaction(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true)).WithName("loader test")
+
+	assert.False(t, ParseWinPrefixCompaction(log, &v1.ConfigMap{}))
+	assert.False(t, ParseWinPrefixCompaction(log, &v1.ConfigMap{Data: map[string]string{WinPrefixCompactionKey: "invalid"}}))
+	assert.True(t, ParseWinPrefixCompaction(log, &v1.ConfigMap{Data: map[string]string{WinPrefixCompactionKey: "true"}}))
+}
+
+// TestLoadResourceConfigFromConfigMap_PrefixCompaction tests prefix compaction is enabled on the prefix IP pool only
+// when prefix delegation is enabled
+func TestLoadResourceConfigFromConfigMap_PrefixCompaction(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true)).WithName("loader test")
+
+	vpcCNIConfig := &v1.ConfigMap{
+		Data: map[string]string{
+			EnableWindowsIPAMKey:             "true",
+			EnableWindowsPrefixDelegationKey: "true",
+			WinPrefixCompactionKey:           "true",
+		},
+	}
+	resourceConfig := LoadResourceConfigFromConfigMap(log, vpcCNIConfig)
+	assert.True(t, resourceConfig[ResourceNameIPAddressFromPrefix].WarmPoolConfig.PrefixCompaction)
+
+	vpcCNIConfig.Data[EnableWindowsPrefixDelegationKey] = "false"
+	resourceConfig = LoadResourceConfigFromConfigMap(log, vpcCNIConfig)
+	assert.False(t, resourceConfig[ResourceNameIPAddressFromPrefix].WarmPoolConfig.PrefixCompaction)
 }
@@ -29,6 +29,8 @@ const (
 	ResourceNameIPAddress = VPCResourcePrefix + "PrivateIPv4Address"
 	// ResourceNameIPAddressFromPrefix is the resource name for prefix-deconstructed IP addresses, not a pod annotation
 	ResourceNameIPAddressFromPrefix = VPCResourcePrefix + "PrivateIPv4AddressFromPrefix"
+	// ResourceNameIPv6Address is the extended resource name for IPv6 addresses deconstructed from /80 IPv6 prefixes
+	ResourceNameIPv6Address = VPCResourcePrefix + "PrivateIPv6Address"
 )
 
 // K8s Labels
@@ -75,6 +77,8 @@ const (
 	VpcCniConfigMapName              = "amazon-vpc-cni"
 	EnableWindowsIPAMKey             = "enable-windows-ipam"
 	EnableWindowsPrefixDelegationKey = "enable-windows-prefix-delegation"
+	// EnableWindowsSecurityGroupsForPodsKey enables branch ENIs for Windows pods, requires Windows IPAM to be enabled
+	EnableWindowsSecurityGroupsForPodsKey = "enable-windows-security-groups-for-pods"
 	// TODO: we will deprecate the confusing naming of Windows flags eventually
 	WarmPrefixTarget = "warm-prefix-target"
 	WarmIPTarget     = "warm-ip-target"
@@ -83,6 +87,8 @@ const (
 	WinWarmPrefixTarget = "windows-warm-prefix-target"
 	WinWarmIPTarget     = "windows-warm-ip-target"
 	WinMinimumIPTarget  = "windows-minimum-ip-target"
+	// WinPrefixCompactionKey enables draining of the fragmented prefixes of the Windows prefix IP pool
+	WinPrefixCompactionKey = "windows-prefix-compaction"
 	// Since LeaderElectionNamespace and VpcCniConfigMapName may be different in the future
 	KubeSystemNamespace            = "kube-system"
 	VpcCNIDaemonSetName            = "aws-node"
@@ -90,6 +96,19 @@ const (
 	BranchENICooldownPeriodKey     = "branch-eni-cooldown"
 	// DescribeNetworkInterfacesMaxResults defines the max number of requests to return for DescribeNetworkInterfaces API call
 	DescribeNetworkInterfacesMaxResults = int64(1000)
+	// DefaultMaxSecurityGroupsPerENI is the default EC2 quota of security groups per network interface, accounts
+	// with an increased quota must raise the limit with the max-security-groups-per-eni flag
+	DefaultMaxSecurityGroupsPerENI = 5
+	// BranchENISecurityGroupUpdateQPS is the rate of EC2 calls to update the security groups of the branch ENIs
+	// when a SecurityGroupPolicy changes
+	BranchENISecurityGroupUpdateQPS = 2
+	// BranchENISecurityGroupUpdateBurst is the burst of EC2 calls to update the security groups of the branch ENIs
+	BranchENISecurityGroupUpdateBurst = 5
+	// BranchENISecurityGroupUpdateRetryBufferSize is the number of pods whose branch ENIs failed to update that can
+	// be queued for retry before the namespace reconcile blocks
+	BranchENISecurityGroupUpdateRetryBufferSize = 100
+	// CheckpointVerifyJitterFactor is the maximum factor of CheckpointVerifyDelay added to the delay of each node
+	CheckpointVerifyJitterFactor = 1.0
 )
 
 type ResourceType string
@@ -97,6 +116,7 @@ type ResourceType string
 const (
 	ResourceTypeIPv4Address ResourceType = "IPv4Address"
 	ResourceTypeIPv4Prefix  ResourceType = "IPv4Prefix"
+	ResourceTypeIPv6Prefix  ResourceType = "IPv6Prefix"
 )
 
 // IPResourceCount contains the arguments for number of IPv4 resources to request
@@ -129,6 +149,31 @@ var (
 	CoolDownPeriod = time.Second * 30
 	// ENICleanUpInterval is the time interval between each dangling ENI clean up task
 	ENICleanUpInterval = time.Minute * 30
+	// CNINodeStatusUpdateInterval is the time interval between each refresh of the CNINode status
+	CNINodeStatusUpdateInterval = time.Minute
+	// SecurityGroupPolicyStatusUpdateInterval is the time interval between each validation of the SecurityGroupPolicy
+	// security groups and refresh of its status
+	SecurityGroupPolicyStatusUpdateInterval = time.Minute * 5
+	// BranchENIWarmPoolIdleTimeout is the time after which the warm pool of branch ENIs for a set of security groups
+	// is drained if no pod requested branch ENIs with those security groups
+	BranchENIWarmPoolIdleTimeout = time.Minute * 30
+	// BranchENISubnetRefreshInterval is the time interval between each refresh of the available IPv4 addresses of
+	// the candidate subnets for the branch ENIs
+	BranchENISubnetRefreshInterval = time.Minute
+	// SubnetMonitorInterval is the time interval between each check of the free IPv4 addresses and /28 prefix blocks
+	// of the subnets used by the managed nodes
+	SubnetMonitorInterval = time.Minute * 5
+	// SubnetExhaustionForecastWindow is the time window of the samples used to compute the IPv4 address allocation rate
+	// of a subnet
+	SubnetExhaustionForecastWindow = time.Minute * 30
+	// SubnetExhaustionWarningThreshold is the predicted time to exhaustion of a subnet below which the subnet is warned
+	SubnetExhaustionWarningThreshold = time.Hour
+	// CheckpointVerifyDelay is the minimum time after which the pools and the branch ENIs of a node initialized from the
+	// checkpoint are verified against EC2, the delay is jittered so the nodes are not verified all at once
+	CheckpointVerifyDelay = time.Second * 30
+	// LookupIndexRetention is the time the owner of a released IP address or branch ENI is kept in the lookup index
+	// of the introspection API, if the resource isn't assigned to another pod
+	LookupIndexRetention = time.Hour
 )
 
 // ResourceConfig is the configuration for each resource type
@@ -158,4 +203,9 @@ type WarmPoolConfig struct {
 	MinIPTarget int
 	// The number of prefixes to be available in prefix IP pool
 	WarmPrefixTarget int
+	// Whether partially used prefixes are drained so they can be released from the prefix IP pool
+	PrefixCompaction bool
+	// The number of addresses handed out from each prefix in prefix IP pool, the 16 addresses of an IPv4 /28
+	// prefix are used if not set
+	AddressesPerPrefix int
 }
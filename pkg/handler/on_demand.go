@@ -14,6 +14,8 @@
 package handler
 
 import (
+	"context"
+
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/worker"
 
@@ -39,9 +41,9 @@ func NewOnDemandHandler(log logr.Logger, resourceName string,
 }
 
 // HandleCreate provides the resource to the on demand resource by passing the Create Job to the respective Worker
-func (h *onDemandResourceHandler) HandleCreate(requestCount int, pod *v1.Pod) (ctrl.Result, error) {
+func (h *onDemandResourceHandler) HandleCreate(ctx context.Context, requestCount int, pod *v1.Pod) (ctrl.Result, error) {
 	job := worker.NewOnDemandCreateJob(pod.Namespace, pod.Name, requestCount)
-	h.resourceProvider.SubmitAsyncJob(job)
+	h.resourceProvider.SubmitAsyncJobWithContext(ctx, job)
 
 	return ctrl.Result{}, nil
 }
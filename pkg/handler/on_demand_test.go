@@ -14,6 +14,7 @@
 package handler
 
 import (
+	"context"
 	"testing"
 
 	"github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/provider"
@@ -96,9 +97,9 @@ func Test_HandleCreate(t *testing.T) {
 
 	handler, mockProvider := getHandlerWithMock(ctrl)
 
-	mockProvider.EXPECT().SubmitAsyncJob(createJob)
+	mockProvider.EXPECT().SubmitAsyncJobWithContext(gomock.Any(), createJob)
 
-	_, err := handler.HandleCreate(1, mockPod)
+	_, err := handler.HandleCreate(context.TODO(), 1, mockPod)
 	assert.NoError(t, err)
 }
 
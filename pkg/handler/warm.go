@@ -21,6 +21,8 @@ import (
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/api"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/pool"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/timetonetwork"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/tracing"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/worker"
 
 	"github.com/go-logr/logr"
@@ -42,10 +44,12 @@ type warmResourceHandler struct {
 	resourceProvider provider.ResourceProvider
 	resourceName     string
 	ctx              context.Context
+	timeToNetwork    *timetonetwork.Tracker
 }
 
 func NewWarmResourceHandler(log logr.Logger, wrapper api.Wrapper,
-	resourceName string, resourceProviders provider.ResourceProvider, ctx context.Context) Handler {
+	resourceName string, resourceProviders provider.ResourceProvider, ctx context.Context,
+	timeToNetwork *timetonetwork.Tracker) Handler {
 
 	return &warmResourceHandler{
 		log:              log,
@@ -53,10 +57,15 @@ func NewWarmResourceHandler(log logr.Logger, wrapper api.Wrapper,
 		resourceProvider: resourceProviders,
 		resourceName:     resourceName,
 		ctx:              ctx,
+		timeToNetwork:    timeToNetwork,
 	}
 }
 
-func (w *warmResourceHandler) HandleCreate(_ int, pod *v1.Pod) (ctrl.Result, error) {
+func (w *warmResourceHandler) HandleCreate(ctx context.Context, _ int, pod *v1.Pod) (result ctrl.Result, err error) {
+	ctx, span := tracing.Start(ctx, "warmResourceHandler.HandleCreate",
+		append(tracing.PodAttributes(pod), tracing.AttributeResourceName.String(w.resourceName))...)
+	defer func() { tracing.End(span, err) }()
+
 	resourcePool, err := w.getResourcePool(pod.Spec.NodeName)
 	if err != nil {
 		return ctrl.Result{}, err
@@ -72,7 +81,7 @@ func (w *warmResourceHandler) HandleCreate(_ int, pod *v1.Pod) (ctrl.Result, err
 	resID, shouldReconcile, err := resourcePool.AssignResource(string(pod.UID))
 	if err != nil {
 		// Reconcile the pool before retrying or returning an error
-		w.reconcilePool(shouldReconcile, resourcePool)
+		w.reconcilePool(ctx, shouldReconcile, resourcePool)
 		switch err {
 		case pool.ErrResourceAreBeingCooledDown:
 			log.V(1).Info("resources are currently being cooled down, will retry")
@@ -112,12 +121,16 @@ func (w *warmResourceHandler) HandleCreate(_ int, pod *v1.Pod) (ctrl.Result, err
 		}
 	}
 
+	_, annotateSpan := tracing.Start(ctx, "AnnotatePod", tracing.PodAttributes(pod)...)
 	err = w.APIWrapper.PodAPI.AnnotatePod(pod.Namespace, pod.Name, pod.UID, w.resourceName, resID)
+	tracing.End(annotateSpan, err)
 	if err != nil {
 		_, errFree := resourcePool.FreeResource(string(pod.UID), resID)
 		if errFree != nil {
 			err = fmt.Errorf("failed to annotate %v, failed to free %v", err, errFree)
 		}
+	} else {
+		w.timeToNetwork.Annotated(pod, w.resourceName)
 	}
 
 	w.APIWrapper.K8sAPI.BroadcastEvent(pod, ReasonResourceAllocated,
@@ -125,16 +138,17 @@ func (w *warmResourceHandler) HandleCreate(_ int, pod *v1.Pod) (ctrl.Result, err
 
 	log.Info("successfully allocated and annotated resource", "resource id", resID)
 
-	w.reconcilePool(shouldReconcile, resourcePool)
+	w.reconcilePool(ctx, shouldReconcile, resourcePool)
 
 	return ctrl.Result{}, err
 }
 
-func (w *warmResourceHandler) reconcilePool(shouldReconcile bool, resourcePool pool.Pool) {
+// reconcilePool submits the job to reconcile the pool if required, the job continues the trace of the context
+func (w *warmResourceHandler) reconcilePool(ctx context.Context, shouldReconcile bool, resourcePool pool.Pool) {
 	if shouldReconcile {
 		job := resourcePool.ReconcilePool()
 		if job.Operations != worker.Operations("") {
-			w.resourceProvider.SubmitAsyncJob(job)
+			w.resourceProvider.SubmitAsyncJobWithContext(ctx, job)
 		}
 	}
 }
@@ -175,7 +189,7 @@ func (w *warmResourceHandler) HandleDelete(pod *v1.Pod) (ctrl.Result, error) {
 		return ctrl.Result{}, nil
 	}
 
-	w.reconcilePool(shouldReconcile, resourcePool)
+	w.reconcilePool(context.Background(), shouldReconcile, resourcePool)
 
 	log.Info("successfully freed resource")
 
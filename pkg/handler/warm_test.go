@@ -14,6 +14,8 @@
 package handler
 
 import (
+	"context"
+	"fmt"
 	"testing"
 
 	"github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/k8s"
@@ -23,6 +25,7 @@ import (
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/api"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/pool"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/timetonetwork"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/worker"
 
 	"github.com/golang/mock/gomock"
@@ -61,25 +64,28 @@ var (
 )
 
 // TestWarmResourceHandler_HandleCreate tests create assigns a resource and annotates the pod and then reconciles a pool
-// and submits the job to the resource provider
+// and submits the job to the resource provider, the time the pod waited is observed once the pod is annotated
 func TestWarmResourceHandler_HandleCreate(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	handler, mockK8sWrapper, mockPodAPI, mockProvider, mockPool := getHandlerAndMocks(ctrl)
 	podCopy := pod.DeepCopy()
+	delete(podCopy.Annotations, config.ResourceNameIPAddress)
+
+	handler.timeToNetwork = timetonetwork.NewTracker(mockK8sWrapper, 0)
+	handler.timeToNetwork.Track(podCopy, resourceName, false)
 
 	mockProvider.EXPECT().GetPool(nodeName).Return(mockPool, true)
 	mockPool.EXPECT().AssignResource(uid).Return(ipAddress, true, nil)
 	mockPodAPI.EXPECT().AnnotatePod(pod.Namespace, pod.Name, types.UID(uid), resourceName, ipAddress).Return(nil)
+	mockK8sWrapper.EXPECT().GetNode(nodeName).Return(nil, fmt.Errorf("not found"))
 	mockK8sWrapper.EXPECT().BroadcastEvent(podCopy, ReasonResourceAllocated, gomock.Any(), v1.EventTypeNormal)
 
 	mockPool.EXPECT().ReconcilePool().Return(job)
-	mockProvider.EXPECT().SubmitAsyncJob(job)
-
-	delete(podCopy.Annotations, config.ResourceNameIPAddress)
+	mockProvider.EXPECT().SubmitAsyncJobWithContext(gomock.Any(), job)
 
-	_, err := handler.HandleCreate(1, podCopy)
+	_, err := handler.HandleCreate(context.TODO(), 1, podCopy)
 	assert.NoError(t, err)
 }
 
@@ -94,11 +100,11 @@ func TestWarmResourceHandler_PoolEmpty(t *testing.T) {
 	mockPool.EXPECT().AssignResource(uid).Return("", true, pool.ErrWarmPoolEmpty)
 	mockK8sWrapper.EXPECT().BroadcastEvent(podCopy, ReasonResourceAllocationFailed, gomock.Any(), v1.EventTypeWarning)
 	mockPool.EXPECT().ReconcilePool().Return(job)
-	mockProvider.EXPECT().SubmitAsyncJob(job)
+	mockProvider.EXPECT().SubmitAsyncJobWithContext(gomock.Any(), job)
 
 	delete(podCopy.Annotations, config.ResourceNameIPAddress)
 
-	rslt, err := handler.HandleCreate(1, podCopy)
+	rslt, err := handler.HandleCreate(context.TODO(), 1, podCopy)
 	assert.NoError(t, err)
 	assert.Equal(t, k8sctrl.Result{
 		Requeue:      true,
@@ -116,7 +122,7 @@ func TestWarmResourceHandler_HandleCreate_AlreadyAnnotated(t *testing.T) {
 
 	mockProvider.EXPECT().GetPool(nodeName).Return(mockPool, true)
 
-	_, err := handler.HandleCreate(1, pod)
+	_, err := handler.HandleCreate(context.TODO(), 1, pod)
 	assert.NoError(t, err)
 }
 
@@ -144,7 +150,7 @@ func TestWarmResourceHandler_HandleDelete_ReconcileAfter(t *testing.T) {
 	mockProvider.EXPECT().GetPool(nodeName).Return(mockPool, true)
 	mockPool.EXPECT().FreeResource(uid, ipAddress).Return(true, nil)
 	mockPool.EXPECT().ReconcilePool().Return(job)
-	mockProvider.EXPECT().SubmitAsyncJob(job)
+	mockProvider.EXPECT().SubmitAsyncJobWithContext(gomock.Any(), job)
 
 	_, err := handler.HandleDelete(pod)
 	assert.NoError(t, err)
@@ -31,7 +31,8 @@ import (
 )
 
 const (
-	NodeNameSpec = "nodeName"
+	NodeNameSpec  = "nodeName"
+	NamespaceSpec = "namespace"
 )
 
 var (
@@ -71,7 +72,10 @@ var (
 type PodClientAPIWrapper interface {
 	GetPod(namespace string, name string) (*v1.Pod, error)
 	ListPods(nodeName string) (*v1.PodList, error)
+	ListPodsInNamespace(namespace string) (*v1.PodList, error)
+	ListNamespaces() []string
 	AnnotatePod(podNamespace string, podName string, uid types.UID, key string, val string) error
+	AnnotatePodWithAnnotations(podNamespace string, podName string, uid types.UID, annotations map[string]string) error
 	GetPodFromAPIServer(ctx context.Context, namespace string, name string) (*v1.Pod, error)
 	GetRunningPodsOnNode(nodeName string) ([]v1.Pod, error)
 }
@@ -144,10 +148,37 @@ func (p *podClientAPIWrapper) ListPods(nodeName string) (*v1.PodList, error) {
 	return podList, nil
 }
 
+// ListPodsInNamespace lists the pods in the given namespace by querying the data store
+func (p *podClientAPIWrapper) ListPodsInNamespace(namespace string) (*v1.PodList, error) {
+	items, err := p.dataStore.ByIndex(NamespaceSpec, namespace)
+	if err != nil {
+		return nil, err
+	}
+	podList := &v1.PodList{}
+	for _, item := range items {
+		podList.Items = append(podList.Items, *item.(*v1.Pod))
+	}
+	return podList, nil
+}
+
+// ListNamespaces returns the namespaces that have at least one pod in the data store
+func (p *podClientAPIWrapper) ListNamespaces() []string {
+	return p.dataStore.ListIndexFuncValues(NamespaceSpec)
+}
+
 // AnnotatePod annotates the pod with the provided key and value
 func (p *podClientAPIWrapper) AnnotatePod(podNamespace string, podName string, uid types.UID,
 	key string, val string) error {
-	annotatePodRequestCallCount.WithLabelValues(key).Inc()
+	return p.AnnotatePodWithAnnotations(podNamespace, podName, uid, map[string]string{key: val})
+}
+
+// AnnotatePodWithAnnotations annotates the pod with all the provided annotations in a single patch, so either all
+// or none of the annotations are set on the pod
+func (p *podClientAPIWrapper) AnnotatePodWithAnnotations(podNamespace string, podName string, uid types.UID,
+	annotations map[string]string) error {
+	for key := range annotations {
+		annotatePodRequestCallCount.WithLabelValues(key).Inc()
+	}
 	ctx := context.Background()
 
 	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
@@ -164,13 +195,20 @@ func (p *podClientAPIWrapper) AnnotatePod(podNamespace string, podName string, u
 				"intended for Pod with UID %s", pod.UID, uid)
 		}
 		newPod := pod.DeepCopy()
-		newPod.Annotations[key] = val
+		if newPod.Annotations == nil {
+			newPod.Annotations = make(map[string]string, len(annotations))
+		}
+		for key, val := range annotations {
+			newPod.Annotations[key] = val
+		}
 
 		return p.client.Patch(ctx, newPod, client.MergeFrom(pod))
 	})
 
 	if err != nil {
-		annotatePodRequestErrCount.WithLabelValues(key).Inc()
+		for key := range annotations {
+			annotatePodRequestErrCount.WithLabelValues(key).Inc()
+		}
 	}
 
 	return err
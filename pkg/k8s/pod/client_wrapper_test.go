@@ -99,17 +99,13 @@ func getMockPodAPIWithClient() (PodClientAPIWrapper, client.Client) {
 }
 
 func getFakeDataStore() cache.Indexer {
-	indexer := map[string]cache.IndexFunc{}
-	indexer[NodeNameSpec] = func(obj interface{}) (strings []string, err error) {
-		return []string{obj.(*v1.Pod).Spec.NodeName}, nil
-	}
 	store := cache.NewIndexer(func(obj interface{}) (s string, err error) {
 		pod := obj.(*v1.Pod)
 		return types.NamespacedName{
 			Namespace: pod.Namespace,
 			Name:      pod.Name,
 		}.String(), nil
-	}, indexer)
+	}, Indexers())
 	store.Add(runningPod)
 	store.Add(completedPod)
 	store.Add(failedPod)
@@ -154,6 +150,22 @@ func TestPodAPI_ListPods(t *testing.T) {
 	assert.ElementsMatch(t, podList.Items, []v1.Pod{*runningPod, *completedPod, *failedPod})
 }
 
+// TestPodAPI_ListPodsInNamespace tests that the pods are listed by namespace and the namespaces of the pods are
+// returned
+func TestPodAPI_ListPodsInNamespace(t *testing.T) {
+	podAPI, _ := getMockPodAPIWithClient()
+
+	podList, err := podAPI.ListPodsInNamespace(podNamespace)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, podList.Items, []v1.Pod{*runningPod, *completedPod, *failedPod})
+
+	podList, err = podAPI.ListPodsInNamespace("other-namespace")
+	assert.NoError(t, err)
+	assert.Empty(t, podList.Items)
+
+	assert.Equal(t, []string{podNamespace}, podAPI.ListNamespaces())
+}
+
 func TestPodAPI_AnnotatePod_UID_Changed(t *testing.T) {
 	podAPI, _ := getMockPodAPIWithClient()
 
@@ -181,6 +193,31 @@ func TestPodAPI_AnnotatePod(t *testing.T) {
 	assert.Equal(t, newAnnotationValue, updatedPod.Annotations[newAnnotation])
 }
 
+// TestPodAPI_AnnotatePodWithAnnotations tests that all the annotations are set on the pod, and none of them are set if
+// the pod was re-created
+func TestPodAPI_AnnotatePodWithAnnotations(t *testing.T) {
+	podAPI, k8sClient := getMockPodAPIWithClient()
+	annotations := map[string]string{newAnnotation: newAnnotationValue, newAnnotation + "-2": newAnnotationValue}
+
+	err := podAPI.AnnotatePodWithAnnotations(podNamespace, podName, types.UID("00000000-0000-0000-0000-000000000001"),
+		annotations)
+	assert.Error(t, err)
+
+	updatedPod := &v1.Pod{}
+	err = k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: podNamespace, Name: podName}, updatedPod)
+	assert.NoError(t, err)
+	assert.NotContains(t, updatedPod.Annotations, newAnnotation)
+	assert.NotContains(t, updatedPod.Annotations, newAnnotation+"-2")
+
+	err = podAPI.AnnotatePodWithAnnotations(podNamespace, podName, podUid, annotations)
+	assert.NoError(t, err)
+
+	err = k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: podNamespace, Name: podName}, updatedPod)
+	assert.NoError(t, err)
+	assert.Equal(t, newAnnotationValue, updatedPod.Annotations[newAnnotation])
+	assert.Equal(t, newAnnotationValue, updatedPod.Annotations[newAnnotation+"-2"])
+}
+
 // TestPodAPI_AnnotatePod_PodNotExists tests that annotate pod fails if the pod doesn't exist
 func TestPodAPI_AnnotatePod_PodNotExists(t *testing.T) {
 	podAPI, _ := getMockPodAPIWithClient()
@@ -73,12 +73,15 @@ func (c *PodConverter) ResourceType() runtime.Object {
 	return c.K8sResourceType
 }
 
-// NodeNameIndexer returns indexer to index in the data store using node name
-func NodeNameIndexer() cache.Indexers {
+// Indexers returns the indexers to index in the data store using node name and namespace
+func Indexers() cache.Indexers {
 	indexer := map[string]cache.IndexFunc{}
 	indexer[NodeNameSpec] = func(obj interface{}) (strings []string, err error) {
 		return []string{obj.(*v1.Pod).Spec.NodeName}, nil
 	}
+	indexer[NamespaceSpec] = func(obj interface{}) (strings []string, err error) {
+		return []string{obj.(*v1.Pod).Namespace}, nil
+	}
 	return indexer
 }
 
@@ -99,8 +102,11 @@ func (c *PodConverter) StripDownPod(pod *v1.Pod) *v1.Pod {
 			Name:              pod.Name,
 			Namespace:         pod.Namespace,
 			UID:               pod.UID,
+			CreationTimestamp: pod.CreationTimestamp,
 			DeletionTimestamp: pod.DeletionTimestamp,
-			Annotations:       getVPCControllerAnnotations(pod.Annotations),
+			// The labels are matched against the pod selector of the security group policies
+			Labels:      pod.Labels,
+			Annotations: getVPCControllerAnnotations(pod.Annotations),
 		},
 		Spec: v1.PodSpec{
 			Containers:         getContainersWithVPCLimits(pod.Spec.Containers),
@@ -108,11 +114,24 @@ func (c *PodConverter) StripDownPod(pod *v1.Pod) *v1.Pod {
 			NodeName:           pod.Spec.NodeName,
 		},
 		Status: v1.PodStatus{
-			Phase: pod.Status.Phase,
+			Phase:      pod.Status.Phase,
+			Conditions: getPodScheduledCondition(pod.Status.Conditions),
 		},
 	}
 }
 
+// getPodScheduledCondition returns only the scheduled condition of the pod, which is used to measure the time the
+// pod waits for the resources
+func getPodScheduledCondition(conditions []v1.PodCondition) []v1.PodCondition {
+	for _, condition := range conditions {
+		if condition.Type == v1.PodScheduled {
+			return []v1.PodCondition{{Type: condition.Type, Status: condition.Status,
+				LastTransitionTime: condition.LastTransitionTime}}
+		}
+	}
+	return nil
+}
+
 // getVPCControllerAnnotations returns only the annotations that were marked by VPC
 // Resource controller
 func getVPCControllerAnnotations(annotations map[string]string) map[string]string {
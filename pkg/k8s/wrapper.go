@@ -83,6 +83,7 @@ type K8sWrapper interface {
 	CreateCNINode(node *v1.Node, clusterName string, nodeID string) error
 	ListCNINodes() ([]*rcv1alpha1.CNINode, error)
 	PatchCNINode(oldCNINode, newCNINode *rcv1alpha1.CNINode) error
+	PatchCNINodeStatus(oldCNINode, newCNINode *rcv1alpha1.CNINode) error
 	DeleteCNINode(cniNode *rcv1alpha1.CNINode) error
 }
 
@@ -284,3 +285,8 @@ func (k *k8sWrapper) ListCNINodes() ([]*rcv1alpha1.CNINode, error) {
 func (k *k8sWrapper) PatchCNINode(oldCNINode, newCNINode *rcv1alpha1.CNINode) error {
 	return k.cacheClient.Patch(k.context, newCNINode, client.MergeFromWithOptions(oldCNINode, client.MergeFromWithOptimisticLock{}))
 }
+
+// PatchCNINodeStatus patches the status subresource of the CNINode
+func (k *k8sWrapper) PatchCNINodeStatus(oldCNINode, newCNINode *rcv1alpha1.CNINode) error {
+	return k.cacheClient.Status().Patch(k.context, newCNINode, client.MergeFrom(oldCNINode))
+}
@@ -0,0 +1,172 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package lookup
+
+import (
+	"context"
+	"net/netip"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// Owner is the pod a resource was last assigned to
+type Owner struct {
+	// NodeName is the name of the node the resource was assigned on
+	NodeName string
+	// PodUID is the UID of the pod the resource was assigned to
+	PodUID string
+	// ReleasedAt is the time the pod released the resource, it's zero while the pod uses the resource
+	ReleasedAt time.Time
+}
+
+// Index is the reverse index of the IPv4 addresses, IPv6 addresses and ENI IDs assigned to the pods, to the pod they
+// were last assigned to. The entries are kept after the pods release the resources, so the owner of a resource can be
+// found while it's cooling down and until it's assigned to another pod or the retention expires. The methods of a nil
+// index don't record anything
+type Index struct {
+	// retention is the time the resources are kept in the index after they are released
+	retention time.Duration
+	lock      sync.RWMutex
+	owners    map[string]Owner
+}
+
+// NewIndex returns an empty index that keeps the released resources for the retention
+func NewIndex(retention time.Duration) *Index {
+	return &Index{retention: retention, owners: make(map[string]Owner)}
+}
+
+// Start evicts the resources released for longer than the retention from the index until the context is done
+func (i *Index) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, func(_ context.Context) {
+		i.evictReleased(time.Now())
+	}, i.retention)
+	return nil
+}
+
+// Assign records the resources are assigned to the pod on the node
+func (i *Index) Assign(nodeName string, podUID string, resourceIDs ...string) {
+	if i == nil {
+		return
+	}
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	for _, resourceID := range resourceIDs {
+		if resourceID != "" {
+			i.owners[NormalizeID(resourceID)] = Owner{NodeName: nodeName, PodUID: podUID}
+		}
+	}
+}
+
+// Release records the pod released the resources
+func (i *Index) Release(resourceIDs ...string) {
+	if i == nil {
+		return
+	}
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	now := time.Now()
+	for _, resourceID := range resourceIDs {
+		resourceID = NormalizeID(resourceID)
+		if owner, ok := i.owners[resourceID]; ok && owner.ReleasedAt.IsZero() {
+			owner.ReleasedAt = now
+			i.owners[resourceID] = owner
+		}
+	}
+}
+
+// Remove removes the resources that no longer exist on the node from the index
+func (i *Index) Remove(nodeName string, resourceIDs ...string) {
+	if i == nil {
+		return
+	}
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	for _, resourceID := range resourceIDs {
+		resourceID = NormalizeID(resourceID)
+		if owner, ok := i.owners[resourceID]; ok && owner.NodeName == nodeName {
+			delete(i.owners, resourceID)
+		}
+	}
+}
+
+// RemoveNode removes all the resources of the deleted node from the index
+func (i *Index) RemoveNode(nodeName string) {
+	if i == nil {
+		return
+	}
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	for resourceID, owner := range i.owners {
+		if owner.NodeName == nodeName {
+			delete(i.owners, resourceID)
+		}
+	}
+}
+
+// Get returns the pod the resource was last assigned to, the resources released for longer than the retention are not
+// returned
+func (i *Index) Get(resourceID string) (Owner, bool) {
+	if i == nil {
+		return Owner{}, false
+	}
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	owner, ok := i.owners[NormalizeID(resourceID)]
+	if !ok || i.isExpired(owner, time.Now()) {
+		return Owner{}, false
+	}
+	return owner, true
+}
+
+// evictReleased removes the resources released for longer than the retention from the index
+func (i *Index) evictReleased(now time.Time) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	for resourceID, owner := range i.owners {
+		if i.isExpired(owner, now) {
+			delete(i.owners, resourceID)
+		}
+	}
+}
+
+// isExpired returns true if the resource was released for longer than the retention
+func (i *Index) isExpired(owner Owner, now time.Time) bool {
+	return !owner.ReleasedAt.IsZero() && now.Sub(owner.ReleasedAt) > i.retention
+}
+
+// NormalizeID returns the ID of the resource in the index. The IP addresses with the mask of their subnet, like the
+// secondary IPv4 addresses, and the /32 or /128 addresses, like the IPv4 addresses of a prefix, are returned without
+// the mask so they can be found by their address. The prefixes are returned in their canonical form and the ENI IDs
+// are returned as is
+func NormalizeID(id string) string {
+	if addr, err := netip.ParseAddr(id); err == nil {
+		return addr.String()
+	}
+	prefix, err := netip.ParsePrefix(id)
+	if err != nil {
+		return id
+	}
+	if prefix.IsSingleIP() || prefix.Addr() != prefix.Masked().Addr() {
+		return prefix.Addr().String()
+	}
+	return prefix.String()
+}
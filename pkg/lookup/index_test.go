@@ -0,0 +1,141 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package lookup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	nodeName  = "node-name"
+	podUID    = "pod-uid"
+	eniID     = "eni-00000000000000001"
+	ipAddress = "192.168.1.1"
+)
+
+// TestIndex tests the owner of the resources is kept after the resources are released, until the resources are
+// assigned to another pod or removed from the node
+func TestIndex(t *testing.T) {
+	index := NewIndex(time.Hour)
+
+	index.Assign(nodeName, podUID, eniID, ipAddress, "")
+	owner, ok := index.Get(eniID)
+	assert.True(t, ok)
+	assert.Equal(t, Owner{NodeName: nodeName, PodUID: podUID}, owner)
+	_, ok = index.Get("")
+	assert.False(t, ok)
+
+	index.Release(eniID, ipAddress)
+	owner, ok = index.Get(ipAddress)
+	assert.True(t, ok)
+	assert.Equal(t, podUID, owner.PodUID)
+	assert.False(t, owner.ReleasedAt.IsZero())
+
+	index.Assign(nodeName, "new-pod-uid", ipAddress)
+	owner, _ = index.Get(ipAddress)
+	assert.Equal(t, Owner{NodeName: nodeName, PodUID: "new-pod-uid"}, owner)
+
+	// The resources are only removed if they are indexed on the node
+	index.Remove("other-node-name", eniID)
+	_, ok = index.Get(eniID)
+	assert.True(t, ok)
+	index.Remove(nodeName, eniID)
+	_, ok = index.Get(eniID)
+	assert.False(t, ok)
+}
+
+// TestIndex_RemoveNode tests all the resources of the deleted node are removed
+func TestIndex_RemoveNode(t *testing.T) {
+	index := NewIndex(time.Hour)
+
+	index.Assign(nodeName, podUID, eniID)
+	index.Assign("other-node-name", podUID, ipAddress)
+
+	index.RemoveNode(nodeName)
+	_, ok := index.Get(eniID)
+	assert.False(t, ok)
+	_, ok = index.Get(ipAddress)
+	assert.True(t, ok)
+}
+
+// TestIndex_Retention tests the released resources are not returned and are evicted after the retention, while the
+// resources in use are kept
+func TestIndex_Retention(t *testing.T) {
+	index := NewIndex(time.Minute)
+
+	index.Assign(nodeName, podUID, eniID, ipAddress)
+	index.Release(ipAddress)
+	owner := index.owners[ipAddress]
+	owner.ReleasedAt = time.Now().Add(-time.Minute * 2)
+	index.owners[ipAddress] = owner
+
+	_, ok := index.Get(ipAddress)
+	assert.False(t, ok)
+
+	index.evictReleased(time.Now())
+	assert.NotContains(t, index.owners, ipAddress)
+	_, ok = index.Get(eniID)
+	assert.True(t, ok)
+}
+
+// TestIndex_Nil tests the nil index doesn't record the resources
+func TestIndex_Nil(t *testing.T) {
+	var index *Index
+
+	index.Assign(nodeName, podUID, eniID)
+	index.Release(eniID)
+	index.Remove(nodeName, eniID)
+	index.RemoveNode(nodeName)
+	_, ok := index.Get(eniID)
+	assert.False(t, ok)
+}
+
+// TestNormalizeID tests the IP addresses with a mask are normalized to the address, while the prefixes and the ENI IDs
+// are kept
+func TestNormalizeID(t *testing.T) {
+	for id, expectedID := range map[string]string{
+		"192.168.1.1":       "192.168.1.1",
+		"192.168.1.1/32":    "192.168.1.1",
+		"192.168.1.17/19":   "192.168.1.17",
+		"192.168.1.16/28":   "192.168.1.16/28",
+		"2600:1f14::1/128":  "2600:1f14::1",
+		"2600:1f14:0::1/80": "2600:1f14::1",
+		"2600:1f14::/80":    "2600:1f14::/80",
+		"2600:1F14::1":      "2600:1f14::1",
+		eniID:               eniID,
+		"not-an-ip/28":      "not-an-ip/28",
+		"192.168.1.1/bad":   "192.168.1.1/bad",
+	} {
+		assert.Equal(t, expectedID, NormalizeID(id), "id %s", id)
+	}
+}
+
+// TestIndex_MaskedID tests the resources indexed with a mask are found by their address
+func TestIndex_MaskedID(t *testing.T) {
+	index := NewIndex(time.Hour)
+
+	index.Assign(nodeName, podUID, "192.168.1.17/19", "192.168.1.33/32")
+	owner, ok := index.Get("192.168.1.17")
+	assert.True(t, ok)
+	assert.Equal(t, podUID, owner.PodUID)
+	_, ok = index.Get("192.168.1.33")
+	assert.True(t, ok)
+
+	index.Release("192.168.1.17")
+	owner, _ = index.Get("192.168.1.17/19")
+	assert.False(t, owner.ReleasedAt.IsZero())
+}
@@ -14,6 +14,7 @@
 package manager
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -68,6 +69,7 @@ type Manager interface {
 	UpdateNode(nodeName string) error
 	DeleteNode(nodeName string) error
 	CheckNodeForLeakedENIs(nodeName string)
+	GetNodesBySubnet() map[string][]string
 	SkipHealthCheck() bool
 }
 
@@ -81,6 +83,8 @@ const (
 	Init   = AsyncOperation("Init")
 	Update = AsyncOperation("Update")
 	Delete = AsyncOperation("Delete")
+	// UpdateStatus periodically refreshes the CNINode status with the node's VPC resource inventory
+	UpdateStatus = AsyncOperation("UpdateStatus")
 )
 
 // NodeUpdateStatus represents the status of the Node on Update operation.
@@ -121,6 +125,7 @@ func NewNodeManager(logger logr.Logger, resourceManager resource.ResourceManager
 		map[string]healthz.Checker{"health-node-manager": manager.check()},
 	)
 
+	// The node jobs are not traced
 	return manager, worker.StartWorkerPool(manager.performAsyncOperation)
 }
 
@@ -164,6 +169,24 @@ func (m *manager) GetNode(nodeName string) (node node.Node, found bool) {
 	return
 }
 
+// GetNodesBySubnet returns the names of the ready managed nodes grouped by the subnet their pods get their IP
+// addresses from
+func (m *manager) GetNodesBySubnet() map[string][]string {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	nodesBySubnet := make(map[string][]string)
+	for nodeName, cachedNode := range m.dataStore {
+		if !cachedNode.IsManaged() || !cachedNode.IsReady() {
+			continue
+		}
+		if subnetID := cachedNode.GetSubnetID(); subnetID != "" {
+			nodesBySubnet[subnetID] = append(nodesBySubnet[subnetID], nodeName)
+		}
+	}
+	return nodesBySubnet
+}
+
 // AddNode adds the managed and un-managed nodes to the in memory data store, the
 // user of node can verify if the node is managed before performing any operations
 func (m *manager) AddNode(nodeName string) error {
@@ -420,7 +443,7 @@ func (m *manager) GetEniConfigName(node *v1.Node) (string, error) {
 }
 
 // performAsyncOperation performs the operation on a node without taking the node manager lock
-func (m *manager) performAsyncOperation(job interface{}) (ctrl.Result, error) {
+func (m *manager) performAsyncOperation(ctx context.Context, job interface{}) (ctrl.Result, error) {
 	asyncJob, ok := job.(AsyncOperationJob)
 	if !ok {
 		m.Log.Error(fmt.Errorf("wrong job type submitted"), "not re-queuing")
@@ -433,7 +456,7 @@ func (m *manager) performAsyncOperation(job interface{}) (ctrl.Result, error) {
 	switch asyncJob.op {
 	case Init:
 		utils.SendNodeEventWithNodeName(m.wrapper.K8sAPI, asyncJob.nodeName, utils.VersionNotice, fmt.Sprintf("The node is managed by VPC resource controller version %s", m.controllerVersion), v1.EventTypeNormal, m.Log)
-		err = asyncJob.node.InitResources(m.resourceManager)
+		err = asyncJob.node.InitResources(ctx, m.resourceManager)
 		if err != nil {
 			if pauseHealthCheckOnError(err) && !m.SkipHealthCheck() {
 				m.setStopHealthCheck()
@@ -441,6 +464,7 @@ func (m *manager) performAsyncOperation(job interface{}) (ctrl.Result, error) {
 			}
 			log.Error(err, "removing the node from cache as it failed to initialize")
 			m.removeNodeSafe(asyncJob.nodeName)
+			m.updateCNINodeStatus(asyncJob.nodeName, err, true)
 			// if initializing node failed, we want to make this visible although the manager will retry
 			// the trunk label will stay as false until retry succeed
 
@@ -448,11 +472,28 @@ func (m *manager) performAsyncOperation(job interface{}) (ctrl.Result, error) {
 			return ctrl.Result{}, nil
 		}
 
+		// Start refreshing the CNINode status of the initialized node
+		m.worker.SubmitJob(AsyncOperationJob{
+			op:       UpdateStatus,
+			node:     asyncJob.node,
+			nodeName: asyncJob.nodeName,
+		})
+
 		// If there's no error, we need to update the node so the capacity is advertised
 		asyncJob.op = Update
-		return m.performAsyncOperation(asyncJob)
+		return m.performAsyncOperation(ctx, asyncJob)
 	case Update:
-		err = asyncJob.node.UpdateResources(m.resourceManager)
+		err = asyncJob.node.UpdateResources(ctx, m.resourceManager)
+		m.updateCNINodeStatus(asyncJob.nodeName, err, true)
+	case UpdateStatus:
+		// Stop refreshing the status once the node is deleted, un-managed or re-initialized
+		cachedNode, found := m.GetNode(asyncJob.nodeName)
+		if !found || cachedNode != asyncJob.node || !cachedNode.IsManaged() {
+			log.V(1).Info("node is not managed anymore, forgetting the status update job")
+			return ctrl.Result{}, nil
+		}
+		m.updateCNINodeStatus(asyncJob.nodeName, nil, false)
+		return ctrl.Result{Requeue: true, RequeueAfter: config.CNINodeStatusUpdateInterval}, nil
 	case Delete:
 		err = asyncJob.node.DeleteResources(m.resourceManager)
 	default:
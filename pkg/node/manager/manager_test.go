@@ -14,6 +14,7 @@
 package manager
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"testing"
@@ -645,24 +646,27 @@ func Test_performAsyncOperation(t *testing.T) {
 	mock.MockK8sAPI.EXPECT().AddLabelToManageNode(v1Node, config.HasTrunkAttachedLabel, config.BooleanTrue).Return(true, nil).AnyTimes()
 	mock.MockK8sAPI.EXPECT().GetNode(nodeName).Return(v1Node, nil)
 	mock.MockK8sAPI.EXPECT().BroadcastEvent(v1Node, utils.VersionNotice, fmt.Sprintf("The node is managed by VPC resource controller version %s", mock.Manager.controllerVersion), v1.EventTypeNormal).Times(1)
-	mock.MockNode.EXPECT().InitResources(mock.MockResourceManager).Return(nil)
-	mock.MockNode.EXPECT().UpdateResources(mock.MockResourceManager).Return(nil)
-	_, err := mock.Manager.performAsyncOperation(job)
+	mock.MockNode.EXPECT().InitResources(gomock.Any(), mock.MockResourceManager).Return(nil)
+	mock.MockWorker.EXPECT().SubmitJob(AsyncOperationJob{op: UpdateStatus, node: mock.MockNode, nodeName: nodeName})
+	mock.MockNode.EXPECT().UpdateResources(gomock.Any(), mock.MockResourceManager).Return(nil)
+	mock.MockK8sAPI.EXPECT().GetCNINode(types.NamespacedName{Name: nodeName}).Return(nil, mockError)
+	_, err := mock.Manager.performAsyncOperation(context.TODO(), job)
 	assert.Contains(t, mock.Manager.dataStore, nodeName)
 	assert.NoError(t, err)
 
 	job.op = Update
-	mock.MockNode.EXPECT().UpdateResources(mock.MockResourceManager).Return(nil)
-	_, err = mock.Manager.performAsyncOperation(job)
+	mock.MockNode.EXPECT().UpdateResources(gomock.Any(), mock.MockResourceManager).Return(nil)
+	mock.MockK8sAPI.EXPECT().GetCNINode(types.NamespacedName{Name: nodeName}).Return(nil, mockError)
+	_, err = mock.Manager.performAsyncOperation(context.TODO(), job)
 	assert.NoError(t, err)
 
 	job.op = Delete
 	mock.MockNode.EXPECT().DeleteResources(mock.MockResourceManager).Return(nil)
-	_, err = mock.Manager.performAsyncOperation(job)
+	_, err = mock.Manager.performAsyncOperation(context.TODO(), job)
 	assert.NoError(t, err)
 
 	job.op = ""
-	_, err = mock.Manager.performAsyncOperation(job)
+	_, err = mock.Manager.performAsyncOperation(context.TODO(), job)
 	assert.NoError(t, err)
 }
 
@@ -678,11 +682,12 @@ func Test_performAsyncOperation_fail(t *testing.T) {
 		op:       Init,
 	}
 
-	mock.MockNode.EXPECT().InitResources(mock.MockResourceManager).Return(&node.ErrInitResources{})
+	mock.MockNode.EXPECT().InitResources(gomock.Any(), mock.MockResourceManager).Return(&node.ErrInitResources{})
 	mock.MockK8sAPI.EXPECT().GetNode(nodeName).Return(v1Node, nil)
+	mock.MockK8sAPI.EXPECT().GetCNINode(types.NamespacedName{Name: nodeName}).Return(nil, mockError)
 	mock.MockK8sAPI.EXPECT().BroadcastEvent(v1Node, utils.VersionNotice, fmt.Sprintf("The node is managed by VPC resource controller version %s", mock.Manager.controllerVersion), v1.EventTypeNormal).Times(1)
 
-	_, err := mock.Manager.performAsyncOperation(job)
+	_, err := mock.Manager.performAsyncOperation(context.TODO(), job)
 	assert.NotContains(t, mock.Manager.dataStore, nodeName) // It should be cleared from cache
 	assert.NoError(t, err)
 }
@@ -699,20 +704,21 @@ func Test_performAsyncOperation_fail_pausingHealthCheck(t *testing.T) {
 		op:       Init,
 	}
 
-	mock.MockNode.EXPECT().InitResources(mock.MockResourceManager).Return(&node.ErrInitResources{
+	mock.MockNode.EXPECT().InitResources(gomock.Any(), mock.MockResourceManager).Return(&node.ErrInitResources{
 		Err: errors.New("RequestLimitExceeded: Request limit exceeded.\n\tstatus code: 503, request id: 123-123-123-123-123"),
 	}).Times(2)
 	mock.MockK8sAPI.EXPECT().GetNode(nodeName).Return(v1Node, nil).Times(2)
+	mock.MockK8sAPI.EXPECT().GetCNINode(types.NamespacedName{Name: nodeName}).Return(nil, mockError).Times(2)
 	mock.MockK8sAPI.EXPECT().BroadcastEvent(v1Node, utils.VersionNotice, fmt.Sprintf("The node is managed by VPC resource controller version %s", mock.Manager.controllerVersion), v1.EventTypeNormal).Times(2)
 
-	_, err := mock.Manager.performAsyncOperation(job)
+	_, err := mock.Manager.performAsyncOperation(context.TODO(), job)
 	time.Sleep(time.Millisecond * 100)
 	assert.True(t, mock.Manager.SkipHealthCheck())
 	assert.NotContains(t, mock.Manager.dataStore, nodeName) // It should be cleared from cache
 	assert.NoError(t, err)
 
 	time.Sleep(time.Second * 2)
-	_, err = mock.Manager.performAsyncOperation(job)
+	_, err = mock.Manager.performAsyncOperation(context.TODO(), job)
 	assert.NoError(t, err)
 	time.Sleep(time.Millisecond * 100)
 	assert.True(t, mock.Manager.SkipHealthCheck())
@@ -874,6 +880,30 @@ func Test_Node_HasInstance(t *testing.T) {
 	assert.True(t, unManagedNode.HasInstance(), "unmanaged node should have instance")
 }
 
+// Test_GetNodesBySubnet tests only the ready managed nodes are grouped by their subnet
+func Test_GetNodesBySubnet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	readyNode1, readyNode2, notReadyNode := mock_node.NewMockNode(ctrl), mock_node.NewMockNode(ctrl),
+		mock_node.NewMockNode(ctrl)
+	mock := NewMock(ctrl, map[string]node.Node{
+		"node-1": readyNode1, "node-2": readyNode2, "node-3": notReadyNode, "node-4": unManagedNode,
+	})
+
+	for _, readyNode := range []*mock_node.MockNode{readyNode1, readyNode2} {
+		readyNode.EXPECT().IsManaged().Return(true)
+		readyNode.EXPECT().IsReady().Return(true)
+		readyNode.EXPECT().GetSubnetID().Return("subnet-1")
+	}
+	notReadyNode.EXPECT().IsManaged().Return(true)
+	notReadyNode.EXPECT().IsReady().Return(false)
+
+	nodesBySubnet := mock.Manager.GetNodesBySubnet()
+	assert.Len(t, nodesBySubnet, 1)
+	assert.ElementsMatch(t, []string{"node-1", "node-2"}, nodesBySubnet["subnet-1"])
+}
+
 func Test_GetEniConfigName(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
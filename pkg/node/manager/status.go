@@ -0,0 +1,116 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package manager
+
+import (
+	"github.com/aws/amazon-vpc-resource-controller-k8s/apis/vpcresources/v1alpha1"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/pool"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider/branch/trunk"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// updateCNINodeStatus writes the node's VPC resource inventory held by the resource providers to the CNINode status.
+// The last reconcile error is only overwritten if updateReconcileError is set, so the periodic refresh retains it
+func (m *manager) updateCNINodeStatus(nodeName string, reconcileErr error, updateReconcileError bool) {
+	cniNode, err := m.wrapper.K8sAPI.GetCNINode(types.NamespacedName{Name: nodeName})
+	if err != nil {
+		m.Log.V(1).Info("failed to get CNINode, skipping status update", "node name", nodeName, "error", err.Error())
+		return
+	}
+
+	status := getCNINodeStatus(nodeName, m.resourceManager.GetResourceProviders())
+	status.LastReconcileError = cniNode.Status.LastReconcileError
+	if updateReconcileError {
+		status.LastReconcileError = ""
+		if reconcileErr != nil {
+			status.LastReconcileError = reconcileErr.Error()
+		}
+	}
+
+	status.Checkpoint = m.getCNINodeCheckpoint(nodeName, cniNode.Status.Checkpoint)
+
+	// Avoid patching the status when nothing changed
+	if equality.Semantic.DeepEqual(status, cniNode.Status) {
+		return
+	}
+
+	newCNINode := cniNode.DeepCopy()
+	newCNINode.Status = status
+	if err := m.wrapper.K8sAPI.PatchCNINodeStatus(cniNode, newCNINode); err != nil {
+		m.Log.Error(err, "failed to update CNINode status", "node name", nodeName)
+	}
+}
+
+// getCNINodeCheckpoint returns the checkpoint of the node's state held by the resource providers, which a new leader
+// uses to initialize the node without describing its resources in EC2. The previous checkpoint is retained if the
+// node isn't initialized, and its time is retained if the state didn't change
+func (m *manager) getCNINodeCheckpoint(nodeName string,
+	previous *v1alpha1.CNINodeCheckpoint) *v1alpha1.CNINodeCheckpoint {
+	cachedNode, found := m.GetNode(nodeName)
+	if !found || !cachedNode.IsManaged() {
+		return previous
+	}
+
+	checkpoint := &v1alpha1.CNINodeCheckpoint{InstanceID: cachedNode.GetNodeInstanceID()}
+	for _, resourceProvider := range m.resourceManager.GetResourceProviders() {
+		if checkpointProvider, ok := resourceProvider.(provider.CheckpointProvider); ok {
+			checkpointProvider.CheckpointNode(nodeName, checkpoint)
+		}
+	}
+	if checkpoint.Trunk == nil && len(checkpoint.IPv4Pools) == 0 {
+		return previous
+	}
+
+	if previous != nil {
+		checkpoint.Time = previous.Time
+		if equality.Semantic.DeepEqual(checkpoint, previous) {
+			return previous
+		}
+	}
+	checkpoint.Time = metav1.Now().Rfc3339Copy()
+	return checkpoint
+}
+
+// getCNINodeStatus aggregates the introspect responses of all resource providers for the node into a CNINode status
+func getCNINodeStatus(nodeName string, resourceProviders map[string]provider.ResourceProvider) v1alpha1.CNINodeStatus {
+	status := v1alpha1.CNINodeStatus{}
+	for resourceName, resourceProvider := range resourceProviders {
+		switch response := resourceProvider.IntrospectNode(nodeName).(type) {
+		case trunk.IntrospectResponse:
+			status.TrunkENIID = response.TrunkENIID
+			for _, branchENIs := range response.PodToBranchENI {
+				status.BranchENICount += len(branchENIs)
+			}
+			// Each branch ENI holds a VLAN ID until it's deleted from the delete queue
+			status.UsedVlanIDs = status.BranchENICount + len(response.DeleteQueue)
+		case pool.IntrospectResponse:
+			// Only the IPv4 address pools are reported, the secondary IP and prefix pools of a node are summed
+			// as one of them is drained when the mode is toggled
+			if resourceName == config.ResourceNameIPv6Address {
+				continue
+			}
+			status.UsedIPCount += len(response.UsedResources)
+			for _, warmResources := range response.WarmResources {
+				status.WarmIPCount += len(warmResources)
+			}
+			status.CoolingIPCount += len(response.CoolingResources)
+		}
+	}
+	return status
+}
@@ -0,0 +1,170 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"testing"
+
+	rcV1alpha1 "github.com/aws/amazon-vpc-resource-controller-k8s/apis/vpcresources/v1alpha1"
+	mock_provider "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/provider"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/node"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/pool"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider/branch/trunk"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var (
+	trunkIntrospectResponse = trunk.IntrospectResponse{
+		TrunkENIID: "eni-trunk",
+		PodToBranchENI: map[string][]trunk.ENIDetails{
+			"pod-1": {{ID: "eni-1", VlanID: 1}},
+			"pod-2": {{ID: "eni-2", VlanID: 2}},
+		},
+		DeleteQueue: []trunk.ENIDetails{{ID: "eni-3", VlanID: 3}},
+	}
+	poolIntrospectResponse = pool.IntrospectResponse{
+		UsedResources:    map[string]pool.Resource{"pod-3": {GroupID: "192.168.1.1", ResourceID: "192.168.1.1"}},
+		WarmResources:    map[string][]pool.Resource{"192.168.1.2": {{GroupID: "192.168.1.2", ResourceID: "192.168.1.2"}}},
+		CoolingResources: []pool.CoolDownResource{{Resource: pool.Resource{GroupID: "192.168.1.3", ResourceID: "192.168.1.3"}}},
+	}
+	expectedStatus = rcV1alpha1.CNINodeStatus{
+		TrunkENIID:     "eni-trunk",
+		BranchENICount: 2,
+		UsedVlanIDs:    3,
+		WarmIPCount:    1,
+		UsedIPCount:    1,
+		CoolingIPCount: 1,
+	}
+)
+
+func getMockProviders(ctrl *gomock.Controller) map[string]provider.ResourceProvider {
+	branchProvider := mock_provider.NewMockResourceProvider(ctrl)
+	branchProvider.EXPECT().IntrospectNode(nodeName).Return(trunkIntrospectResponse).AnyTimes()
+	ipv4Provider := mock_provider.NewMockResourceProvider(ctrl)
+	ipv4Provider.EXPECT().IntrospectNode(nodeName).Return(poolIntrospectResponse).AnyTimes()
+	ipv6Provider := mock_provider.NewMockResourceProvider(ctrl)
+	ipv6Provider.EXPECT().IntrospectNode(nodeName).Return(poolIntrospectResponse).AnyTimes()
+
+	return map[string]provider.ResourceProvider{
+		config.ResourceNamePodENI:      branchProvider,
+		config.ResourceNameIPAddress:   ipv4Provider,
+		config.ResourceNameIPv6Address: ipv6Provider,
+	}
+}
+
+// Test_getCNINodeStatus tests the status is aggregated from the trunk and IPv4 pool introspect responses
+func Test_getCNINodeStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	assert.Equal(t, expectedStatus, getCNINodeStatus(nodeName, getMockProviders(ctrl)))
+}
+
+// Test_updateCNINodeStatus tests the status and the reconcile error are patched and no patch is sent when the
+// status didn't change
+func Test_updateCNINodeStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := NewMock(ctrl, map[string]node.Node{})
+	mock.MockResourceManager.EXPECT().GetResourceProviders().Return(getMockProviders(ctrl)).AnyTimes()
+
+	cniNode := &rcV1alpha1.CNINode{ObjectMeta: metav1.ObjectMeta{Name: nodeName}}
+	updatedCNINode := cniNode.DeepCopy()
+	updatedCNINode.Status = expectedStatus
+	updatedCNINode.Status.LastReconcileError = mockError.Error()
+
+	mock.MockK8sAPI.EXPECT().GetCNINode(types.NamespacedName{Name: nodeName}).Return(cniNode, nil)
+	mock.MockK8sAPI.EXPECT().PatchCNINodeStatus(cniNode, updatedCNINode).Return(nil)
+	mock.Manager.updateCNINodeStatus(nodeName, mockError, true)
+
+	// Periodic refresh retains the reconcile error and skips the patch as nothing changed
+	mock.MockK8sAPI.EXPECT().GetCNINode(types.NamespacedName{Name: nodeName}).Return(updatedCNINode, nil)
+	mock.Manager.updateCNINodeStatus(nodeName, nil, false)
+}
+
+// Test_performAsyncOperation_UpdateStatus tests the status update job is requeued while the node is managed and
+// dropped once the node is removed
+func Test_performAsyncOperation_UpdateStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := NewMock(ctrl, map[string]node.Node{nodeName: managedNode})
+	mock.MockResourceManager.EXPECT().GetResourceProviders().Return(getMockProviders(ctrl)).AnyTimes()
+
+	job := AsyncOperationJob{op: UpdateStatus, node: managedNode, nodeName: nodeName}
+
+	cniNode := &rcV1alpha1.CNINode{ObjectMeta: metav1.ObjectMeta{Name: nodeName}, Status: expectedStatus}
+	mock.MockK8sAPI.EXPECT().GetCNINode(types.NamespacedName{Name: nodeName}).Return(cniNode, nil)
+	result, err := mock.Manager.performAsyncOperation(context.TODO(), job)
+	assert.NoError(t, err)
+	assert.True(t, result.Requeue)
+	assert.Equal(t, config.CNINodeStatusUpdateInterval, result.RequeueAfter)
+
+	delete(mock.Manager.dataStore, nodeName)
+	result, err = mock.Manager.performAsyncOperation(context.TODO(), job)
+	assert.NoError(t, err)
+	assert.False(t, result.Requeue)
+}
+
+// checkpointResourceProvider is a mock resource provider that adds the trunk to the checkpoint
+type checkpointResourceProvider struct {
+	*mock_provider.MockResourceProvider
+	trunk *rcV1alpha1.TrunkCheckpoint
+}
+
+func (p *checkpointResourceProvider) CheckpointNode(_ string, checkpoint *rcV1alpha1.CNINodeCheckpoint) {
+	checkpoint.Trunk = p.trunk
+}
+
+// Test_getCNINodeCheckpoint tests the checkpoint is built from the resource providers of the managed node, and the
+// previous checkpoint is retained if the node isn't managed or its state didn't change
+func Test_getCNINodeCheckpoint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := NewMock(ctrl, map[string]node.Node{})
+	branchProvider := &checkpointResourceProvider{
+		MockResourceProvider: mock_provider.NewMockResourceProvider(ctrl),
+		trunk:                &rcV1alpha1.TrunkCheckpoint{TrunkENIID: "eni-trunk"},
+	}
+	mock.MockResourceManager.EXPECT().GetResourceProviders().Return(map[string]provider.ResourceProvider{
+		config.ResourceNamePodENI:    branchProvider,
+		config.ResourceNameIPAddress: mock_provider.NewMockResourceProvider(ctrl),
+	}).AnyTimes()
+
+	previous := &rcV1alpha1.CNINodeCheckpoint{Time: metav1.Unix(1, 0), InstanceID: instanceID}
+	assert.Same(t, previous, mock.Manager.getCNINodeCheckpoint(nodeName, previous))
+
+	mock.Manager.dataStore[nodeName] = managedNode
+	checkpoint := mock.Manager.getCNINodeCheckpoint(nodeName, previous)
+	assert.Equal(t, instanceID, checkpoint.InstanceID)
+	assert.Equal(t, branchProvider.trunk, checkpoint.Trunk)
+	assert.True(t, checkpoint.Time.After(previous.Time.Time))
+
+	// The state didn't change
+	unchanged := checkpoint.DeepCopy()
+	assert.Same(t, unchanged, mock.Manager.getCNINodeCheckpoint(nodeName, unchanged))
+
+	// The node has no state to checkpoint
+	branchProvider.trunk = nil
+	assert.Same(t, previous, mock.Manager.getCNINodeCheckpoint(nodeName, previous))
+}
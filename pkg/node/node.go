@@ -14,6 +14,7 @@
 package node
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sync"
@@ -69,9 +70,9 @@ func (e *ErrInitResources) Error() string {
 }
 
 type Node interface {
-	InitResources(resourceManager resource.ResourceManager) error
+	InitResources(ctx context.Context, resourceManager resource.ResourceManager) error
 	DeleteResources(resourceManager resource.ResourceManager) error
-	UpdateResources(resourceManager resource.ResourceManager) error
+	UpdateResources(ctx context.Context, resourceManager resource.ResourceManager) error
 
 	UpdateCustomNetworkingSpecs(subnetID string, securityGroup []string)
 	IsReady() bool
@@ -79,6 +80,7 @@ type Node interface {
 	IsNitroInstance() bool
 
 	GetNodeInstanceID() string
+	GetSubnetID() string
 	HasInstance() bool
 
 	GetNextReconciliationTime() time.Time
@@ -114,7 +116,7 @@ func NewUnManagedNode(log logr.Logger, nodeName, instanceID, os string) Node {
 }
 
 // UpdateNode refreshes the capacity if it's reset to 0
-func (n *node) UpdateResources(resourceManager resource.ResourceManager) error {
+func (n *node) UpdateResources(ctx context.Context, resourceManager resource.ResourceManager) error {
 	n.lock.Lock()
 	defer n.lock.Unlock()
 
@@ -129,7 +131,7 @@ func (n *node) UpdateResources(resourceManager resource.ResourceManager) error {
 	var errUpdates []error
 	for _, resourceProvider := range resourceManager.GetResourceProviders() {
 		if resourceProvider.IsInstanceSupported(n.instance) {
-			err := resourceProvider.UpdateResourceCapacity(n.instance)
+			err := resourceProvider.UpdateResourceCapacity(ctx, n.instance)
 			if err != nil {
 				n.log.Error(err, "failed to initialize resource capacity")
 				errUpdates = append(errUpdates, err)
@@ -140,7 +142,7 @@ func (n *node) UpdateResources(resourceManager resource.ResourceManager) error {
 		return fmt.Errorf("failed to update one or more resources %v", errUpdates)
 	}
 
-	err := n.instance.UpdateCurrentSubnetAndCidrBlock(n.ec2API)
+	err := n.instance.UpdateCurrentSubnetAndCidrBlock(ctx, n.ec2API)
 	if err != nil {
 		n.log.Error(err, "failed to update cidr block", "instance", n.instance.Name())
 	}
@@ -149,10 +151,10 @@ func (n *node) UpdateResources(resourceManager resource.ResourceManager) error {
 }
 
 // InitResources initializes the resource pool and provider of all supported resources
-func (n *node) InitResources(resourceManager resource.ResourceManager) error {
+func (n *node) InitResources(ctx context.Context, resourceManager resource.ResourceManager) error {
 	n.lock.Lock()
 	defer n.lock.Unlock()
-	err := n.instance.LoadDetails(n.ec2API)
+	err := n.instance.LoadDetails(ctx, n.ec2API)
 	if err != nil {
 		if errors.Is(err, utils.ErrNotFound) {
 			// Send a node event for users' visibility
@@ -170,7 +172,7 @@ func (n *node) InitResources(resourceManager resource.ResourceManager) error {
 	for _, resourceProvider := range resourceManager.GetResourceProviders() {
 		// Check if the instance is supported and then initialize the provider
 		if resourceProvider.IsInstanceSupported(n.instance) {
-			errInit = resourceProvider.InitResource(n.instance)
+			errInit = resourceProvider.InitResource(ctx, n.instance)
 			if errInit != nil {
 				break
 			}
@@ -248,6 +250,15 @@ func (n *node) GetNodeInstanceID() string {
 	return n.instance.InstanceID()
 }
 
+// GetSubnetID returns the subnet the node's pods get their IP addresses from, it's either the instance subnet or the
+// custom networking subnet
+func (n *node) GetSubnetID() string {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	return n.instance.SubnetID()
+}
+
 func (n *node) HasInstance() bool {
 	n.lock.RLock()
 	defer n.lock.RUnlock()
@@ -14,6 +14,7 @@
 package node
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"testing"
@@ -114,13 +115,13 @@ func TestNode_InitResources(t *testing.T) {
 
 	mock := NewMock(ctrl, 1)
 
-	mock.MockInstance.EXPECT().LoadDetails(mock.MockEC2API).Return(nil)
+	mock.MockInstance.EXPECT().LoadDetails(gomock.Any(), mock.MockEC2API).Return(nil)
 	mock.MockResourceManager.EXPECT().GetResourceProviders().Return(mock.ResourceProvider)
 
 	mock.MockProviders["0"].EXPECT().IsInstanceSupported(mock.MockInstance).Return(true)
-	mock.MockProviders["0"].EXPECT().InitResource(mock.MockInstance).Return(nil)
+	mock.MockProviders["0"].EXPECT().InitResource(gomock.Any(), mock.MockInstance).Return(nil)
 
-	err := mock.NodeWithMock.InitResources(mock.MockResourceManager)
+	err := mock.NodeWithMock.InitResources(context.TODO(), mock.MockResourceManager)
 	assert.NoError(t, err)
 	assert.True(t, mock.NodeWithMock.IsReady())
 }
@@ -131,12 +132,12 @@ func TestNode_InitResources_InstanceNotTrunkSupported(t *testing.T) {
 
 	mock := NewMock(ctrl, 1)
 
-	mock.MockInstance.EXPECT().LoadDetails(mock.MockEC2API).Return(nil)
+	mock.MockInstance.EXPECT().LoadDetails(gomock.Any(), mock.MockEC2API).Return(nil)
 	mock.MockResourceManager.EXPECT().GetResourceProviders().Return(mock.ResourceProvider)
 
 	mock.MockProviders["0"].EXPECT().IsInstanceSupported(mock.MockInstance).Return(false)
 
-	err := mock.NodeWithMock.InitResources(mock.MockResourceManager)
+	err := mock.NodeWithMock.InitResources(context.TODO(), mock.MockResourceManager)
 	assert.NoError(t, err)
 	assert.True(t, mock.NodeWithMock.IsReady())
 }
@@ -159,10 +160,10 @@ func TestNode_InitResources_InstanceNotListed(t *testing.T) {
 	mock.MockInstance.EXPECT().Name().Return(nodeName).Times(1)
 	mock.MockK8sAPI.EXPECT().GetNode(nodeName).Return(node, nil).Times(1)
 	mock.MockK8sAPI.EXPECT().BroadcastEvent(node, "Unsupported", msg, v1.EventTypeWarning).Times(1)
-	mock.MockInstance.EXPECT().LoadDetails(mock.MockEC2API).Return(fmt.Errorf("unsupported instance type, couldn't find ENI Limit for instance %s, error: %w", testInstanceType, utils.ErrNotFound))
+	mock.MockInstance.EXPECT().LoadDetails(gomock.Any(), mock.MockEC2API).Return(fmt.Errorf("unsupported instance type, couldn't find ENI Limit for instance %s, error: %w", testInstanceType, utils.ErrNotFound))
 
 	mock.NodeWithMock.k8sAPI = mock.MockK8sAPI
-	err := mock.NodeWithMock.InitResources(mock.MockResourceManager)
+	err := mock.NodeWithMock.InitResources(context.TODO(), mock.MockResourceManager)
 	assert.Error(t, err)
 	assert.False(t, mock.NodeWithMock.IsReady())
 }
@@ -174,9 +175,9 @@ func TestNode_InitResources_LoadInstanceDetails_Error(t *testing.T) {
 
 	mock := NewMock(ctrl, 1)
 
-	mock.MockInstance.EXPECT().LoadDetails(mock.MockEC2API).Return(mockError)
+	mock.MockInstance.EXPECT().LoadDetails(gomock.Any(), mock.MockEC2API).Return(mockError)
 
-	err := mock.NodeWithMock.InitResources(mock.MockResourceManager)
+	err := mock.NodeWithMock.InitResources(context.TODO(), mock.MockResourceManager)
 	assert.Error(t, &ErrInitResources{Err: mockError}, err)
 }
 
@@ -187,20 +188,20 @@ func TestNode_InitResources_SecondProviderInitFails(t *testing.T) {
 
 	mock := NewMock(ctrl, 2)
 
-	mock.MockInstance.EXPECT().LoadDetails(mock.MockEC2API).Return(nil)
+	mock.MockInstance.EXPECT().LoadDetails(gomock.Any(), mock.MockEC2API).Return(nil)
 	mock.MockResourceManager.EXPECT().GetResourceProviders().Return(mock.ResourceProvider)
 
 	// Second provider throws an error
 	mock.MockProviders["0"].EXPECT().IsInstanceSupported(mock.MockInstance).Return(true).AnyTimes()
-	mock.MockProviders["0"].EXPECT().InitResource(mock.MockInstance).Return(nil).AnyTimes()
+	mock.MockProviders["0"].EXPECT().InitResource(gomock.Any(), mock.MockInstance).Return(nil).AnyTimes()
 
 	mock.MockProviders["1"].EXPECT().IsInstanceSupported(mock.MockInstance).Return(true).AnyTimes()
-	mock.MockProviders["1"].EXPECT().InitResource(mock.MockInstance).Return(mockError).AnyTimes()
+	mock.MockProviders["1"].EXPECT().InitResource(gomock.Any(), mock.MockInstance).Return(mockError).AnyTimes()
 
 	// Expect first provider to be de initialized
 	mock.MockProviders["0"].EXPECT().DeInitResource(mock.MockInstance).Return(nil).AnyTimes()
 
-	err := mock.NodeWithMock.InitResources(mock.MockResourceManager)
+	err := mock.NodeWithMock.InitResources(context.TODO(), mock.MockResourceManager)
 	assert.NotNil(t, err)
 }
 
@@ -253,16 +254,16 @@ func TestNode_UpdateResources(t *testing.T) {
 	mock := NewMock(ctrl, 2)
 	mock.NodeWithMock.ready = true
 
-	mock.MockInstance.EXPECT().UpdateCurrentSubnetAndCidrBlock(mock.MockEC2API).Return(nil)
+	mock.MockInstance.EXPECT().UpdateCurrentSubnetAndCidrBlock(gomock.Any(), mock.MockEC2API).Return(nil)
 
 	mock.MockResourceManager.EXPECT().GetResourceProviders().Return(mock.ResourceProvider)
 
 	mock.MockProviders["0"].EXPECT().IsInstanceSupported(mock.MockInstance).Return(true)
-	mock.MockProviders["0"].EXPECT().UpdateResourceCapacity(mock.MockInstance).Return(nil)
+	mock.MockProviders["0"].EXPECT().UpdateResourceCapacity(gomock.Any(), mock.MockInstance).Return(nil)
 
 	mock.MockProviders["1"].EXPECT().IsInstanceSupported(mock.MockInstance).Return(false)
 
-	err := mock.NodeWithMock.UpdateResources(mock.MockResourceManager)
+	err := mock.NodeWithMock.UpdateResources(context.TODO(), mock.MockResourceManager)
 	assert.NoError(t, err)
 }
 
@@ -277,15 +278,15 @@ func TestNode_UpdateResources_SomeFail(t *testing.T) {
 	mock.MockResourceManager.EXPECT().GetResourceProviders().Return(mock.ResourceProvider)
 
 	mock.MockProviders["0"].EXPECT().IsInstanceSupported(mock.MockInstance).Return(true)
-	mock.MockProviders["0"].EXPECT().UpdateResourceCapacity(mock.MockInstance).Return(nil)
+	mock.MockProviders["0"].EXPECT().UpdateResourceCapacity(gomock.Any(), mock.MockInstance).Return(nil)
 
 	mock.MockProviders["1"].EXPECT().IsInstanceSupported(mock.MockInstance).Return(true)
-	mock.MockProviders["1"].EXPECT().UpdateResourceCapacity(mock.MockInstance).Return(mockError)
+	mock.MockProviders["1"].EXPECT().UpdateResourceCapacity(gomock.Any(), mock.MockInstance).Return(mockError)
 
 	mock.MockProviders["2"].EXPECT().IsInstanceSupported(mock.MockInstance).Return(true)
-	mock.MockProviders["2"].EXPECT().UpdateResourceCapacity(mock.MockInstance).Return(nil)
+	mock.MockProviders["2"].EXPECT().UpdateResourceCapacity(gomock.Any(), mock.MockInstance).Return(nil)
 
-	err := mock.NodeWithMock.UpdateResources(mock.MockResourceManager)
+	err := mock.NodeWithMock.UpdateResources(context.TODO(), mock.MockResourceManager)
 	assert.NotNil(t, err)
 }
 
@@ -297,7 +298,7 @@ func TestNode_UpdateResources_NodeNotReady(t *testing.T) {
 
 	mock := NewMock(ctrl, 1)
 
-	err := mock.NodeWithMock.UpdateResources(mock.MockResourceManager)
+	err := mock.NodeWithMock.UpdateResources(context.TODO(), mock.MockResourceManager)
 	assert.Nil(t, err)
 }
 
@@ -0,0 +1,166 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/resource"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// The flags of the controller that serve the introspection API with TLS and authenticate the requests
+	flagIntrospectTLSCertFile = "introspect-tls-cert-file"
+	flagIntrospectEnableAuth  = "introspect-enable-auth"
+)
+
+// IntrospectClient gets the responses of the controller's introspection API
+type IntrospectClient interface {
+	// Get returns the response body of the path with the query, and the cursor of the next page if the response is
+	// paginated
+	Get(ctx context.Context, path string, query url.Values) (body []byte, cursor string, err error)
+}
+
+// httpIntrospectClient calls the introspection API at the base URL
+type httpIntrospectClient struct {
+	client  *http.Client
+	baseURL *url.URL
+	// token is the bearer token sent with the requests if not empty
+	token string
+}
+
+// NewIntrospectClient returns the client that calls the introspection API at the URL directly, like the URL of a
+// port forward to the controller, with the bearer token if the API requires authentication
+func NewIntrospectClient(baseURL string, token string) (IntrospectClient, error) {
+	parsedURL, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid introspection API url %q: %v", baseURL, err)
+	}
+	return &httpIntrospectClient{client: http.DefaultClient, baseURL: parsedURL, token: token}, nil
+}
+
+// NewProxyIntrospectClient returns the client that calls the introspection API of the leader controller through the
+// pod proxy of the API server, the leader is found from the leader election lease in the namespace. The API is called
+// over HTTPS if the leader serves it with TLS, and an error is returned if the leader authenticates the requests since
+// the API server doesn't forward the bearer token to the pod
+func NewProxyIntrospectClient(ctx context.Context, restConfig *rest.Config, clientSet kubernetes.Interface,
+	namespace string, port int) (IntrospectClient, error) {
+	lease, err := clientSet.CoordinationV1().Leases(namespace).Get(ctx, config.LeaderElectionKey, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the leader election lease of the controller: %v", err)
+	}
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity == "" {
+		return nil, fmt.Errorf("the controller has no leader in namespace %s", namespace)
+	}
+	// The holder identity is the pod name followed by a unique suffix
+	podName, _, _ := strings.Cut(*lease.Spec.HolderIdentity, "_")
+
+	pod, err := clientSet.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the leader controller pod %s: %v", podName, err)
+	}
+	flags := getIntrospectFlags(pod)
+	if flags.enableAuth {
+		return nil, fmt.Errorf("the controller pod %s authenticates the introspection API requests with "+
+			"--%s and the API server pod proxy doesn't forward the bearer token, port forward to the controller "+
+			"with 'kubectl port-forward -n %s pod/%s %d' and pass --url and --token instead",
+			podName, flagIntrospectEnableAuth, namespace, podName, port)
+	}
+	// The pod proxy calls the pod over HTTPS if the scheme prefixes the pod name
+	proxyName := podName + ":" + strconv.Itoa(port)
+	if flags.tls {
+		proxyName = "https:" + proxyName
+	}
+
+	client, err := rest.HTTPClientFor(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	serverURL, _, err := rest.DefaultServerUrlFor(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &httpIntrospectClient{
+		client:  client,
+		baseURL: serverURL.JoinPath("api", "v1", "namespaces", namespace, "pods", proxyName, "proxy"),
+	}, nil
+}
+
+// introspectFlags are the flags of the controller that change how the introspection API is called
+type introspectFlags struct {
+	tls        bool
+	enableAuth bool
+}
+
+// getIntrospectFlags returns the introspection API flags set in the command and arguments of the controller pod
+func getIntrospectFlags(pod *v1.Pod) introspectFlags {
+	flags := introspectFlags{}
+	for _, container := range pod.Spec.Containers {
+		for _, arg := range append(append([]string{}, container.Command...), container.Args...) {
+			if !strings.HasPrefix(arg, "-") {
+				continue
+			}
+			// The flag package accepts the flags with one or two dashes
+			name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+			switch name {
+			case flagIntrospectTLSCertFile:
+				// The value may also be the next argument, which can't be empty
+				flags.tls = !hasValue || value != ""
+			case flagIntrospectEnableAuth:
+				enabled, err := strconv.ParseBool(value)
+				flags.enableAuth = !hasValue || (err == nil && enabled)
+			}
+		}
+	}
+	return flags
+}
+
+func (c *httpIntrospectClient) Get(ctx context.Context, path string, query url.Values) ([]byte, string, error) {
+	requestURL := c.baseURL.JoinPath(path)
+	requestURL.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL.String(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("introspection API returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return body, resp.Header.Get(resource.ContinueHeader), nil
+}
@@ -0,0 +1,263 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/pool"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider/branch/trunk"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/resource"
+)
+
+// podResource is a resource assigned to a pod
+type podResource struct {
+	Namespace    string `json:",omitempty"`
+	Name         string `json:",omitempty"`
+	UID          string
+	ResourceName string
+	// BranchENI is the branch ENI of the pod for the pod ENI resource
+	BranchENI *trunk.ENIDetails `json:",omitempty"`
+	// Resource is the IP address of the pod for the IP address resources
+	Resource *pool.Resource `json:",omitempty"`
+}
+
+// leakedResource is a resource assigned to a pod that no longer exists
+type leakedResource struct {
+	NodeName     string
+	ResourceName string
+	PodUID       string
+	ResourceID   string
+}
+
+// nodesCommand lists the number of used, warm and cooling resources of each resource of each node
+func nodesCommand(ctx context.Context, o *options, args []string, out io.Writer) error {
+	if len(args) != 0 {
+		return fmt.Errorf("%w: nodes takes no arguments", ErrUsage)
+	}
+	all, err := getAllPages(ctx, o, resource.GetResourcesSummaryPath)
+	if err != nil {
+		return err
+	}
+
+	summaries := make(map[string]map[string]interface{})
+	var rows [][]string
+	for resourceName, nodes := range all {
+		for nodeName, data := range nodes {
+			var summary interface{}
+			var used, warm, cooling int
+			if resourceName == config.ResourceNamePodENI {
+				trunkSummary := trunk.IntrospectSummaryResponse{}
+				if err := json.Unmarshal(data, &trunkSummary); err != nil {
+					return fmt.Errorf("failed to decode %s of node %s: %v", resourceName, nodeName, err)
+				}
+				summary = trunkSummary
+				used, warm, cooling = trunkSummary.BranchENICount, trunkSummary.WarmPoolLen, trunkSummary.DeleteQueueLen
+			} else {
+				poolSummary := pool.IntrospectSummaryResponse{}
+				if err := json.Unmarshal(data, &poolSummary); err != nil {
+					return fmt.Errorf("failed to decode %s of node %s: %v", resourceName, nodeName, err)
+				}
+				summary = poolSummary
+				used, warm, cooling = poolSummary.UsedResourcesCount, poolSummary.WarmResourcesCount,
+					poolSummary.CoolingResourcesCount
+			}
+			if summaries[nodeName] == nil {
+				summaries[nodeName] = make(map[string]interface{})
+			}
+			summaries[nodeName][resourceName] = summary
+			rows = append(rows, []string{nodeName, resourceName, strconv.Itoa(used), strconv.Itoa(warm),
+				strconv.Itoa(cooling)})
+		}
+	}
+	return printOutput(out, o.output, summaries, []string{"NODE", "RESOURCE", "IN USE", "WARM", "COOLING"}, rows)
+}
+
+// podsCommand lists the resources of the pods on the node
+func podsCommand(ctx context.Context, o *options, args []string, out io.Writer) error {
+	if len(args) != 0 || o.node == "" {
+		return fmt.Errorf("%w: pods requires the --node flag and takes no arguments", ErrUsage)
+	}
+	resources, err := getNode(ctx, o, o.node)
+	if err != nil {
+		return err
+	}
+	pods, err := listPods(ctx, o, o.node)
+	if err != nil {
+		return err
+	}
+
+	var podResources []podResource
+	newPodResource := func(uid string, resourceName string) podResource {
+		pod := pods[uid]
+		return podResource{Namespace: pod.Namespace, Name: pod.Name, UID: uid, ResourceName: resourceName}
+	}
+	if resources.Trunk != nil {
+		for uid, enis := range resources.Trunk.PodToBranchENI {
+			for _, eni := range enis {
+				podResource := newPodResource(uid, config.ResourceNamePodENI)
+				podResource.BranchENI = &eni
+				podResources = append(podResources, podResource)
+			}
+		}
+	}
+	for resourceName, poolResponse := range resources.Pools {
+		for uid, usedResource := range poolResponse.UsedResources {
+			podResource := newPodResource(uid, resourceName)
+			podResource.Resource = &usedResource
+			podResources = append(podResources, podResource)
+		}
+	}
+	slices.SortFunc(podResources, func(a, b podResource) int {
+		return slices.Compare([]string{a.Namespace, a.Name, a.UID, a.ResourceName},
+			[]string{b.Namespace, b.Name, b.UID, b.ResourceName})
+	})
+
+	var rows [][]string
+	for _, podResource := range podResources {
+		resourceID := ""
+		if podResource.BranchENI != nil {
+			resourceID = fmt.Sprintf("%s (%s)", podResource.BranchENI.ID, podResource.BranchENI.IPV4Addr)
+		} else if podResource.Resource != nil {
+			resourceID = podResource.Resource.ResourceID
+		}
+		rows = append(rows, []string{podResource.Namespace, podResource.Name, podResource.UID,
+			podResource.ResourceName, resourceID})
+	}
+	return printOutput(out, o.output, podResources,
+		[]string{"NAMESPACE", "NAME", "UID", "RESOURCE", "RESOURCE ID"}, rows)
+}
+
+// trunkCommand lists the branch ENIs of the trunk ENI of the node
+func trunkCommand(ctx context.Context, o *options, args []string, out io.Writer) error {
+	nodeName, err := nodeArg("trunk", args)
+	if err != nil {
+		return err
+	}
+	o.resources = config.ResourceNamePodENI
+	resources, err := getNode(ctx, o, nodeName)
+	if err != nil {
+		return err
+	}
+	if resources.Trunk == nil {
+		return fmt.Errorf("node %s has no trunk ENI", nodeName)
+	}
+
+	var rows [][]string
+	addRows := func(enis []trunk.ENIDetails, state resource.ResourceState, podUID string) {
+		for _, eni := range enis {
+			rows = append(rows, []string{eni.ID, eni.IPV4Addr, strconv.Itoa(eni.VlanID), eni.SubnetCIDR, string(state),
+				podUID})
+		}
+	}
+	for uid, enis := range resources.Trunk.PodToBranchENI {
+		addRows(enis, resource.ResourceStateInUse, uid)
+	}
+	for _, enis := range resources.Trunk.WarmPools {
+		addRows(enis, resource.ResourceStateWarm, "")
+	}
+	addRows(resources.Trunk.DeleteQueue, resource.ResourceStateCooling, "")
+	return printOutput(out, o.output, resources.Trunk,
+		[]string{"ENI ID", "IPV4 ADDRESS", "VLAN ID", "SUBNET CIDR", "STATE", "POD UID"}, rows)
+}
+
+// poolCommand lists the IP addresses and prefixes in the pool of each resource of the node
+func poolCommand(ctx context.Context, o *options, args []string, out io.Writer) error {
+	nodeName, err := nodeArg("pool", args)
+	if err != nil {
+		return err
+	}
+	resources, err := getNode(ctx, o, nodeName)
+	if err != nil {
+		return err
+	}
+
+	var rows [][]string
+	for resourceName, poolResponse := range resources.Pools {
+		for uid, usedResource := range poolResponse.UsedResources {
+			rows = append(rows, []string{resourceName, usedResource.GroupID, usedResource.ResourceID,
+				string(resource.ResourceStateInUse), uid})
+		}
+		for _, warmResources := range poolResponse.WarmResources {
+			for _, warmResource := range warmResources {
+				rows = append(rows, []string{resourceName, warmResource.GroupID, warmResource.ResourceID,
+					string(resource.ResourceStateWarm), ""})
+			}
+		}
+		for _, coolingResource := range poolResponse.CoolingResources {
+			rows = append(rows, []string{resourceName, coolingResource.Resource.GroupID,
+				coolingResource.Resource.ResourceID, string(resource.ResourceStateCooling), ""})
+		}
+	}
+	return printOutput(out, o.output, resources.Pools,
+		[]string{"RESOURCE", "GROUP ID", "RESOURCE ID", "STATE", "POD UID"}, rows)
+}
+
+// leaksCommand lists the resources that are assigned to pods which no longer exist. The pods deleted after the
+// resources are listed, whose resources are being released, may be listed as well
+func leaksCommand(ctx context.Context, o *options, args []string, out io.Writer) error {
+	if len(args) != 0 {
+		return fmt.Errorf("%w: leaks takes no arguments", ErrUsage)
+	}
+	all, err := getAllPages(ctx, o, resource.GetAllResourcesPath)
+	if err != nil {
+		return err
+	}
+	pods, err := listPods(ctx, o, "")
+	if err != nil {
+		return err
+	}
+
+	var leakedResources []leakedResource
+	addLeaked := func(nodeName string, resourceName string, uid string, resourceID string) {
+		if _, exists := pods[uid]; !exists {
+			leakedResources = append(leakedResources, leakedResource{NodeName: nodeName,
+				ResourceName: resourceName, PodUID: uid, ResourceID: resourceID})
+		}
+	}
+	for resourceName, nodes := range all {
+		for nodeName, data := range nodes {
+			resources, err := decodeNodeResources(map[string]json.RawMessage{resourceName: data})
+			if err != nil {
+				return err
+			}
+			if resources.Trunk != nil {
+				for uid, enis := range resources.Trunk.PodToBranchENI {
+					for _, eni := range enis {
+						addLeaked(nodeName, resourceName, uid, eni.ID)
+					}
+				}
+			}
+			for uid, usedResource := range resources.Pools[resourceName].UsedResources {
+				addLeaked(nodeName, resourceName, uid, usedResource.ResourceID)
+			}
+		}
+	}
+	slices.SortFunc(leakedResources, func(a, b leakedResource) int {
+		return slices.Compare([]string{a.NodeName, a.ResourceName, a.PodUID, a.ResourceID},
+			[]string{b.NodeName, b.ResourceName, b.PodUID, b.ResourceID})
+	})
+
+	var rows [][]string
+	for _, leaked := range leakedResources {
+		rows = append(rows, []string{leaked.NodeName, leaked.ResourceName, leaked.PodUID, leaked.ResourceID})
+	}
+	return printOutput(out, o.output, leakedResources, []string{"NODE", "RESOURCE", "POD UID", "RESOURCE ID"}, rows)
+}
@@ -0,0 +1,305 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package plugin implements the kubectl vpc-resources plugin that inspects the VPC resources managed by the controller
+// through the controller's introspection API
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/pool"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider/branch/trunk"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/resource"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	// pageLimit is the number of nodes requested in each page from the introspection API
+	pageLimit = 100
+
+	usage = `kubectl vpc-resources inspects the VPC resources managed by the VPC resource controller.
+
+Usage:
+  kubectl vpc-resources <command> [arguments] [flags]
+
+Commands:
+  nodes               List the resources of each node
+  pods --node <node>  List the resources of the pods on the node
+  trunk <node>        List the branch ENIs of the trunk ENI of the node
+  pool <node>         List the IP addresses and prefixes in the pools of the node
+  leaks               List the resources assigned to pods that no longer exist
+
+The introspection API of the leader controller is called through the API server pod proxy, over HTTPS if the
+controller serves it with TLS, or at --url if set, like the URL of a port forward to the controller. The API server
+doesn't forward the bearer token, so --url and --token must be used if the controller authenticates the introspection
+API requests.
+
+Flags:
+`
+)
+
+var (
+	// ErrUsage is returned if the command or its arguments are not valid
+	ErrUsage = errors.New("invalid usage")
+)
+
+// options are the flags of the plugin
+type options struct {
+	kubeconfig  string
+	kubeContext string
+	url         string
+	token       string
+	namespace   string
+	port        int
+	output      string
+	node        string
+	resources   string
+
+	// introspectClient and clientSet are created from the flags when the command runs
+	introspectClient IntrospectClient
+	clientSet        kubernetes.Interface
+}
+
+func (o *options) addFlags(flagSet *flag.FlagSet) {
+	flagSet.StringVar(&o.kubeconfig, "kubeconfig", "", "Path to the kubeconfig file")
+	flagSet.StringVar(&o.kubeContext, "context", "", "The name of the kubeconfig context to use")
+	flagSet.StringVar(&o.url, "url", "", "The URL of the introspection API, like http://localhost:22775")
+	flagSet.StringVar(&o.token, "token", "", "The bearer token to authenticate to the introspection API at --url")
+	flagSet.StringVar(&o.namespace, "namespace", config.LeaderElectionNamespace, "The namespace of the controller")
+	flagSet.IntVar(&o.port, "port", 22775, "The port of the introspection API of the controller")
+	flagSet.StringVar(&o.output, "o", outputTable, "The output format, one of table, json or yaml")
+	flagSet.StringVar(&o.node, "node", "", "The name of the node, required by the pods command")
+	flagSet.StringVar(&o.resources, "resource", "", "The comma separated resource names to list, all if empty")
+}
+
+// init creates the clients from the flags if they are not set
+func (o *options) init(ctx context.Context) error {
+	if o.clientSet != nil && o.introspectClient != nil {
+		return nil
+	}
+	restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: o.kubeconfig, Precedence: clientcmd.NewDefaultClientConfigLoadingRules().Precedence},
+		&clientcmd.ConfigOverrides{CurrentContext: o.kubeContext}).ClientConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %v", err)
+	}
+	if o.clientSet == nil {
+		if o.clientSet, err = kubernetes.NewForConfig(restConfig); err != nil {
+			return err
+		}
+	}
+	if o.introspectClient == nil {
+		if o.url != "" {
+			o.introspectClient, err = NewIntrospectClient(o.url, o.token)
+		} else {
+			o.introspectClient, err = NewProxyIntrospectClient(ctx, restConfig, o.clientSet, o.namespace, o.port)
+		}
+	}
+	return err
+}
+
+// Run runs the plugin command in the arguments and writes the output
+func Run(ctx context.Context, args []string, out io.Writer) error {
+	return run(ctx, args, out, &options{})
+}
+
+func run(ctx context.Context, args []string, out io.Writer, o *options) error {
+	flagSet := flag.NewFlagSet("kubectl vpc-resources", flag.ContinueOnError)
+	flagSet.SetOutput(out)
+	flagSet.Usage = func() {
+		fmt.Fprint(out, usage)
+		flagSet.PrintDefaults()
+	}
+	o.addFlags(flagSet)
+
+	args, err := parseInterspersed(flagSet, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if len(args) == 0 {
+		flagSet.Usage()
+		return ErrUsage
+	}
+	if o.output != outputTable && o.output != outputJSON && o.output != outputYAML {
+		return fmt.Errorf("%w: unknown output format %q", ErrUsage, o.output)
+	}
+
+	command, args := args[0], args[1:]
+	var handler func(ctx context.Context, o *options, args []string, out io.Writer) error
+	switch command {
+	case "nodes":
+		handler = nodesCommand
+	case "pods":
+		handler = podsCommand
+	case "trunk":
+		handler = trunkCommand
+	case "pool":
+		handler = poolCommand
+	case "leaks":
+		handler = leaksCommand
+	default:
+		flagSet.Usage()
+		return fmt.Errorf("%w: unknown command %q", ErrUsage, command)
+	}
+
+	if err := o.init(ctx); err != nil {
+		return err
+	}
+	return handler(ctx, o, args, out)
+}
+
+// parseInterspersed parses the flags that are before, between and after the positional arguments, and returns the
+// positional arguments
+func parseInterspersed(flagSet *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flagSet.Parse(args); err != nil {
+			return nil, err
+		}
+		if flagSet.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, flagSet.Arg(0))
+		args = flagSet.Args()[1:]
+	}
+}
+
+// nodeArg returns the node name that is the only argument of the command
+func nodeArg(command string, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("%w: %s requires the node name as the only argument", ErrUsage, command)
+	}
+	return args[0], nil
+}
+
+// getNode returns the resources of the node
+func getNode(ctx context.Context, o *options, nodeName string) (nodeResources, error) {
+	query := url.Values{}
+	if o.resources != "" {
+		query.Set(resource.QueryResource, o.resources)
+	}
+	body, _, err := o.introspectClient.Get(ctx, resource.GetNodeResourcesPath+nodeName, query)
+	if err != nil {
+		return nodeResources{}, err
+	}
+	raw := map[string]json.RawMessage{}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nodeResources{}, fmt.Errorf("failed to decode the resources of node %s: %v", nodeName, err)
+	}
+	return decodeNodeResources(raw)
+}
+
+// getAllPages returns the response of each resource for all the nodes, by getting the pages of the nodes from the
+// paginated path
+func getAllPages(ctx context.Context, o *options, path string) (map[string]map[string]json.RawMessage, error) {
+	query := url.Values{resource.QueryLimit: []string{fmt.Sprint(pageLimit)}}
+	if o.resources != "" {
+		query.Set(resource.QueryResource, o.resources)
+	}
+
+	all := make(map[string]map[string]json.RawMessage)
+	for {
+		body, cursor, err := o.introspectClient.Get(ctx, path, query)
+		if err != nil {
+			return nil, err
+		}
+		page := map[string]map[string]json.RawMessage{}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("failed to decode the response of %s: %v", path, err)
+		}
+		for resourceName, nodes := range page {
+			if all[resourceName] == nil {
+				all[resourceName] = make(map[string]json.RawMessage)
+			}
+			for nodeName, data := range nodes {
+				all[resourceName][nodeName] = data
+			}
+		}
+		if cursor == "" {
+			return all, nil
+		}
+		query.Set(resource.QueryContinue, cursor)
+	}
+}
+
+// listPods returns the pods by UID, of the node if the node name is not empty or else of all the nodes
+func listPods(ctx context.Context, o *options, nodeName string) (map[string]v1.Pod, error) {
+	listOptions := metav1.ListOptions{Limit: 500}
+	if nodeName != "" {
+		listOptions.FieldSelector = fields.OneTermEqualSelector("spec.nodeName", nodeName).String()
+	}
+	pods := make(map[string]v1.Pod)
+	for {
+		podList, err := o.clientSet.CoreV1().Pods(metav1.NamespaceAll).List(ctx, listOptions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pods: %v", err)
+		}
+		for _, pod := range podList.Items {
+			pods[string(pod.UID)] = pod
+		}
+		if podList.Continue == "" {
+			return pods, nil
+		}
+		listOptions.Continue = podList.Continue
+	}
+}
+
+// nodeResources is the introspection response of the resources of a node, decoded into the types of the controller
+type nodeResources struct {
+	// Trunk is the trunk ENI of the node, nil if the node has no trunk ENI
+	Trunk *trunk.IntrospectResponse `json:",omitempty"`
+	// Pools is the IP address pool of each resource of the node
+	Pools map[string]pool.IntrospectResponse `json:",omitempty"`
+}
+
+// decodeNodeResources decodes the introspection response of each resource of a node
+func decodeNodeResources(raw map[string]json.RawMessage) (nodeResources, error) {
+	resources := nodeResources{Pools: make(map[string]pool.IntrospectResponse)}
+	for resourceName, data := range raw {
+		if resourceName == config.ResourceNamePodENI {
+			trunkResponse := trunk.IntrospectResponse{}
+			if err := json.Unmarshal(data, &trunkResponse); err != nil {
+				return resources, fmt.Errorf("failed to decode %s: %v", resourceName, err)
+			}
+			if trunkResponse.TrunkENIID != "" {
+				resources.Trunk = &trunkResponse
+			}
+			continue
+		}
+		poolResponse := pool.IntrospectResponse{}
+		if err := json.Unmarshal(data, &poolResponse); err != nil {
+			return resources, fmt.Errorf("failed to decode %s: %v", resourceName, err)
+		}
+		if len(poolResponse.UsedResources) > 0 || len(poolResponse.WarmResources) > 0 ||
+			len(poolResponse.CoolingResources) > 0 {
+			resources.Pools[resourceName] = poolResponse
+		}
+	}
+	return resources, nil
+}
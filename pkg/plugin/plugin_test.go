@@ -0,0 +1,287 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/pool"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider/branch/trunk"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/resource"
+
+	"github.com/stretchr/testify/assert"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	nodeName    = "node-1"
+	podUID      = "pod-uid-1"
+	deletedUID  = "pod-uid-2"
+	branchENI   = trunk.ENIDetails{ID: "eni-00000000000000001", IPV4Addr: "192.168.1.1", VlanID: 1}
+	ipResource  = pool.Resource{GroupID: "192.168.2.1", ResourceID: "192.168.2.1"}
+	ipResource2 = pool.Resource{GroupID: "192.168.2.2", ResourceID: "192.168.2.2"}
+
+	nodeResponse = map[string]interface{}{
+		config.ResourceNamePodENI: trunk.IntrospectResponse{TrunkENIID: "eni-trunk",
+			PodToBranchENI: map[string][]trunk.ENIDetails{podUID: {branchENI}}},
+		config.ResourceNameIPAddress: pool.IntrospectResponse{
+			UsedResources: map[string]pool.Resource{deletedUID: ipResource},
+			WarmResources: map[string][]pool.Resource{ipResource2.GroupID: {ipResource2}}},
+	}
+)
+
+// fakeIntrospectClient returns the response of each path, and the pages of the paginated paths in order
+type fakeIntrospectClient struct {
+	responses map[string][]interface{}
+	queries   []url.Values
+}
+
+func (c *fakeIntrospectClient) Get(_ context.Context, path string, query url.Values) ([]byte, string, error) {
+	c.queries = append(c.queries, query)
+	pages := c.responses[path]
+	page := 0
+	if cursor := query.Get(resource.QueryContinue); cursor != "" {
+		fmt.Sscan(cursor, &page)
+	}
+	if page >= len(pages) {
+		return nil, "", fmt.Errorf("unexpected request for %s", path)
+	}
+	body, err := json.Marshal(pages[page])
+	if err != nil {
+		return nil, "", err
+	}
+	cursor := ""
+	if page+1 < len(pages) {
+		cursor = fmt.Sprint(page + 1)
+	}
+	return body, cursor, nil
+}
+
+func getTestOptions(responses map[string][]interface{}, pods ...v1.Pod) (*options, *fakeIntrospectClient) {
+	client := &fakeIntrospectClient{responses: responses}
+	clientSet := fake.NewSimpleClientset()
+	for _, pod := range pods {
+		pod := pod
+		_ = clientSet.Tracker().Add(&pod)
+	}
+	return &options{introspectClient: client, clientSet: clientSet}, client
+}
+
+func getPod(uid string, name string) v1.Pod {
+	return v1.Pod{ObjectMeta: metav1.ObjectMeta{UID: types.UID(uid), Namespace: "default", Name: name},
+		Spec: v1.PodSpec{NodeName: nodeName}}
+}
+
+// TestRun_Usage tests the invalid commands and flags are rejected before calling the introspection API
+func TestRun_Usage(t *testing.T) {
+	for _, args := range [][]string{{}, {"unknown"}, {"nodes", "-o", "xml"}, {"trunk"}, {"pods", "extra"}} {
+		o, client := getTestOptions(nil)
+		err := run(context.Background(), args, &bytes.Buffer{}, o)
+		assert.ErrorIs(t, err, ErrUsage, "args %v", args)
+		assert.Empty(t, client.queries)
+	}
+
+	err := run(context.Background(), []string{"-h"}, &bytes.Buffer{}, &options{})
+	assert.NoError(t, err)
+}
+
+// TestParseInterspersed tests the flags after the positional arguments are parsed
+func TestParseInterspersed(t *testing.T) {
+	o := &options{}
+	flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+	o.addFlags(flagSet)
+
+	args, err := parseInterspersed(flagSet, []string{"trunk", nodeName, "-o", "json", "--port=8080"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"trunk", nodeName}, args)
+	assert.Equal(t, outputJSON, o.output)
+	assert.Equal(t, 8080, o.port)
+}
+
+// TestRun_Nodes tests the summaries of all the pages of nodes are listed
+func TestRun_Nodes(t *testing.T) {
+	o, client := getTestOptions(map[string][]interface{}{resource.GetResourcesSummaryPath: {
+		map[string]interface{}{config.ResourceNamePodENI: map[string]trunk.IntrospectSummaryResponse{
+			nodeName: {TrunkENIID: "eni-trunk", BranchENICount: 3, WarmPoolLen: 1}}},
+		map[string]interface{}{config.ResourceNameIPAddress: map[string]pool.IntrospectSummaryResponse{
+			"node-2": {UsedResourcesCount: 5, WarmResourcesCount: 2, CoolingResourcesCount: 1}}},
+	}})
+
+	out := &bytes.Buffer{}
+	err := run(context.Background(), []string{"nodes"}, out, o)
+	assert.NoError(t, err)
+	assert.Len(t, client.queries, 2)
+	assert.Equal(t, fmt.Sprint(pageLimit), client.queries[0].Get(resource.QueryLimit))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 3)
+	assert.Equal(t, []string{"NODE", "RESOURCE", "IN", "USE", "WARM", "COOLING"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{nodeName, config.ResourceNamePodENI, "3", "1", "0"}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{"node-2", config.ResourceNameIPAddress, "5", "2", "1"}, strings.Fields(lines[2]))
+}
+
+// TestRun_Trunk tests the trunk ENI of the node is returned in the type of the controller
+func TestRun_Trunk(t *testing.T) {
+	o, client := getTestOptions(map[string][]interface{}{resource.GetNodeResourcesPath + nodeName: {nodeResponse}})
+
+	out := &bytes.Buffer{}
+	err := run(context.Background(), []string{"trunk", nodeName, "-o", "yaml"}, out, o)
+	assert.NoError(t, err)
+	assert.Equal(t, config.ResourceNamePodENI, client.queries[0].Get(resource.QueryResource))
+
+	response := trunk.IntrospectResponse{}
+	assert.NoError(t, yaml.Unmarshal(out.Bytes(), &response))
+	assert.Equal(t, nodeResponse[config.ResourceNamePodENI], response)
+}
+
+// TestRun_Pool tests the resources in each state of the pools of the node are listed
+func TestRun_Pool(t *testing.T) {
+	o, _ := getTestOptions(map[string][]interface{}{resource.GetNodeResourcesPath + nodeName: {nodeResponse}})
+
+	out := &bytes.Buffer{}
+	err := run(context.Background(), []string{"pool", nodeName}, out, o)
+	assert.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 3)
+	assert.Equal(t, []string{config.ResourceNameIPAddress, ipResource.GroupID, ipResource.ResourceID,
+		string(resource.ResourceStateInUse), deletedUID}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{config.ResourceNameIPAddress, ipResource2.GroupID, ipResource2.ResourceID,
+		string(resource.ResourceStateWarm)}, strings.Fields(lines[2]))
+}
+
+// TestRun_Pods tests the resources of the pods on the node are listed with the pod namespace and name
+func TestRun_Pods(t *testing.T) {
+	o, _ := getTestOptions(map[string][]interface{}{resource.GetNodeResourcesPath + nodeName: {nodeResponse}},
+		getPod(podUID, "pod-1"))
+
+	out := &bytes.Buffer{}
+	err := run(context.Background(), []string{"pods", "--node", nodeName, "-o", "json"}, out, o)
+	assert.NoError(t, err)
+
+	var podResources []podResource
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &podResources))
+	assert.Equal(t, []podResource{
+		{UID: deletedUID, ResourceName: config.ResourceNameIPAddress, Resource: &ipResource},
+		{Namespace: "default", Name: "pod-1", UID: podUID, ResourceName: config.ResourceNamePodENI,
+			BranchENI: &branchENI},
+	}, podResources)
+}
+
+// TestRun_Leaks tests the resources of the pods that don't exist are listed
+func TestRun_Leaks(t *testing.T) {
+	o, _ := getTestOptions(map[string][]interface{}{resource.GetAllResourcesPath: {map[string]interface{}{
+		config.ResourceNamePodENI:    map[string]interface{}{nodeName: nodeResponse[config.ResourceNamePodENI]},
+		config.ResourceNameIPAddress: map[string]interface{}{nodeName: nodeResponse[config.ResourceNameIPAddress]},
+	}}}, getPod(podUID, "pod-1"))
+
+	out := &bytes.Buffer{}
+	err := run(context.Background(), []string{"leaks", "-o", "json"}, out, o)
+	assert.NoError(t, err)
+
+	var leakedResources []leakedResource
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &leakedResources))
+	assert.Equal(t, []leakedResource{{NodeName: nodeName, ResourceName: config.ResourceNameIPAddress,
+		PodUID: deletedUID, ResourceID: ipResource.ResourceID}}, leakedResources)
+}
+
+// TestIntrospectClient_Get tests the bearer token is sent and the cursor of the next page is returned
+func TestIntrospectClient_Get(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "invalid bearer token", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set(resource.ContinueHeader, "cursor")
+		fmt.Fprint(w, req.URL.Path+"?"+req.URL.RawQuery)
+	}))
+	defer ts.Close()
+
+	client, err := NewIntrospectClient(ts.URL, "token")
+	assert.NoError(t, err)
+	body, cursor, err := client.Get(context.Background(), resource.GetAllResourcesPath,
+		url.Values{resource.QueryLimit: []string{"1"}})
+	assert.NoError(t, err)
+	assert.Equal(t, resource.GetAllResourcesPath+"?limit=1", string(body))
+	assert.Equal(t, "cursor", cursor)
+
+	client, err = NewIntrospectClient(ts.URL, "")
+	assert.NoError(t, err)
+	_, _, err = client.Get(context.Background(), resource.GetAllResourcesPath, nil)
+	assert.ErrorContains(t, err, "invalid bearer token")
+}
+
+// TestNewProxyIntrospectClient tests the pod proxy uses HTTPS if the leader serves the introspection API with TLS,
+// and fails if the leader authenticates the requests
+func TestNewProxyIntrospectClient(t *testing.T) {
+	holderIdentity := "controller-pod_suffix"
+	lease := &coordinationv1.Lease{ObjectMeta: metav1.ObjectMeta{Name: config.LeaderElectionKey,
+		Namespace: config.LeaderElectionNamespace}, Spec: coordinationv1.LeaseSpec{HolderIdentity: &holderIdentity}}
+	restConfig := &rest.Config{Host: "https://api-server"}
+
+	tests := []struct {
+		name          string
+		args          []string
+		expectedURL   string
+		expectedError string
+	}{
+		{
+			name:        "http",
+			args:        []string{"--introspect-bind-addr=:22775", "--introspect-enable-auth=false"},
+			expectedURL: "https://api-server/api/v1/namespaces/kube-system/pods/controller-pod:22775/proxy",
+		},
+		{
+			name:        "https",
+			args:        []string{"-introspect-tls-cert-file", "/tls/tls.crt", "-introspect-tls-key-file=/tls/tls.key"},
+			expectedURL: "https://api-server/api/v1/namespaces/kube-system/pods/https:controller-pod:22775/proxy",
+		},
+		{
+			name:          "auth enabled",
+			args:          []string{"--introspect-enable-auth"},
+			expectedError: "kubectl port-forward -n kube-system pod/controller-pod 22775",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "controller-pod", Namespace: config.LeaderElectionNamespace},
+				Spec: v1.PodSpec{Containers: []v1.Container{{Name: "controller", Args: test.args}}}}
+			clientSet := fake.NewSimpleClientset(lease, pod)
+
+			client, err := NewProxyIntrospectClient(context.Background(), restConfig, clientSet,
+				config.LeaderElectionNamespace, 22775)
+			if test.expectedError != "" {
+				assert.ErrorContains(t, err, test.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedURL, client.(*httpIntrospectClient).baseURL.String())
+		})
+	}
+}
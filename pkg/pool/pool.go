@@ -15,14 +15,19 @@ package pool
 
 import (
 	"fmt"
+	"slices"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
 
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/lookup"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/utils"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/worker"
+
+	"k8s.io/apimachinery/pkg/util/wait"
 )
 
 var (
@@ -77,10 +82,18 @@ type pool struct {
 	// reSyncRequired is set if the upstream and pool are possibly out of sync due to
 	// errors in creating/deleting resources
 	reSyncRequired bool
+	// reSyncAfter is the time before which the re-sync of a pool initialized from the checkpoint is deferred, the
+	// checkpointed resources are served till then
+	reSyncAfter time.Time
 	// isPDPool indicates whether the pool is for prefix IP provider or secondary IP provider
 	isPDPool bool
 	// prefixAvailable indicates whether subnet has any prefix available
 	prefixAvailable bool
+	// drainingGroups is the set of partially used prefixes that new resources are not assigned from when prefix
+	// compaction is enabled, so that the prefixes can be released once all their resources are free
+	drainingGroups map[string]struct{}
+	// index records the pods the resources are assigned to for the lookup of the introspection API
+	index *lookup.Index
 }
 
 // Resource represents a secondary IPv4 address or a prefix-deconstructed IPv4 address, uniquely identified by GroupID and ResourceID
@@ -100,19 +113,35 @@ type CoolDownResource struct {
 
 // IntrospectResponse is the pool state returned to the introspect API
 type IntrospectResponse struct {
-	UsedResources    map[string]Resource
-	WarmResources    map[string][]Resource
-	CoolingResources []CoolDownResource
+	UsedResources       map[string]Resource
+	WarmResources       map[string][]Resource
+	CoolingResources    []CoolDownResource
+	PrefixFragmentation *PrefixFragmentation `json:",omitempty"`
 }
 
 type IntrospectSummaryResponse struct {
 	UsedResourcesCount    int
 	WarmResourcesCount    int
 	CoolingResourcesCount int
+	PrefixFragmentation   *PrefixFragmentation `json:",omitempty"`
+}
+
+// PrefixFragmentation is the fragmentation of the prefixes in the prefix IP pool
+type PrefixFragmentation struct {
+	// PrefixCount is the number of prefixes in the pool
+	PrefixCount int
+	// FreePrefixCount is the number of prefixes with all the resources free, which can be released
+	FreePrefixCount int
+	// FragmentedPrefixCount is the number of prefixes with both free and used resources
+	FragmentedPrefixCount int
+	// FragmentedFreeResourceCount is the number of free resources in the fragmented prefixes
+	FragmentedFreeResourceCount int
+	// DrainingPrefixCount is the number of prefixes being drained by prefix compaction
+	DrainingPrefixCount int
 }
 
 func NewResourcePool(log logr.Logger, poolConfig *config.WarmPoolConfig, usedResources map[string]Resource,
-	warmResources map[string][]Resource, nodeName string, capacity int, isPDPool bool) Pool {
+	warmResources map[string][]Resource, nodeName string, capacity int, isPDPool bool, index *lookup.Index) Pool {
 	pool := &pool{
 		log:            log,
 		warmPoolConfig: poolConfig,
@@ -121,10 +150,45 @@ func NewResourcePool(log logr.Logger, poolConfig *config.WarmPoolConfig, usedRes
 		capacity:       capacity,
 		nodeName:       nodeName,
 		isPDPool:       isPDPool,
+		drainingGroups: make(map[string]struct{}),
+		index:          index,
+	}
+	for requesterID, resource := range usedResources {
+		index.Assign(nodeName, requesterID, resource.ResourceID)
 	}
 	return pool
 }
 
+// NewResourcePoolFromCheckpoint returns a pool initialized from the checkpoint of a previous controller instead of the
+// upstream. The cooling resources are moved from the warm resources to the cool down queue, and the pool is re-synced
+// with the upstream on the first reconcile after a jittered delay to verify the checkpoint, so that the nodes
+// initialized together on a leader change don't describe their ENIs at once
+func NewResourcePoolFromCheckpoint(log logr.Logger, poolConfig *config.WarmPoolConfig, usedResources map[string]Resource,
+	warmResources map[string][]Resource, coolingResources []CoolDownResource, nodeName string, capacity int,
+	isPDPool bool, index *lookup.Index) Pool {
+	var coolDownQueue []CoolDownResource
+	for _, coolingResource := range coolingResources {
+		groupID := coolingResource.Resource.GroupID
+		index := slices.Index(warmResources[groupID], coolingResource.Resource)
+		if index < 0 {
+			continue
+		}
+		warmResources[groupID] = slices.Delete(warmResources[groupID], index, index+1)
+		coolDownQueue = append(coolDownQueue, coolingResource)
+	}
+	// The cool down queue is processed in order of deletion
+	slices.SortStableFunc(coolDownQueue, func(a, b CoolDownResource) int {
+		return a.DeletionTimestamp.Compare(b.DeletionTimestamp)
+	})
+
+	pool := NewResourcePool(log, poolConfig, usedResources, warmResources, nodeName, capacity, isPDPool,
+		index).(*pool)
+	pool.coolDownQueue = coolDownQueue
+	pool.reSyncRequired = true
+	pool.reSyncAfter = time.Now().Add(wait.Jitter(config.CheckpointVerifyDelay, config.CheckpointVerifyJitterFactor))
+	return pool
+}
+
 // ReSync syncs state of upstream with the local pool. If local resources have additional
 // resource which doesn't reflect in upstream list then these resources are removed. If the
 // upstream has additional resources which are not present locally, these resources are added
@@ -149,7 +213,7 @@ func (p *pool) ReSync(upstreamResourceGroupIDs []string) {
 		}
 
 		if p.isPDPool {
-			resourceIDs, err := utils.DeconstructIPsFromPrefix(resourceGroupID)
+			resourceIDs, err := p.deconstructPrefix(resourceGroupID)
 			if err != nil {
 				p.log.Error(err, "failed to sync upstream resource", "resource group id", resourceGroupID, "err", err)
 				continue
@@ -279,6 +343,7 @@ func (p *pool) AssignResource(requesterID string) (resourceID string, shouldReco
 
 	// Add the resource in the used resource key-value pair
 	p.usedResources[requesterID] = resource
+	p.index.Assign(p.nodeName, requesterID, resource.ResourceID)
 	p.log.V(1).Info("assigned resource",
 		"resource id", resource.ResourceID, "requester id", requesterID)
 
@@ -309,6 +374,7 @@ func (p *pool) FreeResource(requesterID string, resourceID string) (shouldReconc
 	}
 
 	delete(p.usedResources, requesterID)
+	p.index.Release(actualResource.ResourceID)
 
 	// Put the resource in cool down queue
 	resource := CoolDownResource{
@@ -333,6 +399,7 @@ func (p *pool) UpdatePool(job *worker.WarmPoolJob, didSucceed bool, prefixAvaila
 	if !didSucceed {
 		// If the job fails, re-sync the state of the Pool with upstream
 		p.reSyncRequired = true
+		p.reSyncAfter = time.Time{}
 		shouldReconcile = true
 		log.Error(fmt.Errorf("warm pool job failed: %v", job), "operation failed")
 	}
@@ -351,7 +418,7 @@ func (p *pool) UpdatePool(job *worker.WarmPoolJob, didSucceed bool, prefixAvaila
 			resourceIDs := []string{resourceGroup}
 			if p.isPDPool {
 				var err error
-				if resourceIDs, err = utils.DeconstructIPsFromPrefix(resourceGroup); err != nil {
+				if resourceIDs, err = p.deconstructPrefix(resourceGroup); err != nil {
 					log.Error(err, "failed to deconstruct prefix into valid IPs", "prefix", resourceGroup)
 				}
 			}
@@ -365,13 +432,13 @@ func (p *pool) UpdatePool(job *worker.WarmPoolJob, didSucceed bool, prefixAvaila
 
 	if job.Operations == worker.OperationCreate {
 		if p.isPDPool {
-			p.pendingCreate -= job.ResourceCount * NumIPv4AddrPerPrefix
+			p.pendingCreate -= job.ResourceCount * p.numResourcesPerGroup()
 		} else {
 			p.pendingCreate -= job.ResourceCount
 		}
 	} else if job.Operations == worker.OperationDeleted {
 		if p.isPDPool {
-			p.pendingDelete -= job.ResourceCount * NumIPv4AddrPerPrefix
+			p.pendingDelete -= job.ResourceCount * p.numResourcesPerGroup()
 		} else {
 			p.pendingDelete -= job.ResourceCount
 		}
@@ -424,7 +491,10 @@ func (p *pool) ReconcilePool() *worker.WarmPoolJob {
 		len(p.usedResources), "pending create", p.pendingCreate, "pending delete", p.pendingDelete,
 		"cool down queue", len(p.coolDownQueue), "total resources", totalCreatedResources, "capacity", p.capacity)
 
-	if p.reSyncRequired {
+	if p.reSyncRequired && time.Now().Before(p.reSyncAfter) {
+		log.V(1).Info("deferring the re-sync of the pool initialized from the checkpoint", "re-sync after",
+			p.reSyncAfter)
+	} else if p.reSyncRequired {
 		// If Pending operations are present then we can't re-sync as the upstream
 		// and pool could change during re-sync
 		if p.pendingCreate != 0 || p.pendingDelete != 0 {
@@ -475,7 +545,7 @@ func (p *pool) ReconcilePool() *worker.WarmPoolJob {
 		log.Info("created job to add resources to warm pool", "pendingCreate", p.pendingCreate,
 			"requested count", deviation)
 		if p.isPDPool {
-			return worker.NewWarmPoolCreateJob(p.nodeName, deviation/NumIPv4AddrPerPrefix)
+			return worker.NewWarmPoolCreateJob(p.nodeName, deviation/p.numResourcesPerGroup())
 		}
 		return worker.NewWarmPoolCreateJob(p.nodeName, deviation)
 
@@ -489,7 +559,7 @@ func (p *pool) ReconcilePool() *worker.WarmPoolJob {
 			var freeResourceGroups []string
 			if p.isPDPool {
 				// for prefix IP pool, each resource group is a /28 IPv4 prefix, which contains 16 IPv4 addresses
-				freeResourceGroups = findFreeGroup(p.warmResources, NumIPv4AddrPerPrefix)
+				freeResourceGroups = findFreeGroup(p.warmResources, p.numResourcesPerGroup())
 			} else {
 				// for secondary IP pool, each resource group only contains 1 IPv4 address
 				freeResourceGroups = findFreeGroup(p.warmResources, 1)
@@ -508,6 +578,7 @@ func (p *pool) ReconcilePool() *worker.WarmPoolJob {
 					// Increment pending to the number of resource being deleted, once successfully deleted the count can be decremented
 					p.pendingDelete += len(p.warmResources[groupID])
 					delete(p.warmResources, groupID)
+					delete(p.drainingGroups, groupID)
 				}
 			} else {
 				log.Info("no warm resources to delete", "deviation", deviation, "numToDelete", numToDelete)
@@ -527,10 +598,11 @@ func (p *pool) ReconcilePool() *worker.WarmPoolJob {
 	return &worker.WarmPoolJob{Operations: worker.OperationReconcileNotRequired}
 }
 
-// SetToDraining sets warm pool config to empty, which would force the pool to delete resources.
+// SetToDraining sets warm pool config to empty, which would force the pool to delete resources. The number of
+// addresses per prefix is kept so the prefixes are still deconstructed the same way
 func (p *pool) SetToDraining() *worker.WarmPoolJob {
 	p.lock.Lock()
-	p.warmPoolConfig = &config.WarmPoolConfig{}
+	p.warmPoolConfig = &config.WarmPoolConfig{AddressesPerPrefix: p.warmPoolConfig.AddressesPerPrefix}
 	p.lock.Unlock()
 
 	return p.ReconcilePool()
@@ -563,13 +635,60 @@ func (p *pool) Introspect() IntrospectResponse {
 		warmResources[group] = resourcesCopy
 	}
 
+	var prefixFragmentation *PrefixFragmentation
+	if p.isPDPool {
+		prefixFragmentation = p.getPrefixFragmentation()
+	}
+
 	return IntrospectResponse{
-		UsedResources:    usedResources,
-		WarmResources:    warmResources,
-		CoolingResources: p.coolDownQueue,
+		UsedResources:       usedResources,
+		WarmResources:       warmResources,
+		CoolingResources:    p.coolDownQueue,
+		PrefixFragmentation: prefixFragmentation,
 	}
 }
 
+// getPrefixFragmentation returns the number of free, fragmented and draining prefixes in the prefix IP pool
+func (p *pool) getPrefixFragmentation() *PrefixFragmentation {
+	prefixes := make(map[string]struct{})
+	for groupID := range p.warmResources {
+		prefixes[groupID] = struct{}{}
+	}
+	for _, resource := range p.usedResources {
+		prefixes[resource.GroupID] = struct{}{}
+	}
+	for _, coolDownResource := range p.coolDownQueue {
+		prefixes[coolDownResource.Resource.GroupID] = struct{}{}
+	}
+
+	fragmentation := &PrefixFragmentation{
+		PrefixCount:         len(prefixes),
+		FreePrefixCount:     len(findFreeGroup(p.warmResources, p.numResourcesPerGroup())),
+		DrainingPrefixCount: len(p.drainingGroups),
+	}
+	for _, groupID := range findFragmentedGroups(p.warmResources, p.numResourcesPerGroup()) {
+		fragmentation.FragmentedPrefixCount++
+		fragmentation.FragmentedFreeResourceCount += len(p.warmResources[groupID])
+	}
+	return fragmentation
+}
+
+// numResourcesPerGroup returns the number of addresses handed out from each prefix of the pool
+func (p *pool) numResourcesPerGroup() int {
+	if p.warmPoolConfig != nil && p.warmPoolConfig.AddressesPerPrefix > 0 {
+		return p.warmPoolConfig.AddressesPerPrefix
+	}
+	return NumIPv4AddrPerPrefix
+}
+
+// deconstructPrefix deconstructs an IPv4 or IPv6 prefix into the list of addresses managed by the pool
+func (p *pool) deconstructPrefix(prefix string) ([]string, error) {
+	if utils.IsIPv6Prefix(prefix) {
+		return utils.DeconstructIPv6AddressesFromPrefix(prefix, p.numResourcesPerGroup())
+	}
+	return utils.DeconstructIPsFromPrefix(prefix)
+}
+
 // findFreeGroup finds groups that have all possible resources free to be allocated or deleted, and returns their group ids
 func findFreeGroup(resourceGroups map[string][]Resource, numResourcesPerGroup int) (freeGroupIDs []string) {
 	for groupID, resources := range resourceGroups {
@@ -580,12 +699,34 @@ func findFreeGroup(resourceGroups map[string][]Resource, numResourcesPerGroup in
 	return
 }
 
-// findMinGroup finds the resource group with the fewest resources, and returns the group id along with its count of resources
-func findMinGroup(resourceGroups map[string][]Resource) (group string, minCount int) {
+// findFragmentedGroups finds groups that have some but not all of their resources free, and returns their group ids
+// sorted by the number of free resources in descending order
+func findFragmentedGroups(resourceGroups map[string][]Resource, numResourcesPerGroup int) (fragmentedGroupIDs []string) {
+	for groupID, resources := range resourceGroups {
+		if len(resources) > 0 && len(resources) < numResourcesPerGroup {
+			fragmentedGroupIDs = append(fragmentedGroupIDs, groupID)
+		}
+	}
+	sort.Slice(fragmentedGroupIDs, func(i, j int) bool {
+		numFreeI, numFreeJ := len(resourceGroups[fragmentedGroupIDs[i]]), len(resourceGroups[fragmentedGroupIDs[j]])
+		if numFreeI != numFreeJ {
+			return numFreeI > numFreeJ
+		}
+		return fragmentedGroupIDs[i] < fragmentedGroupIDs[j]
+	})
+	return
+}
+
+// findMinGroup finds the resource group with the fewest resources, skipping the excluded groups, and returns the group
+// id along with its count of resources
+func findMinGroup(resourceGroups map[string][]Resource, excludedGroups map[string]struct{}) (group string, minCount int) {
 	for groupID, resources := range resourceGroups {
 		if resources == nil || len(resources) == 0 {
 			continue
 		}
+		if _, isExcluded := excludedGroups[groupID]; isExcluded {
+			continue
+		}
 
 		// initialize return values as the first key-value pair; when lower count is found, record the group and minCount
 		if group == "" || len(resources) < minCount {
@@ -596,9 +737,14 @@ func findMinGroup(resourceGroups map[string][]Resource) (group string, minCount
 	return
 }
 
-// assignResourceFromMinGroup assigns a resource from the resource group that has the lowest number of resources
+// assignResourceFromMinGroup assigns a resource from the resource group that has the lowest number of resources. The
+// groups being drained are only used when no other group has a resource available
 func (p *pool) assignResourceFromMinGroup() Resource {
-	groupID, resourceCount := findMinGroup(p.warmResources)
+	groupID, resourceCount := findMinGroup(p.warmResources, p.drainingGroups)
+	if groupID == "" && len(p.drainingGroups) > 0 {
+		p.log.V(1).Info("no resource available outside the draining prefixes, assigning from draining prefix")
+		groupID, resourceCount = findMinGroup(p.warmResources, nil)
+	}
 	if groupID == "" || resourceCount == 0 {
 		return Resource{}
 	}
@@ -636,6 +782,7 @@ func (p *pool) assignResourceFromAnyGroup() Resource {
 // getPDDeviation returns the deviation in number of IPv4 addresses when PD is enabled, taking into account of all PD configuration params
 func (p *pool) getPDDeviation() int {
 	deviationPrefix := 0
+	numResourcesPerGroup := p.numResourcesPerGroup()
 
 	var isWarmIPTargetDefined, isMinIPTargetDefined, isWarmPrefixTargetDefined bool
 
@@ -670,32 +817,36 @@ func (p *pool) getPDDeviation() int {
 	}
 
 	numExistingWarmResources := numResourcesFromMap(p.warmResources)
-	freePrefixes := findFreeGroup(p.warmResources, NumIPv4AddrPerPrefix)
+	freePrefixes := findFreeGroup(p.warmResources, numResourcesPerGroup)
 
 	// if neither WarmIPTarget nor MinIPTarget defined but WarmPrefixTarget is defined, return deviation needed for warm prefix target
 	if !isWarmIPTargetDefined && !isMinIPTargetDefined && isWarmPrefixTargetDefined {
-		deviationPrefix = p.warmPoolConfig.WarmPrefixTarget - len(freePrefixes) - utils.CeilDivision(p.pendingCreate, NumIPv4AddrPerPrefix)
+		deviationPrefix = p.warmPoolConfig.WarmPrefixTarget - len(freePrefixes) - utils.CeilDivision(p.pendingCreate, numResourcesPerGroup)
+
+		// the free resources of the fragmented prefixes don't count towards the warm prefix target, so every
+		// prefix worth of them is surplus that can be compacted
+		p.updateDrainingGroups(p.numFragmentedFreeResources() / numResourcesPerGroup)
 
 		if deviationPrefix != 0 {
 			p.log.Info("calculating IP deviation for prefix pool to satisfy warm prefix target", "warm prefix target",
 				p.warmPoolConfig.WarmPrefixTarget, "numFreePrefix", len(freePrefixes), "p.pendingCreate", p.pendingCreate,
-				"p.pendingDelete", p.pendingDelete, "deviation", deviationPrefix*NumIPv4AddrPerPrefix)
+				"p.pendingDelete", p.pendingDelete, "deviation", deviationPrefix*numResourcesPerGroup)
 		}
 
-		return deviationPrefix * NumIPv4AddrPerPrefix
+		return deviationPrefix * numResourcesPerGroup
 	}
 
 	// total resources in pool include used resources, warm resources, pendingCreate (to avoid duplicate request), and coolDownQueue
 	numTotalResources := len(p.usedResources) + p.pendingCreate + numExistingWarmResources + len(p.coolDownQueue)
 	// number of existing prefixes
-	numCurrPrefix := utils.CeilDivision(numTotalResources, 16)
+	numCurrPrefix := utils.CeilDivision(numTotalResources, numResourcesPerGroup)
 
 	// number of total resources required to meet WarmIPTarget
 	numTotalResForWarmIPTarget := len(p.usedResources) + p.warmPoolConfig.WarmIPTarget
 	// number of total prefixes required to meet WarmIPTarget
-	numPrefixForWarmIPTarget := utils.CeilDivision(numTotalResForWarmIPTarget, 16)
+	numPrefixForWarmIPTarget := utils.CeilDivision(numTotalResForWarmIPTarget, numResourcesPerGroup)
 	// number of prefixes to meet MinIPTarget
-	numPrefixForMinIPTarget := utils.CeilDivision(p.warmPoolConfig.MinIPTarget, 16)
+	numPrefixForMinIPTarget := utils.CeilDivision(p.warmPoolConfig.MinIPTarget, numResourcesPerGroup)
 
 	if numPrefixForWarmIPTarget >= numPrefixForMinIPTarget {
 		// difference is the number of prefixes to create or delete
@@ -705,6 +856,9 @@ func (p *pool) getPDDeviation() int {
 		deviationPrefix = numPrefixForMinIPTarget - numCurrPrefix
 	}
 
+	// the surplus prefixes that can't be deleted right away as they are fragmented are drained when compaction is enabled
+	p.updateDrainingGroups(max(-deviationPrefix-len(freePrefixes), 0))
+
 	// if we need to delete prefixes, should check if any prefix is free to be deleted since they can be fragmented. If not, reset deviation
 	if deviationPrefix < 0 && len(freePrefixes) == 0 {
 		deviationPrefix = 0
@@ -715,10 +869,69 @@ func (p *pool) getPDDeviation() int {
 			"used resources", len(p.usedResources), "existing warm resources", numExistingWarmResources, "pendingCreate", p.pendingCreate,
 			"pendingDelete", p.pendingDelete, "numTotalResources", numTotalResources, "numCurrPrefix", numCurrPrefix,
 			"numTotalResForWarmIPTarget", numTotalResForWarmIPTarget, "numPrefixForWarmIPTarget", numPrefixForWarmIPTarget,
-			"numPrefixForMinIPTarget", numPrefixForMinIPTarget, "deviation", deviationPrefix*NumIPv4AddrPerPrefix)
+			"numPrefixForMinIPTarget", numPrefixForMinIPTarget, "deviation", deviationPrefix*numResourcesPerGroup)
+	}
+
+	return deviationPrefix * numResourcesPerGroup
+}
+
+// numFragmentedFreeResources returns the number of free resources in the prefixes that are partially used
+func (p *pool) numFragmentedFreeResources() int {
+	count := 0
+	for _, groupID := range findFragmentedGroups(p.warmResources, p.numResourcesPerGroup()) {
+		count += len(p.warmResources[groupID])
+	}
+	return count
+}
+
+// updateDrainingGroups marks up to the given number of fragmented prefixes with the most free resources for draining
+// when prefix compaction is enabled. Prefixes that no longer need to be drained, have been released or have all their
+// resources free are unmarked, the free prefixes are then released by the deviation like any other free prefix
+func (p *pool) updateDrainingGroups(numToDrain int) {
+	if !p.warmPoolConfig.PrefixCompaction || p.warmPoolConfig.DesiredSize == 0 {
+		numToDrain = 0
+	}
+	if p.drainingGroups == nil {
+		p.drainingGroups = make(map[string]struct{})
+	}
+
+	for groupID := range p.drainingGroups {
+		if resources, found := p.warmResources[groupID]; !found || len(resources) == p.numResourcesPerGroup() {
+			delete(p.drainingGroups, groupID)
+		}
 	}
 
-	return deviationPrefix * NumIPv4AddrPerPrefix
+	// Keep draining the prefixes closest to being free, the rest of the prefixes are fragmented prefixes that are not
+	// yet marked for draining
+	var candidates, draining []string
+	for _, groupID := range findFragmentedGroups(p.warmResources, p.numResourcesPerGroup()) {
+		if _, isDraining := p.drainingGroups[groupID]; isDraining {
+			draining = append(draining, groupID)
+		} else {
+			candidates = append(candidates, groupID)
+		}
+	}
+	// Fully used prefixes being drained are the furthest from being free
+	for groupID := range p.drainingGroups {
+		if len(p.warmResources[groupID]) == 0 {
+			draining = append(draining, groupID)
+		}
+	}
+
+	for len(draining) > numToDrain {
+		groupID := draining[len(draining)-1]
+		draining = draining[:len(draining)-1]
+		delete(p.drainingGroups, groupID)
+		p.log.Info("stopped draining prefix", "group id", groupID)
+	}
+	for _, groupID := range candidates {
+		if len(p.drainingGroups) >= numToDrain {
+			break
+		}
+		p.drainingGroups[groupID] = struct{}{}
+		p.log.Info("draining fragmented prefix", "group id", groupID,
+			"# free resources", len(p.warmResources[groupID]))
+	}
 }
 
 // calculateSecondaryIPDeviation calculates the deviation required to meet the desired state for secondary IP mode
@@ -14,11 +14,13 @@
 package pool
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
 	"time"
 
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/lookup"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/worker"
 
 	"github.com/stretchr/testify/assert"
@@ -127,16 +129,68 @@ func getMockPool(poolConfig *config.WarmPoolConfig, usedResources map[string]Res
 		coolDownQueue:  []CoolDownResource{},
 		capacity:       capacity,
 		isPDPool:       isPDPool,
+		index:          lookup.NewIndex(time.Hour),
 	}
 
 	return pool
 }
 
 func TestPool_NewResourcePool(t *testing.T) {
-	pool := NewResourcePool(zap.New(), poolConfig, usedResources, warmPoolResources, nodeName, 5, false)
+	pool := NewResourcePool(zap.New(), poolConfig, usedResources, warmPoolResources, nodeName, 5, false, nil)
 	assert.NotNil(t, pool)
 }
 
+// TestPool_NewResourcePoolFromCheckpoint tests the cooling resources are moved from the warm resources to the cool
+// down queue in order of deletion and the pool is re-synced on the first reconcile after the verify delay
+func TestPool_NewResourcePoolFromCheckpoint(t *testing.T) {
+	now := time.Now()
+	warmResources := map[string][]Resource{
+		res3: {{GroupID: res3, ResourceID: res3}},
+		res4: {{GroupID: res4, ResourceID: res4}},
+		res5: {{GroupID: res5, ResourceID: res5}},
+	}
+	coolingResources := []CoolDownResource{
+		{Resource: Resource{GroupID: res5, ResourceID: res5}, DeletionTimestamp: now},
+		{Resource: Resource{GroupID: res4, ResourceID: res4}, DeletionTimestamp: now.Add(-time.Second)},
+		// Not a warm resource, ignored
+		{Resource: Resource{GroupID: res6, ResourceID: res6}, DeletionTimestamp: now},
+	}
+
+	warmPool := NewResourcePoolFromCheckpoint(zap.New(), poolConfig, usedResources, warmResources, coolingResources,
+		nodeName, 5, false, nil).(*pool)
+
+	assert.Equal(t, []CoolDownResource{coolingResources[1], coolingResources[0]}, warmPool.coolDownQueue)
+	assert.Equal(t, []Resource{{GroupID: res3, ResourceID: res3}}, warmPool.warmResources[res3])
+	assert.Empty(t, warmPool.warmResources[res4])
+	assert.Empty(t, warmPool.warmResources[res5])
+	assert.True(t, warmPool.reSyncRequired)
+	assert.WithinRange(t, warmPool.reSyncAfter, now.Add(config.CheckpointVerifyDelay),
+		time.Now().Add(config.CheckpointVerifyDelay*2))
+
+	// The checkpointed resources are served till the re-sync
+	job := warmPool.ReconcilePool()
+	assert.NotEqual(t, worker.OperationReSyncPool, job.Operations)
+	assert.True(t, warmPool.reSyncRequired)
+
+	warmPool.reSyncAfter = time.Now().Add(-time.Second)
+	job = warmPool.ReconcilePool()
+	assert.Equal(t, worker.NewWarmPoolReSyncJob(nodeName), job)
+}
+
+// TestPool_UpdatePool_Failed_CheckpointReSyncNotDeferred tests the pool is re-synced on the next reconcile if a job
+// fails before the re-sync of the checkpoint
+func TestPool_UpdatePool_Failed_CheckpointReSyncNotDeferred(t *testing.T) {
+	warmPool := getMockPool(poolConfig, usedResources, map[string][]Resource{}, 3, false)
+	warmPool.nodeName = nodeName
+	warmPool.reSyncRequired = true
+	warmPool.reSyncAfter = time.Now().Add(time.Minute)
+	warmPool.pendingCreate = 2
+
+	warmPool.UpdatePool(&worker.WarmPoolJob{Operations: worker.OperationCreate, ResourceCount: 2}, false, false)
+
+	assert.Equal(t, worker.NewWarmPoolReSyncJob(nodeName), warmPool.ReconcilePool())
+}
+
 // TestPool_AssignResource tests resource is allocated to pod if present in the warm pool
 func TestPool_AssignResource(t *testing.T) {
 	warmPool := getMockPool(poolConfig, usedResources, warmPoolResources, 5, false)
@@ -204,6 +258,26 @@ func TestPool_FreeResource(t *testing.T) {
 	assert.Equal(t, res2, warmPool.coolDownQueue[0].Resource.ResourceID)
 }
 
+// TestPool_AssignResource_FreeResource_LookupIndex tests the owner of the resource is recorded in the lookup index on
+// assignment and kept after the resource is freed
+func TestPool_AssignResource_FreeResource_LookupIndex(t *testing.T) {
+	warmPool := getMockPool(poolConfig, usedResources, warmPoolResources, 5, false)
+	warmPool.nodeName = nodeName
+
+	resourceID, _, err := warmPool.AssignResource(pod3)
+	assert.NoError(t, err)
+	owner, ok := warmPool.index.Get(resourceID)
+	assert.True(t, ok)
+	assert.Equal(t, lookup.Owner{NodeName: nodeName, PodUID: pod3}, owner)
+
+	_, err = warmPool.FreeResource(pod3, resourceID)
+	assert.NoError(t, err)
+	owner, ok = warmPool.index.Get(resourceID)
+	assert.True(t, ok)
+	assert.Equal(t, pod3, owner.PodUID)
+	assert.False(t, owner.ReleasedAt.IsZero())
+}
+
 // TestPool_FreeResource_isNotAssigned test error is returned if trying to free a resource that doesn't belong to any
 // owner
 func TestPool_FreeResource_isNotAssigned(t *testing.T) {
@@ -245,6 +319,28 @@ func TestPool_UpdatePool_OperationCreate_Succeed(t *testing.T) {
 	assert.False(t, shouldReconcile)
 }
 
+// TestPool_UpdatePool_OperationCreate_IPv6Prefix tests IPv6 prefixes are deconstructed into the configured number of
+// addresses when added to the warm pool
+func TestPool_UpdatePool_OperationCreate_IPv6Prefix(t *testing.T) {
+	ipv6PoolConfig := *poolConfig
+	ipv6PoolConfig.AddressesPerPrefix = 8
+	warmPool := getMockPool(&ipv6PoolConfig, map[string]Resource{}, map[string][]Resource{}, 32, true)
+	warmPool.pendingCreate = ipv6PoolConfig.AddressesPerPrefix
+
+	ipv6Prefix := "2600:1f14:abc:de00:1234::/80"
+	shouldReconcile := warmPool.UpdatePool(&worker.WarmPoolJob{
+		Operations:    worker.OperationCreate,
+		Resources:     []string{ipv6Prefix},
+		ResourceCount: 1,
+	}, true, true)
+
+	assert.False(t, shouldReconcile)
+	assert.Equal(t, 0, warmPool.pendingCreate)
+	assert.Len(t, warmPool.warmResources[ipv6Prefix], ipv6PoolConfig.AddressesPerPrefix)
+	assert.Equal(t, Resource{GroupID: ipv6Prefix, ResourceID: "2600:1f14:abc:de00:1234::1/128"},
+		warmPool.warmResources[ipv6Prefix][0])
+}
+
 // TestPool_UpdatePool_OperationCreate_Failed tests that reconciler is triggered when create operation fails
 func TestPool_UpdatePool_OperationCreate_Failed(t *testing.T) {
 	warmPool := getMockPool(poolConfig, usedResources, map[string][]Resource{}, 3, false)
@@ -659,12 +755,12 @@ func TestFindFreeGroup(t *testing.T) {
 
 func TestFindMinGroup(t *testing.T) {
 	// should not return grp4 since empty resource group is ignored
-	groupID, count := findMinGroup(warmPoolResourcesPrefix)
+	groupID, count := findMinGroup(warmPoolResourcesPrefix, nil)
 	assert.Equal(t, grp1, groupID)
 	assert.Equal(t, 1, count)
 
 	// since grp5 and grp6 have same number of resources, it will return one of them
-	_, count = findMinGroup(warmPoolResourcesSameCount)
+	_, count = findMinGroup(warmPoolResourcesSameCount, nil)
 	assert.Equal(t, 2, count)
 }
 
@@ -1132,3 +1228,118 @@ func TestCalcSecondaryIPDeviation_MinIPTargetAndWarmIPTargetSet_ExpectedDeviatio
 		})
 	}
 }
+
+// getPrefixResources returns the resources of the prefix with the given group id from start to end index
+func getPrefixResources(groupID string, start, end int) []Resource {
+	var resources []Resource
+	for i := start; i < end; i++ {
+		resources = append(resources, Resource{GroupID: groupID, ResourceID: fmt.Sprintf("%s-res-%d", groupID, i)})
+	}
+	return resources
+}
+
+// getFragmentedPrefixPool returns a prefix pool with grp1 having 6 used and 10 free resources and grp2 having 4 used and
+// 12 free resources
+func getFragmentedPrefixPool(poolConfig *config.WarmPoolConfig) *pool {
+	used := make(map[string]Resource)
+	for i, resource := range append(getPrefixResources(grp1, 0, 6), getPrefixResources(grp2, 0, 4)...) {
+		used[fmt.Sprintf("pod-%d", i)] = resource
+	}
+	warm := map[string][]Resource{
+		grp1: getPrefixResources(grp1, 6, 16),
+		grp2: getPrefixResources(grp2, 4, 16),
+	}
+	return getMockPool(poolConfig, used, warm, 224, true)
+}
+
+func TestFindFragmentedGroups(t *testing.T) {
+	// grp3 has the most free resources, grp4 with no free resources and grp7 with all resources free are not fragmented
+	groupIDs := findFragmentedGroups(warmPoolResourcesPrefix, NumIPv4AddrPerPrefix)
+	assert.Equal(t, []string{grp3, grp2, grp1}, groupIDs)
+}
+
+func TestAssignResourceFromMinGroup_DrainingGroup(t *testing.T) {
+	warmPool := getMockPool(poolConfig, nil, warmPoolResourcesSameCount, 7, true)
+	warmPool.drainingGroups = map[string]struct{}{grp5: {}}
+
+	// grp5 is skipped till grp6 has no resources left
+	assert.Equal(t, grp6, warmPool.assignResourceFromMinGroup().GroupID)
+	assert.Equal(t, grp6, warmPool.assignResourceFromMinGroup().GroupID)
+	assert.Equal(t, grp5, warmPool.assignResourceFromMinGroup().GroupID)
+}
+
+// TestPool_ReconcilePool_PrefixCompaction tests the fragmented prefix with the most free resources is drained when
+// there are more prefixes than required and is released once all its resources are freed
+func TestPool_ReconcilePool_PrefixCompaction(t *testing.T) {
+	pdPool := getFragmentedPrefixPool(&config.WarmPoolConfig{DesiredSize: 1, WarmIPTarget: 1, PrefixCompaction: true})
+
+	// 11 IPs needed for the used resources and warm IP target fit in 1 prefix, but no prefix is free to be deleted
+	job := pdPool.ReconcilePool()
+	assert.Equal(t, worker.OperationReconcileNotRequired, job.Operations)
+	assert.Equal(t, map[string]struct{}{grp2: {}}, pdPool.drainingGroups)
+
+	// New resources are not assigned from the draining prefix
+	resourceID, _, err := pdPool.AssignResource(pod1)
+	assert.NoError(t, err)
+	assert.Equal(t, Resource{GroupID: grp1, ResourceID: resourceID}, pdPool.usedResources[pod1])
+
+	// All the resources of the draining prefix are freed
+	for requesterID, resource := range pdPool.usedResources {
+		if resource.GroupID == grp2 {
+			delete(pdPool.usedResources, requesterID)
+			pdPool.warmResources[grp2] = append(pdPool.warmResources[grp2], resource)
+		}
+	}
+
+	job = pdPool.ReconcilePool()
+	assert.Equal(t, worker.NewWarmPoolDeleteJob("", []string{grp2}), job)
+	assert.Empty(t, pdPool.drainingGroups)
+}
+
+// TestPool_getPDDeviation_PrefixCompaction_Disabled tests no prefix is drained if compaction is disabled
+func TestPool_getPDDeviation_PrefixCompaction_Disabled(t *testing.T) {
+	pdPool := getFragmentedPrefixPool(&config.WarmPoolConfig{DesiredSize: 1, WarmIPTarget: 1})
+
+	assert.Equal(t, 0, pdPool.getPDDeviation())
+	assert.Empty(t, pdPool.drainingGroups)
+}
+
+// TestPool_getPDDeviation_PrefixCompaction_NeedMore tests the draining prefixes are unmarked once the pool needs the
+// resources again
+func TestPool_getPDDeviation_PrefixCompaction_NeedMore(t *testing.T) {
+	pdPool := getFragmentedPrefixPool(&config.WarmPoolConfig{DesiredSize: 1, WarmIPTarget: 1, PrefixCompaction: true})
+	pdPool.drainingGroups = map[string]struct{}{grp2: {}}
+	pdPool.warmPoolConfig.WarmIPTarget = 30
+
+	assert.Equal(t, 16, pdPool.getPDDeviation())
+	assert.Empty(t, pdPool.drainingGroups)
+}
+
+// TestPool_getPDDeviation_PrefixCompaction_WarmPrefixTarget tests a prefix is drained for every prefix worth of free
+// resources in the fragmented prefixes when only the warm prefix target is set
+func TestPool_getPDDeviation_PrefixCompaction_WarmPrefixTarget(t *testing.T) {
+	pdPool := getFragmentedPrefixPool(&config.WarmPoolConfig{DesiredSize: 1, WarmPrefixTarget: 1,
+		PrefixCompaction: true})
+
+	// 22 free resources in the fragmented prefixes
+	assert.Equal(t, 16, pdPool.getPDDeviation())
+	assert.Equal(t, map[string]struct{}{grp2: {}}, pdPool.drainingGroups)
+}
+
+// TestPool_Introspect_PrefixFragmentation tests the fragmentation of the prefix pool is returned on introspection
+func TestPool_Introspect_PrefixFragmentation(t *testing.T) {
+	pdPool := getFragmentedPrefixPool(&config.WarmPoolConfig{DesiredSize: 1, WarmIPTarget: 1, PrefixCompaction: true})
+	pdPool.warmResources[grp3] = getPrefixResources(grp3, 0, 16)
+	pdPool.drainingGroups = map[string]struct{}{grp2: {}}
+
+	assert.Equal(t, &PrefixFragmentation{
+		PrefixCount:                 3,
+		FreePrefixCount:             1,
+		FragmentedPrefixCount:       2,
+		FragmentedFreeResourceCount: 22,
+		DrainingPrefixCount:         1,
+	}, pdPool.Introspect().PrefixFragmentation)
+
+	// Secondary IP pool doesn't have prefixes
+	assert.Nil(t, getMockPool(poolConfig, usedResources, warmPoolResources, 5, false).Introspect().PrefixFragmentation)
+}
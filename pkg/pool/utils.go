@@ -14,8 +14,14 @@
 package pool
 
 import (
+	"sort"
+	"time"
+
 	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 
+	"github.com/aws/amazon-vpc-resource-controller-k8s/apis/vpcresources/v1alpha1"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/api"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
 )
@@ -37,3 +43,157 @@ func GetWinWarmPoolConfig(log logr.Logger, w api.Wrapper, isPDEnabled bool) *con
 		return resourceConfig[config.ResourceNameIPAddress].WarmPoolConfig
 	}
 }
+
+// GetNodeWarmPoolConfig retrieves Windows warmpool configuration from ConfigMap and overrides the targets with the
+// optional per node targets set on the node's CNINode
+func GetNodeWarmPoolConfig(log logr.Logger, w api.Wrapper, nodeName string, isPDEnabled bool) *config.WarmPoolConfig {
+	warmPoolConfig := GetWinWarmPoolConfig(log, w, isPDEnabled)
+
+	cniNode, err := w.K8sAPI.GetCNINode(types.NamespacedName{Name: nodeName})
+	if err != nil {
+		log.V(1).Info("failed to get CNINode, will use cluster wide warm pool config", "node name", nodeName,
+			"error", err.Error())
+		return warmPoolConfig
+	}
+
+	return MergeCNINodeWarmPoolConfig(log, warmPoolConfig, cniNode.Spec, isPDEnabled)
+}
+
+// MergeCNINodeWarmPoolConfig returns a copy of the warm pool configuration with the targets set on the CNINode spec
+// taking precedence over the cluster wide targets
+func MergeCNINodeWarmPoolConfig(log logr.Logger, warmPoolConfig *config.WarmPoolConfig, spec v1alpha1.CNINodeSpec,
+	isPDEnabled bool) *config.WarmPoolConfig {
+	if spec.WarmIPTarget == nil && spec.MinIPTarget == nil && spec.WarmPrefixTarget == nil {
+		return warmPoolConfig
+	}
+
+	merged := *warmPoolConfig
+	if spec.WarmIPTarget != nil {
+		merged.WarmIPTarget = *spec.WarmIPTarget
+	}
+	if spec.MinIPTarget != nil {
+		merged.MinIPTarget = *spec.MinIPTarget
+	}
+	if isPDEnabled && spec.WarmPrefixTarget != nil {
+		merged.WarmPrefixTarget = *spec.WarmPrefixTarget
+	}
+
+	if !isPDEnabled && merged.WarmIPTarget == 0 {
+		// In secondary IP mode there must always be 1 warm IP to ensure that the warmpool is never empty
+		log.Info("Explicitly setting WarmIPTarget zero value not supported in secondary IP mode, will override with 1")
+		merged.WarmIPTarget = 1
+	}
+	if isPDEnabled && merged.WarmIPTarget == 0 && merged.MinIPTarget == 0 && merged.WarmPrefixTarget == 0 {
+		// On demand IP allocation is not supported, fall back to the cluster wide targets
+		log.Info("Encountered zero values for all targets on CNINode, will use cluster wide warm pool config")
+		return warmPoolConfig
+	}
+
+	log.V(1).Info("applied warm pool targets from CNINode", "warmIPTarget", merged.WarmIPTarget,
+		"minIPTarget", merged.MinIPTarget, "warmPrefixTarget", merged.WarmPrefixTarget)
+	return &merged
+}
+
+// GetNodeCheckpoint returns the checkpoint of the node's resources from the node's CNINode, nil is returned if the node
+// has no checkpoint or the checkpoint was taken for a different instance
+func GetNodeCheckpoint(log logr.Logger, w api.Wrapper, nodeName string, instanceID string) *v1alpha1.CNINodeCheckpoint {
+	cniNode, err := w.K8sAPI.GetCNINode(types.NamespacedName{Name: nodeName})
+	if err != nil {
+		log.V(1).Info("failed to get CNINode, will initialize from EC2", "node name", nodeName, "error", err.Error())
+		return nil
+	}
+
+	checkpoint := cniNode.Status.Checkpoint
+	if checkpoint == nil {
+		return nil
+	}
+	if checkpoint.InstanceID != instanceID {
+		log.Info("ignoring checkpoint taken for a different instance", "node name", nodeName,
+			"instance ID", instanceID, "checkpoint instance ID", checkpoint.InstanceID)
+		return nil
+	}
+	return checkpoint
+}
+
+// GetIPv4PoolCheckpoint returns the checkpoint of the node's pool for the given resource, nil is returned if the node
+// has no valid checkpoint of the pool
+func GetIPv4PoolCheckpoint(log logr.Logger, w api.Wrapper, nodeName string, instanceID string,
+	resourceName string) *v1alpha1.IPv4PoolCheckpoint {
+	checkpoint := GetNodeCheckpoint(log, w, nodeName, instanceID)
+	if checkpoint == nil {
+		return nil
+	}
+	poolCheckpoint, found := checkpoint.IPv4Pools[resourceName]
+	if !found {
+		return nil
+	}
+	return &poolCheckpoint
+}
+
+// NewIPv4PoolCheckpoint returns the checkpoint of the pool state along with the ENIs the resources are assigned to
+func NewIPv4PoolCheckpoint(state IntrospectResponse, enis []v1alpha1.ENICheckpoint) v1alpha1.IPv4PoolCheckpoint {
+	checkpoint := v1alpha1.IPv4PoolCheckpoint{ENIs: enis}
+	if len(state.UsedResources) > 0 {
+		checkpoint.UsedResources = make(map[string]v1alpha1.PoolResource, len(state.UsedResources))
+		for requesterID, resource := range state.UsedResources {
+			checkpoint.UsedResources[requesterID] = toPoolResource(resource)
+		}
+	}
+	for _, resources := range state.WarmResources {
+		for _, resource := range resources {
+			checkpoint.WarmResources = append(checkpoint.WarmResources, toPoolResource(resource))
+		}
+	}
+	// Sort the warm resources so the checkpoint only changes when the state does
+	sort.Slice(checkpoint.WarmResources, func(i, j int) bool {
+		return checkpoint.WarmResources[i].ResourceID < checkpoint.WarmResources[j].ResourceID
+	})
+	for _, coolingResource := range state.CoolingResources {
+		// The deletion time is serialized with a precision of seconds
+		checkpoint.CoolingResources = append(checkpoint.CoolingResources, v1alpha1.CoolingPoolResource{
+			PoolResource:      toPoolResource(coolingResource.Resource),
+			DeletionTimestamp: metav1.NewTime(coolingResource.DeletionTimestamp).Rfc3339Copy(),
+		})
+	}
+	return checkpoint
+}
+
+// GetCheckpointCoolingResources returns the resources to cool down on initializing the pool from the checkpoint, which
+// are the resources that were cooling down and the resources of the pods deleted since the checkpoint was taken. The
+// resources used by running pods are never cooled down
+func GetCheckpointCoolingResources(checkpoint v1alpha1.IPv4PoolCheckpoint,
+	usedResources map[string]Resource) []CoolDownResource {
+	inUse := make(map[Resource]struct{}, len(usedResources))
+	for _, resource := range usedResources {
+		inUse[resource] = struct{}{}
+	}
+
+	var coolingResources []CoolDownResource
+	for _, coolingResource := range checkpoint.CoolingResources {
+		resource := toResource(coolingResource.PoolResource)
+		if _, found := inUse[resource]; !found {
+			coolingResources = append(coolingResources, CoolDownResource{Resource: resource,
+				DeletionTimestamp: coolingResource.DeletionTimestamp.Time})
+		}
+	}
+	for requesterID, usedResource := range checkpoint.UsedResources {
+		resource := toResource(usedResource)
+		if _, isRunning := usedResources[requesterID]; isRunning {
+			continue
+		}
+		if _, found := inUse[resource]; !found {
+			// The actual deletion time of the pod is not known
+			coolingResources = append(coolingResources, CoolDownResource{Resource: resource,
+				DeletionTimestamp: time.Now()})
+		}
+	}
+	return coolingResources
+}
+
+func toPoolResource(resource Resource) v1alpha1.PoolResource {
+	return v1alpha1.PoolResource{GroupID: resource.GroupID, ResourceID: resource.ResourceID}
+}
+
+func toResource(resource v1alpha1.PoolResource) Resource {
+	return Resource{GroupID: resource.GroupID, ResourceID: resource.ResourceID}
+}
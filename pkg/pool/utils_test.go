@@ -17,12 +17,16 @@ import (
 	"fmt"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
+	"github.com/aws/amazon-vpc-resource-controller-k8s/apis/vpcresources/v1alpha1"
 	mock_k8s "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/k8s"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/api"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
@@ -110,3 +114,192 @@ func TestGetWinWarmPoolConfig_PDDisabledAndAPICallFailure_ReturnsDefaultConfig(t
 	actualWarmPoolConfig := GetWinWarmPoolConfig(log, apiWrapperMock, false)
 	assert.Equal(t, expectedWarmPoolConfig, actualWarmPoolConfig)
 }
+
+func TestGetNodeWarmPoolConfig_CNINodeTargets_OverridesConfig(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	log := zap.New(zap.UseDevMode(true)).WithName("provider test")
+
+	warmIPTarget, minIPTarget, warmPrefixTarget := 5, 10, 1
+	cniNode := &v1alpha1.CNINode{
+		Spec: v1alpha1.CNINodeSpec{
+			WarmIPTarget:     &warmIPTarget,
+			MinIPTarget:      &minIPTarget,
+			WarmPrefixTarget: &warmPrefixTarget,
+		},
+	}
+	expectedWarmPoolConfig := &config.WarmPoolConfig{
+		WarmIPTarget: warmIPTarget,
+		MinIPTarget:  minIPTarget,
+		DesiredSize:  config.IPv4DefaultWinWarmIPTarget,
+	}
+
+	mockK8sWrapper := mock_k8s.NewMockK8sWrapper(ctrl)
+	mockK8sWrapper.EXPECT().GetConfigMap(config.VpcCniConfigMapName, config.KubeSystemNamespace).Return(&v1.ConfigMap{}, nil)
+	mockK8sWrapper.EXPECT().GetCNINode(types.NamespacedName{Name: "node-1"}).Return(cniNode, nil)
+	apiWrapperMock := api.Wrapper{K8sAPI: mockK8sWrapper}
+
+	// Warm prefix target is ignored in secondary IP mode
+	actualWarmPoolConfig := GetNodeWarmPoolConfig(log, apiWrapperMock, "node-1", false)
+	assert.Equal(t, expectedWarmPoolConfig, actualWarmPoolConfig)
+}
+
+func TestGetNodeWarmPoolConfig_CNINodeNotFound_ReturnsConfig(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	log := zap.New(zap.UseDevMode(true)).WithName("provider test")
+
+	expectedWarmPoolConfig := &config.WarmPoolConfig{
+		WarmIPTarget: config.IPv4DefaultWinWarmIPTarget,
+		MinIPTarget:  config.IPv4DefaultWinMinIPTarget,
+		DesiredSize:  config.IPv4DefaultWinWarmIPTarget,
+	}
+
+	mockK8sWrapper := mock_k8s.NewMockK8sWrapper(ctrl)
+	mockK8sWrapper.EXPECT().GetConfigMap(config.VpcCniConfigMapName, config.KubeSystemNamespace).Return(&v1.ConfigMap{}, nil)
+	mockK8sWrapper.EXPECT().GetCNINode(types.NamespacedName{Name: "node-1"}).Return(nil, fmt.Errorf("not found"))
+	apiWrapperMock := api.Wrapper{K8sAPI: mockK8sWrapper}
+
+	actualWarmPoolConfig := GetNodeWarmPoolConfig(log, apiWrapperMock, "node-1", false)
+	assert.Equal(t, expectedWarmPoolConfig, actualWarmPoolConfig)
+}
+
+func TestMergeCNINodeWarmPoolConfig(t *testing.T) {
+	log := zap.New(zap.UseDevMode(true)).WithName("provider test")
+	zero, two := 0, 2
+
+	pdWarmPoolConfig := &config.WarmPoolConfig{
+		WarmIPTarget:     config.IPv4PDDefaultWarmIPTargetSize,
+		MinIPTarget:      config.IPv4PDDefaultMinIPTargetSize,
+		WarmPrefixTarget: config.IPv4PDDefaultWarmPrefixTargetSize,
+		DesiredSize:      config.IPv4PDDefaultWarmIPTargetSize,
+	}
+
+	tests := []struct {
+		name        string
+		spec        v1alpha1.CNINodeSpec
+		isPDEnabled bool
+		expected    *config.WarmPoolConfig
+	}{
+		{
+			name:        "no targets on CNINode",
+			spec:        v1alpha1.CNINodeSpec{},
+			isPDEnabled: true,
+			expected:    pdWarmPoolConfig,
+		},
+		{
+			name:        "warm prefix target only in PD mode",
+			spec:        v1alpha1.CNINodeSpec{WarmIPTarget: &zero, MinIPTarget: &zero, WarmPrefixTarget: &two},
+			isPDEnabled: true,
+			expected:    &config.WarmPoolConfig{WarmPrefixTarget: two, DesiredSize: config.IPv4PDDefaultWarmIPTargetSize},
+		},
+		{
+			name:        "all zero targets in PD mode falls back to cluster config",
+			spec:        v1alpha1.CNINodeSpec{WarmIPTarget: &zero, MinIPTarget: &zero, WarmPrefixTarget: &zero},
+			isPDEnabled: true,
+			expected:    pdWarmPoolConfig,
+		},
+		{
+			name:        "zero warm IP target in secondary IP mode is overridden with 1",
+			spec:        v1alpha1.CNINodeSpec{WarmIPTarget: &zero, MinIPTarget: &two},
+			isPDEnabled: false,
+			expected: &config.WarmPoolConfig{WarmIPTarget: 1, MinIPTarget: two,
+				WarmPrefixTarget: config.IPv4PDDefaultWarmPrefixTargetSize, DesiredSize: config.IPv4PDDefaultWarmIPTargetSize},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, MergeCNINodeWarmPoolConfig(log, pdWarmPoolConfig, test.spec, test.isPDEnabled))
+		})
+	}
+	// The cluster wide config must not be mutated
+	assert.Equal(t, config.IPv4PDDefaultWarmIPTargetSize, pdWarmPoolConfig.WarmIPTarget)
+}
+
+func TestGetIPv4PoolCheckpoint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	log := zap.New(zap.UseDevMode(true)).WithName("provider test")
+
+	poolCheckpoint := v1alpha1.IPv4PoolCheckpoint{
+		ENIs: []v1alpha1.ENICheckpoint{{ID: "eni-1", Resources: []string{"192.168.1.1"}}},
+	}
+	cniNode := &v1alpha1.CNINode{Status: v1alpha1.CNINodeStatus{Checkpoint: &v1alpha1.CNINodeCheckpoint{
+		InstanceID: "i-1",
+		IPv4Pools:  map[string]v1alpha1.IPv4PoolCheckpoint{config.ResourceNameIPAddress: poolCheckpoint},
+	}}}
+
+	mockK8sWrapper := mock_k8s.NewMockK8sWrapper(ctrl)
+	mockK8sWrapper.EXPECT().GetCNINode(types.NamespacedName{Name: "node-1"}).Return(cniNode, nil).Times(3)
+	mockK8sWrapper.EXPECT().GetCNINode(types.NamespacedName{Name: "node-1"}).Return(nil, fmt.Errorf("not found"))
+	apiWrapperMock := api.Wrapper{K8sAPI: mockK8sWrapper}
+
+	assert.Equal(t, &poolCheckpoint, GetIPv4PoolCheckpoint(log, apiWrapperMock, "node-1", "i-1",
+		config.ResourceNameIPAddress))
+	// The pool of the other resource was not checkpointed
+	assert.Nil(t, GetIPv4PoolCheckpoint(log, apiWrapperMock, "node-1", "i-1", config.ResourceNameIPAddressFromPrefix))
+	// The checkpoint was taken for a different instance with the same node name
+	assert.Nil(t, GetIPv4PoolCheckpoint(log, apiWrapperMock, "node-1", "i-2", config.ResourceNameIPAddress))
+	assert.Nil(t, GetIPv4PoolCheckpoint(log, apiWrapperMock, "node-1", "i-1", config.ResourceNameIPAddress))
+}
+
+func TestNewIPv4PoolCheckpoint(t *testing.T) {
+	deletionTime := time.Date(2024, 1, 1, 0, 0, 0, 500, time.UTC)
+	state := IntrospectResponse{
+		UsedResources: map[string]Resource{"default/pod-1": {GroupID: "192.168.1.0/28", ResourceID: "192.168.1.1"}},
+		WarmResources: map[string][]Resource{
+			"192.168.1.16/28": {{GroupID: "192.168.1.16/28", ResourceID: "192.168.1.17"}},
+			"192.168.1.0/28": {{GroupID: "192.168.1.0/28", ResourceID: "192.168.1.3"},
+				{GroupID: "192.168.1.0/28", ResourceID: "192.168.1.2"}},
+		},
+		CoolingResources: []CoolDownResource{{Resource: Resource{GroupID: "192.168.1.0/28", ResourceID: "192.168.1.4"},
+			DeletionTimestamp: deletionTime}},
+	}
+	enis := []v1alpha1.ENICheckpoint{{ID: "eni-1", Resources: []string{"192.168.1.0/28", "192.168.1.16/28"}}}
+
+	expected := v1alpha1.IPv4PoolCheckpoint{
+		ENIs: enis,
+		UsedResources: map[string]v1alpha1.PoolResource{
+			"default/pod-1": {GroupID: "192.168.1.0/28", ResourceID: "192.168.1.1"},
+		},
+		WarmResources: []v1alpha1.PoolResource{
+			{GroupID: "192.168.1.16/28", ResourceID: "192.168.1.17"},
+			{GroupID: "192.168.1.0/28", ResourceID: "192.168.1.2"},
+			{GroupID: "192.168.1.0/28", ResourceID: "192.168.1.3"},
+		},
+		CoolingResources: []v1alpha1.CoolingPoolResource{{
+			PoolResource:      v1alpha1.PoolResource{GroupID: "192.168.1.0/28", ResourceID: "192.168.1.4"},
+			DeletionTimestamp: metav1.NewTime(deletionTime.Truncate(time.Second)),
+		}},
+	}
+	assert.Equal(t, expected, NewIPv4PoolCheckpoint(state, enis))
+}
+
+func TestGetCheckpointCoolingResources(t *testing.T) {
+	deletionTime := metav1.NewTime(time.Now().Add(-time.Second).Truncate(time.Second))
+	checkpoint := v1alpha1.IPv4PoolCheckpoint{
+		UsedResources: map[string]v1alpha1.PoolResource{
+			"default/pod-1": {GroupID: "192.168.1.1", ResourceID: "192.168.1.1"},
+			"default/pod-2": {GroupID: "192.168.1.2", ResourceID: "192.168.1.2"},
+		},
+		CoolingResources: []v1alpha1.CoolingPoolResource{
+			{PoolResource: v1alpha1.PoolResource{GroupID: "192.168.1.3", ResourceID: "192.168.1.3"},
+				DeletionTimestamp: deletionTime},
+			{PoolResource: v1alpha1.PoolResource{GroupID: "192.168.1.4", ResourceID: "192.168.1.4"},
+				DeletionTimestamp: deletionTime},
+		},
+	}
+	// pod-2 was deleted and a new pod got the cooled down resource 192.168.1.4 since the checkpoint was taken
+	usedResources := map[string]Resource{
+		"default/pod-1": {GroupID: "192.168.1.1", ResourceID: "192.168.1.1"},
+		"default/pod-3": {GroupID: "192.168.1.4", ResourceID: "192.168.1.4"},
+	}
+
+	coolingResources := GetCheckpointCoolingResources(checkpoint, usedResources)
+	assert.Len(t, coolingResources, 2)
+	assert.Equal(t, CoolDownResource{Resource: Resource{GroupID: "192.168.1.3", ResourceID: "192.168.1.3"},
+		DeletionTimestamp: deletionTime.Time}, coolingResources[0])
+	assert.Equal(t, Resource{GroupID: "192.168.1.2", ResourceID: "192.168.1.2"}, coolingResources[1].Resource)
+	assert.True(t, coolingResources[1].DeletionTimestamp.After(deletionTime.Time))
+}
@@ -18,22 +18,30 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/aws/amazon-vpc-resource-controller-k8s/apis/vpcresources/v1alpha1"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/api"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/ec2"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/vpc"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/condition"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
 	rcHealthz "github.com/aws/amazon-vpc-resource-controller-k8s/pkg/healthz"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/lookup"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/pool"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider/branch/cooldown"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider/branch/trunk"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/timetonetwork"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/tracing"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/utils"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/worker"
 	"github.com/aws/smithy-go"
@@ -41,6 +49,9 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/prometheus/client_golang/prometheus"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
@@ -53,10 +64,12 @@ const (
 	resourceCountLabel         = "resource_count"
 	operationLabel             = "branch_provider_operation"
 
-	ReasonSecurityGroupRequested    = "SecurityGroupRequested"
-	ReasonResourceAllocated         = "ResourceAllocated"
-	ReasonBranchAllocationFailed    = "BranchAllocationFailed"
-	ReasonBranchENIAnnotationFailed = "BranchENIAnnotationFailed"
+	ReasonSecurityGroupRequested      = "SecurityGroupRequested"
+	ReasonResourceAllocated           = "ResourceAllocated"
+	ReasonBranchAllocationFailed      = "BranchAllocationFailed"
+	ReasonBranchENIAnnotationFailed   = "BranchENIAnnotationFailed"
+	ReasonSecurityGroupPolicyConflict = "SecurityGroupPolicyConflict"
+	ReasonSecurityGroupLimitExceeded  = "SecurityGroupLimitExceeded"
 
 	ReasonTrunkENICreationFailed = "TrunkENICreationFailed"
 )
@@ -105,21 +118,34 @@ type branchENIProvider struct {
 	apiWrapper api.Wrapper
 	ctx        context.Context
 	checker    healthz.Checker
+	// warmPoolConfig is the configuration of the warm pools of branch ENIs on each trunk, nil if disabled
+	warmPoolConfig *config.WarmPoolConfig
+	// conditions is used to check if security groups for pods is enabled on Windows nodes
+	conditions condition.Conditions
+	// index records the pods the resources are assigned to for the lookup of the introspection API
+	index *lookup.Index
+	// timeToNetwork observes the time the pods waited for the branch ENIs once they are annotated
+	timeToNetwork *timetonetwork.Tracker
 }
 
 // NewBranchENIProvider returns the Branch ENI Provider for all nodes across the cluster
 func NewBranchENIProvider(logger logr.Logger, wrapper api.Wrapper,
-	worker worker.Worker, _ config.ResourceConfig, ctx context.Context,
+	worker worker.Worker, resourceConfig config.ResourceConfig, ctx context.Context, conditions condition.Conditions,
+	index *lookup.Index, timeToNetwork *timetonetwork.Tracker,
 ) provider.ResourceProvider {
 	prometheusRegister()
 	trunk.PrometheusRegister()
 
 	provider := &branchENIProvider{
-		apiWrapper:    wrapper,
-		log:           logger,
-		workerPool:    worker,
-		trunkENICache: make(map[string]trunk.TrunkENI),
-		ctx:           ctx,
+		apiWrapper:     wrapper,
+		log:            logger,
+		workerPool:     worker,
+		trunkENICache:  make(map[string]trunk.TrunkENI),
+		ctx:            ctx,
+		warmPoolConfig: resourceConfig.WarmPoolConfig,
+		conditions:     conditions,
+		index:          index,
+		timeToNetwork:  timeToNetwork,
 	}
 	provider.checker = provider.check()
 	return provider
@@ -143,10 +169,11 @@ func timeSinceSeconds(start time.Time) float64 {
 
 // InitResources initialized the resource for the given node name. The initialized trunk ENI is stored in
 // cache for use in future Create/Delete Requests
-func (b *branchENIProvider) InitResource(instance ec2.EC2Instance) error {
+func (b *branchENIProvider) InitResource(ctx context.Context, instance ec2.EC2Instance) error {
 	nodeName := instance.Name()
 	log := b.log.WithValues("nodeName", nodeName)
-	trunkENI := trunk.NewTrunkENI(log, instance, b.apiWrapper.EC2API)
+	trunkENI := trunk.NewTrunkENI(log, instance, b.apiWrapper.EC2API, b.warmPoolConfig, b.index)
+	b.updateBranchENISubnets(nodeName, trunkENI)
 
 	// Initialize the Trunk ENI
 	start := time.Now()
@@ -157,7 +184,13 @@ func (b *branchENIProvider) InitResource(instance ec2.EC2Instance) error {
 		return err
 	}
 
-	if err := trunkENI.InitTrunk(instance, podList); err != nil {
+	// Initialize the trunk from the checkpoint of the previous controller if present, the branch ENIs are verified
+	// against EC2 after a delay so the node doesn't wait on EC2 calls
+	fromCheckpoint := b.initTrunkFromCheckpoint(instance, trunkENI, podList)
+	if !fromCheckpoint {
+		err = trunkENI.InitTrunk(ctx, instance, podList)
+	}
+	if err != nil {
 		// If it's an AWS Error, get the exit code without the error message to avoid
 		// broadcasting multiple different messaged events
 
@@ -194,8 +227,12 @@ func (b *branchENIProvider) InitResource(instance ec2.EC2Instance) error {
 	// TODO: For efficiency submit the process delete queue job only when the delete queue has items.
 	// Submit periodic jobs for the given node name
 	b.SubmitAsyncJob(worker.NewOnDemandProcessDeleteQueueJob(nodeName))
+	if fromCheckpoint {
+		b.workerPool.SubmitJobAfter(worker.NewOnDemandVerifyTrunkJob(nodeName),
+			wait.Jitter(config.CheckpointVerifyDelay, config.CheckpointVerifyJitterFactor))
+	}
 
-	b.log.Info("initialized the resource provider successfully")
+	b.log.Info("initialized the resource provider successfully", "from checkpoint", fromCheckpoint)
 
 	// send an event to notify user this node has trunk interface initialized
 	utils.SendNodeEventWithNodeName(b.apiWrapper.K8sAPI, nodeName, utils.NodeTrunkInitiatedReason, "The node has trunk interface initialized successfully", v1.EventTypeNormal, b.log)
@@ -203,6 +240,23 @@ func (b *branchENIProvider) InitResource(instance ec2.EC2Instance) error {
 	return nil
 }
 
+// initTrunkFromCheckpoint initializes the trunk from the node's checkpoint and returns true on success, false is
+// returned if the node has no valid checkpoint of the trunk
+func (b *branchENIProvider) initTrunkFromCheckpoint(instance ec2.EC2Instance, trunkENI trunk.TrunkENI,
+	podList []v1.Pod) bool {
+	checkpoint := pool.GetNodeCheckpoint(b.log, b.apiWrapper, instance.Name(), instance.InstanceID())
+	if checkpoint == nil || checkpoint.Trunk == nil {
+		return false
+	}
+	if err := trunkENI.InitTrunkFromCheckpoint(checkpoint.Trunk, podList); err != nil {
+		branchProviderOperationsErrCount.WithLabelValues("init_from_checkpoint").Inc()
+		b.log.Info("failed to initialize trunk from checkpoint, will initialize from EC2",
+			"node name", instance.Name(), "error", err.Error())
+		return false
+	}
+	return true
+}
+
 // DeInitResources adds a an asynchronous delete job to the worker which will execute after a certain period.
 // This is done because we receive the Node Delete Event First and the Pods are evicted after the node no longer exists
 // leading to all the pod events to be ignored since the node has been de initialized and hence leaking branch ENs.
@@ -220,9 +274,14 @@ func (b *branchENIProvider) SubmitAsyncJob(job interface{}) {
 	b.workerPool.SubmitJob(job)
 }
 
+// SubmitAsyncJobWithContext submits the job to the worker pool, the job continues the trace of the context
+func (b *branchENIProvider) SubmitAsyncJobWithContext(ctx context.Context, job interface{}) {
+	b.workerPool.SubmitJobWithContext(ctx, job)
+}
+
 // ProcessAsyncJob is the job being executed in the worker pool routine. The job must be submitted using the
 // SubmitAsyncJob in order to be processed asynchronously by the caller.
-func (b *branchENIProvider) ProcessAsyncJob(job interface{}) (ctrl.Result, error) {
+func (b *branchENIProvider) ProcessAsyncJob(ctx context.Context, job interface{}) (ctrl.Result, error) {
 	onDemandJob, isValid := job.(worker.OnDemandJob)
 	if !isValid {
 		return ctrl.Result{}, fmt.Errorf("invalid job type")
@@ -230,37 +289,76 @@ func (b *branchENIProvider) ProcessAsyncJob(job interface{}) (ctrl.Result, error
 
 	switch onDemandJob.Operation {
 	case worker.OperationCreate:
-		return b.CreateAndAnnotateResources(onDemandJob.PodNamespace, onDemandJob.PodName, onDemandJob.RequestCount)
+		return b.CreateAndAnnotateResources(ctx, onDemandJob.PodNamespace, onDemandJob.PodName, onDemandJob.RequestCount)
 	case worker.OperationDeleted:
 		return b.DeleteBranchUsedByPods(onDemandJob.NodeName, onDemandJob.UID)
 	case worker.OperationProcessDeleteQueue:
-		return b.ProcessDeleteQueue(onDemandJob.NodeName)
+		return b.ProcessDeleteQueue(ctx, onDemandJob.NodeName)
 	case worker.OperationDeleteNode:
-		return b.DeleteNode(onDemandJob.NodeName)
+		return b.DeleteNode(ctx, onDemandJob.NodeName)
+	case worker.OperationVerifyTrunk:
+		return b.VerifyTrunk(ctx, onDemandJob.NodeName)
+	case worker.OperationReconcileWarmPool:
+		return b.ReconcileWarmPool(ctx, onDemandJob.NodeName)
 	}
 
 	return ctrl.Result{}, fmt.Errorf("unsupported operation type")
 }
 
 // DeleteNode deletes all the cached branch ENIs associated with the trunk and removes the trunk from the cache.
-func (b *branchENIProvider) DeleteNode(nodeName string) (ctrl.Result, error) {
+func (b *branchENIProvider) DeleteNode(ctx context.Context, nodeName string) (ctrl.Result, error) {
 	trunkENI, isPresent := b.getTrunkFromCache(nodeName)
 	if !isPresent {
 		return ctrl.Result{}, fmt.Errorf("failed to find node %s", nodeName)
 	}
 
-	trunkENI.DeleteAllBranchENIs()
+	trunkENI.DeleteAllBranchENIs(ctx)
 	b.removeTrunkFromCache(nodeName)
+	b.index.RemoveNode(nodeName)
 
 	b.log.Info("de-initialized resource provider successfully", "nodeName", nodeName)
 
 	return ctrl.Result{}, nil
 }
 
+// VerifyTrunk verifies the branch ENIs of the trunk initialized from the checkpoint against EC2
+func (b *branchENIProvider) VerifyTrunk(ctx context.Context, nodeName string) (ctrl.Result, error) {
+	trunkENI, isPresent := b.getTrunkFromCache(nodeName)
+	if !isPresent {
+		b.log.Info("stopping the verify trunk job as the trunk no longer exists", "node", nodeName)
+		return ctrl.Result{}, nil
+	}
+	if err := trunkENI.VerifyBranchENIs(ctx); err != nil {
+		branchProviderOperationsErrCount.WithLabelValues("verify_trunk").Inc()
+		return ctrl.Result{}, fmt.Errorf("verifying branch interfaces of trunk, %w", err)
+	}
+	b.log.Info("verified the trunk initialized from checkpoint", "node", nodeName)
+	return ctrl.Result{}, nil
+}
+
+// CheckpointNode adds the state of the node's trunk and its branch ENIs to the checkpoint
+func (b *branchENIProvider) CheckpointNode(nodeName string, checkpoint *v1alpha1.CNINodeCheckpoint) {
+	trunkENI, isPresent := b.getTrunkFromCache(nodeName)
+	if !isPresent {
+		return
+	}
+	checkpoint.Trunk = trunkENI.Checkpoint()
+}
+
 // GetResourceCapacity returns the resource capacity for the given instance.
-func (b *branchENIProvider) UpdateResourceCapacity(instance ec2.EC2Instance) error {
+func (b *branchENIProvider) UpdateResourceCapacity(ctx context.Context, instance ec2.EC2Instance) error {
 	instanceName := instance.Name()
 	instanceType := instance.Type()
+
+	// Security groups for pods can be enabled on Windows nodes after the node was initialized, in which case the
+	// trunk must be initialized before advertising the capacity
+	if trunkENI, isPresent := b.getTrunkFromCache(instanceName); isPresent {
+		b.updateBranchENISubnets(instanceName, trunkENI)
+	} else if instance.Os() == config.OSWindows {
+		if err := b.InitResource(ctx, instance); err != nil {
+			return err
+		}
+	}
 	capacity := vpc.Limits[instanceType].BranchInterface
 
 	if capacity != 0 {
@@ -275,6 +373,22 @@ func (b *branchENIProvider) UpdateResourceCapacity(instance ec2.EC2Instance) err
 	return nil
 }
 
+// updateBranchENISubnets sets the candidate subnets of the branch ENIs from the node's CNINode on the trunk, the
+// current candidate subnets are retained if the CNINode couldn't be retrieved
+func (b *branchENIProvider) updateBranchENISubnets(nodeName string, trunkENI trunk.TrunkENI) {
+	cniNode, err := b.apiWrapper.K8sAPI.GetCNINode(types.NamespacedName{Name: nodeName})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			trunkENI.SetBranchENISubnets(nil)
+			return
+		}
+		b.log.V(1).Info("failed to get CNINode, will use the current branch ENI subnets", "node name", nodeName,
+			"error", err.Error())
+		return
+	}
+	trunkENI.SetBranchENISubnets(cniNode.Spec.BranchENISubnets)
+}
+
 // ReconcileNode reconciles a nodes by getting the list of pods from K8s and comparing the result
 // with the internal cache.
 func (b *branchENIProvider) ReconcileNode(nodeName string) bool {
@@ -296,21 +410,43 @@ func (b *branchENIProvider) ReconcileNode(nodeName string) bool {
 	return foundLeakedENI
 }
 
-// ProcessDeleteQueue removes cooled down ENIs associated with a trunk for a given node
-func (b *branchENIProvider) ProcessDeleteQueue(nodeName string) (ctrl.Result, error) {
+// ProcessDeleteQueue removes cooled down ENIs associated with a trunk for a given node and submits the job to
+// reconcile the warm pools of branch ENIs on the trunk, so that the creation of warm branch ENIs doesn't delay the
+// delete queue
+func (b *branchENIProvider) ProcessDeleteQueue(ctx context.Context, nodeName string) (ctrl.Result, error) {
 	trunkENI, isPresent := b.getTrunkFromCache(nodeName)
 	log := b.log.WithValues("node", nodeName)
 	if !isPresent {
 		log.Info("stopping the process delete queue job")
 		return ctrl.Result{}, nil
 	}
-	trunkENI.DeleteCooledDownENIs()
+	trunkENI.DeleteCooledDownENIs(ctx)
+	if b.warmPoolConfig != nil {
+		b.SubmitAsyncJob(worker.NewOnDemandReconcileWarmPoolJob(nodeName))
+	}
 	return deleteQueueRequeueRequest, nil
 }
 
+// ReconcileWarmPool creates and deletes the warm branch ENIs of the trunk of the node to match the warm pool config
+func (b *branchENIProvider) ReconcileWarmPool(ctx context.Context, nodeName string) (ctrl.Result, error) {
+	trunkENI, isPresent := b.getTrunkFromCache(nodeName)
+	if !isPresent {
+		b.log.Info("stopping the reconcile warm pool job", "node", nodeName)
+		return ctrl.Result{}, nil
+	}
+	trunkENI.ReconcileWarmPool(ctx)
+	return ctrl.Result{}, nil
+}
+
 // CreateAndAnnotateResources creates resource for the pod, the function can run concurrently for different pods without
 // any locking as long as caller guarantees this function is not called concurrently for same pods.
-func (b *branchENIProvider) CreateAndAnnotateResources(podNamespace string, podName string, resourceCount int) (ctrl.Result, error) {
+func (b *branchENIProvider) CreateAndAnnotateResources(ctx context.Context, podNamespace string, podName string,
+	resourceCount int) (result ctrl.Result, err error) {
+	ctx, span := tracing.Start(ctx, "branchENIProvider.CreateAndAnnotateResources",
+		tracing.AttributePodNamespace.String(podNamespace), tracing.AttributePodName.String(podName),
+		tracing.AttributeRequestCount.Int(resourceCount))
+	defer func() { tracing.End(span, err) }()
+
 	// Get the pod from cache
 	pod, err := b.apiWrapper.PodAPI.GetPod(podNamespace, podName)
 	if err != nil {
@@ -324,7 +460,9 @@ func (b *branchENIProvider) CreateAndAnnotateResources(podNamespace string, podN
 	}
 
 	// Get the pod object again directly from API Server as the cache can be stale
+	_, getPodSpan := tracing.Start(ctx, "GetPodFromAPIServer")
 	pod, err = b.apiWrapper.PodAPI.GetPodFromAPIServer(b.ctx, podNamespace, podName)
+	tracing.End(getPodSpan, err)
 	if err != nil {
 		branchProviderOperationsErrCount.WithLabelValues("get_pod_api_server").Inc()
 		return ctrl.Result{}, err
@@ -337,11 +475,24 @@ func (b *branchENIProvider) CreateAndAnnotateResources(podNamespace string, podN
 		return ctrl.Result{}, nil
 	}
 
-	securityGroups, err := b.apiWrapper.SGPAPI.GetMatchingSecurityGroupForPods(pod)
+	resolution, err := b.apiWrapper.SGPAPI.ResolveSecurityGroupsForPod(pod)
 	if err != nil {
+		limitErr := &utils.SecurityGroupLimitExceededError{}
+		if errors.As(err, &limitErr) {
+			b.apiWrapper.K8sAPI.BroadcastEvent(pod, ReasonSecurityGroupLimitExceeded, limitErr.Error(),
+				v1.EventTypeWarning)
+		}
 		return ctrl.Result{}, err
 	}
 
+	if resolution.HasConflict() {
+		b.apiWrapper.K8sAPI.BroadcastEvent(pod, ReasonSecurityGroupPolicyConflict, fmt.Sprintf("Pod matched "+
+			"overlapping SecurityGroupPolicies %v with different Security Groups, applied policies %v",
+			resolution.MatchedPolicies, resolution.AppliedPolicies), v1.EventTypeWarning)
+	}
+
+	securityGroups := resolution.SecurityGroups
+
 	if len(securityGroups) == 0 {
 		b.apiWrapper.K8sAPI.BroadcastEvent(pod, ReasonSecurityGroupRequested,
 			"Pod will get the instance security group as the pod didn't match any Security Group from "+
@@ -362,7 +513,7 @@ func (b *branchENIProvider) CreateAndAnnotateResources(podNamespace string, podN
 	}
 
 	// Get the list of branch ENIs that will be allocated to the pod object
-	branchENIs, err := trunkENI.CreateAndAssociateBranchENIs(pod, securityGroups, resourceCount)
+	branchENIs, err := trunkENI.CreateAndAssociateBranchENIs(ctx, pod, securityGroups, resourceCount)
 	if err != nil {
 		if err == trunk.ErrCurrentlyAtMaxCapacity {
 			return ctrl.Result{RequeueAfter: cooldown.GetCoolDown().GetCoolDownPeriod(), Requeue: true}, nil
@@ -383,10 +534,27 @@ func (b *branchENIProvider) CreateAndAnnotateResources(podNamespace string, podN
 		return ctrl.Result{}, err
 	}
 
-	start = time.Now()
+	annotations := map[string]string{config.ResourceNamePodENI: string(jsonBytes)}
+	// The Windows CNI reads the IPv4 address of the pod from the IPv4 annotation, it's set in the same patch as the
+	// pod-eni annotation which marks the allocation as complete, so the pod is never left with only one of them
+	if utils.HasWindowsNodeSelector(pod) {
+		ipv4Address, err := getWindowsPodIPv4Address(branchENIs)
+		if err != nil {
+			trunkENI.PushENIsToFrontOfDeleteQueue(pod, branchENIs)
+			b.log.Info("pushed the ENIs to the delete queue as failed to annotate the windows pod", "ENI/s", branchENIs)
+			b.apiWrapper.K8sAPI.BroadcastEvent(pod, ReasonBranchENIAnnotationFailed,
+				fmt.Sprintf("failed to annotate pod with branch ENI IPv4 address: %v", err), v1.EventTypeWarning)
+			branchProviderOperationsErrCount.WithLabelValues("annotate_branch_eni").Inc()
+			return ctrl.Result{}, err
+		}
+		annotations[config.ResourceNameIPAddress] = ipv4Address
+	}
+
 	// Annotate the pod with the created resources
-	err = b.apiWrapper.PodAPI.AnnotatePod(pod.Namespace, pod.Name, pod.UID,
-		config.ResourceNamePodENI, string(jsonBytes))
+	start = time.Now()
+	_, annotateSpan := tracing.Start(ctx, "AnnotatePod", tracing.PodAttributes(pod)...)
+	err = b.apiWrapper.PodAPI.AnnotatePodWithAnnotations(pod.Namespace, pod.Name, pod.UID, annotations)
+	tracing.End(annotateSpan, err)
 	if err != nil {
 		trunkENI.PushENIsToFrontOfDeleteQueue(pod, branchENIs)
 		b.log.Info("pushed the ENIs to the delete queue as failed to annotate the pod", "ENI/s", branchENIs)
@@ -396,6 +564,8 @@ func (b *branchENIProvider) CreateAndAnnotateResources(podNamespace string, podN
 		return ctrl.Result{}, err
 	}
 
+	b.timeToNetwork.Annotated(pod, config.ResourceNamePodENI)
+
 	// Broadcast event to indicate the resource has been successfully created and annotated to the pod object
 	b.apiWrapper.K8sAPI.BroadcastEvent(pod, ReasonResourceAllocated,
 		fmt.Sprintf("Allocated %s to the pod", string(jsonBytes)), v1.EventTypeNormal)
@@ -408,6 +578,19 @@ func (b *branchENIProvider) CreateAndAnnotateResources(podNamespace string, podN
 	return ctrl.Result{}, nil
 }
 
+// getWindowsPodIPv4Address returns the IPv4 address of the branch ENI of the windows pod along with the prefix length
+// of the subnet, in the same format as the IPv4 address allocated from the secondary IP pool
+func getWindowsPodIPv4Address(branchENIs []*trunk.ENIDetails) (string, error) {
+	if len(branchENIs) != 1 {
+		return "", fmt.Errorf("windows pod supports exactly one branch ENI, got %d", len(branchENIs))
+	}
+	subnetCIDR := strings.Split(branchENIs[0].SubnetCIDR, "/")
+	if len(subnetCIDR) != 2 {
+		return "", fmt.Errorf("invalid subnet cidr %s of branch ENI %s", branchENIs[0].SubnetCIDR, branchENIs[0].ID)
+	}
+	return branchENIs[0].IPV4Addr + "/" + subnetCIDR[1], nil
+}
+
 func (b *branchENIProvider) DeleteBranchUsedByPods(nodeName string, UID string) (ctrl.Result, error) {
 	trunkENI, isPresent := b.getTrunkFromCache(nodeName)
 	if !isPresent {
@@ -423,6 +606,44 @@ func (b *branchENIProvider) DeleteBranchUsedByPods(nodeName string, UID string)
 	return ctrl.Result{}, nil
 }
 
+// UpdateBranchENISecurityGroups records the security groups modified on the branch ENI used by the pod in the trunk of
+// the node
+func (b *branchENIProvider) UpdateBranchENISecurityGroups(nodeName string, UID string, eniID string,
+	securityGroups []string) {
+	trunkENI, isPresent := b.getTrunkFromCache(nodeName)
+	if !isPresent {
+		b.log.Info("failed to find trunk ENI for the node to update security groups", "nodeName", nodeName)
+		return
+	}
+	trunkENI.UpdateBranchENISecurityGroups(UID, eniID, securityGroups)
+}
+
+// SetBranchENISubnets sets the candidate subnets of the new branch ENIs on the trunk of the node, the subnets are
+// set from the CNINode when the trunk is initialized if the trunk isn't present yet
+func (b *branchENIProvider) SetBranchENISubnets(nodeName string, spec *v1alpha1.BranchENISubnets) {
+	trunkENI, isPresent := b.getTrunkFromCache(nodeName)
+	if !isPresent {
+		b.log.V(1).Info("trunk ENI not found, will set the branch ENI subnets on initialization", "nodeName", nodeName)
+		return
+	}
+	trunkENI.SetBranchENISubnets(spec)
+}
+
+// GetBranchENISubnets returns the candidate subnets of the branch ENIs of all the trunks, the instance subnets are not
+// included
+func (b *branchENIProvider) GetBranchENISubnets() []string {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	subnetIDs := make(map[string]struct{})
+	for _, trunkENI := range b.trunkENICache {
+		for _, subnetID := range trunkENI.GetBranchENISubnetIDs() {
+			subnetIDs[subnetID] = struct{}{}
+		}
+	}
+	return slices.Sorted(maps.Keys(subnetIDs))
+}
+
 // addTrunkToCache adds the trunk eni to cache, if the trunk already exists an error is thrown
 func (b *branchENIProvider) addTrunkToCache(nodeName string, trunkENI trunk.TrunkENI) error {
 	b.lock.Lock()
@@ -473,9 +694,16 @@ func (b *branchENIProvider) GetPool(_ string) (pool.Pool, bool) {
 	return nil, false
 }
 
-// IsInstanceSupported returns true for linux node as pod eni is only supported for linux worker node
+// IsInstanceSupported returns true for linux node and for windows node when security groups for pods is enabled
+// for windows in the amazon-vpc-cni configmap
 func (b *branchENIProvider) IsInstanceSupported(instance ec2.EC2Instance) bool {
-	if instance.Os() != config.OSLinux {
+	switch instance.Os() {
+	case config.OSLinux:
+	case config.OSWindows:
+		if !b.conditions.IsWindowsSecurityGroupsForPodsEnabled() {
+			return false
+		}
+	default:
 		return false
 	}
 
@@ -518,11 +746,16 @@ func (b *branchENIProvider) IntrospectSummary() interface{} {
 }
 
 func changeToIntrospectSummary(details trunk.IntrospectResponse) trunk.IntrospectSummaryResponse {
+	var warmPoolLen int
+	for _, warmENIs := range details.WarmPools {
+		warmPoolLen += len(warmENIs)
+	}
 	return trunk.IntrospectSummaryResponse{
 		TrunkENIID:     details.TrunkENIID,
 		InstanceID:     details.InstanceID,
 		BranchENICount: len(details.PodToBranchENI),
 		DeleteQueueLen: len(details.DeleteQueue),
+		WarmPoolLen:    warmPoolLen,
 	}
 }
 
@@ -537,6 +770,26 @@ func (b *branchENIProvider) IntrospectNode(nodeName string) interface{} {
 	return trunkENI.Introspect()
 }
 
+// IntrospectNodeNames returns the names of the nodes with the resources of the provider
+func (b *branchENIProvider) IntrospectNodeNames() []string {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	return slices.Collect(maps.Keys(b.trunkENICache))
+}
+
+// IntrospectNodeSummary returns the resources summary of the node
+func (b *branchENIProvider) IntrospectNodeSummary(nodeName string) interface{} {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	trunkENI, found := b.trunkENICache[nodeName]
+	if !found {
+		return struct{}{}
+	}
+	return changeToIntrospectSummary(trunkENI.Introspect())
+}
+
 func (b *branchENIProvider) check() healthz.Checker {
 	b.log.Info("Branch provider's healthz subpath was added")
 	return func(req *http.Request) error {
@@ -20,7 +20,9 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/aws/amazon-vpc-resource-controller-k8s/apis/vpcresources/v1alpha1"
 	mock_ec2 "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/aws/ec2"
+	mock_condition "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/condition"
 	mock_k8s "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/k8s"
 	mock_pod "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/k8s/pod"
 	mock_trunk "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/provider/branch/trunk"
@@ -30,12 +32,16 @@ import (
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/vpc"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider/branch/trunk"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/timetonetwork"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/utils"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/worker"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	k8sCtrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -269,10 +275,11 @@ func TestBranchENIProvider_GetResourceCapacity(t *testing.T) {
 
 	mockInstance.EXPECT().Type().Return(supportedInstanceType)
 	mockInstance.EXPECT().Name().Return(NodeName)
+	mockInstance.EXPECT().Os().Return(config.OSLinux)
 	mockK8sWrapper.EXPECT().AdvertiseCapacityIfNotSet(NodeName, config.ResourceNamePodENI,
 		vpc.Limits[supportedInstanceType].BranchInterface)
 
-	err := provider.UpdateResourceCapacity(mockInstance)
+	err := provider.UpdateResourceCapacity(context.TODO(), mockInstance)
 	assert.NoError(t, err)
 }
 
@@ -288,11 +295,46 @@ func TestBranchENIProvider_GetResourceCapacity_NotSupported(t *testing.T) {
 
 	mockInstance.EXPECT().Name().Return(NodeName)
 	mockInstance.EXPECT().Type().Return(supportedInstanceType)
+	mockInstance.EXPECT().Os().Return(config.OSLinux)
 
-	err := provider.UpdateResourceCapacity(mockInstance)
+	err := provider.UpdateResourceCapacity(context.TODO(), mockInstance)
 	assert.NoError(t, err)
 }
 
+// TestBranchENIProvider_UpdateResourceCapacity_BranchENISubnets tests the branch ENI subnets from the CNINode are set
+// on the trunk ENI and unset if the CNINode is not found
+func TestBranchENIProvider_UpdateResourceCapacity_BranchENISubnets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	provider, mockK8sWrapper := getProviderAndMockK8sWrapper(ctrl)
+	mockInstance := mock_ec2.NewMockEC2Instance(ctrl)
+	mockTrunk := mock_trunk.NewMockTrunkENI(ctrl)
+	provider.trunkENICache[NodeName] = mockTrunk
+
+	branchENISubnets := &v1alpha1.BranchENISubnets{SubnetIDs: []string{"subnet-1", "subnet-2"}}
+	cniNode := &v1alpha1.CNINode{
+		ObjectMeta: metav1.ObjectMeta{Name: NodeName},
+		Spec:       v1alpha1.CNINodeSpec{BranchENISubnets: branchENISubnets},
+	}
+
+	mockInstance.EXPECT().Name().Return(NodeName).Times(3)
+	mockInstance.EXPECT().Type().Return("t3.medium").Times(3)
+	gomock.InOrder(
+		mockK8sWrapper.EXPECT().GetCNINode(types.NamespacedName{Name: NodeName}).Return(cniNode, nil),
+		mockTrunk.EXPECT().SetBranchENISubnets(branchENISubnets),
+		// The current subnets are retained on transient errors
+		mockK8sWrapper.EXPECT().GetCNINode(types.NamespacedName{Name: NodeName}).Return(nil, MockError),
+		mockK8sWrapper.EXPECT().GetCNINode(types.NamespacedName{Name: NodeName}).Return(nil,
+			apierrors.NewNotFound(schema.GroupResource{Group: "vpcresources.k8s.aws", Resource: "cninodes"}, NodeName)),
+		mockTrunk.EXPECT().SetBranchENISubnets(nil),
+	)
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, provider.UpdateResourceCapacity(context.TODO(), mockInstance))
+	}
+}
+
 func TestBranchENIProvider_Supported_LabelNode(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -318,12 +360,14 @@ func TestBranchENIProvider_Supported_LabelNode(t *testing.T) {
 }
 
 // TestBranchENIProvider_CreateAndAnnotateResources tests that create is invoked equal to the number of resources to
-// be created
+// be created, and the time the pod waited is observed once the pod is annotated
 func TestBranchENIProvider_CreateAndAnnotateResources(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	provider, mockPodAPI, mockSGPAPI, mockK8sAPI := getProviderAndMocks(ctrl)
+	provider.timeToNetwork = timetonetwork.NewTracker(mockK8sAPI, 0)
+	provider.timeToNetwork.Track(MockPod1, config.ResourceNamePodENI, false)
 
 	resCount := 1
 	expectedAnnotation, _ := json.Marshal(EniDetails)
@@ -333,14 +377,15 @@ func TestBranchENIProvider_CreateAndAnnotateResources(t *testing.T) {
 
 	mockPodAPI.EXPECT().GetPod(MockPodNamespace1, MockPodName1).Return(MockPod1, nil)
 	mockPodAPI.EXPECT().GetPodFromAPIServer(ctx, MockPodNamespace1, MockPodName1).Return(MockPod1, nil)
-	mockSGPAPI.EXPECT().GetMatchingSecurityGroupForPods(MockPod1).Return(SecurityGroups, nil)
+	mockSGPAPI.EXPECT().ResolveSecurityGroupsForPod(MockPod1).Return(&utils.SecurityGroupResolution{SecurityGroups: SecurityGroups}, nil)
 	mockK8sAPI.EXPECT().BroadcastEvent(MockPod1, ReasonSecurityGroupRequested, gomock.Any(), v1.EventTypeNormal)
-	fakeTrunk.EXPECT().CreateAndAssociateBranchENIs(MockPod1, SecurityGroups, resCount).Return(EniDetails, nil)
-	mockPodAPI.EXPECT().AnnotatePod(MockPodNamespace1, MockPodName1, MockPodUID1, config.ResourceNamePodENI,
-		string(expectedAnnotation)).Return(nil)
+	fakeTrunk.EXPECT().CreateAndAssociateBranchENIs(gomock.Any(), MockPod1, SecurityGroups, resCount).Return(EniDetails, nil)
+	mockPodAPI.EXPECT().AnnotatePodWithAnnotations(MockPodNamespace1, MockPodName1, MockPodUID1,
+		map[string]string{config.ResourceNamePodENI: string(expectedAnnotation)}).Return(nil)
+	mockK8sAPI.EXPECT().GetNode(NodeName).Return(nil, fmt.Errorf("not found"))
 	mockK8sAPI.EXPECT().BroadcastEvent(MockPod1, ReasonResourceAllocated, gomock.Any(), v1.EventTypeNormal)
 
-	_, err := provider.CreateAndAnnotateResources(MockPodNamespace1, MockPodName1, resCount)
+	_, err := provider.CreateAndAnnotateResources(context.TODO(), MockPodNamespace1, MockPodName1, resCount)
 
 	assert.NoError(t, err)
 }
@@ -361,7 +406,7 @@ func TestBranchENIProvider_CreateAndAnnotateResources_AlreadyAnnotated_Cache(t *
 
 	mockPodAPI.EXPECT().GetPod(MockPodNamespace1, MockPodName1).Return(mockPodWithAnnotation, nil)
 
-	_, err := provider.CreateAndAnnotateResources(MockPodNamespace1, MockPodName1, resCount)
+	_, err := provider.CreateAndAnnotateResources(context.TODO(), MockPodNamespace1, MockPodName1, resCount)
 
 	assert.NoError(t, err)
 }
@@ -385,7 +430,7 @@ func TestBranchENIProvider_CreateAndAnnotateResources_AlreadyAnnotated_APIServer
 	mockPodAPI.EXPECT().GetPod(MockPodNamespace1, MockPodName1).Return(MockPod1, nil)
 	mockPodAPI.EXPECT().GetPodFromAPIServer(ctx, MockPodNamespace1, MockPodName1).Return(mockPodWithAnnotation, nil)
 
-	_, err := provider.CreateAndAnnotateResources(MockPodNamespace1, MockPodName1, resCount)
+	_, err := provider.CreateAndAnnotateResources(context.TODO(), MockPodNamespace1, MockPodName1, resCount)
 
 	assert.NoError(t, err)
 }
@@ -405,7 +450,7 @@ func TestBranchENIProvider_CreateAndAnnotateResources_GetPodError(t *testing.T)
 	mockPodAPI.EXPECT().GetPod(MockPodNamespace1, MockPodName1).Return(MockPod1, nil)
 	mockPodAPI.EXPECT().GetPodFromAPIServer(ctx, MockPodNamespace1, MockPodName1).Return(nil, MockError)
 
-	_, err := provider.CreateAndAnnotateResources(MockPodNamespace1, MockPodName1, resCount)
+	_, err := provider.CreateAndAnnotateResources(context.TODO(), MockPodNamespace1, MockPodName1, resCount)
 
 	assert.Equal(t, MockError, err)
 }
@@ -425,7 +470,7 @@ func TestBranchENIProvider_CreateAndAnnotateResources_TrunkNotPreset(t *testing.
 	mockPodAPI.EXPECT().GetPod(MockPodNamespace1, MockPodName1).Return(MockPod1, nil)
 	mockPodAPI.EXPECT().GetPodFromAPIServer(ctx, MockPodNamespace1, MockPodName1).Return(nil, MockError)
 
-	_, err := provider.CreateAndAnnotateResources(MockPodNamespace1, MockPodName1, resCount)
+	_, err := provider.CreateAndAnnotateResources(context.TODO(), MockPodNamespace1, MockPodName1, resCount)
 	assert.NotNil(t, err)
 }
 
@@ -441,13 +486,73 @@ func TestBranchENIProvider_CreateAndAnnotateResources_GetSecurityGroup_Error(t *
 
 	mockPodAPI.EXPECT().GetPod(MockPodNamespace1, MockPodName1).Return(MockPod1, nil)
 	mockPodAPI.EXPECT().GetPodFromAPIServer(ctx, MockPodNamespace1, MockPodName1).Return(MockPod1, nil)
-	mockSGPAPI.EXPECT().GetMatchingSecurityGroupForPods(MockPod1).Return(nil, MockError)
+	mockSGPAPI.EXPECT().ResolveSecurityGroupsForPod(MockPod1).Return(nil, MockError)
 
-	_, err := provider.CreateAndAnnotateResources(MockPodNamespace1, MockPodName1, resCount)
+	_, err := provider.CreateAndAnnotateResources(context.TODO(), MockPodNamespace1, MockPodName1, resCount)
 
 	assert.Error(t, err)
 }
 
+// TestBranchENIProvider_CreateAndAnnotateResources_SecurityGroupLimitExceeded tests that a warning event is sent to
+// the pod and the error is propagated if the resolved security groups exceed the limit
+func TestBranchENIProvider_CreateAndAnnotateResources_SecurityGroupLimitExceeded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	provider, mockPodAPI, mockSGPAPI, mockK8sAPI := getProviderAndMocks(ctrl)
+
+	resCount := 1
+	limitErr := &utils.SecurityGroupLimitExceededError{SecurityGroups: SecurityGroups, Limit: 1}
+
+	mockPodAPI.EXPECT().GetPod(MockPodNamespace1, MockPodName1).Return(MockPod1, nil)
+	mockPodAPI.EXPECT().GetPodFromAPIServer(ctx, MockPodNamespace1, MockPodName1).Return(MockPod1, nil)
+	mockSGPAPI.EXPECT().ResolveSecurityGroupsForPod(MockPod1).Return(
+		&utils.SecurityGroupResolution{SecurityGroups: SecurityGroups}, limitErr)
+	mockK8sAPI.EXPECT().BroadcastEvent(MockPod1, ReasonSecurityGroupLimitExceeded, limitErr.Error(), v1.EventTypeWarning)
+
+	_, err := provider.CreateAndAnnotateResources(context.TODO(), MockPodNamespace1, MockPodName1, resCount)
+
+	assert.ErrorIs(t, err, limitErr)
+}
+
+// TestBranchENIProvider_CreateAndAnnotateResources_PolicyConflict tests that a warning event is sent to the pod when
+// the pod matches overlapping policies with different security groups
+func TestBranchENIProvider_CreateAndAnnotateResources_PolicyConflict(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	provider, mockPodAPI, mockSGPAPI, mockK8sAPI := getProviderAndMocks(ctrl)
+
+	resCount := 1
+	expectedAnnotation, _ := json.Marshal(EniDetails)
+	fakeTrunk := mock_trunk.NewMockTrunkENI(ctrl)
+	provider.trunkENICache[NodeName] = fakeTrunk
+
+	highPriority := utils.MatchedSecurityGroupPolicy{Name: "default/high", Priority: 10, SecurityGroups: SecurityGroups}
+	lowPriority := utils.MatchedSecurityGroupPolicy{Name: "default/low", Priority: 1, SecurityGroups: []string{"sg-other"}}
+	resolution := &utils.SecurityGroupResolution{
+		SecurityGroups:  SecurityGroups,
+		MatchedPolicies: []utils.MatchedSecurityGroupPolicy{highPriority, lowPriority},
+		AppliedPolicies: []utils.MatchedSecurityGroupPolicy{highPriority},
+	}
+
+	mockPodAPI.EXPECT().GetPod(MockPodNamespace1, MockPodName1).Return(MockPod1, nil)
+	mockPodAPI.EXPECT().GetPodFromAPIServer(ctx, MockPodNamespace1, MockPodName1).Return(MockPod1, nil)
+	mockSGPAPI.EXPECT().ResolveSecurityGroupsForPod(MockPod1).Return(resolution, nil)
+	mockK8sAPI.EXPECT().BroadcastEvent(MockPod1, ReasonSecurityGroupPolicyConflict,
+		"Pod matched overlapping SecurityGroupPolicies [default/high(priority=10) default/low(priority=1)] with "+
+			"different Security Groups, applied policies [default/high(priority=10)]", v1.EventTypeWarning)
+	mockK8sAPI.EXPECT().BroadcastEvent(MockPod1, ReasonSecurityGroupRequested, gomock.Any(), v1.EventTypeNormal)
+	fakeTrunk.EXPECT().CreateAndAssociateBranchENIs(gomock.Any(), MockPod1, SecurityGroups, resCount).Return(EniDetails, nil)
+	mockPodAPI.EXPECT().AnnotatePodWithAnnotations(MockPodNamespace1, MockPodName1, MockPodUID1,
+		map[string]string{config.ResourceNamePodENI: string(expectedAnnotation)}).Return(nil)
+	mockK8sAPI.EXPECT().BroadcastEvent(MockPod1, ReasonResourceAllocated, gomock.Any(), v1.EventTypeNormal)
+
+	_, err := provider.CreateAndAnnotateResources(context.TODO(), MockPodNamespace1, MockPodName1, resCount)
+
+	assert.NoError(t, err)
+}
+
 // TestBranchENIProvider_CreateAndAnnotateResources_Annotate_Error tests if annotate fails the ENIs are pushed back to
 // the delete queue
 func TestBranchENIProvider_CreateAndAnnotateResources_Annotate_Error(t *testing.T) {
@@ -465,14 +570,14 @@ func TestBranchENIProvider_CreateAndAnnotateResources_Annotate_Error(t *testing.
 	mockPodAPI.EXPECT().GetPod(MockPodNamespace1, MockPodName1).Return(MockPod1, nil)
 	mockPodAPI.EXPECT().GetPodFromAPIServer(ctx, MockPodNamespace1, MockPodName1).Return(MockPod1, nil)
 	mockK8sAPI.EXPECT().BroadcastEvent(MockPod1, ReasonSecurityGroupRequested, gomock.Any(), v1.EventTypeNormal)
-	mockSGPAPI.EXPECT().GetMatchingSecurityGroupForPods(MockPod1).Return(SecurityGroups, nil)
-	fakeTrunk.EXPECT().CreateAndAssociateBranchENIs(MockPod1, SecurityGroups, resCount).Return(EniDetails, nil)
-	mockPodAPI.EXPECT().AnnotatePod(MockPodNamespace1, MockPodName1, MockPodUID1,
-		config.ResourceNamePodENI, string(expectedAnnotation)).Return(MockError)
+	mockSGPAPI.EXPECT().ResolveSecurityGroupsForPod(MockPod1).Return(&utils.SecurityGroupResolution{SecurityGroups: SecurityGroups}, nil)
+	fakeTrunk.EXPECT().CreateAndAssociateBranchENIs(gomock.Any(), MockPod1, SecurityGroups, resCount).Return(EniDetails, nil)
+	mockPodAPI.EXPECT().AnnotatePodWithAnnotations(MockPodNamespace1, MockPodName1, MockPodUID1,
+		map[string]string{config.ResourceNamePodENI: string(expectedAnnotation)}).Return(MockError)
 	mockK8sAPI.EXPECT().BroadcastEvent(MockPod1, ReasonBranchENIAnnotationFailed, gomock.Any(), v1.EventTypeWarning)
 	fakeTrunk.EXPECT().PushENIsToFrontOfDeleteQueue(MockPod1, EniDetails)
 
-	_, err := provider.CreateAndAnnotateResources(MockPodNamespace1, MockPodName1, resCount)
+	_, err := provider.CreateAndAnnotateResources(context.TODO(), MockPodNamespace1, MockPodName1, resCount)
 
 	assert.Error(t, MockError, err)
 }
@@ -531,7 +636,7 @@ func TestBranchENIProvider_ReconcileNode_TrunkENIDeleted(t *testing.T) {
 func TestBranchENIProvider_ProcessDeleteQueue_TrunkENIDeleted(t *testing.T) {
 	provider := getProvider()
 
-	result, err := provider.ProcessDeleteQueue(NodeName)
+	result, err := provider.ProcessDeleteQueue(context.TODO(), NodeName)
 	assert.NoError(t, err)
 	assert.Equal(t, k8sCtrl.Result{}, result)
 }
@@ -547,13 +652,161 @@ func TestBranchENIProvider_ProcessDeleteQueue(t *testing.T) {
 	fakeTrunk1 := mock_trunk.NewMockTrunkENI(ctrl)
 	provider.trunkENICache[NodeName] = fakeTrunk1
 
-	fakeTrunk1.EXPECT().DeleteCooledDownENIs()
+	fakeTrunk1.EXPECT().DeleteCooledDownENIs(gomock.Any())
+
+	result, err := provider.ProcessDeleteQueue(context.TODO(), NodeName)
+	assert.NoError(t, err)
+	assert.Equal(t, deleteQueueRequeueRequest, result)
+}
+
+// TestBranchENIProvider_ProcessDeleteQueue_WarmPool tests that the process delete queue job submits the job to
+// reconcile the warm pool instead of reconciling it inline
+func TestBranchENIProvider_ProcessDeleteQueue_WarmPool(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	provider, mockWorker := getProviderWithMockWorker(ctrl)
+	provider.trunkENICache = make(map[string]trunk.TrunkENI)
+	provider.warmPoolConfig = &config.WarmPoolConfig{DesiredSize: 1}
+
+	fakeTrunk1 := mock_trunk.NewMockTrunkENI(ctrl)
+	provider.trunkENICache[NodeName] = fakeTrunk1
+
+	fakeTrunk1.EXPECT().DeleteCooledDownENIs(gomock.Any())
+	mockWorker.EXPECT().SubmitJob(worker.NewOnDemandReconcileWarmPoolJob(NodeName))
 
-	result, err := provider.ProcessDeleteQueue(NodeName)
+	result, err := provider.ProcessDeleteQueue(context.TODO(), NodeName)
 	assert.NoError(t, err)
 	assert.Equal(t, deleteQueueRequeueRequest, result)
 }
 
+// TestBranchENIProvider_ReconcileWarmPool tests that the reconcile warm pool job reconciles the warm pool of the trunk
+// and is not requeued
+func TestBranchENIProvider_ReconcileWarmPool(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	provider := getProvider()
+
+	fakeTrunk1 := mock_trunk.NewMockTrunkENI(ctrl)
+	provider.trunkENICache[NodeName] = fakeTrunk1
+
+	fakeTrunk1.EXPECT().ReconcileWarmPool(gomock.Any())
+
+	result, err := provider.ProcessAsyncJob(context.TODO(), worker.NewOnDemandReconcileWarmPoolJob(NodeName))
+	assert.NoError(t, err)
+	assert.Equal(t, k8sCtrl.Result{}, result)
+}
+
+// TestBranchENIProvider_SetBranchENISubnets tests the branch ENI subnets are set on the trunk of the node if present
+func TestBranchENIProvider_SetBranchENISubnets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	provider := getProvider()
+
+	fakeTrunk1 := mock_trunk.NewMockTrunkENI(ctrl)
+	provider.trunkENICache[NodeName] = fakeTrunk1
+	spec := &v1alpha1.BranchENISubnets{SubnetIDs: []string{"subnet-1"}}
+
+	fakeTrunk1.EXPECT().SetBranchENISubnets(spec)
+
+	provider.SetBranchENISubnets(NodeName, spec)
+	// The subnets are set on initialization if the trunk isn't present
+	provider.SetBranchENISubnets("node-2", spec)
+}
+
+// TestBranchENIProvider_GetBranchENISubnets tests the candidate subnets of the branch ENIs of all the trunks are
+// returned once
+func TestBranchENIProvider_GetBranchENISubnets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	provider := getProvider()
+
+	fakeTrunk1 := mock_trunk.NewMockTrunkENI(ctrl)
+	fakeTrunk2 := mock_trunk.NewMockTrunkENI(ctrl)
+	provider.trunkENICache[NodeName] = fakeTrunk1
+	provider.trunkENICache["node-2"] = fakeTrunk2
+
+	fakeTrunk1.EXPECT().GetBranchENISubnetIDs().Return([]string{"subnet-2", "subnet-1"})
+	fakeTrunk2.EXPECT().GetBranchENISubnetIDs().Return([]string{"subnet-2"})
+
+	assert.Equal(t, []string{"subnet-1", "subnet-2"}, provider.GetBranchENISubnets())
+}
+
+// TestBranchENIProvider_initTrunkFromCheckpoint tests the trunk is initialized from the node's checkpoint if it's
+// valid, and not initialized if the node has no checkpoint or the checkpoint doesn't match the running pods
+func TestBranchENIProvider_initTrunkFromCheckpoint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	provider, mockK8sWrapper := getProviderAndMockK8sWrapper(ctrl)
+	mockInstance := mock_ec2.NewMockEC2Instance(ctrl)
+	mockInstance.EXPECT().Name().Return(NodeName).AnyTimes()
+	mockInstance.EXPECT().InstanceID().Return("i-1").AnyTimes()
+	fakeTrunk1 := mock_trunk.NewMockTrunkENI(ctrl)
+
+	trunkCheckpoint := &v1alpha1.TrunkCheckpoint{TrunkENIID: "eni-trunk"}
+	cniNode := &v1alpha1.CNINode{Status: v1alpha1.CNINodeStatus{
+		Checkpoint: &v1alpha1.CNINodeCheckpoint{InstanceID: "i-1", Trunk: trunkCheckpoint},
+	}}
+	podList := []v1.Pod{*MockPod1}
+
+	mockK8sWrapper.EXPECT().GetCNINode(types.NamespacedName{Name: NodeName}).Return(cniNode, nil).Times(2)
+	fakeTrunk1.EXPECT().InitTrunkFromCheckpoint(trunkCheckpoint, podList).Return(nil)
+	assert.True(t, provider.initTrunkFromCheckpoint(mockInstance, fakeTrunk1, podList))
+
+	fakeTrunk1.EXPECT().InitTrunkFromCheckpoint(trunkCheckpoint, podList).Return(MockError)
+	assert.False(t, provider.initTrunkFromCheckpoint(mockInstance, fakeTrunk1, podList))
+
+	mockK8sWrapper.EXPECT().GetCNINode(types.NamespacedName{Name: NodeName}).Return(&v1alpha1.CNINode{}, nil)
+	assert.False(t, provider.initTrunkFromCheckpoint(mockInstance, fakeTrunk1, podList))
+}
+
+// TestBranchENIProvider_VerifyTrunk tests the branch ENIs of the trunk are verified and the error is returned so the
+// job is retried
+func TestBranchENIProvider_VerifyTrunk(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	provider := getProvider()
+	result, err := provider.VerifyTrunk(context.TODO(), NodeName)
+	assert.NoError(t, err)
+	assert.Equal(t, k8sCtrl.Result{}, result)
+
+	fakeTrunk1 := mock_trunk.NewMockTrunkENI(ctrl)
+	provider.trunkENICache[NodeName] = fakeTrunk1
+
+	fakeTrunk1.EXPECT().VerifyBranchENIs(gomock.Any()).Return(MockError)
+	_, err = provider.VerifyTrunk(context.TODO(), NodeName)
+	assert.ErrorIs(t, err, MockError)
+
+	fakeTrunk1.EXPECT().VerifyBranchENIs(gomock.Any()).Return(nil)
+	result, err = provider.VerifyTrunk(context.TODO(), NodeName)
+	assert.NoError(t, err)
+	assert.Equal(t, k8sCtrl.Result{}, result)
+}
+
+// TestBranchENIProvider_CheckpointNode tests the trunk is added to the checkpoint if the node has a trunk
+func TestBranchENIProvider_CheckpointNode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	provider := getProvider()
+	checkpoint := &v1alpha1.CNINodeCheckpoint{}
+	provider.CheckpointNode(NodeName, checkpoint)
+	assert.Nil(t, checkpoint.Trunk)
+
+	fakeTrunk1 := mock_trunk.NewMockTrunkENI(ctrl)
+	provider.trunkENICache[NodeName] = fakeTrunk1
+	trunkCheckpoint := &v1alpha1.TrunkCheckpoint{TrunkENIID: "eni-trunk"}
+	fakeTrunk1.EXPECT().Checkpoint().Return(trunkCheckpoint)
+
+	provider.CheckpointNode(NodeName, checkpoint)
+	assert.Equal(t, trunkCheckpoint, checkpoint.Trunk)
+}
+
 func TestBranchENIProvider_Introspect(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -619,6 +872,10 @@ func TestUnSupportedNodeEvents_Windows(t *testing.T) {
 		},
 	}
 
+	mockConditions := mock_condition.NewMockConditions(ctrl)
+	provider.conditions = mockConditions
+
+	mockConditions.EXPECT().IsWindowsSecurityGroupsForPodsEnabled().Return(false)
 	mockInstance.EXPECT().Os().Return(config.OSWindows).Times(1)
 	mockInstance.EXPECT().Type().Return(supportedInstanceType).Times(0)
 	mockInstance.EXPECT().Name().Return(NodeName).Times(0)
@@ -628,3 +885,116 @@ func TestUnSupportedNodeEvents_Windows(t *testing.T) {
 	supported := provider.IsInstanceSupported(mockInstance)
 	assert.False(t, supported)
 }
+
+func TestBranchENIProvider_Supported_Windows(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	provider, _ := getProviderAndMockK8sWrapper(ctrl)
+	mockInstance := mock_ec2.NewMockEC2Instance(ctrl)
+	mockConditions := mock_condition.NewMockConditions(ctrl)
+	provider.conditions = mockConditions
+
+	mockConditions.EXPECT().IsWindowsSecurityGroupsForPodsEnabled().Return(true)
+	mockInstance.EXPECT().Os().Return(config.OSWindows)
+	mockInstance.EXPECT().Type().Return("c5.large")
+
+	supported := provider.IsInstanceSupported(mockInstance)
+	assert.True(t, supported)
+}
+
+// TestBranchENIProvider_CreateAndAnnotateResources_WindowsPod tests that the windows pod is annotated with the IPv4
+// address of the branch ENI in the same patch as the pod-eni annotation
+func TestBranchENIProvider_CreateAndAnnotateResources_WindowsPod(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	provider, mockPodAPI, mockSGPAPI, mockK8sAPI := getProviderAndMocks(ctrl)
+
+	resCount := 1
+	windowsPod := MockPod1.DeepCopy()
+	windowsPod.Spec.NodeSelector = map[string]string{config.NodeLabelOS: config.OSWindows}
+	branchENIs := []*trunk.ENIDetails{{ID: "test-id", IPV4Addr: "192.168.0.10", SubnetCIDR: "192.168.0.0/19"}}
+	expectedAnnotation, _ := json.Marshal(branchENIs)
+	fakeTrunk := mock_trunk.NewMockTrunkENI(ctrl)
+
+	provider.trunkENICache[NodeName] = fakeTrunk
+
+	mockPodAPI.EXPECT().GetPod(MockPodNamespace1, MockPodName1).Return(windowsPod, nil)
+	mockPodAPI.EXPECT().GetPodFromAPIServer(ctx, MockPodNamespace1, MockPodName1).Return(windowsPod, nil)
+	mockSGPAPI.EXPECT().ResolveSecurityGroupsForPod(windowsPod).Return(&utils.SecurityGroupResolution{SecurityGroups: SecurityGroups}, nil)
+	mockK8sAPI.EXPECT().BroadcastEvent(windowsPod, ReasonSecurityGroupRequested, gomock.Any(), v1.EventTypeNormal)
+	fakeTrunk.EXPECT().CreateAndAssociateBranchENIs(gomock.Any(), windowsPod, SecurityGroups, resCount).Return(branchENIs, nil)
+	mockPodAPI.EXPECT().AnnotatePodWithAnnotations(MockPodNamespace1, MockPodName1, MockPodUID1, map[string]string{
+		config.ResourceNameIPAddress: "192.168.0.10/19",
+		config.ResourceNamePodENI:    string(expectedAnnotation),
+	}).Return(nil)
+	mockK8sAPI.EXPECT().BroadcastEvent(windowsPod, ReasonResourceAllocated, gomock.Any(), v1.EventTypeNormal)
+
+	_, err := provider.CreateAndAnnotateResources(context.TODO(), MockPodNamespace1, MockPodName1, resCount)
+
+	assert.NoError(t, err)
+}
+
+// TestBranchENIProvider_CreateAndAnnotateResources_WindowsPod_Annotate_Error tests that the branch ENIs are pushed to
+// the delete queue if the patch of the IPv4 address and the pod-eni annotations fails, so neither annotation is set
+func TestBranchENIProvider_CreateAndAnnotateResources_WindowsPod_Annotate_Error(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	provider, mockPodAPI, mockSGPAPI, mockK8sAPI := getProviderAndMocks(ctrl)
+
+	resCount := 1
+	windowsPod := MockPod1.DeepCopy()
+	windowsPod.Spec.NodeSelector = map[string]string{config.NodeLabelOS: config.OSWindows}
+	branchENIs := []*trunk.ENIDetails{{ID: "test-id", IPV4Addr: "192.168.0.10", SubnetCIDR: "192.168.0.0/19"}}
+	expectedAnnotation, _ := json.Marshal(branchENIs)
+	fakeTrunk := mock_trunk.NewMockTrunkENI(ctrl)
+
+	provider.trunkENICache[NodeName] = fakeTrunk
+
+	mockPodAPI.EXPECT().GetPod(MockPodNamespace1, MockPodName1).Return(windowsPod, nil)
+	mockPodAPI.EXPECT().GetPodFromAPIServer(ctx, MockPodNamespace1, MockPodName1).Return(windowsPod, nil)
+	mockSGPAPI.EXPECT().ResolveSecurityGroupsForPod(windowsPod).Return(&utils.SecurityGroupResolution{SecurityGroups: SecurityGroups}, nil)
+	mockK8sAPI.EXPECT().BroadcastEvent(windowsPod, ReasonSecurityGroupRequested, gomock.Any(), v1.EventTypeNormal)
+	fakeTrunk.EXPECT().CreateAndAssociateBranchENIs(gomock.Any(), windowsPod, SecurityGroups, resCount).Return(branchENIs, nil)
+	mockPodAPI.EXPECT().AnnotatePodWithAnnotations(MockPodNamespace1, MockPodName1, MockPodUID1, map[string]string{
+		config.ResourceNameIPAddress: "192.168.0.10/19",
+		config.ResourceNamePodENI:    string(expectedAnnotation),
+	}).Return(MockError)
+	fakeTrunk.EXPECT().PushENIsToFrontOfDeleteQueue(windowsPod, branchENIs)
+	mockK8sAPI.EXPECT().BroadcastEvent(windowsPod, ReasonBranchENIAnnotationFailed, gomock.Any(), v1.EventTypeWarning)
+
+	_, err := provider.CreateAndAnnotateResources(context.TODO(), MockPodNamespace1, MockPodName1, resCount)
+
+	assert.Error(t, err)
+}
+
+// TestBranchENIProvider_CreateAndAnnotateResources_WindowsPod_InvalidSubnet tests that the windows pod is not annotated
+// and the branch ENIs are pushed to the delete queue if the IPv4 address of the branch ENI can't be formatted
+func TestBranchENIProvider_CreateAndAnnotateResources_WindowsPod_InvalidSubnet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	provider, mockPodAPI, mockSGPAPI, mockK8sAPI := getProviderAndMocks(ctrl)
+
+	resCount := 1
+	windowsPod := MockPod1.DeepCopy()
+	windowsPod.Spec.NodeSelector = map[string]string{config.NodeLabelOS: config.OSWindows}
+	branchENIs := []*trunk.ENIDetails{{ID: "test-id", IPV4Addr: "192.168.0.10", SubnetCIDR: "192.168.0.0"}}
+	fakeTrunk := mock_trunk.NewMockTrunkENI(ctrl)
+
+	provider.trunkENICache[NodeName] = fakeTrunk
+
+	mockPodAPI.EXPECT().GetPod(MockPodNamespace1, MockPodName1).Return(windowsPod, nil)
+	mockPodAPI.EXPECT().GetPodFromAPIServer(ctx, MockPodNamespace1, MockPodName1).Return(windowsPod, nil)
+	mockSGPAPI.EXPECT().ResolveSecurityGroupsForPod(windowsPod).Return(&utils.SecurityGroupResolution{SecurityGroups: SecurityGroups}, nil)
+	mockK8sAPI.EXPECT().BroadcastEvent(windowsPod, ReasonSecurityGroupRequested, gomock.Any(), v1.EventTypeNormal)
+	fakeTrunk.EXPECT().CreateAndAssociateBranchENIs(gomock.Any(), windowsPod, SecurityGroups, resCount).Return(branchENIs, nil)
+	fakeTrunk.EXPECT().PushENIsToFrontOfDeleteQueue(windowsPod, branchENIs)
+	mockK8sAPI.EXPECT().BroadcastEvent(windowsPod, ReasonBranchENIAnnotationFailed, gomock.Any(), v1.EventTypeWarning)
+
+	_, err := provider.CreateAndAnnotateResources(context.TODO(), MockPodNamespace1, MockPodName1, resCount)
+
+	assert.Error(t, err)
+}
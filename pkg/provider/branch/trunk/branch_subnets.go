@@ -0,0 +1,206 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package trunk
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-vpc-resource-controller-k8s/apis/vpcresources/v1alpha1"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+// branchSubnet is a subnet the branch ENIs of the trunk can be created in
+type branchSubnet struct {
+	id          string
+	cidrBlock   string
+	v6CidrBlock string
+	// availableIPs is the number of available IPv4 addresses in the subnet, it's decremented locally for each
+	// branch ENI created in the subnet till the next refresh
+	availableIPs int
+}
+
+// branchSubnets holds the candidate subnets of the branch ENIs configured on the CNINode
+type branchSubnets struct {
+	// lock is used to select the subnets without blocking the other operations on the trunk
+	lock sync.Mutex
+	// spec is the candidate subnets and the selection strategy, the instance subnet is used if nil
+	spec *v1alpha1.BranchENISubnets
+	// candidates is the list of candidate subnets as last described from EC2
+	candidates []*branchSubnet
+	// lastRefreshed is the time the candidate subnets were last described from EC2
+	lastRefreshed time.Time
+	// nextIndex is the index of the next candidate subnet for the round robin strategy
+	nextIndex int
+}
+
+// SetBranchENISubnets sets the candidate subnets of the new branch ENIs, the instance subnet is used if the spec is
+// nil or doesn't select any subnet
+func (t *trunkENI) SetBranchENISubnets(spec *v1alpha1.BranchENISubnets) {
+	if spec != nil && len(spec.SubnetIDs) == 0 && len(spec.SubnetTags) == 0 {
+		spec = nil
+	}
+
+	t.branchSubnets.lock.Lock()
+	defer t.branchSubnets.lock.Unlock()
+
+	if equality.Semantic.DeepEqual(t.branchSubnets.spec, spec) {
+		return
+	}
+
+	t.branchSubnets.spec = spec.DeepCopy()
+	t.branchSubnets.candidates = nil
+	t.branchSubnets.lastRefreshed = time.Time{}
+	t.branchSubnets.nextIndex = 0
+
+	t.log.Info("updated the candidate subnets of the branch ENIs", "branch ENI subnets", spec)
+}
+
+// selectBranchSubnet returns the subnet to create the next branch ENI in as per the selection strategy. The instance
+// subnet is returned if no candidate subnet is configured or all the candidate subnets are out of IPv4 addresses
+func (t *trunkENI) selectBranchSubnet(ctx context.Context) branchSubnet {
+	t.refreshCandidateSubnets(ctx)
+
+	t.branchSubnets.lock.Lock()
+	defer t.branchSubnets.lock.Unlock()
+
+	var selected *branchSubnet
+	candidates := t.branchSubnets.candidates
+	if t.branchSubnets.spec != nil && t.branchSubnets.spec.Strategy == v1alpha1.SubnetSelectionStrategyRoundRobin {
+		for i := 0; i < len(candidates) && selected == nil; i++ {
+			candidate := candidates[(t.branchSubnets.nextIndex+i)%len(candidates)]
+			if candidate.availableIPs > 0 {
+				selected = candidate
+				t.branchSubnets.nextIndex = (t.branchSubnets.nextIndex + i + 1) % len(candidates)
+			}
+		}
+	} else {
+		for _, candidate := range candidates {
+			if candidate.availableIPs > 0 && (selected == nil || candidate.availableIPs > selected.availableIPs) {
+				selected = candidate
+			}
+		}
+	}
+
+	if selected == nil {
+		if len(candidates) > 0 {
+			t.log.Info("candidate subnets are out of IPv4 addresses, will use the instance subnet")
+		}
+		return branchSubnet{
+			id:          t.instance.SubnetID(),
+			cidrBlock:   t.instance.SubnetCidrBlock(),
+			v6CidrBlock: t.instance.SubnetV6CidrBlock(),
+		}
+	}
+
+	selected.availableIPs--
+	return *selected
+}
+
+// candidateSubnetIDs returns the ids of the subnets the new branch ENIs can be created in
+func (t *trunkENI) candidateSubnetIDs(ctx context.Context) []string {
+	t.refreshCandidateSubnets(ctx)
+
+	t.branchSubnets.lock.Lock()
+	defer t.branchSubnets.lock.Unlock()
+
+	if len(t.branchSubnets.candidates) == 0 {
+		return []string{t.instance.SubnetID()}
+	}
+	var subnetIDs []string
+	for _, candidate := range t.branchSubnets.candidates {
+		subnetIDs = append(subnetIDs, candidate.id)
+	}
+	return subnetIDs
+}
+
+// GetBranchENISubnetIDs returns the ids of the candidate subnets as last described from EC2, the subnets are not
+// refreshed and the instance subnet is not included
+func (t *trunkENI) GetBranchENISubnetIDs() []string {
+	t.branchSubnets.lock.Lock()
+	defer t.branchSubnets.lock.Unlock()
+
+	var subnetIDs []string
+	for _, candidate := range t.branchSubnets.candidates {
+		subnetIDs = append(subnetIDs, candidate.id)
+	}
+	return subnetIDs
+}
+
+// refreshCandidateSubnets describes the candidate subnets from EC2 if they were not refreshed in the last refresh
+// interval. The subnets are described without holding the branch subnets lock, so the selection of the subnets by
+// the other routines isn't blocked on EC2. The previous candidates are retained if the refresh fails and the
+// described subnets are discarded if the spec changed in the meantime
+func (t *trunkENI) refreshCandidateSubnets(ctx context.Context) {
+	t.branchSubnets.lock.Lock()
+	spec := t.branchSubnets.spec
+	if spec == nil || time.Since(t.branchSubnets.lastRefreshed) < config.BranchENISubnetRefreshInterval {
+		t.branchSubnets.lock.Unlock()
+		return
+	}
+	// Refresh at most once per interval even on failure to not overwhelm the EC2 API
+	t.branchSubnets.lastRefreshed = time.Now()
+	t.branchSubnets.lock.Unlock()
+
+	subnets, err := t.ec2ApiHelper.GetSubnets(ctx, spec.SubnetIDs, spec.SubnetTags, t.instance.VpcID(),
+		t.instance.AvailabilityZone())
+	if err != nil {
+		trunkENIOperationsErrCount.WithLabelValues("describe_branch_subnets").Inc()
+		t.log.Error(err, "failed to describe the candidate subnets of the branch ENIs")
+		return
+	}
+
+	var candidates []*branchSubnet
+	for _, subnet := range subnets {
+		candidate := &branchSubnet{
+			id:           aws.ToString(subnet.SubnetId),
+			cidrBlock:    aws.ToString(subnet.CidrBlock),
+			availableIPs: int(aws.ToInt32(subnet.AvailableIpAddressCount)),
+		}
+		for _, v6CidrBlock := range subnet.Ipv6CidrBlockAssociationSet {
+			if v6CidrBlock.Ipv6CidrBlock != nil {
+				candidate.v6CidrBlock = *v6CidrBlock.Ipv6CidrBlock
+				break
+			}
+		}
+		candidates = append(candidates, candidate)
+	}
+	// Keep a stable order for the round robin strategy across refreshes
+	slices.SortFunc(candidates, func(a, b *branchSubnet) int {
+		return strings.Compare(a.id, b.id)
+	})
+	if len(candidates) == 0 {
+		t.log.Info("no candidate subnet found in the instance VPC and availability zone",
+			"branch ENI subnets", spec)
+	}
+
+	t.branchSubnets.lock.Lock()
+	defer t.branchSubnets.lock.Unlock()
+
+	// The spec is replaced, not modified, on update so a different pointer means the candidates are stale
+	if t.branchSubnets.spec != spec {
+		t.log.V(1).Info("branch ENI subnets changed during the refresh, discarding the described subnets")
+		return
+	}
+	t.branchSubnets.candidates = candidates
+	if t.branchSubnets.nextIndex >= len(candidates) {
+		t.branchSubnets.nextIndex = 0
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package trunk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-vpc-resource-controller-k8s/apis/vpcresources/v1alpha1"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsEc2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	VpcId            = "vpc-00000000000000000"
+	AvailabilityZone = "us-west-2a"
+
+	CandidateSubnetId1    = "subnet-00000000000000001"
+	CandidateSubnetCidr1  = "10.0.0.0/24"
+	CandidateSubnetV6Cidr = "2600:1::/64"
+	CandidateSubnetId2    = "subnet-00000000000000002"
+	CandidateSubnetCidr2  = "10.0.1.0/24"
+
+	candidateSubnets = []awsEc2Types.Subnet{
+		{
+			SubnetId:                &CandidateSubnetId2,
+			CidrBlock:               &CandidateSubnetCidr2,
+			AvailableIpAddressCount: aws.Int32(2),
+		},
+		{
+			SubnetId:                &CandidateSubnetId1,
+			CidrBlock:               &CandidateSubnetCidr1,
+			AvailableIpAddressCount: aws.Int32(3),
+			Ipv6CidrBlockAssociationSet: []awsEc2Types.SubnetIpv6CidrBlockAssociation{
+				{Ipv6CidrBlock: &CandidateSubnetV6Cidr},
+			},
+		},
+	}
+)
+
+// TestTrunkENI_SetBranchENISubnets tests the candidate subnets are reset only when the spec changes and an empty spec
+// is treated as no spec
+func TestTrunkENI_SetBranchENISubnets(t *testing.T) {
+	trunkENI := getMockTrunk()
+
+	spec := &v1alpha1.BranchENISubnets{SubnetIDs: []string{CandidateSubnetId1}}
+	trunkENI.SetBranchENISubnets(spec)
+	assert.Equal(t, spec, trunkENI.branchSubnets.spec)
+
+	trunkENI.branchSubnets.candidates = []*branchSubnet{{id: CandidateSubnetId1}}
+	trunkENI.branchSubnets.lastRefreshed = time.Now()
+
+	// Same spec doesn't reset the candidates
+	trunkENI.SetBranchENISubnets(spec.DeepCopy())
+	assert.Len(t, trunkENI.branchSubnets.candidates, 1)
+
+	// Changed spec resets the candidates
+	trunkENI.SetBranchENISubnets(&v1alpha1.BranchENISubnets{SubnetIDs: []string{CandidateSubnetId2}})
+	assert.Nil(t, trunkENI.branchSubnets.candidates)
+	assert.True(t, trunkENI.branchSubnets.lastRefreshed.IsZero())
+
+	// Empty spec is same as no spec
+	trunkENI.SetBranchENISubnets(&v1alpha1.BranchENISubnets{Strategy: v1alpha1.SubnetSelectionStrategyRoundRobin})
+	assert.Nil(t, trunkENI.branchSubnets.spec)
+}
+
+// TestTrunkENI_selectBranchSubnet_MostFreeIPs tests the subnet with the most available IPs is selected
+func TestTrunkENI_selectBranchSubnet_MostFreeIPs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	trunkENI, mockEC2APIHelper, mockInstance := getMockHelperInstanceAndTrunkObject(ctrl)
+	trunkENI.SetBranchENISubnets(&v1alpha1.BranchENISubnets{SubnetTags: map[string]string{"pods": "true"}})
+
+	mockInstance.EXPECT().VpcID().Return(VpcId)
+	mockInstance.EXPECT().AvailabilityZone().Return(AvailabilityZone)
+	mockEC2APIHelper.EXPECT().GetSubnets(gomock.Any(), nil, map[string]string{"pods": "true"}, VpcId, AvailabilityZone).
+		Return(candidateSubnets, nil)
+
+	// Subnet 1 has 3 IPs and subnet 2 has 2 IPs, the local count is decremented on each selection and the first
+	// subnet in order is selected on a tie
+	assert.Equal(t, branchSubnet{id: CandidateSubnetId1, cidrBlock: CandidateSubnetCidr1,
+		v6CidrBlock: CandidateSubnetV6Cidr, availableIPs: 2}, trunkENI.selectBranchSubnet(context.TODO()))
+	assert.Equal(t, CandidateSubnetId1, trunkENI.selectBranchSubnet(context.TODO()).id)
+	assert.Equal(t, CandidateSubnetId2, trunkENI.selectBranchSubnet(context.TODO()).id)
+}
+
+// TestTrunkENI_selectBranchSubnet_RoundRobin tests the candidate subnets with available IPs are selected in turn
+func TestTrunkENI_selectBranchSubnet_RoundRobin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	trunkENI, mockEC2APIHelper, mockInstance := getMockHelperInstanceAndTrunkObject(ctrl)
+	trunkENI.SetBranchENISubnets(&v1alpha1.BranchENISubnets{
+		SubnetIDs: []string{CandidateSubnetId1, CandidateSubnetId2},
+		Strategy:  v1alpha1.SubnetSelectionStrategyRoundRobin,
+	})
+
+	mockInstance.EXPECT().VpcID().Return(VpcId)
+	mockInstance.EXPECT().AvailabilityZone().Return(AvailabilityZone)
+	mockEC2APIHelper.EXPECT().GetSubnets(gomock.Any(), []string{CandidateSubnetId1, CandidateSubnetId2}, nil, VpcId,
+		AvailabilityZone).Return(candidateSubnets, nil)
+
+	var selected []string
+	for i := 0; i < 5; i++ {
+		selected = append(selected, trunkENI.selectBranchSubnet(context.TODO()).id)
+	}
+	// Subnet 2 runs out of IPs after two selections
+	assert.Equal(t, []string{CandidateSubnetId1, CandidateSubnetId2, CandidateSubnetId1, CandidateSubnetId2,
+		CandidateSubnetId1}, selected)
+}
+
+// TestTrunkENI_selectBranchSubnet_InstanceSubnet tests the instance subnet is selected if no candidate subnet is
+// configured or the candidate subnets are out of IPs
+func TestTrunkENI_selectBranchSubnet_InstanceSubnet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	trunkENI, _, mockInstance := getMockHelperInstanceAndTrunkObject(ctrl)
+	instanceSubnet := branchSubnet{id: SubnetId, cidrBlock: SubnetCidrBlock, v6CidrBlock: SubnetV6CidrBlock}
+
+	mockInstance.EXPECT().SubnetID().Return(SubnetId).Times(3)
+	mockInstance.EXPECT().SubnetCidrBlock().Return(SubnetCidrBlock).Times(2)
+	mockInstance.EXPECT().SubnetV6CidrBlock().Return(SubnetV6CidrBlock).Times(2)
+
+	assert.Equal(t, instanceSubnet, trunkENI.selectBranchSubnet(context.TODO()))
+	assert.Equal(t, []string{SubnetId}, trunkENI.candidateSubnetIDs(context.TODO()))
+
+	trunkENI.branchSubnets.spec = &v1alpha1.BranchENISubnets{SubnetIDs: []string{CandidateSubnetId1}}
+	trunkENI.branchSubnets.candidates = []*branchSubnet{{id: CandidateSubnetId1, availableIPs: 0}}
+	trunkENI.branchSubnets.lastRefreshed = time.Now()
+	assert.Equal(t, instanceSubnet, trunkENI.selectBranchSubnet(context.TODO()))
+}
+
+// TestTrunkENI_candidateSubnetIDs_RefreshError tests the previous candidate subnets are retained if describing the
+// candidate subnets fails
+func TestTrunkENI_candidateSubnetIDs_RefreshError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	trunkENI, mockEC2APIHelper, mockInstance := getMockHelperInstanceAndTrunkObject(ctrl)
+	trunkENI.branchSubnets.spec = &v1alpha1.BranchENISubnets{SubnetIDs: []string{CandidateSubnetId1}}
+	trunkENI.branchSubnets.candidates = []*branchSubnet{{id: CandidateSubnetId1, availableIPs: 1}}
+
+	mockInstance.EXPECT().VpcID().Return(VpcId)
+	mockInstance.EXPECT().AvailabilityZone().Return(AvailabilityZone)
+	mockEC2APIHelper.EXPECT().GetSubnets(gomock.Any(), []string{CandidateSubnetId1}, nil, VpcId, AvailabilityZone).
+		Return(nil, MockError)
+
+	// The subnets are not described again till the refresh interval elapses
+	assert.Equal(t, []string{CandidateSubnetId1}, trunkENI.candidateSubnetIDs(context.TODO()))
+	assert.Equal(t, []string{CandidateSubnetId1}, trunkENI.candidateSubnetIDs(context.TODO()))
+}
+
+// TestTrunkENI_candidateSubnetIDs_SpecChangedDuringRefresh tests the subnets are described without holding the branch
+// subnets lock and the described subnets are discarded if the spec changed during the refresh
+func TestTrunkENI_candidateSubnetIDs_SpecChangedDuringRefresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	trunkENI, mockEC2APIHelper, mockInstance := getMockHelperInstanceAndTrunkObject(ctrl)
+	trunkENI.branchSubnets.spec = &v1alpha1.BranchENISubnets{SubnetIDs: []string{CandidateSubnetId1}}
+	newSpec := &v1alpha1.BranchENISubnets{SubnetIDs: []string{CandidateSubnetId2}}
+
+	mockInstance.EXPECT().VpcID().Return(VpcId)
+	mockInstance.EXPECT().AvailabilityZone().Return(AvailabilityZone)
+	mockInstance.EXPECT().SubnetID().Return(SubnetId)
+	mockEC2APIHelper.EXPECT().GetSubnets(gomock.Any(), []string{CandidateSubnetId1}, nil, VpcId, AvailabilityZone).
+		DoAndReturn(func(_ context.Context, _ []string, _ map[string]string, _, _ string) ([]awsEc2Types.Subnet, error) {
+			// Would deadlock if the lock was held while describing the subnets
+			trunkENI.SetBranchENISubnets(newSpec)
+			return candidateSubnets[1:], nil
+		})
+
+	assert.Equal(t, []string{SubnetId}, trunkENI.candidateSubnetIDs(context.TODO()))
+	assert.Equal(t, newSpec, trunkENI.branchSubnets.spec)
+	assert.Nil(t, trunkENI.branchSubnets.candidates)
+	assert.True(t, trunkENI.branchSubnets.lastRefreshed.IsZero())
+}
+
+// TestTrunkENI_GetBranchENISubnetIDs tests the candidate subnets are returned without refreshing them or falling back
+// to the instance subnet
+func TestTrunkENI_GetBranchENISubnetIDs(t *testing.T) {
+	trunkENI := getMockTrunk()
+	assert.Nil(t, trunkENI.GetBranchENISubnetIDs())
+
+	trunkENI.branchSubnets.spec = &v1alpha1.BranchENISubnets{SubnetIDs: []string{CandidateSubnetId1}}
+	trunkENI.branchSubnets.candidates = []*branchSubnet{{id: CandidateSubnetId1}, {id: CandidateSubnetId2}}
+	assert.Equal(t, []string{CandidateSubnetId1, CandidateSubnetId2}, trunkENI.GetBranchENISubnetIDs())
+}
+
+// TestTrunkENI_CreateAndAssociateBranchENIs_CandidateSubnet tests the branch ENI is created in the selected candidate
+// subnet with the subnet's CIDR blocks
+func TestTrunkENI_CreateAndAssociateBranchENIs_CandidateSubnet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	trunkENI, mockEC2APIHelper, mockInstance := getMockHelperInstanceAndTrunkObject(ctrl)
+	trunkENI.trunkENIId = trunkId
+	trunkENI.SetBranchENISubnets(&v1alpha1.BranchENISubnets{SubnetIDs: []string{CandidateSubnetId1}})
+
+	mockInstance.EXPECT().Type().Return(InstanceType)
+	mockInstance.EXPECT().VpcID().Return(VpcId)
+	mockInstance.EXPECT().AvailabilityZone().Return(AvailabilityZone)
+	mockEC2APIHelper.EXPECT().GetSubnets(gomock.Any(), []string{CandidateSubnetId1}, nil, VpcId, AvailabilityZone).
+		Return(candidateSubnets[1:], nil)
+	mockEC2APIHelper.EXPECT().CreateNetworkInterface(gomock.Any(), &BranchEniDescription, &CandidateSubnetId1, SecurityGroups,
+		append(vlan1Tag, trunkENI.nodeIDTag...), nil, nil).Return(BranchInterface1, nil)
+	mockEC2APIHelper.EXPECT().AssociateBranchToTrunk(gomock.Any(), &trunkId, &Branch1Id, VlanId1).Return(mockAssociationOutput1, nil)
+
+	eniDetails, err := trunkENI.CreateAndAssociateBranchENIs(context.TODO(), MockPod2, SecurityGroups, 1)
+	assert.NoError(t, err)
+	assert.Len(t, eniDetails, 1)
+	assert.Equal(t, CandidateSubnetId1, eniDetails[0].subnetID)
+	assert.Equal(t, CandidateSubnetCidr1, eniDetails[0].SubnetCIDR)
+	assert.Equal(t, CandidateSubnetV6Cidr, eniDetails[0].SubnetV6CIDR)
+}
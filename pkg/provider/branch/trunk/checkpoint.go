@@ -0,0 +1,317 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package trunk
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/aws/amazon-vpc-resource-controller-k8s/apis/vpcresources/v1alpha1"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Checkpoint returns the state of the trunk and its branch ENIs. The warm branch ENIs are checkpointed as cooled down
+// ENIs of the delete queue, on warm start they are returned to the warm pools or deleted by the next delete queue run
+func (t *trunkENI) Checkpoint() *v1alpha1.TrunkCheckpoint {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if t.trunkENIId == "" {
+		return nil
+	}
+
+	checkpoint := &v1alpha1.TrunkCheckpoint{TrunkENIID: t.trunkENIId}
+	if len(t.uidToBranchENIMap) > 0 {
+		checkpoint.BranchENIs = make(map[string][]v1alpha1.BranchENICheckpoint, len(t.uidToBranchENIMap))
+		for uid, branchENIs := range t.uidToBranchENIMap {
+			var enis []v1alpha1.BranchENICheckpoint
+			for _, eni := range branchENIs {
+				enis = append(enis, toBranchENICheckpoint(eni))
+			}
+			checkpoint.BranchENIs[uid] = enis
+		}
+	}
+	for _, eni := range t.deleteQueue {
+		checkpoint.DeleteQueue = append(checkpoint.DeleteQueue, toBranchENICheckpoint(eni))
+	}
+
+	// Iterate over the warm pools in order so the checkpoint only changes when the state does
+	keys := make([]string, 0, len(t.warmPools))
+	for key := range t.warmPools {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		for _, eni := range t.warmPools[key].enis {
+			warmENI := toBranchENICheckpoint(eni)
+			warmENI.DeletionTimestamp = nil
+			checkpoint.DeleteQueue = append(checkpoint.DeleteQueue, warmENI)
+		}
+	}
+	return checkpoint
+}
+
+// InitTrunkFromCheckpoint initializes the trunk and its branch ENIs from the checkpoint taken by the previous
+// controller without calling EC2. The branch ENIs of the pods deleted since the checkpoint was taken are pushed to the
+// delete queue. An error is returned if a running pod's branch ENIs are not in the checkpoint, in which case the
+// trunk must be initialized from EC2 instead
+func (t *trunkENI) InitTrunkFromCheckpoint(checkpoint *v1alpha1.TrunkCheckpoint, podList []v1.Pod) error {
+	if checkpoint == nil || checkpoint.TrunkENIID == "" {
+		return fmt.Errorf("checkpoint has no trunk interface")
+	}
+
+	// Validate the checkpoint before changing the trunk's state
+	for _, checkpointENIs := range checkpoint.BranchENIs {
+		if err := validateVlanIDs(checkpointENIs); err != nil {
+			return err
+		}
+	}
+	if err := validateVlanIDs(checkpoint.DeleteQueue); err != nil {
+		return err
+	}
+	// runningPods is the node name of the running pods by UID
+	runningPods := make(map[string]string)
+	for _, pod := range podList {
+		pod := pod // Fix gosec G601, so we can use &pod
+		eniListFromPod := t.getBranchInterfacesUsedByPod(&pod)
+		if len(eniListFromPod) == 0 {
+			continue
+		}
+		uid := string(pod.UID)
+		checkpointENIs := checkpoint.BranchENIs[uid]
+		if !slices.Equal(branchENIIDs(eniListFromPod), checkpointBranchENIIDs(checkpointENIs)) {
+			return fmt.Errorf("branch interfaces of pod %s/%s don't match the checkpoint", pod.Namespace, pod.Name)
+		}
+		runningPods[uid] = pod.Spec.NodeName
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.trunkENIId = checkpoint.TrunkENIID
+	for uid, checkpointENIs := range checkpoint.BranchENIs {
+		var branchENIs []*ENIDetails
+		for _, checkpointENI := range checkpointENIs {
+			eni := fromBranchENICheckpoint(checkpointENI)
+			t.usedVlanIds[eni.VlanID] = true
+			branchENIs = append(branchENIs, eni)
+		}
+		if nodeName, isRunning := runningPods[uid]; isRunning {
+			t.uidToBranchENIMap[uid] = branchENIs
+			t.indexBranchENIs(uid, nodeName, branchENIs)
+			continue
+		}
+		// Pod could have been deleted since the checkpoint was taken, the actual deletion time is not known
+		for _, eni := range branchENIs {
+			eni.deletionTimeStamp = time.Now()
+			t.deleteQueue = append(t.deleteQueue, eni)
+		}
+		t.log.Info("pushing eni to delete queue as pod no longer exists", "pod uid", uid, "eni", branchENIs)
+	}
+	for _, checkpointENI := range checkpoint.DeleteQueue {
+		eni := fromBranchENICheckpoint(checkpointENI)
+		t.usedVlanIds[eni.VlanID] = true
+		t.deleteQueue = append(t.deleteQueue, eni)
+	}
+
+	t.log.V(1).Info("successfully initialized trunk from checkpoint", "trunk", t.trunkENIId,
+		"branch interfaces", t.uidToBranchENIMap, "delete queue", t.deleteQueue)
+
+	return nil
+}
+
+// VerifyBranchENIs verifies the cached branch ENIs against the branch ENIs associated with the trunk in EC2 after the
+// trunk was initialized from a checkpoint. Associated branch ENIs unknown to the cache are pushed to the delete queue
+// and the ENIs of the delete queue that no longer exist are removed from it
+func (t *trunkENI) VerifyBranchENIs(ctx context.Context) error {
+	// Only verify the ENIs known before describing the branch ENIs, as the ENIs created after can be missing in EC2
+	knownENIs := t.cachedBranchENIIDs()
+
+	branchInterfaces, err := t.ec2ApiHelper.GetBranchNetworkInterface(ctx, &t.trunkENIId)
+	if err != nil {
+		trunkENIOperationsErrCount.WithLabelValues("verify_branch_enis").Inc()
+		return err
+	}
+	associatedBranchInterfaces := make(map[string]struct{}, len(branchInterfaces))
+	for _, branchInterface := range branchInterfaces {
+		associatedBranchInterfaces[aws.ToString(branchInterface.NetworkInterfaceId)] = struct{}{}
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	// Branch ENIs of the pods deleted in EC2 are logged, they are removed from the cache when the pods are deleted
+	for uid, branchENIs := range t.uidToBranchENIMap {
+		for _, eni := range branchENIs {
+			if _, isPresent := associatedBranchInterfaces[eni.ID]; !isPresent && knownENIs.Has(eni.ID) {
+				t.log.Error(fmt.Errorf("eni allocated to pod not found in ec2"), "eni not found", "pod uid", uid,
+					"eni", eni)
+				trunkENIOperationsErrCount.WithLabelValues("get_branch_eni_from_ec2").Inc()
+			}
+		}
+	}
+
+	// Remove the ENIs that were already deleted from the delete queue
+	t.deleteQueue = slices.DeleteFunc(t.deleteQueue, func(eni *ENIDetails) bool {
+		if _, isPresent := associatedBranchInterfaces[eni.ID]; isPresent || !knownENIs.Has(eni.ID) {
+			return false
+		}
+		t.log.Info("removing eni from delete queue as it no longer exists", "eni", eni.ID)
+		t.usedVlanIds[eni.VlanID] = false
+		return true
+	})
+
+	// Push the branch ENIs that don't belong to any pod to the delete queue
+	cachedENIs := t.cachedBranchENIIDsLocked()
+	for _, branchInterface := range branchInterfaces {
+		eniID := aws.ToString(branchInterface.NetworkInterfaceId)
+		if cachedENIs.Has(eniID) {
+			continue
+		}
+		vlanId, err := t.getVlanIdFromTag(branchInterface.TagSet)
+		if err != nil {
+			trunkENIOperationsErrCount.WithLabelValues("get_vlan_from_tag").Inc()
+			t.log.Error(err, "failed to find vlan id", "interface", eniID)
+			continue
+		}
+		// The vlan of a branch ENI being created is assigned before the ENI is added to the cache
+		if vlanId <= 0 || vlanId >= len(t.usedVlanIds) || t.usedVlanIds[vlanId] {
+			continue
+		}
+		t.log.Info("pushing eni to delete queue as no pod owns it", "eni", eniID)
+		t.usedVlanIds[vlanId] = true
+		t.deleteQueue = append(t.deleteQueue, &ENIDetails{
+			ID:                eniID,
+			VlanID:            vlanId,
+			deletionTimeStamp: time.Now(),
+		})
+	}
+
+	return nil
+}
+
+// eniIDSet is the set of branch ENI IDs
+type eniIDSet map[string]struct{}
+
+func (s eniIDSet) Has(eniID string) bool {
+	_, found := s[eniID]
+	return found
+}
+
+// cachedBranchENIIDs returns the IDs of all the branch ENIs in the cache
+func (t *trunkENI) cachedBranchENIIDs() eniIDSet {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return t.cachedBranchENIIDsLocked()
+}
+
+// cachedBranchENIIDsLocked returns the IDs of all the branch ENIs in the cache, the caller must hold the lock
+func (t *trunkENI) cachedBranchENIIDsLocked() eniIDSet {
+	ids := make(eniIDSet)
+	for _, branchENIs := range t.uidToBranchENIMap {
+		for _, eni := range branchENIs {
+			ids[eni.ID] = struct{}{}
+		}
+	}
+	for _, eni := range t.deleteQueue {
+		ids[eni.ID] = struct{}{}
+	}
+	for _, pool := range t.warmPools {
+		for _, eni := range pool.enis {
+			ids[eni.ID] = struct{}{}
+		}
+	}
+	return ids
+}
+
+// validateVlanIDs returns an error if a checkpointed branch ENI has a vlan ID that cannot be allocated
+func validateVlanIDs(enis []v1alpha1.BranchENICheckpoint) error {
+	for _, eni := range enis {
+		if eni.VlanID <= 0 || eni.VlanID >= MaxAllocatableVlanIds {
+			return fmt.Errorf("branch interface %s has invalid vlan id %d", eni.ID, eni.VlanID)
+		}
+	}
+	return nil
+}
+
+func branchENIIDs(enis []*ENIDetails) []string {
+	ids := make([]string, 0, len(enis))
+	for _, eni := range enis {
+		ids = append(ids, eni.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func checkpointBranchENIIDs(enis []v1alpha1.BranchENICheckpoint) []string {
+	ids := make([]string, 0, len(enis))
+	for _, eni := range enis {
+		ids = append(ids, eni.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func toBranchENICheckpoint(eni *ENIDetails) v1alpha1.BranchENICheckpoint {
+	checkpoint := v1alpha1.BranchENICheckpoint{
+		ID:               eni.ID,
+		MACAddress:       eni.MACAdd,
+		IPv4Address:      eni.IPV4Addr,
+		IPv6Address:      eni.IPV6Addr,
+		VlanID:           eni.VlanID,
+		AssociationID:    eni.AssociationID,
+		SubnetID:         eni.subnetID,
+		SubnetCIDR:       eni.SubnetCIDR,
+		SubnetV6CIDR:     eni.SubnetV6CIDR,
+		SecurityGroups:   eni.securityGroups,
+		DeleteRetryCount: eni.deleteRetryCount,
+	}
+	if eni.securityGroupsModified {
+		// The security groups of the modified branch ENI are left unknown so the next leader doesn't reuse it
+		checkpoint.SecurityGroups = nil
+	}
+	if !eni.deletionTimeStamp.IsZero() {
+		// The time is serialized with a precision of seconds
+		deletionTimestamp := metav1.NewTime(eni.deletionTimeStamp).Rfc3339Copy()
+		checkpoint.DeletionTimestamp = &deletionTimestamp
+	}
+	return checkpoint
+}
+
+func fromBranchENICheckpoint(checkpoint v1alpha1.BranchENICheckpoint) *ENIDetails {
+	eni := &ENIDetails{
+		ID:               checkpoint.ID,
+		MACAdd:           checkpoint.MACAddress,
+		IPV4Addr:         checkpoint.IPv4Address,
+		IPV6Addr:         checkpoint.IPv6Address,
+		VlanID:           checkpoint.VlanID,
+		AssociationID:    checkpoint.AssociationID,
+		subnetID:         checkpoint.SubnetID,
+		SubnetCIDR:       checkpoint.SubnetCIDR,
+		SubnetV6CIDR:     checkpoint.SubnetV6CIDR,
+		securityGroups:   slices.Clone(checkpoint.SecurityGroups),
+		deleteRetryCount: checkpoint.DeleteRetryCount,
+	}
+	if checkpoint.DeletionTimestamp != nil {
+		eni.deletionTimeStamp = checkpoint.DeletionTimestamp.Time
+	}
+	return eni
+}
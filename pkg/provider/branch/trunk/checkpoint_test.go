@@ -0,0 +1,193 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package trunk
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-vpc-resource-controller-k8s/apis/vpcresources/v1alpha1"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsEc2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	coolingENI = &ENIDetails{ID: "eni-00000000000000003", VlanID: 3, subnetID: SubnetId,
+		securityGroups: []string{"sg-1"}, deletionTimeStamp: time.Now().UTC().Truncate(time.Second), deleteRetryCount: 1}
+	warmENI = &ENIDetails{ID: "eni-00000000000000004", VlanID: 4, subnetID: SubnetId,
+		securityGroups: []string{"sg-1"}}
+)
+
+func vlanTag(vlanID int) []awsEc2Types.Tag {
+	return []awsEc2Types.Tag{{Key: aws.String(config.VLandIDTag), Value: aws.String(strconv.Itoa(vlanID))}, trunkIDTag}
+}
+
+// TestTrunkENI_Checkpoint_InitTrunkFromCheckpoint tests the trunk initialized from the checkpoint of another trunk has
+// the same branch ENIs, and the warm ENIs are restored as cooled down ENIs of the delete queue
+func TestTrunkENI_Checkpoint_InitTrunkFromCheckpoint(t *testing.T) {
+	trunkENI := getMockTrunk()
+	assert.Nil(t, trunkENI.Checkpoint())
+
+	trunkENI.trunkENIId = trunkId
+	podENIs := withSecurityGroups(SecurityGroups, EniDetails1, EniDetails2)
+	trunkENI.uidToBranchENIMap[PodUID] = podENIs
+	trunkENI.deleteQueue = []*ENIDetails{coolingENI}
+	trunkENI.warmPools["sg-1"] = &warmPool{securityGroups: []string{"sg-1"}, enis: []*ENIDetails{warmENI}}
+
+	checkpoint := trunkENI.Checkpoint()
+	assert.Equal(t, trunkId, checkpoint.TrunkENIID)
+	assert.Len(t, checkpoint.BranchENIs[PodUID], 2)
+	assert.Equal(t, v1alpha1.BranchENICheckpoint{ID: warmENI.ID, VlanID: warmENI.VlanID, SubnetID: SubnetId,
+		SecurityGroups: []string{"sg-1"}}, checkpoint.DeleteQueue[1])
+
+	newTrunkENI := getMockTrunk()
+	err := newTrunkENI.InitTrunkFromCheckpoint(checkpoint, []v1.Pod{*MockPod1})
+	assert.NoError(t, err)
+
+	assert.Equal(t, trunkId, newTrunkENI.trunkENIId)
+	assert.Equal(t, map[string][]*ENIDetails{PodUID: podENIs}, newTrunkENI.uidToBranchENIMap)
+	assert.Equal(t, []*ENIDetails{coolingENI, warmENI}, newTrunkENI.deleteQueue)
+	for _, vlanID := range []int{VlanId1, VlanId2, coolingENI.VlanID, warmENI.VlanID} {
+		assert.True(t, newTrunkENI.usedVlanIds[vlanID])
+	}
+}
+
+// TestTrunkENI_InitTrunkFromCheckpoint_PodDeleted tests the branch ENIs of the pods deleted since the checkpoint was
+// taken are pushed to the delete queue
+func TestTrunkENI_InitTrunkFromCheckpoint_PodDeleted(t *testing.T) {
+	trunkENI := getMockTrunk()
+	checkpoint := &v1alpha1.TrunkCheckpoint{
+		TrunkENIID: trunkId,
+		BranchENIs: map[string][]v1alpha1.BranchENICheckpoint{PodUID2: {{ID: Branch2Id, VlanID: VlanId2}}},
+	}
+
+	err := trunkENI.InitTrunkFromCheckpoint(checkpoint, []v1.Pod{*MockPod2})
+	assert.NoError(t, err)
+
+	assert.Empty(t, trunkENI.uidToBranchENIMap)
+	assert.Len(t, trunkENI.deleteQueue, 1)
+	assert.Equal(t, Branch2Id, trunkENI.deleteQueue[0].ID)
+	assert.False(t, trunkENI.deleteQueue[0].deletionTimeStamp.IsZero())
+	assert.True(t, trunkENI.usedVlanIds[VlanId2])
+}
+
+// TestTrunkENI_InitTrunkFromCheckpoint_Invalid tests an error is returned without changing the trunk if the checkpoint
+// doesn't have the branch ENIs of a running pod or has an invalid vlan ID
+func TestTrunkENI_InitTrunkFromCheckpoint_Invalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		checkpoint *v1alpha1.TrunkCheckpoint
+	}{
+		{
+			name:       "no trunk",
+			checkpoint: nil,
+		},
+		{
+			name:       "missing pod",
+			checkpoint: &v1alpha1.TrunkCheckpoint{TrunkENIID: trunkId},
+		},
+		{
+			name: "different branch ENIs",
+			checkpoint: &v1alpha1.TrunkCheckpoint{
+				TrunkENIID: trunkId,
+				BranchENIs: map[string][]v1alpha1.BranchENICheckpoint{PodUID: {{ID: Branch1Id, VlanID: VlanId1}}},
+			},
+		},
+		{
+			name: "invalid vlan id",
+			checkpoint: &v1alpha1.TrunkCheckpoint{
+				TrunkENIID: trunkId,
+				BranchENIs: map[string][]v1alpha1.BranchENICheckpoint{PodUID: {{ID: Branch1Id, VlanID: VlanId1},
+					{ID: Branch2Id, VlanID: VlanId2}}},
+				DeleteQueue: []v1alpha1.BranchENICheckpoint{{ID: coolingENI.ID, VlanID: MaxAllocatableVlanIds}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			trunkENI := getMockTrunk()
+			err := trunkENI.InitTrunkFromCheckpoint(test.checkpoint, []v1.Pod{*MockPod1})
+			assert.Error(t, err)
+			assert.Empty(t, trunkENI.trunkENIId)
+			assert.Empty(t, trunkENI.uidToBranchENIMap)
+			assert.Empty(t, trunkENI.deleteQueue)
+		})
+	}
+}
+
+// TestTrunkENI_VerifyBranchENIs tests the unknown branch ENIs are pushed to the delete queue, the deleted ENIs are
+// removed from the delete queue and the ENIs being created are ignored
+func TestTrunkENI_VerifyBranchENIs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	trunkENI, mockEC2APIHelper, _ := getMockHelperInstanceAndTrunkObject(ctrl)
+	trunkENI.trunkENIId = trunkId
+	trunkENI.uidToBranchENIMap[PodUID] = []*ENIDetails{EniDetails1}
+	trunkENI.deleteQueue = []*ENIDetails{{ID: coolingENI.ID, VlanID: coolingENI.VlanID}}
+	for _, vlanID := range []int{VlanId1, coolingENI.VlanID, 6} {
+		trunkENI.usedVlanIds[vlanID] = true
+	}
+
+	unknownENIID, creatingENIID := "eni-00000000000000005", "eni-00000000000000006"
+	mockEC2APIHelper.EXPECT().GetBranchNetworkInterface(gomock.Any(), &trunkId).Return([]*awsEc2Types.NetworkInterface{
+		{NetworkInterfaceId: &Branch1Id, TagSet: vlan1Tag},
+		{NetworkInterfaceId: &unknownENIID, TagSet: vlanTag(5)},
+		// The vlan of the branch ENI being created is already assigned
+		{NetworkInterfaceId: &creatingENIID, TagSet: vlanTag(6)},
+	}, nil)
+
+	err := trunkENI.VerifyBranchENIs(context.TODO())
+	assert.NoError(t, err)
+
+	assert.Equal(t, []*ENIDetails{EniDetails1}, trunkENI.uidToBranchENIMap[PodUID])
+	assert.Len(t, trunkENI.deleteQueue, 1)
+	assert.Equal(t, unknownENIID, trunkENI.deleteQueue[0].ID)
+	assert.Equal(t, 5, trunkENI.deleteQueue[0].VlanID)
+	assert.True(t, trunkENI.usedVlanIds[5])
+	assert.False(t, trunkENI.usedVlanIds[coolingENI.VlanID])
+}
+
+// TestTrunkENI_VerifyBranchENIs_Error tests the error is returned if the branch ENIs cannot be described
+func TestTrunkENI_VerifyBranchENIs_Error(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	trunkENI, mockEC2APIHelper, _ := getMockHelperInstanceAndTrunkObject(ctrl)
+	trunkENI.trunkENIId = trunkId
+	trunkENI.deleteQueue = []*ENIDetails{{ID: coolingENI.ID, VlanID: coolingENI.VlanID}}
+
+	mockEC2APIHelper.EXPECT().GetBranchNetworkInterface(gomock.Any(), &trunkId).Return(nil, MockError)
+
+	err := trunkENI.VerifyBranchENIs(context.TODO())
+	assert.ErrorIs(t, err, MockError)
+	assert.Len(t, trunkENI.deleteQueue, 1)
+}
+
+// TestToBranchENICheckpoint tests the deletion time is checkpointed with a precision of seconds
+func TestToBranchENICheckpoint(t *testing.T) {
+	deletionTime := time.Date(2024, 1, 1, 0, 0, 1, 500, time.UTC)
+	checkpoint := toBranchENICheckpoint(&ENIDetails{ID: Branch1Id, deletionTimeStamp: deletionTime})
+
+	assert.Equal(t, &metav1.Time{Time: deletionTime.Truncate(time.Second)}, checkpoint.DeletionTimestamp)
+}
@@ -14,6 +14,7 @@
 package trunk
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"slices"
@@ -22,16 +23,18 @@ import (
 	"sync"
 	"time"
 
+	"github.com/aws/amazon-vpc-resource-controller-k8s/apis/vpcresources/v1alpha1"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/ec2"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/ec2/api"
 	ec2Errors "github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/errors"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/vpc"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/lookup"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider/branch/cooldown"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/tracing"
 	"github.com/samber/lo"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	awsEc2 "github.com/aws/aws-sdk-go-v2/service/ec2"
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/go-logr/logr"
 	"github.com/prometheus/client_golang/prometheus"
@@ -90,21 +93,35 @@ var (
 
 type TrunkENI interface {
 	// InitTrunk initializes trunk interface
-	InitTrunk(instance ec2.EC2Instance, pods []v1.Pod) error
+	InitTrunk(ctx context.Context, instance ec2.EC2Instance, pods []v1.Pod) error
 	// CreateAndAssociateBranchENIs creates and associate branch interface/s to trunk interface
-	CreateAndAssociateBranchENIs(pod *v1.Pod, securityGroups []string, eniCount int) ([]*ENIDetails, error)
+	CreateAndAssociateBranchENIs(ctx context.Context, pod *v1.Pod, securityGroups []string, eniCount int) ([]*ENIDetails, error)
 	// PushBranchENIsToCoolDownQueue pushes the branch interface belonging to the pod to the cool down queue
 	PushBranchENIsToCoolDownQueue(UID string)
 	// DeleteCooledDownENIs deletes the interfaces that have been sitting in the queue for cool down period
-	DeleteCooledDownENIs()
+	DeleteCooledDownENIs(ctx context.Context)
 	// Reconcile compares the cache state with the list of pods to identify events that were missed and clean up the dangling interfaces
 	Reconcile(pods []v1.Pod) bool
 	// PushENIsToFrontOfDeleteQueue pushes the eni network interfaces to the front of the delete queue
 	PushENIsToFrontOfDeleteQueue(*v1.Pod, []*ENIDetails)
 	// DeleteAllBranchENIs deletes all the branch ENI associated with the trunk and also clears the cool down queue
-	DeleteAllBranchENIs()
+	DeleteAllBranchENIs(ctx context.Context)
 	// Introspect returns the state of the Trunk ENI
 	Introspect() IntrospectResponse
+	// ReconcileWarmPool creates or deletes the warm branch interfaces to reach the desired size of each warm pool
+	ReconcileWarmPool(ctx context.Context)
+	// SetBranchENISubnets sets the candidate subnets and the selection strategy for the new branch interfaces
+	SetBranchENISubnets(spec *v1alpha1.BranchENISubnets)
+	// GetBranchENISubnetIDs returns the ids of the candidate subnets last described for the new branch interfaces
+	GetBranchENISubnetIDs() []string
+	// Checkpoint returns the state of the trunk and its branch interfaces
+	Checkpoint() *v1alpha1.TrunkCheckpoint
+	// InitTrunkFromCheckpoint initializes the trunk interface from the checkpoint without calling EC2
+	InitTrunkFromCheckpoint(checkpoint *v1alpha1.TrunkCheckpoint, pods []v1.Pod) error
+	// VerifyBranchENIs verifies the branch interfaces initialized from the checkpoint against EC2
+	VerifyBranchENIs(ctx context.Context) error
+	// UpdateBranchENISecurityGroups records the security groups modified on the branch interface used by the pod
+	UpdateBranchENISecurityGroups(UID string, eniID string, securityGroups []string)
 }
 
 // trunkENI is the first trunk network interface of an instance
@@ -127,6 +144,14 @@ type trunkENI struct {
 	deleteQueue []*ENIDetails
 	// nodeName tag is the tag added to trunk and branch ENIs created on the node
 	nodeIDTag []ec2types.Tag
+	// warmPoolConfig is the configuration of the warm pools, the warm pools are disabled if nil
+	warmPoolConfig *config.WarmPoolConfig
+	// warmPools is the map of security groups key to the pool of warm branch ENIs created with those security groups
+	warmPools map[string]*warmPool
+	// branchSubnets is the candidate subnets of the new branch ENIs
+	branchSubnets branchSubnets
+	// index records the pods the branch ENIs are assigned to for the lookup of the introspection API
+	index *lookup.Index
 }
 
 // PodENI is a json convertible structure that stores the Branch ENI details that can be
@@ -150,6 +175,14 @@ type ENIDetails struct {
 	deleteRetryCount int
 	// ID of association between branch and trunk ENI
 	AssociationID string `json:"associationID"`
+	// securityGroups is the sorted list of security groups of the branch ENI, it's not known for the branch ENIs
+	// loaded from the pod annotation
+	securityGroups []string
+	// securityGroupsModified is set when the security groups of the branch ENI were modified while it was used by a
+	// pod, the branch ENI is then not returned to the warm pool or reused without verifying its security groups
+	securityGroupsModified bool
+	// subnetID is the subnet of the branch ENI, the branch ENIs of a trunk can span multiple subnets
+	subnetID string
 }
 
 type IntrospectResponse struct {
@@ -157,6 +190,7 @@ type IntrospectResponse struct {
 	InstanceID     string
 	PodToBranchENI map[string][]ENIDetails
 	DeleteQueue    []ENIDetails
+	WarmPools      map[string][]ENIDetails
 }
 
 type IntrospectSummaryResponse struct {
@@ -164,10 +198,12 @@ type IntrospectSummaryResponse struct {
 	InstanceID     string
 	BranchENICount int
 	DeleteQueueLen int
+	WarmPoolLen    int
 }
 
 // NewTrunkENI returns a new Trunk ENI interface.
-func NewTrunkENI(logger logr.Logger, instance ec2.EC2Instance, helper api.EC2APIHelper) TrunkENI {
+func NewTrunkENI(logger logr.Logger, instance ec2.EC2Instance, helper api.EC2APIHelper,
+	warmPoolConfig *config.WarmPoolConfig, index *lookup.Index) TrunkENI {
 	availVlans := make([]bool, MaxAllocatableVlanIds)
 	// VlanID 0 cannot be assigned.
 	availVlans[0] = true
@@ -184,6 +220,9 @@ func NewTrunkENI(logger logr.Logger, instance ec2.EC2Instance, helper api.EC2API
 				Value: aws.String(instance.InstanceID()),
 			},
 		},
+		warmPoolConfig: warmPoolConfig,
+		warmPools:      make(map[string]*warmPool),
+		index:          index,
 	}
 }
 
@@ -200,11 +239,11 @@ func PrometheusRegister() {
 
 // InitTrunk initializes the trunk network interface and all it's associated branch network interfaces by making calls
 // to EC2 API
-func (t *trunkENI) InitTrunk(instance ec2.EC2Instance, podList []v1.Pod) error {
+func (t *trunkENI) InitTrunk(ctx context.Context, instance ec2.EC2Instance, podList []v1.Pod) error {
 	instanceID := t.instance.InstanceID()
 	log := t.log.WithValues("request", "initialize", "instance ID", instanceID)
 
-	nwInterfaces, err := t.ec2ApiHelper.GetInstanceNetworkInterface(&instanceID)
+	nwInterfaces, err := t.ec2ApiHelper.GetInstanceNetworkInterface(ctx, &instanceID)
 	if err != nil {
 		trunkENIOperationsErrCount.WithLabelValues("describe_instance_nw_interface").Inc()
 		return err
@@ -220,7 +259,7 @@ func (t *trunkENI) InitTrunk(instance ec2.EC2Instance, podList []v1.Pod) error {
 		}
 		if *nwInterface.InterfaceType == "trunk" {
 			// Check that the trunkENI is in attached state before adding to cache
-			if err = t.ec2ApiHelper.WaitForNetworkInterfaceStatusChange(nwInterface.NetworkInterfaceId, string(ec2types.AttachmentStatusAttached)); err == nil {
+			if err = t.ec2ApiHelper.WaitForNetworkInterfaceStatusChange(ctx, nwInterface.NetworkInterfaceId, string(ec2types.AttachmentStatusAttached)); err == nil {
 				t.trunkENIId = *nwInterface.NetworkInterfaceId
 			} else {
 				return fmt.Errorf("failed to verify network interface status attached for %v", *nwInterface.NetworkInterfaceId)
@@ -238,8 +277,8 @@ func (t *trunkENI) InitTrunk(instance ec2.EC2Instance, podList []v1.Pod) error {
 			return err
 		}
 
-		trunk, err := t.ec2ApiHelper.CreateAndAttachNetworkInterface(&instanceID, aws.String(t.instance.SubnetID()),
-			t.instance.CurrentInstanceSecurityGroups(), t.nodeIDTag, &freeIndex, &TrunkEniDescription, &InterfaceTypeTrunk, nil)
+		trunk, err := t.ec2ApiHelper.CreateAndAttachNetworkInterface(ctx, &instanceID,
+			aws.String(t.instance.SubnetID()), t.instance.CurrentInstanceSecurityGroups(), t.nodeIDTag, &freeIndex, &TrunkEniDescription, &InterfaceTypeTrunk, nil)
 		if err != nil {
 			trunkENIOperationsErrCount.WithLabelValues("create_trunk_eni").Inc()
 			return err
@@ -286,8 +325,8 @@ func (t *trunkENI) InitTrunk(instance ec2.EC2Instance, podList []v1.Pod) error {
 		}
 	}
 
-	// Get the list of branch ENIs
-	branchInterfaces, err := t.ec2ApiHelper.GetBranchNetworkInterface(&t.trunkENIId, aws.String(t.instance.SubnetID()))
+	// Get the list of branch ENIs, the branch ENIs can be in any of the candidate subnets
+	branchInterfaces, err := t.ec2ApiHelper.GetBranchNetworkInterface(ctx, &t.trunkENIId)
 	if err != nil {
 		return err
 	}
@@ -307,7 +346,7 @@ func (t *trunkENI) InitTrunk(instance ec2.EC2Instance, podList []v1.Pod) error {
 		}
 		var branchENIs []*ENIDetails
 		for _, eni := range eniListFromPod {
-			_, isPresent := associatedBranchInterfaces[eni.ID]
+			branchInterface, isPresent := associatedBranchInterfaces[eni.ID]
 			if !isPresent {
 				t.log.Error(fmt.Errorf("eni allocated to pod not found in ec2"), "eni not found", "eni", eni)
 				trunkENIOperationsErrCount.WithLabelValues("get_branch_eni_from_ec2").Inc()
@@ -315,11 +354,14 @@ func (t *trunkENI) InitTrunk(instance ec2.EC2Instance, podList []v1.Pod) error {
 			}
 			// Mark the Vlan ID from the pod's annotation
 			t.markVlanAssigned(eni.VlanID)
+			// The subnet is not part of the pod's annotation as the branch ENIs can span multiple subnets
+			eni.subnetID = aws.ToString(branchInterface.SubnetId)
 
 			branchENIs = append(branchENIs, eni)
 			delete(associatedBranchInterfaces, eni.ID)
 		}
 		t.uidToBranchENIMap[string(pod.UID)] = branchENIs
+		t.indexBranchENIs(string(pod.UID), pod.Spec.NodeName, branchENIs)
 	}
 
 	// Delete the branch ENI that don't belong to any pod.
@@ -374,6 +416,7 @@ func (t *trunkENI) Reconcile(pods []v1.Pod) bool {
 				t.deleteQueue = append(t.deleteQueue, eni)
 			}
 			delete(t.uidToBranchENIMap, uid)
+			t.releaseBranchENIs(branchENIs)
 			t.log.Info("leaked eni pushed to delete queue, deleted non-existing pod", "pod uid", uid, "eni", branchENIs)
 		}
 	}
@@ -383,7 +426,11 @@ func (t *trunkENI) Reconcile(pods []v1.Pod) bool {
 
 // CreateAndAssociateBranchToTrunk creates a new branch network interface and associates the branch to the trunk
 // network interface. It returns a Json convertible structure which has all the required details of the branch ENI
-func (t *trunkENI) CreateAndAssociateBranchENIs(pod *v1.Pod, securityGroups []string, eniCount int) ([]*ENIDetails, error) {
+func (t *trunkENI) CreateAndAssociateBranchENIs(ctx context.Context, pod *v1.Pod, securityGroups []string,
+	eniCount int) (branchENIs []*ENIDetails, err error) {
+	ctx, span := tracing.Start(ctx, "trunkENI.CreateAndAssociateBranchENIs", tracing.PodAttributes(pod)...)
+	defer func() { tracing.End(span, err) }()
+
 	log := t.log.WithValues("request", "create", "pod namespace", pod.Namespace, "pod name", pod.Name)
 
 	branchENI, isPresent := t.getBranchFromCache(string(pod.UID))
@@ -392,88 +439,61 @@ func (t *trunkENI) CreateAndAssociateBranchENIs(pod *v1.Pod, securityGroups []st
 		return nil, fmt.Errorf("cannot create new eni entry already exist, older entry : %v", branchENI)
 	}
 
-	if !t.canCreateMore() {
-		return nil, ErrCurrentlyAtMaxCapacity
-	}
-
 	// If the security group is empty use the instance security group
 	if securityGroups == nil || len(securityGroups) == 0 {
 		securityGroups = t.instance.CurrentInstanceSecurityGroups()
 	}
 
-	var newENIs []*ENIDetails
-	var err error
-	var nwInterface *ec2types.NetworkInterface
-	var vlanID int
-
-	for i := 0; i < eniCount; i++ {
-		// Assign VLAN
-		vlanID, err = t.assignVlanId()
-		if err != nil {
-			err = fmt.Errorf("assigning vlad id, %w", err)
-			trunkENIOperationsErrCount.WithLabelValues("assign_vlan_id").Inc()
-			break
-		}
-
-		// Vlan ID tag workaround, as describe trunk association is not supported with assumed role
-		tags := []ec2types.Tag{
-			{
-				Key:   aws.String(config.VLandIDTag),
-				Value: aws.String(strconv.Itoa(vlanID)),
-			},
-			{
-				Key:   aws.String(config.TrunkENIIDTag),
-				Value: &t.trunkENIId,
-			},
+	// Use the warm branch ENIs created with the same security groups first
+	warmENIs := t.popENIsFromWarmPool(securityGroups, eniCount)
+	// Reuse the cooled down branch ENIs with the same security groups and in a candidate subnet instead of creating new ones
+	var reusedENIs []*ENIDetails
+	if len(warmENIs) < eniCount {
+		reusedENIs = t.verifyReusedENIs(ctx, t.popCooledDownENIsFromDeleteQueue(securityGroups, t.candidateSubnetIDs(ctx),
+			eniCount-len(warmENIs)))
+	}
+	if toCreate := eniCount - len(warmENIs) - len(reusedENIs); toCreate > 0 {
+		capacity := t.remainingCapacity()
+		if capacity < toCreate {
+			// Free the capacity held by the warm branch ENIs of other security groups
+			capacity += t.deleteEvictedENIs(ctx, t.evictWarmENIs(securityGroups, toCreate-capacity))
 		}
-		// append the nodeName tag to add to branch ENIs
-		tags = append(tags, t.nodeIDTag...)
-		// Create Branch ENI
-		nwInterface, err = t.ec2ApiHelper.CreateNetworkInterface(&BranchEniDescription,
-			aws.String(t.instance.SubnetID()), securityGroups, tags, nil, nil)
-		if err != nil {
-			err = fmt.Errorf("creating network interface, %w", err)
-			t.freeVlanId(vlanID)
-			branchENIOperationsFailureCount.WithLabelValues("creating_branch_eni_failed").Inc()
-			break
-		} else {
-			branchENIOperationsSuccessCount.WithLabelValues("created_branch_eni_succeeded").Inc()
+		if capacity <= 0 {
+			t.pushENIsToWarmPool(warmENIs)
+			t.PushENIsToFrontOfDeleteQueue(nil, reusedENIs)
+			return nil, ErrCurrentlyAtMaxCapacity
 		}
+	}
 
-		// Branch ENI can have an IPv4 address, IPv6 address, or both
-		var v4Addr, v6Addr string
-		if nwInterface.PrivateIpAddress != nil {
-			v4Addr = *nwInterface.PrivateIpAddress
-		}
-		if nwInterface.Ipv6Address != nil {
-			v6Addr = *nwInterface.Ipv6Address
-		}
-		newENI := &ENIDetails{
-			ID: *nwInterface.NetworkInterfaceId, MACAdd: *nwInterface.MacAddress,
-			IPV4Addr: v4Addr, IPV6Addr: v6Addr, SubnetCIDR: t.instance.SubnetCidrBlock(),
-			SubnetV6CIDR: t.instance.SubnetV6CidrBlock(), VlanID: vlanID,
-		}
-		newENIs = append(newENIs, newENI)
+	var newENIs []*ENIDetails
+	var newENI *ENIDetails
 
-		// Associate Branch to trunk
-		var associationOutput *awsEc2.AssociateTrunkInterfaceOutput
-		associationOutput, err = t.ec2ApiHelper.AssociateBranchToTrunk(&t.trunkENIId, nwInterface.NetworkInterfaceId, vlanID)
+	for i := len(warmENIs) + len(reusedENIs); i < eniCount; i++ {
+		newENI, err = t.createAndAssociateBranchENI(ctx, securityGroups)
+		if newENI != nil {
+			newENIs = append(newENIs, newENI)
+		}
 		if err != nil {
-			err = fmt.Errorf("associating branch to trunk, %w", err)
-			trunkENIOperationsErrCount.WithLabelValues("associate_branch").Inc()
 			break
 		}
-		newENI.AssociationID = *associationOutput.InterfaceAssociation.AssociationId
 	}
 
 	if err != nil {
 		log.Error(err, "failed to create ENI, moving the ENI to delete list")
 		// Moving to delete list, because it has all the retrying logic in case of failure
-		t.PushENIsToFrontOfDeleteQueue(nil, newENIs)
+		t.PushENIsToFrontOfDeleteQueue(nil, append(reusedENIs, newENIs...))
+		t.pushENIsToWarmPool(warmENIs)
 		return nil, err
 	}
+	if len(warmENIs) > 0 {
+		branchENIOperationsSuccessCount.WithLabelValues("assigned_warm_branch_eni").Add(float64(len(warmENIs)))
+	}
+	if len(reusedENIs) > 0 {
+		branchENIOperationsSuccessCount.WithLabelValues("reused_cooled_down_branch_eni").Add(float64(len(reusedENIs)))
+	}
+	newENIs = append(append(warmENIs, reusedENIs...), newENIs...)
 
-	t.addBranchToCache(string(pod.UID), newENIs)
+	t.addBranchToCache(string(pod.UID), pod.Spec.NodeName, newENIs)
 
 	log.Info("successfully created branch interfaces", "interfaces", newENIs,
 		"security group used", securityGroups)
@@ -481,14 +501,75 @@ func (t *trunkENI) CreateAndAssociateBranchENIs(pod *v1.Pod, securityGroups []st
 	return newENIs, nil
 }
 
+// createAndAssociateBranchENI creates a new branch network interface with the security groups and associates it to the
+// trunk network interface. The created branch is returned along with the error if the association fails so the
+// caller can delete it
+func (t *trunkENI) createAndAssociateBranchENI(ctx context.Context, securityGroups []string) (*ENIDetails, error) {
+	// Assign VLAN
+	vlanID, err := t.assignVlanId()
+	if err != nil {
+		trunkENIOperationsErrCount.WithLabelValues("assign_vlan_id").Inc()
+		return nil, fmt.Errorf("assigning vlad id, %w", err)
+	}
+
+	// Vlan ID tag workaround, as describe trunk association is not supported with assumed role
+	tags := []ec2types.Tag{
+		{
+			Key:   aws.String(config.VLandIDTag),
+			Value: aws.String(strconv.Itoa(vlanID)),
+		},
+		{
+			Key:   aws.String(config.TrunkENIIDTag),
+			Value: &t.trunkENIId,
+		},
+	}
+	// append the nodeName tag to add to branch ENIs
+	tags = append(tags, t.nodeIDTag...)
+	// Create Branch ENI in the subnet selected from the candidate subnets
+	subnet := t.selectBranchSubnet(ctx)
+	nwInterface, err := t.ec2ApiHelper.CreateNetworkInterface(ctx, &BranchEniDescription,
+		aws.String(subnet.id), securityGroups, tags, nil, nil)
+	if err != nil {
+		t.freeVlanId(vlanID)
+		branchENIOperationsFailureCount.WithLabelValues("creating_branch_eni_failed").Inc()
+		return nil, fmt.Errorf("creating network interface, %w", err)
+	}
+	branchENIOperationsSuccessCount.WithLabelValues("created_branch_eni_succeeded").Inc()
+
+	// Branch ENI can have an IPv4 address, IPv6 address, or both
+	var v4Addr, v6Addr string
+	if nwInterface.PrivateIpAddress != nil {
+		v4Addr = *nwInterface.PrivateIpAddress
+	}
+	if nwInterface.Ipv6Address != nil {
+		v6Addr = *nwInterface.Ipv6Address
+	}
+	newENI := &ENIDetails{
+		ID: *nwInterface.NetworkInterfaceId, MACAdd: *nwInterface.MacAddress,
+		IPV4Addr: v4Addr, IPV6Addr: v6Addr, SubnetCIDR: subnet.cidrBlock,
+		SubnetV6CIDR: subnet.v6CidrBlock, VlanID: vlanID,
+		securityGroups: sortedSecurityGroups(securityGroups), subnetID: subnet.id,
+	}
+
+	// Associate Branch to trunk
+	associationOutput, err := t.ec2ApiHelper.AssociateBranchToTrunk(ctx, &t.trunkENIId, nwInterface.NetworkInterfaceId, vlanID)
+	if err != nil {
+		trunkENIOperationsErrCount.WithLabelValues("associate_branch").Inc()
+		return newENI, fmt.Errorf("associating branch to trunk, %w", err)
+	}
+	newENI.AssociationID = *associationOutput.InterfaceAssociation.AssociationId
+
+	return newENI, nil
+}
+
 // DeleteAllBranchENIs deletes all the branch ENIs associated with the trunk and all the ENIs present in the cool down
 // queue, this is the last API call to the the Trunk ENI before it is removed from cache
-func (t *trunkENI) DeleteAllBranchENIs() {
+func (t *trunkENI) DeleteAllBranchENIs(ctx context.Context) {
 	// Delete all the branch used by the pod on this trunk ENI
 	// Since after this call, the trunk will be removed from cache. No need to clean up its branch map
 	for _, podENIs := range t.uidToBranchENIMap {
 		for _, eni := range podENIs {
-			err := t.deleteENI(eni)
+			err := t.deleteENI(ctx, eni)
 			if err != nil {
 				// Just log, if the ENI still exists it can be removed by the dangling ENI cleaner routine
 				t.log.Error(err, "failed to delete eni", "eni id", eni.ID)
@@ -498,12 +579,40 @@ func (t *trunkENI) DeleteAllBranchENIs() {
 
 	// Delete all the branch ENI present in the cool down queue
 	for _, eni := range t.deleteQueue {
-		err := t.deleteENI(eni)
+		err := t.deleteENI(ctx, eni)
 		if err != nil {
 			// Just log, if the ENI still exists it can be removed by the dangling ENI cleaner routine
 			t.log.Error(err, "failed to delete eni", "eni id", eni.ID)
 		}
 	}
+
+	// Delete all the branch ENI present in the warm pools
+	for _, pool := range t.warmPools {
+		for _, eni := range pool.enis {
+			err := t.deleteENI(ctx, eni)
+			if err != nil {
+				// Just log, if the ENI still exists it can be removed by the dangling ENI cleaner routine
+				t.log.Error(err, "failed to delete eni", "eni id", eni.ID)
+			}
+		}
+	}
+}
+
+// UpdateBranchENISecurityGroups records the security groups modified on the branch ENI used by the pod and marks the
+// branch ENI as modified
+func (t *trunkENI) UpdateBranchENISecurityGroups(UID string, eniID string, securityGroups []string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for _, eni := range t.uidToBranchENIMap[UID] {
+		if eni.ID == eniID {
+			eni.securityGroups = sortedSecurityGroups(securityGroups)
+			eni.securityGroupsModified = true
+			return
+		}
+	}
+	t.log.Info("couldn't find branch ENI of the pod in cache to update its security groups", "UID", UID,
+		"eni id", eniID)
 }
 
 // DeleteBranchNetworkInterface deletes the branch network interface and returns an error in case of failure to delete
@@ -526,16 +635,20 @@ func (t *trunkENI) PushBranchENIsToCoolDownQueue(UID string) {
 	}
 
 	delete(t.uidToBranchENIMap, UID)
+	t.releaseBranchENIs(branchENIs)
 
 	t.log.Info("moved branch network interfaces to delete queue", "Interfaces",
 		branchENIs, "UID", UID)
 }
 
-func (t *trunkENI) DeleteCooledDownENIs() {
+func (t *trunkENI) DeleteCooledDownENIs(ctx context.Context) {
 	for eni, hasENI := t.popENIFromDeleteQueue(); hasENI; eni, hasENI = t.popENIFromDeleteQueue() {
-		if eni.deletionTimeStamp.IsZero() ||
-			time.Now().After(eni.deletionTimeStamp.Add(cooldown.GetCoolDown().GetCoolDownPeriod())) {
-			err := t.deleteENI(eni)
+		if isCooledDown(eni) {
+			// Return the cooled down ENI to the warm pool of its security groups instead of deleting it
+			if t.returnENIToWarmPool(eni) {
+				continue
+			}
+			err := t.deleteENI(ctx, eni)
 			if err != nil {
 				eni.deleteRetryCount++
 				if eni.deleteRetryCount >= MaxDeleteRetries {
@@ -558,10 +671,10 @@ func (t *trunkENI) DeleteCooledDownENIs() {
 }
 
 // deleteENIs deletes the provided ENIs and frees up the Vlan assigned to then
-func (t *trunkENI) deleteENI(eniDetail *ENIDetails) (err error) {
+func (t *trunkENI) deleteENI(ctx context.Context, eniDetail *ENIDetails) (err error) {
 	// Disassociate branch ENI from trunk if association ID exists and delete branch network interface
 	if eniDetail.AssociationID != "" {
-		err = t.ec2ApiHelper.DisassociateTrunkInterface(&eniDetail.AssociationID)
+		err = t.ec2ApiHelper.DisassociateTrunkInterface(ctx, &eniDetail.AssociationID)
 		if err != nil {
 			trunkENIOperationsErrCount.WithLabelValues("disassociate_trunk_error").Inc()
 			if !strings.Contains(err.Error(), ec2Errors.NotFoundAssociationID) {
@@ -572,7 +685,7 @@ func (t *trunkENI) deleteENI(eniDetail *ENIDetails) (err error) {
 			}
 		}
 	}
-	err = t.ec2ApiHelper.DeleteNetworkInterface(&eniDetail.ID)
+	err = t.ec2ApiHelper.DeleteNetworkInterface(ctx, &eniDetail.ID)
 	if err != nil {
 		branchENIOperationsFailureCount.WithLabelValues("delete_branch_error").Inc()
 
@@ -640,6 +753,78 @@ func (t *trunkENI) PushENIsToFrontOfDeleteQueue(pod *v1.Pod, eniList []*ENIDetai
 	t.deleteQueue = append(eniList, t.deleteQueue...)
 }
 
+// popCooledDownENIsFromDeleteQueue removes up to count cooled down branch ENIs with the same security groups and in
+// one of the subnets from the delete queue so they can be reassigned along with their VLAN ID. The branch ENIs that failed to
+// associate with the trunk or whose security groups were modified are not reused
+func (t *trunkENI) popCooledDownENIsFromDeleteQueue(securityGroups []string, subnetIDs []string, count int) []*ENIDetails {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	sortedSGs := sortedSecurityGroups(securityGroups)
+	var reusedENIs []*ENIDetails
+	var remaining []*ENIDetails
+	for _, eni := range t.deleteQueue {
+		if len(reusedENIs) < count && eni.AssociationID != "" && slices.Contains(subnetIDs, eni.subnetID) &&
+			len(eni.securityGroups) > 0 && !eni.securityGroupsModified && slices.Equal(eni.securityGroups, sortedSGs) &&
+			isCooledDown(eni) {
+			eni.deletionTimeStamp = time.Time{}
+			eni.deleteRetryCount = 0
+			reusedENIs = append(reusedENIs, eni)
+			continue
+		}
+		remaining = append(remaining, eni)
+	}
+	t.deleteQueue = remaining
+
+	if len(reusedENIs) > 0 {
+		t.log.Info("reusing cooled down branch ENIs from delete queue", "ENIs", reusedENIs)
+	}
+	return reusedENIs
+}
+
+// verifyReusedENIs returns the branch ENIs popped from the delete queue whose security groups in EC2 still match the
+// security groups recorded on them, since the security groups can be modified out of band after the branch ENI was
+// created. The branch ENIs that don't match, or all of them if they can't be described, are moved back to the delete
+// queue and are not reused again
+func (t *trunkENI) verifyReusedENIs(ctx context.Context, enis []*ENIDetails) []*ENIDetails {
+	if len(enis) == 0 {
+		return nil
+	}
+
+	eniIDs := make([]string, 0, len(enis))
+	for _, eni := range enis {
+		eniIDs = append(eniIDs, eni.ID)
+	}
+	securityGroupsByENI, err := t.ec2ApiHelper.GetNetworkInterfaceSecurityGroups(ctx, eniIDs)
+	if err != nil {
+		t.log.Error(err, "failed to verify security groups of cooled down branch ENIs, not reusing them", "ENIs", enis)
+		t.PushENIsToFrontOfDeleteQueue(nil, enis)
+		return nil
+	}
+
+	var verified, stale []*ENIDetails
+	for _, eni := range enis {
+		securityGroups, found := securityGroupsByENI[eni.ID]
+		if found && slices.Equal(sortedSecurityGroups(securityGroups), eni.securityGroups) {
+			verified = append(verified, eni)
+			continue
+		}
+		eni.securityGroupsModified = true
+		stale = append(stale, eni)
+	}
+	if len(stale) > 0 {
+		t.log.Info("security groups of cooled down branch ENIs changed, not reusing them", "ENIs", stale)
+		t.PushENIsToFrontOfDeleteQueue(nil, stale)
+	}
+	return verified
+}
+
+// isCooledDown returns true if the branch ENI in the delete queue has completed the cool down period
+func isCooledDown(eni *ENIDetails) bool {
+	return eni.deletionTimeStamp.IsZero() ||
+		time.Now().After(eni.deletionTimeStamp.Add(cooldown.GetCoolDown().GetCoolDownPeriod()))
+}
+
 // popENIFromDeleteQueue pops an ENI from delete queue, if the queue is empty then the false is returned
 func (t *trunkENI) popENIFromDeleteQueue() (eni *ENIDetails, hasENI bool) {
 	t.lock.Lock()
@@ -655,7 +840,7 @@ func (t *trunkENI) popENIFromDeleteQueue() (eni *ENIDetails, hasENI bool) {
 }
 
 // addBranchToCache adds the given branch to the cache if not already present
-func (t *trunkENI) addBranchToCache(UID string, branchENIs []*ENIDetails) {
+func (t *trunkENI) addBranchToCache(UID string, nodeName string, branchENIs []*ENIDetails) {
 	t.lock.Lock()
 	defer t.lock.Unlock()
 
@@ -665,6 +850,22 @@ func (t *trunkENI) addBranchToCache(UID string, branchENIs []*ENIDetails) {
 	}
 
 	t.uidToBranchENIMap[UID] = branchENIs
+	t.indexBranchENIs(UID, nodeName, branchENIs)
+}
+
+// indexBranchENIs records the branch ENIs and their IP addresses are assigned to the pod on the node in the lookup
+// index
+func (t *trunkENI) indexBranchENIs(UID string, nodeName string, branchENIs []*ENIDetails) {
+	for _, eni := range branchENIs {
+		t.index.Assign(nodeName, UID, eni.ID, eni.IPV4Addr, eni.IPV6Addr)
+	}
+}
+
+// releaseBranchENIs records the pod released the branch ENIs and their IP addresses in the lookup index
+func (t *trunkENI) releaseBranchENIs(branchENIs []*ENIDetails) {
+	for _, eni := range branchENIs {
+		t.index.Release(eni.ID, eni.IPV4Addr, eni.IPV6Addr)
+	}
 }
 
 // getBranchFromCache returns the branch from the cache
@@ -723,6 +924,12 @@ func (t *trunkENI) getVlanIdFromTag(tags []ec2types.Tag) (int, error) {
 }
 
 func (t *trunkENI) canCreateMore() bool {
+	return t.remainingCapacity() > 0
+}
+
+// remainingCapacity returns the number of branch ENIs that can be created on the trunk, the branch ENIs used by pods,
+// in the warm pools and in the delete queue count towards the limit of the instance type
+func (t *trunkENI) remainingCapacity() int {
 	t.lock.RLock()
 	defer t.lock.RUnlock()
 
@@ -730,11 +937,11 @@ func (t *trunkENI) canCreateMore() bool {
 	for _, branches := range t.uidToBranchENIMap {
 		usedBranches += len(branches)
 	}
-
-	if usedBranches+len(t.deleteQueue) < vpc.Limits[t.instance.Type()].BranchInterface {
-		return true
+	for _, pool := range t.warmPools {
+		usedBranches += len(pool.enis)
 	}
-	return false
+
+	return vpc.Limits[t.instance.Type()].BranchInterface - usedBranches - len(t.deleteQueue)
 }
 
 func (t *trunkENI) Introspect() IntrospectResponse {
@@ -756,5 +963,15 @@ func (t *trunkENI) Introspect() IntrospectResponse {
 	for _, eni := range t.deleteQueue {
 		response.DeleteQueue = append(response.DeleteQueue, *eni)
 	}
+	if len(t.warmPools) > 0 {
+		response.WarmPools = make(map[string][]ENIDetails)
+		for key, pool := range t.warmPools {
+			var eniDetails []ENIDetails
+			for _, eni := range pool.enis {
+				eniDetails = append(eniDetails, *eni)
+			}
+			response.WarmPools[key] = eniDetails
+		}
+	}
 	return response
 }
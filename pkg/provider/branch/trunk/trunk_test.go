@@ -14,6 +14,7 @@
 package trunk
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"testing"
@@ -26,6 +27,7 @@ import (
 
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/ec2"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/lookup"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider/branch/cooldown"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -185,11 +187,13 @@ var (
 		{
 			InterfaceType:      awsEc2Types.NetworkInterfaceTypeBranch,
 			NetworkInterfaceId: &EniDetails1.ID,
+			SubnetId:           &SubnetId,
 			TagSet:             vlan1Tag,
 		},
 		{
 			InterfaceType:      awsEc2Types.NetworkInterfaceTypeBranch,
 			NetworkInterfaceId: &EniDetails2.ID,
+			SubnetId:           &SubnetId,
 			TagSet:             vlan2Tag,
 		},
 	}
@@ -244,6 +248,8 @@ func getMockTrunk() trunkENI {
 		log:               log,
 		usedVlanIds:       make([]bool, MaxAllocatableVlanIds),
 		uidToBranchENIMap: map[string][]*ENIDetails{},
+		warmPools:         map[string]*warmPool{},
+		index:             lookup.NewIndex(time.Hour),
 		nodeIDTag: []awsEc2Types.Tag{
 			{
 				Key:   aws.String(config.NetworkInterfaceNodeIDKey),
@@ -253,8 +259,20 @@ func getMockTrunk() trunkENI {
 	}
 }
 
+// withSecurityGroups returns a copy of the ENI details created in the instance subnet with the security groups set
+func withSecurityGroups(securityGroups []string, enis ...*ENIDetails) []*ENIDetails {
+	var enisWithSGs []*ENIDetails
+	for _, eni := range enis {
+		eniCopy := *eni
+		eniCopy.securityGroups = sortedSecurityGroups(securityGroups)
+		eniCopy.subnetID = SubnetId
+		enisWithSGs = append(enisWithSGs, &eniCopy)
+	}
+	return enisWithSGs
+}
+
 func TestNewTrunkENI(t *testing.T) {
-	trunkENI := NewTrunkENI(zap.New(), FakeInstance, nil)
+	trunkENI := NewTrunkENI(zap.New(), FakeInstance, nil, nil, nil)
 	assert.NotNil(t, trunkENI)
 }
 
@@ -324,15 +342,19 @@ func TestTrunkENI_getBranchFromCache_NotPresent(t *testing.T) {
 	assert.False(t, isPresent)
 }
 
-// TestTrunkENI_addBranchToCache tests branch is added to the cache
+// TestTrunkENI_addBranchToCache tests branch is added to the cache and the lookup index
 func TestTrunkENI_addBranchToCache(t *testing.T) {
 	trunkENI := getMockTrunk()
 
-	trunkENI.addBranchToCache(PodUID, branchENIs1)
+	trunkENI.addBranchToCache(PodUID, NodeName, branchENIs1)
 
 	branchFromCache, ok := trunkENI.uidToBranchENIMap[PodUID]
 	assert.True(t, ok)
 	assert.Equal(t, branchENIs1, branchFromCache)
+
+	owner, ok := trunkENI.index.Get(EniDetails1.ID)
+	assert.True(t, ok)
+	assert.Equal(t, lookup.Owner{NodeName: NodeName, PodUID: PodUID}, owner)
 }
 
 // TestTrunkENI_pushENIToDeleteQueue tests pushing to delete queue the data is stored in FIFO strategy
@@ -441,8 +463,8 @@ func TestTrunkENI_deleteENI(t *testing.T) {
 		{
 			name: "Vland_Freed, verifies VLANID is freed when branch ENI is deleted",
 			prepare: func(f *fields) {
-				f.mockEC2APIHelper.EXPECT().DisassociateTrunkInterface(&MockAssociationID1).Return(nil)
-				f.mockEC2APIHelper.EXPECT().DeleteNetworkInterface(&Branch1Id).Return(nil)
+				f.mockEC2APIHelper.EXPECT().DisassociateTrunkInterface(gomock.Any(), &MockAssociationID1).Return(nil)
+				f.mockEC2APIHelper.EXPECT().DeleteNetworkInterface(gomock.Any(), &Branch1Id).Return(nil)
 			},
 			args: args{
 				eniDetail: EniDetails1,
@@ -456,8 +478,8 @@ func TestTrunkENI_deleteENI(t *testing.T) {
 		{
 			name: "Vland_NotFreed, verifies VLANID is not freed when branch ENI delete fails",
 			prepare: func(f *fields) {
-				f.mockEC2APIHelper.EXPECT().DisassociateTrunkInterface(&MockAssociationID1).Return(nil)
-				f.mockEC2APIHelper.EXPECT().DeleteNetworkInterface(&Branch1Id).Return(MockError)
+				f.mockEC2APIHelper.EXPECT().DisassociateTrunkInterface(gomock.Any(), &MockAssociationID1).Return(nil)
+				f.mockEC2APIHelper.EXPECT().DeleteNetworkInterface(gomock.Any(), &Branch1Id).Return(MockError)
 			},
 			args: args{
 				eniDetail: EniDetails1,
@@ -471,8 +493,8 @@ func TestTrunkENI_deleteENI(t *testing.T) {
 		{
 			name: "DisassociateTrunkInterface_Fails, verifies branch ENI is deleted when disassociation fails for backward compatibility",
 			prepare: func(f *fields) {
-				f.mockEC2APIHelper.EXPECT().DisassociateTrunkInterface(&MockAssociationID1).Return(MockError)
-				f.mockEC2APIHelper.EXPECT().DeleteNetworkInterface(&Branch1Id).Return(nil)
+				f.mockEC2APIHelper.EXPECT().DisassociateTrunkInterface(gomock.Any(), &MockAssociationID1).Return(MockError)
+				f.mockEC2APIHelper.EXPECT().DeleteNetworkInterface(gomock.Any(), &Branch1Id).Return(nil)
 			},
 			args: args{
 				eniDetail: EniDetails1,
@@ -486,7 +508,7 @@ func TestTrunkENI_deleteENI(t *testing.T) {
 		{
 			name: "MissingAssociationID, verifies DisassociateTrunkInterface is skipped when association ID is missing and branch ENI is deleted for backward compatibility",
 			prepare: func(f *fields) {
-				f.mockEC2APIHelper.EXPECT().DeleteNetworkInterface(&Branch2Id).Return(nil)
+				f.mockEC2APIHelper.EXPECT().DeleteNetworkInterface(gomock.Any(), &Branch2Id).Return(nil)
 			},
 			args: args{
 				eniDetail: ENIDetailsMissingAssociationID,
@@ -514,7 +536,7 @@ func TestTrunkENI_deleteENI(t *testing.T) {
 			if tt.prepare != nil {
 				tt.prepare(&f)
 			}
-			err := f.trunkENI.deleteENI(tt.args.eniDetail)
+			err := f.trunkENI.deleteENI(context.TODO(), tt.args.eniDetail)
 			assert.Equal(t, err != nil, tt.wantErr)
 			if tt.asserts != nil {
 				tt.asserts(&f)
@@ -538,7 +560,7 @@ func TestTrunkENI_DeleteCooledDownENIs_NotCooledDown(t *testing.T) {
 	mockK8sAPI.EXPECT().GetConfigMap(config.VpcCniConfigMapName, config.KubeSystemNamespace).Return(createCoolDownMockCM("30"), nil)
 	cooldown.InitCoolDownPeriod(mockK8sAPI, zap.New(zap.UseDevMode(true)).WithName("cooldown"))
 
-	trunkENI.DeleteCooledDownENIs()
+	trunkENI.DeleteCooledDownENIs(context.TODO())
 	assert.Equal(t, 2, len(trunkENI.deleteQueue))
 }
 
@@ -556,16 +578,16 @@ func TestTrunkENI_DeleteCooledDownENIs_NoDeletionTimeStamp(t *testing.T) {
 
 	trunkENI.deleteQueue = append(trunkENI.deleteQueue, EniDetails1, EniDetails2)
 
-	ec2APIHelper.EXPECT().DisassociateTrunkInterface(&MockAssociationID1).Return(nil)
-	ec2APIHelper.EXPECT().DeleteNetworkInterface(&EniDetails1.ID).Return(nil)
-	ec2APIHelper.EXPECT().DisassociateTrunkInterface(&MockAssociationID2).Return(nil)
-	ec2APIHelper.EXPECT().DeleteNetworkInterface(&EniDetails2.ID).Return(nil)
+	ec2APIHelper.EXPECT().DisassociateTrunkInterface(gomock.Any(), &MockAssociationID1).Return(nil)
+	ec2APIHelper.EXPECT().DeleteNetworkInterface(gomock.Any(), &EniDetails1.ID).Return(nil)
+	ec2APIHelper.EXPECT().DisassociateTrunkInterface(gomock.Any(), &MockAssociationID2).Return(nil)
+	ec2APIHelper.EXPECT().DeleteNetworkInterface(gomock.Any(), &EniDetails2.ID).Return(nil)
 
 	mockK8sAPI := mock_k8s.NewMockK8sWrapper(ctrl)
 	mockK8sAPI.EXPECT().GetConfigMap(config.VpcCniConfigMapName, config.KubeSystemNamespace).Return(createCoolDownMockCM("30"), nil)
 	cooldown.InitCoolDownPeriod(mockK8sAPI, zap.New(zap.UseDevMode(true)).WithName("cooldown"))
 
-	trunkENI.DeleteCooledDownENIs()
+	trunkENI.DeleteCooledDownENIs(context.TODO())
 	assert.Equal(t, 0, len(trunkENI.deleteQueue))
 }
 
@@ -582,14 +604,14 @@ func TestTrunkENI_DeleteCooledDownENIs_CooledDownResource(t *testing.T) {
 
 	trunkENI.deleteQueue = append(trunkENI.deleteQueue, EniDetails1, EniDetails2)
 
-	ec2APIHelper.EXPECT().DisassociateTrunkInterface(&MockAssociationID1).Return(nil)
-	ec2APIHelper.EXPECT().DeleteNetworkInterface(&EniDetails1.ID).Return(nil)
+	ec2APIHelper.EXPECT().DisassociateTrunkInterface(gomock.Any(), &MockAssociationID1).Return(nil)
+	ec2APIHelper.EXPECT().DeleteNetworkInterface(gomock.Any(), &EniDetails1.ID).Return(nil)
 
 	mockK8sAPI := mock_k8s.NewMockK8sWrapper(ctrl)
 	mockK8sAPI.EXPECT().GetConfigMap(config.VpcCniConfigMapName, config.KubeSystemNamespace).Return(createCoolDownMockCM("30"), nil)
 	cooldown.InitCoolDownPeriod(mockK8sAPI, zap.New(zap.UseDevMode(true)).WithName("cooldown"))
 
-	trunkENI.DeleteCooledDownENIs()
+	trunkENI.DeleteCooledDownENIs(context.TODO())
 	assert.Equal(t, 1, len(trunkENI.deleteQueue))
 	assert.Equal(t, EniDetails2, trunkENI.deleteQueue[0])
 }
@@ -614,12 +636,12 @@ func TestTrunkENI_DeleteCooledDownENIs_DeleteFailed(t *testing.T) {
 	cooldown.InitCoolDownPeriod(mockK8sAPI, zap.New(zap.UseDevMode(true)).WithName("cooldown"))
 
 	coolDown.EXPECT().GetCoolDownPeriod().Return(time.Second * 60).AnyTimes()
-	ec2APIHelper.EXPECT().DisassociateTrunkInterface(&MockAssociationID1).Return(nil).Times(MaxDeleteRetries)
-	ec2APIHelper.EXPECT().DeleteNetworkInterface(&EniDetails1.ID).Return(MockError).Times(MaxDeleteRetries)
-	ec2APIHelper.EXPECT().DisassociateTrunkInterface(&MockAssociationID2).Return(nil)
-	ec2APIHelper.EXPECT().DeleteNetworkInterface(&EniDetails2.ID).Return(nil)
+	ec2APIHelper.EXPECT().DisassociateTrunkInterface(gomock.Any(), &MockAssociationID1).Return(nil).Times(MaxDeleteRetries)
+	ec2APIHelper.EXPECT().DeleteNetworkInterface(gomock.Any(), &EniDetails1.ID).Return(MockError).Times(MaxDeleteRetries)
+	ec2APIHelper.EXPECT().DisassociateTrunkInterface(gomock.Any(), &MockAssociationID2).Return(nil)
+	ec2APIHelper.EXPECT().DeleteNetworkInterface(gomock.Any(), &EniDetails2.ID).Return(nil)
 
-	trunkENI.DeleteCooledDownENIs()
+	trunkENI.DeleteCooledDownENIs(context.TODO())
 	assert.Zero(t, len(trunkENI.deleteQueue))
 }
 
@@ -692,10 +714,10 @@ func TestTrunkENI_InitTrunk(t *testing.T) {
 				freeIndex := int32(2)
 				f.mockInstance.EXPECT().InstanceID().Return(InstanceId)
 				f.mockInstance.EXPECT().CurrentInstanceSecurityGroups().Return(SecurityGroups)
-				f.mockEC2APIHelper.EXPECT().GetInstanceNetworkInterface(&InstanceId).Return([]awsEc2Types.InstanceNetworkInterface{}, nil)
+				f.mockEC2APIHelper.EXPECT().GetInstanceNetworkInterface(gomock.Any(), &InstanceId).Return([]awsEc2Types.InstanceNetworkInterface{}, nil)
 				f.mockInstance.EXPECT().GetHighestUnusedDeviceIndex().Return(freeIndex, nil)
 				f.mockInstance.EXPECT().SubnetID().Return(SubnetId)
-				f.mockEC2APIHelper.EXPECT().CreateAndAttachNetworkInterface(&InstanceId, &SubnetId, SecurityGroups, f.trunkENI.nodeIDTag,
+				f.mockEC2APIHelper.EXPECT().CreateAndAttachNetworkInterface(gomock.Any(), &InstanceId, &SubnetId, SecurityGroups, f.trunkENI.nodeIDTag,
 					&freeIndex, &TrunkEniDescription, &InterfaceTypeTrunk, nil).Return(trunkInterface, nil)
 			},
 			// Pass nil to set the instance to fields.mockInstance in the function later
@@ -709,7 +731,7 @@ func TestTrunkENI_InitTrunk(t *testing.T) {
 			name: "ErrWhen_EmptyNWInterfaceResponse, verifies error is returned when interface type is nil",
 			prepare: func(f *fields) {
 				f.mockInstance.EXPECT().InstanceID().Return(InstanceId)
-				f.mockEC2APIHelper.EXPECT().GetInstanceNetworkInterface(&InstanceId).Return(
+				f.mockEC2APIHelper.EXPECT().GetInstanceNetworkInterface(gomock.Any(), &InstanceId).Return(
 					[]awsEc2Types.InstanceNetworkInterface{{InterfaceType: nil}}, nil)
 			},
 			args:    args{instance: nil, podList: []v1.Pod{*MockPod2}},
@@ -720,7 +742,7 @@ func TestTrunkENI_InitTrunk(t *testing.T) {
 			name: "GetTrunkError, verifies error is returned when get trunkENI call fails",
 			prepare: func(f *fields) {
 				f.mockInstance.EXPECT().InstanceID().Return(InstanceId)
-				f.mockEC2APIHelper.EXPECT().GetInstanceNetworkInterface(&InstanceId).Return(nil, MockError)
+				f.mockEC2APIHelper.EXPECT().GetInstanceNetworkInterface(gomock.Any(), &InstanceId).Return(nil, MockError)
 			},
 			args:    args{instance: nil, podList: []v1.Pod{*MockPod2}},
 			wantErr: true,
@@ -730,7 +752,7 @@ func TestTrunkENI_InitTrunk(t *testing.T) {
 			name: "GetFreeIndexFail, verifies error is returned if no free index exists",
 			prepare: func(f *fields) {
 				f.mockInstance.EXPECT().InstanceID().Return(InstanceId)
-				f.mockEC2APIHelper.EXPECT().GetInstanceNetworkInterface(&InstanceId).Return([]awsEc2Types.InstanceNetworkInterface{}, nil)
+				f.mockEC2APIHelper.EXPECT().GetInstanceNetworkInterface(gomock.Any(), &InstanceId).Return([]awsEc2Types.InstanceNetworkInterface{}, nil)
 				f.mockInstance.EXPECT().GetHighestUnusedDeviceIndex().Return(int32(0), MockError)
 			},
 			args:    args{instance: nil, podList: []v1.Pod{*MockPod2}},
@@ -742,10 +764,9 @@ func TestTrunkENI_InitTrunk(t *testing.T) {
 			prepare: func(f *fields) {
 				f.mockInstance.EXPECT().InstanceID().Return(InstanceId)
 				f.mockInstance.EXPECT().GetCustomNetworkingSpec().Return("", []string{})
-				f.mockEC2APIHelper.EXPECT().GetInstanceNetworkInterface(&InstanceId).Return(instanceNwInterfaces, nil)
-				f.mockEC2APIHelper.EXPECT().WaitForNetworkInterfaceStatusChange(&trunkId, string(awsEc2Types.AttachmentStatusAttached)).Return(nil)
-				f.mockInstance.EXPECT().SubnetID().Return(SubnetId)
-				f.mockEC2APIHelper.EXPECT().GetBranchNetworkInterface(&trunkId, &SubnetId).Return(branchInterfaces, nil)
+				f.mockEC2APIHelper.EXPECT().GetInstanceNetworkInterface(gomock.Any(), &InstanceId).Return(instanceNwInterfaces, nil)
+				f.mockEC2APIHelper.EXPECT().WaitForNetworkInterfaceStatusChange(gomock.Any(), &trunkId, string(awsEc2Types.AttachmentStatusAttached)).Return(nil)
+				f.mockEC2APIHelper.EXPECT().GetBranchNetworkInterface(gomock.Any(), &trunkId).Return(branchInterfaces, nil)
 			},
 			args:    args{instance: FakeInstance, podList: []v1.Pod{*MockPod1, *MockPod2}},
 			wantErr: false,
@@ -757,6 +778,8 @@ func TestTrunkENI_InitTrunk(t *testing.T) {
 				assert.Equal(t, Branch2Id, branchENIs[1].ID)
 				assert.Equal(t, VlanId1, branchENIs[0].VlanID)
 				assert.Equal(t, VlanId2, branchENIs[1].VlanID)
+				assert.Equal(t, SubnetId, branchENIs[0].subnetID)
+				assert.Equal(t, SubnetId, branchENIs[1].subnetID)
 
 				// Assert that Vlan ID's are marked as used and if you retry using then you get error
 				assert.True(t, f.trunkENI.usedVlanIds[EniDetails1.VlanID])
@@ -772,10 +795,9 @@ func TestTrunkENI_InitTrunk(t *testing.T) {
 			prepare: func(f *fields) {
 				f.mockInstance.EXPECT().InstanceID().Return(InstanceId)
 				f.mockInstance.EXPECT().GetCustomNetworkingSpec().Return("", []string{})
-				f.mockEC2APIHelper.EXPECT().GetInstanceNetworkInterface(&InstanceId).Return(instanceNwInterfaces, nil)
-				f.mockEC2APIHelper.EXPECT().WaitForNetworkInterfaceStatusChange(&trunkId, string(awsEc2Types.AttachmentStatusAttached)).Return(nil)
-				f.mockInstance.EXPECT().SubnetID().Return(SubnetId)
-				f.mockEC2APIHelper.EXPECT().GetBranchNetworkInterface(&trunkId, &SubnetId).Return(branchInterfaces, nil)
+				f.mockEC2APIHelper.EXPECT().GetInstanceNetworkInterface(gomock.Any(), &InstanceId).Return(instanceNwInterfaces, nil)
+				f.mockEC2APIHelper.EXPECT().WaitForNetworkInterfaceStatusChange(gomock.Any(), &trunkId, string(awsEc2Types.AttachmentStatusAttached)).Return(nil)
+				f.mockEC2APIHelper.EXPECT().GetBranchNetworkInterface(gomock.Any(), &trunkId).Return(branchInterfaces, nil)
 			},
 			args:    args{instance: FakeInstance, podList: []v1.Pod{*MockPod2}},
 			wantErr: false,
@@ -793,8 +815,8 @@ func TestTrunkENI_InitTrunk(t *testing.T) {
 			name: "TrunkExists_NotAttached, verifies error is returned if trunkENI is not attached",
 			prepare: func(f *fields) {
 				f.mockInstance.EXPECT().InstanceID().Return(InstanceId)
-				f.mockEC2APIHelper.EXPECT().GetInstanceNetworkInterface(&InstanceId).Return(instanceNwInterfaces, nil)
-				f.mockEC2APIHelper.EXPECT().WaitForNetworkInterfaceStatusChange(&trunkId, string(awsEc2Types.AttachmentStatusAttached)).Return(MockError)
+				f.mockEC2APIHelper.EXPECT().GetInstanceNetworkInterface(gomock.Any(), &InstanceId).Return(instanceNwInterfaces, nil)
+				f.mockEC2APIHelper.EXPECT().WaitForNetworkInterfaceStatusChange(gomock.Any(), &trunkId, string(awsEc2Types.AttachmentStatusAttached)).Return(MockError)
 			},
 			args:    args{instance: FakeInstance, podList: []v1.Pod{*MockPod1, *MockPod2}},
 			wantErr: true,
@@ -818,7 +840,7 @@ func TestTrunkENI_InitTrunk(t *testing.T) {
 			if tt.args.instance == nil {
 				tt.args.instance = f.mockInstance
 			}
-			err := f.trunkENI.InitTrunk(tt.args.instance, tt.args.podList)
+			err := f.trunkENI.InitTrunk(context.TODO(), tt.args.instance, tt.args.podList)
 			assert.Equal(t, err != nil, tt.wantErr)
 			if tt.asserts != nil {
 				tt.asserts(&f)
@@ -837,19 +859,19 @@ func TestTrunkENI_CreateAndAssociateBranchENIs(t *testing.T) {
 	trunkENI.trunkENIId = trunkId
 
 	mockInstance.EXPECT().Type().Return(InstanceType)
-	mockInstance.EXPECT().SubnetID().Return(SubnetId).Times(2)
+	mockInstance.EXPECT().SubnetID().Return(SubnetId).Times(3)
 	mockInstance.EXPECT().SubnetCidrBlock().Return(SubnetCidrBlock).Times(2)
 	mockInstance.EXPECT().SubnetV6CidrBlock().Return(SubnetV6CidrBlock).Times(2)
 
-	mockEC2APIHelper.EXPECT().CreateNetworkInterface(&BranchEniDescription, &SubnetId, SecurityGroups,
+	mockEC2APIHelper.EXPECT().CreateNetworkInterface(gomock.Any(), &BranchEniDescription, &SubnetId, SecurityGroups,
 		append(vlan1Tag, trunkENI.nodeIDTag...), nil, nil).Return(BranchInterface1, nil)
-	mockEC2APIHelper.EXPECT().AssociateBranchToTrunk(&trunkId, &Branch1Id, VlanId1).Return(mockAssociationOutput1, nil)
-	mockEC2APIHelper.EXPECT().CreateNetworkInterface(&BranchEniDescription, &SubnetId, SecurityGroups, append(vlan2Tag, trunkENI.nodeIDTag...),
+	mockEC2APIHelper.EXPECT().AssociateBranchToTrunk(gomock.Any(), &trunkId, &Branch1Id, VlanId1).Return(mockAssociationOutput1, nil)
+	mockEC2APIHelper.EXPECT().CreateNetworkInterface(gomock.Any(), &BranchEniDescription, &SubnetId, SecurityGroups, append(vlan2Tag, trunkENI.nodeIDTag...),
 		nil, nil).Return(BranchInterface2, nil)
-	mockEC2APIHelper.EXPECT().AssociateBranchToTrunk(&trunkId, &Branch2Id, VlanId2).Return(mockAssociationOutput2, nil)
+	mockEC2APIHelper.EXPECT().AssociateBranchToTrunk(gomock.Any(), &trunkId, &Branch2Id, VlanId2).Return(mockAssociationOutput2, nil)
 
-	eniDetails, err := trunkENI.CreateAndAssociateBranchENIs(MockPod2, SecurityGroups, 2)
-	expectedENIDetails := []*ENIDetails{EniDetails1, EniDetails2}
+	eniDetails, err := trunkENI.CreateAndAssociateBranchENIs(context.TODO(), MockPod2, SecurityGroups, 2)
+	expectedENIDetails := withSecurityGroups(SecurityGroups, EniDetails1, EniDetails2)
 
 	assert.NoError(t, err)
 	// VLan ID are marked as used
@@ -870,20 +892,20 @@ func TestTrunkENI_CreateAndAssociateBranchENIs_InstanceSecurityGroup(t *testing.
 	trunkENI.trunkENIId = trunkId
 
 	mockInstance.EXPECT().Type().Return(InstanceType)
-	mockInstance.EXPECT().SubnetID().Return(SubnetId).Times(2)
+	mockInstance.EXPECT().SubnetID().Return(SubnetId).Times(3)
 	mockInstance.EXPECT().SubnetCidrBlock().Return(SubnetCidrBlock).Times(2)
 	mockInstance.EXPECT().SubnetV6CidrBlock().Return(SubnetV6CidrBlock).Times(2)
 	mockInstance.EXPECT().CurrentInstanceSecurityGroups().Return(InstanceSecurityGroup)
 
-	mockEC2APIHelper.EXPECT().CreateNetworkInterface(&BranchEniDescription, &SubnetId, InstanceSecurityGroup,
+	mockEC2APIHelper.EXPECT().CreateNetworkInterface(gomock.Any(), &BranchEniDescription, &SubnetId, InstanceSecurityGroup,
 		append(vlan1Tag, trunkENI.nodeIDTag...), nil, nil).Return(BranchInterface1, nil)
-	mockEC2APIHelper.EXPECT().AssociateBranchToTrunk(&trunkId, &Branch1Id, VlanId1).Return(mockAssociationOutput1, nil)
-	mockEC2APIHelper.EXPECT().CreateNetworkInterface(&BranchEniDescription, &SubnetId, InstanceSecurityGroup,
+	mockEC2APIHelper.EXPECT().AssociateBranchToTrunk(gomock.Any(), &trunkId, &Branch1Id, VlanId1).Return(mockAssociationOutput1, nil)
+	mockEC2APIHelper.EXPECT().CreateNetworkInterface(gomock.Any(), &BranchEniDescription, &SubnetId, InstanceSecurityGroup,
 		append(vlan2Tag, trunkENI.nodeIDTag...), nil, nil).Return(BranchInterface2, nil)
-	mockEC2APIHelper.EXPECT().AssociateBranchToTrunk(&trunkId, &Branch2Id, VlanId2).Return(mockAssociationOutput2, nil)
+	mockEC2APIHelper.EXPECT().AssociateBranchToTrunk(gomock.Any(), &trunkId, &Branch2Id, VlanId2).Return(mockAssociationOutput2, nil)
 
-	eniDetails, err := trunkENI.CreateAndAssociateBranchENIs(MockPod2, []string{}, 2)
-	expectedENIDetails := []*ENIDetails{EniDetails1, EniDetails2}
+	eniDetails, err := trunkENI.CreateAndAssociateBranchENIs(context.TODO(), MockPod2, []string{}, 2)
+	expectedENIDetails := withSecurityGroups(InstanceSecurityGroup, EniDetails1, EniDetails2)
 
 	assert.NoError(t, err)
 	// VLan ID are marked as used
@@ -904,22 +926,22 @@ func TestTrunkENI_CreateAndAssociateBranchENIs_ErrorAssociate(t *testing.T) {
 	trunkENI.trunkENIId = trunkId
 
 	mockInstance.EXPECT().Type().Return(InstanceType)
-	mockInstance.EXPECT().SubnetID().Return(SubnetId).Times(2)
+	mockInstance.EXPECT().SubnetID().Return(SubnetId).Times(3)
 	mockInstance.EXPECT().SubnetCidrBlock().Return(SubnetCidrBlock).Times(2)
 	mockInstance.EXPECT().SubnetV6CidrBlock().Return(SubnetV6CidrBlock).Times(2)
 
 	gomock.InOrder(
-		mockEC2APIHelper.EXPECT().CreateNetworkInterface(&BranchEniDescription, &SubnetId, SecurityGroups,
+		mockEC2APIHelper.EXPECT().CreateNetworkInterface(gomock.Any(), &BranchEniDescription, &SubnetId, SecurityGroups,
 			append(vlan1Tag, trunkENI.nodeIDTag...), nil, nil).Return(BranchInterface1, nil),
-		mockEC2APIHelper.EXPECT().AssociateBranchToTrunk(&trunkId, &Branch1Id, VlanId1).Return(mockAssociationOutput1, nil),
-		mockEC2APIHelper.EXPECT().CreateNetworkInterface(&BranchEniDescription, &SubnetId, SecurityGroups,
+		mockEC2APIHelper.EXPECT().AssociateBranchToTrunk(gomock.Any(), &trunkId, &Branch1Id, VlanId1).Return(mockAssociationOutput1, nil),
+		mockEC2APIHelper.EXPECT().CreateNetworkInterface(gomock.Any(), &BranchEniDescription, &SubnetId, SecurityGroups,
 			append(vlan2Tag, trunkENI.nodeIDTag...), nil, nil).Return(BranchInterface2, nil),
-		mockEC2APIHelper.EXPECT().AssociateBranchToTrunk(&trunkId, &Branch2Id, VlanId2).Return(nil, MockError),
+		mockEC2APIHelper.EXPECT().AssociateBranchToTrunk(gomock.Any(), &trunkId, &Branch2Id, VlanId2).Return(nil, MockError),
 	)
 
-	_, err := trunkENI.CreateAndAssociateBranchENIs(MockPod2, SecurityGroups, 2)
+	_, err := trunkENI.CreateAndAssociateBranchENIs(context.TODO(), MockPod2, SecurityGroups, 2)
 	assert.Error(t, MockError, err)
-	assert.Equal(t, []*ENIDetails{EniDetails1, ENIDetailsMissingAssociationID}, trunkENI.deleteQueue)
+	assert.Equal(t, withSecurityGroups(SecurityGroups, EniDetails1, ENIDetailsMissingAssociationID), trunkENI.deleteQueue)
 }
 
 // TestTrunkENI_CreateAndAssociateBranchENIs_ErrorCreate tests if error is returned on associate then the created interfaces
@@ -932,21 +954,123 @@ func TestTrunkENI_CreateAndAssociateBranchENIs_ErrorCreate(t *testing.T) {
 	trunkENI.trunkENIId = trunkId
 
 	mockInstance.EXPECT().Type().Return(InstanceType)
-	mockInstance.EXPECT().SubnetID().Return(SubnetId).Times(2)
-	mockInstance.EXPECT().SubnetCidrBlock().Return(SubnetCidrBlock).Times(1)
-	mockInstance.EXPECT().SubnetV6CidrBlock().Return(SubnetV6CidrBlock).Times(1)
+	mockInstance.EXPECT().SubnetID().Return(SubnetId).Times(3)
+	mockInstance.EXPECT().SubnetCidrBlock().Return(SubnetCidrBlock).Times(2)
+	mockInstance.EXPECT().SubnetV6CidrBlock().Return(SubnetV6CidrBlock).Times(2)
 
 	gomock.InOrder(
-		mockEC2APIHelper.EXPECT().CreateNetworkInterface(&BranchEniDescription, &SubnetId, SecurityGroups, append(vlan1Tag, trunkENI.nodeIDTag...),
+		mockEC2APIHelper.EXPECT().CreateNetworkInterface(gomock.Any(), &BranchEniDescription, &SubnetId, SecurityGroups, append(vlan1Tag, trunkENI.nodeIDTag...),
 			nil, nil).Return(BranchInterface1, nil),
-		mockEC2APIHelper.EXPECT().AssociateBranchToTrunk(&trunkId, &Branch1Id, VlanId1).Return(mockAssociationOutput1, nil),
-		mockEC2APIHelper.EXPECT().CreateNetworkInterface(&BranchEniDescription, &SubnetId, SecurityGroups, append(vlan2Tag, trunkENI.nodeIDTag...),
+		mockEC2APIHelper.EXPECT().AssociateBranchToTrunk(gomock.Any(), &trunkId, &Branch1Id, VlanId1).Return(mockAssociationOutput1, nil),
+		mockEC2APIHelper.EXPECT().CreateNetworkInterface(gomock.Any(), &BranchEniDescription, &SubnetId, SecurityGroups, append(vlan2Tag, trunkENI.nodeIDTag...),
 			nil, nil).Return(nil, MockError),
 	)
 
-	_, err := trunkENI.CreateAndAssociateBranchENIs(MockPod2, SecurityGroups, 2)
+	_, err := trunkENI.CreateAndAssociateBranchENIs(context.TODO(), MockPod2, SecurityGroups, 2)
 	assert.Error(t, MockError, err)
-	assert.Equal(t, []*ENIDetails{EniDetails1}, trunkENI.deleteQueue)
+	assert.Equal(t, withSecurityGroups(SecurityGroups, EniDetails1), trunkENI.deleteQueue)
+}
+
+// TestTrunkENI_CreateAndAssociateBranchENIs_ReuseCooledDownENI tests the cooled down branch ENI with the same security
+// groups and subnet is reassigned from the delete queue without creating a new branch ENI
+func TestTrunkENI_CreateAndAssociateBranchENIs_ReuseCooledDownENI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	trunkENI, mockEC2APIHelper, mockInstance := getMockHelperInstanceAndTrunkObject(ctrl)
+	cooledDownENIs := withSecurityGroups(SecurityGroups, EniDetails1)
+	otherSGENIs := withSecurityGroups(InstanceSecurityGroup, EniDetails2)
+	trunkENI.usedVlanIds[VlanId1] = true
+	trunkENI.usedVlanIds[VlanId2] = true
+	trunkENI.deleteQueue = append(trunkENI.deleteQueue, otherSGENIs[0], cooledDownENIs[0])
+
+	mockInstance.EXPECT().SubnetID().Return(SubnetId)
+	mockEC2APIHelper.EXPECT().GetNetworkInterfaceSecurityGroups(gomock.Any(), []string{EniDetails1.ID}).
+		Return(map[string][]string{EniDetails1.ID: SecurityGroups}, nil)
+
+	eniDetails, err := trunkENI.CreateAndAssociateBranchENIs(context.TODO(), MockPod2, SecurityGroups, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, cooledDownENIs, eniDetails)
+	assert.Equal(t, cooledDownENIs, trunkENI.uidToBranchENIMap[PodUID2])
+	assert.Equal(t, otherSGENIs, trunkENI.deleteQueue)
+	// The VLAN ID is still assigned to the reused branch ENI
+	assert.True(t, trunkENI.usedVlanIds[VlanId1])
+}
+
+// TestTrunkENI_UpdateBranchENISecurityGroups tests the modified security groups are recorded on the branch ENI of the
+// pod and the branch ENI is not reused once cooled down
+func TestTrunkENI_UpdateBranchENISecurityGroups(t *testing.T) {
+	trunkENI := getMockTrunk()
+	branchENIs := withSecurityGroups(SecurityGroups, EniDetails1)
+	trunkENI.uidToBranchENIMap[PodUID2] = branchENIs
+
+	trunkENI.UpdateBranchENISecurityGroups(PodUID2, EniDetails1.ID, InstanceSecurityGroup)
+	assert.Equal(t, sortedSecurityGroups(InstanceSecurityGroup), branchENIs[0].securityGroups)
+	assert.True(t, branchENIs[0].securityGroupsModified)
+
+	trunkENI.deleteQueue = append(trunkENI.deleteQueue, branchENIs...)
+	assert.Empty(t, trunkENI.popCooledDownENIsFromDeleteQueue(InstanceSecurityGroup, []string{SubnetId}, 1))
+	assert.Equal(t, branchENIs, trunkENI.deleteQueue)
+}
+
+// TestTrunkENI_CreateAndAssociateBranchENIs_ReuseCooledDownENI_SecurityGroupsChanged tests the cooled down branch ENI
+// whose security groups were changed in EC2 is not reused and a new branch ENI is created instead
+func TestTrunkENI_CreateAndAssociateBranchENIs_ReuseCooledDownENI_SecurityGroupsChanged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	trunkENI, mockEC2APIHelper, mockInstance := getMockHelperInstanceAndTrunkObject(ctrl)
+	trunkENI.trunkENIId = trunkId
+	changedENIs := withSecurityGroups(SecurityGroups, EniDetails2)
+	trunkENI.usedVlanIds[VlanId2] = true
+	trunkENI.deleteQueue = append(trunkENI.deleteQueue, changedENIs...)
+
+	mockInstance.EXPECT().Type().Return(InstanceType)
+	mockInstance.EXPECT().SubnetID().Return(SubnetId).Times(2)
+	mockInstance.EXPECT().SubnetCidrBlock().Return(SubnetCidrBlock)
+	mockInstance.EXPECT().SubnetV6CidrBlock().Return(SubnetV6CidrBlock)
+	mockEC2APIHelper.EXPECT().GetNetworkInterfaceSecurityGroups(gomock.Any(), []string{EniDetails2.ID}).
+		Return(map[string][]string{EniDetails2.ID: InstanceSecurityGroup}, nil)
+	mockEC2APIHelper.EXPECT().CreateNetworkInterface(gomock.Any(), &BranchEniDescription, &SubnetId, SecurityGroups,
+		append(vlan1Tag, trunkENI.nodeIDTag...), nil, nil).Return(BranchInterface1, nil)
+	mockEC2APIHelper.EXPECT().AssociateBranchToTrunk(gomock.Any(), &trunkId, &Branch1Id, VlanId1).Return(mockAssociationOutput1, nil)
+
+	eniDetails, err := trunkENI.CreateAndAssociateBranchENIs(context.TODO(), MockPod2, SecurityGroups, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, withSecurityGroups(SecurityGroups, EniDetails1), eniDetails)
+	assert.Equal(t, changedENIs, trunkENI.deleteQueue)
+	assert.True(t, changedENIs[0].securityGroupsModified)
+}
+
+// TestTrunkENI_CreateAndAssociateBranchENIs_NotCooledDownENI tests the branch ENI that has not cooled down is not
+// reused and a new branch ENI is created instead
+func TestTrunkENI_CreateAndAssociateBranchENIs_NotCooledDownENI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	trunkENI, mockEC2APIHelper, mockInstance := getMockHelperInstanceAndTrunkObject(ctrl)
+	trunkENI.trunkENIId = trunkId
+	notCooledDownENIs := withSecurityGroups(SecurityGroups, EniDetails2)
+	notCooledDownENIs[0].deletionTimeStamp = time.Now()
+	trunkENI.usedVlanIds[VlanId2] = true
+	trunkENI.deleteQueue = append(trunkENI.deleteQueue, notCooledDownENIs...)
+
+	mockK8sAPI := mock_k8s.NewMockK8sWrapper(ctrl)
+	mockK8sAPI.EXPECT().GetConfigMap(config.VpcCniConfigMapName, config.KubeSystemNamespace).Return(createCoolDownMockCM("30"), nil)
+	cooldown.InitCoolDownPeriod(mockK8sAPI, zap.New(zap.UseDevMode(true)).WithName("cooldown"))
+
+	mockInstance.EXPECT().Type().Return(InstanceType)
+	mockInstance.EXPECT().SubnetID().Return(SubnetId).Times(2)
+	mockInstance.EXPECT().SubnetCidrBlock().Return(SubnetCidrBlock)
+	mockInstance.EXPECT().SubnetV6CidrBlock().Return(SubnetV6CidrBlock)
+	mockEC2APIHelper.EXPECT().CreateNetworkInterface(gomock.Any(), &BranchEniDescription, &SubnetId, SecurityGroups,
+		append(vlan1Tag, trunkENI.nodeIDTag...), nil, nil).Return(BranchInterface1, nil)
+	mockEC2APIHelper.EXPECT().AssociateBranchToTrunk(gomock.Any(), &trunkId, &Branch1Id, VlanId1).Return(mockAssociationOutput1, nil)
+
+	eniDetails, err := trunkENI.CreateAndAssociateBranchENIs(context.TODO(), MockPod2, SecurityGroups, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, withSecurityGroups(SecurityGroups, EniDetails1), eniDetails)
+	assert.Equal(t, notCooledDownENIs, trunkENI.deleteQueue)
 }
 
 func TestTrunkENI_Introspect(t *testing.T) {
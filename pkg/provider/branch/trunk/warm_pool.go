@@ -0,0 +1,225 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package trunk
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
+)
+
+// warmPool is the pool of warm branch ENIs created with the same set of security groups
+type warmPool struct {
+	// securityGroups is the sorted list of security groups of the branch ENIs in the pool
+	securityGroups []string
+	// enis is the list of warm branch ENIs associated with the trunk and not used by any pod
+	enis []*ENIDetails
+	// lastUsed is the last time a pod requested branch ENIs with the security groups of the pool
+	lastUsed time.Time
+}
+
+// sortedSecurityGroups returns a sorted copy of the security groups
+func sortedSecurityGroups(securityGroups []string) []string {
+	sorted := slices.Clone(securityGroups)
+	slices.Sort(sorted)
+	return slices.Compact(sorted)
+}
+
+// securityGroupsKey returns the key of the warm pool for the security groups
+func securityGroupsKey(securityGroups []string) string {
+	return strings.Join(sortedSecurityGroups(securityGroups), ",")
+}
+
+// popENIsFromWarmPool removes up to count branch ENIs from the warm pool of the security groups and marks the pool
+// as used. The pool is created on the first request so that it's filled by the next warm pool reconcile
+func (t *trunkENI) popENIsFromWarmPool(securityGroups []string, count int) []*ENIDetails {
+	if t.warmPoolConfig == nil {
+		return nil
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	key := securityGroupsKey(securityGroups)
+	pool, ok := t.warmPools[key]
+	if !ok {
+		pool = &warmPool{securityGroups: sortedSecurityGroups(securityGroups)}
+		t.warmPools[key] = pool
+	}
+	pool.lastUsed = time.Now()
+
+	count = min(count, len(pool.enis))
+	enis := slices.Clone(pool.enis[:count])
+	pool.enis = pool.enis[count:]
+	return enis
+}
+
+// pushENIsToWarmPool pushes the branch ENIs back to the warm pool of their security groups
+func (t *trunkENI) pushENIsToWarmPool(enis []*ENIDetails) {
+	if len(enis) == 0 {
+		return
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for _, eni := range enis {
+		key := securityGroupsKey(eni.securityGroups)
+		pool, ok := t.warmPools[key]
+		if !ok {
+			pool = &warmPool{securityGroups: eni.securityGroups, lastUsed: time.Now()}
+			t.warmPools[key] = pool
+		}
+		pool.enis = append(pool.enis, eni)
+	}
+}
+
+// returnENIToWarmPool adds the cooled down branch ENI to the warm pool of its security groups if the pool is below
+// the desired size. The branch ENIs whose security groups were modified are not returned as the warm pool hands out
+// its branch ENIs without verifying them. Returns false if the branch ENI should be deleted instead
+func (t *trunkENI) returnENIToWarmPool(eni *ENIDetails) bool {
+	if t.warmPoolConfig == nil || len(eni.securityGroups) == 0 || eni.securityGroupsModified {
+		return false
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	pool, ok := t.warmPools[securityGroupsKey(eni.securityGroups)]
+	if !ok || len(pool.enis) >= t.warmPoolConfig.DesiredSize {
+		return false
+	}
+
+	eni.deletionTimeStamp = time.Time{}
+	eni.deleteRetryCount = 0
+	pool.enis = append(pool.enis, eni)
+	branchENIOperationsSuccessCount.WithLabelValues("returned_branch_eni_to_warm_pool").Inc()
+	t.log.V(1).Info("returned cooled down eni to warm pool", "eni", eni, "security groups", pool.securityGroups)
+
+	return true
+}
+
+// evictWarmENIs removes up to count branch ENIs from the warm pools of the security groups other than the given ones,
+// starting from the least recently used pool, so that the capacity they hold can be used by the pod
+func (t *trunkENI) evictWarmENIs(securityGroups []string, count int) []*ENIDetails {
+	if t.warmPoolConfig == nil {
+		return nil
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	key := securityGroupsKey(securityGroups)
+	var pools []*warmPool
+	for poolKey, pool := range t.warmPools {
+		if poolKey != key && len(pool.enis) > 0 {
+			pools = append(pools, pool)
+		}
+	}
+	slices.SortFunc(pools, func(a, b *warmPool) int {
+		return a.lastUsed.Compare(b.lastUsed)
+	})
+
+	var evicted []*ENIDetails
+	for _, pool := range pools {
+		n := min(count-len(evicted), len(pool.enis))
+		evicted = append(evicted, pool.enis[:n]...)
+		pool.enis = pool.enis[n:]
+		if len(evicted) == count {
+			break
+		}
+	}
+	return evicted
+}
+
+// deleteEvictedENIs deletes the branch ENIs evicted from the warm pools and returns the number of branch ENIs deleted.
+// The branch ENIs were never used by a pod so they are deleted without cool down, the branch ENIs that fail to delete
+// are moved to the delete queue to be retried
+func (t *trunkENI) deleteEvictedENIs(ctx context.Context, enis []*ENIDetails) int {
+	var deleted int
+	for _, eni := range enis {
+		if err := t.deleteENI(ctx, eni); err != nil {
+			t.PushENIsToFrontOfDeleteQueue(nil, []*ENIDetails{eni})
+			continue
+		}
+		deleted++
+		branchENIOperationsSuccessCount.WithLabelValues("evicted_warm_branch_eni").Inc()
+		t.log.Info("evicted warm branch ENI to free capacity", "eni", eni, "security groups", eni.securityGroups)
+	}
+	return deleted
+}
+
+// ReconcileWarmPool creates branch ENIs for the warm pools that are below the desired size by more than the max
+// deviation and moves the branch ENIs of the warm pools that are above the desired size by more than the max
+// deviation to the delete queue. The warm pools that were not used for config.BranchENIWarmPoolIdleTimeout are
+// drained and removed
+func (t *trunkENI) ReconcileWarmPool(ctx context.Context) {
+	if t.warmPoolConfig == nil {
+		return
+	}
+
+	toCreate, toDelete := t.getWarmPoolDeviation()
+	if len(toDelete) > 0 {
+		t.log.Info("moving excess warm branch ENIs to delete queue", "ENIs", toDelete)
+		t.PushENIsToFrontOfDeleteQueue(nil, toDelete)
+	}
+
+	for key, count := range toCreate {
+		securityGroups := strings.Split(key, ",")
+		for i := 0; i < count; i++ {
+			if !t.canCreateMore() {
+				t.log.V(1).Info("cannot create more warm branch ENIs as trunk is at max capacity")
+				return
+			}
+			eni, err := t.createAndAssociateBranchENI(ctx, securityGroups)
+			if err != nil {
+				t.log.Error(err, "failed to create warm branch ENI", "security groups", securityGroups)
+				if eni != nil {
+					t.PushENIsToFrontOfDeleteQueue(nil, []*ENIDetails{eni})
+				}
+				break
+			}
+			branchENIOperationsSuccessCount.WithLabelValues("created_warm_branch_eni").Inc()
+			t.pushENIsToWarmPool([]*ENIDetails{eni})
+		}
+	}
+}
+
+// getWarmPoolDeviation returns the number of branch ENIs to create for each warm pool and removes the branch ENIs to
+// be deleted from the warm pools
+func (t *trunkENI) getWarmPoolDeviation() (toCreate map[string]int, toDelete []*ENIDetails) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	toCreate = make(map[string]int)
+	for key, pool := range t.warmPools {
+		if time.Since(pool.lastUsed) > config.BranchENIWarmPoolIdleTimeout {
+			toDelete = append(toDelete, pool.enis...)
+			delete(t.warmPools, key)
+			continue
+		}
+
+		deviation := t.warmPoolConfig.DesiredSize - len(pool.enis)
+		if deviation > t.warmPoolConfig.MaxDeviation {
+			toCreate[key] = deviation
+		} else if -deviation > t.warmPoolConfig.MaxDeviation {
+			toDelete = append(toDelete, pool.enis[t.warmPoolConfig.DesiredSize:]...)
+			pool.enis = pool.enis[:t.warmPoolConfig.DesiredSize]
+		}
+	}
+	return toCreate, toDelete
+}
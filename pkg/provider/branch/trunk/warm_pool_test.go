@@ -0,0 +1,291 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package trunk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mock_k8s "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/k8s"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/vpc"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider/branch/cooldown"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+var (
+	warmPoolSecurityGroups = []string{"sg-2", "sg-1", "sg-2"}
+	warmPoolKey            = "sg-1,sg-2"
+)
+
+func TestSecurityGroupsKey(t *testing.T) {
+	assert.Equal(t, warmPoolKey, securityGroupsKey(warmPoolSecurityGroups))
+	// The input security groups are not modified
+	assert.Equal(t, []string{"sg-2", "sg-1", "sg-2"}, warmPoolSecurityGroups)
+}
+
+// TestTrunkENI_popENIsFromWarmPool tests the warm ENIs are removed from the pool and the pool is created on the first
+// request for the security groups
+func TestTrunkENI_popENIsFromWarmPool(t *testing.T) {
+	trunkENI := getMockTrunk()
+	trunkENI.warmPoolConfig = &config.WarmPoolConfig{DesiredSize: 2}
+
+	enis := trunkENI.popENIsFromWarmPool(warmPoolSecurityGroups, 1)
+	assert.Empty(t, enis)
+	assert.Contains(t, trunkENI.warmPools, warmPoolKey)
+
+	warmENIs := withSecurityGroups(warmPoolSecurityGroups, EniDetails1, EniDetails2)
+	trunkENI.pushENIsToWarmPool(warmENIs)
+
+	enis = trunkENI.popENIsFromWarmPool(warmPoolSecurityGroups, 3)
+	assert.Equal(t, warmENIs, enis)
+	assert.Empty(t, trunkENI.warmPools[warmPoolKey].enis)
+}
+
+// TestTrunkENI_popENIsFromWarmPool_Disabled tests no pool is created when the warm pool is disabled
+func TestTrunkENI_popENIsFromWarmPool_Disabled(t *testing.T) {
+	trunkENI := getMockTrunk()
+
+	enis := trunkENI.popENIsFromWarmPool(warmPoolSecurityGroups, 1)
+	assert.Empty(t, enis)
+	assert.Empty(t, trunkENI.warmPools)
+}
+
+// TestTrunkENI_CreateAndAssociateBranchENIs_WarmPool tests the warm ENIs are assigned to the pod without creating new
+// branch ENIs
+func TestTrunkENI_CreateAndAssociateBranchENIs_WarmPool(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	trunkENI, _, _ := getMockHelperInstanceAndTrunkObject(ctrl)
+	trunkENI.warmPoolConfig = &config.WarmPoolConfig{DesiredSize: 2}
+	warmENIs := withSecurityGroups(warmPoolSecurityGroups, EniDetails1, EniDetails2)
+	trunkENI.pushENIsToWarmPool(warmENIs)
+
+	eniDetails, err := trunkENI.CreateAndAssociateBranchENIs(context.TODO(), MockPod2, warmPoolSecurityGroups, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, warmENIs[:1], eniDetails)
+	assert.Equal(t, warmENIs[:1], trunkENI.uidToBranchENIMap[PodUID2])
+	assert.Equal(t, warmENIs[1:], trunkENI.warmPools[warmPoolKey].enis)
+}
+
+// TestTrunkENI_CreateAndAssociateBranchENIs_WarmPool_ErrorCreate tests the warm ENIs are returned to the pool if
+// creating the remaining branch ENIs fails
+func TestTrunkENI_CreateAndAssociateBranchENIs_WarmPool_ErrorCreate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	trunkENI, mockEC2APIHelper, mockInstance := getMockHelperInstanceAndTrunkObject(ctrl)
+	trunkENI.trunkENIId = trunkId
+	trunkENI.warmPoolConfig = &config.WarmPoolConfig{DesiredSize: 2}
+	warmENIs := withSecurityGroups(warmPoolSecurityGroups, EniDetails2)
+	trunkENI.usedVlanIds[VlanId2] = true
+	trunkENI.pushENIsToWarmPool(warmENIs)
+
+	mockInstance.EXPECT().Type().Return(InstanceType)
+	mockInstance.EXPECT().SubnetID().Return(SubnetId).Times(2)
+	mockInstance.EXPECT().SubnetCidrBlock().Return(SubnetCidrBlock)
+	mockInstance.EXPECT().SubnetV6CidrBlock().Return(SubnetV6CidrBlock)
+	mockEC2APIHelper.EXPECT().CreateNetworkInterface(gomock.Any(), &BranchEniDescription, &SubnetId, warmPoolSecurityGroups,
+		append(vlan1Tag, trunkENI.nodeIDTag...), nil, nil).Return(nil, MockError)
+
+	_, err := trunkENI.CreateAndAssociateBranchENIs(context.TODO(), MockPod2, warmPoolSecurityGroups, 2)
+	assert.ErrorIs(t, err, MockError)
+	assert.Equal(t, warmENIs, trunkENI.warmPools[warmPoolKey].enis)
+	assert.Empty(t, trunkENI.deleteQueue)
+	assert.False(t, trunkENI.usedVlanIds[VlanId1])
+}
+
+// TestTrunkENI_DeleteCooledDownENIs_ReturnToWarmPool tests the cooled down ENIs are returned to the warm pool until
+// the pool reaches the desired size and the remaining ENIs are deleted
+func TestTrunkENI_DeleteCooledDownENIs_ReturnToWarmPool(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	trunkENI, ec2APIHelper, _ := getMockHelperInstanceAndTrunkObject(ctrl)
+	trunkENI.warmPoolConfig = &config.WarmPoolConfig{DesiredSize: 1}
+	trunkENI.popENIsFromWarmPool(warmPoolSecurityGroups, 1)
+
+	cooledDownENIs := withSecurityGroups(warmPoolSecurityGroups, EniDetails1, EniDetails2)
+	cooledDownENIs[0].deletionTimeStamp = time.Now().Add(-time.Second * 60)
+	cooledDownENIs[1].deletionTimeStamp = time.Now().Add(-time.Second * 60)
+	trunkENI.deleteQueue = append(trunkENI.deleteQueue, cooledDownENIs...)
+
+	ec2APIHelper.EXPECT().DisassociateTrunkInterface(gomock.Any(), &MockAssociationID2).Return(nil)
+	ec2APIHelper.EXPECT().DeleteNetworkInterface(gomock.Any(), &EniDetails2.ID).Return(nil)
+
+	mockK8sAPI := mock_k8s.NewMockK8sWrapper(ctrl)
+	mockK8sAPI.EXPECT().GetConfigMap(config.VpcCniConfigMapName, config.KubeSystemNamespace).Return(createCoolDownMockCM("30"), nil)
+	cooldown.InitCoolDownPeriod(mockK8sAPI, zap.New(zap.UseDevMode(true)).WithName("cooldown"))
+
+	trunkENI.DeleteCooledDownENIs(context.TODO())
+	assert.Empty(t, trunkENI.deleteQueue)
+	assert.Equal(t, cooledDownENIs[:1], trunkENI.warmPools[warmPoolKey].enis)
+	assert.True(t, cooledDownENIs[0].deletionTimeStamp.IsZero())
+}
+
+// TestTrunkENI_returnENIToWarmPool_SecurityGroupsModified tests the branch ENI whose security groups were modified is
+// not returned to the warm pool
+func TestTrunkENI_returnENIToWarmPool_SecurityGroupsModified(t *testing.T) {
+	trunkENI := getMockTrunk()
+	trunkENI.warmPoolConfig = &config.WarmPoolConfig{DesiredSize: 1}
+
+	modifiedENIs := withSecurityGroups(warmPoolSecurityGroups, EniDetails1)
+	modifiedENIs[0].securityGroupsModified = true
+
+	assert.False(t, trunkENI.returnENIToWarmPool(modifiedENIs[0]))
+	assert.Nil(t, trunkENI.warmPools[warmPoolKey])
+}
+
+// TestTrunkENI_evictWarmENIs tests the warm ENIs of the other security groups are evicted starting from the least
+// recently used pool, and the warm ENIs of the requested security groups are kept
+func TestTrunkENI_evictWarmENIs(t *testing.T) {
+	trunkENI := getMockTrunk()
+	trunkENI.warmPoolConfig = &config.WarmPoolConfig{DesiredSize: 2}
+
+	recentSecurityGroups, idleSecurityGroups := []string{"sg-3"}, []string{"sg-4"}
+	requestedENIs := withSecurityGroups(warmPoolSecurityGroups, EniDetails1)
+	recentENIs := withSecurityGroups(recentSecurityGroups, EniDetails2)
+	idleENIs := withSecurityGroups(idleSecurityGroups, EniDetails1, EniDetails2)
+	trunkENI.pushENIsToWarmPool(requestedENIs)
+	trunkENI.pushENIsToWarmPool(recentENIs)
+	trunkENI.pushENIsToWarmPool(idleENIs)
+	trunkENI.warmPools[securityGroupsKey(idleSecurityGroups)].lastUsed = time.Now().Add(-time.Minute)
+
+	evicted := trunkENI.evictWarmENIs(warmPoolSecurityGroups, 3)
+	assert.Equal(t, append(idleENIs, recentENIs...), evicted)
+	assert.Equal(t, requestedENIs, trunkENI.warmPools[warmPoolKey].enis)
+	assert.Empty(t, trunkENI.warmPools[securityGroupsKey(recentSecurityGroups)].enis)
+	assert.Empty(t, trunkENI.warmPools[securityGroupsKey(idleSecurityGroups)].enis)
+}
+
+// TestTrunkENI_CreateAndAssociateBranchENIs_EvictWarmENIs tests the warm ENI of other security groups is deleted to
+// create the branch ENI of the pod when the trunk is at max capacity
+func TestTrunkENI_CreateAndAssociateBranchENIs_EvictWarmENIs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	trunkENI, mockEC2APIHelper, mockInstance := getMockHelperInstanceAndTrunkObject(ctrl)
+	trunkENI.trunkENIId = trunkId
+	trunkENI.warmPoolConfig = &config.WarmPoolConfig{DesiredSize: 1}
+	otherSecurityGroups := []string{"sg-3"}
+	otherSGENIs := withSecurityGroups(otherSecurityGroups, EniDetails1)
+	trunkENI.usedVlanIds[VlanId1] = true
+	trunkENI.pushENIsToWarmPool(otherSGENIs)
+	// The remaining capacity of the trunk is held by the pods
+	trunkENI.uidToBranchENIMap[PodUID] = make([]*ENIDetails, vpc.Limits[InstanceType].BranchInterface-1)
+
+	mockInstance.EXPECT().Type().Return(InstanceType)
+	mockInstance.EXPECT().SubnetID().Return(SubnetId).Times(2)
+	mockInstance.EXPECT().SubnetCidrBlock().Return(SubnetCidrBlock)
+	mockInstance.EXPECT().SubnetV6CidrBlock().Return(SubnetV6CidrBlock)
+	mockEC2APIHelper.EXPECT().DisassociateTrunkInterface(gomock.Any(), &MockAssociationID1).Return(nil)
+	mockEC2APIHelper.EXPECT().DeleteNetworkInterface(gomock.Any(), &EniDetails1.ID).Return(nil)
+	mockEC2APIHelper.EXPECT().CreateNetworkInterface(gomock.Any(), &BranchEniDescription, &SubnetId, SecurityGroups,
+		append(vlan1Tag, trunkENI.nodeIDTag...), nil, nil).Return(BranchInterface1, nil)
+	mockEC2APIHelper.EXPECT().AssociateBranchToTrunk(gomock.Any(), &trunkId, &Branch1Id, VlanId1).Return(mockAssociationOutput1, nil)
+
+	eniDetails, err := trunkENI.CreateAndAssociateBranchENIs(context.TODO(), MockPod2, SecurityGroups, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, withSecurityGroups(SecurityGroups, EniDetails1), eniDetails)
+	assert.Empty(t, trunkENI.warmPools[securityGroupsKey(otherSecurityGroups)].enis)
+	assert.Empty(t, trunkENI.deleteQueue)
+}
+
+// TestTrunkENI_ReconcileWarmPool_Create tests branch ENIs are created with the context of the job for the warm pool
+// below the desired size
+func TestTrunkENI_ReconcileWarmPool_Create(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	trunkENI, mockEC2APIHelper, mockInstance := getMockHelperInstanceAndTrunkObject(ctrl)
+	trunkENI.trunkENIId = trunkId
+	trunkENI.warmPoolConfig = &config.WarmPoolConfig{DesiredSize: 2}
+	trunkENI.popENIsFromWarmPool(warmPoolSecurityGroups, 1)
+	sortedSGs := sortedSecurityGroups(warmPoolSecurityGroups)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockInstance.EXPECT().Type().Return(InstanceType).Times(2)
+	mockInstance.EXPECT().SubnetID().Return(SubnetId).Times(2)
+	mockInstance.EXPECT().SubnetCidrBlock().Return(SubnetCidrBlock).Times(2)
+	mockInstance.EXPECT().SubnetV6CidrBlock().Return(SubnetV6CidrBlock).Times(2)
+
+	mockEC2APIHelper.EXPECT().CreateNetworkInterface(ctx, &BranchEniDescription, &SubnetId, sortedSGs,
+		append(vlan1Tag, trunkENI.nodeIDTag...), nil, nil).Return(BranchInterface1, nil)
+	mockEC2APIHelper.EXPECT().AssociateBranchToTrunk(ctx, &trunkId, &Branch1Id, VlanId1).Return(mockAssociationOutput1, nil)
+	mockEC2APIHelper.EXPECT().CreateNetworkInterface(ctx, &BranchEniDescription, &SubnetId, sortedSGs,
+		append(vlan2Tag, trunkENI.nodeIDTag...), nil, nil).Return(BranchInterface2, nil)
+	mockEC2APIHelper.EXPECT().AssociateBranchToTrunk(ctx, &trunkId, &Branch2Id, VlanId2).Return(mockAssociationOutput2, nil)
+
+	trunkENI.ReconcileWarmPool(ctx)
+	assert.Equal(t, withSecurityGroups(warmPoolSecurityGroups, EniDetails1, EniDetails2),
+		trunkENI.warmPools[warmPoolKey].enis)
+}
+
+// TestTrunkENI_ReconcileWarmPool_WithinDeviation tests no branch ENIs are created or deleted when the warm pool is
+// within the max deviation of the desired size
+func TestTrunkENI_ReconcileWarmPool_WithinDeviation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	trunkENI, _, _ := getMockHelperInstanceAndTrunkObject(ctrl)
+	trunkENI.warmPoolConfig = &config.WarmPoolConfig{DesiredSize: 2, MaxDeviation: 1}
+	trunkENI.popENIsFromWarmPool(warmPoolSecurityGroups, 1)
+	trunkENI.pushENIsToWarmPool(withSecurityGroups(warmPoolSecurityGroups, EniDetails1))
+
+	trunkENI.ReconcileWarmPool(context.TODO())
+	assert.Len(t, trunkENI.warmPools[warmPoolKey].enis, 1)
+	assert.Empty(t, trunkENI.deleteQueue)
+}
+
+// TestTrunkENI_ReconcileWarmPool_Trim tests the excess warm ENIs are moved to the delete queue
+func TestTrunkENI_ReconcileWarmPool_Trim(t *testing.T) {
+	trunkENI := getMockTrunk()
+	trunkENI.warmPoolConfig = &config.WarmPoolConfig{DesiredSize: 1}
+	warmENIs := withSecurityGroups(warmPoolSecurityGroups, EniDetails1, EniDetails2)
+	trunkENI.popENIsFromWarmPool(warmPoolSecurityGroups, 1)
+	trunkENI.pushENIsToWarmPool(warmENIs)
+
+	trunkENI.ReconcileWarmPool(context.TODO())
+	assert.Equal(t, warmENIs[:1], trunkENI.warmPools[warmPoolKey].enis)
+	assert.Equal(t, warmENIs[1:], trunkENI.deleteQueue)
+}
+
+// TestTrunkENI_ReconcileWarmPool_Idle tests the warm pool not used for the idle timeout is drained and removed
+func TestTrunkENI_ReconcileWarmPool_Idle(t *testing.T) {
+	trunkENI := getMockTrunk()
+	trunkENI.warmPoolConfig = &config.WarmPoolConfig{DesiredSize: 2}
+	warmENIs := withSecurityGroups(warmPoolSecurityGroups, EniDetails1, EniDetails2)
+	trunkENI.popENIsFromWarmPool(warmPoolSecurityGroups, 1)
+	trunkENI.pushENIsToWarmPool(warmENIs)
+	trunkENI.warmPools[warmPoolKey].lastUsed = time.Now().Add(-config.BranchENIWarmPoolIdleTimeout - time.Minute)
+
+	trunkENI.ReconcileWarmPool(context.TODO())
+	assert.Empty(t, trunkENI.warmPools)
+	assert.Equal(t, warmENIs, trunkENI.deleteQueue)
+}
+
+// TestTrunkENI_ReconcileWarmPool_Disabled tests the warm pools are not reconciled when the warm pool is disabled
+func TestTrunkENI_ReconcileWarmPool_Disabled(t *testing.T) {
+	trunkENI := getMockTrunk()
+	trunkENI.warmPools[warmPoolKey] = &warmPool{lastUsed: time.Now().Add(-config.BranchENIWarmPoolIdleTimeout * 2)}
+
+	trunkENI.ReconcileWarmPool(context.TODO())
+	assert.Contains(t, trunkENI.warmPools, warmPoolKey)
+}
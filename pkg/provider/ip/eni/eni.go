@@ -14,10 +14,13 @@
 package eni
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 
+	"github.com/aws/amazon-vpc-resource-controller-k8s/apis/vpcresources/v1alpha1"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/ec2"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/ec2/api"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/vpc"
@@ -25,6 +28,7 @@ import (
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/utils"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/go-logr/logr"
 )
 
@@ -39,12 +43,18 @@ type eniManager struct {
 	attachedENIs []*eni
 	// resourceToENIMap is the map from IPv4 address or prefix to the ENI that it belongs to
 	resourceToENIMap map[string]*eni
+	// hasTrunk is set if the trunk interface is attached to the instance, or will be attached as security groups for
+	// pods is enabled. The trunk interface takes up one of the interfaces of the instance
+	hasTrunk bool
 }
 
 // eniDetails stores the eniID along with the number of new IPs that can be assigned form it
 type eni struct {
 	eniID             string
 	remainingCapacity int
+	// attachmentID and deviceIndex are used to detach the ENI from the instance and free the device index on deletion
+	attachmentID string
+	deviceIndex  int32
 }
 
 type IPv4Resource struct {
@@ -53,25 +63,37 @@ type IPv4Resource struct {
 }
 
 type ENIManager interface {
-	InitResources(ec2APIHelper api.EC2APIHelper) (*IPv4Resource, error)
-	CreateIPV4Resource(required int, resourceType config.ResourceType, ec2APIHelper api.EC2APIHelper, log logr.Logger) ([]string, error)
-	DeleteIPV4Resource(ipList []string, resourceType config.ResourceType, ec2APIHelper api.EC2APIHelper, log logr.Logger) ([]string, error)
+	InitResources(ctx context.Context, ec2APIHelper api.EC2APIHelper) (*IPv4Resource, error)
+	InitResourcesFromCheckpoint(enis []v1alpha1.ENICheckpoint) *IPv4Resource
+	Checkpoint() []v1alpha1.ENICheckpoint
+	CreateIPV4Resource(ctx context.Context, required int, resourceType config.ResourceType, ec2APIHelper api.EC2APIHelper, log logr.Logger) ([]string, error)
+	DeleteIPV4Resource(ctx context.Context, ipList []string, resourceType config.ResourceType, ec2APIHelper api.EC2APIHelper, log logr.Logger) ([]string, error)
 }
 
-// NewENIManager returns a new ENI Manager
-func NewENIManager(instance ec2.EC2Instance) *eniManager {
+// NewENIManager returns a new ENI Manager, hasTrunk reserves one of the interfaces of the instance for the trunk
+// interface
+func NewENIManager(instance ec2.EC2Instance, hasTrunk bool) *eniManager {
 	return &eniManager{
 		resourceToENIMap: map[string]*eni{},
 		instance:         instance,
+		hasTrunk:         hasTrunk,
 	}
 }
 
+// HasTrunk returns true if one of the interfaces of the instance is taken up by the trunk interface
+func (e *eniManager) HasTrunk() bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return e.hasTrunk
+}
+
 // InitResources loads the list of ENIs, secondary IPs and prefixes, associated with the instance
-func (e *eniManager) InitResources(ec2APIHelper api.EC2APIHelper) (*IPv4Resource, error) {
+func (e *eniManager) InitResources(ctx context.Context, ec2APIHelper api.EC2APIHelper) (*IPv4Resource, error) {
 	e.lock.Lock()
 	defer e.lock.Unlock()
 
-	nwInterfaces, err := ec2APIHelper.GetInstanceNetworkInterface(aws.String(e.instance.InstanceID()))
+	nwInterfaces, err := ec2APIHelper.GetInstanceNetworkInterface(ctx, aws.String(e.instance.InstanceID()))
 	if err != nil {
 		return nil, err
 	}
@@ -81,15 +103,29 @@ func (e *eniManager) InitResources(ec2APIHelper api.EC2APIHelper) (*IPv4Resource
 		return nil, fmt.Errorf("unsupported instance type, error: %w", utils.ErrNotFound)
 	}
 
+	// The ENIs are rebuilt from the EC2 response as the manager could have been initialized earlier, either from
+	// EC2 or from a checkpoint
+	e.attachedENIs = nil
+	e.resourceToENIMap = map[string]*eni{}
+
 	ipLimit := limits.IPv4PerInterface
 	var availIPs []string
 	var availPrefixes []string
 	for _, nwInterface := range nwInterfaces {
+		// The trunk interface is managed by the branch ENI provider when security groups for pods is enabled
+		if aws.ToString(nwInterface.InterfaceType) == string(ec2types.NetworkInterfaceTypeTrunk) {
+			e.hasTrunk = true
+			continue
+		}
 		if nwInterface.PrivateIpAddresses != nil {
 			eni := &eni{
 				remainingCapacity: ipLimit,
 				eniID:             *nwInterface.NetworkInterfaceId,
 			}
+			if nwInterface.Attachment != nil {
+				eni.attachmentID = aws.ToString(nwInterface.Attachment.AttachmentId)
+				eni.deviceIndex = aws.ToInt32(nwInterface.Attachment.DeviceIndex)
+			}
 			// loop through assigned IPv4 addresses and store into map
 			for _, ip := range nwInterface.PrivateIpAddresses {
 				if *ip.Primary != true {
@@ -116,9 +152,70 @@ func (e *eniManager) InitResources(ec2APIHelper api.EC2APIHelper) (*IPv4Resource
 	return &ipV4Resource, nil
 }
 
+// InitResourcesFromCheckpoint initializes the ENIs from the checkpoint taken by a previous controller instead of EC2 and
+// returns the IPv4 resources assigned to the ENIs
+func (e *eniManager) InitResourcesFromCheckpoint(enis []v1alpha1.ENICheckpoint) *IPv4Resource {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.attachedENIs = nil
+	e.resourceToENIMap = map[string]*eni{}
+
+	var availIPs []string
+	var availPrefixes []string
+	for _, eniCheckpoint := range enis {
+		eni := &eni{
+			eniID:             eniCheckpoint.ID,
+			remainingCapacity: eniCheckpoint.RemainingCapacity,
+			attachmentID:      eniCheckpoint.AttachmentID,
+			deviceIndex:       eniCheckpoint.DeviceIndex,
+		}
+		for _, resource := range eniCheckpoint.Resources {
+			// Prefixes are stored with the prefix length, whereas the IPv4 addresses are stored without subnet mask
+			if strings.Contains(resource, "/") {
+				availPrefixes = append(availPrefixes, resource)
+			} else {
+				availIPs = append(availIPs, resource)
+			}
+			e.resourceToENIMap[resource] = eni
+		}
+		e.attachedENIs = append(e.attachedENIs, eni)
+	}
+
+	return &IPv4Resource{
+		PrivateIPv4Addresses: e.addSubnetMaskToIPSlice(availIPs),
+		IPv4Prefixes:         availPrefixes,
+	}
+}
+
+// Checkpoint returns the ENIs along with the secondary IPv4 addresses and prefixes assigned to them
+func (e *eniManager) Checkpoint() []v1alpha1.ENICheckpoint {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	resourcesPerENI := make(map[*eni][]string)
+	for resource, eni := range e.resourceToENIMap {
+		resourcesPerENI[eni] = append(resourcesPerENI[eni], resource)
+	}
+
+	var enis []v1alpha1.ENICheckpoint
+	for _, eni := range e.attachedENIs {
+		resources := resourcesPerENI[eni]
+		sort.Strings(resources)
+		enis = append(enis, v1alpha1.ENICheckpoint{
+			ID:                eni.eniID,
+			AttachmentID:      eni.attachmentID,
+			DeviceIndex:       eni.deviceIndex,
+			RemainingCapacity: eni.remainingCapacity,
+			Resources:         resources,
+		})
+	}
+	return enis
+}
+
 // CreateIPV4Resource creates either IPv4 address or IPv4 prefix depending on ResourceType and returns the list of assigned resources
 // along with the error if not all the required resources were assigned
-func (e *eniManager) CreateIPV4Resource(required int, resourceType config.ResourceType, ec2APIHelper api.EC2APIHelper, log logr.Logger) ([]string, error) {
+func (e *eniManager) CreateIPV4Resource(ctx context.Context, required int, resourceType config.ResourceType, ec2APIHelper api.EC2APIHelper, log logr.Logger) ([]string, error) {
 	e.lock.Lock()
 	defer e.lock.Unlock()
 
@@ -139,7 +236,7 @@ func (e *eniManager) CreateIPV4Resource(required int, resourceType config.Resour
 				canAssign = want
 			}
 			// Assign the IPv4 resource from this ENI
-			assigned, err := ec2APIHelper.AssignIPv4ResourcesAndWaitTillReady(e.attachedENIs[index].eniID, resourceType, canAssign)
+			assigned, err := ec2APIHelper.AssignIPv4ResourcesAndWaitTillReady(ctx, e.attachedENIs[index].eniID, resourceType, canAssign)
 			if err != nil && len(assigned) == 0 {
 				// Return the list of resources that were actually created along with the error
 				return assigned, err
@@ -163,71 +260,43 @@ func (e *eniManager) CreateIPV4Resource(required int, resourceType config.Resour
 		}
 	}
 
-	// TODO: Windows doesn't support multi-ENI yet, commenting out code that creates new ENI. Uncomment once multi-ENI is supported.
-	//// Number of secondary IPs or IPv4 prefixes supported minus the primary IP
-	//ipLimit := vpc.Limits[e.instance.Type()].IPv4PerInterface - 1
-	//eniLimit := vpc.Limits[e.instance.Type()].Interface
-	//
-	//// If the existing ENIs could not assign the required resources, loop till the new ENIs can assign the required
-	//// number of IPv4 resources
-	//for len(assignedIPv4Resources) < required &&
-	//	len(e.attachedENIs) < eniLimit {
-	//
-	//	deviceIndex, err := e.instance.GetHighestUnusedDeviceIndex()
-	//	if err != nil {
-	//		// TODO: Refresh device index for linux nodes only
-	//		return assignedIPv4Resources, err
-	//	}
-	//	want := required - len(assignedIPv4Resources)
-	//	if want > ipLimit {
-	//		want = ipLimit
-	//	}
-	//
-	//	// Create new ENI and store newly assigned resources into map
-	//	switch resourceType {
-	//	case config.ResourceTypeIPv4Address:
-	//		ipResourceCount := &config.IPResourceCount{SecondaryIPv4Count: want}
-	//		nwInterface, err := ec2APIHelper.CreateAndAttachNetworkInterface(aws.String(e.instance.InstanceID()),
-	//			aws.String(e.instance.SubnetID()), e.instance.CurrentInstanceSecurityGroups(), nil, aws.Int64(deviceIndex),
-	//			&ENIDescription, nil, ipResourceCount)
-	//		if err != nil {
-	//			// TODO: Check if any clean up is required here for linux nodes only?
-	//			return assignedIPv4Resources, err
-	//		}
-	//		eni := &eni{
-	//			remainingCapacity: ipLimit - want,
-	//			eniID:             *nwInterface.NetworkInterfaceId,
-	//		}
-	//		e.attachedENIs = append(e.attachedENIs, eni)
-	//		for _, assignedIP := range nwInterface.PrivateIpAddresses {
-	//			if !*assignedIP.Primary {
-	//				assignedIPv4Resources = append(assignedIPv4Resources, *assignedIP.PrivateIpAddress)
-	//				// Also add the mapping from IP to ENI
-	//				e.resourceToENIMap[*assignedIP.PrivateIpAddress] = eni
-	//			}
-	//		}
-	//
-	//	case config.ResourceTypeIPv4Prefix:
-	//		ipResourceCount := &config.IPResourceCount{IPv4PrefixCount: want}
-	//		nwInterface, err := ec2APIHelper.CreateAndAttachNetworkInterface(aws.String(e.instance.InstanceID()),
-	//			aws.String(e.instance.SubnetID()), e.instance.CurrentInstanceSecurityGroups(), nil, aws.Int64(deviceIndex),
-	//			&ENIDescription, nil, ipResourceCount)
-	//		if err != nil {
-	//			// TODO: Check if any clean up is required here for linux nodes only?
-	//			return assignedIPv4Resources, err
-	//		}
-	//		eni := &eni{
-	//			remainingCapacity: ipLimit - want,
-	//			eniID:             *nwInterface.NetworkInterfaceId,
-	//		}
-	//		e.attachedENIs = append(e.attachedENIs, eni)
-	//		for _, assignedPrefix := range nwInterface.Ipv4Prefixes {
-	//			assignedIPv4Resources = append(assignedIPv4Resources, *assignedPrefix.Ipv4Prefix)
-	//			// Also add the mapping from Prefix to ENI
-	//			e.resourceToENIMap[*assignedPrefix.Ipv4Prefix] = eni
-	//		}
-	//	}
-	//}
+	if len(assignedIPv4Resources) < required {
+		// Number of secondary IPs or IPv4 prefixes supported minus the primary IP
+		ipLimit := vpc.Limits[e.instance.Type()].IPv4PerInterface - 1
+		eniLimit := vpc.Limits[e.instance.Type()].Interface
+		if e.hasTrunk {
+			eniLimit--
+		}
+
+		// If the existing ENIs could not assign the required resources, loop till the new ENIs can assign the required
+		// number of IPv4 resources
+		for len(assignedIPv4Resources) < required && len(e.attachedENIs) < eniLimit {
+			want := required - len(assignedIPv4Resources)
+			if want > ipLimit {
+				want = ipLimit
+			}
+
+			newENI, assigned, err := e.createAndAttachENI(ctx, want, resourceType, ec2APIHelper)
+			if err != nil {
+				log.Error(err, "failed to create and attach new ENI", "resource type", resourceType, "want", want)
+				// Return the resources assigned till now, the caller will retry for the remaining resources
+				if resourceType == config.ResourceTypeIPv4Address {
+					assignedIPv4Resources = e.addSubnetMaskToIPSlice(assignedIPv4Resources)
+				}
+				return assignedIPv4Resources, err
+			}
+			newENI.remainingCapacity = ipLimit - len(assigned)
+			e.attachedENIs = append(e.attachedENIs, newENI)
+			assignedIPv4Resources = append(assignedIPv4Resources, assigned...)
+			// Add the mapping from IP or prefix to ENI
+			for _, resource := range assigned {
+				e.resourceToENIMap[resource] = newENI
+			}
+
+			log.Info("created new ENI with IPv4 resources", "resource type", resourceType, "resources", assigned,
+				"eni", newENI.eniID, "device index", newENI.deviceIndex, "want", want)
+		}
+	}
 
 	var err error
 	// This can happen if the subnet doesn't have remaining IPs
@@ -244,9 +313,73 @@ func (e *eniManager) CreateIPV4Resource(required int, resourceType config.Resour
 	return assignedIPv4Resources, err
 }
 
+// createAndAttachENI creates a new ENI with the IPv4 resources at the highest unused device index of the instance and
+// returns the ENI along with the list of assigned secondary IPv4 addresses or prefixes
+func (e *eniManager) createAndAttachENI(ctx context.Context, want int, resourceType config.ResourceType,
+	ec2APIHelper api.EC2APIHelper) (*eni, []string, error) {
+	deviceIndex, err := e.instance.GetHighestUnusedDeviceIndex()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ipResourceCount := &config.IPResourceCount{}
+	switch resourceType {
+	case config.ResourceTypeIPv4Address:
+		ipResourceCount.SecondaryIPv4Count = want
+	case config.ResourceTypeIPv4Prefix:
+		ipResourceCount.IPv4PrefixCount = want
+	default:
+		e.instance.FreeDeviceIndex(deviceIndex)
+		return nil, nil, fmt.Errorf("unsupported resource type %s", resourceType)
+	}
+
+	instanceID := e.instance.InstanceID()
+	tags := []ec2types.Tag{
+		{
+			Key:   aws.String(config.NetworkInterfaceNodeIDKey),
+			Value: aws.String(instanceID),
+		},
+	}
+	nwInterface, err := ec2APIHelper.CreateAndAttachNetworkInterface(ctx, aws.String(instanceID),
+		aws.String(e.instance.SubnetID()), e.instance.CurrentInstanceSecurityGroups(), tags, aws.Int32(deviceIndex),
+		&ENIDescription, nil, ipResourceCount)
+	if err != nil {
+		// The network interface is returned only if it could not be deleted after failing to attach, the device
+		// index may still be in use in that case
+		if nwInterface == nil {
+			e.instance.FreeDeviceIndex(deviceIndex)
+		}
+		return nil, nil, err
+	}
+
+	newENI := &eni{
+		eniID:       aws.ToString(nwInterface.NetworkInterfaceId),
+		deviceIndex: deviceIndex,
+	}
+	if nwInterface.Attachment != nil {
+		newENI.attachmentID = aws.ToString(nwInterface.Attachment.AttachmentId)
+	}
+
+	var assigned []string
+	switch resourceType {
+	case config.ResourceTypeIPv4Address:
+		for _, assignedIP := range nwInterface.PrivateIpAddresses {
+			if !aws.ToBool(assignedIP.Primary) {
+				assigned = append(assigned, aws.ToString(assignedIP.PrivateIpAddress))
+			}
+		}
+	case config.ResourceTypeIPv4Prefix:
+		for _, assignedPrefix := range nwInterface.Ipv4Prefixes {
+			assigned = append(assigned, aws.ToString(assignedPrefix.Ipv4Prefix))
+		}
+	}
+
+	return newENI, assigned, nil
+}
+
 // DeleteIPV4Resource deletes the list of IPv4 resources depending on resource type and returns the list of resources
 // that failed to delete along with the error
-func (e *eniManager) DeleteIPV4Resource(resourceList []string, resourceType config.ResourceType, ec2APIHelper api.EC2APIHelper, log logr.Logger) ([]string, error) {
+func (e *eniManager) DeleteIPV4Resource(ctx context.Context, resourceList []string, resourceType config.ResourceType, ec2APIHelper api.EC2APIHelper, log logr.Logger) ([]string, error) {
 	e.lock.Lock()
 	defer e.lock.Unlock()
 
@@ -266,7 +399,7 @@ func (e *eniManager) DeleteIPV4Resource(resourceList []string, resourceType conf
 	groupedResources := e.groupResourcesPerENI(resourceList)
 
 	for eni, resources := range groupedResources {
-		err := ec2APIHelper.UnassignIPv4Resources(eni.eniID, resourceType, resources)
+		err := ec2APIHelper.UnassignIPv4Resources(ctx, eni.eniID, resourceType, resources)
 		if err != nil {
 			errors = append(errors, err)
 			log.Info("failed to deleted IPv4 resources", "eni", eni.eniID, "resource type", resourceType,
@@ -289,13 +422,21 @@ func (e *eniManager) DeleteIPV4Resource(resourceList []string, resourceType conf
 	for _, eni := range e.attachedENIs {
 		// ENI doesn't have any secondary IP or prefix attached to it and is not the primary network interface
 		if eni.remainingCapacity == ipLimit && primaryENIID != eni.eniID {
-			err := ec2APIHelper.DeleteNetworkInterface(&eni.eniID)
+			var err error
+			if eni.attachmentID != "" {
+				err = ec2APIHelper.DetachAndDeleteNetworkInterface(ctx, &eni.attachmentID, &eni.eniID)
+			} else {
+				err = ec2APIHelper.DeleteNetworkInterface(ctx, &eni.eniID)
+			}
 			if err != nil {
 				errors = append(errors, err)
 				e.attachedENIs[i] = eni
 				i++
 				continue
 			}
+			if eni.attachmentID != "" {
+				e.instance.FreeDeviceIndex(eni.deviceIndex)
+			}
 			log.Info("deleted ENI successfully as it has no secondary IP or prefix attached",
 				"id", eni.eniID)
 		} else {
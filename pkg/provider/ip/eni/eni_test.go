@@ -14,10 +14,12 @@
 package eni
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"testing"
 
+	"github.com/aws/amazon-vpc-resource-controller-k8s/apis/vpcresources/v1alpha1"
 	mock_ec2 "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/aws/ec2"
 	mock_api "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/aws/ec2/api"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
@@ -37,9 +39,11 @@ var (
 	eniID2 = "eni-000000000000002"
 	eniID3 = "eni-000000000000003"
 
-	subnetID   = "subnet-0000000001"
-	subnetMask = "16"
-	instanceSG = []string{"sg-1"}
+	subnetID     = "subnet-0000000001"
+	attachmentID = "eni-attach-000000000000001"
+	subnetMask   = "16"
+	instanceSG   = []string{"sg-1"}
+	nodeIDTags   = []ec2types.Tag{{Key: aws.String(config.NetworkInterfaceNodeIDKey), Value: &instanceID}}
 
 	ip1         = "192.168.1.0"
 	ip1WithMask = ip1 + "/" + subnetMask
@@ -81,6 +85,7 @@ var (
 
 	networkInterface1 = ec2types.NetworkInterface{
 		NetworkInterfaceId: &eniID2,
+		Attachment:         &ec2types.NetworkInterfaceAttachment{AttachmentId: &attachmentID},
 		PrivateIpAddresses: []ec2types.NetworkInterfacePrivateIpAddress{
 			{PrivateIpAddress: &ip1, Primary: aws.Bool(true)},
 			{PrivateIpAddress: &ip2, Primary: aws.Bool(false)},
@@ -90,6 +95,7 @@ var (
 	}
 	networkInterface2 = ec2types.NetworkInterface{
 		NetworkInterfaceId: &eniID2,
+		Attachment:         &ec2types.NetworkInterfaceAttachment{AttachmentId: &attachmentID},
 		PrivateIpAddresses: []ec2types.NetworkInterfacePrivateIpAddress{
 			{PrivateIpAddress: &ip5, Primary: aws.Bool(true)},
 			{PrivateIpAddress: &ip6, Primary: aws.Bool(false)},
@@ -97,6 +103,7 @@ var (
 	}
 	networkInterface3 = ec2types.NetworkInterface{
 		NetworkInterfaceId: &eniID3,
+		Attachment:         &ec2types.NetworkInterfaceAttachment{AttachmentId: &attachmentID},
 		PrivateIpAddresses: []ec2types.NetworkInterfacePrivateIpAddress{
 			{PrivateIpAddress: &ip1, Primary: aws.Bool(true)},
 		},
@@ -104,6 +111,7 @@ var (
 	}
 	networkInterface4 = ec2types.NetworkInterface{
 		NetworkInterfaceId: &eniID3,
+		Attachment:         &ec2types.NetworkInterfaceAttachment{AttachmentId: &attachmentID},
 		PrivateIpAddresses: []ec2types.NetworkInterfacePrivateIpAddress{
 			{PrivateIpAddress: &ip1, Primary: aws.Bool(true)},
 		},
@@ -124,9 +132,28 @@ func createENIDetails(eniID string, remainingCapacity int) *eni {
 	}
 }
 
+// createAttachedENIDetails returns the details of the ENI created and attached at the device index returned by
+// expectCreateAndAttachENI
+func createAttachedENIDetails(eniID string, remainingCapacity int) *eni {
+	eniDetails := createENIDetails(eniID, remainingCapacity)
+	eniDetails.attachmentID = attachmentID
+	eniDetails.deviceIndex = 2
+	return eniDetails
+}
+
+// expectCreateAndAttachENI sets the instance expectations for creating the given number of new ENIs
+func expectCreateAndAttachENI(mockInstance *mock_ec2.MockEC2Instance, count int) {
+	mockInstance.EXPECT().Type().Return(instanceType).Times(2)
+	mockInstance.EXPECT().GetHighestUnusedDeviceIndex().Return(int32(2), nil).Times(count)
+	mockInstance.EXPECT().InstanceID().Return(instanceID).Times(count)
+	mockInstance.EXPECT().SubnetID().Return(subnetID).Times(count)
+	mockInstance.EXPECT().CurrentInstanceSecurityGroups().Return(instanceSG).Times(count)
+}
+
 func TestNewENIManager(t *testing.T) {
-	eniManager := NewENIManager(nil)
+	eniManager := NewENIManager(nil, true)
 	assert.NotNil(t, eniManager)
+	assert.True(t, eniManager.HasTrunk())
 }
 
 func getMockManager(ctrl *gomock.Controller) (eniManager, *mock_ec2.MockEC2Instance, *mock_api.MockEC2APIHelper) {
@@ -150,14 +177,14 @@ func TestEni_InitResources(t *testing.T) {
 	mockInstance.EXPECT().InstanceID().Return(instanceID)
 	mockInstance.EXPECT().SubnetMask().Return(subnetMask).Times(3)
 
-	mockEc2APIHelper.EXPECT().GetInstanceNetworkInterface(&instanceID).Return(nwInterfaces, nil)
+	mockEc2APIHelper.EXPECT().GetInstanceNetworkInterface(gomock.Any(), &instanceID).Return(nwInterfaces, nil)
 
 	// Capacity is 4 and already present 3, so remaining capacity = 4-3=1
 	expectedENIDetails1 := createENIDetails(eniID1, 1)
 	// Capacity is 4 and already present 1, so remaining capacity = 4-1=3
 	expectedENIDetails2 := createENIDetails(eniID2, 3)
 
-	ipV4Resource, err := manager.InitResources(mockEc2APIHelper)
+	ipV4Resource, err := manager.InitResources(context.TODO(), mockEc2APIHelper)
 
 	assert.NoError(t, err)
 	// Assert all the IPs are returned
@@ -173,6 +200,40 @@ func TestEni_InitResources(t *testing.T) {
 	}, manager.resourceToENIMap))
 }
 
+// TestEni_InitResources_SkipTrunkENI tests that the trunk ENI managed by the branch ENI provider is not used for
+// assigning IPv4 resources
+func TestEni_InitResources_SkipTrunkENI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	manager, mockInstance, mockEc2APIHelper := getMockManager(ctrl)
+
+	trunkENIID := "eni-trunk"
+	trunkIP := "192.168.4.1"
+	withTrunk := append([]ec2types.InstanceNetworkInterface{}, nwInterfaces...)
+	withTrunk = append(withTrunk, ec2types.InstanceNetworkInterface{
+		NetworkInterfaceId: &trunkENIID,
+		InterfaceType:      aws.String(string(ec2types.NetworkInterfaceTypeTrunk)),
+		PrivateIpAddresses: []ec2types.InstancePrivateIpAddress{
+			{PrivateIpAddress: &trunkIP, Primary: aws.Bool(false)},
+		},
+	})
+
+	mockInstance.EXPECT().Type().Return(instanceType)
+	mockInstance.EXPECT().InstanceID().Return(instanceID)
+	mockInstance.EXPECT().SubnetMask().Return(subnetMask).Times(3)
+
+	mockEc2APIHelper.EXPECT().GetInstanceNetworkInterface(gomock.Any(), &instanceID).Return(withTrunk, nil)
+
+	ipV4Resource, err := manager.InitResources(context.TODO(), mockEc2APIHelper)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{ip1WithMask, ip2WithMask, ip3WithMask}, ipV4Resource.PrivateIPv4Addresses)
+	assert.Equal(t, []*eni{createENIDetails(eniID1, 1), createENIDetails(eniID2, 3)}, manager.attachedENIs)
+	assert.NotContains(t, manager.resourceToENIMap, trunkIP)
+	assert.True(t, manager.HasTrunk())
+}
+
 // TestEni_InitResources_Error tests that error is returned if the ec2 api fails to describe the instance
 func TestEni_InitResources_Error(t *testing.T) {
 	ctrl := gomock.NewController(t)
@@ -182,9 +243,9 @@ func TestEni_InitResources_Error(t *testing.T) {
 
 	mockInstance.EXPECT().InstanceID().Return(instanceID)
 
-	mockEc2APIHelper.EXPECT().GetInstanceNetworkInterface(&instanceID).Return(nwInterfaces, mockError)
+	mockEc2APIHelper.EXPECT().GetInstanceNetworkInterface(gomock.Any(), &instanceID).Return(nwInterfaces, mockError)
 
-	_, err := manager.InitResources(mockEc2APIHelper)
+	_, err := manager.InitResources(context.TODO(), mockEc2APIHelper)
 
 	assert.Error(t, mockError, err)
 }
@@ -198,14 +259,46 @@ func TestEni_InitResources_Unsupported_Type_Error(t *testing.T) {
 	dummyType := "dummy.large"
 	mockInstance.EXPECT().InstanceID().Return(instanceID)
 	mockInstance.EXPECT().Type().Return(dummyType)
-	mockEc2APIHelper.EXPECT().GetInstanceNetworkInterface(&instanceID).Return(nwInterfaces, nil)
+	mockEc2APIHelper.EXPECT().GetInstanceNetworkInterface(gomock.Any(), &instanceID).Return(nwInterfaces, nil)
 
-	_, err := manager.InitResources(mockEc2APIHelper)
+	_, err := manager.InitResources(context.TODO(), mockEc2APIHelper)
 
 	assert.Error(t, mockError, err)
 	assert.ErrorIs(t, err, utils.ErrNotFound)
 }
 
+// TestEni_Checkpoint_InitResourcesFromCheckpoint tests the ENIs initialized from EC2 are checkpointed and a new manager
+// initialized from the checkpoint has the same state and returns the same IPs and prefixes without calling EC2
+func TestEni_Checkpoint_InitResourcesFromCheckpoint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	manager, mockInstance, mockEc2APIHelper := getMockManager(ctrl)
+
+	mockInstance.EXPECT().Type().Return(instanceType)
+	mockInstance.EXPECT().InstanceID().Return(instanceID)
+	mockInstance.EXPECT().SubnetMask().Return(subnetMask).Times(6)
+
+	mockEc2APIHelper.EXPECT().GetInstanceNetworkInterface(gomock.Any(), &instanceID).Return(nwInterfaces, nil)
+
+	ipV4Resource, err := manager.InitResources(context.TODO(), mockEc2APIHelper)
+	assert.NoError(t, err)
+
+	checkpoint := manager.Checkpoint()
+	assert.Equal(t, []v1alpha1.ENICheckpoint{
+		{ID: eniID1, RemainingCapacity: 1, Resources: []string{ip1, prefix1, ip2}},
+		{ID: eniID2, RemainingCapacity: 3, Resources: []string{ip3}},
+	}, checkpoint)
+
+	newManager, _, _ := getMockManager(ctrl)
+	newManager.instance = mockInstance
+	restoredResource := newManager.InitResourcesFromCheckpoint(checkpoint)
+
+	assert.Equal(t, ipV4Resource, restoredResource)
+	assert.Equal(t, manager.attachedENIs, newManager.attachedENIs)
+	assert.True(t, reflect.DeepEqual(manager.resourceToENIMap, newManager.resourceToENIMap))
+}
+
 // TestEniManager_CreateIPV4Resource_TypeIPV4Address_FromSingleENI tests IPs are created using a single ENI when it has the desired
 // capacity
 func TestEniManager_CreateIPV4Resource_TypeIPV4Address_FromSingleENI(t *testing.T) {
@@ -216,11 +309,11 @@ func TestEniManager_CreateIPV4Resource_TypeIPV4Address_FromSingleENI(t *testing.
 
 	manager.attachedENIs = []*eni{createENIDetails(eniID1, 2)}
 
-	mockEc2APIHelper.EXPECT().AssignIPv4ResourcesAndWaitTillReady(eniID1, config.ResourceTypeIPv4Address, 2).Return([]string{ip1, ip2}, nil)
+	mockEc2APIHelper.EXPECT().AssignIPv4ResourcesAndWaitTillReady(gomock.Any(), eniID1, config.ResourceTypeIPv4Address, 2).Return([]string{ip1, ip2}, nil)
 	mockInstance.EXPECT().Name().Return(instanceName)
 	mockInstance.EXPECT().SubnetMask().Return(subnetMask).Times(2)
 
-	ips, err := manager.CreateIPV4Resource(2, config.ResourceTypeIPv4Address, mockEc2APIHelper, log)
+	ips, err := manager.CreateIPV4Resource(context.TODO(), 2, config.ResourceTypeIPv4Address, mockEc2APIHelper, log)
 
 	assert.NoError(t, err)
 	assert.Equal(t, []string{ip1WithMask, ip2WithMask}, ips)
@@ -237,18 +330,18 @@ func TestEniManager_CreateIPV4Resource_TypeIPV4Prefix_FromSingleENI(t *testing.T
 
 	manager.attachedENIs = []*eni{createENIDetails(eniID1, 2)}
 
-	mockEc2APIHelper.EXPECT().AssignIPv4ResourcesAndWaitTillReady(eniID1, config.ResourceTypeIPv4Prefix, 2).Return([]string{prefix1, prefix2}, nil)
+	mockEc2APIHelper.EXPECT().AssignIPv4ResourcesAndWaitTillReady(gomock.Any(), eniID1, config.ResourceTypeIPv4Prefix, 2).Return([]string{prefix1, prefix2}, nil)
 	mockInstance.EXPECT().Name().Return(instanceName)
 
-	prefixes, err := manager.CreateIPV4Resource(2, config.ResourceTypeIPv4Prefix, mockEc2APIHelper, log)
+	prefixes, err := manager.CreateIPV4Resource(context.TODO(), 2, config.ResourceTypeIPv4Prefix, mockEc2APIHelper, log)
 
 	assert.NoError(t, err)
 	assert.Equal(t, []string{prefix1, prefix2}, prefixes)
 	assert.Equal(t, 0, manager.attachedENIs[0].remainingCapacity)
 }
 
-// TestEniManager_CreateIPV4Resource_TypeIPV4Address_FromSingleENIFail test that if some ip creation fails, the ones that succeeded
-// are returned
+// TestEniManager_CreateIPV4Resource_TypeIPV4Address_FromSingleENIFail test that if the new ENI creation fails, the ips that
+// succeeded are returned and the device index is freed
 func TestEniManager_CreateIPV4Resource_TypeIPV4Address_FromSingleENIFail(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -260,21 +353,25 @@ func TestEniManager_CreateIPV4Resource_TypeIPV4Address_FromSingleENIFail(t *test
 
 	mockInstance.EXPECT().Name().Return(instanceName)
 	mockInstance.EXPECT().SubnetMask().Return(subnetMask)
+	expectCreateAndAttachENI(mockInstance, 1)
 
 	// only assign 1 since remaining capacity is 1
-	mockEc2APIHelper.EXPECT().AssignIPv4ResourcesAndWaitTillReady(eniID2, config.ResourceTypeIPv4Address, 1).Return([]string{ip6}, nil)
+	mockEc2APIHelper.EXPECT().AssignIPv4ResourcesAndWaitTillReady(gomock.Any(), eniID2, config.ResourceTypeIPv4Address, 1).Return([]string{ip6}, nil)
+	mockEc2APIHelper.EXPECT().CreateAndAttachNetworkInterface(gomock.Any(), &instanceID, &subnetID, instanceSG, nodeIDTags, aws.Int32(2),
+		&ENIDescription, nil, ipCountFor1).Return(nil, mockError)
+	mockInstance.EXPECT().FreeDeviceIndex(int32(2))
 
-	ips, err := manager.CreateIPV4Resource(2, config.ResourceTypeIPv4Address, mockEc2APIHelper, log)
+	ips, err := manager.CreateIPV4Resource(context.TODO(), 2, config.ResourceTypeIPv4Address, mockEc2APIHelper, log)
 
-	assert.Error(t, mockError, err)
+	assert.ErrorIs(t, err, mockError)
 	assert.Equal(t, []string{ip6WithMask}, ips)
 	assert.Equal(t, []*eni{existingENI}, manager.attachedENIs)
 	assert.Equal(t, map[string]*eni{ip6: existingENI}, manager.resourceToENIMap)
 	assert.Equal(t, 0, manager.attachedENIs[0].remainingCapacity)
 }
 
-// TestEniManager_CreateIPV4Resource_TypeIPV4Prefix_FromSingleENIFail test that if some prefix creation fails, the ones that
-// succeeded are returned
+// TestEniManager_CreateIPV4Resource_TypeIPV4Prefix_FromSingleENIFail test that if the new ENI creation fails, the prefixes
+// that succeeded are returned and the device index is freed
 func TestEniManager_CreateIPV4Resource_TypeIPV4Prefix_FromSingleENIFail(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -285,13 +382,17 @@ func TestEniManager_CreateIPV4Resource_TypeIPV4Prefix_FromSingleENIFail(t *testi
 	manager.attachedENIs = []*eni{existingENI}
 
 	mockInstance.EXPECT().Name().Return(instanceName)
+	expectCreateAndAttachENI(mockInstance, 1)
 
 	// only assign 1 since remaining capacity is 1
-	mockEc2APIHelper.EXPECT().AssignIPv4ResourcesAndWaitTillReady(eniID3, config.ResourceTypeIPv4Prefix, 1).Return([]string{prefix1}, nil)
+	mockEc2APIHelper.EXPECT().AssignIPv4ResourcesAndWaitTillReady(gomock.Any(), eniID3, config.ResourceTypeIPv4Prefix, 1).Return([]string{prefix1}, nil)
+	mockEc2APIHelper.EXPECT().CreateAndAttachNetworkInterface(gomock.Any(), &instanceID, &subnetID, instanceSG, nodeIDTags, aws.Int32(2),
+		&ENIDescription, nil, prefixCountFor1).Return(nil, mockError)
+	mockInstance.EXPECT().FreeDeviceIndex(int32(2))
 
-	prefixes, err := manager.CreateIPV4Resource(2, config.ResourceTypeIPv4Prefix, mockEc2APIHelper, log)
+	prefixes, err := manager.CreateIPV4Resource(context.TODO(), 2, config.ResourceTypeIPv4Prefix, mockEc2APIHelper, log)
 
-	assert.Error(t, mockError, err)
+	assert.ErrorIs(t, err, mockError)
 	assert.Equal(t, []string{prefix1}, prefixes)
 	assert.Equal(t, []*eni{existingENI}, manager.attachedENIs)
 	assert.Equal(t, map[string]*eni{prefix1: existingENI}, manager.resourceToENIMap)
@@ -307,158 +408,87 @@ func TestEniManager_CreateIPV4Resource_TypeIPV4Address_AssignEmpty(t *testing.T)
 	manager, mockInstance, mockEc2APIHelper := getMockManager(ctrl)
 
 	existingENI := createENIDetails(eniID2, 1)
-	manager.attachedENIs = []*eni{existingENI}
+	manager.attachedENIs = []*eni{existingENI, createENIDetails(eniID1, 0), createENIDetails(eniID3, 0)}
 
 	mockInstance.EXPECT().Name().Return(instanceName)
+	mockInstance.EXPECT().Type().Return(instanceType).Times(2)
 
 	// only assign 1 since remaining capacity is 1, note it returns nil assigned and nil error
-	mockEc2APIHelper.EXPECT().AssignIPv4ResourcesAndWaitTillReady(eniID2, config.ResourceTypeIPv4Address, 1).Return(nil, nil)
+	mockEc2APIHelper.EXPECT().AssignIPv4ResourcesAndWaitTillReady(gomock.Any(), eniID2, config.ResourceTypeIPv4Address, 1).Return(nil, nil)
 
 	// should return error about failing to assign required resources
-	ips, err := manager.CreateIPV4Resource(2, config.ResourceTypeIPv4Address, mockEc2APIHelper, log)
+	ips, err := manager.CreateIPV4Resource(context.TODO(), 2, config.ResourceTypeIPv4Address, mockEc2APIHelper, log)
 
-	assert.Error(t, mockError, err)
+	assert.Error(t, err)
 	assert.Nil(t, ips)
-	assert.Equal(t, []*eni{existingENI}, manager.attachedENIs)
 	assert.Equal(t, map[string]*eni{}, manager.resourceToENIMap)
 	assert.Equal(t, 1, manager.attachedENIs[0].remainingCapacity)
 }
 
-// TODO: Uncomment once multi-ENI is supported for Windows
-//// TestEniManager_CreateIPV4Resource_TypeIPV4Address_FromMultipleENI tests IPs are assigned from multiple different ENIs
-//func TestEniManager_CreateIPV4Resource_TypeIPV4Address_FromMultipleENI(t *testing.T) {
-//	ctrl := gomock.NewController(t)
-//	defer ctrl.Finish()
-//
-//	manager, mockInstance, mockEc2APIHelper := getMockManager(ctrl)
-//
-//	manager.attachedENIs = []*eni{createENIDetails(eniID1, 1),
-//		createENIDetails(eniID2, 3)}
-//
-//	gomock.InOrder(
-//		mockEc2APIHelper.EXPECT().AssignIPv4ResourcesAndWaitTillReady(eniID1, config.ResourceTypeIPv4Address, 1).Return([]string{ip1}, nil),
-//		mockEc2APIHelper.EXPECT().AssignIPv4ResourcesAndWaitTillReady(eniID2, config.ResourceTypeIPv4Address, 1).Return([]string{ip2}, nil),
-//	)
-//
-//	mockInstance.EXPECT().Type().Return(instanceType).Times(2)
-//	mockInstance.EXPECT().Name().Return(instanceName)
-//	mockInstance.EXPECT().SubnetMask().Return(subnetMask).Times(2)
-//
-//	ips, err := manager.CreateIPV4Resource(2, config.ResourceTypeIPv4Address, mockEc2APIHelper, log)
-//
-//	assert.NoError(t, err)
-//	// Assert returned Ips are as expected
-//	assert.Equal(t, []string{ip1WithMask, ip2WithMask}, ips)
-//	// Assert the remaining capacity for the eni manager is reduced
-//	assert.Equal(t, 0, manager.attachedENIs[0].remainingCapacity)
-//	assert.Equal(t, 2, manager.attachedENIs[1].remainingCapacity)
-//	// Assert the mapping from IP to ENI  is created
-//	assert.Equal(t, manager.attachedENIs[0], manager.resourceToENIMap[ip1])
-//	assert.Equal(t, manager.attachedENIs[1], manager.resourceToENIMap[ip2])
-//}
-//
-//// TestEniManager_CreateIPV4Resource_TypeIPV4Prefix_FromMultipleENI tests prefixes are assigned from multiple different ENIs
-//func TestEniManager_CreateIPV4Resource_TypeIPV4Prefix_FromMultipleENI(t *testing.T) {
-//	ctrl := gomock.NewController(t)
-//	defer ctrl.Finish()
-//
-//	manager, mockInstance, mockEc2APIHelper := getMockManager(ctrl)
-//
-//	manager.attachedENIs = []*eni{createENIDetails(eniID1, 1),
-//		createENIDetails(eniID2, 3)}
-//
-//	gomock.InOrder(
-//		mockEc2APIHelper.EXPECT().AssignIPv4ResourcesAndWaitTillReady(eniID1, config.ResourceTypeIPv4Prefix, 1).Return([]string{prefix1}, nil),
-//		mockEc2APIHelper.EXPECT().AssignIPv4ResourcesAndWaitTillReady(eniID2, config.ResourceTypeIPv4Prefix, 1).Return([]string{prefix2}, nil),
-//	)
-//
-//	mockInstance.EXPECT().Type().Return(instanceType).Times(2)
-//	mockInstance.EXPECT().Name().Return(instanceName)
-//
-//	prefixes, err := manager.CreateIPV4Resource(2, config.ResourceTypeIPv4Prefix, mockEc2APIHelper, log)
-//
-//	assert.NoError(t, err)
-//	// Assert returned prefixes are as expected
-//	assert.Equal(t, []string{prefix1, prefix2}, prefixes)
-//	// Assert the remaining capacity for the eni manager is reduced
-//	assert.Equal(t, 0, manager.attachedENIs[0].remainingCapacity)
-//	assert.Equal(t, 2, manager.attachedENIs[1].remainingCapacity)
-//	// Assert the mapping from resource to ENI  is created
-//	assert.Equal(t, manager.attachedENIs[0], manager.resourceToENIMap[prefix1])
-//	assert.Equal(t, manager.attachedENIs[1], manager.resourceToENIMap[prefix2])
-//}
-//
-//// TestEniManager_CreateIPV4Resource_TypeIPV4Address_FromNewENI tests if existing ENIs cannot supply new IP, IPs are allocated from a new
-//// ENI
-//func TestEniManager_CreateIPV4Resource_TypeIPV4Address_FromNewENI(t *testing.T) {
-//	ctrl := gomock.NewController(t)
-//	defer ctrl.Finish()
-//
-//	manager, mockInstance, mockEc2APIHelper := getMockManager(ctrl)
-//
-//	existingENI := createENIDetails(eniID1, 0)
-//	manager.attachedENIs = []*eni{existingENI}
-//
-//	mockInstance.EXPECT().Name().Return(instanceName)
-//	mockInstance.EXPECT().Type().Return(instanceType).Times(2)
-//	mockInstance.EXPECT().GetHighestUnusedDeviceIndex().Return(int64(3), nil).Times(2)
-//	mockInstance.EXPECT().InstanceID().Return(instanceID).Times(2)
-//	mockInstance.EXPECT().SubnetID().Return(subnetID).Times(2)
-//	mockInstance.EXPECT().SubnetMask().Return(subnetMask).Times(4)
-//	mockInstance.EXPECT().CurrentInstanceSecurityGroups().Return(instanceSG).Times(2)
-//
-//	gomock.InOrder(
-//		mockEc2APIHelper.EXPECT().CreateAndAttachNetworkInterface(&instanceID, &subnetID, instanceSG, nil, aws.Int64(3),
-//			&ENIDescription, nil, ipCountFor3).Return(networkInterface1, nil),
-//		mockEc2APIHelper.EXPECT().CreateAndAttachNetworkInterface(&instanceID, &subnetID, instanceSG, nil, aws.Int64(3),
-//			&ENIDescription, nil, ipCountFor1).Return(networkInterface2, nil),
-//	)
-//
-//	ips, err := manager.CreateIPV4Resource(4, config.ResourceTypeIPv4Address, mockEc2APIHelper, log)
-//
-//	expectedNewENI1 := createENIDetails(*networkInterface1.NetworkInterfaceId, 0)
-//	expectedNewENI2 := createENIDetails(*networkInterface2.NetworkInterfaceId, 2)
-//
-//	assert.NoError(t, err)
-//	assert.Equal(t, []string{ip2WithMask, ip3WithMask, ip4WithMask, ip6WithMask}, ips)
-//	assert.Equal(t, []*eni{existingENI, expectedNewENI1, expectedNewENI2}, manager.attachedENIs)
-//	assert.Equal(t, map[string]*eni{ip2: expectedNewENI1, ip3: expectedNewENI1, ip4: expectedNewENI1, ip6: expectedNewENI2}, manager.resourceToENIMap)
-//}
-//
-//// TestEniManager_CreateIPV4Resource_TypeIPV4Prefix_FromNewENI tests if existing ENIs cannot supply new prefix, prefixes are allocated from a new
-//// ENI
-//func TestEniManager_CreateIPV4Resource_TypeIPV4Prefix_FromNewENI(t *testing.T) {
-//	ctrl := gomock.NewController(t)
-//	defer ctrl.Finish()
-//
-//	manager, mockInstance, mockEc2APIHelper := getMockManager(ctrl)
-//
-//	existingENI := createENIDetails(eniID1, 0)
-//	manager.attachedENIs = []*eni{existingENI}
-//
-//	mockInstance.EXPECT().Name().Return(instanceName)
-//	mockInstance.EXPECT().Type().Return(instanceType).Times(2)
-//	mockInstance.EXPECT().GetHighestUnusedDeviceIndex().Return(int64(3), nil).Times(1)
-//	mockInstance.EXPECT().InstanceID().Return(instanceID).Times(1)
-//	mockInstance.EXPECT().SubnetID().Return(subnetID).Times(1)
-//	mockInstance.EXPECT().CurrentInstanceSecurityGroups().Return(instanceSG).Times(1)
-//
-//	mockEc2APIHelper.EXPECT().CreateAndAttachNetworkInterface(&instanceID, &subnetID, instanceSG, nil, aws.Int64(3),
-//		&ENIDescription, nil, prefixCountFor1).Return(networkInterface3, nil)
-//
-//	prefixes, err := manager.CreateIPV4Resource(1, config.ResourceTypeIPv4Prefix, mockEc2APIHelper, log)
-//
-//	expectedNewENI3 := createENIDetails(*networkInterface3.NetworkInterfaceId, 2)
-//
-//	assert.NoError(t, err)
-//	assert.Equal(t, []string{prefix1}, prefixes)
-//	assert.Equal(t, []*eni{existingENI, expectedNewENI3}, manager.attachedENIs)
-//	assert.Equal(t, map[string]*eni{prefix1: expectedNewENI3}, manager.resourceToENIMap)
-//}
-
-// TODO: remove once multi-ENI is supported for Windows
-// TestEniManager_CreateIPV4Resource_TypeIPV4Address_NoNewENI tests if existing ENIs cannot supply new IP address, no new ENI is created
-func TestEniManager_CreateIPV4Resource_TypeIPV4Address_NoNewENI(t *testing.T) {
+// TestEniManager_CreateIPV4Resource_TypeIPV4Address_FromMultipleENI tests IPs are assigned from multiple different ENIs
+func TestEniManager_CreateIPV4Resource_TypeIPV4Address_FromMultipleENI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	manager, mockInstance, mockEc2APIHelper := getMockManager(ctrl)
+
+	manager.attachedENIs = []*eni{createENIDetails(eniID1, 1),
+		createENIDetails(eniID2, 3)}
+
+	gomock.InOrder(
+		mockEc2APIHelper.EXPECT().AssignIPv4ResourcesAndWaitTillReady(gomock.Any(), eniID1, config.ResourceTypeIPv4Address, 1).Return([]string{ip1}, nil),
+		mockEc2APIHelper.EXPECT().AssignIPv4ResourcesAndWaitTillReady(gomock.Any(), eniID2, config.ResourceTypeIPv4Address, 1).Return([]string{ip2}, nil),
+	)
+
+	mockInstance.EXPECT().Name().Return(instanceName)
+	mockInstance.EXPECT().SubnetMask().Return(subnetMask).Times(2)
+
+	ips, err := manager.CreateIPV4Resource(context.TODO(), 2, config.ResourceTypeIPv4Address, mockEc2APIHelper, log)
+
+	assert.NoError(t, err)
+	// Assert returned Ips are as expected
+	assert.Equal(t, []string{ip1WithMask, ip2WithMask}, ips)
+	// Assert the remaining capacity for the eni manager is reduced
+	assert.Equal(t, 0, manager.attachedENIs[0].remainingCapacity)
+	assert.Equal(t, 2, manager.attachedENIs[1].remainingCapacity)
+	// Assert the mapping from IP to ENI  is created
+	assert.Equal(t, manager.attachedENIs[0], manager.resourceToENIMap[ip1])
+	assert.Equal(t, manager.attachedENIs[1], manager.resourceToENIMap[ip2])
+}
+
+// TestEniManager_CreateIPV4Resource_TypeIPV4Prefix_FromMultipleENI tests prefixes are assigned from multiple different ENIs
+func TestEniManager_CreateIPV4Resource_TypeIPV4Prefix_FromMultipleENI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	manager, mockInstance, mockEc2APIHelper := getMockManager(ctrl)
+
+	manager.attachedENIs = []*eni{createENIDetails(eniID1, 1),
+		createENIDetails(eniID2, 3)}
+
+	gomock.InOrder(
+		mockEc2APIHelper.EXPECT().AssignIPv4ResourcesAndWaitTillReady(gomock.Any(), eniID1, config.ResourceTypeIPv4Prefix, 1).Return([]string{prefix1}, nil),
+		mockEc2APIHelper.EXPECT().AssignIPv4ResourcesAndWaitTillReady(gomock.Any(), eniID2, config.ResourceTypeIPv4Prefix, 1).Return([]string{prefix2}, nil),
+	)
+
+	mockInstance.EXPECT().Name().Return(instanceName)
+
+	prefixes, err := manager.CreateIPV4Resource(context.TODO(), 2, config.ResourceTypeIPv4Prefix, mockEc2APIHelper, log)
+
+	assert.NoError(t, err)
+	// Assert returned prefixes are as expected
+	assert.Equal(t, []string{prefix1, prefix2}, prefixes)
+	// Assert the remaining capacity for the eni manager is reduced
+	assert.Equal(t, 0, manager.attachedENIs[0].remainingCapacity)
+	assert.Equal(t, 2, manager.attachedENIs[1].remainingCapacity)
+	// Assert the mapping from resource to ENI  is created
+	assert.Equal(t, manager.attachedENIs[0], manager.resourceToENIMap[prefix1])
+	assert.Equal(t, manager.attachedENIs[1], manager.resourceToENIMap[prefix2])
+}
+
+// TestEniManager_CreateIPV4Resource_TypeIPV4Address_FromNewENI tests if existing ENIs cannot supply new IP, IPs are allocated from a new
+// ENI
+func TestEniManager_CreateIPV4Resource_TypeIPV4Address_FromNewENI(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
@@ -468,16 +498,30 @@ func TestEniManager_CreateIPV4Resource_TypeIPV4Address_NoNewENI(t *testing.T) {
 	manager.attachedENIs = []*eni{existingENI}
 
 	mockInstance.EXPECT().Name().Return(instanceName)
+	mockInstance.EXPECT().SubnetMask().Return(subnetMask).Times(4)
+	expectCreateAndAttachENI(mockInstance, 2)
 
-	ips, err := manager.CreateIPV4Resource(1, config.ResourceTypeIPv4Address, mockEc2APIHelper, log)
+	gomock.InOrder(
+		mockEc2APIHelper.EXPECT().CreateAndAttachNetworkInterface(gomock.Any(), &instanceID, &subnetID, instanceSG, nodeIDTags, aws.Int32(2),
+			&ENIDescription, nil, ipCountFor3).Return(&networkInterface1, nil),
+		mockEc2APIHelper.EXPECT().CreateAndAttachNetworkInterface(gomock.Any(), &instanceID, &subnetID, instanceSG, nodeIDTags, aws.Int32(2),
+			&ENIDescription, nil, ipCountFor1).Return(&networkInterface2, nil),
+	)
 
-	assert.Error(t, err)
-	assert.Nil(t, ips)
+	ips, err := manager.CreateIPV4Resource(context.TODO(), 4, config.ResourceTypeIPv4Address, mockEc2APIHelper, log)
+
+	expectedNewENI1 := createAttachedENIDetails(*networkInterface1.NetworkInterfaceId, 0)
+	expectedNewENI2 := createAttachedENIDetails(*networkInterface2.NetworkInterfaceId, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{ip2WithMask, ip3WithMask, ip4WithMask, ip6WithMask}, ips)
+	assert.Equal(t, []*eni{existingENI, expectedNewENI1, expectedNewENI2}, manager.attachedENIs)
+	assert.Equal(t, map[string]*eni{ip2: expectedNewENI1, ip3: expectedNewENI1, ip4: expectedNewENI1, ip6: expectedNewENI2}, manager.resourceToENIMap)
 }
 
-// TODO: remove once multi-ENI is supported for Windows
-// TestEniManager_CreateIPV4Resource_TypeIPV4Prefix_NoNewENI tests if existing ENIs cannot supply new prefix, no new ENI is created
-func TestEniManager_CreateIPV4Resource_TypeIPV4Prefix_NoNewENI(t *testing.T) {
+// TestEniManager_CreateIPV4Resource_TypeIPV4Prefix_FromNewENI tests if existing ENIs cannot supply new prefix, prefixes are allocated from a new
+// ENI
+func TestEniManager_CreateIPV4Resource_TypeIPV4Prefix_FromNewENI(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
@@ -487,83 +531,143 @@ func TestEniManager_CreateIPV4Resource_TypeIPV4Prefix_NoNewENI(t *testing.T) {
 	manager.attachedENIs = []*eni{existingENI}
 
 	mockInstance.EXPECT().Name().Return(instanceName)
+	expectCreateAndAttachENI(mockInstance, 1)
+
+	mockEc2APIHelper.EXPECT().CreateAndAttachNetworkInterface(gomock.Any(), &instanceID, &subnetID, instanceSG, nodeIDTags, aws.Int32(2),
+		&ENIDescription, nil, prefixCountFor1).Return(&networkInterface3, nil)
+
+	prefixes, err := manager.CreateIPV4Resource(context.TODO(), 1, config.ResourceTypeIPv4Prefix, mockEc2APIHelper, log)
+
+	expectedNewENI3 := createAttachedENIDetails(*networkInterface3.NetworkInterfaceId, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{prefix1}, prefixes)
+	assert.Equal(t, []*eni{existingENI, expectedNewENI3}, manager.attachedENIs)
+	assert.Equal(t, map[string]*eni{prefix1: expectedNewENI3}, manager.resourceToENIMap)
+}
+
+// TestEniManager_CreateIPV4Resource_TypeIPV4Address_ENILimit tests if existing ENIs cannot supply new IP address and the
+// instance is at the ENI limit, no new ENI is created
+func TestEniManager_CreateIPV4Resource_TypeIPV4Address_ENILimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	manager, mockInstance, mockEc2APIHelper := getMockManager(ctrl)
+
+	manager.attachedENIs = []*eni{createENIDetails(eniID1, 0), createENIDetails(eniID2, 0), createENIDetails(eniID3, 0)}
+
+	mockInstance.EXPECT().Name().Return(instanceName)
+	mockInstance.EXPECT().Type().Return(instanceType).Times(2)
+
+	ips, err := manager.CreateIPV4Resource(context.TODO(), 1, config.ResourceTypeIPv4Address, mockEc2APIHelper, log)
+
+	assert.Error(t, err)
+	assert.Nil(t, ips)
+}
+
+// TestEniManager_CreateIPV4Resource_TypeIPV4Address_ENILimitWithTrunk tests no new ENI is created when the instance is
+// at the ENI limit with the trunk interface taking up one of the interfaces
+func TestEniManager_CreateIPV4Resource_TypeIPV4Address_ENILimitWithTrunk(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	manager, mockInstance, mockEc2APIHelper := getMockManager(ctrl)
+	manager.hasTrunk = true
+
+	manager.attachedENIs = []*eni{createENIDetails(eniID1, 0), createENIDetails(eniID2, 0)}
+
+	mockInstance.EXPECT().Name().Return(instanceName)
+	mockInstance.EXPECT().Type().Return(instanceType).Times(2)
 
-	prefixes, err := manager.CreateIPV4Resource(1, config.ResourceTypeIPv4Prefix, mockEc2APIHelper, log)
+	ips, err := manager.CreateIPV4Resource(context.TODO(), 1, config.ResourceTypeIPv4Address, mockEc2APIHelper, log)
+
+	assert.Error(t, err)
+	assert.Nil(t, ips)
+}
+
+// TestEniManager_CreateIPV4Resource_TypeIPV4Prefix_ENILimit tests if existing ENIs cannot supply new prefix and the
+// instance is at the ENI limit, no new ENI is created
+func TestEniManager_CreateIPV4Resource_TypeIPV4Prefix_ENILimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	manager, mockInstance, mockEc2APIHelper := getMockManager(ctrl)
+
+	manager.attachedENIs = []*eni{createENIDetails(eniID1, 0), createENIDetails(eniID2, 0), createENIDetails(eniID3, 0)}
+
+	mockInstance.EXPECT().Name().Return(instanceName)
+	mockInstance.EXPECT().Type().Return(instanceType).Times(2)
+
+	prefixes, err := manager.CreateIPV4Resource(context.TODO(), 1, config.ResourceTypeIPv4Prefix, mockEc2APIHelper, log)
 
 	assert.Error(t, err)
 	assert.Nil(t, prefixes)
 }
 
-// TODO: Uncomment once multi-ENI is supported for Windows
-//// TestEniManager_CreateIPV4Resource_TypeIPV4Address_InBetweenENIFail test that if some of the IP creation fails, the ones that succeeded
-//// are returned
-//func TestEniManager_CreateIPV4Resource_TypeIPV4Address_InBetweenENIFail(t *testing.T) {
-//	ctrl := gomock.NewController(t)
-//	defer ctrl.Finish()
-//
-//	manager, mockInstance, mockEc2APIHelper := getMockManager(ctrl)
-//
-//	existingENI := createENIDetails(eniID1, 0)
-//	manager.attachedENIs = []*eni{existingENI}
-//
-//	mockInstance.EXPECT().Name().Return(instanceName)
-//	mockInstance.EXPECT().Type().Return(instanceType).Times(2)
-//	mockInstance.EXPECT().GetHighestUnusedDeviceIndex().Return(int64(3), nil).Times(2)
-//	mockInstance.EXPECT().InstanceID().Return(instanceID).Times(2)
-//	mockInstance.EXPECT().SubnetID().Return(subnetID).Times(2)
-//	mockInstance.EXPECT().CurrentInstanceSecurityGroups().Return(instanceSG).Times(2)
-//
-//	gomock.InOrder(
-//		mockEc2APIHelper.EXPECT().CreateAndAttachNetworkInterface(&instanceID, &subnetID, instanceSG, nil, aws.Int64(3),
-//			&ENIDescription, nil, ipCountFor3).Return(networkInterface1, nil),
-//		mockEc2APIHelper.EXPECT().CreateAndAttachNetworkInterface(&instanceID, &subnetID, instanceSG, nil, aws.Int64(3),
-//			&ENIDescription, nil, ipCountFor1).Return(nil, mockError),
-//	)
-//
-//	ips, err := manager.CreateIPV4Resource(4, config.ResourceTypeIPv4Address, mockEc2APIHelper, log)
-//
-//	expectedNewENI1 := createENIDetails(*networkInterface1.NetworkInterfaceId, 0)
-//
-//	assert.Error(t, mockError, err)
-//	assert.Equal(t, []string{ip2, ip3, ip4}, ips)
-//	assert.Equal(t, []*eni{existingENI, expectedNewENI1}, manager.attachedENIs)
-//	assert.Equal(t, map[string]*eni{ip2: expectedNewENI1, ip3: expectedNewENI1, ip4: expectedNewENI1}, manager.resourceToENIMap)
-//}
-//
-//// TestEniManager_CreateIPV4Resource_TypeIPV4Prefix_InBetweenENIFail test that if some prefix creation fails, the ones that succeeded
-//// are returned
-//func TestEniManager_CreateIPV4Resource_TypeIPV4Prefix_InBetweenENIFail(t *testing.T) {
-//	ctrl := gomock.NewController(t)
-//	defer ctrl.Finish()
-//
-//	manager, mockInstance, mockEc2APIHelper := getMockManager(ctrl)
-//
-//	existingENI := createENIDetails(eniID1, 0)
-//	manager.attachedENIs = []*eni{existingENI}
-//
-//	mockInstance.EXPECT().Name().Return(instanceName)
-//	mockInstance.EXPECT().Type().Return(instanceType).Times(2)
-//	mockInstance.EXPECT().GetHighestUnusedDeviceIndex().Return(int64(3), nil).Times(2)
-//	mockInstance.EXPECT().InstanceID().Return(instanceID).Times(2)
-//	mockInstance.EXPECT().SubnetID().Return(subnetID).Times(2)
-//	mockInstance.EXPECT().CurrentInstanceSecurityGroups().Return(instanceSG).Times(2)
-//
-//	gomock.InOrder(
-//		mockEc2APIHelper.EXPECT().CreateAndAttachNetworkInterface(&instanceID, &subnetID, instanceSG, nil, aws.Int64(3),
-//			&ENIDescription, nil, prefixCountFor3).Return(networkInterface4, nil),
-//		mockEc2APIHelper.EXPECT().CreateAndAttachNetworkInterface(&instanceID, &subnetID, instanceSG, nil, aws.Int64(3),
-//			&ENIDescription, nil, prefixCountFor1).Return(nil, mockError),
-//	)
-//
-//	prefixes, err := manager.CreateIPV4Resource(4, config.ResourceTypeIPv4Prefix, mockEc2APIHelper, log)
-//
-//	expectedNewENI3 := createENIDetails(*networkInterface4.NetworkInterfaceId, 0)
-//
-//	assert.Error(t, mockError, err)
-//	assert.Equal(t, []string{prefix1, prefix2, prefix3}, prefixes)
-//	assert.Equal(t, []*eni{existingENI, expectedNewENI3}, manager.attachedENIs)
-//	assert.Equal(t, map[string]*eni{prefix1: expectedNewENI3, prefix2: expectedNewENI3, prefix3: expectedNewENI3}, manager.resourceToENIMap)
-//}
+// TestEniManager_CreateIPV4Resource_TypeIPV4Address_InBetweenENIFail test that if some of the IP creation fails, the ones that succeeded
+// are returned
+func TestEniManager_CreateIPV4Resource_TypeIPV4Address_InBetweenENIFail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	manager, mockInstance, mockEc2APIHelper := getMockManager(ctrl)
+
+	existingENI := createENIDetails(eniID1, 0)
+	manager.attachedENIs = []*eni{existingENI}
+
+	mockInstance.EXPECT().Name().Return(instanceName)
+	mockInstance.EXPECT().SubnetMask().Return(subnetMask).Times(3)
+	expectCreateAndAttachENI(mockInstance, 2)
+
+	gomock.InOrder(
+		mockEc2APIHelper.EXPECT().CreateAndAttachNetworkInterface(gomock.Any(), &instanceID, &subnetID, instanceSG, nodeIDTags, aws.Int32(2),
+			&ENIDescription, nil, ipCountFor3).Return(&networkInterface1, nil),
+		mockEc2APIHelper.EXPECT().CreateAndAttachNetworkInterface(gomock.Any(), &instanceID, &subnetID, instanceSG, nodeIDTags, aws.Int32(2),
+			&ENIDescription, nil, ipCountFor1).Return(nil, mockError),
+	)
+	mockInstance.EXPECT().FreeDeviceIndex(int32(2))
+
+	ips, err := manager.CreateIPV4Resource(context.TODO(), 4, config.ResourceTypeIPv4Address, mockEc2APIHelper, log)
+
+	expectedNewENI1 := createAttachedENIDetails(*networkInterface1.NetworkInterfaceId, 0)
+
+	assert.ErrorIs(t, err, mockError)
+	assert.Equal(t, []string{ip2WithMask, ip3WithMask, ip4WithMask}, ips)
+	assert.Equal(t, []*eni{existingENI, expectedNewENI1}, manager.attachedENIs)
+	assert.Equal(t, map[string]*eni{ip2: expectedNewENI1, ip3: expectedNewENI1, ip4: expectedNewENI1}, manager.resourceToENIMap)
+}
+
+// TestEniManager_CreateIPV4Resource_TypeIPV4Prefix_InBetweenENIFail test that if some prefix creation fails, the ones that succeeded
+// are returned
+func TestEniManager_CreateIPV4Resource_TypeIPV4Prefix_InBetweenENIFail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	manager, mockInstance, mockEc2APIHelper := getMockManager(ctrl)
+
+	existingENI := createENIDetails(eniID1, 0)
+	manager.attachedENIs = []*eni{existingENI}
+
+	mockInstance.EXPECT().Name().Return(instanceName)
+	expectCreateAndAttachENI(mockInstance, 2)
+
+	gomock.InOrder(
+		mockEc2APIHelper.EXPECT().CreateAndAttachNetworkInterface(gomock.Any(), &instanceID, &subnetID, instanceSG, nodeIDTags, aws.Int32(2),
+			&ENIDescription, nil, prefixCountFor3).Return(&networkInterface4, nil),
+		mockEc2APIHelper.EXPECT().CreateAndAttachNetworkInterface(gomock.Any(), &instanceID, &subnetID, instanceSG, nodeIDTags, aws.Int32(2),
+			&ENIDescription, nil, prefixCountFor1).Return(nil, mockError),
+	)
+	mockInstance.EXPECT().FreeDeviceIndex(int32(2))
+
+	prefixes, err := manager.CreateIPV4Resource(context.TODO(), 4, config.ResourceTypeIPv4Prefix, mockEc2APIHelper, log)
+
+	expectedNewENI3 := createAttachedENIDetails(*networkInterface4.NetworkInterfaceId, 0)
+
+	assert.ErrorIs(t, err, mockError)
+	assert.Equal(t, []string{prefix1, prefix2, prefix3}, prefixes)
+	assert.Equal(t, []*eni{existingENI, expectedNewENI3}, manager.attachedENIs)
+	assert.Equal(t, map[string]*eni{prefix1: expectedNewENI3, prefix2: expectedNewENI3, prefix3: expectedNewENI3}, manager.resourceToENIMap)
+}
 
 // TestEniManager_DeleteIPV4Resource_TypeIPV4Address tests ips are un assigned and network interface without any secondary IP is deleted
 func TestEniManager_DeleteIPV4Resource_TypeIPV4Address(t *testing.T) {
@@ -582,12 +686,12 @@ func TestEniManager_DeleteIPV4Resource_TypeIPV4Address(t *testing.T) {
 	mockInstance.EXPECT().Type().Return(instanceType).Times(1)
 	mockInstance.EXPECT().PrimaryNetworkInterfaceID().Return(eniID1)
 	// Unassign the IPs from interface 1 and 2
-	mockEc2APIHelper.EXPECT().UnassignIPv4Resources(eniID1, config.ResourceTypeIPv4Address, []string{ip1}).Return(nil)
-	mockEc2APIHelper.EXPECT().UnassignIPv4Resources(eniID2, config.ResourceTypeIPv4Address, []string{ip3, ip4}).Return(nil)
+	mockEc2APIHelper.EXPECT().UnassignIPv4Resources(gomock.Any(), eniID1, config.ResourceTypeIPv4Address, []string{ip1}).Return(nil)
+	mockEc2APIHelper.EXPECT().UnassignIPv4Resources(gomock.Any(), eniID2, config.ResourceTypeIPv4Address, []string{ip3, ip4}).Return(nil)
 	// Delete the network interface 2 as it has no more secondary IP left
-	mockEc2APIHelper.EXPECT().DeleteNetworkInterface(&eniID2).Return(nil)
+	mockEc2APIHelper.EXPECT().DeleteNetworkInterface(gomock.Any(), &eniID2).Return(nil)
 
-	failedToDelete, err := manager.DeleteIPV4Resource([]string{ip3, ip1, ip4}, config.ResourceTypeIPv4Address, mockEc2APIHelper, log)
+	failedToDelete, err := manager.DeleteIPV4Resource(context.TODO(), []string{ip3, ip1, ip4}, config.ResourceTypeIPv4Address, mockEc2APIHelper, log)
 
 	assert.NoError(t, err)
 	assert.Empty(t, failedToDelete)
@@ -596,6 +700,35 @@ func TestEniManager_DeleteIPV4Resource_TypeIPV4Address(t *testing.T) {
 	assert.NotContains(t, manager.resourceToENIMap, ip3)
 }
 
+// TestEniManager_DeleteIPV4Resource_TypeIPV4Address_DetachENI tests the ENI created by the manager is detached and deleted
+// and its device index is freed when it has no secondary IP left
+func TestEniManager_DeleteIPV4Resource_TypeIPV4Address_DetachENI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	manager, mockInstance, mockEc2APIHelper := getMockManager(ctrl)
+
+	eniDetails1 := createENIDetails(eniID1, 3)
+	eniDetails2 := createAttachedENIDetails(eniID2, 2)
+
+	manager.resourceToENIMap = map[string]*eni{ip3: eniDetails2}
+	manager.attachedENIs = []*eni{eniDetails1, eniDetails2}
+
+	mockInstance.EXPECT().Name().Return(instanceName)
+	mockInstance.EXPECT().Type().Return(instanceType)
+	mockInstance.EXPECT().PrimaryNetworkInterfaceID().Return(eniID1)
+	mockEc2APIHelper.EXPECT().UnassignIPv4Resources(gomock.Any(), eniID2, config.ResourceTypeIPv4Address, []string{ip3}).Return(nil)
+	mockEc2APIHelper.EXPECT().DetachAndDeleteNetworkInterface(gomock.Any(), &attachmentID, &eniID2).Return(nil)
+	mockInstance.EXPECT().FreeDeviceIndex(int32(2))
+
+	failedToDelete, err := manager.DeleteIPV4Resource(context.TODO(), []string{ip3}, config.ResourceTypeIPv4Address, mockEc2APIHelper, log)
+
+	assert.NoError(t, err)
+	assert.Empty(t, failedToDelete)
+	assert.Equal(t, []*eni{eniDetails1}, manager.attachedENIs)
+	assert.Empty(t, manager.resourceToENIMap)
+}
+
 // TestEniManager_DeleteIPV4Resource_TypeIPV4Prefix tests prefixes are un assigned
 func TestEniManager_DeleteIPV4Resource_TypeIPV4Prefix(t *testing.T) {
 	ctrl := gomock.NewController(t)
@@ -612,9 +745,9 @@ func TestEniManager_DeleteIPV4Resource_TypeIPV4Prefix(t *testing.T) {
 	mockInstance.EXPECT().Type().Return(instanceType).Times(1)
 	mockInstance.EXPECT().PrimaryNetworkInterfaceID().Return(eniID1)
 	// Unassign the prefix from interface 1
-	mockEc2APIHelper.EXPECT().UnassignIPv4Resources(eniID1, config.ResourceTypeIPv4Prefix, []string{prefix1}).Return(nil)
+	mockEc2APIHelper.EXPECT().UnassignIPv4Resources(gomock.Any(), eniID1, config.ResourceTypeIPv4Prefix, []string{prefix1}).Return(nil)
 
-	failedToDelete, err := manager.DeleteIPV4Resource([]string{prefix1}, config.ResourceTypeIPv4Prefix, mockEc2APIHelper, log)
+	failedToDelete, err := manager.DeleteIPV4Resource(context.TODO(), []string{prefix1}, config.ResourceTypeIPv4Prefix, mockEc2APIHelper, log)
 
 	assert.NoError(t, err)
 	assert.Empty(t, failedToDelete)
@@ -639,12 +772,12 @@ func TestEniManager_DeleteIPV4Resource_TypeIPV4Address_SomeFail(t *testing.T) {
 	mockInstance.EXPECT().Type().Return(instanceType).Times(1)
 	mockInstance.EXPECT().PrimaryNetworkInterfaceID().Return(eniID1)
 	// Unassign the IPs from interface 1 and 2
-	mockEc2APIHelper.EXPECT().UnassignIPv4Resources(eniID1, config.ResourceTypeIPv4Address, []string{ip1}).Return(mockError)
-	mockEc2APIHelper.EXPECT().UnassignIPv4Resources(eniID2, config.ResourceTypeIPv4Address, []string{ip3}).Return(nil)
+	mockEc2APIHelper.EXPECT().UnassignIPv4Resources(gomock.Any(), eniID1, config.ResourceTypeIPv4Address, []string{ip1}).Return(mockError)
+	mockEc2APIHelper.EXPECT().UnassignIPv4Resources(gomock.Any(), eniID2, config.ResourceTypeIPv4Address, []string{ip3}).Return(nil)
 	// Delete the network interface 2 as it has no more secondary IP left
-	mockEc2APIHelper.EXPECT().DeleteNetworkInterface(&eniID2).Return(nil)
+	mockEc2APIHelper.EXPECT().DeleteNetworkInterface(gomock.Any(), &eniID2).Return(nil)
 
-	failedToDelete, err := manager.DeleteIPV4Resource([]string{ip1, ip3}, config.ResourceTypeIPv4Address, mockEc2APIHelper, log)
+	failedToDelete, err := manager.DeleteIPV4Resource(context.TODO(), []string{ip1, ip3}, config.ResourceTypeIPv4Address, mockEc2APIHelper, log)
 
 	assert.NotNil(t, err)
 	assert.Equal(t, []string{ip1}, failedToDelete)
@@ -669,9 +802,9 @@ func TestEniManager_DeleteIPV4Resource_TypeIPV4Prefix_SomeFail(t *testing.T) {
 	mockInstance.EXPECT().Type().Return(instanceType).Times(1)
 	mockInstance.EXPECT().PrimaryNetworkInterfaceID().Return(eniID1)
 
-	mockEc2APIHelper.EXPECT().UnassignIPv4Resources(eniID1, config.ResourceTypeIPv4Prefix, []string{prefix1}).Return(mockError)
+	mockEc2APIHelper.EXPECT().UnassignIPv4Resources(gomock.Any(), eniID1, config.ResourceTypeIPv4Prefix, []string{prefix1}).Return(mockError)
 
-	failedToDelete, err := manager.DeleteIPV4Resource([]string{prefix1}, config.ResourceTypeIPv4Prefix, mockEc2APIHelper, log)
+	failedToDelete, err := manager.DeleteIPV4Resource(context.TODO(), []string{prefix1}, config.ResourceTypeIPv4Prefix, mockEc2APIHelper, log)
 
 	assert.NotNil(t, err)
 	assert.Equal(t, []string{prefix1}, failedToDelete)
@@ -14,17 +14,22 @@
 package ip
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"net/http"
+	"slices"
 	"sync"
 
+	"github.com/aws/amazon-vpc-resource-controller-k8s/apis/vpcresources/v1alpha1"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/api"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/ec2"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/vpc"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/condition"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
 	rcHealthz "github.com/aws/amazon-vpc-resource-controller-k8s/pkg/healthz"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/lookup"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/pool"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider/ip/eni"
@@ -52,6 +57,8 @@ type ipv4Provider struct {
 	instanceProviderAndPool map[string]*ResourceProviderAndPool
 	// conditions is used to check which IP allocation mode is enabled
 	conditions condition.Conditions
+	// index records the pods the resources are assigned to for the lookup of the introspection API
+	index *lookup.Index
 	// healthz check subpath
 	checker healthz.Checker
 }
@@ -69,7 +76,8 @@ type ResourceProviderAndPool struct {
 }
 
 func NewIPv4Provider(log logr.Logger, apiWrapper api.Wrapper,
-	workerPool worker.Worker, resourceConfig config.ResourceConfig, conditions condition.Conditions) provider.ResourceProvider {
+	workerPool worker.Worker, resourceConfig config.ResourceConfig, conditions condition.Conditions,
+	index *lookup.Index) provider.ResourceProvider {
 	provider := &ipv4Provider{
 		instanceProviderAndPool: make(map[string]*ResourceProviderAndPool),
 		config:                  resourceConfig.WarmPoolConfig,
@@ -77,16 +85,28 @@ func NewIPv4Provider(log logr.Logger, apiWrapper api.Wrapper,
 		apiWrapper:              apiWrapper,
 		workerPool:              workerPool,
 		conditions:              conditions,
+		index:                   index,
 	}
 	provider.checker = provider.check()
 	return provider
 }
 
-func (p *ipv4Provider) InitResource(instance ec2.EC2Instance) error {
+func (p *ipv4Provider) InitResource(ctx context.Context, instance ec2.EC2Instance) error {
 	nodeName := instance.Name()
 
-	eniManager := eni.NewENIManager(instance)
-	ipV4Resources, err := eniManager.InitResources(p.apiWrapper.EC2API)
+	// The trunk interface is attached to Windows nodes when security groups for pods is enabled
+	eniManager := eni.NewENIManager(instance, p.conditions.IsWindowsSecurityGroupsForPodsEnabled())
+	// Initialize the ENIs from the checkpoint of the previous controller if present, the pool is verified against EC2
+	// on its first reconcile
+	poolCheckpoint := pool.GetIPv4PoolCheckpoint(p.log, p.apiWrapper, nodeName, instance.InstanceID(),
+		config.ResourceNameIPAddress)
+	var ipV4Resources *eni.IPv4Resource
+	var err error
+	if poolCheckpoint != nil {
+		ipV4Resources = eniManager.InitResourcesFromCheckpoint(poolCheckpoint.ENIs)
+	} else {
+		ipV4Resources, err = eniManager.InitResources(ctx, p.apiWrapper.EC2API)
+	}
 	if err != nil || ipV4Resources == nil {
 		if errors.Is(err, utils.ErrNotFound) {
 			msg := fmt.Sprintf("The instance type %s is not supported for Windows", instance.Type())
@@ -151,13 +171,14 @@ func (p *ipv4Provider) InitResource(instance ec2.EC2Instance) error {
 	}
 
 	// Expected node capacity based on instance type in secondary IP mode
-	nodeCapacity := getCapacity(instance.Type(), instance.Os())
+	nodeCapacity := getCapacity(instance.Type(), eniManager.HasTrunk())
 
 	isPDEnabled := p.conditions.IsWindowsPrefixDelegationEnabled()
-	p.config = pool.GetWinWarmPoolConfig(p.log, p.apiWrapper, isPDEnabled)
+	// The node's warm pool config is kept local since p.config is shared by the concurrent node initializations
+	warmPoolConfig := pool.GetNodeWarmPoolConfig(p.log, p.apiWrapper, instance.Name(), isPDEnabled)
 
 	// Set warm pool config to empty config if PD is enabled
-	secondaryIPWPConfig := p.config
+	secondaryIPWPConfig := warmPoolConfig
 	if isPDEnabled {
 		secondaryIPWPConfig = &config.WarmPoolConfig{}
 	} else {
@@ -170,15 +191,22 @@ func (p *ipv4Provider) InitResource(instance ec2.EC2Instance) error {
 		}
 	}
 
-	resourcePool := pool.NewResourcePool(p.log.WithName("secondary ipv4 address resource pool").
-		WithValues("node name", instance.Name()), secondaryIPWPConfig, podToResourceMap,
-		warmResources, instance.Name(), nodeCapacity, false)
+	poolLog := p.log.WithName("secondary ipv4 address resource pool").WithValues("node name", instance.Name())
+	var resourcePool pool.Pool
+	if poolCheckpoint != nil {
+		resourcePool = pool.NewResourcePoolFromCheckpoint(poolLog, secondaryIPWPConfig, podToResourceMap, warmResources,
+			pool.GetCheckpointCoolingResources(*poolCheckpoint, podToResourceMap), instance.Name(), nodeCapacity, false,
+			p.index)
+	} else {
+		resourcePool = pool.NewResourcePool(poolLog, secondaryIPWPConfig, podToResourceMap, warmResources, instance.Name(),
+			nodeCapacity, false, p.index)
+	}
 
 	p.putInstanceProviderAndPool(nodeName, resourcePool, eniManager, nodeCapacity, isPDEnabled)
 
 	p.log.Info("initialized the resource provider for secondary ipv4 address",
 		"capacity", nodeCapacity, "node name", nodeName, "instance type",
-		instance.Type(), "instance ID", instance.InstanceID())
+		instance.Type(), "instance ID", instance.InstanceID(), "from checkpoint", poolCheckpoint != nil)
 
 	// Reconcile pool after starting up and submit the async job
 	job := resourcePool.ReconcilePool()
@@ -194,12 +222,13 @@ func (p *ipv4Provider) InitResource(instance ec2.EC2Instance) error {
 func (p *ipv4Provider) DeInitResource(instance ec2.EC2Instance) error {
 	nodeName := instance.Name()
 	p.deleteInstanceProviderAndPool(nodeName)
+	p.index.RemoveNode(nodeName)
 
 	return nil
 }
 
 // UpdateResourceCapacity updates the resource capacity based on the type of instance
-func (p *ipv4Provider) UpdateResourceCapacity(instance ec2.EC2Instance) error {
+func (p *ipv4Provider) UpdateResourceCapacity(ctx context.Context, instance ec2.EC2Instance) error {
 	resourceProviderAndPool, isPresent := p.getInstanceProviderAndPool(instance.Name())
 	if !isPresent {
 		p.log.Error(utils.ErrNotFound, utils.ErrMsgProviderAndPoolNotFound, "node name", instance.Name())
@@ -241,10 +270,10 @@ func (p *ipv4Provider) UpdateResourceCapacity(instance ec2.EC2Instance) error {
 
 	resourceProviderAndPool.isPrevPDEnabled = false
 
-	p.config = pool.GetWinWarmPoolConfig(p.log, p.apiWrapper, isCurrPDEnabled && isNitroInstance)
+	warmPoolConfig := pool.GetNodeWarmPoolConfig(p.log, p.apiWrapper, instance.Name(), isCurrPDEnabled && isNitroInstance)
 
 	// Set the secondary IP provider pool state to active
-	job := resourceProviderAndPool.resourcePool.SetToActive(p.config)
+	job := resourceProviderAndPool.resourcePool.SetToActive(warmPoolConfig)
 	if job.Operations != worker.OperationReconcileNotRequired {
 		p.SubmitAsyncJob(job)
 	}
@@ -289,9 +318,14 @@ func (p *ipv4Provider) SubmitAsyncJob(job interface{}) {
 	p.workerPool.SubmitJob(job)
 }
 
+// SubmitAsyncJobWithContext submits the job to the worker pool, the job continues the trace of the context
+func (p *ipv4Provider) SubmitAsyncJobWithContext(ctx context.Context, job interface{}) {
+	p.workerPool.SubmitJobWithContext(ctx, job)
+}
+
 // ProcessAsyncJob processes the job, the function should be called using the worker pool in order to be processed
 // asynchronously
-func (p *ipv4Provider) ProcessAsyncJob(job interface{}) (ctrl.Result, error) {
+func (p *ipv4Provider) ProcessAsyncJob(ctx context.Context, job interface{}) (ctrl.Result, error) {
 	warmPoolJob, isValid := job.(*worker.WarmPoolJob)
 	if !isValid {
 		return ctrl.Result{}, fmt.Errorf("invalid job type")
@@ -299,11 +333,11 @@ func (p *ipv4Provider) ProcessAsyncJob(job interface{}) (ctrl.Result, error) {
 
 	switch warmPoolJob.Operations {
 	case worker.OperationCreate:
-		p.CreatePrivateIPv4AndUpdatePool(warmPoolJob)
+		p.CreatePrivateIPv4AndUpdatePool(ctx, warmPoolJob)
 	case worker.OperationDeleted:
-		p.DeletePrivateIPv4AndUpdatePool(warmPoolJob)
+		p.DeletePrivateIPv4AndUpdatePool(ctx, warmPoolJob)
 	case worker.OperationReSyncPool:
-		p.ReSyncPool(warmPoolJob)
+		p.ReSyncPool(ctx, warmPoolJob)
 	case worker.OperationProcessDeleteQueue:
 		return p.ProcessDeleteQueue(warmPoolJob)
 	}
@@ -313,14 +347,14 @@ func (p *ipv4Provider) ProcessAsyncJob(job interface{}) (ctrl.Result, error) {
 
 // CreatePrivateIPv4AndUpdatePool executes the Create IPv4 workflow by assigning the desired number of IPv4 address
 // provided in the warm pool job
-func (p *ipv4Provider) CreatePrivateIPv4AndUpdatePool(job *worker.WarmPoolJob) {
+func (p *ipv4Provider) CreatePrivateIPv4AndUpdatePool(ctx context.Context, job *worker.WarmPoolJob) {
 	instanceResource, found := p.getInstanceProviderAndPool(job.NodeName)
 	if !found {
 		p.log.Error(utils.ErrNotFound, utils.ErrMsgProviderAndPoolNotFound, "node name", job.NodeName)
 		return
 	}
 	didSucceed := true
-	ips, err := instanceResource.eniManager.CreateIPV4Resource(job.ResourceCount, config.ResourceTypeIPv4Address, p.apiWrapper.EC2API, p.log)
+	ips, err := instanceResource.eniManager.CreateIPV4Resource(ctx, job.ResourceCount, config.ResourceTypeIPv4Address, p.apiWrapper.EC2API, p.log)
 	if err != nil {
 		p.log.Error(err, "failed to create all/some of the IPv4 addresses", "created ips", ips)
 		didSucceed = false
@@ -329,14 +363,14 @@ func (p *ipv4Provider) CreatePrivateIPv4AndUpdatePool(job *worker.WarmPoolJob) {
 	p.updatePoolAndReconcileIfRequired(instanceResource.resourcePool, job, didSucceed)
 }
 
-func (p *ipv4Provider) ReSyncPool(job *worker.WarmPoolJob) {
+func (p *ipv4Provider) ReSyncPool(ctx context.Context, job *worker.WarmPoolJob) {
 	providerAndPool, found := p.instanceProviderAndPool[job.NodeName]
 	if !found {
 		p.log.Error(utils.ErrNotFound, "node is not initialized", "node name", job.NodeName)
 		return
 	}
 
-	ipV4Resources, err := providerAndPool.eniManager.InitResources(p.apiWrapper.EC2API)
+	ipV4Resources, err := providerAndPool.eniManager.InitResources(ctx, p.apiWrapper.EC2API)
 	if err != nil || ipV4Resources == nil {
 		p.log.Error(err, "failed to get init resources for the node", "node name", job.NodeName)
 		return
@@ -346,14 +380,14 @@ func (p *ipv4Provider) ReSyncPool(job *worker.WarmPoolJob) {
 }
 
 // DeletePrivateIPv4AndUpdatePool executes the Delete IPv4 workflow for the list of IPs provided in the warm pool job
-func (p *ipv4Provider) DeletePrivateIPv4AndUpdatePool(job *worker.WarmPoolJob) {
+func (p *ipv4Provider) DeletePrivateIPv4AndUpdatePool(ctx context.Context, job *worker.WarmPoolJob) {
 	instanceResource, found := p.getInstanceProviderAndPool(job.NodeName)
 	if !found {
 		p.log.Error(utils.ErrNotFound, utils.ErrMsgProviderAndPoolNotFound, "node name", job.NodeName)
 		return
 	}
 	didSucceed := true
-	failedIPs, err := instanceResource.eniManager.DeleteIPV4Resource(job.Resources, config.ResourceTypeIPv4Address, p.apiWrapper.EC2API, p.log)
+	failedIPs, err := instanceResource.eniManager.DeleteIPV4Resource(ctx, job.Resources, config.ResourceTypeIPv4Address, p.apiWrapper.EC2API, p.log)
 	if err != nil {
 		p.log.Error(err, "failed to delete all/some of the IPv4 addresses", "failed ips", failedIPs)
 		didSucceed = false
@@ -408,21 +442,18 @@ func (p *ipv4Provider) deleteInstanceProviderAndPool(nodeName string) {
 	delete(p.instanceProviderAndPool, nodeName)
 }
 
-// getCapacity returns the capacity based on the instance type and the instance os
-func getCapacity(instanceType string, instanceOs string) int {
-	// Assign only 1st ENIs non primary IP
+// getCapacity returns the capacity based on the instance type as the sum of the non primary IPs across all the ENIs,
+// excluding the trunk interface if the instance has one
+func getCapacity(instanceType string, hasTrunk bool) int {
 	limits, found := vpc.Limits[instanceType]
 	if !found {
 		return 0
 	}
-	var capacity int
-	if instanceOs == config.OSWindows {
-		capacity = limits.IPv4PerInterface - 1
-	} else {
-		capacity = (limits.IPv4PerInterface - 1) * limits.Interface
+	interfaces := limits.Interface
+	if hasTrunk {
+		interfaces--
 	}
-
-	return capacity
+	return (limits.IPv4PerInterface - 1) * interfaces
 }
 
 // difference returns the difference between the slice and the map in the argument
@@ -481,6 +512,7 @@ func ChangeToIntrospectSummary(details pool.IntrospectResponse) pool.IntrospectS
 		WarmResourcesCount:    len(details.WarmResources),
 		CoolingResourcesCount: len(details.CoolingResources),
 		UsedResourcesCount:    len(details.UsedResources),
+		PrefixFragmentation:   details.PrefixFragmentation,
 	}
 }
 
@@ -495,6 +527,42 @@ func (p *ipv4Provider) IntrospectNode(nodeName string) interface{} {
 	return resource.resourcePool.Introspect()
 }
 
+// IntrospectNodeNames returns the names of the nodes with the resources of the provider
+func (p *ipv4Provider) IntrospectNodeNames() []string {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	return slices.Collect(maps.Keys(p.instanceProviderAndPool))
+}
+
+// IntrospectNodeSummary returns the resources summary of the node
+func (p *ipv4Provider) IntrospectNodeSummary(nodeName string) interface{} {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	resource, found := p.instanceProviderAndPool[nodeName]
+	if !found {
+		return struct{}{}
+	}
+	return ChangeToIntrospectSummary(resource.resourcePool.Introspect())
+}
+
+// CheckpointNode adds the state of the node's pool and the ENIs the pool's resources are assigned to the checkpoint
+func (p *ipv4Provider) CheckpointNode(nodeName string, checkpoint *v1alpha1.CNINodeCheckpoint) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	resource, found := p.instanceProviderAndPool[nodeName]
+	if !found {
+		return
+	}
+	if checkpoint.IPv4Pools == nil {
+		checkpoint.IPv4Pools = make(map[string]v1alpha1.IPv4PoolCheckpoint)
+	}
+	checkpoint.IPv4Pools[config.ResourceNameIPAddress] = pool.NewIPv4PoolCheckpoint(resource.resourcePool.Introspect(),
+		resource.eniManager.Checkpoint())
+}
+
 func (p *ipv4Provider) check() healthz.Checker {
 	p.log.Info("IPv4 provider's healthz subpath was added")
 	return func(req *http.Request) error {
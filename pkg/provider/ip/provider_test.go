@@ -14,13 +14,17 @@
 package ip
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"strconv"
 	"testing"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 
+	"github.com/aws/amazon-vpc-resource-controller-k8s/apis/vpcresources/v1alpha1"
 	mock_ec2 "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/aws/ec2"
 	mock_condition "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/condition"
 	mock_k8s "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/k8s"
@@ -29,6 +33,7 @@ import (
 	mock_worker "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/worker"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/api"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/lookup"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/pool"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider/ip/eni"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/worker"
@@ -76,17 +81,16 @@ func TestIpv4Provider_no_difference(t *testing.T) {
 	assert.Empty(t, unusedIPs)
 }
 
-// TestNewIPv4Provider_getCapacity tests capacity of different os type
+// TestNewIPv4Provider_getCapacity tests capacity is summed across all the ENIs of the instance type
 func TestNewIPv4Provider_getCapacity(t *testing.T) {
-	capacityLinux := getCapacity(instanceType, config.OSLinux)
-	capacityWindows := getCapacity(instanceType, config.OSWindows)
-	capacityUnknown := getCapacity("x.large", "linux")
+	capacity := getCapacity(instanceType, false)
+	capacityUnknown := getCapacity("x.large", false)
 
 	assert.Zero(t, capacityUnknown)
-	// IP(6) - 1(Primary) = 5
-	assert.Equal(t, 5, capacityWindows)
 	// (IP(6) - 1(Primary)) * 3(ENI) = 15
-	assert.Equal(t, 15, capacityLinux)
+	assert.Equal(t, 15, capacity)
+	// (IP(6) - 1(Primary)) * (3(ENI) - 1(Trunk)) = 10
+	assert.Equal(t, 10, getCapacity(instanceType, true))
 }
 
 // TestNewIPv4Provider_deleteInstanceProviderAndPool tests that the ResourcePoolAndProvider for given node is removed from
@@ -98,6 +102,24 @@ func TestNewIPv4Provider_deleteInstanceProviderAndPool(t *testing.T) {
 	assert.NotContains(t, ipProvider.instanceProviderAndPool, nodeName)
 }
 
+// TestIpv4Provider_DeInitResource tests the node is removed from the cache and its resources from the lookup index
+func TestIpv4Provider_DeInitResource(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockInstance := mock_ec2.NewMockEC2Instance(ctrl)
+	ipProvider := getMockIpProvider()
+	ipProvider.index = lookup.NewIndex(time.Hour)
+	ipProvider.instanceProviderAndPool[nodeName] = &ResourceProviderAndPool{}
+	ipProvider.index.Assign(nodeName, "pod-uid", "192.168.1.1")
+
+	mockInstance.EXPECT().Name().Return(nodeName)
+	assert.NoError(t, ipProvider.DeInitResource(mockInstance))
+	assert.NotContains(t, ipProvider.instanceProviderAndPool, nodeName)
+	_, found := ipProvider.index.Get("192.168.1.1")
+	assert.False(t, found)
+}
+
 // TestNewIPv4Provider_getInstanceProviderAndPool tests if the resource pool and provider is present in cache it's returned
 func TestNewIPv4Provider_getInstanceProviderAndPool(t *testing.T) {
 	ipProvider := getMockIpProvider()
@@ -178,7 +200,7 @@ func TestIpv4Provider_DeletePrivateIPv4AndUpdatePool(t *testing.T) {
 		NodeName:      nodeName,
 	}
 
-	mockManager.EXPECT().DeleteIPV4Resource(resourcesToDelete, config.ResourceTypeIPv4Address, nil, gomock.Any()).Return([]string{}, nil)
+	mockManager.EXPECT().DeleteIPV4Resource(gomock.Any(), resourcesToDelete, config.ResourceTypeIPv4Address, nil, gomock.Any()).Return([]string{}, nil)
 	mockPool.EXPECT().UpdatePool(&worker.WarmPoolJob{
 		Operations:    worker.OperationDeleted,
 		Resources:     []string{},
@@ -186,7 +208,7 @@ func TestIpv4Provider_DeletePrivateIPv4AndUpdatePool(t *testing.T) {
 		NodeName:      nodeName,
 	}, true, false).Return(false)
 
-	ipv4Provider.DeletePrivateIPv4AndUpdatePool(deleteJob)
+	ipv4Provider.DeletePrivateIPv4AndUpdatePool(context.TODO(), deleteJob)
 }
 
 // TestIpv4Provider_DeletePrivateIPv4AndUpdatePool_SomeResourceFail tests if some resource fail to delete those resources
@@ -209,7 +231,7 @@ func TestIpv4Provider_DeletePrivateIPv4AndUpdatePool_SomeResourceFail(t *testing
 		NodeName:      nodeName,
 	}
 
-	mockManager.EXPECT().DeleteIPV4Resource(resourcesToDelete, config.ResourceTypeIPv4Address, nil, gomock.Any()).Return(failedResources, nil)
+	mockManager.EXPECT().DeleteIPV4Resource(gomock.Any(), resourcesToDelete, config.ResourceTypeIPv4Address, nil, gomock.Any()).Return(failedResources, nil)
 	mockPool.EXPECT().UpdatePool(&worker.WarmPoolJob{
 		Operations:    worker.OperationDeleted,
 		Resources:     failedResources,
@@ -217,7 +239,7 @@ func TestIpv4Provider_DeletePrivateIPv4AndUpdatePool_SomeResourceFail(t *testing
 		NodeName:      nodeName,
 	}, true, false).Return(false)
 
-	ipv4Provider.DeletePrivateIPv4AndUpdatePool(&deleteJob)
+	ipv4Provider.DeletePrivateIPv4AndUpdatePool(context.TODO(), &deleteJob)
 }
 
 // TestIPv4Provider_CreatePrivateIPv4AndUpdatePool tests if resources are created then the job object is updated
@@ -239,7 +261,7 @@ func TestIPv4Provider_CreatePrivateIPv4AndUpdatePool(t *testing.T) {
 		NodeName:      nodeName,
 	}
 
-	mockManager.EXPECT().CreateIPV4Resource(2, config.ResourceTypeIPv4Address, nil, gomock.Any()).Return(createdResources, nil)
+	mockManager.EXPECT().CreateIPV4Resource(gomock.Any(), 2, config.ResourceTypeIPv4Address, nil, gomock.Any()).Return(createdResources, nil)
 	mockPool.EXPECT().UpdatePool(&worker.WarmPoolJob{
 		Operations:    worker.OperationCreate,
 		Resources:     createdResources,
@@ -247,7 +269,7 @@ func TestIPv4Provider_CreatePrivateIPv4AndUpdatePool(t *testing.T) {
 		NodeName:      nodeName,
 	}, true, false).Return(false)
 
-	ipv4Provider.CreatePrivateIPv4AndUpdatePool(createJob)
+	ipv4Provider.CreatePrivateIPv4AndUpdatePool(context.TODO(), createJob)
 }
 
 // TestIPv4Provider_CreatePrivateIPv4AndUpdatePool_Fail tests that if some of the create fails then the pool is
@@ -269,7 +291,7 @@ func TestIPv4Provider_CreatePrivateIPv4AndUpdatePool_Fail(t *testing.T) {
 		NodeName:      nodeName,
 	}
 
-	mockManager.EXPECT().CreateIPV4Resource(2, config.ResourceTypeIPv4Address, nil, gomock.Any()).Return(createdResources, fmt.Errorf("failed"))
+	mockManager.EXPECT().CreateIPV4Resource(gomock.Any(), 2, config.ResourceTypeIPv4Address, nil, gomock.Any()).Return(createdResources, fmt.Errorf("failed"))
 	mockPool.EXPECT().UpdatePool(&worker.WarmPoolJob{
 		Operations:    worker.OperationCreate,
 		Resources:     createdResources,
@@ -277,7 +299,7 @@ func TestIPv4Provider_CreatePrivateIPv4AndUpdatePool_Fail(t *testing.T) {
 		NodeName:      nodeName,
 	}, false, false).Return(false)
 
-	ipv4Provider.CreatePrivateIPv4AndUpdatePool(createJob)
+	ipv4Provider.CreatePrivateIPv4AndUpdatePool(context.TODO(), createJob)
 }
 
 func TestIpv4Provider_ReSyncPool(t *testing.T) {
@@ -296,14 +318,14 @@ func TestIpv4Provider_ReSyncPool(t *testing.T) {
 	}
 
 	// When error occurs, pool should not be re-synced
-	mockManager.EXPECT().InitResources(ipv4Provider.apiWrapper.EC2API).Return(nil, fmt.Errorf(""))
-	ipv4Provider.ReSyncPool(reSyncJob)
+	mockManager.EXPECT().InitResources(gomock.Any(), ipv4Provider.apiWrapper.EC2API).Return(nil, fmt.Errorf(""))
+	ipv4Provider.ReSyncPool(context.TODO(), reSyncJob)
 
 	// When no error occurs, pool should be re-synced
 	ipV4Resources := &eni.IPv4Resource{PrivateIPv4Addresses: resources}
-	mockManager.EXPECT().InitResources(ipv4Provider.apiWrapper.EC2API).Return(ipV4Resources, nil)
+	mockManager.EXPECT().InitResources(gomock.Any(), ipv4Provider.apiWrapper.EC2API).Return(ipV4Resources, nil)
 	mockPool.EXPECT().ReSync(resources)
-	ipv4Provider.ReSyncPool(reSyncJob)
+	ipv4Provider.ReSyncPool(context.TODO(), reSyncJob)
 }
 
 // TestIPv4Provider_SubmitAsyncJob tests that the job is submitted to the worker on calling SubmitAsyncJob
@@ -354,17 +376,18 @@ func TestIPv4Provider_UpdateResourceCapacity_FromFromPDToIP(t *testing.T) {
 	ipv4Provider.putInstanceProviderAndPool(nodeName, mockPool, mockManager, nodeCapacity, true)
 	mockConditions.EXPECT().IsWindowsPrefixDelegationEnabled().Return(false)
 	mockK8sWrapper.EXPECT().GetConfigMap(config.VpcCniConfigMapName, config.KubeSystemNamespace).Return(expectedVpcCNIConfig, nil)
+	mockK8sWrapper.EXPECT().GetCNINode(types.NamespacedName{Name: nodeName}).Return(&v1alpha1.CNINode{}, nil)
 
 	job := &worker.WarmPoolJob{Operations: worker.OperationCreate}
 	mockPool.EXPECT().SetToActive(&ipV4WarmPoolConfig).Return(job)
 	mockWorker.EXPECT().SubmitJob(job)
 
-	mockInstance.EXPECT().Name().Return(nodeName).Times(3)
+	mockInstance.EXPECT().Name().Return(nodeName).Times(4)
 	mockInstance.EXPECT().Type().Return(instanceType).Times(2)
 	mockInstance.EXPECT().Os().Return(config.OSWindows)
 	mockK8sWrapper.EXPECT().AdvertiseCapacityIfNotSet(nodeName, config.ResourceNameIPAddress, 14).Return(nil)
 
-	err := ipv4Provider.UpdateResourceCapacity(mockInstance)
+	err := ipv4Provider.UpdateResourceCapacity(context.TODO(), mockInstance)
 	assert.NoError(t, err)
 }
 
@@ -391,7 +414,7 @@ func TestIPv4Provider_UpdateResourceCapacity_FromFromIPToPD(t *testing.T) {
 	mockInstance.EXPECT().Name().Return(nodeName)
 	mockInstance.EXPECT().Type().Return(instanceType)
 
-	err := ipv4Provider.UpdateResourceCapacity(mockInstance)
+	err := ipv4Provider.UpdateResourceCapacity(context.TODO(), mockInstance)
 	assert.NoError(t, err)
 }
 
@@ -422,16 +445,17 @@ func TestIPv4Provider_UpdateResourceCapacity_FromFromIPToPD_NonNitro(t *testing.
 	ipv4Provider.putInstanceProviderAndPool(nodeName, mockPool, mockManager, nodeCapacity, false)
 	mockConditions.EXPECT().IsWindowsPrefixDelegationEnabled().Return(true)
 	mockK8sWrapper.EXPECT().GetConfigMap(config.VpcCniConfigMapName, config.KubeSystemNamespace).Return(expectedVpcCNIConfig, nil)
+	mockK8sWrapper.EXPECT().GetCNINode(types.NamespacedName{Name: nodeName}).Return(&v1alpha1.CNINode{}, nil)
 
 	job := &worker.WarmPoolJob{Operations: worker.OperationCreate}
 	mockPool.EXPECT().SetToActive(&ipV4WarmPoolConfig).Return(job)
 	mockWorker.EXPECT().SubmitJob(job)
-	mockInstance.EXPECT().Name().Return(nodeName).Times(4)
+	mockInstance.EXPECT().Name().Return(nodeName).Times(5)
 	mockInstance.EXPECT().Type().Return(nonNitroInstanceType).Times(3)
 	mockInstance.EXPECT().Os().Return(config.OSWindows)
 	mockK8sWrapper.EXPECT().AdvertiseCapacityIfNotSet(nodeName, config.ResourceNameIPAddress, 14).Return(nil)
 
-	err := ipv4Provider.UpdateResourceCapacity(mockInstance)
+	err := ipv4Provider.UpdateResourceCapacity(context.TODO(), mockInstance)
 	assert.NoError(t, err)
 }
 
@@ -453,10 +477,57 @@ func TestIPv4Provider_UpdateResourceCapacity_FromPDToPD(t *testing.T) {
 	ipv4Provider.putInstanceProviderAndPool(nodeName, mockPool, mockManager, nodeCapacity, true)
 	mockConditions.EXPECT().IsWindowsPrefixDelegationEnabled().Return(true)
 
-	err := ipv4Provider.UpdateResourceCapacity(mockInstance)
+	err := ipv4Provider.UpdateResourceCapacity(context.TODO(), mockInstance)
 	assert.NoError(t, err)
 }
 
+// TestIPv4Provider_UpdateResourceCapacity_CNINodeTargets tests the targets on the node's CNINode are only applied to
+// the node's pool and the provider's config shared by the other nodes is not modified
+func TestIPv4Provider_UpdateResourceCapacity_CNINodeTargets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockInstance := mock_ec2.NewMockEC2Instance(ctrl)
+	mockK8sWrapper := mock_k8s.NewMockK8sWrapper(ctrl)
+	mockConditions := mock_condition.NewMockConditions(ctrl)
+	mockWorker := mock_worker.NewMockWorker(ctrl)
+	providerConfig := ipV4WarmPoolConfig
+	ipv4Provider := ipv4Provider{apiWrapper: api.Wrapper{K8sAPI: mockK8sWrapper}, workerPool: mockWorker, config: &providerConfig,
+		instanceProviderAndPool: map[string]*ResourceProviderAndPool{}, log: zap.New(zap.UseDevMode(true)).WithName("ip provider"), conditions: mockConditions}
+	expectedVpcCNIConfig := &v1.ConfigMap{
+		Data: map[string]string{
+			config.EnableWindowsIPAMKey:             "true",
+			config.EnableWindowsPrefixDelegationKey: "false",
+			config.WarmIPTarget:                     strconv.Itoa(config.IPv4DefaultWinWarmIPTarget),
+			config.MinimumIPTarget:                  strconv.Itoa(config.IPv4DefaultWinMinIPTarget),
+		},
+	}
+	warmIPTarget := 5
+	cniNode := &v1alpha1.CNINode{Spec: v1alpha1.CNINodeSpec{WarmIPTarget: &warmIPTarget}}
+
+	mockPool := mock_pool.NewMockPool(ctrl)
+	mockManager := mock_eni.NewMockENIManager(ctrl)
+	ipv4Provider.putInstanceProviderAndPool(nodeName, mockPool, mockManager, nodeCapacity, false)
+	mockConditions.EXPECT().IsWindowsPrefixDelegationEnabled().Return(false)
+	mockK8sWrapper.EXPECT().GetConfigMap(config.VpcCniConfigMapName, config.KubeSystemNamespace).Return(expectedVpcCNIConfig, nil)
+	mockK8sWrapper.EXPECT().GetCNINode(types.NamespacedName{Name: nodeName}).Return(cniNode, nil)
+
+	nodeConfig := ipV4WarmPoolConfig
+	nodeConfig.WarmIPTarget = warmIPTarget
+	job := &worker.WarmPoolJob{Operations: worker.OperationCreate}
+	mockPool.EXPECT().SetToActive(&nodeConfig).Return(job)
+	mockWorker.EXPECT().SubmitJob(job)
+
+	mockInstance.EXPECT().Name().Return(nodeName).Times(4)
+	mockInstance.EXPECT().Type().Return(instanceType).Times(2)
+	mockInstance.EXPECT().Os().Return(config.OSWindows)
+	mockK8sWrapper.EXPECT().AdvertiseCapacityIfNotSet(nodeName, config.ResourceNameIPAddress, 14).Return(nil)
+
+	err := ipv4Provider.UpdateResourceCapacity(context.TODO(), mockInstance)
+	assert.NoError(t, err)
+	assert.Equal(t, ipV4WarmPoolConfig, *ipv4Provider.config)
+}
+
 // TestIPv4Provider_UpdateResourceCapacity_FromIPToIP tests the resource capacity is not updated when secondary IP mode stays enabled
 func TestIPv4Provider_UpdateResourceCapacity_FromIPToIP(t *testing.T) {
 	ctrl := gomock.NewController(t)
@@ -482,17 +553,18 @@ func TestIPv4Provider_UpdateResourceCapacity_FromIPToIP(t *testing.T) {
 	ipv4Provider.putInstanceProviderAndPool(nodeName, mockPool, mockManager, nodeCapacity, false)
 	mockConditions.EXPECT().IsWindowsPrefixDelegationEnabled().Return(false)
 	mockK8sWrapper.EXPECT().GetConfigMap(config.VpcCniConfigMapName, config.KubeSystemNamespace).Return(expectedVpcCNIConfig, nil)
+	mockK8sWrapper.EXPECT().GetCNINode(types.NamespacedName{Name: nodeName}).Return(&v1alpha1.CNINode{}, nil)
 
 	job := &worker.WarmPoolJob{Operations: worker.OperationCreate}
 	mockPool.EXPECT().SetToActive(&ipV4WarmPoolConfig).Return(job)
 	mockWorker.EXPECT().SubmitJob(job)
 
-	mockInstance.EXPECT().Name().Return(nodeName).Times(3)
+	mockInstance.EXPECT().Name().Return(nodeName).Times(4)
 	mockInstance.EXPECT().Type().Return(instanceType).Times(2)
 	mockInstance.EXPECT().Os().Return(config.OSWindows)
 	mockK8sWrapper.EXPECT().AdvertiseCapacityIfNotSet(nodeName, config.ResourceNameIPAddress, 14).Return(nil)
 
-	err := ipv4Provider.UpdateResourceCapacity(mockInstance)
+	err := ipv4Provider.UpdateResourceCapacity(context.TODO(), mockInstance)
 	assert.NoError(t, err)
 }
 
@@ -528,6 +600,15 @@ func TestIpv4Provider_Introspect(t *testing.T) {
 
 	resp = ipv4Provider.IntrospectNode("unregistered-node")
 	assert.Equal(t, resp, struct{}{})
+
+	assert.Equal(t, []string{nodeName}, ipv4Provider.IntrospectNodeNames())
+
+	mockPool.EXPECT().Introspect().Return(expectedResp)
+	resp = ipv4Provider.IntrospectNodeSummary(nodeName)
+	assert.Equal(t, resp, ChangeToIntrospectSummary(expectedResp))
+
+	resp = ipv4Provider.IntrospectNodeSummary("unregistered-node")
+	assert.Equal(t, resp, struct{}{})
 }
 
 func getMockIpProvider() ipv4Provider {
@@ -0,0 +1,514 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ipv6prefix
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/aws/amazon-vpc-resource-controller-k8s/apis/vpcresources/v1alpha1"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/api"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/ec2"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/vpc"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
+	rcHealthz "github.com/aws/amazon-vpc-resource-controller-k8s/pkg/healthz"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/lookup"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/pool"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider/ip"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/utils"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/worker"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/go-logr/logr"
+	"github.com/samber/lo"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+type ipv6PrefixProvider struct {
+	// log is the logger initialized with IPv6 prefix provider details
+	log logr.Logger
+	// apiWrapper wraps all clients used by the controller
+	apiWrapper api.Wrapper
+	// workerPool with worker routine to execute asynchronous job on the IPv6 prefix provider
+	workerPool worker.Worker
+	// config is the default warm pool configuration for the IPv6 prefix resource
+	config *config.WarmPoolConfig
+	// lock to allow multiple routines to access the cache concurrently
+	lock sync.RWMutex // guards the following
+	// instanceProviderAndPool stores the network interface and the resource pool per instance
+	instanceProviderAndPool map[string]*ResourceProviderAndPool
+	// index records the pods the resources are assigned to for the lookup of the introspection API
+	index *lookup.Index
+	// healthz check subpath
+	checker healthz.Checker
+}
+
+// ResourceProviderAndPool contains the network interface the IPv6 prefixes are assigned to and the resource pool
+type ResourceProviderAndPool struct {
+	// eniID is the network interface on which the IPv6 prefixes are assigned
+	eniID        string
+	resourcePool pool.Pool
+	// capacity is stored so that it can be advertised when node is updated
+	capacity int
+}
+
+func NewIPv6PrefixProvider(log logr.Logger, apiWrapper api.Wrapper, workerPool worker.Worker,
+	resourceConfig config.ResourceConfig, index *lookup.Index) provider.ResourceProvider {
+	provider := &ipv6PrefixProvider{
+		instanceProviderAndPool: make(map[string]*ResourceProviderAndPool),
+		config:                  resourceConfig.WarmPoolConfig,
+		log:                     log,
+		apiWrapper:              apiWrapper,
+		workerPool:              workerPool,
+		index:                   index,
+	}
+	provider.checker = provider.check()
+	return provider
+}
+
+// InitResource loads the IPv6 prefixes assigned to the primary network interface of the instance and the IPv6 addresses
+// used by the running pods to initialize the resource pool
+func (p *ipv6PrefixProvider) InitResource(ctx context.Context, instance ec2.EC2Instance) error {
+	nodeName := instance.Name()
+
+	presentPrefixes, err := p.getIPv6Prefixes(ctx, instance)
+	if err != nil {
+		return err
+	}
+
+	pods, err := p.apiWrapper.PodAPI.GetRunningPodsOnNode(nodeName)
+	if err != nil {
+		return err
+	}
+
+	// Construct map of all the IPv6 addresses handed out from each assigned prefix
+	warmResourceIDToGroup := map[string]string{}
+	for _, prefix := range presentPrefixes {
+		ips, err := utils.DeconstructIPv6AddressesFromPrefix(prefix, p.config.AddressesPerPrefix)
+		if err != nil {
+			return err
+		}
+		for _, ip := range ips {
+			warmResourceIDToGroup[ip] = prefix
+		}
+	}
+
+	podToResourceMap := make(map[string]pool.Resource)
+	for _, pod := range pods {
+		annotation, present := pod.Annotations[config.ResourceNameIPv6Address]
+		if !present {
+			continue
+		}
+
+		prefix, found := warmResourceIDToGroup[annotation]
+		if !found {
+			p.log.Info("ignoring IPv6 address not deconstructed from an assigned prefix", "IPv6 address", annotation)
+			continue
+		}
+		// store running pod into map of used resources and remove its IPv6 address from warm resources
+		podToResourceMap[string(pod.UID)] = pool.Resource{GroupID: prefix, ResourceID: annotation}
+		delete(warmResourceIDToGroup, annotation)
+	}
+
+	// Construct map of warm Resources, key is prefix, value is list of Resources belonged to that prefix
+	warmResources := make(map[string][]pool.Resource)
+	for ip, prefix := range warmResourceIDToGroup {
+		warmResources[prefix] = append(warmResources[prefix], pool.Resource{GroupID: prefix, ResourceID: ip})
+	}
+
+	nodeCapacity := getCapacity(instance.Type()) * p.config.AddressesPerPrefix
+
+	// Each pool owns a copy of the warm pool config since the pool updates the targets in place
+	warmPoolConfig := *p.config
+	resourcePool := pool.NewResourcePool(p.log.WithName("prefix ipv6 address resource pool").
+		WithValues("node name", nodeName), &warmPoolConfig, podToResourceMap,
+		warmResources, nodeName, nodeCapacity, true, p.index)
+
+	p.putInstanceProviderAndPool(nodeName, resourcePool, instance.PrimaryNetworkInterfaceID(), nodeCapacity)
+
+	p.log.Info("initialized the resource provider for ipv6 prefix",
+		"capacity", nodeCapacity, "node name", nodeName, "instance type",
+		instance.Type(), "instance ID", instance.InstanceID(), "warmPoolConfig", warmPoolConfig)
+
+	job := resourcePool.ReconcilePool()
+	if job.Operations != worker.OperationReconcileNotRequired {
+		p.SubmitAsyncJob(job)
+	}
+
+	// Submit the async job to periodically process the delete queue
+	p.SubmitAsyncJob(worker.NewWarmProcessDeleteQueueJob(nodeName))
+	return nil
+}
+
+func (p *ipv6PrefixProvider) DeInitResource(instance ec2.EC2Instance) error {
+	p.deleteInstanceProviderAndPool(instance.Name())
+	p.index.RemoveNode(instance.Name())
+	return nil
+}
+
+// UpdateResourceCapacity advertises the IPv6 address capacity on the node. If the IPv6 prefix delegation feature was
+// enabled on the CNINode after the node was initialized, the resource pool is initialized first
+func (p *ipv6PrefixProvider) UpdateResourceCapacity(ctx context.Context, instance ec2.EC2Instance) error {
+	resourceProviderAndPool, isPresent := p.getInstanceProviderAndPool(instance.Name())
+	if !isPresent {
+		p.log.Info("IPv6 prefix delegation was enabled after node initialization, initializing the resource pool",
+			"node name", instance.Name())
+		if err := p.InitResource(ctx, instance); err != nil {
+			return err
+		}
+		resourceProviderAndPool, _ = p.getInstanceProviderAndPool(instance.Name())
+	}
+
+	err := p.apiWrapper.K8sAPI.AdvertiseCapacityIfNotSet(instance.Name(), config.ResourceNameIPv6Address,
+		resourceProviderAndPool.capacity)
+	if err != nil {
+		return err
+	}
+	p.log.V(1).Info("advertised capacity", "instance", instance.Name(), "instance type", instance.Type(),
+		"os", instance.Os(), "capacity", resourceProviderAndPool.capacity)
+
+	return nil
+}
+
+func (p *ipv6PrefixProvider) SubmitAsyncJob(job interface{}) {
+	p.workerPool.SubmitJob(job)
+}
+
+// SubmitAsyncJobWithContext submits the job to the worker pool, the job continues the trace of the context
+func (p *ipv6PrefixProvider) SubmitAsyncJobWithContext(ctx context.Context, job interface{}) {
+	p.workerPool.SubmitJobWithContext(ctx, job)
+}
+
+func (p *ipv6PrefixProvider) ProcessAsyncJob(ctx context.Context, job interface{}) (ctrl.Result, error) {
+	warmPoolJob, isValid := job.(*worker.WarmPoolJob)
+	if !isValid {
+		return ctrl.Result{}, fmt.Errorf("invalid job type")
+	}
+
+	switch warmPoolJob.Operations {
+	case worker.OperationCreate:
+		p.CreateIPv6PrefixAndUpdatePool(ctx, warmPoolJob)
+	case worker.OperationDeleted:
+		p.DeleteIPv6PrefixAndUpdatePool(ctx, warmPoolJob)
+	case worker.OperationReSyncPool:
+		p.ReSyncPool(ctx, warmPoolJob)
+	case worker.OperationProcessDeleteQueue:
+		return p.ProcessDeleteQueue(warmPoolJob)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// CreateIPv6PrefixAndUpdatePool assigns the number of IPv6 prefixes required by the warm pool job to the network
+// interface and updates the pool with the assigned prefixes
+func (p *ipv6PrefixProvider) CreateIPv6PrefixAndUpdatePool(ctx context.Context, job *worker.WarmPoolJob) {
+	instanceResource, found := p.getInstanceProviderAndPool(job.NodeName)
+	if !found {
+		p.log.Error(utils.ErrNotFound, utils.ErrMsgProviderAndPoolNotFound, "node name", job.NodeName)
+		return
+	}
+
+	// For successful jobs or non-retryable errors, do not re-sync or reconcile the pool.
+	notRetry := true
+	// If subnet has sufficient cidr blocks, prefixAvailable is true, otherwise false.
+	prefixAvailable := true
+
+	resources, err := p.apiWrapper.EC2API.AssignIPv6PrefixesAndWaitTillReady(ctx, instanceResource.eniID, job.ResourceCount)
+	if err != nil {
+		p.log.Error(err, "failed to create all/some of the IPv6 prefixes", "created resources", resources)
+
+		// For retryable errors, set notRetry as false to re-sync and reconcile the pool.
+		if utils.ShouldRetryOnError(err) {
+			notRetry = false
+		}
+
+		if strings.HasPrefix(err.Error(), utils.InsufficientCidrBlocksReason) {
+			// Prefix not available in the subnet, set status and pass it to pool. Note this is a non-retryable error.
+			prefixAvailable = false
+			utils.SendNodeEventWithNodeName(p.apiWrapper.K8sAPI, job.NodeName, utils.InsufficientCidrBlocksReason,
+				utils.ErrInsufficientCidrBlocks.Error(), v1.EventTypeWarning, p.log)
+		}
+	}
+	job.Resources = resources
+	p.updatePoolAndReconcileIfRequired(instanceResource.resourcePool, job, notRetry, prefixAvailable)
+}
+
+// DeleteIPv6PrefixAndUpdatePool un-assigns the list of IPv6 prefixes in the warm pool job from the network interface
+func (p *ipv6PrefixProvider) DeleteIPv6PrefixAndUpdatePool(ctx context.Context, job *worker.WarmPoolJob) {
+	instanceResource, found := p.getInstanceProviderAndPool(job.NodeName)
+	if !found {
+		p.log.Error(utils.ErrNotFound, utils.ErrMsgProviderAndPoolNotFound, "node name", job.NodeName)
+		return
+	}
+
+	didSucceed := true
+	err := p.apiWrapper.EC2API.UnassignIPv6Prefixes(ctx, instanceResource.eniID, job.Resources)
+	if err != nil {
+		p.log.Error(err, "failed to delete the IPv6 prefixes", "failed resources", job.Resources)
+		didSucceed = false
+	} else {
+		p.log.Info("deleted IPv6 prefixes", "eni", instanceResource.eniID, "resources", job.Resources)
+		job.Resources = nil
+	}
+	p.updatePoolAndReconcileIfRequired(instanceResource.resourcePool, job, didSucceed, true)
+}
+
+func (p *ipv6PrefixProvider) ReSyncPool(ctx context.Context, job *worker.WarmPoolJob) {
+	instanceResource, found := p.getInstanceProviderAndPool(job.NodeName)
+	if !found {
+		p.log.Error(utils.ErrNotFound, "node is not initialized", "node name", job.NodeName)
+		return
+	}
+
+	prefixes, err := p.getIPv6PrefixesOnENI(ctx, job.NodeName, instanceResource.eniID)
+	if err != nil {
+		p.log.Error(err, "failed to get IPv6 prefixes for the node", "node name", job.NodeName)
+		return
+	}
+
+	instanceResource.resourcePool.ReSync(prefixes)
+}
+
+func (p *ipv6PrefixProvider) ProcessDeleteQueue(job *worker.WarmPoolJob) (ctrl.Result, error) {
+	resourceProviderAndPool, isPresent := p.getInstanceProviderAndPool(job.NodeName)
+	if !isPresent {
+		p.log.Info("forgetting the delete queue processing job", "node name", job.NodeName)
+		return ctrl.Result{}, nil
+	}
+	resourceProviderAndPool.resourcePool.ProcessCoolDownQueue()
+
+	// After the cool down queue is processed check if we need to do reconciliation
+	job = resourceProviderAndPool.resourcePool.ReconcilePool()
+	if job.Operations != worker.OperationReconcileNotRequired {
+		p.SubmitAsyncJob(job)
+	}
+
+	// Re-submit the job to execute after cool down period has ended
+	return ctrl.Result{Requeue: true, RequeueAfter: config.CoolDownPeriod}, nil
+}
+
+// updatePoolAndReconcileIfRequired updates the resource pool and reconcile again and submit a new job if required
+func (p *ipv6PrefixProvider) updatePoolAndReconcileIfRequired(resourcePool pool.Pool, job *worker.WarmPoolJob, didSucceed bool,
+	prefixAvailable bool) {
+	// Update the pool to add the created/failed resource to the warm pool and decrement the pending count
+	shouldReconcile := resourcePool.UpdatePool(job, didSucceed, prefixAvailable)
+
+	if shouldReconcile {
+		job := resourcePool.ReconcilePool()
+		if job.Operations != worker.OperationReconcileNotRequired {
+			p.SubmitAsyncJob(job)
+		}
+	}
+}
+
+func (p *ipv6PrefixProvider) GetPool(nodeName string) (pool.Pool, bool) {
+	providerAndPool, exists := p.getInstanceProviderAndPool(nodeName)
+	if !exists {
+		return nil, false
+	}
+	return providerAndPool.resourcePool, true
+}
+
+// IsInstanceSupported returns true if the IPv6 prefix delegation feature is enabled in the node's CNINode and the
+// instance is a nitro instance
+func (p *ipv6PrefixProvider) IsInstanceSupported(instance ec2.EC2Instance) bool {
+	// Nodes that were already initialized stay supported so their pool is cleaned up on delete even after the
+	// CNINode is removed
+	if _, found := p.getInstanceProviderAndPool(instance.Name()); found {
+		return true
+	}
+
+	cniNode, err := p.apiWrapper.K8sAPI.GetCNINode(types.NamespacedName{Name: instance.Name()})
+	if err != nil {
+		p.log.V(1).Info("failed to get CNINode, IPv6 prefix delegation not enabled", "node name", instance.Name(),
+			"error", err.Error())
+		return false
+	}
+	if !lo.ContainsBy(cniNode.Spec.Features, func(feature v1alpha1.Feature) bool {
+		return feature.Name == v1alpha1.IPv6PrefixDelegation
+	}) {
+		return false
+	}
+
+	isNitroInstance, err := utils.IsNitroInstance(instance.Type())
+	if err == nil && isNitroInstance {
+		return true
+	}
+
+	msg := fmt.Sprintf("The instance type %s is not supported for IPv6 prefix delegation", instance.Type())
+	utils.SendNodeEventWithNodeName(p.apiWrapper.K8sAPI, instance.Name(), utils.UnsupportedInstanceTypeReason, msg,
+		v1.EventTypeWarning, p.log)
+	return false
+}
+
+func (p *ipv6PrefixProvider) Introspect() interface{} {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	response := make(map[string]pool.IntrospectResponse)
+	for nodeName, resource := range p.instanceProviderAndPool {
+		response[nodeName] = resource.resourcePool.Introspect()
+	}
+	return response
+}
+
+func (p *ipv6PrefixProvider) IntrospectSummary() interface{} {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	response := make(map[string]pool.IntrospectSummaryResponse)
+	for nodeName, resource := range p.instanceProviderAndPool {
+		response[nodeName] = ip.ChangeToIntrospectSummary(resource.resourcePool.Introspect())
+	}
+	return response
+}
+
+func (p *ipv6PrefixProvider) IntrospectNode(node string) interface{} {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	resource, found := p.instanceProviderAndPool[node]
+	if !found {
+		return struct{}{}
+	}
+	return resource.resourcePool.Introspect()
+}
+
+// IntrospectNodeNames returns the names of the nodes with the resources of the provider
+func (p *ipv6PrefixProvider) IntrospectNodeNames() []string {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	return slices.Collect(maps.Keys(p.instanceProviderAndPool))
+}
+
+// IntrospectNodeSummary returns the resources summary of the node
+func (p *ipv6PrefixProvider) IntrospectNodeSummary(nodeName string) interface{} {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	resource, found := p.instanceProviderAndPool[nodeName]
+	if !found {
+		return struct{}{}
+	}
+	return ip.ChangeToIntrospectSummary(resource.resourcePool.Introspect())
+}
+
+// getIPv6Prefixes returns the IPv6 prefixes assigned to the primary network interface of the instance
+func (p *ipv6PrefixProvider) getIPv6Prefixes(ctx context.Context, instance ec2.EC2Instance) ([]string, error) {
+	nwInterfaces, err := p.apiWrapper.EC2API.GetInstanceNetworkInterface(ctx, aws.String(instance.InstanceID()))
+	if err != nil {
+		return nil, err
+	}
+
+	var prefixes []string
+	for _, nwInterface := range nwInterfaces {
+		if nwInterface.NetworkInterfaceId == nil || *nwInterface.NetworkInterfaceId != instance.PrimaryNetworkInterfaceID() {
+			continue
+		}
+		for _, prefix := range nwInterface.Ipv6Prefixes {
+			prefixes = append(prefixes, *prefix.Ipv6Prefix)
+		}
+	}
+	return prefixes, nil
+}
+
+// getIPv6PrefixesOnENI returns the IPv6 prefixes assigned to the given network interface
+func (p *ipv6PrefixProvider) getIPv6PrefixesOnENI(ctx context.Context, nodeName string, eniID string) ([]string,
+	error) {
+	nwInterfaces, err := p.apiWrapper.EC2API.DescribeNetworkInterfaces(ctx, []string{eniID})
+	if err != nil {
+		return nil, err
+	}
+	if len(nwInterfaces) != 1 {
+		return nil, fmt.Errorf("failed to find network interface %s of node %s", eniID, nodeName)
+	}
+
+	var prefixes []string
+	for _, prefix := range nwInterfaces[0].Ipv6Prefixes {
+		prefixes = append(prefixes, *prefix.Ipv6Prefix)
+	}
+	return prefixes, nil
+}
+
+// putInstanceProviderAndPool stores the node's network interface and pool to the cache
+func (p *ipv6PrefixProvider) putInstanceProviderAndPool(nodeName string, resourcePool pool.Pool, eniID string, capacity int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.instanceProviderAndPool[nodeName] = &ResourceProviderAndPool{
+		eniID:        eniID,
+		resourcePool: resourcePool,
+		capacity:     capacity,
+	}
+}
+
+// getInstanceProviderAndPool returns the node's network interface and pool from the cache
+func (p *ipv6PrefixProvider) getInstanceProviderAndPool(nodeName string) (*ResourceProviderAndPool, bool) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	resource, found := p.instanceProviderAndPool[nodeName]
+	return resource, found
+}
+
+// deleteInstanceProviderAndPool deletes the node's network interface and pool from the cache
+func (p *ipv6PrefixProvider) deleteInstanceProviderAndPool(nodeName string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	delete(p.instanceProviderAndPool, nodeName)
+}
+
+// getCapacity returns the number of IPv6 prefixes that can be assigned to the primary network interface of the
+// instance, one slot is left for the primary IPv6 address of the interface
+func getCapacity(instanceType string) int {
+	limits, found := vpc.Limits[instanceType]
+	if !found {
+		return 0
+	}
+	return limits.IPv4PerInterface - 1
+}
+
+func (p *ipv6PrefixProvider) check() healthz.Checker {
+	p.log.Info("IPv6 prefix provider's healthz subpath was added")
+	return func(req *http.Request) error {
+		err := rcHealthz.PingWithTimeout(func(c chan<- error) {
+			var ping interface{}
+			p.SubmitAsyncJob(ping)
+			p.log.V(1).Info("***** health check on IPv6 prefix provider tested SubmitAsyncJob *****")
+			c <- nil
+		}, p.log)
+
+		return err
+	}
+}
+
+func (p *ipv6PrefixProvider) GetHealthChecker() healthz.Checker {
+	return p.checker
+}
+
+// ReconcileNode implements provider.ResourceProvider.
+func (*ipv6PrefixProvider) ReconcileNode(nodeName string) bool {
+	return false
+}
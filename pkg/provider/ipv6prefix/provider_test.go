@@ -0,0 +1,377 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ipv6prefix
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/aws/amazon-vpc-resource-controller-k8s/apis/vpcresources/v1alpha1"
+	mock_ec2 "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/aws/ec2"
+	mock_api "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/aws/ec2/api"
+	mock_k8s "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/k8s"
+	mock_pod "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/k8s/pod"
+	mock_pool "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/pool"
+	mock_worker "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/worker"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/api"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/pool"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/utils"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/worker"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+var (
+	nodeName             = "node-1"
+	instanceID           = "i-00000000000000000"
+	instanceType         = "t3.medium"
+	nonNitroInstanceType = "c1.medium"
+	eniID                = "eni-000000000000000"
+
+	prefix1 = "2001:db8::/80"
+	prefix2 = "2001:db8:0:0:1::/80"
+
+	nodeCapacity = 80
+
+	ipv6WarmPoolConfig = &config.WarmPoolConfig{
+		DesiredSize:        config.IPv6PDDefaultWPSize,
+		MaxDeviation:       config.IPv6PDDefaultMaxDev,
+		WarmIPTarget:       config.IPv6PDDefaultWarmIPTargetSize,
+		MinIPTarget:        config.IPv6PDDefaultMinIPTargetSize,
+		WarmPrefixTarget:   config.IPv6PDDefaultWarmPrefixTargetSize,
+		AddressesPerPrefix: config.IPv6PDDefaultAddressesPerPrefix,
+	}
+
+	node = &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: nodeName,
+		},
+	}
+
+	cniNodeWithIPv6PD = &v1alpha1.CNINode{
+		ObjectMeta: metav1.ObjectMeta{Name: nodeName},
+		Spec: v1alpha1.CNINodeSpec{
+			Features: []v1alpha1.Feature{{Name: v1alpha1.IPv6PrefixDelegation}},
+		},
+	}
+
+	cniNodeWithoutIPv6PD = &v1alpha1.CNINode{
+		ObjectMeta: metav1.ObjectMeta{Name: nodeName},
+		Spec: v1alpha1.CNINodeSpec{
+			Features: []v1alpha1.Feature{{Name: v1alpha1.SecurityGroupsForPods}},
+		},
+	}
+)
+
+// TestIPv6PrefixProvider_getCapacity tests the number of prefixes that can be assigned to the primary network interface
+func TestIPv6PrefixProvider_getCapacity(t *testing.T) {
+	// IP(6) - 1(Primary) = 5
+	assert.Equal(t, 5, getCapacity(instanceType))
+	assert.Zero(t, getCapacity("x.large"))
+}
+
+// TestIPv6PrefixProvider_putInstanceProviderAndPool tests put stores the resource pool and eni into the cache and
+// delete removes it from the cache
+func TestIPv6PrefixProvider_putInstanceProviderAndPool(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPool := mock_pool.NewMockPool(ctrl)
+
+	prefixProvider := getMockIPv6PrefixProvider()
+	prefixProvider.putInstanceProviderAndPool(nodeName, mockPool, eniID, nodeCapacity)
+
+	result, found := prefixProvider.getInstanceProviderAndPool(nodeName)
+	assert.True(t, found)
+	assert.Equal(t, &ResourceProviderAndPool{resourcePool: mockPool, eniID: eniID, capacity: nodeCapacity}, result)
+
+	prefixProvider.deleteInstanceProviderAndPool(nodeName)
+	assert.NotContains(t, prefixProvider.instanceProviderAndPool, nodeName)
+}
+
+// TestIPv6PrefixProvider_InitResource tests that the pool is initialized with the IPv6 addresses used by running pods
+// and the rest of the addresses from the assigned prefixes are added to the warm pool
+func TestIPv6PrefixProvider_InitResource(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockInstance := mock_ec2.NewMockEC2Instance(ctrl)
+	mockEC2APIHelper := mock_api.NewMockEC2APIHelper(ctrl)
+	mockK8sWrapper := mock_k8s.NewMockK8sWrapper(ctrl)
+	mockPodAPI := mock_pod.NewMockPodClientAPIWrapper(ctrl)
+	mockWorker := mock_worker.NewMockWorker(ctrl)
+
+	prefixProvider := getMockIPv6PrefixProvider()
+	prefixProvider.apiWrapper = api.Wrapper{EC2API: mockEC2APIHelper, K8sAPI: mockK8sWrapper, PodAPI: mockPodAPI}
+	prefixProvider.workerPool = mockWorker
+	prefixProvider.config = ipv6WarmPoolConfig
+
+	ips, _ := utils.DeconstructIPv6AddressesFromPrefix(prefix1, ipv6WarmPoolConfig.AddressesPerPrefix)
+	pods := []v1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:         "pod-uid",
+			Annotations: map[string]string{config.ResourceNameIPv6Address: ips[0]},
+		},
+	}}
+
+	mockInstance.EXPECT().Name().Return(nodeName).AnyTimes()
+	mockInstance.EXPECT().InstanceID().Return(instanceID).AnyTimes()
+	mockInstance.EXPECT().Type().Return(instanceType).AnyTimes()
+	mockInstance.EXPECT().PrimaryNetworkInterfaceID().Return(eniID).AnyTimes()
+	mockEC2APIHelper.EXPECT().GetInstanceNetworkInterface(gomock.Any(), aws.String(instanceID)).Return(
+		[]ec2types.InstanceNetworkInterface{
+			{
+				NetworkInterfaceId: aws.String(eniID),
+				Ipv6Prefixes:       []ec2types.InstanceIpv6Prefix{{Ipv6Prefix: aws.String(prefix1)}},
+			},
+			{
+				NetworkInterfaceId: aws.String("eni-secondary"),
+				Ipv6Prefixes:       []ec2types.InstanceIpv6Prefix{{Ipv6Prefix: aws.String(prefix2)}},
+			},
+		}, nil)
+	mockPodAPI.EXPECT().GetRunningPodsOnNode(nodeName).Return(pods, nil)
+	mockWorker.EXPECT().SubmitJob(gomock.Any()).AnyTimes()
+
+	err := prefixProvider.InitResource(context.TODO(), mockInstance)
+	assert.NoError(t, err)
+
+	resourceProviderAndPool, found := prefixProvider.getInstanceProviderAndPool(nodeName)
+	assert.True(t, found)
+	assert.Equal(t, eniID, resourceProviderAndPool.eniID)
+	assert.Equal(t, getCapacity(instanceType)*ipv6WarmPoolConfig.AddressesPerPrefix, resourceProviderAndPool.capacity)
+	introspect := resourceProviderAndPool.resourcePool.Introspect()
+	assert.Equal(t, pool.Resource{GroupID: prefix1, ResourceID: ips[0]}, introspect.UsedResources["pod-uid"])
+	assert.Len(t, introspect.WarmResources[prefix1], ipv6WarmPoolConfig.AddressesPerPrefix-1)
+	assert.NotContains(t, introspect.WarmResources, prefix2)
+}
+
+// TestIPv6PrefixProvider_CreateIPv6PrefixAndUpdatePool tests if resources are created then the job object is updated
+// with the resources and the pool is updated
+func TestIPv6PrefixProvider_CreateIPv6PrefixAndUpdatePool(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEC2APIHelper := mock_api.NewMockEC2APIHelper(ctrl)
+	mockPool := mock_pool.NewMockPool(ctrl)
+	prefixProvider := getMockIPv6PrefixProvider()
+	prefixProvider.apiWrapper = api.Wrapper{EC2API: mockEC2APIHelper}
+	prefixProvider.putInstanceProviderAndPool(nodeName, mockPool, eniID, nodeCapacity)
+	createdResources := []string{prefix1, prefix2}
+
+	createJob := &worker.WarmPoolJob{
+		Operations:    worker.OperationCreate,
+		Resources:     []string{},
+		ResourceCount: 2,
+		NodeName:      nodeName,
+	}
+
+	mockEC2APIHelper.EXPECT().AssignIPv6PrefixesAndWaitTillReady(gomock.Any(), eniID, 2).Return(createdResources, nil)
+	mockPool.EXPECT().UpdatePool(&worker.WarmPoolJob{
+		Operations:    worker.OperationCreate,
+		Resources:     createdResources,
+		ResourceCount: 2,
+		NodeName:      nodeName,
+	}, true, true).Return(false)
+
+	prefixProvider.CreateIPv6PrefixAndUpdatePool(context.TODO(), createJob)
+}
+
+// TestIPv6PrefixProvider_CreateIPv6PrefixAndUpdatePool_Fail_InsufficientCidrBlocks tests that if the subnet has no
+// free cidr blocks then an event is sent and the pool is notified that prefixes are unavailable
+func TestIPv6PrefixProvider_CreateIPv6PrefixAndUpdatePool_Fail_InsufficientCidrBlocks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEC2APIHelper := mock_api.NewMockEC2APIHelper(ctrl)
+	mockK8sWrapper := mock_k8s.NewMockK8sWrapper(ctrl)
+	mockPool := mock_pool.NewMockPool(ctrl)
+	prefixProvider := getMockIPv6PrefixProvider()
+	prefixProvider.apiWrapper = api.Wrapper{EC2API: mockEC2APIHelper, K8sAPI: mockK8sWrapper}
+	prefixProvider.putInstanceProviderAndPool(nodeName, mockPool, eniID, nodeCapacity)
+
+	createJob := &worker.WarmPoolJob{
+		Operations:    worker.OperationCreate,
+		ResourceCount: 1,
+		NodeName:      nodeName,
+	}
+
+	mockEC2APIHelper.EXPECT().AssignIPv6PrefixesAndWaitTillReady(gomock.Any(), eniID, 1).Return(nil,
+		fmt.Errorf("InsufficientCidrBlocks: The specified subnet does not have enough free cidr blocks to satisfy the request. Status"))
+	mockPool.EXPECT().UpdatePool(&worker.WarmPoolJob{
+		Operations:    worker.OperationCreate,
+		ResourceCount: 1,
+		NodeName:      nodeName,
+	}, true, false).Return(false)
+	mockK8sWrapper.EXPECT().GetNode(nodeName).Return(node, nil).Times(1)
+	mockK8sWrapper.EXPECT().BroadcastEvent(node, utils.InsufficientCidrBlocksReason, utils.ErrInsufficientCidrBlocks.Error(),
+		v1.EventTypeWarning).Times(1)
+
+	prefixProvider.CreateIPv6PrefixAndUpdatePool(context.TODO(), createJob)
+}
+
+// TestIPv6PrefixProvider_DeleteIPv6PrefixAndUpdatePool tests job with empty resources is passed back on successful delete
+// and the original resources are passed back if the delete fails
+func TestIPv6PrefixProvider_DeleteIPv6PrefixAndUpdatePool(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEC2APIHelper := mock_api.NewMockEC2APIHelper(ctrl)
+	mockPool := mock_pool.NewMockPool(ctrl)
+	prefixProvider := getMockIPv6PrefixProvider()
+	prefixProvider.apiWrapper = api.Wrapper{EC2API: mockEC2APIHelper}
+	prefixProvider.putInstanceProviderAndPool(nodeName, mockPool, eniID, nodeCapacity)
+	resourcesToDelete := []string{prefix1, prefix2}
+
+	mockEC2APIHelper.EXPECT().UnassignIPv6Prefixes(gomock.Any(), eniID, resourcesToDelete).Return(nil)
+	mockPool.EXPECT().UpdatePool(&worker.WarmPoolJob{
+		Operations: worker.OperationDeleted,
+		NodeName:   nodeName,
+	}, true, true).Return(false)
+	prefixProvider.DeleteIPv6PrefixAndUpdatePool(context.TODO(), &worker.WarmPoolJob{
+		Operations: worker.OperationDeleted,
+		Resources:  resourcesToDelete,
+		NodeName:   nodeName,
+	})
+
+	mockEC2APIHelper.EXPECT().UnassignIPv6Prefixes(gomock.Any(), eniID, resourcesToDelete).Return(fmt.Errorf("failed"))
+	mockPool.EXPECT().UpdatePool(&worker.WarmPoolJob{
+		Operations: worker.OperationDeleted,
+		Resources:  resourcesToDelete,
+		NodeName:   nodeName,
+	}, false, true).Return(false)
+	prefixProvider.DeleteIPv6PrefixAndUpdatePool(context.TODO(), &worker.WarmPoolJob{
+		Operations: worker.OperationDeleted,
+		Resources:  resourcesToDelete,
+		NodeName:   nodeName,
+	})
+}
+
+// TestIPv6PrefixProvider_ReSyncPool tests the pool is re-synced with the prefixes on the network interface
+func TestIPv6PrefixProvider_ReSyncPool(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEC2APIHelper := mock_api.NewMockEC2APIHelper(ctrl)
+	mockPool := mock_pool.NewMockPool(ctrl)
+	prefixProvider := getMockIPv6PrefixProvider()
+	prefixProvider.apiWrapper = api.Wrapper{EC2API: mockEC2APIHelper}
+	prefixProvider.putInstanceProviderAndPool(nodeName, mockPool, eniID, nodeCapacity)
+
+	reSyncJob := &worker.WarmPoolJob{
+		Operations: worker.OperationReSyncPool,
+		NodeName:   nodeName,
+	}
+
+	// When error occurs, pool should not be re-synced
+	mockEC2APIHelper.EXPECT().DescribeNetworkInterfaces(gomock.Any(), []string{eniID}).Return(nil, fmt.Errorf("failed"))
+	prefixProvider.ReSyncPool(context.TODO(), reSyncJob)
+
+	// When no error occurs, pool should be re-synced
+	mockEC2APIHelper.EXPECT().DescribeNetworkInterfaces(gomock.Any(), []string{eniID}).Return([]ec2types.NetworkInterface{{
+		Ipv6Prefixes: []ec2types.Ipv6PrefixSpecification{{Ipv6Prefix: aws.String(prefix1)}, {Ipv6Prefix: aws.String(prefix2)}},
+	}}, nil)
+	mockPool.EXPECT().ReSync([]string{prefix1, prefix2})
+	prefixProvider.ReSyncPool(context.TODO(), reSyncJob)
+}
+
+// TestIPv6PrefixProvider_UpdateResourceCapacity tests the capacity is advertised for an initialized node
+func TestIPv6PrefixProvider_UpdateResourceCapacity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockInstance := mock_ec2.NewMockEC2Instance(ctrl)
+	mockK8sWrapper := mock_k8s.NewMockK8sWrapper(ctrl)
+	mockPool := mock_pool.NewMockPool(ctrl)
+	prefixProvider := getMockIPv6PrefixProvider()
+	prefixProvider.apiWrapper = api.Wrapper{K8sAPI: mockK8sWrapper}
+	prefixProvider.putInstanceProviderAndPool(nodeName, mockPool, eniID, nodeCapacity)
+
+	mockInstance.EXPECT().Name().Return(nodeName).AnyTimes()
+	mockInstance.EXPECT().Type().Return(instanceType).AnyTimes()
+	mockInstance.EXPECT().Os().Return(config.OSLinux).AnyTimes()
+	mockK8sWrapper.EXPECT().AdvertiseCapacityIfNotSet(nodeName, config.ResourceNameIPv6Address, nodeCapacity).Return(nil)
+
+	err := prefixProvider.UpdateResourceCapacity(context.TODO(), mockInstance)
+	assert.NoError(t, err)
+}
+
+// TestIPv6PrefixProvider_Introspect tests the introspect response of the pool is returned
+func TestIPv6PrefixProvider_Introspect(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	prefixProvider := getMockIPv6PrefixProvider()
+	mockPool := mock_pool.NewMockPool(ctrl)
+	prefixProvider.putInstanceProviderAndPool(nodeName, mockPool, eniID, nodeCapacity)
+	expectedResp := pool.IntrospectResponse{}
+
+	mockPool.EXPECT().Introspect().Return(expectedResp)
+	resp := prefixProvider.Introspect()
+	assert.True(t, reflect.DeepEqual(resp, map[string]pool.IntrospectResponse{nodeName: expectedResp}))
+
+	mockPool.EXPECT().Introspect().Return(expectedResp)
+	resp = prefixProvider.IntrospectNode(nodeName)
+	assert.Equal(t, resp, expectedResp)
+
+	resp = prefixProvider.IntrospectNode("unregistered-node")
+	assert.Equal(t, resp, struct{}{})
+}
+
+// TestIsInstanceSupported tests that the instance is supported only if the CNINode has the IPv6 prefix delegation
+// feature and the instance type is nitro
+func TestIsInstanceSupported(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockInstance := mock_ec2.NewMockEC2Instance(ctrl)
+	mockK8sWrapper := mock_k8s.NewMockK8sWrapper(ctrl)
+	prefixProvider := getMockIPv6PrefixProvider()
+	prefixProvider.apiWrapper = api.Wrapper{K8sAPI: mockK8sWrapper}
+	mockInstance.EXPECT().Name().Return(nodeName).AnyTimes()
+
+	// Feature enabled on a nitro instance
+	mockK8sWrapper.EXPECT().GetCNINode(types.NamespacedName{Name: nodeName}).Return(cniNodeWithIPv6PD, nil)
+	mockInstance.EXPECT().Type().Return(instanceType)
+	assert.True(t, prefixProvider.IsInstanceSupported(mockInstance))
+
+	// Feature not enabled
+	mockK8sWrapper.EXPECT().GetCNINode(types.NamespacedName{Name: nodeName}).Return(cniNodeWithoutIPv6PD, nil)
+	assert.False(t, prefixProvider.IsInstanceSupported(mockInstance))
+
+	// CNINode not found
+	mockK8sWrapper.EXPECT().GetCNINode(types.NamespacedName{Name: nodeName}).Return(nil, fmt.Errorf("not found"))
+	assert.False(t, prefixProvider.IsInstanceSupported(mockInstance))
+
+	// Feature enabled on a non nitro instance
+	mockK8sWrapper.EXPECT().GetCNINode(types.NamespacedName{Name: nodeName}).Return(cniNodeWithIPv6PD, nil)
+	mockInstance.EXPECT().Type().Return(nonNitroInstanceType).AnyTimes()
+	mockK8sWrapper.EXPECT().GetNode(nodeName).Return(node, nil).Times(1)
+	mockK8sWrapper.EXPECT().BroadcastEvent(node, utils.UnsupportedInstanceTypeReason, gomock.Any(), v1.EventTypeWarning).Times(1)
+	assert.False(t, prefixProvider.IsInstanceSupported(mockInstance))
+}
+
+func getMockIPv6PrefixProvider() *ipv6PrefixProvider {
+	return &ipv6PrefixProvider{instanceProviderAndPool: map[string]*ResourceProviderAndPool{},
+		log: zap.New(zap.UseDevMode(true)).WithName("ipv6 prefix provider")}
+}
@@ -14,18 +14,23 @@
 package prefix
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"net/http"
+	"slices"
 	"strings"
 	"sync"
 
+	"github.com/aws/amazon-vpc-resource-controller-k8s/apis/vpcresources/v1alpha1"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/api"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/ec2"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/vpc"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/condition"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
 	rcHealthz "github.com/aws/amazon-vpc-resource-controller-k8s/pkg/healthz"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/lookup"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/pool"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider/ip"
@@ -54,6 +59,8 @@ type ipv4PrefixProvider struct {
 	instanceProviderAndPool map[string]*ResourceProviderAndPool
 	// conditions is used to check which IP allocation mode is enabled
 	conditions condition.Conditions
+	// index records the pods the resources are assigned to for the lookup of the introspection API
+	index *lookup.Index
 	// healthz check subpath
 	checker healthz.Checker
 }
@@ -71,7 +78,7 @@ type ResourceProviderAndPool struct {
 }
 
 func NewIPv4PrefixProvider(log logr.Logger, apiWrapper api.Wrapper, workerPool worker.Worker,
-	resourceConfig config.ResourceConfig, conditions condition.Conditions) provider.ResourceProvider {
+	resourceConfig config.ResourceConfig, conditions condition.Conditions, index *lookup.Index) provider.ResourceProvider {
 	provider := &ipv4PrefixProvider{
 		instanceProviderAndPool: make(map[string]*ResourceProviderAndPool),
 		config:                  resourceConfig.WarmPoolConfig,
@@ -79,16 +86,28 @@ func NewIPv4PrefixProvider(log logr.Logger, apiWrapper api.Wrapper, workerPool w
 		apiWrapper:              apiWrapper,
 		workerPool:              workerPool,
 		conditions:              conditions,
+		index:                   index,
 	}
 	provider.checker = provider.check()
 	return provider
 }
 
-func (p *ipv4PrefixProvider) InitResource(instance ec2.EC2Instance) error {
+func (p *ipv4PrefixProvider) InitResource(ctx context.Context, instance ec2.EC2Instance) error {
 	nodeName := instance.Name()
 
-	eniManager := eni.NewENIManager(instance)
-	ipV4Resources, err := eniManager.InitResources(p.apiWrapper.EC2API)
+	// The trunk interface is attached to Windows nodes when security groups for pods is enabled
+	eniManager := eni.NewENIManager(instance, p.conditions.IsWindowsSecurityGroupsForPodsEnabled())
+	// Initialize the ENIs from the checkpoint of the previous controller if present, the pool is verified against EC2
+	// on its first reconcile
+	poolCheckpoint := pool.GetIPv4PoolCheckpoint(p.log, p.apiWrapper, nodeName, instance.InstanceID(),
+		config.ResourceNameIPAddressFromPrefix)
+	var ipV4Resources *eni.IPv4Resource
+	var err error
+	if poolCheckpoint != nil {
+		ipV4Resources = eniManager.InitResourcesFromCheckpoint(poolCheckpoint.ENIs)
+	} else {
+		ipV4Resources, err = eniManager.InitResources(ctx, p.apiWrapper.EC2API)
+	}
 	if err != nil || ipV4Resources == nil {
 		if errors.Is(err, utils.ErrNotFound) {
 			msg := fmt.Sprintf("The instance type %s is not supported for Windows", instance.Type())
@@ -153,34 +172,43 @@ func (p *ipv4PrefixProvider) InitResource(instance ec2.EC2Instance) error {
 	}
 
 	// Expected node capacity based on instance type in PD mode
-	nodeCapacity := getCapacity(instance.Type(), instance.Os()) * pool.NumIPv4AddrPerPrefix
+	nodeCapacity := getCapacity(instance.Type(), eniManager.HasTrunk()) * pool.NumIPv4AddrPerPrefix
 
 	isPDEnabled := p.conditions.IsWindowsPrefixDelegationEnabled()
-	p.config = pool.GetWinWarmPoolConfig(p.log, p.apiWrapper, isPDEnabled)
+	// The node's warm pool config is kept local since p.config is shared by the concurrent node initializations
+	warmPoolConfig := pool.GetNodeWarmPoolConfig(p.log, p.apiWrapper, instance.Name(), isPDEnabled)
 
 	// Set warm pool config to empty if PD is not enabled
-	prefixIPWPConfig := p.config
+	prefixIPWPConfig := warmPoolConfig
 	if !isPDEnabled {
 		prefixIPWPConfig = &config.WarmPoolConfig{}
 	} else {
 		// Log the discrepancy between the advertised and the actual node capacity when it is in PD mode
 		if numberUsedSecondaryIP > 0 {
-			actualCapacity := (getCapacity(instance.Type(), instance.Os()) - numberUsedSecondaryIP) * pool.NumIPv4AddrPerPrefix
+			actualCapacity := (getCapacity(instance.Type(), eniManager.HasTrunk()) - numberUsedSecondaryIP) * pool.NumIPv4AddrPerPrefix
 			p.log.Info("there could be discrepancy between advertised and actual node capacity due to existing pods from "+
 				"secondary IP mode", "node name", instance.Name(), "advertised capacity", nodeCapacity,
 				"actual capacity", actualCapacity)
 		}
 	}
 
-	resourcePool := pool.NewResourcePool(p.log.WithName("prefix ipv4 address resource pool").
-		WithValues("node name", instance.Name()), prefixIPWPConfig, podToResourceMap,
-		warmResources, instance.Name(), nodeCapacity, true)
+	poolLog := p.log.WithName("prefix ipv4 address resource pool").WithValues("node name", instance.Name())
+	var resourcePool pool.Pool
+	if poolCheckpoint != nil {
+		resourcePool = pool.NewResourcePoolFromCheckpoint(poolLog, prefixIPWPConfig, podToResourceMap, warmResources,
+			pool.GetCheckpointCoolingResources(*poolCheckpoint, podToResourceMap), instance.Name(), nodeCapacity, true,
+			p.index)
+	} else {
+		resourcePool = pool.NewResourcePool(poolLog, prefixIPWPConfig, podToResourceMap, warmResources, instance.Name(),
+			nodeCapacity, true, p.index)
+	}
 
 	p.putInstanceProviderAndPool(nodeName, resourcePool, eniManager, nodeCapacity, isPDEnabled)
 
 	p.log.Info("initialized the resource provider for ipv4 prefix",
 		"capacity", nodeCapacity, "node name", nodeName, "instance type",
-		instance.Type(), "instance ID", instance.InstanceID(), "warmPoolConfig", p.config)
+		instance.Type(), "instance ID", instance.InstanceID(), "warmPoolConfig", warmPoolConfig,
+		"from checkpoint", poolCheckpoint != nil)
 
 	job := resourcePool.ReconcilePool()
 	if job.Operations != worker.OperationReconcileNotRequired {
@@ -195,11 +223,12 @@ func (p *ipv4PrefixProvider) InitResource(instance ec2.EC2Instance) error {
 func (p *ipv4PrefixProvider) DeInitResource(instance ec2.EC2Instance) error {
 	nodeName := instance.Name()
 	p.deleteInstanceProviderAndPool(nodeName)
+	p.index.RemoveNode(nodeName)
 
 	return nil
 }
 
-func (p *ipv4PrefixProvider) UpdateResourceCapacity(instance ec2.EC2Instance) error {
+func (p *ipv4PrefixProvider) UpdateResourceCapacity(ctx context.Context, instance ec2.EC2Instance) error {
 	resourceProviderAndPool, isPresent := p.getInstanceProviderAndPool(instance.Name())
 	if !isPresent {
 		p.log.Error(utils.ErrNotFound, utils.ErrMsgProviderAndPoolNotFound, "node name", instance.Name())
@@ -234,7 +263,7 @@ func (p *ipv4PrefixProvider) UpdateResourceCapacity(instance ec2.EC2Instance) er
 
 	resourceProviderAndPool.isPrevPDEnabled = true
 
-	warmPoolConfig := pool.GetWinWarmPoolConfig(p.log, p.apiWrapper, isCurrPDEnabled)
+	warmPoolConfig := pool.GetNodeWarmPoolConfig(p.log, p.apiWrapper, instance.Name(), isCurrPDEnabled)
 
 	// Set the secondary IP provider pool state to active
 	job := resourceProviderAndPool.resourcePool.SetToActive(warmPoolConfig)
@@ -263,7 +292,12 @@ func (p *ipv4PrefixProvider) SubmitAsyncJob(job interface{}) {
 	p.workerPool.SubmitJob(job)
 }
 
-func (p *ipv4PrefixProvider) ProcessAsyncJob(job interface{}) (ctrl.Result, error) {
+// SubmitAsyncJobWithContext submits the job to the worker pool, the job continues the trace of the context
+func (p *ipv4PrefixProvider) SubmitAsyncJobWithContext(ctx context.Context, job interface{}) {
+	p.workerPool.SubmitJobWithContext(ctx, job)
+}
+
+func (p *ipv4PrefixProvider) ProcessAsyncJob(ctx context.Context, job interface{}) (ctrl.Result, error) {
 	warmPoolJob, isValid := job.(*worker.WarmPoolJob)
 	if !isValid {
 		return ctrl.Result{}, fmt.Errorf("invalid job type")
@@ -271,11 +305,11 @@ func (p *ipv4PrefixProvider) ProcessAsyncJob(job interface{}) (ctrl.Result, erro
 
 	switch warmPoolJob.Operations {
 	case worker.OperationCreate:
-		p.CreateIPv4PrefixAndUpdatePool(warmPoolJob)
+		p.CreateIPv4PrefixAndUpdatePool(ctx, warmPoolJob)
 	case worker.OperationDeleted:
-		p.DeleteIPv4PrefixAndUpdatePool(warmPoolJob)
+		p.DeleteIPv4PrefixAndUpdatePool(ctx, warmPoolJob)
 	case worker.OperationReSyncPool:
-		p.ReSyncPool(warmPoolJob)
+		p.ReSyncPool(ctx, warmPoolJob)
 	case worker.OperationProcessDeleteQueue:
 		return p.ProcessDeleteQueue(warmPoolJob)
 	}
@@ -285,7 +319,7 @@ func (p *ipv4PrefixProvider) ProcessAsyncJob(job interface{}) (ctrl.Result, erro
 
 // CreateIPv4PrefixAndUpdatePool executes the Create IPv4 Prefix workflow by assigning enough prefixes to satisfy
 // the desired number of prefixes required by the warm pool job
-func (p *ipv4PrefixProvider) CreateIPv4PrefixAndUpdatePool(job *worker.WarmPoolJob) {
+func (p *ipv4PrefixProvider) CreateIPv4PrefixAndUpdatePool(ctx context.Context, job *worker.WarmPoolJob) {
 	instanceResource, found := p.getInstanceProviderAndPool(job.NodeName)
 	if !found {
 		p.log.Error(utils.ErrNotFound, utils.ErrMsgProviderAndPoolNotFound, "node name", job.NodeName)
@@ -297,7 +331,7 @@ func (p *ipv4PrefixProvider) CreateIPv4PrefixAndUpdatePool(job *worker.WarmPoolJ
 	// If subnet has sufficient cidr blocks, prefixAvailable is true, otherwise false.
 	prefixAvailable := true
 
-	resources, err := instanceResource.eniManager.CreateIPV4Resource(job.ResourceCount, config.ResourceTypeIPv4Prefix, p.apiWrapper.EC2API,
+	resources, err := instanceResource.eniManager.CreateIPV4Resource(ctx, job.ResourceCount, config.ResourceTypeIPv4Prefix, p.apiWrapper.EC2API,
 		p.log)
 
 	if err != nil {
@@ -322,7 +356,7 @@ func (p *ipv4PrefixProvider) CreateIPv4PrefixAndUpdatePool(job *worker.WarmPoolJ
 }
 
 // DeleteIPv4PrefixAndUpdatePool executes the Delete IPv4 Prefix workflow for the list of prefixes provided in the warm pool job
-func (p *ipv4PrefixProvider) DeleteIPv4PrefixAndUpdatePool(job *worker.WarmPoolJob) {
+func (p *ipv4PrefixProvider) DeleteIPv4PrefixAndUpdatePool(ctx context.Context, job *worker.WarmPoolJob) {
 	instanceResource, found := p.getInstanceProviderAndPool(job.NodeName)
 	if !found {
 		p.log.Error(utils.ErrNotFound, utils.ErrMsgProviderAndPoolNotFound, "node name", job.NodeName)
@@ -330,7 +364,7 @@ func (p *ipv4PrefixProvider) DeleteIPv4PrefixAndUpdatePool(job *worker.WarmPoolJ
 	}
 
 	didSucceed := true
-	failedResources, err := instanceResource.eniManager.DeleteIPV4Resource(job.Resources, config.ResourceTypeIPv4Prefix,
+	failedResources, err := instanceResource.eniManager.DeleteIPV4Resource(ctx, job.Resources, config.ResourceTypeIPv4Prefix,
 		p.apiWrapper.EC2API, p.log)
 
 	if err != nil {
@@ -341,14 +375,14 @@ func (p *ipv4PrefixProvider) DeleteIPv4PrefixAndUpdatePool(job *worker.WarmPoolJ
 	p.updatePoolAndReconcileIfRequired(instanceResource.resourcePool, job, didSucceed, true)
 }
 
-func (p *ipv4PrefixProvider) ReSyncPool(job *worker.WarmPoolJob) {
+func (p *ipv4PrefixProvider) ReSyncPool(ctx context.Context, job *worker.WarmPoolJob) {
 	providerAndPool, found := p.instanceProviderAndPool[job.NodeName]
 	if !found {
 		p.log.Error(utils.ErrNotFound, "node is not initialized", "node name", job.NodeName)
 		return
 	}
 
-	ipV4Resources, err := providerAndPool.eniManager.InitResources(p.apiWrapper.EC2API)
+	ipV4Resources, err := providerAndPool.eniManager.InitResources(ctx, p.apiWrapper.EC2API)
 	if err != nil || ipV4Resources == nil {
 		p.log.Error(err, "failed to get init resources for the node", "node name", job.NodeName)
 		return
@@ -458,6 +492,26 @@ func (p *ipv4PrefixProvider) IntrospectNode(node string) interface{} {
 	return resource.resourcePool.Introspect()
 }
 
+// IntrospectNodeNames returns the names of the nodes with the resources of the provider
+func (p *ipv4PrefixProvider) IntrospectNodeNames() []string {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	return slices.Collect(maps.Keys(p.instanceProviderAndPool))
+}
+
+// IntrospectNodeSummary returns the resources summary of the node
+func (p *ipv4PrefixProvider) IntrospectNodeSummary(nodeName string) interface{} {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	resource, found := p.instanceProviderAndPool[nodeName]
+	if !found {
+		return struct{}{}
+	}
+	return ip.ChangeToIntrospectSummary(resource.resourcePool.Introspect())
+}
+
 // putInstanceProviderAndPool stores the node's instance provider and pool to the cache
 func (p *ipv4PrefixProvider) putInstanceProviderAndPool(nodeName string, resourcePool pool.Pool, manager eni.ENIManager, capacity int,
 	isPrevPDEnabled bool) {
@@ -491,21 +545,34 @@ func (p *ipv4PrefixProvider) deleteInstanceProviderAndPool(nodeName string) {
 	delete(p.instanceProviderAndPool, nodeName)
 }
 
-// getCapacity returns the capacity for IPv4 addresses deconstructed from IPv4 prefixes based on the instance type and the instance os;
-func getCapacity(instanceType string, instanceOs string) int {
-	// Assign only 1st ENIs non-primary IP
+// getCapacity returns the capacity for IPv4 addresses deconstructed from IPv4 prefixes based on the instance type as the
+// sum of the non-primary IPs across all the ENIs, excluding the trunk interface if the instance has one
+func getCapacity(instanceType string, hasTrunk bool) int {
 	limits, found := vpc.Limits[instanceType]
 	if !found {
 		return 0
 	}
-	var capacity int
-	if instanceOs == config.OSWindows {
-		capacity = limits.IPv4PerInterface - 1
-	} else {
-		capacity = (limits.IPv4PerInterface - 1) * limits.Interface
+	interfaces := limits.Interface
+	if hasTrunk {
+		interfaces--
 	}
+	return (limits.IPv4PerInterface - 1) * interfaces
+}
 
-	return capacity
+// CheckpointNode adds the state of the node's pool and the ENIs the pool's resources are assigned to the checkpoint
+func (p *ipv4PrefixProvider) CheckpointNode(nodeName string, checkpoint *v1alpha1.CNINodeCheckpoint) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	resource, found := p.instanceProviderAndPool[nodeName]
+	if !found {
+		return
+	}
+	if checkpoint.IPv4Pools == nil {
+		checkpoint.IPv4Pools = make(map[string]v1alpha1.IPv4PoolCheckpoint)
+	}
+	checkpoint.IPv4Pools[config.ResourceNameIPAddressFromPrefix] = pool.NewIPv4PoolCheckpoint(resource.resourcePool.Introspect(),
+		resource.eniManager.Checkpoint())
 }
 
 func (p *ipv4PrefixProvider) check() healthz.Checker {
@@ -14,6 +14,7 @@
 package prefix
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -21,6 +22,7 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/aws/amazon-vpc-resource-controller-k8s/apis/vpcresources/v1alpha1"
 	mock_ec2 "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/aws/ec2"
 	mock_condition "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/condition"
 	mock_k8s "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/k8s"
@@ -37,6 +39,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
 
@@ -86,17 +89,16 @@ var (
 	}
 )
 
-// TestNewIPv4PrefixProvider_getCapacity tests capacity of different os type
+// TestNewIPv4PrefixProvider_getCapacity tests capacity is summed across all the ENIs of the instance type
 func TestNewIPv4PrefixProvider_getCapacity(t *testing.T) {
-	capacityLinux := getCapacity(instanceType, config.OSLinux)
-	capacityWindows := getCapacity(instanceType, config.OSWindows)
-	capacityUnknown := getCapacity("x.large", "linux")
+	capacity := getCapacity(instanceType, false)
+	capacityUnknown := getCapacity("x.large", false)
 
 	assert.Zero(t, capacityUnknown)
-	// IP(6) - 1(Primary) = 5
-	assert.Equal(t, 5, capacityWindows)
 	// (IP(6) - 1(Primary)) * 3(ENI) = 15
-	assert.Equal(t, 15, capacityLinux)
+	assert.Equal(t, 15, capacity)
+	// (IP(6) - 1(Primary)) * (3(ENI) - 1(Trunk)) = 10
+	assert.Equal(t, 10, getCapacity(instanceType, true))
 }
 
 // TestNewIPv4PrefixProvider_deleteInstanceProviderAndPool tests that the ResourcePoolAndProvider for given node is removed from
@@ -189,7 +191,7 @@ func TestIpv4PrefixProvider_DeleteIPv4PrefixAndUpdatePool(t *testing.T) {
 		NodeName:      nodeName,
 	}
 
-	mockManager.EXPECT().DeleteIPV4Resource(resourcesToDelete, config.ResourceTypeIPv4Prefix, nil, gomock.Any()).Return([]string{}, nil)
+	mockManager.EXPECT().DeleteIPV4Resource(gomock.Any(), resourcesToDelete, config.ResourceTypeIPv4Prefix, nil, gomock.Any()).Return([]string{}, nil)
 	mockPool.EXPECT().UpdatePool(&worker.WarmPoolJob{
 		Operations:    worker.OperationDeleted,
 		Resources:     []string{},
@@ -197,7 +199,7 @@ func TestIpv4PrefixProvider_DeleteIPv4PrefixAndUpdatePool(t *testing.T) {
 		NodeName:      nodeName,
 	}, true, true).Return(false)
 
-	provider.DeleteIPv4PrefixAndUpdatePool(deleteJob)
+	provider.DeleteIPv4PrefixAndUpdatePool(context.TODO(), deleteJob)
 }
 
 // TestIpv4PrefixProvider_DeletePrivateIPv4AndUpdatePool_SomeResourceFail tests if some resource fail to delete those resources
@@ -220,7 +222,7 @@ func TestIpv4PrefixProvider_DeletePrivateIPv4AndUpdatePool_SomeResourceFail(t *t
 		NodeName:      nodeName,
 	}
 
-	mockManager.EXPECT().DeleteIPV4Resource(resourcesToDelete, config.ResourceTypeIPv4Prefix, nil, gomock.Any()).Return(failedResources, nil)
+	mockManager.EXPECT().DeleteIPV4Resource(gomock.Any(), resourcesToDelete, config.ResourceTypeIPv4Prefix, nil, gomock.Any()).Return(failedResources, nil)
 	mockPool.EXPECT().UpdatePool(&worker.WarmPoolJob{
 		Operations:    worker.OperationDeleted,
 		Resources:     failedResources,
@@ -228,7 +230,7 @@ func TestIpv4PrefixProvider_DeletePrivateIPv4AndUpdatePool_SomeResourceFail(t *t
 		NodeName:      nodeName,
 	}, true, true).Return(false)
 
-	prefixProvider.DeleteIPv4PrefixAndUpdatePool(&deleteJob)
+	prefixProvider.DeleteIPv4PrefixAndUpdatePool(context.TODO(), &deleteJob)
 }
 
 // TestIPv4PrefixProvider_CreateIPv4PrefixAndUpdatePool tests if resources are created then the job object is updated
@@ -250,7 +252,7 @@ func TestIPv4PrefixProvider_CreateIPv4PrefixAndUpdatePool(t *testing.T) {
 		NodeName:      nodeName,
 	}
 
-	mockManager.EXPECT().CreateIPV4Resource(2, config.ResourceTypeIPv4Prefix, nil, gomock.Any()).Return(createdResources, nil)
+	mockManager.EXPECT().CreateIPV4Resource(gomock.Any(), 2, config.ResourceTypeIPv4Prefix, nil, gomock.Any()).Return(createdResources, nil)
 	mockPool.EXPECT().UpdatePool(&worker.WarmPoolJob{
 		Operations:    worker.OperationCreate,
 		Resources:     createdResources,
@@ -258,7 +260,7 @@ func TestIPv4PrefixProvider_CreateIPv4PrefixAndUpdatePool(t *testing.T) {
 		NodeName:      nodeName,
 	}, true, true).Return(false)
 
-	prefixProvider.CreateIPv4PrefixAndUpdatePool(createJob)
+	prefixProvider.CreateIPv4PrefixAndUpdatePool(context.TODO(), createJob)
 }
 
 // TestIPv4PrefixProvider_CreateIPv4PrefixAndUpdatePool_Fail tests that if some create fails then the pool is
@@ -282,7 +284,7 @@ func TestIPv4PrefixProvider_CreateIPv4PrefixAndUpdatePool_Fail(t *testing.T) {
 		NodeName:      nodeName,
 	}
 
-	mockManager.EXPECT().CreateIPV4Resource(3, config.ResourceTypeIPv4Prefix, nil, gomock.Any()).Return(createdResources,
+	mockManager.EXPECT().CreateIPV4Resource(gomock.Any(), 3, config.ResourceTypeIPv4Prefix, nil, gomock.Any()).Return(createdResources,
 		fmt.Errorf("failed"))
 	mockPool.EXPECT().UpdatePool(&worker.WarmPoolJob{
 		Operations:    worker.OperationCreate,
@@ -295,7 +297,7 @@ func TestIPv4PrefixProvider_CreateIPv4PrefixAndUpdatePool_Fail(t *testing.T) {
 	mockPool.EXPECT().ReconcilePool().Return(job)
 	mockWorker.EXPECT().SubmitJob(job)
 
-	prefixProvider.CreateIPv4PrefixAndUpdatePool(createJob)
+	prefixProvider.CreateIPv4PrefixAndUpdatePool(context.TODO(), createJob)
 }
 
 // TestIPv4PrefixProvider_CreateIPv4PrefixAndUpdatePool_Fail_InsufficientCidrBlocks tests that if some create fails then the pool is
@@ -322,7 +324,7 @@ func TestIPv4PrefixProvider_CreateIPv4PrefixAndUpdatePool_Fail_InsufficientCidrB
 		NodeName:      nodeName,
 	}
 
-	mockManager.EXPECT().CreateIPV4Resource(3, config.ResourceTypeIPv4Prefix, nil, gomock.Any()).Return(createdResources,
+	mockManager.EXPECT().CreateIPV4Resource(gomock.Any(), 3, config.ResourceTypeIPv4Prefix, nil, gomock.Any()).Return(createdResources,
 		fmt.Errorf("InsufficientCidrBlocks: The specified subnet does not have enough free cidr blocks to satisfy the request. Status"))
 
 	// Since InsufficientCidrBlocks error is not retryable, didSucceed was not set as false to not re-sync or reconcile the pool.
@@ -336,7 +338,7 @@ func TestIPv4PrefixProvider_CreateIPv4PrefixAndUpdatePool_Fail_InsufficientCidrB
 
 	mockK8sWrapper.EXPECT().GetNode(nodeName).Return(node, nil).Times(1)
 	mockK8sWrapper.EXPECT().BroadcastEvent(node, utils.InsufficientCidrBlocksReason, utils.ErrInsufficientCidrBlocks.Error(), v1.EventTypeWarning).Times(1)
-	prefixProvider.CreateIPv4PrefixAndUpdatePool(createJob)
+	prefixProvider.CreateIPv4PrefixAndUpdatePool(context.TODO(), createJob)
 }
 
 // TestIPv4PrefixProvider_ReSyncPool
@@ -356,14 +358,14 @@ func TestIPv4PrefixProvider_ReSyncPool(t *testing.T) {
 	}
 
 	// When error occurs, pool should not be re-synced
-	mockManager.EXPECT().InitResources(prefixProvider.apiWrapper.EC2API).Return(nil, fmt.Errorf(""))
-	prefixProvider.ReSyncPool(reSyncJob)
+	mockManager.EXPECT().InitResources(gomock.Any(), prefixProvider.apiWrapper.EC2API).Return(nil, fmt.Errorf(""))
+	prefixProvider.ReSyncPool(context.TODO(), reSyncJob)
 
 	// When no error occurs, pool should be re-synced
 	ipV4Resources := &eni.IPv4Resource{IPv4Prefixes: resources}
-	mockManager.EXPECT().InitResources(prefixProvider.apiWrapper.EC2API).Return(ipV4Resources, nil)
+	mockManager.EXPECT().InitResources(gomock.Any(), prefixProvider.apiWrapper.EC2API).Return(ipV4Resources, nil)
 	mockPool.EXPECT().ReSync(resources)
-	prefixProvider.ReSyncPool(reSyncJob)
+	prefixProvider.ReSyncPool(context.TODO(), reSyncJob)
 }
 
 // TestIPv4PrefixProvider_SubmitAsyncJob tests that the job is submitted to the worker on calling SubmitAsyncJob
@@ -399,6 +401,7 @@ func TestIPv4PrefixProvider_UpdateResourceCapacity_FromFromIPToPD(t *testing.T)
 
 	for _, c := range []*v1.ConfigMap{vpcCNIConfig, vpcCNIConfigWindows} {
 		mockK8sWrapper.EXPECT().GetConfigMap(config.VpcCniConfigMapName, config.KubeSystemNamespace).Return(c, nil)
+		mockK8sWrapper.EXPECT().GetCNINode(types.NamespacedName{Name: nodeName}).Return(&v1alpha1.CNINode{}, nil)
 		mockPool := mock_pool.NewMockPool(ctrl)
 		mockManager := mock_eni.NewMockENIManager(ctrl)
 		prefixProvider.putInstanceProviderAndPool(nodeName, mockPool, mockManager, nodeCapacity, true)
@@ -408,12 +411,12 @@ func TestIPv4PrefixProvider_UpdateResourceCapacity_FromFromIPToPD(t *testing.T)
 		mockPool.EXPECT().SetToActive(pdWarmPoolConfig).Return(job)
 		mockWorker.EXPECT().SubmitJob(job)
 
-		mockInstance.EXPECT().Name().Return(nodeName).Times(2)
+		mockInstance.EXPECT().Name().Return(nodeName).Times(3)
 		mockInstance.EXPECT().Type().Return(instanceType)
 		mockInstance.EXPECT().Os().Return(config.OSWindows)
 		mockK8sWrapper.EXPECT().AdvertiseCapacityIfNotSet(nodeName, config.ResourceNameIPAddress, 224).Return(nil)
 
-		err := prefixProvider.UpdateResourceCapacity(mockInstance)
+		err := prefixProvider.UpdateResourceCapacity(context.TODO(), mockInstance)
 		assert.NoError(t, err)
 	}
 }
@@ -441,7 +444,7 @@ func TestIPv4PrefixProvider_UpdateResourceCapacity_FromFromPDToIP(t *testing.T)
 	mockWorker.EXPECT().SubmitJob(job)
 	mockInstance.EXPECT().Name().Return(nodeName).Times(1)
 
-	err := prefixProvider.UpdateResourceCapacity(mockInstance)
+	err := prefixProvider.UpdateResourceCapacity(context.TODO(), mockInstance)
 	assert.NoError(t, err)
 }
 
@@ -466,17 +469,18 @@ func TestIPv4PrefixProvider_UpdateResourceCapacity_FromPDToPD(t *testing.T) {
 	for _, c := range []*v1.ConfigMap{vpcCNIConfig, vpcCNIConfigWindows} {
 		mockConditions.EXPECT().IsWindowsPrefixDelegationEnabled().Return(true)
 		mockK8sWrapper.EXPECT().GetConfigMap(config.VpcCniConfigMapName, config.KubeSystemNamespace).Return(c, nil)
+		mockK8sWrapper.EXPECT().GetCNINode(types.NamespacedName{Name: nodeName}).Return(&v1alpha1.CNINode{}, nil)
 
 		job := &worker.WarmPoolJob{Operations: worker.OperationCreate}
 		mockPool.EXPECT().SetToActive(pdWarmPoolConfig).Return(job)
 		mockWorker.EXPECT().SubmitJob(job)
 
-		mockInstance.EXPECT().Name().Return(nodeName).Times(2)
+		mockInstance.EXPECT().Name().Return(nodeName).Times(3)
 		mockInstance.EXPECT().Type().Return(instanceType)
 		mockInstance.EXPECT().Os().Return(config.OSWindows)
 		mockK8sWrapper.EXPECT().AdvertiseCapacityIfNotSet(nodeName, config.ResourceNameIPAddress, 224).Return(nil)
 
-		err := prefixProvider.UpdateResourceCapacity(mockInstance)
+		err := prefixProvider.UpdateResourceCapacity(context.TODO(), mockInstance)
 		assert.NoError(t, err)
 	}
 }
@@ -499,7 +503,7 @@ func TestIPv4PrefixProvider_UpdateResourceCapacity_FromIPToIP(t *testing.T) {
 	prefixProvider.putInstanceProviderAndPool(nodeName, mockPool, mockManager, nodeCapacity, false)
 	mockConditions.EXPECT().IsWindowsPrefixDelegationEnabled().Return(false)
 
-	err := prefixProvider.UpdateResourceCapacity(mockInstance)
+	err := prefixProvider.UpdateResourceCapacity(context.TODO(), mockInstance)
 	assert.NoError(t, err)
 }
 
@@ -14,9 +14,11 @@
 package provider
 
 import (
+	"context"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 
+	"github.com/aws/amazon-vpc-resource-controller-k8s/apis/vpcresources/v1alpha1"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/ec2"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/pool"
 )
@@ -24,15 +26,17 @@ import (
 // ResourceProvider is the provider interface that each resource managed by the controller has to implement
 type ResourceProvider interface {
 	// InitResource initializes the resource provider
-	InitResource(instance ec2.EC2Instance) error
+	InitResource(ctx context.Context, instance ec2.EC2Instance) error
 	// DeInitResources de initializes the resource provider
 	DeInitResource(instance ec2.EC2Instance) error
 	// UpdateResourceCapacity updates the resource capacity
-	UpdateResourceCapacity(instance ec2.EC2Instance) error
+	UpdateResourceCapacity(ctx context.Context, instance ec2.EC2Instance) error
 	// SubmitAsyncJob submits a job to the worker
 	SubmitAsyncJob(job interface{})
-	// ProcessAsyncJob processes a job form the worker queue
-	ProcessAsyncJob(job interface{}) (ctrl.Result, error)
+	// SubmitAsyncJobWithContext submits a job to the worker, the job continues the trace of the context
+	SubmitAsyncJobWithContext(ctx context.Context, job interface{})
+	// ProcessAsyncJob processes a job form the worker queue, the context has the span of the job
+	ProcessAsyncJob(ctx context.Context, job interface{}) (ctrl.Result, error)
 	// GetPool returns the warm pool for resources that support warm pool
 	GetPool(nodeName string) (pool.Pool, bool)
 	// IsInstanceSupported returns true if an instance type is supported by the provider
@@ -47,3 +51,40 @@ type ResourceProvider interface {
 	IntrospectSummary() interface{}
 	ReconcileNode(nodeName string) bool
 }
+
+// CheckpointProvider is implemented by the resource providers whose node state is checkpointed to the CNINode, so that
+// a new controller leader can initialize the node from the checkpoint instead of EC2
+type CheckpointProvider interface {
+	// CheckpointNode adds the state of the node's resources to the checkpoint
+	CheckpointNode(nodeName string, checkpoint *v1alpha1.CNINodeCheckpoint)
+}
+
+// BranchENISecurityGroupUpdater is implemented by the resource providers of branch ENIs, so that the security groups
+// modified on the branch ENIs of running pods are recorded in the provider's state
+type BranchENISecurityGroupUpdater interface {
+	// UpdateBranchENISecurityGroups records the security groups modified on the branch ENI used by the pod
+	UpdateBranchENISecurityGroups(nodeName string, UID string, eniID string, securityGroups []string)
+}
+
+// BranchENISubnetsSetter is implemented by the resource providers of branch ENIs, so that the candidate subnets of
+// the branch ENIs are updated as soon as the CNINode spec changes
+type BranchENISubnetsSetter interface {
+	// SetBranchENISubnets sets the candidate subnets of the new branch ENIs of the node
+	SetBranchENISubnets(nodeName string, spec *v1alpha1.BranchENISubnets)
+}
+
+// BranchENISubnetsLister is implemented by the resource providers of branch ENIs, so that the candidate subnets of the
+// branch ENIs are monitored along with the subnets of the nodes
+type BranchENISubnetsLister interface {
+	// GetBranchENISubnets returns the candidate subnets of the branch ENIs of all the nodes
+	GetBranchENISubnets() []string
+}
+
+// NodeIntrospector is implemented by the resource providers that introspect the nodes one by one, so that the
+// introspection API builds the response of the nodes in the requested page only
+type NodeIntrospector interface {
+	// IntrospectNodeNames returns the names of the nodes with the resources of the provider
+	IntrospectNodeNames() []string
+	// IntrospectNodeSummary returns the resources summary of the node
+	IntrospectNodeSummary(nodeName string) interface{}
+}
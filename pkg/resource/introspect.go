@@ -17,9 +17,18 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"strings"
 
 	rcHealthz "github.com/aws/amazon-vpc-resource-controller-k8s/pkg/healthz"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/k8s"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/k8s/pod"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/lookup"
 	"github.com/go-logr/logr"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	authenticationv1client "k8s.io/client-go/kubernetes/typed/authentication/v1"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 )
@@ -34,78 +43,179 @@ type IntrospectHandler struct {
 	Log             logr.Logger
 	BindAddress     string
 	ResourceManager ResourceManager
+	// LookupIndex finds the node and the pod of the resources assigned to the pods
+	LookupIndex *lookup.Index
+	// K8sAPI lists the nodes to filter the nodes by their labels
+	K8sAPI k8s.K8sWrapper
+	// PodAPI lists the pods of a node to find the namespace and name of the pods by UID
+	PodAPI pod.PodClientAPIWrapper
+	// CertFile and KeyFile are the TLS certificate and key to serve the API over HTTPS, the API is served over HTTP
+	// if they are not set
+	CertFile string
+	KeyFile  string
+	// TokenReviewer authenticates the bearer token of the requests, the requests are not authenticated if it's nil
+	TokenReviewer authenticationv1client.TokenReviewInterface
+	// AccessReviewer authorizes the authenticated user to get the path of the requests with a SubjectAccessReview,
+	// the authenticated requests are not authorized if it's nil
+	AccessReviewer authorizationv1client.SubjectAccessReviewInterface
 }
 
-// StartENICleaner starts the ENI Cleaner routine that cleans up dangling ENIs created by the controller
+// Start starts the introspection API server
 func (i *IntrospectHandler) Start(_ context.Context) error {
-	i.Log.Info("starting introspection API")
+	i.Log.Info("starting introspection API", "tls", i.CertFile != "", "authentication", i.TokenReviewer != nil,
+		"authorization", i.AccessReviewer != nil)
 
 	mux := http.NewServeMux()
-	mux.HandleFunc(GetAllResourcesPath, i.ResourceHandler)
-	mux.HandleFunc(GetNodeResourcesPath, i.NodeResourceHandler)
-	mux.HandleFunc(GetResourcesSummaryPath, i.ResourceSummaryHandler)
+	mux.HandleFunc(GetAllResourcesPath, i.authenticate(i.ResourceHandler))
+	mux.HandleFunc(GetNodeResourcesPath, i.authenticate(i.NodeResourceHandler))
+	mux.HandleFunc(GetResourcesSummaryPath, i.authenticate(i.ResourceSummaryHandler))
+	mux.HandleFunc(LookupResourcePath, i.authenticate(i.LookupHandler))
 
+	var err error
 	// Should this be a fatal error?
-	err := http.ListenAndServe(i.BindAddress, mux) // #nosec G114
+	if i.CertFile != "" && i.KeyFile != "" {
+		err = http.ListenAndServeTLS(i.BindAddress, i.CertFile, i.KeyFile, mux) // #nosec G114
+	} else {
+		err = http.ListenAndServe(i.BindAddress, mux) // #nosec G114
+	}
 	if err != nil {
 		i.Log.Error(err, "failed to run introspect API")
 	}
 	return err
 }
 
-// ResourceHandler returns all the nodes associated with the resource
-func (i *IntrospectHandler) ResourceHandler(w http.ResponseWriter, _ *http.Request) {
-	response := make(map[string]interface{})
-	for resourceName, provider := range i.ResourceManager.GetResourceProviders() {
-		data := provider.Introspect()
-		response[resourceName] = data
+// authenticate returns the handler that serves the request only if its bearer token is authenticated by a
+// TokenReview, and the authenticated user is allowed to get the path of the request by a SubjectAccessReview
+func (i *IntrospectHandler) authenticate(handler http.HandlerFunc) http.HandlerFunc {
+	if i.TokenReviewer == nil {
+		return handler
 	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			http.Error(w, "missing bearer token", http.StatusUnauthorized)
+			return
+		}
+		review, err := i.TokenReviewer.Create(r.Context(), &authenticationv1.TokenReview{
+			Spec: authenticationv1.TokenReviewSpec{Token: token},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			i.Log.Error(err, "failed to review the bearer token of introspect request")
+			http.Error(w, "failed to authenticate the bearer token", http.StatusInternalServerError)
+			return
+		}
+		if !review.Status.Authenticated {
+			i.Log.V(1).Info("rejected introspect request with invalid bearer token", "error", review.Status.Error)
+			http.Error(w, "invalid bearer token", http.StatusUnauthorized)
+			return
+		}
+		i.Log.V(1).Info("authenticated introspect request", "user", review.Status.User.Username,
+			"path", r.URL.Path)
+		if i.AccessReviewer != nil {
+			allowed, err := i.authorize(r, review.Status.User)
+			if err != nil {
+				i.Log.Error(err, "failed to review the access of introspect request")
+				http.Error(w, "failed to authorize the request", http.StatusInternalServerError)
+				return
+			}
+			if !allowed {
+				i.Log.V(1).Info("rejected unauthorized introspect request", "user", review.Status.User.Username,
+					"path", r.URL.Path)
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+		}
+		handler(w, r)
+	}
+}
 
-	jsonData, err := json.MarshalIndent(response, "", "\t")
+// authorize returns true if the user is allowed to get the non resource URL of the request path
+func (i *IntrospectHandler) authorize(r *http.Request, user authenticationv1.UserInfo) (bool, error) {
+	extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+	for key, value := range user.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+	review, err := i.AccessReviewer.Create(r.Context(), &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user.Username,
+			Groups: user.Groups,
+			UID:    user.UID,
+			Extra:  extra,
+			NonResourceAttributes: &authorizationv1.NonResourceAttributes{
+				Path: r.URL.Path,
+				Verb: "get",
+			},
+		},
+	}, metav1.CreateOptions{})
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte(err.Error()))
-		return
+		return false, err
 	}
+	return review.Status.Allowed, nil
+}
 
-	w.Header().Set("content-type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write(jsonData)
+// ResourceHandler returns all the nodes associated with the resource
+func (i *IntrospectHandler) ResourceHandler(w http.ResponseWriter, r *http.Request) {
+	i.nodesHandler(w, r, false)
 }
 
 // NodeResourceHandler returns all the resources associated with the Node
 func (i *IntrospectHandler) NodeResourceHandler(w http.ResponseWriter, r *http.Request) {
 	nodeName := r.URL.Path[len(GetNodeResourcesPath):]
 
+	query, err := parseIntrospectQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	response := make(map[string]interface{})
-	for resourceName, provider := range i.ResourceManager.GetResourceProviders() {
+	for resourceName, provider := range query.filterProviders(i.ResourceManager.GetResourceProviders()) {
 		data := provider.IntrospectNode(nodeName)
 		if data != nil {
 			response[resourceName] = data
 		}
 	}
 
-	jsonData, err := json.MarshalIndent(response, "", "\t")
+	writeResponse(w, response, query.pretty)
+}
+
+// ResourceSummaryHandler returns all the resources associated with the Node
+func (i *IntrospectHandler) ResourceSummaryHandler(w http.ResponseWriter, r *http.Request) {
+	i.nodesHandler(w, r, true)
+}
+
+// nodesHandler returns the introspection response, or the summary, of each resource for the nodes selected by the
+// query parameters, the next page is requested with the cursor in the continue header of the response
+func (i *IntrospectHandler) nodesHandler(w http.ResponseWriter, r *http.Request, summary bool) {
+	query, err := parseIntrospectQuery(r.URL.Query())
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte(err.Error()))
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	w.Header().Set("content-type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write(jsonData)
-}
+	providers := query.filterProviders(i.ResourceManager.GetResourceProviders())
+	filter, err := query.nodeFilter(i.K8sAPI, providers)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
-// ResourceSummaryHandler returns all the resources associated with the Node
-func (i *IntrospectHandler) ResourceSummaryHandler(w http.ResponseWriter, r *http.Request) {
-	response := make(map[string]interface{})
-	for resourceName, provider := range i.ResourceManager.GetResourceProviders() {
-		data := provider.IntrospectSummary()
-		response[resourceName] = data
+	response, cursor := query.page(providers, filter, summary)
+
+	if cursor != "" {
+		w.Header().Set(ContinueHeader, cursor)
 	}
+	writeResponse(w, response, query.pretty)
+}
 
-	jsonData, err := json.MarshalIndent(response, "", "\t")
+func writeResponse(w http.ResponseWriter, response map[string]interface{}, pretty bool) {
+	var jsonData []byte
+	var err error
+	if pretty {
+		jsonData, err = json.MarshalIndent(response, "", "\t")
+	} else {
+		jsonData, err = json.Marshal(response)
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte(err.Error()))
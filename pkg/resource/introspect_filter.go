@@ -0,0 +1,282 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package resource
+
+import (
+	"encoding/base64"
+	"fmt"
+	"maps"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/k8s"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/pool"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider/branch/trunk"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// QueryResource is the comma separated list of the resource names to return
+	QueryResource = "resource"
+	// QueryNodeSelector is the label selector of the nodes to return
+	QueryNodeSelector = "nodeSelector"
+	// QueryPodUID is the UID of the pod, only the nodes with the resources of the pod are returned
+	QueryPodUID = "podUID"
+	// QueryLimit is the maximum number of nodes to return
+	QueryLimit = "limit"
+	// QueryContinue is the cursor returned with the previous page to get the next page
+	QueryContinue = "continue"
+	// QueryPretty indents the JSON response if set to true
+	QueryPretty = "pretty"
+
+	// ContinueHeader is the response header with the cursor to get the next page, it's not set on the last page
+	ContinueHeader = "X-Continue"
+)
+
+// introspectQuery is the filter and the page of the nodes requested from the introspection API
+type introspectQuery struct {
+	// resourceNames are the resources to return, all resources are returned if empty
+	resourceNames []string
+	// nodeSelector selects the nodes to return by their labels, all nodes are returned if nil
+	nodeSelector labels.Selector
+	// podUID is the UID of the pod to return the nodes of, all nodes are returned if empty
+	podUID string
+	// limit is the maximum number of nodes to return, all nodes are returned if 0
+	limit int
+	// after is the node name the previous page ended with
+	after  string
+	pretty bool
+}
+
+// parseIntrospectQuery returns the introspect query from the URL query parameters
+func parseIntrospectQuery(values url.Values) (*introspectQuery, error) {
+	query := &introspectQuery{}
+	for _, resourceNames := range values[QueryResource] {
+		for _, resourceName := range strings.Split(resourceNames, ",") {
+			if resourceName = strings.TrimSpace(resourceName); resourceName != "" {
+				query.resourceNames = append(query.resourceNames, resourceName)
+			}
+		}
+	}
+	if nodeSelector := values.Get(QueryNodeSelector); nodeSelector != "" {
+		selector, err := labels.Parse(nodeSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %v", QueryNodeSelector, nodeSelector, err)
+		}
+		query.nodeSelector = selector
+	}
+	query.podUID = values.Get(QueryPodUID)
+	if limit := values.Get(QueryLimit); limit != "" {
+		var err error
+		if query.limit, err = strconv.Atoi(limit); err != nil || query.limit <= 0 {
+			return nil, fmt.Errorf("invalid %s %q, must be a positive integer", QueryLimit, limit)
+		}
+	}
+	if cursor := values.Get(QueryContinue); cursor != "" {
+		after, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %v", QueryContinue, cursor, err)
+		}
+		query.after = string(after)
+	}
+	if pretty := values.Get(QueryPretty); pretty != "" {
+		var err error
+		if query.pretty, err = strconv.ParseBool(pretty); err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %v", QueryPretty, pretty, err)
+		}
+	}
+	return query, nil
+}
+
+// filterProviders returns the resource providers selected by the query
+func (q *introspectQuery) filterProviders(providers map[string]provider.ResourceProvider) map[string]provider.ResourceProvider {
+	if len(q.resourceNames) == 0 {
+		return providers
+	}
+	filtered := make(map[string]provider.ResourceProvider)
+	for resourceName, resourceProvider := range providers {
+		if slices.Contains(q.resourceNames, resourceName) {
+			filtered[resourceName] = resourceProvider
+		}
+	}
+	return filtered
+}
+
+// nodeFilter returns the function that returns true for the names of the nodes selected by the query, or nil if all
+// the nodes are selected
+func (q *introspectQuery) nodeFilter(k8sAPI k8s.K8sWrapper,
+	providers map[string]provider.ResourceProvider) (func(nodeName string) bool, error) {
+	if q.nodeSelector == nil && q.podUID == "" {
+		return nil, nil
+	}
+
+	var selectedNodes map[string]struct{}
+	if q.nodeSelector != nil {
+		if k8sAPI == nil {
+			return nil, fmt.Errorf("filtering by %s is not supported", QueryNodeSelector)
+		}
+		nodeList, err := k8sAPI.ListNodes()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list nodes: %v", err)
+		}
+		selectedNodes = make(map[string]struct{})
+		for _, node := range nodeList.Items {
+			if q.nodeSelector.Matches(labels.Set(node.Labels)) {
+				selectedNodes[node.Name] = struct{}{}
+			}
+		}
+	}
+
+	var podNodes map[string]struct{}
+	if q.podUID != "" {
+		podNodes = make(map[string]struct{})
+		for _, resourceProvider := range providers {
+			for nodeName, data := range nodeEntries(resourceProvider.Introspect()) {
+				if hasPod(data, q.podUID) {
+					podNodes[nodeName] = struct{}{}
+				}
+			}
+		}
+	}
+
+	return func(nodeName string) bool {
+		if _, ok := selectedNodes[nodeName]; selectedNodes != nil && !ok {
+			return false
+		}
+		if _, ok := podNodes[nodeName]; podNodes != nil && !ok {
+			return false
+		}
+		return true
+	}, nil
+}
+
+// page returns the introspection response, or the summary, of each resource for the nodes selected by the filter and
+// in the page of the query, and the cursor of the next page which is empty on the last page. The nodes are paged by
+// their sorted names, and only the nodes in the page are introspected from the providers that introspect the nodes
+// one by one. The responses that are not by node name are returned as is
+func (q *introspectQuery) page(providers map[string]provider.ResourceProvider, filter func(nodeName string) bool,
+	summary bool) (map[string]interface{}, string) {
+	responses := make(map[string]interface{}, len(providers))
+	if filter == nil && q.limit == 0 && q.after == "" {
+		for resourceName, resourceProvider := range providers {
+			responses[resourceName] = introspect(resourceProvider, summary)
+		}
+		return responses, ""
+	}
+
+	// The node names of each provider that introspects the nodes one by one, and the introspection response of each
+	// node of the other providers
+	providerNodes := make(map[string]map[string]struct{}, len(providers))
+	entries := make(map[string]map[string]interface{}, len(providers))
+	var nodeNames []string
+	for resourceName, resourceProvider := range providers {
+		var names []string
+		if nodeIntrospector, ok := resourceProvider.(provider.NodeIntrospector); ok {
+			names = nodeIntrospector.IntrospectNodeNames()
+			providerNodes[resourceName] = make(map[string]struct{}, len(names))
+			for _, nodeName := range names {
+				providerNodes[resourceName][nodeName] = struct{}{}
+			}
+		} else {
+			response := introspect(resourceProvider, summary)
+			nodeResponses := nodeEntries(response)
+			if nodeResponses == nil {
+				responses[resourceName] = response
+				continue
+			}
+			entries[resourceName] = nodeResponses
+			names = slices.Collect(maps.Keys(nodeResponses))
+		}
+		for _, nodeName := range names {
+			if nodeName > q.after && (filter == nil || filter(nodeName)) {
+				nodeNames = append(nodeNames, nodeName)
+			}
+		}
+	}
+	slices.Sort(nodeNames)
+	nodeNames = slices.Compact(nodeNames)
+
+	var cursor string
+	if q.limit > 0 && len(nodeNames) > q.limit {
+		nodeNames = nodeNames[:q.limit]
+		cursor = base64.RawURLEncoding.EncodeToString([]byte(nodeNames[q.limit-1]))
+	}
+
+	for resourceName, nodes := range providerNodes {
+		nodeIntrospector := providers[resourceName].(provider.NodeIntrospector)
+		pagedResponses := make(map[string]interface{})
+		for _, nodeName := range nodeNames {
+			if _, ok := nodes[nodeName]; !ok {
+				continue
+			}
+			if summary {
+				pagedResponses[nodeName] = nodeIntrospector.IntrospectNodeSummary(nodeName)
+			} else {
+				pagedResponses[nodeName] = providers[resourceName].IntrospectNode(nodeName)
+			}
+		}
+		responses[resourceName] = pagedResponses
+	}
+	for resourceName, nodeResponses := range entries {
+		pagedResponses := make(map[string]interface{})
+		for _, nodeName := range nodeNames {
+			if data, ok := nodeResponses[nodeName]; ok {
+				pagedResponses[nodeName] = data
+			}
+		}
+		responses[resourceName] = pagedResponses
+	}
+	return responses, cursor
+}
+
+// introspect returns the introspection response, or the summary, of all the nodes of the resource provider
+func introspect(resourceProvider provider.ResourceProvider, summary bool) interface{} {
+	if summary {
+		return resourceProvider.IntrospectSummary()
+	}
+	return resourceProvider.Introspect()
+}
+
+// nodeEntries returns the introspection response of each node from the introspection response of all the nodes of a
+// resource, or nil if the response is not by node name
+func nodeEntries(response interface{}) map[string]interface{} {
+	value := reflect.ValueOf(response)
+	if value.Kind() != reflect.Map || value.Type().Key().Kind() != reflect.String {
+		return nil
+	}
+	entries := make(map[string]interface{}, value.Len())
+	iter := value.MapRange()
+	for iter.Next() {
+		entries[iter.Key().String()] = iter.Value().Interface()
+	}
+	return entries
+}
+
+// hasPod returns true if the resources of the pod are in the introspection response of the node
+func hasPod(data interface{}, podUID string) bool {
+	switch response := data.(type) {
+	case trunk.IntrospectResponse:
+		_, ok := response.PodToBranchENI[podUID]
+		return ok
+	case pool.IntrospectResponse:
+		_, ok := response.UsedResources[podUID]
+		return ok
+	}
+	return false
+}
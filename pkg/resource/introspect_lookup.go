@@ -0,0 +1,209 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package resource
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/lookup"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/pool"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider/branch/trunk"
+)
+
+const (
+	// LookupResourcePath resolves an IP address, prefix or ENI ID to the pod and node it belongs to
+	LookupResourcePath = "/resources/lookup"
+	// QueryID is the IPv4 address, IPv6 address, prefix or ENI ID to look up
+	QueryID = "id"
+)
+
+// ResourceState is the state of a resource in the pool of its node
+type ResourceState string
+
+const (
+	// ResourceStateInUse is the state of a resource assigned to a pod
+	ResourceStateInUse ResourceState = "InUse"
+	// ResourceStateWarm is the state of a free resource that can be assigned to a pod
+	ResourceStateWarm ResourceState = "Warm"
+	// ResourceStateCooling is the state of a resource released by a pod that is cooling down before being reused or
+	// deleted
+	ResourceStateCooling ResourceState = "Cooling"
+)
+
+// LookupResponse is a resource matching the looked up ID
+type LookupResponse struct {
+	ResourceName string
+	// ResourceID is the IP address or the ENI ID of the resource
+	ResourceID string
+	// GroupID is the prefix or the secondary IP address the resource belongs to, it's not set for branch ENIs
+	GroupID  string `json:",omitempty"`
+	NodeName string
+	State    ResourceState
+	// PodUID is the pod using the resource, or the pod that last used a warm or cooling resource if known
+	PodUID       string     `json:",omitempty"`
+	PodNamespace string     `json:",omitempty"`
+	PodName      string     `json:",omitempty"`
+	ReleasedAt   *time.Time `json:",omitempty"`
+}
+
+// LookupHandler returns the resources, along with their node, pod and state, matching the IP address, prefix or ENI
+// ID in the query
+func (i *IntrospectHandler) LookupHandler(w http.ResponseWriter, r *http.Request) {
+	query, err := parseIntrospectQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id := r.URL.Query().Get(QueryID)
+	if id == "" {
+		http.Error(w, fmt.Sprintf("missing %s query parameter", QueryID), http.StatusBadRequest)
+		return
+	}
+
+	providers := query.filterProviders(i.ResourceManager.GetResourceProviders())
+	response := i.lookup(providers, id)
+	if len(response) == 0 {
+		http.Error(w, fmt.Sprintf("%s not found", id), http.StatusNotFound)
+		return
+	}
+
+	writeResponse(w, map[string]interface{}{QueryID: id, "resources": response}, query.pretty)
+}
+
+// lookup returns the resources matching the ID. The node of the resource is found from the lookup index, and all the
+// nodes are searched if the ID is not indexed, like the prefixes and the warm resources never assigned to a pod, or if
+// the indexed node no longer has the resource
+func (i *IntrospectHandler) lookup(providers map[string]provider.ResourceProvider, id string) []LookupResponse {
+	var response []LookupResponse
+	owner, isIndexed := i.LookupIndex.Get(id)
+	if isIndexed {
+		for resourceName, resourceProvider := range providers {
+			response = append(response,
+				matchResource(resourceName, owner.NodeName, resourceProvider.IntrospectNode(owner.NodeName), id)...)
+		}
+		if len(response) == 0 {
+			i.LookupIndex.Remove(owner.NodeName, id)
+		}
+	}
+	if len(response) == 0 {
+		for resourceName, resourceProvider := range providers {
+			for nodeName, data := range nodeEntries(resourceProvider.Introspect()) {
+				response = append(response, matchResource(resourceName, nodeName, data, id)...)
+			}
+		}
+	}
+
+	for index := range response {
+		i.setPod(&response[index])
+	}
+	slices.SortFunc(response, func(a, b LookupResponse) int {
+		return cmp.Or(cmp.Compare(a.ResourceName, b.ResourceName), cmp.Compare(a.NodeName, b.NodeName),
+			cmp.Compare(a.ResourceID, b.ResourceID))
+	})
+	return response
+}
+
+// setPod sets the last pod that used the warm or cooling resource from the lookup index, and the namespace and name
+// of the pod from the pod data store
+func (i *IntrospectHandler) setPod(response *LookupResponse) {
+	if response.PodUID == "" {
+		owner, ok := i.LookupIndex.Get(response.ResourceID)
+		if !ok || owner.NodeName != response.NodeName {
+			return
+		}
+		response.PodUID = owner.PodUID
+		if !owner.ReleasedAt.IsZero() {
+			response.ReleasedAt = &owner.ReleasedAt
+		}
+	}
+	if i.PodAPI == nil {
+		return
+	}
+	podList, err := i.PodAPI.ListPods(response.NodeName)
+	if err != nil {
+		i.Log.Error(err, "failed to list pods to look up the pod", "node", response.NodeName)
+		return
+	}
+	for _, pod := range podList.Items {
+		if string(pod.UID) == response.PodUID {
+			response.PodNamespace, response.PodName = pod.Namespace, pod.Name
+			return
+		}
+	}
+}
+
+// matchResource returns the resources matching the ID in the introspection response of the node. The IP addresses are
+// matched by their address, since the pools keep the secondary IPv4 addresses with the mask of the subnet and the IPv4
+// addresses of the prefixes with a /32 mask
+func matchResource(resourceName string, nodeName string, data interface{}, id string) []LookupResponse {
+	id = lookup.NormalizeID(id)
+	var response []LookupResponse
+	add := func(resourceID string, groupID string, state ResourceState, podUID string) {
+		response = append(response, LookupResponse{ResourceName: resourceName, ResourceID: resourceID,
+			GroupID: groupID, NodeName: nodeName, State: state, PodUID: podUID})
+	}
+
+	switch nodeResponse := data.(type) {
+	case pool.IntrospectResponse:
+		isMatch := func(resource pool.Resource) bool {
+			return lookup.NormalizeID(resource.ResourceID) == id || lookup.NormalizeID(resource.GroupID) == id
+		}
+		for podUID, resource := range nodeResponse.UsedResources {
+			if isMatch(resource) {
+				add(resource.ResourceID, resource.GroupID, ResourceStateInUse, podUID)
+			}
+		}
+		for _, resources := range nodeResponse.WarmResources {
+			for _, resource := range resources {
+				if isMatch(resource) {
+					add(resource.ResourceID, resource.GroupID, ResourceStateWarm, "")
+				}
+			}
+		}
+		for _, coolingResource := range nodeResponse.CoolingResources {
+			if isMatch(coolingResource.Resource) {
+				add(coolingResource.Resource.ResourceID, coolingResource.Resource.GroupID, ResourceStateCooling, "")
+			}
+		}
+	case trunk.IntrospectResponse:
+		isMatch := func(eni trunk.ENIDetails) bool {
+			return eni.ID == id || lookup.NormalizeID(eni.IPV4Addr) == id || lookup.NormalizeID(eni.IPV6Addr) == id
+		}
+		for podUID, enis := range nodeResponse.PodToBranchENI {
+			for _, eni := range enis {
+				if isMatch(eni) {
+					add(eni.ID, "", ResourceStateInUse, podUID)
+				}
+			}
+		}
+		for _, enis := range nodeResponse.WarmPools {
+			for _, eni := range enis {
+				if isMatch(eni) {
+					add(eni.ID, "", ResourceStateWarm, "")
+				}
+			}
+		}
+		for _, eni := range nodeResponse.DeleteQueue {
+			if isMatch(eni) {
+				add(eni.ID, "", ResourceStateCooling, "")
+			}
+		}
+	}
+	return response
+}
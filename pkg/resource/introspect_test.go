@@ -15,17 +15,35 @@ package resource
 
 import (
 	"encoding/json"
+	"maps"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"slices"
 	"testing"
+	"time"
 
+	mock_k8s "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/k8s"
+	mock_pod "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/k8s/pod"
 	mock_provider "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/provider"
 	mock_resource "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/resource"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/lookup"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/pool"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider/branch/trunk"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
 
 var (
@@ -48,6 +66,7 @@ func NewMockIntrospectHandler(ctrl *gomock.Controller) MockIntrospect {
 		mockProvider: mock_provider.NewMockResourceProvider(ctrl),
 		handler: IntrospectHandler{
 			ResourceManager: mockManager,
+			LookupIndex:     lookup.NewIndex(time.Hour),
 		},
 		response: map[string]string{resourceName: response},
 	}
@@ -115,3 +134,401 @@ func VerifyResponse(t *testing.T, rr *httptest.ResponseRecorder, response map[st
 	assert.Equal(t, rr.Code, http.StatusOK)
 	assert.Equal(t, response, *got)
 }
+
+// getIntrospectResponse returns the introspection response of the branch ENI provider for the nodes, with the pods
+// on the first node
+func getIntrospectResponse(nodeNames []string, podUIDs ...string) map[string]trunk.IntrospectResponse {
+	response := make(map[string]trunk.IntrospectResponse)
+	for i, name := range nodeNames {
+		nodeResponse := trunk.IntrospectResponse{TrunkENIID: name, PodToBranchENI: map[string][]trunk.ENIDetails{}}
+		if i == 0 {
+			for _, uid := range podUIDs {
+				nodeResponse.PodToBranchENI[uid] = []trunk.ENIDetails{{ID: "eni-" + uid}}
+			}
+		}
+		response[name] = nodeResponse
+	}
+	return response
+}
+
+func getNodeNames(t *testing.T, rr *httptest.ResponseRecorder, resourceName string) []string {
+	got := map[string]map[string]json.RawMessage{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+	var nodeNames []string
+	for name := range got[resourceName] {
+		nodeNames = append(nodeNames, name)
+	}
+	slices.Sort(nodeNames)
+	return nodeNames
+}
+
+// TestIntrospectHandler_ResourceHandler_Page tests the nodes are returned in pages of the limit sorted by node name
+// until there is no continue cursor
+func TestIntrospectHandler_ResourceHandler_Page(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := NewMockIntrospectHandler(ctrl)
+	nodeNames := []string{"node-1", "node-2", "node-3", "node-4", "node-5"}
+
+	mock.mockManager.EXPECT().GetResourceProviders().
+		Return(map[string]provider.ResourceProvider{resourceName: mock.mockProvider}).Times(3)
+	mock.mockProvider.EXPECT().Introspect().Return(getIntrospectResponse(nodeNames)).Times(3)
+
+	var pages [][]string
+	query := url.Values{QueryLimit: []string{"2"}}
+	for {
+		req, err := http.NewRequest("GET", GetAllResourcesPath+"?"+query.Encode(), nil)
+		assert.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		mock.handler.ResourceHandler(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		pages = append(pages, getNodeNames(t, rr, resourceName))
+
+		cursor := rr.Header().Get(ContinueHeader)
+		if cursor == "" {
+			break
+		}
+		query.Set(QueryContinue, cursor)
+	}
+	assert.Equal(t, [][]string{{"node-1", "node-2"}, {"node-3", "node-4"}, {"node-5"}}, pages)
+}
+
+// TestIntrospectHandler_ResourceHandler_Filter tests the resources and nodes are filtered by the query parameters
+// mockNodeIntrospector is a resource provider that introspects the nodes one by one
+type mockNodeIntrospector struct {
+	*mock_provider.MockResourceProvider
+	*mock_provider.MockNodeIntrospector
+}
+
+// TestIntrospectHandler_ResourceHandler_PageNodeIntrospector tests that only the nodes in the page are introspected
+// from the provider that introspects the nodes one by one
+func TestIntrospectHandler_ResourceHandler_PageNodeIntrospector(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := NewMockIntrospectHandler(ctrl)
+	nodeIntrospector := &mockNodeIntrospector{
+		MockResourceProvider: mock.mockProvider,
+		MockNodeIntrospector: mock_provider.NewMockNodeIntrospector(ctrl),
+	}
+	nodeNames := []string{"node-5", "node-3", "node-1", "node-4", "node-2"}
+
+	mock.mockManager.EXPECT().GetResourceProviders().
+		Return(map[string]provider.ResourceProvider{resourceName: nodeIntrospector}).Times(3)
+	nodeIntrospector.MockNodeIntrospector.EXPECT().IntrospectNodeNames().Return(nodeNames).Times(3)
+	for _, nodeName := range nodeNames {
+		mock.mockProvider.EXPECT().IntrospectNode(nodeName).Return(response)
+	}
+
+	var pages [][]string
+	query := url.Values{QueryLimit: []string{"2"}}
+	for {
+		req, err := http.NewRequest("GET", GetAllResourcesPath+"?"+query.Encode(), nil)
+		assert.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		mock.handler.ResourceHandler(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		pages = append(pages, getNodeNames(t, rr, resourceName))
+
+		cursor := rr.Header().Get(ContinueHeader)
+		if cursor == "" {
+			break
+		}
+		query.Set(QueryContinue, cursor)
+	}
+	assert.Equal(t, [][]string{{"node-1", "node-2"}, {"node-3", "node-4"}, {"node-5"}}, pages)
+}
+
+// TestIntrospectHandler_SummaryHandler_PageNodeIntrospector tests that the summary of the nodes in the page is
+// returned from the provider that introspects the nodes one by one
+func TestIntrospectHandler_SummaryHandler_PageNodeIntrospector(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := NewMockIntrospectHandler(ctrl)
+	nodeIntrospector := &mockNodeIntrospector{
+		MockResourceProvider: mock.mockProvider,
+		MockNodeIntrospector: mock_provider.NewMockNodeIntrospector(ctrl),
+	}
+
+	mock.mockManager.EXPECT().GetResourceProviders().
+		Return(map[string]provider.ResourceProvider{resourceName: nodeIntrospector})
+	nodeIntrospector.MockNodeIntrospector.EXPECT().IntrospectNodeNames().Return([]string{"node-2", "node-1"})
+	nodeIntrospector.MockNodeIntrospector.EXPECT().IntrospectNodeSummary("node-1").Return(response)
+
+	req, err := http.NewRequest("GET", GetResourcesSummaryPath+"?"+QueryLimit+"=1", nil)
+	assert.NoError(t, err)
+	rr := httptest.NewRecorder()
+
+	mock.handler.ResourceSummaryHandler(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, []string{"node-1"}, getNodeNames(t, rr, resourceName))
+	assert.NotEmpty(t, rr.Header().Get(ContinueHeader))
+}
+
+func TestIntrospectHandler_ResourceHandler_Filter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := NewMockIntrospectHandler(ctrl)
+	mockK8sAPI := mock_k8s.NewMockK8sWrapper(ctrl)
+	mock.handler.K8sAPI = mockK8sAPI
+	ipProvider := mock_provider.NewMockResourceProvider(ctrl)
+	providers := map[string]provider.ResourceProvider{resourceName: mock.mockProvider,
+		config.ResourceNameIPAddress: ipProvider}
+	podUID := "pod-uid"
+
+	tests := []struct {
+		name              string
+		query             url.Values
+		prepare           func()
+		expectedResources []string
+		expectedNodes     []string
+		expectedCode      int
+	}{
+		{
+			name:  "filter by resource",
+			query: url.Values{QueryResource: []string{resourceName}},
+			prepare: func() {
+				mock.mockProvider.EXPECT().Introspect().Return(getIntrospectResponse([]string{"node-1"}))
+			},
+			expectedResources: []string{resourceName},
+			expectedNodes:     []string{"node-1"},
+			expectedCode:      http.StatusOK,
+		},
+		{
+			name:  "filter by node selector",
+			query: url.Values{QueryResource: []string{resourceName}, QueryNodeSelector: []string{"role=db"}},
+			prepare: func() {
+				mockK8sAPI.EXPECT().ListNodes().Return(&v1.NodeList{Items: []v1.Node{
+					{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{"role": "web"}}},
+					{ObjectMeta: metav1.ObjectMeta{Name: "node-2", Labels: map[string]string{"role": "db"}}},
+				}}, nil)
+				mock.mockProvider.EXPECT().Introspect().Return(getIntrospectResponse([]string{"node-1", "node-2"}))
+			},
+			expectedResources: []string{resourceName},
+			expectedNodes:     []string{"node-2"},
+			expectedCode:      http.StatusOK,
+		},
+		{
+			name:  "filter by pod UID",
+			query: url.Values{QueryPodUID: []string{podUID}},
+			prepare: func() {
+				response := getIntrospectResponse([]string{"node-2", "node-1"}, podUID)
+				mock.mockProvider.EXPECT().Introspect().Return(response).Times(2)
+				ipProvider.EXPECT().Introspect().Return(map[string]pool.IntrospectResponse{"node-1": {}}).Times(2)
+			},
+			expectedResources: []string{config.ResourceNameIPAddress, resourceName},
+			expectedNodes:     []string{"node-2"},
+			expectedCode:      http.StatusOK,
+		},
+		{
+			name:         "invalid node selector",
+			query:        url.Values{QueryNodeSelector: []string{"role in db"}},
+			prepare:      func() {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid limit",
+			query:        url.Values{QueryLimit: []string{"0"}},
+			prepare:      func() {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock.mockManager.EXPECT().GetResourceProviders().Return(providers).MaxTimes(1)
+			test.prepare()
+
+			req, err := http.NewRequest("GET", GetAllResourcesPath+"?"+test.query.Encode(), nil)
+			assert.NoError(t, err)
+			rr := httptest.NewRecorder()
+
+			mock.handler.ResourceHandler(rr, req)
+			assert.Equal(t, test.expectedCode, rr.Code)
+			if test.expectedCode != http.StatusOK {
+				return
+			}
+			got := map[string]json.RawMessage{}
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+			assert.ElementsMatch(t, test.expectedResources, slices.Collect(maps.Keys(got)))
+			assert.Equal(t, test.expectedNodes, getNodeNames(t, rr, resourceName))
+		})
+	}
+}
+
+// TestIntrospectHandler_Authenticate tests the requests are served only with a bearer token authenticated by a
+// TokenReview
+func TestIntrospectHandler_Authenticate(t *testing.T) {
+	validToken := "valid-token"
+	allowedToken := "allowed-token"
+	clientSet := fake.NewSimpleClientset()
+	clientSet.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		review.Status.Authenticated = review.Spec.Token == validToken || review.Spec.Token == allowedToken
+		review.Status.User = authenticationv1.UserInfo{Username: review.Spec.Token}
+		return true, review, nil
+	})
+	clientSet.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool,
+		runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		review.Status.Allowed = review.Spec.User == allowedToken &&
+			review.Spec.NonResourceAttributes.Path == GetResourcesSummaryPath &&
+			review.Spec.NonResourceAttributes.Verb == "get"
+		return true, review, nil
+	})
+
+	tests := []struct {
+		name           string
+		accessReviewer bool
+		expectedCodes  map[string]int
+	}{
+		{
+			name: "authentication only",
+			expectedCodes: map[string]int{"": http.StatusUnauthorized, "invalid-token": http.StatusUnauthorized,
+				validToken: http.StatusOK, allowedToken: http.StatusOK},
+		},
+		{
+			name:           "authentication and authorization",
+			accessReviewer: true,
+			expectedCodes: map[string]int{"": http.StatusUnauthorized, "invalid-token": http.StatusUnauthorized,
+				validToken: http.StatusForbidden, allowedToken: http.StatusOK},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := IntrospectHandler{
+				Log:           zap.New(),
+				TokenReviewer: clientSet.AuthenticationV1().TokenReviews(),
+			}
+			if test.accessReviewer {
+				handler.AccessReviewer = clientSet.AuthorizationV1().SubjectAccessReviews()
+			}
+			served := handler.authenticate(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			for token, expectedCode := range test.expectedCodes {
+				req, err := http.NewRequest("GET", GetResourcesSummaryPath, nil)
+				assert.NoError(t, err)
+				if token != "" {
+					req.Header.Set("Authorization", "Bearer "+token)
+				}
+				rr := httptest.NewRecorder()
+
+				served(rr, req)
+				assert.Equal(t, expectedCode, rr.Code, "token %q", token)
+			}
+		})
+	}
+}
+
+func TestIntrospectHandler_LookupHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := NewMockIntrospectHandler(ctrl)
+	mockPodAPI := mock_pod.NewMockPodClientAPIWrapper(ctrl)
+	mock.handler.PodAPI = mockPodAPI
+	podUID, prefix := "lookup-pod-uid", "192.168.1.16/28"
+	// The pools keep the IPv4 addresses of the prefixes with a /32 mask and the secondary IPv4 addresses with the mask
+	// of the subnet
+	usedResource := pool.Resource{GroupID: prefix, ResourceID: "192.168.1.17/32"}
+	warmResource := pool.Resource{GroupID: prefix, ResourceID: "192.168.1.18/32"}
+	nodeResponse := pool.IntrospectResponse{
+		UsedResources: map[string]pool.Resource{podUID: usedResource},
+		WarmResources: map[string][]pool.Resource{prefix: {warmResource}},
+	}
+	secondaryIP := pool.Resource{GroupID: "192.168.2.10/19", ResourceID: "192.168.2.10/19"}
+	secondaryIPNodeResponse := pool.IntrospectResponse{
+		WarmResources: map[string][]pool.Resource{secondaryIP.GroupID: {secondaryIP}},
+	}
+	mock.handler.LookupIndex.Assign(nodeName, podUID, usedResource.ResourceID)
+
+	tests := []struct {
+		name             string
+		id               string
+		prepare          func()
+		expectedResponse []LookupResponse
+		expectedCode     int
+	}{
+		{
+			name: "indexed resource",
+			id:   "192.168.1.17",
+			prepare: func() {
+				mock.mockProvider.EXPECT().IntrospectNode(nodeName).Return(nodeResponse)
+				mockPodAPI.EXPECT().ListPods(nodeName).Return(&v1.PodList{Items: []v1.Pod{{ObjectMeta: metav1.ObjectMeta{
+					UID: types.UID(podUID), Namespace: "default", Name: "pod"}}}}, nil)
+			},
+			expectedResponse: []LookupResponse{{ResourceName: resourceName, ResourceID: usedResource.ResourceID,
+				GroupID: prefix, NodeName: nodeName, State: ResourceStateInUse, PodUID: podUID,
+				PodNamespace: "default", PodName: "pod"}},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "prefix",
+			id:   prefix,
+			prepare: func() {
+				mock.mockProvider.EXPECT().Introspect().Return(map[string]pool.IntrospectResponse{nodeName: nodeResponse})
+				mockPodAPI.EXPECT().ListPods(nodeName).Return(&v1.PodList{}, nil)
+			},
+			expectedResponse: []LookupResponse{
+				{ResourceName: resourceName, ResourceID: usedResource.ResourceID, GroupID: prefix, NodeName: nodeName,
+					State: ResourceStateInUse, PodUID: podUID},
+				{ResourceName: resourceName, ResourceID: warmResource.ResourceID, GroupID: prefix, NodeName: nodeName,
+					State: ResourceStateWarm},
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "secondary IP with the subnet mask",
+			id:   "192.168.2.10",
+			prepare: func() {
+				mock.mockProvider.EXPECT().Introspect().Return(
+					map[string]pool.IntrospectResponse{nodeName: secondaryIPNodeResponse})
+			},
+			expectedResponse: []LookupResponse{{ResourceName: resourceName, ResourceID: secondaryIP.ResourceID,
+				GroupID: secondaryIP.GroupID, NodeName: nodeName, State: ResourceStateWarm}},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "not found",
+			id:   "192.168.2.1",
+			prepare: func() {
+				mock.mockProvider.EXPECT().Introspect().Return(map[string]pool.IntrospectResponse{nodeName: nodeResponse})
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "missing id",
+			prepare:      func() {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mock.mockManager.EXPECT().GetResourceProviders().
+				Return(map[string]provider.ResourceProvider{resourceName: mock.mockProvider}).MaxTimes(1)
+			test.prepare()
+
+			req, err := http.NewRequest("GET", LookupResourcePath+"?"+url.Values{QueryID: []string{test.id}}.Encode(), nil)
+			assert.NoError(t, err)
+			rr := httptest.NewRecorder()
+
+			mock.handler.LookupHandler(rr, req)
+			assert.Equal(t, test.expectedCode, rr.Code)
+			if test.expectedCode != http.StatusOK {
+				return
+			}
+			got := struct{ Resources []LookupResponse }{}
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+			assert.Equal(t, test.expectedResponse, got.Resources)
+		})
+	}
+}
@@ -22,10 +22,13 @@ import (
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/handler"
 	rcHealthz "github.com/aws/amazon-vpc-resource-controller-k8s/pkg/healthz"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/lookup"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider/branch"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider/ip"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider/ipv6prefix"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider/prefix"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/timetonetwork"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/worker"
 	"github.com/go-logr/logr"
 
@@ -38,6 +41,7 @@ var (
 	branchProviderHealthCheckSubpath     = "health-branch-provider"
 	ipv4ProviderHealthCheckSubpath       = "health-ipv4-provider"
 	ipv4PrefixProviderHealthCheckSubpath = "health-ipv4-prefix-provider"
+	ipv6PrefixProviderHealthCheckSubpath = "health-ipv6-prefix-provider"
 )
 
 type Manager struct {
@@ -56,11 +60,11 @@ type ResourceManager interface {
 	GetResourceHandler(resourceName string) (handler.Handler, bool)
 }
 
-func NewResourceManager(ctx context.Context, resourceNames []string, wrapper api.Wrapper, log logr.Logger,
-	healthzHandler *rcHealthz.HealthzHandler, conditions condition.Conditions) (ResourceManager, error) {
-	// Load that static configuration of the resource
-	resourceConfig := config.LoadResourceConfig()
-
+// NewResourceManager initializes the provider and handler of each resource with the resource's static configuration,
+// loaded by config.LoadResourceConfig and optionally overridden by the caller
+func NewResourceManager(ctx context.Context, resourceNames []string, resourceConfig map[string]config.ResourceConfig,
+	wrapper api.Wrapper, log logr.Logger, healthzHandler *rcHealthz.HealthzHandler,
+	conditions condition.Conditions, index *lookup.Index, timeToNetwork *timetonetwork.Tracker) (ResourceManager, error) {
 	resources := make(map[string]Resource)
 
 	healthCheckers := make(map[string]healthz.Checker)
@@ -79,7 +83,7 @@ func NewResourceManager(ctx context.Context, resourceNames []string, wrapper api
 		log.Info("initializing resource", "resource name",
 			resourceName, "resource count", resourceConfig.WorkerCount)
 
-		workers := worker.NewDefaultWorkerPool(
+		workers := worker.NewPriorityWorkerPool(
 			resourceConfig.Name,
 			resourceConfig.WorkerCount,
 			config.WorkQueueDefaultMaxRetries,
@@ -90,19 +94,25 @@ func NewResourceManager(ctx context.Context, resourceNames []string, wrapper api
 
 		if resourceName == config.ResourceNameIPAddress {
 			resourceProvider = ip.NewIPv4Provider(ctrl.Log.WithName("ipv4 provider"),
-				wrapper, workers, resourceConfig, conditions)
+				wrapper, workers, resourceConfig, conditions, index)
 			healthCheckers[ipv4ProviderHealthCheckSubpath] = resourceProvider.GetHealthChecker()
 			resourceHandler = handler.NewWarmResourceHandler(ctrl.Log.WithName(resourceName), wrapper,
-				resourceName, resourceProvider, ctx)
+				resourceName, resourceProvider, ctx, timeToNetwork)
 		} else if resourceName == config.ResourceNameIPAddressFromPrefix {
 			resourceProvider = prefix.NewIPv4PrefixProvider(ctrl.Log.WithName("ipv4 prefix provider"),
-				wrapper, workers, resourceConfig, conditions)
+				wrapper, workers, resourceConfig, conditions, index)
 			healthCheckers[ipv4PrefixProviderHealthCheckSubpath] = resourceProvider.GetHealthChecker()
 			resourceHandler = handler.NewWarmResourceHandler(ctrl.Log.WithName(resourceName), wrapper,
-				config.ResourceNameIPAddress, resourceProvider, ctx)
+				config.ResourceNameIPAddress, resourceProvider, ctx, timeToNetwork)
+		} else if resourceName == config.ResourceNameIPv6Address {
+			resourceProvider = ipv6prefix.NewIPv6PrefixProvider(ctrl.Log.WithName("ipv6 prefix provider"),
+				wrapper, workers, resourceConfig, index)
+			healthCheckers[ipv6PrefixProviderHealthCheckSubpath] = resourceProvider.GetHealthChecker()
+			resourceHandler = handler.NewWarmResourceHandler(ctrl.Log.WithName(resourceName), wrapper,
+				resourceName, resourceProvider, ctx, timeToNetwork)
 		} else if resourceName == config.ResourceNamePodENI {
 			resourceProvider = branch.NewBranchENIProvider(ctrl.Log.WithName("branch eni provider"),
-				wrapper, workers, resourceConfig, ctx)
+				wrapper, workers, resourceConfig, ctx, conditions, index, timeToNetwork)
 			healthCheckers[branchProviderHealthCheckSubpath] = resourceProvider.GetHealthChecker()
 			resourceHandler = handler.NewOnDemandHandler(ctrl.Log.WithName(resourceName),
 				resourceName, resourceProvider)
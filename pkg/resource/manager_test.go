@@ -51,7 +51,7 @@ func Test_NewResourceManager_WinPDFeatureON(t *testing.T) {
 
 	mockK8s := mock_k8s.NewMockK8sWrapper(ctrl)
 	conditions := condition.NewControllerConditions(zap.New(), mockK8s, true)
-	manger, err := NewResourceManager(context.TODO(), resources, mock.Wrapper, zap.New(zap.UseDevMode(true)), healthzHandler, conditions)
+	manger, err := NewResourceManager(context.TODO(), resources, config.LoadResourceConfig(), mock.Wrapper, zap.New(zap.UseDevMode(true)), healthzHandler, conditions, nil, nil)
 	assert.NoError(t, err)
 
 	_, ok := manger.GetResourceHandler(config.ResourceNamePodENI)
@@ -85,7 +85,7 @@ func Test_NewResourceManager_Test_NewResourceManager_WinPDFeatureOFF(t *testing.
 
 	mockK8s := mock_k8s.NewMockK8sWrapper(ctrl)
 	conditions := condition.NewControllerConditions(zap.New(), mockK8s, false)
-	manger, err := NewResourceManager(context.TODO(), resources, mock.Wrapper, zap.New(zap.UseDevMode(true)), healthzHandler, conditions)
+	manger, err := NewResourceManager(context.TODO(), resources, config.LoadResourceConfig(), mock.Wrapper, zap.New(zap.UseDevMode(true)), healthzHandler, conditions, nil, nil)
 	assert.NoError(t, err)
 
 	_, ok := manger.GetResourceHandler(config.ResourceNamePodENI)
@@ -0,0 +1,299 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package subnet
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/aws/ec2/api"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
+	rcHealthz "github.com/aws/amazon-vpc-resource-controller-k8s/pkg/healthz"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/k8s"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/resource"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// prefixBlockSize is the number of IPv4 addresses in a /28 prefix block
+	prefixBlockSize = 16
+
+	// warningIPExhaustionPredicted is set when the subnet is predicted to run out of IPv4 addresses within the
+	// warning threshold
+	warningIPExhaustionPredicted = "ip_exhaustion_predicted"
+	// warningPrefixBlocksExhausted is set when the subnet doesn't have enough available IPv4 addresses for a /28 block
+	warningPrefixBlocksExhausted = "prefix_blocks_exhausted"
+)
+
+var (
+	subnetAvailableIPCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "subnet_available_ip_address_count",
+			Help: "The number of available IPv4 addresses in the subnet used by the managed nodes",
+		},
+		[]string{"subnet_id"},
+	)
+	subnetMaxFreePrefixBlockCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "subnet_max_free_prefix_block_count",
+			Help: "The maximum number of /28 blocks that can be assigned in the subnet from its available IPv4 " +
+				"addresses, the fragmentation of the subnet can make fewer blocks assignable",
+		},
+		[]string{"subnet_id"},
+	)
+	subnetIPExhaustionSeconds = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "subnet_ip_exhaustion_forecast_seconds",
+			Help: "The predicted time in seconds till the subnet runs out of IPv4 addresses at the recent allocation rate",
+		},
+		[]string{"subnet_id"},
+	)
+	subnetExhaustionWarning = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "subnet_exhaustion_warning",
+			Help: "Set to 1 if the subnet is predicted to run out of IPv4 addresses or has no /28 block available",
+		},
+		[]string{"subnet_id", "warning"},
+	)
+	subnetMonitorErrCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "subnet_monitor_err_count",
+			Help: "The number of errors encountered while monitoring the subnets",
+		},
+		[]string{"operation"},
+	)
+
+	prometheusRegistered = false
+)
+
+func prometheusRegister() {
+	if !prometheusRegistered {
+		metrics.Registry.MustRegister(
+			subnetAvailableIPCount,
+			subnetMaxFreePrefixBlockCount,
+			subnetIPExhaustionSeconds,
+			subnetExhaustionWarning,
+			subnetMonitorErrCount,
+		)
+
+		prometheusRegistered = true
+	}
+}
+
+// NodeLister lists the managed nodes using each subnet
+type NodeLister interface {
+	GetNodesBySubnet() map[string][]string
+}
+
+// Monitor periodically tracks the available IPv4 addresses of the subnets used by the managed nodes and their branch
+// ENIs, forecasts the time till the subnets run out of IPv4 addresses and warns through metrics and node events before
+// the pods start failing
+type Monitor struct {
+	Log logr.Logger
+	// EC2APIHelper is used to describe the subnets, the subnets are described once per cache TTL across the nodes
+	EC2APIHelper api.EC2APIHelper
+	// K8sAPI is used to send the warning events to the nodes using the subnets
+	K8sAPI     k8s.K8sWrapper
+	NodeLister NodeLister
+	// ResourceManager is used to list the candidate subnets of the branch ENIs
+	ResourceManager resource.ResourceManager
+	// subnets is the state of the monitored subnets, it's only accessed from the monitor routine
+	subnets map[string]*subnetState
+}
+
+// subnetState is the state of a monitored subnet across the monitor runs
+type subnetState struct {
+	// samples is the number of available IPv4 addresses sampled in the forecast window, the oldest sample first
+	samples []sample
+	// exhaustionPredicted is set if the subnet is predicted to run out of IPv4 addresses within the warning threshold
+	exhaustionPredicted bool
+	// prefixBlocksExhausted is set if the subnet doesn't have enough available IPv4 addresses for a /28 block
+	prefixBlocksExhausted bool
+}
+
+type sample struct {
+	time         time.Time
+	availableIPs int
+}
+
+func (m *Monitor) SetupWithManager(mgr ctrl.Manager, healthzHandler *rcHealthz.HealthzHandler) error {
+	m.subnets = make(map[string]*subnetState)
+	prometheusRegister()
+
+	healthzHandler.AddControllersHealthCheckers(
+		map[string]healthz.Checker{"health-subnet-monitor": rcHealthz.SimplePing("subnet monitor", m.Log)},
+	)
+
+	return mgr.Add(m)
+}
+
+// Start runs the subnet monitor routine till the context is cancelled
+func (m *Monitor) Start(ctx context.Context) error {
+	m.Log.Info("starting subnet monitor")
+
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		m.monitorSubnets(ctx, time.Now())
+	}, config.SubnetMonitorInterval)
+
+	return nil
+}
+
+// monitorSubnets checks all the subnets used by the managed nodes and their branch ENIs and stops tracking the subnets
+// no longer in use
+func (m *Monitor) monitorSubnets(ctx context.Context, now time.Time) {
+	nodesBySubnet := m.NodeLister.GetNodesBySubnet()
+	subnetIDs := m.getSubnetIDs(nodesBySubnet)
+
+	for subnetID := range m.subnets {
+		if _, isPresent := subnetIDs[subnetID]; !isPresent {
+			delete(m.subnets, subnetID)
+			subnetAvailableIPCount.DeleteLabelValues(subnetID)
+			subnetMaxFreePrefixBlockCount.DeleteLabelValues(subnetID)
+			subnetIPExhaustionSeconds.DeleteLabelValues(subnetID)
+			subnetExhaustionWarning.DeletePartialMatch(prometheus.Labels{"subnet_id": subnetID})
+		}
+	}
+
+	for subnetID := range subnetIDs {
+		state, isPresent := m.subnets[subnetID]
+		if !isPresent {
+			state = &subnetState{}
+			m.subnets[subnetID] = state
+		}
+		m.checkSubnet(ctx, subnetID, nodesBySubnet[subnetID], state, now)
+	}
+}
+
+// getSubnetIDs returns the subnets used by the managed nodes and the candidate subnets of their branch ENIs
+func (m *Monitor) getSubnetIDs(nodesBySubnet map[string][]string) map[string]struct{} {
+	subnetIDs := make(map[string]struct{})
+	for subnetID := range nodesBySubnet {
+		subnetIDs[subnetID] = struct{}{}
+	}
+
+	resourceProvider, found := m.ResourceManager.GetResourceProvider(config.ResourceNamePodENI)
+	if !found {
+		return subnetIDs
+	}
+	if lister, ok := resourceProvider.(provider.BranchENISubnetsLister); ok {
+		for _, subnetID := range lister.GetBranchENISubnets() {
+			subnetIDs[subnetID] = struct{}{}
+		}
+	}
+	return subnetIDs
+}
+
+// checkSubnet updates the metrics of the subnet and sets the warnings of the subnet when the subnet is predicted to
+// run out of IPv4 addresses or doesn't have enough IPv4 addresses for a /28 block. The warnings are logged and sent
+// as events to the nodes using the subnet when they are first observed
+func (m *Monitor) checkSubnet(ctx context.Context, subnetID string, nodeNames []string, state *subnetState, now time.Time) {
+	log := m.Log.WithValues("subnet id", subnetID)
+
+	subnet, err := m.EC2APIHelper.GetSubnet(ctx, &subnetID)
+	if err != nil {
+		subnetMonitorErrCount.WithLabelValues("describe_subnet").Inc()
+		log.Error(err, "failed to describe subnet")
+		return
+	}
+
+	availableIPs := int(aws.ToInt32(subnet.AvailableIpAddressCount))
+	subnetAvailableIPCount.WithLabelValues(subnetID).Set(float64(availableIPs))
+
+	state.addSample(now, availableIPs)
+	timeToExhaustion, isDepleting := state.timeToExhaustion()
+	if isDepleting {
+		subnetIPExhaustionSeconds.WithLabelValues(subnetID).Set(timeToExhaustion.Seconds())
+	} else {
+		subnetIPExhaustionSeconds.DeleteLabelValues(subnetID)
+	}
+
+	exhaustionPredicted := isDepleting && timeToExhaustion < config.SubnetExhaustionWarningThreshold
+	if exhaustionPredicted && !state.exhaustionPredicted {
+		log.Info("subnet is predicted to run out of IPv4 addresses", "available IPs", availableIPs,
+			"time to exhaustion", timeToExhaustion.Round(time.Minute))
+		m.sendNodeEvents(nodeNames, utils.SubnetIPExhaustionPredictedReason,
+			fmt.Sprintf("Subnet %s is predicted to run out of IPv4 addresses in %s, %d addresses are available",
+				subnetID, timeToExhaustion.Round(time.Minute), availableIPs))
+	}
+	state.exhaustionPredicted = exhaustionPredicted
+	setWarning(subnetID, warningIPExhaustionPredicted, exhaustionPredicted)
+
+	maxFreePrefixBlocks := availableIPs / prefixBlockSize
+	subnetMaxFreePrefixBlockCount.WithLabelValues(subnetID).Set(float64(maxFreePrefixBlocks))
+
+	prefixBlocksExhausted := maxFreePrefixBlocks == 0
+	if prefixBlocksExhausted && !state.prefixBlocksExhausted {
+		log.Info("subnet has no /28 block available, new IPv4 prefixes can't be assigned in the subnet",
+			"available IPs", availableIPs)
+		m.sendNodeEvents(nodeNames, utils.SubnetPrefixBlocksExhaustedReason,
+			fmt.Sprintf("Subnet %s doesn't have enough available IPv4 addresses for a /28 block, new IPv4 prefixes "+
+				"can't be assigned in the subnet", subnetID))
+	}
+	state.prefixBlocksExhausted = prefixBlocksExhausted
+	setWarning(subnetID, warningPrefixBlocksExhausted, prefixBlocksExhausted)
+}
+
+func (m *Monitor) sendNodeEvents(nodeNames []string, reason string, msg string) {
+	for _, nodeName := range nodeNames {
+		utils.SendNodeEventWithNodeName(m.K8sAPI, nodeName, reason, msg, v1.EventTypeWarning, m.Log)
+	}
+}
+
+func setWarning(subnetID string, warning string, isSet bool) {
+	value := 0.0
+	if isSet {
+		value = 1
+	}
+	subnetExhaustionWarning.WithLabelValues(subnetID, warning).Set(value)
+}
+
+// addSample adds the number of available IPv4 addresses and drops the samples older than the forecast window
+func (s *subnetState) addSample(now time.Time, availableIPs int) {
+	s.samples = append(s.samples, sample{time: now, availableIPs: availableIPs})
+
+	windowStart := now.Add(-config.SubnetExhaustionForecastWindow)
+	for len(s.samples) > 1 && s.samples[0].time.Before(windowStart) {
+		s.samples = s.samples[1:]
+	}
+}
+
+// timeToExhaustion returns the time till the subnet runs out of IPv4 addresses at the allocation rate over the
+// forecast window, false is returned if the available IPv4 addresses are not decreasing
+func (s *subnetState) timeToExhaustion() (time.Duration, bool) {
+	if len(s.samples) == 0 {
+		return 0, false
+	}
+	oldest, latest := s.samples[0], s.samples[len(s.samples)-1]
+	if latest.availableIPs == 0 {
+		return 0, true
+	}
+
+	elapsed := latest.time.Sub(oldest.time)
+	allocated := oldest.availableIPs - latest.availableIPs
+	if elapsed <= 0 || allocated <= 0 {
+		return 0, false
+	}
+	return time.Duration(float64(latest.availableIPs) / float64(allocated) * float64(elapsed)), true
+}
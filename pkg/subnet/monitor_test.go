@@ -0,0 +1,244 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package subnet
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	mock_api "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/aws/ec2/api"
+	mock_k8s "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/k8s"
+	mock_manager "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/node/manager"
+	mock_provider "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/provider"
+	mock_resource "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/resource"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+var (
+	subnetID       = "subnet-00000000000000000"
+	branchSubnetID = "subnet-00000000000000001"
+	subnetCidr     = "192.168.0.0/24"
+	nodeName       = "node-1"
+
+	mockError = fmt.Errorf("mock error")
+)
+
+// mockBranchProvider is the branch ENI provider that lists the candidate subnets of the branch ENIs
+type mockBranchProvider struct {
+	*mock_provider.MockResourceProvider
+	*mock_provider.MockBranchENISubnetsLister
+}
+
+func getMockMonitor(ctrl *gomock.Controller) (*Monitor, *mock_api.MockEC2APIHelper, *mock_manager.MockManager,
+	*mock_resource.MockResourceManager, *mock_k8s.MockK8sWrapper) {
+	mockEC2APIHelper := mock_api.NewMockEC2APIHelper(ctrl)
+	mockManager := mock_manager.NewMockManager(ctrl)
+	mockResourceManager := mock_resource.NewMockResourceManager(ctrl)
+	mockK8sAPI := mock_k8s.NewMockK8sWrapper(ctrl)
+
+	return &Monitor{
+		Log:             zap.New(zap.UseDevMode(true)).WithName("subnet monitor"),
+		EC2APIHelper:    mockEC2APIHelper,
+		K8sAPI:          mockK8sAPI,
+		NodeLister:      mockManager,
+		ResourceManager: mockResourceManager,
+		subnets:         make(map[string]*subnetState),
+	}, mockEC2APIHelper, mockManager, mockResourceManager, mockK8sAPI
+}
+
+func getSubnet(subnetID string, availableIPs int32) *ec2types.Subnet {
+	return &ec2types.Subnet{
+		SubnetId:                &subnetID,
+		CidrBlock:               &subnetCidr,
+		AvailableIpAddressCount: aws.Int32(availableIPs),
+	}
+}
+
+func getGaugeValue(gauge prometheus.Gauge) float64 {
+	metric := &dto.Metric{}
+	_ = gauge.Write(metric)
+	return metric.GetGauge().GetValue()
+}
+
+// TestMonitor_monitorSubnets tests the metrics are updated and the warnings are set when the subnet is predicted to
+// run out of IPv4 addresses and doesn't have enough IPv4 addresses for a /28 block, the nodes using the subnet get a
+// warning event once when each warning is first set
+func TestMonitor_monitorSubnets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	monitor, mockEC2APIHelper, mockManager, mockResourceManager, mockK8sAPI := getMockMonitor(ctrl)
+	now := time.Now()
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeName}}
+
+	mockK8sAPI.EXPECT().GetNode(nodeName).Return(node, nil).Times(2)
+	mockK8sAPI.EXPECT().BroadcastEvent(node, utils.SubnetIPExhaustionPredictedReason, gomock.Any(),
+		v1.EventTypeWarning)
+	mockK8sAPI.EXPECT().BroadcastEvent(node, utils.SubnetPrefixBlocksExhaustedReason, gomock.Any(),
+		v1.EventTypeWarning)
+
+	mockManager.EXPECT().GetNodesBySubnet().Return(map[string][]string{subnetID: {nodeName}}).Times(3)
+	mockResourceManager.EXPECT().GetResourceProvider(config.ResourceNamePodENI).Return(nil, false).Times(3)
+	gomock.InOrder(
+		mockEC2APIHelper.EXPECT().GetSubnet(gomock.Any(), &subnetID).Return(getSubnet(subnetID, 100), nil),
+		mockEC2APIHelper.EXPECT().GetSubnet(gomock.Any(), &subnetID).Return(getSubnet(subnetID, 60), nil),
+		mockEC2APIHelper.EXPECT().GetSubnet(gomock.Any(), &subnetID).Return(getSubnet(subnetID, 10), nil),
+	)
+
+	monitor.monitorSubnets(context.TODO(), now)
+	assert.Equal(t, float64(100), getGaugeValue(subnetAvailableIPCount.WithLabelValues(subnetID)))
+	assert.Equal(t, float64(6), getGaugeValue(subnetMaxFreePrefixBlockCount.WithLabelValues(subnetID)))
+	assert.Equal(t, float64(0),
+		getGaugeValue(subnetExhaustionWarning.WithLabelValues(subnetID, warningIPExhaustionPredicted)))
+	assert.False(t, monitor.subnets[subnetID].exhaustionPredicted)
+
+	// 40 IPs allocated in 10 minutes, the remaining 60 IPs will last 15 minutes
+	monitor.monitorSubnets(context.TODO(), now.Add(time.Minute*10))
+	assert.Equal(t, (time.Minute * 15).Seconds(),
+		getGaugeValue(subnetIPExhaustionSeconds.WithLabelValues(subnetID)))
+	assert.Equal(t, float64(1),
+		getGaugeValue(subnetExhaustionWarning.WithLabelValues(subnetID, warningIPExhaustionPredicted)))
+	assert.True(t, monitor.subnets[subnetID].exhaustionPredicted)
+	assert.False(t, monitor.subnets[subnetID].prefixBlocksExhausted)
+
+	monitor.monitorSubnets(context.TODO(), now.Add(time.Minute*20))
+	assert.Equal(t, float64(0), getGaugeValue(subnetMaxFreePrefixBlockCount.WithLabelValues(subnetID)))
+	assert.Equal(t, float64(1),
+		getGaugeValue(subnetExhaustionWarning.WithLabelValues(subnetID, warningPrefixBlocksExhausted)))
+	assert.True(t, monitor.subnets[subnetID].exhaustionPredicted)
+	assert.True(t, monitor.subnets[subnetID].prefixBlocksExhausted)
+}
+
+// TestMonitor_monitorSubnets_BranchENISubnets tests the candidate subnets of the branch ENIs are monitored along with
+// the subnets of the nodes
+func TestMonitor_monitorSubnets_BranchENISubnets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	monitor, mockEC2APIHelper, mockManager, mockResourceManager, _ := getMockMonitor(ctrl)
+	branchProvider := &mockBranchProvider{
+		MockResourceProvider:       mock_provider.NewMockResourceProvider(ctrl),
+		MockBranchENISubnetsLister: mock_provider.NewMockBranchENISubnetsLister(ctrl),
+	}
+
+	mockManager.EXPECT().GetNodesBySubnet().Return(map[string][]string{subnetID: {nodeName}})
+	mockResourceManager.EXPECT().GetResourceProvider(config.ResourceNamePodENI).Return(branchProvider, true)
+	branchProvider.MockBranchENISubnetsLister.EXPECT().GetBranchENISubnets().Return([]string{subnetID, branchSubnetID})
+	mockEC2APIHelper.EXPECT().GetSubnet(gomock.Any(), &subnetID).Return(getSubnet(subnetID, 100), nil)
+	mockEC2APIHelper.EXPECT().GetSubnet(gomock.Any(), &branchSubnetID).Return(getSubnet(branchSubnetID, 50), nil)
+
+	monitor.monitorSubnets(context.TODO(), time.Now())
+	assert.Len(t, monitor.subnets, 2)
+	assert.Equal(t, float64(50), getGaugeValue(subnetAvailableIPCount.WithLabelValues(branchSubnetID)))
+}
+
+// TestMonitor_monitorSubnets_SubnetRemoved tests the subnet is no longer tracked once no node uses it
+func TestMonitor_monitorSubnets_SubnetRemoved(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	monitor, _, mockManager, mockResourceManager, _ := getMockMonitor(ctrl)
+	monitor.subnets[subnetID] = &subnetState{}
+	subnetAvailableIPCount.WithLabelValues(subnetID).Set(10)
+	subnetExhaustionWarning.WithLabelValues(subnetID, warningPrefixBlocksExhausted).Set(1)
+
+	mockManager.EXPECT().GetNodesBySubnet().Return(map[string][]string{})
+	mockResourceManager.EXPECT().GetResourceProvider(config.ResourceNamePodENI).Return(nil, false)
+
+	monitor.monitorSubnets(context.TODO(), time.Now())
+	assert.Empty(t, monitor.subnets)
+	// The metrics were already deleted
+	assert.False(t, subnetAvailableIPCount.DeleteLabelValues(subnetID))
+	assert.False(t, subnetExhaustionWarning.DeleteLabelValues(subnetID, warningPrefixBlocksExhausted))
+}
+
+// TestMonitor_monitorSubnets_DescribeError tests the subnet state is retained if the subnet couldn't be described
+func TestMonitor_monitorSubnets_DescribeError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	monitor, mockEC2APIHelper, mockManager, mockResourceManager, _ := getMockMonitor(ctrl)
+	state := &subnetState{samples: []sample{{time: time.Now(), availableIPs: 10}}}
+	monitor.subnets[subnetID] = state
+
+	mockManager.EXPECT().GetNodesBySubnet().Return(map[string][]string{subnetID: {nodeName}})
+	mockResourceManager.EXPECT().GetResourceProvider(config.ResourceNamePodENI).Return(nil, false)
+	mockEC2APIHelper.EXPECT().GetSubnet(gomock.Any(), &subnetID).Return(nil, mockError)
+
+	monitor.monitorSubnets(context.TODO(), time.Now())
+	assert.Len(t, monitor.subnets[subnetID].samples, 1)
+}
+
+// TestSubnetState_timeToExhaustion tests the time to exhaustion is computed from the samples in the forecast window
+func TestSubnetState_timeToExhaustion(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name         string
+		samples      []sample
+		expected     time.Duration
+		isDepleting  bool
+		expectedSize int
+	}{
+		{
+			name:         "single sample",
+			samples:      []sample{{time: now, availableIPs: 10}},
+			expectedSize: 1,
+		},
+		{
+			name:         "no allocation",
+			samples:      []sample{{time: now, availableIPs: 10}, {time: now.Add(time.Minute), availableIPs: 12}},
+			expectedSize: 2,
+		},
+		{
+			name:         "exhausted",
+			samples:      []sample{{time: now, availableIPs: 0}},
+			isDepleting:  true,
+			expectedSize: 1,
+		},
+		{
+			name: "samples older than the window are dropped",
+			samples: []sample{{time: now.Add(-time.Hour), availableIPs: 1000},
+				{time: now.Add(time.Minute * 5), availableIPs: 30}, {time: now.Add(time.Minute * 10), availableIPs: 20}},
+			expected:     time.Minute * 10,
+			isDepleting:  true,
+			expectedSize: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := &subnetState{}
+			for _, s := range test.samples {
+				state.addSample(s.time, s.availableIPs)
+			}
+			timeToExhaustion, isDepleting := state.timeToExhaustion()
+			assert.Equal(t, test.isDepleting, isDepleting)
+			assert.Equal(t, test.expected, timeToExhaustion)
+			assert.Len(t, state.samples, test.expectedSize)
+		})
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timetonetwork
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/k8s"
+
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// ReasonTimeToNetworkSLOExceeded is the reason of the event on the pods that waited longer than the SLO for
+	// the resources to be annotated
+	ReasonTimeToNetworkSLOExceeded = "TimeToNetworkSLOExceeded"
+
+	// Outcomes of the wait of the pods for the resources
+	OutcomeAnnotated = "annotated"
+	OutcomeAbandoned = "abandoned"
+
+	// Labels of the node group of the node, in order of precedence
+	NodeGroupLabelEKS       = "eks.amazonaws.com/nodegroup"
+	NodeGroupLabelKarpenter = "karpenter.sh/nodepool"
+	NodeGroupUnknown        = "unknown"
+)
+
+var (
+	podTimeToNetworkSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "pod_time_to_network_seconds",
+			Help: "The time from the pod being scheduled, or created if not scheduled yet, to the pod being annotated " +
+				"with the resource, or to the pod being deleted or completed before being annotated",
+			Buckets: []float64{0.5, 1, 2, 3, 5, 10, 20, 30, 60, 120, 300},
+		},
+		[]string{"resource", "node_group", "outcome"},
+	)
+
+	prometheusRegistered = false
+)
+
+func prometheusRegister() {
+	if !prometheusRegistered {
+		metrics.Registry.MustRegister(podTimeToNetworkSeconds)
+
+		prometheusRegistered = true
+	}
+}
+
+// podResource is a resource requested by a pod
+type podResource struct {
+	uid          types.UID
+	resourceName string
+}
+
+// Tracker measures the time the pods wait for their resources. The pod controller tracks the resources of the pods
+// it sees without the annotation, and the handlers observe the wait once they annotate the pod. Only the pods seen by
+// the controller without the annotation are tracked, so the pods annotated before the controller started are not
+// observed. The methods of a nil tracker don't record anything
+type Tracker struct {
+	k8sAPI k8s.K8sWrapper
+	// slo is the wait after which a warning event is broadcast on the pod, the events are disabled if 0
+	slo  time.Duration
+	lock sync.Mutex
+	// pending are the tracked resources of the pods, set to true once the pod is annotated with the resource
+	pending map[podResource]bool
+}
+
+// NewTracker returns a tracker that broadcasts a warning event on the pods that waited longer than the SLO
+func NewTracker(k8sAPI k8s.K8sWrapper, slo time.Duration) *Tracker {
+	prometheusRegister()
+
+	return &Tracker{k8sAPI: k8sAPI, slo: slo, pending: make(map[podResource]bool)}
+}
+
+// Track tracks the resource of the pod seen by the pod controller. The resource is tracked until the controller sees
+// the pod with the annotation, or the wait is observed as abandoned when the pod is deleted or completes before it's
+// annotated
+func (t *Tracker) Track(pod *v1.Pod, resourceName string, isDeleted bool) {
+	if t == nil {
+		return
+	}
+	if isDeleted {
+		if t.remove(podResource{uid: pod.UID, resourceName: resourceName}) {
+			t.observe(pod, resourceName, OutcomeAbandoned)
+		}
+		return
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	key := podResource{uid: pod.UID, resourceName: resourceName}
+	if _, ok := pod.Annotations[resourceName]; ok {
+		// The later events of the pod are not older than the annotation, so the resource is not tracked anymore
+		delete(t.pending, key)
+		return
+	}
+	// The pod may still be seen without the annotation after it's annotated, until the cache is updated
+	if _, tracked := t.pending[key]; !tracked {
+		t.pending[key] = false
+	}
+}
+
+// remove stops tracking the resource of the pod and returns true if the pod was waiting for the resource
+func (t *Tracker) remove(key podResource) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	annotated, tracked := t.pending[key]
+	delete(t.pending, key)
+	return tracked && !annotated
+}
+
+// Annotated observes the time the pod waited for the resource once the handler annotated the pod with the resource
+func (t *Tracker) Annotated(pod *v1.Pod, resourceName string) {
+	if t == nil {
+		return
+	}
+	if t.markAnnotated(podResource{uid: pod.UID, resourceName: resourceName}) {
+		t.observe(pod, resourceName, OutcomeAnnotated)
+	}
+}
+
+// markAnnotated marks the resource of the pod as annotated and returns true if the pod was waiting for the resource
+func (t *Tracker) markAnnotated(key podResource) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if annotated, tracked := t.pending[key]; !tracked || annotated {
+		return false
+	}
+	t.pending[key] = true
+	return true
+}
+
+// Forget stops tracking the resources of the pod without observing the wait
+func (t *Tracker) Forget(uid types.UID) {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for key := range t.pending {
+		if key.uid == uid {
+			delete(t.pending, key)
+		}
+	}
+}
+
+// observe observes the time the pod waited for the resource, and broadcasts a warning event on the pod if it was
+// annotated after the SLO
+func (t *Tracker) observe(pod *v1.Pod, resourceName string, outcome string) {
+	timeToNetwork := time.Since(getPodScheduledTime(pod))
+	podTimeToNetworkSeconds.WithLabelValues(resourceName, t.getNodeGroup(pod.Spec.NodeName), outcome).
+		Observe(timeToNetwork.Seconds())
+
+	if outcome == OutcomeAnnotated && t.slo > 0 && timeToNetwork > t.slo {
+		t.k8sAPI.BroadcastEvent(pod, ReasonTimeToNetworkSLOExceeded,
+			fmt.Sprintf("Pod waited %s for %s, longer than the SLO of %s",
+				timeToNetwork.Round(time.Second), resourceName, t.slo), v1.EventTypeWarning)
+	}
+}
+
+// getNodeGroup returns the name of the node group or node pool of the node
+func (t *Tracker) getNodeGroup(nodeName string) string {
+	node, err := t.k8sAPI.GetNode(nodeName)
+	if err != nil {
+		return NodeGroupUnknown
+	}
+	for _, label := range []string{NodeGroupLabelEKS, NodeGroupLabelKarpenter} {
+		if nodeGroup, ok := node.Labels[label]; ok {
+			return nodeGroup
+		}
+	}
+	return NodeGroupUnknown
+}
+
+// getPodScheduledTime returns the time the pod was scheduled, or created if the scheduled condition is not set
+func getPodScheduledTime(pod *v1.Pod) time.Time {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodScheduled && condition.Status == v1.ConditionTrue {
+			return condition.LastTransitionTime.Time
+		}
+	}
+	return pod.CreationTimestamp.Time
+}
@@ -0,0 +1,186 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package timetonetwork
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	mock_k8s "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/k8s"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var (
+	resourceName = config.ResourceNamePodENI
+	nodeName     = "node-1"
+	nodeGroup    = "node-group-1"
+	node         = &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeName,
+		Labels: map[string]string{NodeGroupLabelEKS: nodeGroup}}}
+)
+
+// getTimeToNetworkCount returns the number of observations of the time to network
+func getTimeToNetworkCount(t *testing.T, nodeGroup string, outcome string) uint64 {
+	metric := &dto.Metric{}
+	err := podTimeToNetworkSeconds.WithLabelValues(resourceName, nodeGroup, outcome).(prometheus.Histogram).
+		Write(metric)
+	assert.NoError(t, err)
+	return metric.GetHistogram().GetSampleCount()
+}
+
+// getScheduledPod returns a pod without the annotations that was scheduled at the time
+func getScheduledPod(uid string, scheduledAt time.Time) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-" + uid, Namespace: "default", UID: types.UID("uid-" + uid),
+			Annotations: map[string]string{}},
+		Spec: v1.PodSpec{NodeName: nodeName},
+		Status: v1.PodStatus{Conditions: []v1.PodCondition{{Type: v1.PodScheduled, Status: v1.ConditionTrue,
+			LastTransitionTime: metav1.NewTime(scheduledAt)}}},
+	}
+}
+
+// getAnnotatedPod returns a copy of the pod annotated with the resource
+func getAnnotatedPod(pod *v1.Pod) *v1.Pod {
+	annotatedPod := pod.DeepCopy()
+	annotatedPod.Annotations[resourceName] = "annotation"
+	return annotatedPod
+}
+
+// TestTracker_Annotated tests the time to network is observed once when the handler annotates the pod, and the event
+// is broadcast when it's longer than the SLO
+func TestTracker_Annotated(t *testing.T) {
+	tests := []struct {
+		name        string
+		scheduledAt time.Time
+		expectEvent bool
+	}{
+		{name: "within SLO", scheduledAt: time.Now().Add(-time.Second)},
+		{name: "SLO exceeded", scheduledAt: time.Now().Add(-time.Minute), expectEvent: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockK8sAPI := mock_k8s.NewMockK8sWrapper(ctrl)
+			tracker := NewTracker(mockK8sAPI, 30*time.Second)
+
+			pod := getScheduledPod(test.name, test.scheduledAt)
+			before := getTimeToNetworkCount(t, nodeGroup, OutcomeAnnotated)
+
+			mockK8sAPI.EXPECT().GetNode(nodeName).Return(node, nil)
+			if test.expectEvent {
+				mockK8sAPI.EXPECT().BroadcastEvent(pod, ReasonTimeToNetworkSLOExceeded, gomock.Any(),
+					v1.EventTypeWarning)
+			}
+
+			tracker.Track(pod, resourceName, false)
+			tracker.Annotated(pod, resourceName)
+			// The pod seen without the annotation before the cache is updated is not observed again
+			tracker.Track(pod, resourceName, false)
+			tracker.Annotated(pod, resourceName)
+			tracker.Track(getAnnotatedPod(pod), resourceName, false)
+
+			assert.Equal(t, before+1, getTimeToNetworkCount(t, nodeGroup, OutcomeAnnotated))
+			assert.Empty(t, tracker.pending)
+		})
+	}
+}
+
+// TestTracker_Abandoned tests the time to network is observed as abandoned when the pod is deleted before being
+// annotated, and the node group is unknown if the node doesn't exist
+func TestTracker_Abandoned(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockK8sAPI := mock_k8s.NewMockK8sWrapper(ctrl)
+	tracker := NewTracker(mockK8sAPI, time.Second)
+
+	pod := getScheduledPod("abandoned", time.Now().Add(-time.Minute))
+	before := getTimeToNetworkCount(t, NodeGroupUnknown, OutcomeAbandoned)
+
+	mockK8sAPI.EXPECT().GetNode(nodeName).Return(nil, fmt.Errorf("not found"))
+
+	tracker.Track(pod, resourceName, false)
+	tracker.Track(pod, resourceName, true)
+
+	assert.Equal(t, before+1, getTimeToNetworkCount(t, NodeGroupUnknown, OutcomeAbandoned))
+	assert.Empty(t, tracker.pending)
+}
+
+// TestTracker_NotPending tests the pods annotated or deleted without being seen waiting for the annotation are not
+// observed, and the pods deleted after being annotated are not observed as abandoned
+func TestTracker_NotPending(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tracker := NewTracker(mock_k8s.NewMockK8sWrapper(ctrl), time.Second)
+
+	pod := getScheduledPod("not-pending", time.Now().Add(-time.Minute))
+	tracker.Track(getAnnotatedPod(pod), resourceName, false)
+	tracker.Annotated(pod, resourceName)
+	tracker.Track(pod, resourceName, true)
+
+	assert.Empty(t, tracker.pending)
+}
+
+// TestTracker_Forget tests all the resources of the pod are not tracked anymore without being observed
+func TestTracker_Forget(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tracker := NewTracker(mock_k8s.NewMockK8sWrapper(ctrl), time.Second)
+
+	pod := getScheduledPod("forget", time.Now().Add(-time.Minute))
+	otherPod := getScheduledPod("other", time.Now().Add(-time.Minute))
+	tracker.Track(pod, resourceName, false)
+	tracker.Track(pod, config.ResourceNameIPAddress, false)
+	tracker.Track(otherPod, resourceName, false)
+
+	tracker.Forget(pod.UID)
+	tracker.Annotated(pod, resourceName)
+	tracker.Track(pod, config.ResourceNameIPAddress, true)
+
+	assert.Equal(t, map[podResource]bool{{uid: otherPod.UID, resourceName: resourceName}: false}, tracker.pending)
+}
+
+// TestTracker_Nil tests the methods of a nil tracker don't record anything
+func TestTracker_Nil(t *testing.T) {
+	var tracker *Tracker
+
+	pod := getScheduledPod("nil", time.Now())
+	tracker.Track(pod, resourceName, false)
+	tracker.Annotated(pod, resourceName)
+	tracker.Track(pod, resourceName, true)
+	tracker.Forget(pod.UID)
+}
+
+// TestGetPodScheduledTime tests the creation time is used if the pod is not scheduled
+func TestGetPodScheduledTime(t *testing.T) {
+	scheduledAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+	pod := getScheduledPod("scheduled", scheduledAt)
+	assert.Equal(t, scheduledAt, getPodScheduledTime(pod))
+
+	pod.Status.Conditions = nil
+	pod.CreationTimestamp = metav1.NewTime(scheduledAt.Add(-time.Second))
+	assert.Equal(t, scheduledAt.Add(-time.Second), getPodScheduledTime(pod))
+}
@@ -0,0 +1,111 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package tracing traces the allocation of the resources to the pods with OpenTelemetry spans. The spans are dropped
+// unless Init is called with a collector endpoint
+package tracing
+
+import (
+	"context"
+
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/version"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	tracerName = "github.com/aws/amazon-vpc-resource-controller-k8s"
+
+	// Attribute keys of the spans
+	AttributePodNamespace = attribute.Key("k8s.pod.namespace")
+	AttributePodName      = attribute.Key("k8s.pod.name")
+	AttributePodUID       = attribute.Key("k8s.pod.uid")
+	AttributeNodeName     = attribute.Key("k8s.node.name")
+	AttributeResourceName = attribute.Key("vpc.resource.name")
+	AttributeRequestCount = attribute.Key("vpc.resource.request_count")
+	AttributeJobOperation = attribute.Key("vpc.job.operation")
+	AttributeEC2Operation = attribute.Key("aws.ec2.operation")
+)
+
+// Config is the configuration of the export of the spans
+type Config struct {
+	// Endpoint is the host:port of the OTLP HTTP collector, tracing is disabled if empty
+	Endpoint string
+	// Insecure exports the spans over HTTP instead of HTTPS
+	Insecure bool
+	// SampleRatio is the ratio of the traces started by the controller that are sampled
+	SampleRatio float64
+}
+
+// Init exports the spans to the OTLP collector of the configuration and returns the function that flushes the
+// remaining spans and stops the export. Nothing is exported if the endpoint is not set
+func Init(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	if cfg.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+	if cfg.Insecure {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(sdkresource.NewSchemaless(
+			attribute.String("service.name", config.ControllerName),
+			attribute.String("service.version", version.GitVersion),
+		)),
+	)
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return tracerProvider.Shutdown, nil
+}
+
+// Start starts a span that is a child of the span in the context, and returns the context with the new span
+func Start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// End records the error on the span if it's not nil and ends the span
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// PodAttributes returns the attributes that identify the pod
+func PodAttributes(pod *v1.Pod) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		AttributePodNamespace.String(pod.Namespace),
+		AttributePodName.String(pod.Name),
+		AttributePodUID.String(string(pod.UID)),
+		AttributeNodeName.String(pod.Spec.NodeName),
+	}
+}
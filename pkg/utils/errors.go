@@ -15,6 +15,7 @@ package utils
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 )
 
@@ -35,3 +36,16 @@ func ShouldRetryOnError(err error) bool {
 	}
 	return true
 }
+
+// SecurityGroupLimitExceededError is returned when the security groups resolved for a pod exceed the number of
+// security groups that can be associated with a network interface
+type SecurityGroupLimitExceededError struct {
+	SecurityGroups []string
+	Policies       []string
+	Limit          int
+}
+
+func (e *SecurityGroupLimitExceededError) Error() string {
+	return fmt.Sprintf("security groups %v resolved from policies %v exceed the limit of %d security groups per "+
+		"network interface", e.SecurityGroups, e.Policies, e.Limit)
+}
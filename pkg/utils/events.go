@@ -30,6 +30,8 @@ const (
 	BranchENICoolDownUpdateReason       = "BranchENICoolDownPeriodUpdated"
 	CNINodeDeleteFailed                 = "CNINodeDeletionFailed"
 	CNINodeCreateFailed                 = "CNINodeCreationFailed"
+	SubnetIPExhaustionPredictedReason   = "SubnetIPExhaustionPredicted"
+	SubnetPrefixBlocksExhaustedReason   = "SubnetPrefixBlocksExhausted"
 )
 
 func SendNodeEventWithNodeName(client k8s.K8sWrapper, nodeName, reason, msg, eventType string, logger logr.Logger) {
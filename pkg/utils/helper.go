@@ -17,7 +17,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/netip"
 	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -33,6 +36,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -59,25 +63,80 @@ func RemoveDuplicatedSg(list []string) []string {
 
 type SecurityGroupForPodsAPI interface {
 	GetMatchingSecurityGroupForPods(pod *corev1.Pod) ([]string, error)
+	ResolveSecurityGroupsForPod(pod *corev1.Pod) (*SecurityGroupResolution, error)
 }
 
 type SecurityGroupForPods struct {
 	Client client.Client
 	Log    logr.Logger
+	// MaxSecurityGroupsPerENI is the maximum number of security groups that can be resolved for a pod, zero
+	// disables the check
+	MaxSecurityGroupsPerENI int
+}
+
+// MatchedSecurityGroupPolicy is a SecurityGroupPolicy or a ClusterSecurityGroupPolicy matching a pod
+type MatchedSecurityGroupPolicy struct {
+	// Name is namespace/name of the SecurityGroupPolicy or the name of the ClusterSecurityGroupPolicy
+	Name           string
+	Priority       int32
+	MergeMode      vpcresourcesv1beta1.MergeMode
+	SecurityGroups []string
+}
+
+func (m MatchedSecurityGroupPolicy) String() string {
+	return fmt.Sprintf("%s(priority=%d)", m.Name, m.Priority)
+}
+
+// SecurityGroupResolution contains the security groups resolved for a pod from the policies matching the pod
+type SecurityGroupResolution struct {
+	// SecurityGroups is the list of security groups that should be associated with the pod
+	SecurityGroups []string
+	// MatchedPolicies is the list of policies matching the pod sorted by descending priority
+	MatchedPolicies []MatchedSecurityGroupPolicy
+	// AppliedPolicies is the list of matched policies whose security groups were applied to the pod
+	AppliedPolicies []MatchedSecurityGroupPolicy
+}
+
+// HasConflict returns true if the pod matched more than one policy with different security groups
+func (r *SecurityGroupResolution) HasConflict() bool {
+	if len(r.MatchedPolicies) < 2 {
+		return false
+	}
+	securityGroups := sets.New(r.MatchedPolicies[0].SecurityGroups...)
+	for _, policy := range r.MatchedPolicies[1:] {
+		if !securityGroups.Equal(sets.New(policy.SecurityGroups...)) {
+			return true
+		}
+	}
+	return false
 }
 
 // NewSecurityGroupForPodsAPI returns the SecurityGroupForPod APIs for common operations on objects
 // Using Security Group Policy
-func NewSecurityGroupForPodsAPI(client client.Client, log logr.Logger) SecurityGroupForPodsAPI {
+func NewSecurityGroupForPodsAPI(client client.Client, maxSecurityGroupsPerENI int, log logr.Logger) SecurityGroupForPodsAPI {
 	return &SecurityGroupForPods{
-		Client: client,
-		Log:    log,
+		Client:                  client,
+		Log:                     log,
+		MaxSecurityGroupsPerENI: maxSecurityGroupsPerENI,
 	}
 }
 
 // GetMatchingSecurityGroupForPods returns the list of security groups that should be associated
-// with the Pod by matching against all the SecurityGroupPolicy
+// with the Pod by matching against all the SecurityGroupPolicy in the Pod namespace and all the
+// ClusterSecurityGroupPolicy
 func (s *SecurityGroupForPods) GetMatchingSecurityGroupForPods(pod *corev1.Pod) ([]string, error) {
+	resolution, err := s.ResolveSecurityGroupsForPod(pod)
+	if err != nil {
+		return nil, err
+	}
+	return resolution.SecurityGroups, nil
+}
+
+// ResolveSecurityGroupsForPod matches the Pod against all the SecurityGroupPolicy in the Pod namespace and all the
+// ClusterSecurityGroupPolicy and resolves the security groups of the matching policies using their priority and
+// merge mode. The resolution is returned along with a SecurityGroupLimitExceededError if the resolved security
+// groups exceed the limit per network interface
+func (s *SecurityGroupForPods) ResolveSecurityGroupsForPod(pod *corev1.Pod) (*SecurityGroupResolution, error) {
 	helperLog := s.Log.WithValues("Pod name", pod.Name, "Pod namespace", pod.Namespace)
 
 	// Build SGP list from cache.
@@ -91,7 +150,7 @@ func (s *SecurityGroupForPods) GetMatchingSecurityGroupForPods(pod *corev1.Pod)
 			helperLog.Error(err,
 				"Webhook couldn't find SGP definition: "+
 					"GroupVersionResource or GroupKind didn't match. Will allow regular pods creation.")
-			return nil, nil
+			return &SecurityGroupResolution{}, nil
 		}
 		helperLog.Error(err, "Client Listing SGP failed in Webhook.")
 		return nil, err
@@ -108,20 +167,131 @@ func (s *SecurityGroupForPods) GetMatchingSecurityGroupForPods(pod *corev1.Pod)
 		return nil, err
 	}
 
-	sgList := s.filterPodSecurityGroups(sgpList, pod, sa)
-	if len(sgList) > 0 {
+	matchedPolicies := s.filterPodSecurityGroups(sgpList, pod, sa)
+
+	clusterMatchedPolicies, err := s.getMatchingClusterSecurityGroupPolicies(ctx, pod, sa)
+	if err != nil {
+		helperLog.Error(err, "Failed matching ClusterSecurityGroupPolicy in Webhook.")
+		return nil, err
+	}
+
+	resolution := &SecurityGroupResolution{MatchedPolicies: append(matchedPolicies, clusterMatchedPolicies...)}
+	resolution.SecurityGroups, resolution.AppliedPolicies = resolveSecurityGroupPolicies(resolution.MatchedPolicies)
+
+	if len(resolution.SecurityGroups) > 0 {
 		helperLog.V(1).Info("Pod matched a SecurityGroupPolicy and will get the following Security Groups:",
-			"Security Groups", sgList)
+			"Security Groups", resolution.SecurityGroups, "Policies", resolution.AppliedPolicies)
+	}
+
+	if s.MaxSecurityGroupsPerENI > 0 && len(resolution.SecurityGroups) > s.MaxSecurityGroupsPerENI {
+		var policies []string
+		for _, policy := range resolution.AppliedPolicies {
+			policies = append(policies, policy.Name)
+		}
+		return resolution, &SecurityGroupLimitExceededError{
+			SecurityGroups: resolution.SecurityGroups,
+			Policies:       policies,
+			Limit:          s.MaxSecurityGroupsPerENI,
+		}
+	}
+	return resolution, nil
+}
+
+// resolveSecurityGroupPolicies sorts the matched policies by descending priority and returns the security groups
+// and the policies applied to the pod. The security groups of all the policies are merged unless one of the highest
+// priority policies has the HighestPriorityWins merge mode, in which case only the highest priority policies apply
+func resolveSecurityGroupPolicies(matchedPolicies []MatchedSecurityGroupPolicy) ([]string, []MatchedSecurityGroupPolicy) {
+	if len(matchedPolicies) == 0 {
+		return nil, nil
+	}
+
+	sort.SliceStable(matchedPolicies, func(i, j int) bool {
+		return matchedPolicies[i].Priority > matchedPolicies[j].Priority
+	})
+
+	appliedPolicies := matchedPolicies
+	highestPriority := matchedPolicies[0].Priority
+	for i, policy := range matchedPolicies {
+		if policy.Priority != highestPriority {
+			break
+		}
+		if policy.MergeMode == vpcresourcesv1beta1.MergeModeHighestPriorityWins {
+			appliedPolicies = matchedPolicies[:i+1]
+			for _, samePriorityPolicy := range matchedPolicies[i+1:] {
+				if samePriorityPolicy.Priority != highestPriority {
+					break
+				}
+				appliedPolicies = append(appliedPolicies, samePriorityPolicy)
+			}
+			break
+		}
+	}
+
+	var sgList []string
+	for _, policy := range appliedPolicies {
+		sgList = append(sgList, policy.SecurityGroups...)
+	}
+	return RemoveDuplicatedSg(sgList), appliedPolicies
+}
+
+// getMatchingClusterSecurityGroupPolicies returns all the ClusterSecurityGroupPolicy matching the Pod and its namespace
+func (s *SecurityGroupForPods) getMatchingClusterSecurityGroupPolicies(ctx context.Context, pod *corev1.Pod,
+	sa *corev1.ServiceAccount) ([]MatchedSecurityGroupPolicy, error) {
+	csgpList := &vpcresourcesv1beta1.ClusterSecurityGroupPolicyList{}
+	if err := s.Client.List(ctx, csgpList); err != nil {
+		// The ClusterSecurityGroupPolicy CRD is optional, only the SecurityGroupPolicy will be used if it's not installed
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(csgpList.Items) == 0 {
+		return nil, nil
+	}
+
+	// Get the namespace labels from cache
+	namespace := &corev1.Namespace{}
+	if err := s.Client.Get(ctx, types.NamespacedName{Name: pod.Namespace}, namespace); err != nil {
+		return nil, err
+	}
+
+	return s.filterPodClusterSecurityGroups(csgpList, pod, sa, namespace), nil
+}
+
+func (s *SecurityGroupForPods) filterPodClusterSecurityGroups(
+	csgpList *vpcresourcesv1beta1.ClusterSecurityGroupPolicyList,
+	pod *corev1.Pod,
+	sa *corev1.ServiceAccount,
+	namespace *corev1.Namespace,
+) []MatchedSecurityGroupPolicy {
+	var matchedPolicies []MatchedSecurityGroupPolicy
+	csgpLogger := s.Log.WithValues("Pod name", pod.Name, "Pod namespace", pod.Namespace)
+	for _, csgp := range csgpList.Items {
+		if len(csgp.Spec.SecurityGroups.Groups) == 0 {
+			csgpLogger.Info("Found an invalid ClusterSecurityGroupPolicy due to security groups is nil or empty.",
+				"Invalid CSGP", csgp.Name)
+			continue
+		}
+
+		if matched, err := IsPodMatchingClusterSecurityGroupPolicy(&csgp, pod, sa, namespace); err != nil {
+			csgpLogger.Error(err, "Failed converting CSGP selectors to match pod.", "CSGP name", csgp.Name)
+			continue
+		} else if !matched {
+			continue
+		}
+
+		matchedPolicies = append(matchedPolicies, newMatchedSecurityGroupPolicy(csgp.Name, &csgp.Spec.SecurityGroupPolicySpec))
 	}
-	return sgList, nil
+
+	return matchedPolicies
 }
 
 func (s *SecurityGroupForPods) filterPodSecurityGroups(
 	sgpList *vpcresourcesv1beta1.SecurityGroupPolicyList,
 	pod *corev1.Pod,
 	sa *corev1.ServiceAccount,
-) []string {
-	var sgList []string
+) []MatchedSecurityGroupPolicy {
+	var matchedPolicies []MatchedSecurityGroupPolicy
 	sgpLogger := s.Log.WithValues("Pod name", pod.Name, "Pod namespace", pod.Namespace)
 	for _, sgp := range sgpList.Items {
 		hasPodSelector := sgp.Spec.PodSelector != nil
@@ -137,34 +307,86 @@ func (s *SecurityGroupForPods) filterPodSecurityGroups(
 			continue
 		}
 
-		podMatched, saMatched := false, false
-		if podSelector, podSelectorError := metav1.LabelSelectorAsSelector(sgp.Spec.PodSelector); podSelectorError == nil {
-			if podSelector.Matches(labels.Set(pod.Labels)) {
-				podMatched = true
-			}
-		} else {
-			sgpLogger.Error(podSelectorError, "Failed converting SGP pod selector to match pod labels.",
+		if matched, err := IsPodMatchingSecurityGroupPolicy(&sgp.Spec, pod, sa); err != nil {
+			sgpLogger.Error(err, "Failed converting SGP selectors to match pod.",
 				"SGP name", sgp.Name, "SGP namespace", sgp.Namespace)
+			continue
+		} else if !matched {
+			continue
 		}
 
-		if saSelector, saSelectorError := metav1.LabelSelectorAsSelector(sgp.Spec.ServiceAccountSelector); saSelectorError == nil {
-			if saSelector.Matches(labels.Set(sa.Labels)) {
-				saMatched = true
-			}
-		} else {
-			sgpLogger.Error(saSelectorError, "Failed converting SGP SA selector to match pod labels.",
-				"SGP name", sgp.Name, "SGP namespace", sgp.Namespace)
-		}
+		matchedPolicies = append(matchedPolicies, newMatchedSecurityGroupPolicy(
+			types.NamespacedName{Namespace: sgp.Namespace, Name: sgp.Name}.String(), &sgp.Spec))
+	}
 
-		if (hasPodSelector && !podMatched) || (hasSASelector && !saMatched) {
-			continue
+	return matchedPolicies
+}
+
+func newMatchedSecurityGroupPolicy(name string, spec *vpcresourcesv1beta1.SecurityGroupPolicySpec) MatchedSecurityGroupPolicy {
+	return MatchedSecurityGroupPolicy{
+		Name:           name,
+		Priority:       spec.Priority,
+		MergeMode:      spec.MergeMode,
+		SecurityGroups: RemoveDuplicatedSg(spec.SecurityGroups.Groups),
+	}
+}
+
+// IsPodMatchingClusterSecurityGroupPolicy returns true if the pod namespace labels match the namespace selector and the
+// pod matches the pod and service account selectors of the ClusterSecurityGroupPolicy.
+func IsPodMatchingClusterSecurityGroupPolicy(csgp *vpcresourcesv1beta1.ClusterSecurityGroupPolicy, pod *corev1.Pod,
+	sa *corev1.ServiceAccount, namespace *corev1.Namespace) (bool, error) {
+	// Policy without a namespace selector doesn't match any namespace
+	if csgp.Spec.NamespaceSelector == nil {
+		return false, nil
+	}
+	namespaceSelector, err := metav1.LabelSelectorAsSelector(csgp.Spec.NamespaceSelector)
+	if err != nil {
+		return false, err
+	}
+	if !namespaceSelector.Matches(labels.Set(namespace.Labels)) {
+		return false, nil
+	}
+	return IsPodMatchingSecurityGroupPolicy(&csgp.Spec.SecurityGroupPolicySpec, pod, sa)
+}
+
+// IsPodMatchingSecurityGroupPolicy returns true if the pod labels match the pod selector and the pod service account
+// matches the service account selector of the policy spec. A nil selector is not used for matching.
+func IsPodMatchingSecurityGroupPolicy(spec *vpcresourcesv1beta1.SecurityGroupPolicySpec, pod *corev1.Pod,
+	sa *corev1.ServiceAccount) (bool, error) {
+	if spec.PodSelector != nil {
+		podSelector, err := metav1.LabelSelectorAsSelector(spec.PodSelector)
+		if err != nil {
+			return false, err
+		}
+		if !podSelector.Matches(labels.Set(pod.Labels)) {
+			return false, nil
 		}
+	}
+
+	if spec.ServiceAccountSelector != nil {
+		return isServiceAccountMatchingSelector(spec.ServiceAccountSelector, pod.Spec.ServiceAccountName, sa)
+	}
+	return true, nil
+}
 
-		sgList = append(sgList, sgp.Spec.SecurityGroups.Groups...)
+// isServiceAccountMatchingSelector returns true if the service account matches both the label selector and the
+// names of the ServiceAccountSelector. An empty label selector or an empty list of names matches all service accounts
+func isServiceAccountMatchingSelector(saSelector *vpcresourcesv1beta1.ServiceAccountSelector, saName string,
+	sa *corev1.ServiceAccount) (bool, error) {
+	if len(saSelector.MatchNames) > 0 && !slices.Contains(saSelector.MatchNames, saName) {
+		return false, nil
 	}
 
-	sgList = RemoveDuplicatedSg(sgList)
-	return sgList
+	if saSelector.LabelSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(saSelector.LabelSelector)
+		if err != nil {
+			return false, err
+		}
+		if !selector.Matches(labels.Set(sa.Labels)) {
+			return false, nil
+		}
+	}
+	return true, nil
 }
 
 // DeconstructIPsFromPrefix deconstructs a IPv4 prefix into a list of /32 IPv4 addresses
@@ -206,6 +428,36 @@ func DeconstructIPsFromPrefix(prefix string) ([]string, error) {
 	return deconstructedIPs, nil
 }
 
+// DeconstructIPv6AddressesFromPrefix deconstructs an IPv6 prefix into a list of count /128 IPv6 addresses. Unlike IPv4
+// prefixes, an IPv6 prefix can't be fully enumerated, so only the first count addresses after the network address are
+// returned
+func DeconstructIPv6AddressesFromPrefix(prefix string, count int) ([]string, error) {
+	ipv6Prefix, err := netip.ParsePrefix(prefix)
+	if err != nil {
+		return nil, err
+	}
+	if !ipv6Prefix.Addr().Is6() || ipv6Prefix.Addr().Is4In6() {
+		return nil, fmt.Errorf("invalid IPv6 prefix %v", prefix)
+	}
+
+	var deconstructedIPs []string
+	addr := ipv6Prefix.Masked().Addr()
+	for i := 0; i < count; i++ {
+		addr = addr.Next()
+		if !addr.IsValid() || !ipv6Prefix.Contains(addr) {
+			return nil, fmt.Errorf("IPv6 prefix %v doesn't have %d addresses", prefix, count)
+		}
+		deconstructedIPs = append(deconstructedIPs, addr.String()+"/128")
+	}
+	return deconstructedIPs, nil
+}
+
+// IsIPv6Prefix returns true if the given prefix is an IPv6 CIDR
+func IsIPv6Prefix(prefix string) bool {
+	ipPrefix, err := netip.ParsePrefix(prefix)
+	return err == nil && ipPrefix.Addr().Is6() && !ipPrefix.Addr().Is4In6()
+}
+
 func IsNitroInstance(instanceType string) (bool, error) {
 	limits, found := vpc.Limits[instanceType]
 	if !found {
@@ -252,6 +504,19 @@ func PodHasENIRequest(pod *corev1.Pod) bool {
 	return false
 }
 
+// HasWindowsNodeSelector returns true if the pod is scheduled on windows nodes using the os node selector
+func HasWindowsNodeSelector(pod *corev1.Pod) bool {
+	osLabel := pod.Spec.NodeSelector[config.NodeLabelOS]
+
+	// Beta will be removed in v1.18.
+	osLabelBeta := pod.Spec.NodeSelector[config.NodeLabelOSBeta]
+
+	if osLabel != config.OSWindows && osLabelBeta != config.OSWindows {
+		return false
+	}
+	return true
+}
+
 func IntToInt32(value int) (int32, error) {
 	const (
 		minInt32 = -2147483648
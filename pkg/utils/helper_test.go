@@ -14,6 +14,8 @@
 package utils
 
 import (
+	"context"
+	"encoding/json"
 	"testing"
 
 	"github.com/samber/lo"
@@ -22,6 +24,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	vpcresourcesv1beta1 "github.com/aws/amazon-vpc-resource-controller-k8s/apis/vpcresources/v1beta1"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
@@ -64,7 +67,7 @@ func TestCanInjectENI_CombinedSelectors(t *testing.T) {
 	}
 
 	// Combined SA selector and PodSelector
-	sgs := helper.filterPodSecurityGroups(sgpList, testPod, testSA)
+	sgs := resolvedSecurityGroups(helper.filterPodSecurityGroups(sgpList, testPod, testSA))
 	assert.True(t, isEverySecurityGroupIncluded(sgs))
 }
 
@@ -78,7 +81,7 @@ func TestCanInjectENI_PodSelectors(t *testing.T) {
 		ListMeta: metav1.ListMeta{},
 		Items:    []vpcresourcesv1beta1.SecurityGroupPolicy{securityGroupPolicyPod},
 	}
-	sgs := helper.filterPodSecurityGroups(sgpList, testPod, testSA)
+	sgs := resolvedSecurityGroups(helper.filterPodSecurityGroups(sgpList, testPod, testSA))
 	assert.True(t, isEverySecurityGroupIncluded(sgs))
 }
 
@@ -92,7 +95,7 @@ func TestCanInjectENI_SASelectors(t *testing.T) {
 		ListMeta: metav1.ListMeta{},
 		Items:    []vpcresourcesv1beta1.SecurityGroupPolicy{securityGroupPolicySa},
 	}
-	sgs := helper.filterPodSecurityGroups(sgpList, testPod, testSA)
+	sgs := resolvedSecurityGroups(helper.filterPodSecurityGroups(sgpList, testPod, testSA))
 	assert.True(t, isEverySecurityGroupIncluded(sgs))
 }
 
@@ -110,7 +113,7 @@ func TestCanInjectENI_Multi_SGPs(t *testing.T) {
 		ListMeta: metav1.ListMeta{},
 		Items:    sgsList,
 	}
-	sgs := helper.filterPodSecurityGroups(sgpList, testPod, testSA)
+	sgs := resolvedSecurityGroups(helper.filterPodSecurityGroups(sgpList, testPod, testSA))
 	assert.True(t, isEverySecurityGroupIncluded(sgs))
 }
 
@@ -124,7 +127,7 @@ func TestCanInjectENI_EmptyPodSelector(t *testing.T) {
 		ListMeta: metav1.ListMeta{},
 		Items:    []vpcresourcesv1beta1.SecurityGroupPolicy{securityGroupPolicyEmptyPodSelector},
 	}
-	sgs := helper.filterPodSecurityGroups(sgpList, testPod, testSA)
+	sgs := resolvedSecurityGroups(helper.filterPodSecurityGroups(sgpList, testPod, testSA))
 	assert.True(t, isEverySecurityGroupIncluded(sgs))
 }
 
@@ -138,7 +141,7 @@ func TestCanInjectENI_EmptySASelector(t *testing.T) {
 		ListMeta: metav1.ListMeta{},
 		Items:    []vpcresourcesv1beta1.SecurityGroupPolicy{securityGroupPolicyEmptySaSelector},
 	}
-	sgs := helper.filterPodSecurityGroups(sgpList, testPod, testSA)
+	sgs := resolvedSecurityGroups(helper.filterPodSecurityGroups(sgpList, testPod, testSA))
 	assert.True(t, isEverySecurityGroupIncluded(sgs))
 }
 
@@ -152,7 +155,7 @@ func TestCanInjectENI_MatchLabelSASelector(t *testing.T) {
 		ListMeta: metav1.ListMeta{},
 		Items:    []vpcresourcesv1beta1.SecurityGroupPolicy{securityGroupPolicyEmptySaSelector},
 	}
-	sgs := helper.filterPodSecurityGroups(sgpList, testPod, testSA)
+	sgs := resolvedSecurityGroups(helper.filterPodSecurityGroups(sgpList, testPod, testSA))
 	assert.True(t, isEverySecurityGroupIncluded(sgs))
 }
 
@@ -166,7 +169,7 @@ func TestCanInjectENI_MatchExpressionsSASelector(t *testing.T) {
 		ListMeta: metav1.ListMeta{},
 		Items:    []vpcresourcesv1beta1.SecurityGroupPolicy{securityGroupPolicyEmptySaSelector},
 	}
-	sgs := helper.filterPodSecurityGroups(sgpList, testPod, testSA)
+	sgs := resolvedSecurityGroups(helper.filterPodSecurityGroups(sgpList, testPod, testSA))
 	assert.True(t, isEverySecurityGroupIncluded(sgs))
 }
 
@@ -180,7 +183,7 @@ func TestCanInjectENI_MatchLabelPodSelector(t *testing.T) {
 		ListMeta: metav1.ListMeta{},
 		Items:    []vpcresourcesv1beta1.SecurityGroupPolicy{securityGroupPolicyEmptySaSelector},
 	}
-	sgs := helper.filterPodSecurityGroups(sgpList, testPod, testSA)
+	sgs := resolvedSecurityGroups(helper.filterPodSecurityGroups(sgpList, testPod, testSA))
 	assert.True(t, isEverySecurityGroupIncluded(sgs))
 }
 
@@ -194,7 +197,7 @@ func TestCanInjectENI_MatchExpressionsPodSelector(t *testing.T) {
 		ListMeta: metav1.ListMeta{},
 		Items:    []vpcresourcesv1beta1.SecurityGroupPolicy{securityGroupPolicyEmptySaSelector},
 	}
-	sgs := helper.filterPodSecurityGroups(sgpList, testPod, testSA)
+	sgs := resolvedSecurityGroups(helper.filterPodSecurityGroups(sgpList, testPod, testSA))
 	assert.True(t, isEverySecurityGroupIncluded(sgs))
 }
 
@@ -210,10 +213,261 @@ func TestCanInjectENI_MismatchedSASelector(t *testing.T) {
 	}
 	mismatchedSa := testSA.DeepCopy()
 	mismatchedSa.Labels["environment"] = "dev"
-	sgs := helper.filterPodSecurityGroups(sgpList, testPod, mismatchedSa)
+	sgs := resolvedSecurityGroups(helper.filterPodSecurityGroups(sgpList, testPod, mismatchedSa))
 	assert.True(t, len(sgs) == 0)
 }
 
+// TestCanInjectENI_MatchNamesSASelector tests SA selector matching the service account name in SGP.
+func TestCanInjectENI_MatchNamesSASelector(t *testing.T) {
+	securityGroupPolicySa := NewSecurityGroupPolicyMatchNamesSASelector(
+		"test", "test_namespace", testSecurityGroupsOne, []string{"other_sa", saName})
+	sgpList := &vpcresourcesv1beta1.SecurityGroupPolicyList{
+		Items: []vpcresourcesv1beta1.SecurityGroupPolicy{securityGroupPolicySa},
+	}
+	sgs := resolvedSecurityGroups(helper.filterPodSecurityGroups(sgpList, testPod, testSA))
+	assert.True(t, isEverySecurityGroupIncluded(sgs))
+}
+
+// TestCanInjectENI_MismatchedNamesSASelector tests SA selector not matching the service account name in SGP.
+func TestCanInjectENI_MismatchedNamesSASelector(t *testing.T) {
+	securityGroupPolicySa := NewSecurityGroupPolicyMatchNamesSASelector(
+		"test", "test_namespace", testSecurityGroupsOne, []string{"other_sa"})
+	sgpList := &vpcresourcesv1beta1.SecurityGroupPolicyList{
+		Items: []vpcresourcesv1beta1.SecurityGroupPolicy{securityGroupPolicySa},
+	}
+	sgs := resolvedSecurityGroups(helper.filterPodSecurityGroups(sgpList, testPod, testSA))
+	assert.True(t, len(sgs) == 0)
+}
+
+// TestCanInjectENI_MatchNamesAndLabelsSASelector tests SA selector names and labels are ANDed in SGP.
+func TestCanInjectENI_MatchNamesAndLabelsSASelector(t *testing.T) {
+	securityGroupPolicySa := NewSecurityGroupPolicySaSelector(
+		"test", "test_namespace", testSecurityGroupsOne)
+	securityGroupPolicySa.Spec.ServiceAccountSelector.MatchNames = []string{saName}
+	sgpList := &vpcresourcesv1beta1.SecurityGroupPolicyList{
+		Items: []vpcresourcesv1beta1.SecurityGroupPolicy{securityGroupPolicySa},
+	}
+	sgs := resolvedSecurityGroups(helper.filterPodSecurityGroups(sgpList, testPod, testSA))
+	assert.True(t, isEverySecurityGroupIncluded(sgs))
+
+	// Name matches but labels don't
+	mismatchedSa := testSA.DeepCopy()
+	mismatchedSa.Labels["environment"] = "dev"
+	sgs = resolvedSecurityGroups(helper.filterPodSecurityGroups(sgpList, testPod, mismatchedSa))
+	assert.True(t, len(sgs) == 0)
+}
+
+// TestServiceAccountSelector_BackwardCompatible tests SA selector written as a plain label selector is
+// decoded into the label selector of the ServiceAccountSelector.
+func TestServiceAccountSelector_BackwardCompatible(t *testing.T) {
+	spec := vpcresourcesv1beta1.SecurityGroupPolicySpec{}
+	err := json.Unmarshal([]byte(`{"serviceAccountSelector":{"matchLabels":{"role":"db"},`+
+		`"matchExpressions":[{"key":"environment","operator":"In","values":["qa"]}]},`+
+		`"securityGroups":{"groupIds":["sg-00001"]}}`), &spec)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"role": "db"}, spec.ServiceAccountSelector.MatchLabels)
+	assert.Len(t, spec.ServiceAccountSelector.MatchExpressions, 1)
+	assert.Empty(t, spec.ServiceAccountSelector.MatchNames)
+
+	spec = vpcresourcesv1beta1.SecurityGroupPolicySpec{}
+	err = json.Unmarshal([]byte(`{"serviceAccountSelector":{"matchNames":["test_sa"]}}`), &spec)
+	assert.NoError(t, err)
+	assert.Nil(t, spec.ServiceAccountSelector.LabelSelector)
+	assert.Equal(t, []string{"test_sa"}, spec.ServiceAccountSelector.MatchNames)
+}
+
+// TestCanInjectENI_ClusterSGPNamespaceSelector tests CSGP matching the pod namespace labels.
+func TestCanInjectENI_ClusterSGPNamespaceSelector(t *testing.T) {
+	csgpList := &vpcresourcesv1beta1.ClusterSecurityGroupPolicyList{
+		Items: []vpcresourcesv1beta1.ClusterSecurityGroupPolicy{
+			NewClusterSecurityGroupPolicy("test", map[string]string{"team": "payments"}, testSecurityGroupsOne),
+		},
+	}
+	sgs := resolvedSecurityGroups(helper.filterPodClusterSecurityGroups(csgpList, testPod, testSA,
+		NewNamespace(namespace, map[string]string{"team": "payments"})))
+	assert.True(t, isEverySecurityGroupIncluded(sgs))
+
+	// Namespace labels don't match the namespace selector
+	sgs = resolvedSecurityGroups(helper.filterPodClusterSecurityGroups(csgpList, testPod, testSA,
+		NewNamespace(namespace, map[string]string{"team": "orders"})))
+	assert.True(t, len(sgs) == 0)
+
+	// Namespace matches but the pod doesn't match the pod selector
+	csgpList.Items[0].Spec.PodSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"role": "web"}}
+	sgs = resolvedSecurityGroups(helper.filterPodClusterSecurityGroups(csgpList, testPod, testSA,
+		NewNamespace(namespace, map[string]string{"team": "payments"})))
+	assert.True(t, len(sgs) == 0)
+}
+
+// TestCanInjectENI_ClusterSGPNilNamespaceSelector tests CSGP without namespace selector doesn't match any pod.
+func TestCanInjectENI_ClusterSGPNilNamespaceSelector(t *testing.T) {
+	csgp := NewClusterSecurityGroupPolicy("test", nil, testSecurityGroupsOne)
+	csgp.Spec.NamespaceSelector = nil
+	csgpList := &vpcresourcesv1beta1.ClusterSecurityGroupPolicyList{
+		Items: []vpcresourcesv1beta1.ClusterSecurityGroupPolicy{csgp},
+	}
+	sgs := resolvedSecurityGroups(helper.filterPodClusterSecurityGroups(csgpList, testPod, testSA, NewNamespace(namespace, nil)))
+	assert.True(t, len(sgs) == 0)
+}
+
+// TestGetMatchingSecurityGroupForPods_ClusterSGP tests security groups from SGP and CSGP are merged.
+func TestGetMatchingSecurityGroupForPods_ClusterSGP(t *testing.T) {
+	sgpHelper := SecurityGroupForPods{
+		Client: fake.NewClientBuilder().WithScheme(testScheme).WithObjects(
+			NewServiceAccount(saName, namespace),
+			NewNamespace(namespace, map[string]string{"team": "payments"}),
+			NewSecurityGroupPolicyOne(name+"_1", namespace, testSecurityGroupsOne),
+			lo.ToPtr(NewClusterSecurityGroupPolicy("payments", map[string]string{"team": "payments"},
+				append(testSecurityGroupsOne, testSecurityGroupsTwo...))),
+			lo.ToPtr(NewClusterSecurityGroupPolicy("orders", map[string]string{"team": "orders"},
+				[]string{"sg-00005"})),
+		).Build(),
+		Log: helper.Log,
+	}
+
+	sgs, err := sgpHelper.GetMatchingSecurityGroupForPods(testPod)
+	assert.NoError(t, err)
+	assert.Equal(t, append(testSecurityGroupsOne, testSecurityGroupsTwo...), sgs)
+}
+
+// TestResolveSecurityGroupPolicies tests the security groups of matched policies are resolved by priority and merge mode.
+func TestResolveSecurityGroupPolicies(t *testing.T) {
+	low := MatchedSecurityGroupPolicy{Name: "ns/low", Priority: 1, SecurityGroups: []string{"sg-1", "sg-2"}}
+	high := MatchedSecurityGroupPolicy{Name: "ns/high", Priority: 10, SecurityGroups: []string{"sg-2", "sg-3"}}
+	highExclusive := MatchedSecurityGroupPolicy{Name: "high-exclusive", Priority: 10,
+		MergeMode: vpcresourcesv1beta1.MergeModeHighestPriorityWins, SecurityGroups: []string{"sg-4"}}
+
+	tests := []struct {
+		name                    string
+		matchedPolicies         []MatchedSecurityGroupPolicy
+		expectedSecurityGroups  []string
+		expectedAppliedPolicies []MatchedSecurityGroupPolicy
+	}{
+		{
+			name: "no matched policies",
+		},
+		{
+			name:                    "union of all the matched policies ordered by priority",
+			matchedPolicies:         []MatchedSecurityGroupPolicy{low, high},
+			expectedSecurityGroups:  []string{"sg-2", "sg-3", "sg-1"},
+			expectedAppliedPolicies: []MatchedSecurityGroupPolicy{high, low},
+		},
+		{
+			name:                    "highest priority wins drops lower priority policies",
+			matchedPolicies:         []MatchedSecurityGroupPolicy{low, highExclusive},
+			expectedSecurityGroups:  []string{"sg-4"},
+			expectedAppliedPolicies: []MatchedSecurityGroupPolicy{highExclusive},
+		},
+		{
+			name:                    "highest priority wins keeps policies with the same priority",
+			matchedPolicies:         []MatchedSecurityGroupPolicy{low, high, highExclusive},
+			expectedSecurityGroups:  []string{"sg-2", "sg-3", "sg-4"},
+			expectedAppliedPolicies: []MatchedSecurityGroupPolicy{high, highExclusive},
+		},
+		{
+			name: "highest priority wins on a lower priority policy is ignored",
+			matchedPolicies: []MatchedSecurityGroupPolicy{high, {Name: "low-exclusive", Priority: 1,
+				MergeMode: vpcresourcesv1beta1.MergeModeHighestPriorityWins, SecurityGroups: []string{"sg-5"}}},
+			expectedSecurityGroups: []string{"sg-2", "sg-3", "sg-5"},
+			expectedAppliedPolicies: []MatchedSecurityGroupPolicy{high, {Name: "low-exclusive", Priority: 1,
+				MergeMode: vpcresourcesv1beta1.MergeModeHighestPriorityWins, SecurityGroups: []string{"sg-5"}}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sgs, applied := resolveSecurityGroupPolicies(test.matchedPolicies)
+			assert.Equal(t, test.expectedSecurityGroups, sgs)
+			assert.Equal(t, test.expectedAppliedPolicies, applied)
+		})
+	}
+}
+
+// TestSecurityGroupResolution_HasConflict tests a conflict is reported only for policies with different security groups.
+func TestSecurityGroupResolution_HasConflict(t *testing.T) {
+	one := MatchedSecurityGroupPolicy{Name: "ns/one", SecurityGroups: []string{"sg-1", "sg-2"}}
+	sameAsOne := MatchedSecurityGroupPolicy{Name: "ns/two", SecurityGroups: []string{"sg-2", "sg-1"}}
+	other := MatchedSecurityGroupPolicy{Name: "ns/three", SecurityGroups: []string{"sg-3"}}
+
+	assert.False(t, (&SecurityGroupResolution{}).HasConflict())
+	assert.False(t, (&SecurityGroupResolution{MatchedPolicies: []MatchedSecurityGroupPolicy{one}}).HasConflict())
+	assert.False(t, (&SecurityGroupResolution{MatchedPolicies: []MatchedSecurityGroupPolicy{one, sameAsOne}}).HasConflict())
+	assert.True(t, (&SecurityGroupResolution{MatchedPolicies: []MatchedSecurityGroupPolicy{one, other}}).HasConflict())
+}
+
+// TestResolveSecurityGroupsForPod_Priority tests the higher priority policy wins over the overlapping policy.
+func TestResolveSecurityGroupsForPod_Priority(t *testing.T) {
+	sgpOne := NewSecurityGroupPolicyOne(name+"_1", namespace, testSecurityGroupsOne)
+	sgpTwo := NewSecurityGroupPolicyOne(name+"_2", namespace, testSecurityGroupsTwo)
+	sgpTwo.Spec.Priority = 100
+	sgpTwo.Spec.MergeMode = vpcresourcesv1beta1.MergeModeHighestPriorityWins
+
+	sgpHelper := SecurityGroupForPods{
+		Client: fake.NewClientBuilder().WithScheme(testScheme).WithObjects(
+			NewServiceAccount(saName, namespace), sgpOne, sgpTwo).Build(),
+		Log: helper.Log,
+	}
+
+	resolution, err := sgpHelper.ResolveSecurityGroupsForPod(testPod)
+	assert.NoError(t, err)
+	assert.Equal(t, testSecurityGroupsTwo, resolution.SecurityGroups)
+	assert.Len(t, resolution.MatchedPolicies, 2)
+	assert.Equal(t, namespace+"/"+name+"_2", resolution.MatchedPolicies[0].Name)
+	assert.Len(t, resolution.AppliedPolicies, 1)
+	assert.True(t, resolution.HasConflict())
+}
+
+// TestResolveSecurityGroupsForPod_LimitExceeded tests an error is returned when the resolved security groups
+// exceed the limit per network interface.
+func TestResolveSecurityGroupsForPod_LimitExceeded(t *testing.T) {
+	sgpHelper := SecurityGroupForPods{
+		Client: fake.NewClientBuilder().WithScheme(testScheme).WithObjects(
+			NewServiceAccount(saName, namespace),
+			NewSecurityGroupPolicyOne(name+"_1", namespace, testSecurityGroupsOne),
+			NewSecurityGroupPolicyOne(name+"_2", namespace, testSecurityGroupsTwo),
+		).Build(),
+		Log:                     helper.Log,
+		MaxSecurityGroupsPerENI: len(testSecurityGroupsOne),
+	}
+
+	resolution, err := sgpHelper.ResolveSecurityGroupsForPod(testPod)
+	limitErr := &SecurityGroupLimitExceededError{}
+	assert.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, len(testSecurityGroupsOne), limitErr.Limit)
+	assert.ElementsMatch(t, []string{namespace + "/" + name + "_1", namespace + "/" + name + "_2"}, limitErr.Policies)
+	assert.Len(t, resolution.SecurityGroups, len(testSecurityGroupsOne)+len(testSecurityGroupsTwo))
+
+	// The limit is not enforced when disabled
+	sgpHelper.MaxSecurityGroupsPerENI = 0
+	_, err = sgpHelper.ResolveSecurityGroupsForPod(testPod)
+	assert.NoError(t, err)
+}
+
+// TestResolveSecurityGroupsForPod_DefaultLimit tests the pods are rejected if the security groups resolved for the pod
+// exceed the default EC2 quota of security groups per network interface
+func TestResolveSecurityGroupsForPod_DefaultLimit(t *testing.T) {
+	securityGroups := []string{"sg-00001", "sg-00002", "sg-00003", "sg-00004", "sg-00005", "sg-00006"}
+	sgpHelper := SecurityGroupForPods{
+		Client: fake.NewClientBuilder().WithScheme(testScheme).WithObjects(
+			NewServiceAccount(saName, namespace),
+			NewSecurityGroupPolicyOne(name+"_1", namespace, securityGroups[:config.DefaultMaxSecurityGroupsPerENI]),
+		).Build(),
+		Log:                     helper.Log,
+		MaxSecurityGroupsPerENI: config.DefaultMaxSecurityGroupsPerENI,
+	}
+
+	resolution, err := sgpHelper.ResolveSecurityGroupsForPod(testPod)
+	assert.NoError(t, err)
+	assert.Len(t, resolution.SecurityGroups, config.DefaultMaxSecurityGroupsPerENI)
+
+	assert.NoError(t, sgpHelper.Client.Create(context.TODO(),
+		NewSecurityGroupPolicyOne(name+"_2", namespace, securityGroups[config.DefaultMaxSecurityGroupsPerENI:])))
+	_, err = sgpHelper.ResolveSecurityGroupsForPod(testPod)
+	limitErr := &SecurityGroupLimitExceededError{}
+	assert.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, config.DefaultMaxSecurityGroupsPerENI, limitErr.Limit)
+}
+
 // TestEmptySecurityGroupInSGP tests empty security group groupids in SGP.
 func TestEmptySecurityGroupInSGP(t *testing.T) {
 	securityGroupPolicyPod := NewSecurityGroupPolicyPodSelector(
@@ -224,7 +478,7 @@ func TestEmptySecurityGroupInSGP(t *testing.T) {
 		ListMeta: metav1.ListMeta{},
 		Items:    []vpcresourcesv1beta1.SecurityGroupPolicy{securityGroupPolicyPod},
 	}
-	sgs := helper.filterPodSecurityGroups(sgpList, testPod, testSA)
+	sgs := resolvedSecurityGroups(helper.filterPodSecurityGroups(sgpList, testPod, testSA))
 	assert.True(t, len(sgs) == 0)
 }
 
@@ -271,13 +525,15 @@ func NewSecurityGroupPolicyCombined(
 					Values:   []string{"qa", "production"},
 				}},
 			},
-			ServiceAccountSelector: &metav1.LabelSelector{
-				MatchLabels: map[string]string{"role": "db"},
-				MatchExpressions: []metav1.LabelSelectorRequirement{{
-					Key:      "environment",
-					Operator: "In",
-					Values:   []string{"qa", "production"},
-				}},
+			ServiceAccountSelector: &vpcresourcesv1beta1.ServiceAccountSelector{
+				LabelSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"role": "db"},
+					MatchExpressions: []metav1.LabelSelectorRequirement{{
+						Key:      "environment",
+						Operator: "In",
+						Values:   []string{"qa", "production"},
+					}},
+				},
 			},
 			SecurityGroups: vpcresourcesv1beta1.GroupIds{
 				Groups: securityGroups,
@@ -343,13 +599,15 @@ func NewSecurityGroupPolicySaSelector(name string, namespace string, securityGro
 			Namespace: namespace,
 		},
 		Spec: vpcresourcesv1beta1.SecurityGroupPolicySpec{
-			ServiceAccountSelector: &metav1.LabelSelector{
-				MatchLabels: map[string]string{"role": "db"},
-				MatchExpressions: []metav1.LabelSelectorRequirement{{
-					Key:      "environment",
-					Operator: "In",
-					Values:   []string{"qa", "production"},
-				}},
+			ServiceAccountSelector: &vpcresourcesv1beta1.ServiceAccountSelector{
+				LabelSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"role": "db"},
+					MatchExpressions: []metav1.LabelSelectorRequirement{{
+						Key:      "environment",
+						Operator: "In",
+						Values:   []string{"qa", "production"},
+					}},
+				},
 			},
 			SecurityGroups: vpcresourcesv1beta1.GroupIds{
 				Groups: securityGroups,
@@ -368,9 +626,12 @@ func NewSecurityGroupPolicyEmptySaSelector(name string, namespace string, securi
 			Namespace: namespace,
 		},
 		Spec: vpcresourcesv1beta1.SecurityGroupPolicySpec{
-			ServiceAccountSelector: &metav1.LabelSelector{
-				MatchLabels:      map[string]string{},
-				MatchExpressions: []metav1.LabelSelectorRequirement{}},
+			ServiceAccountSelector: &vpcresourcesv1beta1.ServiceAccountSelector{
+				LabelSelector: &metav1.LabelSelector{
+					MatchLabels:      map[string]string{},
+					MatchExpressions: []metav1.LabelSelectorRequirement{},
+				},
+			},
 			SecurityGroups: vpcresourcesv1beta1.GroupIds{
 				Groups: securityGroups,
 			},
@@ -434,8 +695,10 @@ func NewSecurityGroupPolicyMatchLabelSASelector(name string, namespace string, s
 			Namespace: namespace,
 		},
 		Spec: vpcresourcesv1beta1.SecurityGroupPolicySpec{
-			ServiceAccountSelector: &metav1.LabelSelector{
-				MatchLabels: map[string]string{"role": "db"},
+			ServiceAccountSelector: &vpcresourcesv1beta1.ServiceAccountSelector{
+				LabelSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"role": "db"},
+				},
 			},
 			SecurityGroups: vpcresourcesv1beta1.GroupIds{
 				Groups: securityGroups,
@@ -454,12 +717,14 @@ func NewSecurityGroupPolicyMatchExpressionSASelector(name string, namespace stri
 			Namespace: namespace,
 		},
 		Spec: vpcresourcesv1beta1.SecurityGroupPolicySpec{
-			ServiceAccountSelector: &metav1.LabelSelector{
-				MatchExpressions: []metav1.LabelSelectorRequirement{
-					{
-						Key:      "environment",
-						Operator: "In",
-						Values:   []string{"qa", "production"},
+			ServiceAccountSelector: &vpcresourcesv1beta1.ServiceAccountSelector{
+				LabelSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{
+							Key:      "environment",
+							Operator: "In",
+							Values:   []string{"qa", "production"},
+						},
 					},
 				},
 			},
@@ -471,6 +736,57 @@ func NewSecurityGroupPolicyMatchExpressionSASelector(name string, namespace stri
 	return sgp
 }
 
+// NewClusterSecurityGroupPolicy creates a test CSGP with namespace selector.
+func NewClusterSecurityGroupPolicy(name string, namespaceLabels map[string]string,
+	securityGroups []string) vpcresourcesv1beta1.ClusterSecurityGroupPolicy {
+	return vpcresourcesv1beta1.ClusterSecurityGroupPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: vpcresourcesv1beta1.ClusterSecurityGroupPolicySpec{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: namespaceLabels,
+			},
+			SecurityGroupPolicySpec: vpcresourcesv1beta1.SecurityGroupPolicySpec{
+				SecurityGroups: vpcresourcesv1beta1.GroupIds{
+					Groups: securityGroups,
+				},
+			},
+		},
+	}
+}
+
+// NewNamespace creates a test namespace with labels.
+func NewNamespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+	}
+}
+
+// NewSecurityGroupPolicyMatchNamesSASelector creates a test SGP with match names SA selector.
+func NewSecurityGroupPolicyMatchNamesSASelector(name string, namespace string, securityGroups []string,
+	saNames []string) vpcresourcesv1beta1.SecurityGroupPolicy {
+	sgp := vpcresourcesv1beta1.SecurityGroupPolicy{
+		TypeMeta: metav1.TypeMeta{},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: vpcresourcesv1beta1.SecurityGroupPolicySpec{
+			ServiceAccountSelector: &vpcresourcesv1beta1.ServiceAccountSelector{
+				MatchNames: saNames,
+			},
+			SecurityGroups: vpcresourcesv1beta1.GroupIds{
+				Groups: securityGroups,
+			},
+		},
+	}
+	return sgp
+}
+
 func TestDeconstructIPsFromPrefix(t *testing.T) {
 	// /32 prefix only contains 1 ip address
 	prefix1 := "10.0.1.0/32"
@@ -515,6 +831,43 @@ func TestDeconstructIPsFromPrefix_InvalidPrefix(t *testing.T) {
 	}
 }
 
+func TestDeconstructIPv6AddressesFromPrefix(t *testing.T) {
+	ips, err := DeconstructIPv6AddressesFromPrefix("2600:1f14:abc:de00:1234::/80", 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2600:1f14:abc:de00:1234::1/128", "2600:1f14:abc:de00:1234::2/128",
+		"2600:1f14:abc:de00:1234::3/128"}, ips)
+
+	// /126 only has 3 addresses after the network address
+	ips, err = DeconstructIPv6AddressesFromPrefix("2600:1f14::/126", 4)
+	assert.Error(t, err)
+	assert.Nil(t, ips)
+}
+
+func TestDeconstructIPv6AddressesFromPrefix_InvalidPrefix(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+	}{
+		{name: "IPv4 prefix", prefix: "10.0.1.0/28"},
+		{name: "missing mask", prefix: "2600:1f14::"},
+		{name: "invalid mask", prefix: "2600:1f14::/129"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ips, err := DeconstructIPv6AddressesFromPrefix(test.prefix, 16)
+			assert.Error(t, err)
+			assert.Nil(t, ips)
+		})
+	}
+}
+
+func TestIsIPv6Prefix(t *testing.T) {
+	assert.True(t, IsIPv6Prefix("2600:1f14::/80"))
+	assert.False(t, IsIPv6Prefix("10.0.1.0/28"))
+	assert.False(t, IsIPv6Prefix("10.0.1.0/32"))
+}
+
 func TestIsNitroInstance(t *testing.T) {
 	instanceType := "a1.2xlarge"
 	isNitro, err := IsNitroInstance(instanceType)
@@ -746,3 +1099,9 @@ func TestGetNodeID(t *testing.T) {
 		})
 	}
 }
+
+// resolvedSecurityGroups returns the security groups resolved from the matched policies
+func resolvedSecurityGroups(matchedPolicies []MatchedSecurityGroupPolicy) []string {
+	sgs, _ := resolveSecurityGroupPolicies(matchedPolicies)
+	return sgs
+}
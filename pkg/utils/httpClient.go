@@ -14,19 +14,51 @@
 package utils
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
 	"golang.org/x/time/rate"
 )
 
 const (
 	// defaultAWSSDKClientTimeout is the timeout for individual HTTP requests made by AWS SDK clients.
 	defaultAWSSDKClientTimeout = 30 * time.Second
+
+	// throttleBackOffFactor is the factor the rate limit of an action is multiplied by when the action is throttled
+	throttleBackOffFactor = 0.5
+	// minRateLimitFactor is the fraction of the configured QPS below which the rate limit of an action doesn't back off
+	minRateLimitFactor = 0.1
+	// rateLimitRecoveryFactor is the fraction of the configured QPS the rate limit of an action recovers by in each
+	// adjust interval without throttling
+	rateLimitRecoveryFactor = 0.1
+	// rateLimitAdjustInterval is the minimum interval between the adjustments of the rate limit of an action, so the
+	// concurrent requests throttled together back off the rate limit once
+	rateLimitAdjustInterval = time.Second
+	// maxErrorResponsePeekSize is the maximum size of the error response body read to find the throttling error code
+	maxErrorResponsePeekSize = 64 * 1024
 )
 
+// throttlingErrorCodes are the error codes returned by AWS APIs when the requests are throttled
+var throttlingErrorCodes = []string{"RequestLimitExceeded", "Throttling", "ThrottlingException",
+	"TooManyRequestsException"}
+
+// podStartActions are the API actions that allocate the resources of the starting pods, they have priority over the
+// other actions for the shared budget so the other actions can't delay them
+var podStartActions = map[string]struct{}{
+	"AssociateTrunkInterface":  {},
+	"CreateNetworkInterface":   {},
+	"AttachNetworkInterface":   {},
+	"AssignPrivateIpAddresses": {},
+	"AssignIpv6Addresses":      {},
+}
+
 // NewAWSSDKHTTPClient returns a new HTTP client with the default AWS SDK timeout.
 func NewAWSSDKHTTPClient() *http.Client {
 	return &http.Client{Timeout: defaultAWSSDKClientTimeout}
@@ -34,6 +66,11 @@ func NewAWSSDKHTTPClient() *http.Client {
 
 // NewRateLimitedClient returns a new HTTP client with rate limiter and the default AWS SDK timeout.
 // The timeout is applied after the rate limit wait, so queue time does not eat into the HTTP timeout.
+// All the API actions share the budget of the given QPS and burst. The API actions that start pods reserve the budget
+// ahead of the other actions, which only use the budget that is not reserved, so a flood of the other actions can't
+// delay the pod start actions. On top of the shared budget, each API action has its own rate limit that backs off
+// multiplicatively when the action is throttled and recovers additively up to the given QPS while it's not throttled,
+// so that a throttled action doesn't consume the budget of the other actions.
 func NewRateLimitedClient(qps int, burst int) (*http.Client, error) {
 	if qps == 0 {
 		return NewAWSSDKHTTPClient(), nil
@@ -43,24 +80,170 @@ func NewRateLimitedClient(qps int, burst int) (*http.Client, error) {
 	}
 	return &http.Client{
 		Transport: &rateLimitedRoundTripper{
-			rt:      http.DefaultTransport,
-			rl:      rate.NewLimiter(rate.Limit(qps), burst),
-			timeout: defaultAWSSDKClientTimeout,
+			rt:       http.DefaultTransport,
+			qps:      rate.Limit(qps),
+			burst:    burst,
+			limiter:  rate.NewLimiter(rate.Limit(qps), burst),
+			limiters: make(map[string]*aimdLimiter),
+			timeout:  defaultAWSSDKClientTimeout,
 		},
 	}, nil
 }
 
 type rateLimitedRoundTripper struct {
-	rt      http.RoundTripper
-	rl      *rate.Limiter
-	timeout time.Duration
+	rt    http.RoundTripper
+	qps   rate.Limit
+	burst int
+
+	// limiter is the rate limiter of the QPS and burst shared by all the API actions, only the pod start actions
+	// reserve its future tokens
+	limiter *rate.Limiter
+
+	lock sync.Mutex
+	// limiters is the rate limiter of each API action backed off on throttling
+	limiters map[string]*aimdLimiter
+	timeout  time.Duration
 }
 
 func (rr *rateLimitedRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
-	if err := rr.rl.Wait(req.Context()); err != nil {
+	action := getAction(req)
+	rl := rr.getLimiter(action)
+	if err := rl.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	if err := rr.waitBudget(req.Context(), action); err != nil {
 		return nil, err
 	}
 	ctx, cancel := context.WithTimeout(req.Context(), rr.timeout)
 	defer cancel()
-	return rr.rt.RoundTrip(req.WithContext(ctx))
+	resp, err := rr.rt.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if isThrottled(resp) {
+		rl.onThrottle(time.Now())
+	} else if resp.StatusCode < http.StatusInternalServerError {
+		rl.onSuccess(time.Now())
+	}
+	return resp, nil
+}
+
+// waitBudget waits for the shared budget. The pod start actions reserve the next available token, while the other
+// actions wait until a token is available that is not reserved by the pod start actions
+func (rr *rateLimitedRoundTripper) waitBudget(ctx context.Context, action string) error {
+	if _, ok := podStartActions[action]; ok {
+		return rr.limiter.Wait(ctx)
+	}
+	for !rr.limiter.Allow() {
+		// The tokens are negative while they are reserved by the pod start actions
+		wait := time.Duration((1 - rr.limiter.Tokens()) / float64(rr.qps) * float64(time.Second))
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return nil
+}
+
+// getLimiter returns the rate limiter of the action, creating it with the configured QPS and burst if it doesn't exist
+func (rr *rateLimitedRoundTripper) getLimiter(action string) *aimdLimiter {
+	rr.lock.Lock()
+	defer rr.lock.Unlock()
+
+	rl, ok := rr.limiters[action]
+	if !ok {
+		rl = newAIMDLimiter(rr.qps, rr.burst)
+		rr.limiters[action] = rl
+	}
+	return rl
+}
+
+// getAction returns the API action of the request from the AWS SDK operation name, or from the Action query parameter
+// if the request was not made by the AWS SDK
+func getAction(req *http.Request) string {
+	if action := awsmiddleware.GetOperationName(req.Context()); action != "" {
+		return action
+	}
+	return req.URL.Query().Get("Action")
+}
+
+// isThrottled returns true if the response is a throttling error. The error response body is read to find the error
+// code and is restored so the caller can read it again
+func isThrottled(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	if resp.StatusCode != http.StatusBadRequest && resp.StatusCode != http.StatusServiceUnavailable {
+		return false
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorResponsePeekSize))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+	if err != nil {
+		return false
+	}
+
+	for _, code := range throttlingErrorCodes {
+		if bytes.Contains(body, []byte("<Code>"+code+"</Code>")) ||
+			bytes.Contains(body, []byte(`"`+code+`"`)) ||
+			strings.HasSuffix(resp.Header.Get("X-Amzn-ErrorType"), code) {
+			return true
+		}
+	}
+	return false
+}
+
+// aimdLimiter is a token bucket rate limiter with additive increase and multiplicative decrease of the rate limit
+type aimdLimiter struct {
+	*rate.Limiter
+	maxLimit rate.Limit
+	minLimit rate.Limit
+	step     rate.Limit
+
+	lock sync.Mutex
+	// lastBackOff is the time the rate limit was last backed off
+	lastBackOff time.Time
+	// lastAdjusted is the time the rate limit was last backed off or recovered
+	lastAdjusted time.Time
+}
+
+func newAIMDLimiter(qps rate.Limit, burst int) *aimdLimiter {
+	return &aimdLimiter{
+		Limiter:  rate.NewLimiter(qps, burst),
+		maxLimit: qps,
+		minLimit: qps * minRateLimitFactor,
+		step:     qps * rateLimitRecoveryFactor,
+	}
+}
+
+// onThrottle decreases the rate limit multiplicatively, unless it was already backed off within the adjust interval
+func (l *aimdLimiter) onThrottle(now time.Time) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if now.Sub(l.lastBackOff) < rateLimitAdjustInterval {
+		return
+	}
+	l.SetLimitAt(now, max(l.Limit()*throttleBackOffFactor, l.minLimit))
+	l.lastBackOff, l.lastAdjusted = now, now
+}
+
+// onSuccess increases the rate limit additively up to the maximum rate limit, unless it was adjusted within the adjust
+// interval
+func (l *aimdLimiter) onSuccess(now time.Time) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.Limit() >= l.maxLimit || now.Sub(l.lastAdjusted) < rateLimitAdjustInterval {
+		return
+	}
+	l.SetLimitAt(now, min(l.Limit()+l.step, l.maxLimit))
+	l.lastAdjusted = now
 }
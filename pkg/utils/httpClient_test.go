@@ -16,11 +16,15 @@ package utils
 import (
 	"context"
 	"fmt"
+	"io"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
+
+	"golang.org/x/time/rate"
 )
 
 func TestNewRateLimitedClient(t *testing.T) {
@@ -174,3 +178,167 @@ func TestNewRateLimitedClient_SetsTimeout(t *testing.T) {
 		t.Fatalf("expected transport timeout %v, got %v", defaultAWSSDKClientTimeout, rt.timeout)
 	}
 }
+
+func TestAIMDLimiter(t *testing.T) {
+	l := newAIMDLimiter(10, 1)
+	now := time.Now()
+
+	l.onThrottle(now)
+	if l.Limit() != 5 {
+		t.Fatalf("expected limit 5 after throttle, got %v", l.Limit())
+	}
+	// Throttles within the adjust interval are from the same burst of requests
+	l.onThrottle(now.Add(rateLimitAdjustInterval / 2))
+	if l.Limit() != 5 {
+		t.Fatalf("expected limit 5 after throttle within adjust interval, got %v", l.Limit())
+	}
+	for i := 1; i <= 5; i++ {
+		l.onThrottle(now.Add(time.Duration(i) * rateLimitAdjustInterval))
+	}
+	if l.Limit() != 1 {
+		t.Fatalf("expected limit to back off to minimum 1, got %v", l.Limit())
+	}
+
+	now = now.Add(5 * rateLimitAdjustInterval)
+	l.onSuccess(now.Add(rateLimitAdjustInterval / 2))
+	if l.Limit() != 1 {
+		t.Fatalf("expected limit 1 after success within adjust interval, got %v", l.Limit())
+	}
+	for i := 1; i <= 20; i++ {
+		l.onSuccess(now.Add(time.Duration(i) * rateLimitAdjustInterval))
+		if i < 9 && math.Abs(float64(l.Limit())-float64(1+i)) > 1e-9 {
+			t.Fatalf("expected limit %d after %d recoveries, got %v", 1+i, i, l.Limit())
+		}
+	}
+	if l.Limit() != 10 {
+		t.Fatalf("expected limit to recover to maximum 10, got %v", l.Limit())
+	}
+}
+
+func TestRateLimitedRoundTripper_Throttled(t *testing.T) {
+	throttledBody := `<Response><Errors><Error><Code>RequestLimitExceeded</Code></Error></Errors></Response>`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Query().Get("Action") {
+		case "DeleteNetworkInterface":
+			http.Error(w, throttledBody, http.StatusServiceUnavailable)
+		case "DescribeSubnets":
+			http.Error(w, `<Response><Errors><Error><Code>InvalidSubnetID.NotFound</Code></Error></Errors></Response>`,
+				http.StatusBadRequest)
+		default:
+			fmt.Fprint(w, `test`)
+		}
+	}))
+	defer ts.Close()
+
+	cli, err := NewRateLimitedClient(10, 1)
+	if err != nil {
+		t.Fatalf("failed to create a new client (%v)", err)
+	}
+	for _, action := range []string{"DeleteNetworkInterface", "DescribeSubnets", "AssociateTrunkInterface"} {
+		resp, err := cli.Get(ts.URL + "?Action=" + action)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to read the response body of %s (%v)", action, err)
+		}
+		if action == "DeleteNetworkInterface" && strings.TrimSpace(string(body)) != throttledBody {
+			t.Fatalf("expected the throttled response body to be restored, got %q", body)
+		}
+	}
+
+	rr := cli.Transport.(*rateLimitedRoundTripper)
+	expectedLimits := map[string]rate.Limit{"DeleteNetworkInterface": 5, "DescribeSubnets": 10,
+		"AssociateTrunkInterface": 10}
+	for action, expectedLimit := range expectedLimits {
+		if limit := rr.getLimiter(action).Limit(); limit != expectedLimit {
+			t.Fatalf("expected limit %v for %s, got %v", expectedLimit, action, limit)
+		}
+	}
+}
+
+// TestRateLimitedRoundTripper_SharedBudget tests the API actions other than the pod start actions share their budget
+func TestRateLimitedRoundTripper_SharedBudget(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, `test`)
+	}))
+	defer ts.Close()
+
+	cli, err := NewRateLimitedClient(1, 1)
+	if err != nil {
+		t.Fatalf("failed to create a new client (%v)", err)
+	}
+	resp, err := cli.Get(ts.URL + "?Action=DescribeSubnets")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	_ = resp.Body.Close()
+
+	// The burst was used by the other action, so the request has to wait for the shared budget
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"?Action=DescribeInstances", nil)
+	if err != nil {
+		t.Fatalf("failed to create request (%v)", err)
+	}
+	if _, err = cli.Do(req); err == nil || !strings.Contains(err.Error(), "context deadline") {
+		t.Fatalf("expected the request to exceed the context deadline waiting for the shared budget, got %v", err)
+	}
+}
+
+// TestRateLimitedRoundTripper_PodStartBudget tests an action saturating the shared budget doesn't delay the pod start
+// actions, which reserve the budget ahead of it
+func TestRateLimitedRoundTripper_PodStartBudget(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, `test`)
+	}))
+	defer ts.Close()
+
+	cli, err := NewRateLimitedClient(2, 2)
+	if err != nil {
+		t.Fatalf("failed to create a new client (%v)", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	for i := 0; i < 10; i++ {
+		go func() {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"?Action=DeleteNetworkInterface", nil)
+			if err != nil {
+				return
+			}
+			if resp, err := cli.Do(req); err == nil {
+				_ = resp.Body.Close()
+			}
+		}()
+	}
+	// Let the flood of the other action use up the shared budget
+	time.Sleep(50 * time.Millisecond)
+
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		resp, err := cli.Get(ts.URL + "?Action=AssociateTrunkInterface")
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		_ = resp.Body.Close()
+	}
+	// Each request reserves one of the next tokens ahead of the flood of the other action, which takes 4s of the
+	// shared budget
+	if took := time.Since(start); took > 1500*time.Millisecond {
+		t.Fatalf("expected the pod start action not to wait behind the other action, took %v", took)
+	}
+
+	// The other actions still wait for the shared budget behind the flood
+	ctx, cancelOther := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+	defer cancelOther()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"?Action=DescribeSubnets", nil)
+	if err != nil {
+		t.Fatalf("failed to create request (%v)", err)
+	}
+	if _, err = cli.Do(req); err == nil || !strings.Contains(err.Error(), "context deadline") {
+		t.Fatalf("expected the request to exceed the context deadline waiting for the shared budget, got %v", err)
+	}
+}
@@ -34,6 +34,10 @@ const (
 	OperationReSyncPool Operations = "ReSyncPool"
 	// OperationDeleteNode represents the job to delete the node
 	OperationDeleteNode Operations = "NodeDelete"
+	// OperationVerifyTrunk represents the job to verify the trunk initialized from the checkpoint
+	OperationVerifyTrunk Operations = "VerifyTrunk"
+	// OperationReconcileWarmPool represents the job to reconcile the warm pools of branch ENIs of the trunk
+	OperationReconcileWarmPool Operations = "ReconcileWarmPool"
 )
 
 // OnDemandJob represents the job that will be executed by the respective worker
@@ -95,6 +99,22 @@ func NewOnDemandDeleteNodeJob(nodeName string) OnDemandJob {
 	}
 }
 
+// NewOnDemandVerifyTrunkJob returns a verify trunk job
+func NewOnDemandVerifyTrunkJob(nodeName string) OnDemandJob {
+	return OnDemandJob{
+		Operation: OperationVerifyTrunk,
+		NodeName:  nodeName,
+	}
+}
+
+// NewOnDemandReconcileWarmPoolJob returns a reconcile warm pool job
+func NewOnDemandReconcileWarmPoolJob(nodeName string) OnDemandJob {
+	return OnDemandJob{
+		Operation: OperationReconcileWarmPool,
+		NodeName:  nodeName,
+	}
+}
+
 // WarmPoolJob represents the job for a resource handler for warm pool resources
 type WarmPoolJob struct {
 	// Operation is the type of operation on warm pool
@@ -139,3 +159,60 @@ func NewWarmProcessDeleteQueueJob(nodeName string) *WarmPoolJob {
 		NodeName:   nodeName,
 	}
 }
+
+// PriorityClass is the class of a job, the jobs of higher classes get a larger share of the worker routines when jobs
+// of multiple classes are queued
+type PriorityClass int
+
+const (
+	// PriorityHigh is the class of the jobs that pods wait on to start
+	PriorityHigh PriorityClass = iota
+	// PriorityNormal is the class of the jobs that maintain the warm pools and the state of the nodes
+	PriorityNormal
+	// PriorityLow is the class of the jobs that clean up the resources of deleted pods and nodes
+	PriorityLow
+
+	numPriorityClasses = int(PriorityLow) + 1
+)
+
+// priorityWeights is the relative share of the worker routines of each priority class
+var priorityWeights = [numPriorityClasses]int{
+	PriorityHigh:   8,
+	PriorityNormal: 4,
+	PriorityLow:    1,
+}
+
+func (p PriorityClass) String() string {
+	switch p {
+	case PriorityHigh:
+		return "high"
+	case PriorityLow:
+		return "low"
+	default:
+		return "normal"
+	}
+}
+
+// GetJobPriority returns the priority class of the job
+func GetJobPriority(job interface{}) PriorityClass {
+	switch GetJobOperation(job) {
+	case OperationCreate:
+		return PriorityHigh
+	case OperationDeleted, OperationDeleting, OperationProcessDeleteQueue, OperationDeleteNode:
+		return PriorityLow
+	default:
+		return PriorityNormal
+	}
+}
+
+// GetJobOperation returns the operation of the on demand or warm pool job, empty for other jobs
+func GetJobOperation(job interface{}) Operations {
+	switch j := job.(type) {
+	case OnDemandJob:
+		return j.Operation
+	case *WarmPoolJob:
+		return j.Operations
+	default:
+		return ""
+	}
+}
@@ -85,3 +85,27 @@ func TestNewWarmPoolReSyncJob(t *testing.T) {
 	assert.Equal(t, OperationReSyncPool, WarmPoolJob.Operations)
 	assert.Equal(t, nodeName, WarmPoolJob.NodeName)
 }
+
+// TestGetJobPriority tests the pod start jobs have high priority and the clean up jobs have low priority
+func TestGetJobPriority(t *testing.T) {
+	tests := []struct {
+		job      interface{}
+		priority PriorityClass
+	}{
+		{job: NewOnDemandCreateJob(podNamespace, podName, reqCount), priority: PriorityHigh},
+		{job: NewWarmPoolCreateJob(nodeName, reqCount), priority: PriorityHigh},
+		{job: NewOnDemandReconcileNodeJob(nodeName), priority: PriorityNormal},
+		{job: NewOnDemandVerifyTrunkJob(nodeName), priority: PriorityNormal},
+		{job: NewWarmPoolReSyncJob(nodeName), priority: PriorityNormal},
+		{job: NewOnDemandDeletedJob(nodeName, UID), priority: PriorityLow},
+		{job: NewOnDemandProcessDeleteQueueJob(nodeName), priority: PriorityLow},
+		{job: NewOnDemandDeleteNodeJob(nodeName), priority: PriorityLow},
+		{job: NewWarmPoolDeleteJob(nodeName, []string{"ip-1"}), priority: PriorityLow},
+		{job: NewWarmProcessDeleteQueueJob(nodeName), priority: PriorityLow},
+		{job: "unknown", priority: PriorityNormal},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.priority, GetJobPriority(test.job), "job %v", test.job)
+	}
+}
@@ -0,0 +1,302 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/workqueue"
+)
+
+var (
+	jobsQueueDepth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "jobs_queue_depth",
+			Help: "The number of jobs waiting to be processed by priority class",
+		}, []string{"resource", "priority"},
+	)
+
+	jobsQueueLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "jobs_queue_latency_seconds",
+			Help:    "The time jobs wait to be processed after they are ready by priority class",
+			Buckets: prometheus.ExponentialBuckets(0.001, 4, 10),
+		}, []string{"resource", "priority"},
+	)
+)
+
+// classQueue is the rate limiting queue of the jobs of a priority class, which tracks the time the queued jobs are
+// ready to be processed
+type classQueue struct {
+	workqueue.RateLimitingInterface
+	rateLimiter workqueue.RateLimiter
+
+	lock sync.Mutex
+	// readyTimes is the earliest time each queued job is ready to be processed
+	readyTimes map[interface{}]time.Time
+}
+
+func newClassQueue() *classQueue {
+	rateLimiter := workqueue.DefaultControllerRateLimiter()
+	return &classQueue{
+		RateLimitingInterface: workqueue.NewRateLimitingQueue(rateLimiter),
+		rateLimiter:           rateLimiter,
+		readyTimes:            make(map[interface{}]time.Time),
+	}
+}
+
+func (q *classQueue) Add(job interface{}) {
+	q.setReadyTime(job, time.Now())
+	q.RateLimitingInterface.Add(job)
+}
+
+func (q *classQueue) AddAfter(job interface{}, duration time.Duration) {
+	q.setReadyTime(job, time.Now().Add(duration))
+	q.RateLimitingInterface.AddAfter(job, duration)
+}
+
+func (q *classQueue) AddRateLimited(job interface{}) {
+	q.AddAfter(job, q.rateLimiter.When(job))
+}
+
+func (q *classQueue) setReadyTime(job interface{}, readyTime time.Time) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if existing, ok := q.readyTimes[job]; !ok || readyTime.Before(existing) {
+		q.readyTimes[job] = readyTime
+	}
+}
+
+// popReadyTime returns the time the job was ready to be processed and stops tracking it
+func (q *classQueue) popReadyTime(job interface{}) (time.Time, bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	readyTime, ok := q.readyTimes[job]
+	delete(q.readyTimes, job)
+	return readyTime, ok
+}
+
+// readyJob is the job taken from the queue of a priority class, waiting to be picked by a worker routine
+type readyJob struct {
+	job   interface{}
+	isSet bool
+}
+
+// priorityWorker is the worker pool that queues the jobs of each priority class separately and shares the worker
+// routines between the classes with queued jobs by weighted fair scheduling, so the jobs pods wait on to start are
+// not queued behind the clean up jobs
+type priorityWorker struct {
+	worker
+
+	queues [numPriorityClasses]*classQueue
+
+	lock sync.Mutex
+	// cond is signalled when a job is ready or picked, or when a queue is shut down
+	cond *sync.Cond
+	// ready is the next job of each priority class, only one job is taken from the queue of a class at a time so the
+	// jobs stay in the queues until a worker routine is available
+	ready [numPriorityClasses]readyJob
+	// currentWeights are the smooth weighted round robin weights of the priority classes
+	currentWeights [numPriorityClasses]int
+	// shutDownQueues is the number of queues that are shut down
+	shutDownQueues int
+}
+
+// NewPriorityWorkerPool returns a new worker pool for a given resource type that processes the jobs by their priority
+// class
+func NewPriorityWorkerPool(resourceName string, workerCount int, maxRequeue int,
+	logger logr.Logger, ctx context.Context) Worker {
+
+	prometheusRegister()
+
+	w := &priorityWorker{
+		worker: worker{
+			resourceName:    resourceName,
+			maxRetriesOnErr: maxRequeue,
+			maxWorkerCount:  workerCount,
+			Log:             logger,
+			ctx:             ctx,
+		},
+	}
+	w.cond = sync.NewCond(&w.lock)
+	for class := range w.queues {
+		w.queues[class] = newClassQueue()
+	}
+	return w
+}
+
+// SubmitJob adds the job to the queue of its priority class
+func (w *priorityWorker) SubmitJob(job interface{}) {
+	// in theory, only health check endpoint should send a nil job to test periodically
+	if job == nil {
+		w.Log.V(1).Info("For informational / health check purpose only to check worker queue availability",
+			"WorkerQueueLen", w.queueLen())
+		return
+	}
+	class := GetJobPriority(job)
+	w.queues[class].Add(job)
+	w.updateQueueDepth(class)
+	jobsSubmittedCount.WithLabelValues(w.resourceName).Inc()
+}
+
+// SubmitJobWithContext adds the job to the queue of its priority class with the span context of the caller
+func (w *priorityWorker) SubmitJobWithContext(ctx context.Context, job interface{}) {
+	if job != nil {
+		w.jobContexts.set(ctx, job)
+	}
+	w.SubmitJob(job)
+}
+
+// SubmitJobAfter submits the job to the queue of its priority class after the given time period
+func (w *priorityWorker) SubmitJobAfter(job interface{}, submitAfter time.Duration) {
+	w.queues[GetJobPriority(job)].AddAfter(job, submitAfter)
+	jobsSubmittedCount.WithLabelValues(w.resourceName).Inc()
+}
+
+// StartWorkerPool starts a routine for each priority class to take the jobs from the queue of the class, and the
+// worker routines that process the jobs
+func (w *priorityWorker) StartWorkerPool(workerFunc WorkerFunc) error {
+	if w.workersStarted {
+		return WorkersAlreadyStartedError
+	}
+	w.workerFunc = workerFunc
+	w.workersStarted = true
+
+	go func() {
+		w.Log.Info("starting routine to listen on chanel for termination signal")
+		<-w.ctx.Done()
+		for _, queue := range w.queues {
+			queue.ShutDown()
+		}
+		w.Log.Info("shut down the queues after receiving termination signal")
+	}()
+
+	for class := range w.queues {
+		go w.dispatch(PriorityClass(class))
+	}
+
+	w.Log.Info("starting worker routines", "worker count", w.maxWorkerCount)
+
+	for workerCount := 1; workerCount <= w.maxWorkerCount; workerCount++ {
+		go w.runWorker()
+	}
+
+	return nil
+}
+
+// dispatch takes the jobs from the queue of the priority class one at a time and makes them ready for the worker
+// routines, until the queue is shut down
+func (w *priorityWorker) dispatch(class PriorityClass) {
+	queue := w.queues[class]
+	for {
+		w.lock.Lock()
+		for w.ready[class].isSet {
+			w.cond.Wait()
+		}
+		w.lock.Unlock()
+
+		job, quit := queue.Get()
+		if quit {
+			w.lock.Lock()
+			w.shutDownQueues++
+			w.cond.Broadcast()
+			w.lock.Unlock()
+			return
+		}
+		w.updateQueueDepth(class)
+
+		w.lock.Lock()
+		w.ready[class] = readyJob{job: job, isSet: true}
+		w.cond.Broadcast()
+		w.lock.Unlock()
+	}
+}
+
+// runWorker processes the ready jobs until all the queues are shut down
+func (w *priorityWorker) runWorker() {
+	for {
+		class, job, ok := w.nextJob()
+		if !ok {
+			return
+		}
+
+		queue := w.queues[class]
+		if readyTime, ok := queue.popReadyTime(job); ok {
+			jobsQueueLatency.WithLabelValues(w.resourceName, class.String()).
+				Observe(time.Since(readyTime).Seconds())
+		}
+		w.processJob(queue, job)
+		queue.Done(job)
+	}
+}
+
+// nextJob waits for a ready job and returns it, ok is false if all the queues are shut down and no job is ready
+func (w *priorityWorker) nextJob() (class PriorityClass, job interface{}, ok bool) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	for {
+		if class, ok = w.pickClass(); ok {
+			job = w.ready[class].job
+			w.ready[class] = readyJob{}
+			// Wake up the routine taking the next job of the class
+			w.cond.Broadcast()
+			return class, job, true
+		}
+		if w.shutDownQueues == numPriorityClasses {
+			return class, nil, false
+		}
+		w.cond.Wait()
+	}
+}
+
+// pickClass returns the priority class with a ready job to process next by smooth weighted round robin, so each class
+// with ready jobs gets a share of the jobs processed proportional to its weight
+func (w *priorityWorker) pickClass() (PriorityClass, bool) {
+	picked, totalWeight := -1, 0
+	for class := range w.ready {
+		if !w.ready[class].isSet {
+			continue
+		}
+		w.currentWeights[class] += priorityWeights[class]
+		totalWeight += priorityWeights[class]
+		if picked < 0 || w.currentWeights[class] > w.currentWeights[picked] {
+			picked = class
+		}
+	}
+	if picked < 0 {
+		return 0, false
+	}
+	w.currentWeights[picked] -= totalWeight
+	return PriorityClass(picked), true
+}
+
+// queueLen returns the number of jobs waiting in the queues of all the priority classes
+func (w *priorityWorker) queueLen() int {
+	queueLen := 0
+	for _, queue := range w.queues {
+		queueLen += queue.Len()
+	}
+	return queueLen
+}
+
+func (w *priorityWorker) updateQueueDepth(class PriorityClass) {
+	jobsQueueDepth.WithLabelValues(w.resourceName, class.String()).Set(float64(w.queues[class].Len()))
+}
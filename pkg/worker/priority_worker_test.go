@@ -0,0 +1,162 @@
+// Copyright Amazon.com Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+func GetMockPriorityWorkerPool(ctx context.Context) *priorityWorker {
+	log := zap.New(zap.UseDevMode(true)).WithValues("worker resource Id", resourceName)
+	return NewPriorityWorkerPool(resourceName, workerCount, maxRequeue, log, ctx).(*priorityWorker)
+}
+
+// TestPriorityWorker_SubmitJob tests the jobs pods wait on to start are processed ahead of the queued clean up jobs
+func TestPriorityWorker_SubmitJob(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := GetMockPriorityWorkerPool(ctx)
+
+	jobCount := 10
+	var lock sync.Mutex
+	var processed []PriorityClass
+	done := make(chan struct{})
+	workerFunc := func(_ context.Context, job interface{}) (ctrl.Result, error) {
+		// The jobs take time to process, so the next job of each class is ready by the time the worker is free
+		time.Sleep(time.Millisecond * 5)
+		lock.Lock()
+		defer lock.Unlock()
+		processed = append(processed, GetJobPriority(job))
+		if len(processed) == 2*jobCount {
+			close(done)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// Queue the clean up jobs ahead of the pod start jobs
+	for i := 0; i < jobCount; i++ {
+		w.SubmitJob(NewOnDemandDeletedJob(nodeName, types.UID(fmt.Sprintf("uid-%d", i))))
+	}
+	for i := 0; i < jobCount; i++ {
+		w.SubmitJob(NewOnDemandCreateJob(podNamespace, fmt.Sprintf("pod-%d", i), reqCount))
+	}
+	assert.Equal(t, 2*jobCount, w.queueLen())
+
+	err := w.StartWorkerPool(workerFunc)
+	assert.NoError(t, err)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the jobs to complete")
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	lowBeforeLastHigh := 0
+	for i, highCount := 0, 0; highCount < jobCount; i++ {
+		if processed[i] == PriorityHigh {
+			highCount++
+		} else {
+			lowBeforeLastHigh++
+		}
+	}
+	// With the weights of 8 and 1, at most 2 clean up jobs are scheduled with the 10 pod start jobs, and one more
+	// may be picked before the pod start jobs are ready
+	assert.LessOrEqual(t, lowBeforeLastHigh, 3)
+}
+
+// TestPriorityWorker_SubmitJob_RequeueOnError verifies the failed job is retried on the queue of its class
+func TestPriorityWorker_SubmitJob_RequeueOnError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var lock sync.Mutex
+	invoked := 0
+	workerFunc := func(_ context.Context, job interface{}) (ctrl.Result, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		invoked++
+		return ctrl.Result{}, fmt.Errorf("error")
+	}
+
+	w := GetMockPriorityWorkerPool(ctx)
+	err := w.StartWorkerPool(workerFunc)
+	assert.NoError(t, err)
+
+	w.SubmitJob(NewOnDemandProcessDeleteQueueJob(nodeName))
+
+	time.Sleep((mockTimeToProcessWorkerFunc + bufferTimeBwWorkerFuncExecution) * time.Millisecond * time.Duration(maxRequeue))
+
+	// expected invocation = max requeue + the first invocation
+	lock.Lock()
+	assert.Equal(t, maxRequeue+1, invoked)
+	lock.Unlock()
+}
+
+// TestPriorityWorker_pickClass tests the classes with ready jobs are picked in proportion to their weights
+func TestPriorityWorker_pickClass(t *testing.T) {
+	w := GetMockPriorityWorkerPool(context.Background())
+
+	_, ok := w.pickClass()
+	assert.False(t, ok)
+
+	for class := range w.ready {
+		w.ready[class].isSet = true
+	}
+	picked := map[PriorityClass]int{}
+	for i := 0; i < 13; i++ {
+		class, ok := w.pickClass()
+		assert.True(t, ok)
+		picked[class]++
+	}
+	assert.Equal(t, map[PriorityClass]int{PriorityHigh: 8, PriorityNormal: 4, PriorityLow: 1}, picked)
+
+	w.ready[PriorityHigh].isSet = false
+	class, ok := w.pickClass()
+	assert.True(t, ok)
+	assert.NotEqual(t, PriorityHigh, class)
+}
+
+// TestClassQueue_ReadyTime tests the earliest time a job is ready is tracked until the job is processed
+func TestClassQueue_ReadyTime(t *testing.T) {
+	queue := newClassQueue()
+	defer queue.ShutDown()
+	job := NewOnDemandReconcileNodeJob(nodeName)
+
+	queue.AddAfter(job, time.Hour)
+	queue.Add(job)
+	readyTime, ok := queue.popReadyTime(job)
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now(), readyTime, time.Second)
+
+	_, ok = queue.popReadyTime(job)
+	assert.False(t, ok)
+
+	requeueTime := time.Now()
+	queue.AddRateLimited(job)
+	readyTime, ok = queue.popReadyTime(job)
+	assert.True(t, ok)
+	assert.True(t, readyTime.After(requeueTime))
+	assert.Equal(t, 1, queue.NumRequeues(job))
+}
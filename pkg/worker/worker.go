@@ -16,10 +16,14 @@ package worker
 import (
 	"context"
 	"errors"
+	"sync"
 	"time"
 
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/tracing"
+
 	"github.com/go-logr/logr"
 	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -66,9 +70,14 @@ var (
 	WorkersAlreadyStartedError = errors.New("failed to start the workers as they are already running")
 )
 
+// WorkerFunc is the function that processes a job, the context has the span of the job
+type WorkerFunc func(ctx context.Context, job interface{}) (ctrl.Result, error)
+
 type Worker interface {
-	StartWorkerPool(func(interface{}) (ctrl.Result, error)) error
+	StartWorkerPool(WorkerFunc) error
 	SubmitJob(job interface{})
+	// SubmitJobWithContext submits the job, and the span of the job continues the trace of the context
+	SubmitJobWithContext(ctx context.Context, job interface{})
 	SubmitJobAfter(job interface{}, submitAfter time.Duration)
 }
 
@@ -78,7 +87,7 @@ type worker struct {
 	// workersStarted is the flag to prevent starting duplicate set of workers
 	workersStarted bool
 	// workerFunc is the function that will be invoked with the job by the worker routine
-	workerFunc func(interface{}) (ctrl.Result, error)
+	workerFunc WorkerFunc
 	// maxRetries is the number of times to retry item in case of failure
 	maxRetriesOnErr int
 	// maxWorkerCount represents the maximum number of workers that will be started
@@ -89,6 +98,53 @@ type worker struct {
 	Log logr.Logger
 	// queue is the k8s rate limiting queue to store the submitted jobs
 	queue workqueue.RateLimitingInterface
+	// jobContexts are the span contexts of the callers that submitted the queued jobs
+	jobContexts jobContexts
+}
+
+// jobContexts holds the span context of the caller that submitted each job until the job is forgotten, so the span
+// of the job and its retries continue the trace of the caller. The span contexts are kept out of the jobs so the
+// queue still merges the duplicate jobs
+type jobContexts struct {
+	lock         sync.Mutex
+	spanContexts map[interface{}]trace.SpanContext
+}
+
+// set stores the span context of the caller, the span context of the first caller is kept for duplicate jobs
+func (j *jobContexts) set(ctx context.Context, job interface{}) {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return
+	}
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if j.spanContexts == nil {
+		j.spanContexts = make(map[interface{}]trace.SpanContext)
+	}
+	if _, ok := j.spanContexts[job]; !ok {
+		j.spanContexts[job] = spanContext
+	}
+}
+
+// get returns the parent context with the span context of the caller that submitted the job
+func (j *jobContexts) get(parent context.Context, job interface{}) context.Context {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if spanContext, ok := j.spanContexts[job]; ok {
+		return trace.ContextWithRemoteSpanContext(parent, spanContext)
+	}
+	return parent
+}
+
+// forget removes the span context of the job once the job is not retried anymore
+func (j *jobContexts) forget(job interface{}) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	delete(j.spanContexts, job)
 }
 
 // NewDefaultWorkerPool returns a new worker pool for a give resource type with the given configuration
@@ -114,13 +170,15 @@ func prometheusRegister() {
 			jobsSubmittedCount,
 			jobsCompletedCount,
 			jobsFailedCount,
-			jobsNotFoundCount)
+			jobsNotFoundCount,
+			jobsQueueDepth,
+			jobsQueueLatency)
 
 		prometheusRegistered = true
 	}
 }
 
-func (w *worker) SetWorkerFunc(workerFunc func(interface{}) (ctrl.Result, error)) {
+func (w *worker) SetWorkerFunc(workerFunc WorkerFunc) {
 	w.workerFunc = workerFunc
 }
 
@@ -136,6 +194,14 @@ func (w *worker) SubmitJob(job interface{}) {
 	jobsSubmittedCount.WithLabelValues(w.resourceName).Inc()
 }
 
+// SubmitJobWithContext adds the job to the rate limited queue with the span context of the caller
+func (w *worker) SubmitJobWithContext(ctx context.Context, job interface{}) {
+	if job != nil {
+		w.jobContexts.set(ctx, job)
+	}
+	w.SubmitJob(job)
+}
+
 // SubmitJobAfter submits the job to the work queue after the given time period
 func (w *worker) SubmitJobAfter(job interface{}, submitAfter time.Duration) {
 	w.queue.AddAfter(job, submitAfter)
@@ -155,42 +221,57 @@ func (w *worker) processNextItem() (cont bool) {
 		return
 	}
 	defer w.queue.Done(job)
+
+	w.processJob(w.queue, job)
+	return true
+}
+
+// processJob executes the job and re-queues it on the queue if it fails or has to be retried after some time
+func (w *worker) processJob(queue workqueue.RateLimitingInterface, job interface{}) {
 	log := w.Log.WithValues("job", job)
 
-	cont = true
+	ctx, span := tracing.Start(w.jobContexts.get(w.ctx, job), "worker.processJob",
+		tracing.AttributeResourceName.String(w.resourceName),
+		tracing.AttributeJobOperation.String(string(GetJobOperation(job))))
+	result, err := w.workerFunc(ctx, job)
+	tracing.End(span, err)
 
-	if result, err := w.workerFunc(job); err != nil {
-		if w.queue.NumRequeues(job) >= w.maxRetriesOnErr {
+	if err != nil {
+		if queue.NumRequeues(job) >= w.maxRetriesOnErr {
 			log.Error(err, "exceeded maximum retries", "max retries", w.maxRetriesOnErr)
-			w.queue.Forget(job)
+			w.forget(queue, job)
 			jobsFailedCount.WithLabelValues(w.resourceName).Inc()
 			return
 		} else if apierrors.IsNotFound(err) {
 			//similar to upstream https://github.com/kubernetes-sigs/controller-runtime/issues/377#issue-426207628
 			log.Error(err, "won't requeue a not found errored job", "job", job)
-			w.queue.Forget(job)
+			w.forget(queue, job)
 			jobsNotFoundCount.WithLabelValues(w.resourceName).Inc()
 			return
 		}
-		log.Error(err, "re-queuing job", "retry count", w.queue.NumRequeues(job))
-		w.queue.AddRateLimited(job)
+		log.Error(err, "re-queuing job", "retry count", queue.NumRequeues(job))
+		queue.AddRateLimited(job)
 		return
 	} else if result.Requeue {
 		log.V(1).Info("timed retry", "retry after", result.RequeueAfter)
-		w.queue.AddAfter(job, result.RequeueAfter)
+		queue.AddAfter(job, result.RequeueAfter)
 		return
 	}
 
 	log.V(1).Info("completed job successfully")
 
-	w.queue.Forget(job)
+	w.forget(queue, job)
 	jobsCompletedCount.WithLabelValues(w.resourceName).Inc()
+}
 
-	return
+// forget stops tracking the retries and the span context of the job
+func (w *worker) forget(queue workqueue.RateLimitingInterface, job interface{}) {
+	queue.Forget(job)
+	w.jobContexts.forget(job)
 }
 
 // StartWorkerPool starts the worker pool that starts the worker routines that concurrently listen on the channel
-func (w *worker) StartWorkerPool(workerFunc func(interface{}) (ctrl.Result, error)) error {
+func (w *worker) StartWorkerPool(workerFunc WorkerFunc) error {
 	if w.workersStarted {
 		return WorkersAlreadyStartedError
 	}
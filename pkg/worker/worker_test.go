@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -42,7 +43,7 @@ func GetMockWorkerPool(ctx context.Context) Worker {
 	return NewDefaultWorkerPool(resourceName, workerCount, maxRequeue, log, ctx)
 }
 
-func MockWorkerFunc(job interface{}) (result ctrl.Result, err error) {
+func MockWorkerFunc(_ context.Context, job interface{}) (result ctrl.Result, err error) {
 	mu.Lock()
 	defer mu.Unlock()
 	v := job.(*int)
@@ -90,7 +91,7 @@ func TestWorker_SubmitJob_RequeueOnError(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	workerFunc := func(job interface{}) (result ctrl.Result, err error) {
+	workerFunc := func(_ context.Context, job interface{}) (result ctrl.Result, err error) {
 		mu.Lock()
 		defer mu.Unlock()
 		invoked := job.(*int)
@@ -118,7 +119,7 @@ func TestWorker_SubmitJob_NotRequeueOnError(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	workerFunc := func(job interface{}) (result ctrl.Result, err error) {
+	workerFunc := func(_ context.Context, job interface{}) (result ctrl.Result, err error) {
 		mu.Lock()
 		defer mu.Unlock()
 		invoked := job.(*int)
@@ -144,3 +145,62 @@ func TestWorker_SubmitJob_NotRequeueOnError(t *testing.T) {
 	assert.Equal(t, actualInqueue, invoked)
 	mu.RUnlock()
 }
+
+// getMockSpanContext returns a context with a sampled remote span context of the trace ID
+func getMockSpanContext(traceID byte) context.Context {
+	return trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{traceID},
+		SpanID:     trace.SpanID{traceID},
+		TraceFlags: trace.FlagsSampled,
+	}))
+}
+
+// TestWorker_SubmitJobWithContext verifies the job is processed in the trace of the context it was submitted with,
+// and the span context is forgotten once the job completes
+func TestWorker_SubmitJobWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var traceID trace.TraceID
+	workerFunc := func(ctx context.Context, job interface{}) (result ctrl.Result, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		traceID = trace.SpanContextFromContext(ctx).TraceID()
+		return ctrl.Result{}, nil
+	}
+
+	w := GetMockWorkerPool(ctx)
+	err := w.StartWorkerPool(workerFunc)
+	assert.NoError(t, err)
+
+	var job = 0
+	w.SubmitJobWithContext(getMockSpanContext(1), &job)
+
+	time.Sleep(time.Millisecond * (mockTimeToProcessWorkerFunc + bufferTimeBwWorkerFuncExecution))
+
+	mu.RLock()
+	assert.Equal(t, trace.TraceID{1}, traceID)
+	mu.RUnlock()
+
+	jobContexts := &w.(*worker).jobContexts
+	jobContexts.lock.Lock()
+	assert.Empty(t, jobContexts.spanContexts)
+	jobContexts.lock.Unlock()
+}
+
+// TestJobContexts verifies the span context of the first caller is kept for the duplicate jobs until it's forgotten
+func TestJobContexts(t *testing.T) {
+	job := OnDemandJob{Operation: OperationCreate, PodName: "pod-1", PodNamespace: "default"}
+	jobContexts := &jobContexts{}
+
+	// The context without a span isn't stored
+	jobContexts.set(context.Background(), job)
+	assert.False(t, trace.SpanContextFromContext(jobContexts.get(context.Background(), job)).IsValid())
+
+	jobContexts.set(getMockSpanContext(1), job)
+	jobContexts.set(getMockSpanContext(2), job)
+	assert.Equal(t, trace.TraceID{1}, trace.SpanContextFromContext(jobContexts.get(context.Background(), job)).TraceID())
+
+	jobContexts.forget(job)
+	assert.False(t, trace.SpanContextFromContext(jobContexts.get(context.Background(), job)).IsValid())
+}
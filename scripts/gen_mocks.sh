@@ -14,6 +14,10 @@ mockgen -destination=../mocks/amazon-vcp-resource-controller-k8s/pkg/worker/mock
 mockgen -destination=../mocks/amazon-vcp-resource-controller-k8s/pkg/handler/mock_handler.go github.com/aws/amazon-vpc-resource-controller-k8s/pkg/handler Handler
 # package provider mocks
 mockgen -destination=../mocks/amazon-vcp-resource-controller-k8s/pkg/provider/mock_provider.go github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider ResourceProvider
+mockgen -destination=../mocks/amazon-vcp-resource-controller-k8s/pkg/provider/mock_branch_eni_sg_updater.go github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider BranchENISecurityGroupUpdater
+mockgen -destination=../mocks/amazon-vcp-resource-controller-k8s/pkg/provider/mock_branch_eni_subnets_setter.go github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider BranchENISubnetsSetter
+mockgen -destination=../mocks/amazon-vcp-resource-controller-k8s/pkg/provider/mock_branch_eni_subnets_lister.go github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider BranchENISubnetsLister
+mockgen -destination=../mocks/amazon-vcp-resource-controller-k8s/pkg/provider/mock_node_introspector.go github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider NodeIntrospector
 mockgen -destination=../mocks/amazon-vcp-resource-controller-k8s/pkg/provider/branch/trunk/mock_trunk.go github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider/branch/trunk TrunkENI
 mockgen -destination=../mocks/amazon-vcp-resource-controller-k8s/pkg/provider/branch/cooldown/mock_cooldown.go github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider/branch/cooldown CoolDown
 mockgen -destination=../mocks/amazon-vcp-resource-controller-k8s/pkg/provider/ip/eni/mock_eni.go github.com/aws/amazon-vpc-resource-controller-k8s/pkg/provider/ip/eni ENIManager
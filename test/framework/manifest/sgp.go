@@ -27,7 +27,7 @@ type SGPBuilder struct {
 	namespace     string
 	securityGroup []string
 	podSelector   *v1.LabelSelector
-	saSelector    *v1.LabelSelector
+	saSelector    *v1beta1.ServiceAccountSelector
 }
 
 func NewSGPBuilder() *SGPBuilder {
@@ -68,7 +68,10 @@ func (s *SGPBuilder) PodMatchLabel(key string, value string) *SGPBuilder {
 
 func (s *SGPBuilder) ServiceAccountMatchLabel(key string, value string) *SGPBuilder {
 	if s.saSelector == nil {
-		s.saSelector = &v1.LabelSelector{}
+		s.saSelector = &v1beta1.ServiceAccountSelector{}
+	}
+	if s.saSelector.LabelSelector == nil {
+		s.saSelector.LabelSelector = &v1.LabelSelector{}
 	}
 	if s.saSelector.MatchLabels == nil {
 		s.saSelector.MatchLabels = map[string]string{}
@@ -79,7 +82,10 @@ func (s *SGPBuilder) ServiceAccountMatchLabel(key string, value string) *SGPBuil
 
 func (s *SGPBuilder) ServiceAccountMatchExpression(key string, operator v1.LabelSelectorOperator, values ...string) *SGPBuilder {
 	if s.saSelector == nil {
-		s.saSelector = &v1.LabelSelector{}
+		s.saSelector = &v1beta1.ServiceAccountSelector{}
+	}
+	if s.saSelector.LabelSelector == nil {
+		s.saSelector.LabelSelector = &v1.LabelSelector{}
 	}
 	if s.saSelector.MatchExpressions == nil {
 		s.saSelector.MatchExpressions = []v1.LabelSelectorRequirement{}
@@ -92,6 +98,14 @@ func (s *SGPBuilder) ServiceAccountMatchExpression(key string, operator v1.Label
 	return s
 }
 
+func (s *SGPBuilder) ServiceAccountMatchName(names ...string) *SGPBuilder {
+	if s.saSelector == nil {
+		s.saSelector = &v1beta1.ServiceAccountSelector{}
+	}
+	s.saSelector.MatchNames = append(s.saSelector.MatchNames, names...)
+	return s
+}
+
 func (s *SGPBuilder) Build() (*v1beta1.SecurityGroupPolicy, error) {
 	if s.securityGroup == nil {
 		return nil, fmt.Errorf("security group is required field")
@@ -16,6 +16,7 @@ package core
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -35,6 +36,9 @@ const (
 	DefaultResourceLimit         = "1"
 	FargatePodSGAnnotationKey    = "fargate.amazonaws.com/pod-sg"
 	FargatePodIdentifierLabelKey = "eks.amazonaws.com/fargate-profile"
+	// IPv6AddressRequestLabelKey is the label on the pods that request an IPv6 address from the /80 IPv6 prefixes of
+	// their node when IPv6 prefix delegation is enabled
+	IPv6AddressRequestLabelKey = "vpc.amazonaws.com/ipv6-address"
 )
 
 // +kubebuilder:webhook:path=/mutate-v1-pod,mutating=true,matchPolicy=Equivalent,failurePolicy=ignore,groups="",resources=pods,verbs=create,versions=v1,name=mpod.vpc.k8s.aws,sideEffects=None,admissionReviewVersions=v1
@@ -45,6 +49,8 @@ type PodMutationWebHook struct {
 	SGPAPI    utils.SecurityGroupForPodsAPI
 	Log       logr.Logger
 	Condition condition.Conditions
+	// EnableIPv6PrefixDelegation injects the IPv6 address resource to the pods that request it
+	EnableIPv6PrefixDelegation bool
 }
 
 func NewPodMutationWebHook(
@@ -53,12 +59,14 @@ func NewPodMutationWebHook(
 	condition condition.Conditions,
 	d admission.Decoder,
 	healthzHandler *rcHealthz.HealthzHandler,
+	enableIPv6PrefixDelegation bool,
 ) *PodMutationWebHook {
 	podWebhook := &PodMutationWebHook{
-		SGPAPI:    sgpAPI,
-		Log:       log,
-		Condition: condition,
-		decoder:   d,
+		SGPAPI:                     sgpAPI,
+		Log:                        log,
+		Condition:                  condition,
+		decoder:                    d,
+		EnableIPv6PrefixDelegation: enableIPv6PrefixDelegation,
 	}
 	// add health check on subpath for pod mutation webhook
 	healthzHandler.AddControllersHealthCheckers(
@@ -96,8 +104,10 @@ func (i *PodMutationWebHook) Handle(_ context.Context, req admission.Request) ad
 		response = i.HandleFargatePod(req, pod, log)
 	case Linux:
 		response = i.HandleLinuxPod(req, pod, log)
+		response = i.HandleIPv6Pod(req, pod, response, log)
 	case Windows:
 		response = i.HandleWindowsPod(req, pod, log)
+		response = i.HandleIPv6Pod(req, pod, response, log)
 	default:
 		response = admission.Allowed("No criteria met injecting resource limit to Pod")
 	}
@@ -117,7 +127,7 @@ func WhichPod(pod *corev1.Pod) PodType {
 		return Fargate
 	}
 	// Windows Pod
-	if hasWindowsNodeSelector(pod) {
+	if utils.HasWindowsNodeSelector(pod) {
 		return Windows
 	}
 	return Linux
@@ -131,6 +141,10 @@ func (i *PodMutationWebHook) HandleFargatePod(req admission.Request, pod *corev1
 	if err != nil {
 		i.Log.Error(err, "failed to get matching SGP for Pods",
 			"namespace", pod.Namespace, "name", pod.Name)
+		limitErr := &utils.SecurityGroupLimitExceededError{}
+		if errors.As(err, &limitErr) {
+			return admission.Denied(limitErr.Error())
+		}
 		return admission.Denied("Failed to get Matching SGP for Pods, rejecting event")
 	}
 
@@ -160,7 +174,9 @@ func (i *PodMutationWebHook) HandleFargatePod(req admission.Request, pod *corev1
 }
 
 // HandleWindowsPod mutates the Windows Pod by injecting a secondary IPv4 Address
-// Limit to the Pod when the Windows IPAM feature is enabled via ConfigMap
+// Limit to the Pod when the Windows IPAM feature is enabled via ConfigMap. If the
+// Windows Security Groups for Pods feature is also enabled and the Pod matches any
+// SGP, pod-eni limit is injected instead
 func (i *PodMutationWebHook) HandleWindowsPod(req admission.Request, pod *corev1.Pod,
 	log logr.Logger) (response admission.Response) {
 
@@ -168,12 +184,29 @@ func (i *PodMutationWebHook) HandleWindowsPod(req admission.Request, pod *corev1
 		return admission.Allowed("")
 	}
 
+	resourceName := corev1.ResourceName(config.ResourceNameIPAddress)
+	if i.Condition.IsWindowsSecurityGroupsForPodsEnabled() {
+		sgList, err := i.SGPAPI.GetMatchingSecurityGroupForPods(pod)
+		if err != nil {
+			i.Log.Error(err, "failed to get matching SGP for Pods",
+				"namespace", pod.Namespace, "name", pod.Name)
+			limitErr := &utils.SecurityGroupLimitExceededError{}
+			if errors.As(err, &limitErr) {
+				return admission.Denied(limitErr.Error())
+			}
+			return admission.Denied("Failed to get Matching SGP for Pods, rejecting event")
+		}
+		if len(sgList) != 0 {
+			resourceName = config.ResourceNamePodENI
+		}
+	}
+
 	i.Log.Info("injecting resource to the first container of the pod",
-		"resource name", config.ResourceNameIPAddress, "resource count", DefaultResourceLimit)
+		"resource name", resourceName, "resource count", DefaultResourceLimit)
 	pod.Spec.Containers[0].
-		Resources.Limits[config.ResourceNameIPAddress] = resource.MustParse(DefaultResourceLimit)
+		Resources.Limits[resourceName] = resource.MustParse(DefaultResourceLimit)
 	pod.Spec.Containers[0].
-		Resources.Requests[config.ResourceNameIPAddress] = resource.MustParse(DefaultResourceLimit)
+		Resources.Requests[resourceName] = resource.MustParse(DefaultResourceLimit)
 
 	return i.GetPatchResponse(req, pod, log)
 }
@@ -187,6 +220,10 @@ func (i *PodMutationWebHook) HandleLinuxPod(req admission.Request, pod *corev1.P
 	if err != nil {
 		i.Log.Error(err, "failed to get matching SGP for Pods",
 			"namespace", pod.Namespace, "name", pod.Name)
+		limitErr := &utils.SecurityGroupLimitExceededError{}
+		if errors.As(err, &limitErr) {
+			return admission.Denied(limitErr.Error())
+		}
 		return admission.Denied("Failed to get Matching SGP for Pods, rejecting event")
 	}
 	if len(sgList) == 0 {
@@ -204,6 +241,30 @@ func (i *PodMutationWebHook) HandleLinuxPod(req admission.Request, pod *corev1.P
 	return i.GetPatchResponse(req, pod, log)
 }
 
+// HandleIPv6Pod mutates the Linux or Windows Pod by additionally injecting the IPv6 Address limit when the IPv6
+// prefix delegation feature is enabled and the Pod requests an IPv6 Address with the label. The Pods that got pod-eni
+// injected are skipped since the branch ENI provides their addresses
+func (i *PodMutationWebHook) HandleIPv6Pod(req admission.Request, pod *corev1.Pod, response admission.Response,
+	log logr.Logger) admission.Response {
+
+	if !i.EnableIPv6PrefixDelegation || !response.Allowed || pod.Labels[IPv6AddressRequestLabelKey] != "true" {
+		return response
+	}
+	if _, ok := pod.Spec.Containers[0].Resources.Limits[config.ResourceNamePodENI]; ok {
+		return response
+	}
+
+	log.Info("injecting resource to the first container of the pod", "resource name",
+		config.ResourceNameIPv6Address, "resource count", DefaultResourceLimit)
+
+	pod.Spec.Containers[0].Resources.
+		Limits[config.ResourceNameIPv6Address] = resource.MustParse(DefaultResourceLimit)
+	pod.Spec.Containers[0].Resources.
+		Requests[config.ResourceNameIPv6Address] = resource.MustParse(DefaultResourceLimit)
+
+	return i.GetPatchResponse(req, pod, log)
+}
+
 // InitializeEmptyFields inits the empty fields in the request
 func (i *PodMutationWebHook) InitializeEmptyFields(req admission.Request, pod *corev1.Pod) {
 	if pod.Spec.Containers[0].Resources.Limits == nil {
@@ -235,15 +296,3 @@ func (i *PodMutationWebHook) GetPatchResponse(req admission.Request, pod *corev1
 
 	return admission.PatchResponseFromRaw(req.Object.Raw, marshaledPod)
 }
-
-func hasWindowsNodeSelector(pod *corev1.Pod) bool {
-	osLabel := pod.Spec.NodeSelector[config.NodeLabelOS]
-
-	// Beta will be removed in v1.18.
-	osLabelBeta := pod.Spec.NodeSelector[config.NodeLabelOSBeta]
-
-	if osLabel != config.OSWindows && osLabelBeta != config.OSWindows {
-		return false
-	}
-	return true
-}
@@ -23,6 +23,7 @@ import (
 	mock_condition "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/condition"
 	mock_utils "github.com/aws/amazon-vpc-resource-controller-k8s/mocks/amazon-vcp-resource-controller-k8s/pkg/utils"
 	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/config"
+	"github.com/aws/amazon-vpc-resource-controller-k8s/pkg/utils"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -43,6 +44,7 @@ var (
 	firstContainerPatchLimitURI   = "/spec/containers/0/resources/limits"
 	ipResourceJsonPointer         = "/" + jsonPointer(config.ResourceNameIPAddress)
 	podENIResourceJsonPointer     = "/" + jsonPointer(config.ResourceNamePodENI)
+	ipv6ResourceJsonPointer       = "/" + jsonPointer(config.ResourceNameIPv6Address)
 )
 
 type Mock struct {
@@ -61,6 +63,8 @@ func TestPodMutationWebHook_Handle(t *testing.T) {
 	namespace := "default"
 	sgList := []string{"sg-1", "sg-2"}
 	mockErr := fmt.Errorf("mock erorr")
+	limitErr := &utils.SecurityGroupLimitExceededError{SecurityGroups: []string{"sg1", "sg2"},
+		Policies: []string{"default/sgp1", "default/sgp2"}, Limit: 1}
 
 	basePod := &corev1.Pod{
 		TypeMeta: metav1.TypeMeta{
@@ -141,12 +145,158 @@ func TestPodMutationWebHook_Handle(t *testing.T) {
 	sgpPodWithoutLimitsRaw, err := json.Marshal(sgpPodWithoutLimits)
 	assert.NoError(t, err)
 
+	// Pod requesting an IPv6 Address
+	ipv6Pod := basePod.DeepCopy()
+	ipv6Pod.Labels[IPv6AddressRequestLabelKey] = "true"
+	ipv6PodRaw, err := json.Marshal(ipv6Pod)
+	assert.NoError(t, err)
+
+	// Windows Pod requesting an IPv6 Address
+	windowsIPv6Pod := windowsPod.DeepCopy()
+	windowsIPv6Pod.Labels[IPv6AddressRequestLabelKey] = "true"
+	windowsIPv6PodRaw, err := json.Marshal(windowsIPv6Pod)
+	assert.NoError(t, err)
+
 	test := []struct {
 		name           string
+		enableIPv6     bool
 		mockInvocation func(mock Mock)
 		req            admission.Request
 		want           admission.Response
 	}{
+		{
+			name:       "[Linux] Pod requests IPv6 address",
+			enableIPv6: true,
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Object: runtime.RawExtension{
+						Raw:    ipv6PodRaw,
+						Object: ipv6Pod,
+					},
+				},
+			},
+			mockInvocation: func(mock Mock) {
+				mock.SGPMock.EXPECT().GetMatchingSecurityGroupForPods(gomock.AssignableToTypeOf(ipv6Pod)).Return([]string{}, nil)
+			},
+
+			want: admission.Response{
+				Patches: []jsonpatch.JsonPatchOperation{
+					{
+						Operation: "add",
+						Path:      firstContainerPatchRequestURI + ipv6ResourceJsonPointer,
+						Value:     "1",
+					},
+					{
+						Operation: "add",
+						Path:      firstContainerPatchLimitURI + ipv6ResourceJsonPointer,
+						Value:     "1",
+					},
+				},
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed:   true,
+					PatchType: &jsonPatchType,
+				},
+			},
+		},
+		{
+			name:       "[Linux] Pod requests IPv6 address and matches SG",
+			enableIPv6: true,
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Object: runtime.RawExtension{
+						Raw:    ipv6PodRaw,
+						Object: ipv6Pod,
+					},
+				},
+			},
+			mockInvocation: func(mock Mock) {
+				mock.SGPMock.EXPECT().GetMatchingSecurityGroupForPods(gomock.AssignableToTypeOf(ipv6Pod)).Return(sgList, nil)
+			},
+
+			want: admission.Response{
+				Patches: []jsonpatch.JsonPatchOperation{
+					{
+						Operation: "add",
+						Path:      firstContainerPatchRequestURI + podENIResourceJsonPointer,
+						Value:     "1",
+					},
+					{
+						Operation: "add",
+						Path:      firstContainerPatchLimitURI + podENIResourceJsonPointer,
+						Value:     "1",
+					},
+				},
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed:   true,
+					PatchType: &jsonPatchType,
+				},
+			},
+		},
+		{
+			name: "[Linux] Pod requests IPv6 address when IPv6 prefix delegation is disabled",
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Object: runtime.RawExtension{
+						Raw:    ipv6PodRaw,
+						Object: ipv6Pod,
+					},
+				},
+			},
+			mockInvocation: func(mock Mock) {
+				mock.SGPMock.EXPECT().GetMatchingSecurityGroupForPods(gomock.AssignableToTypeOf(ipv6Pod)).Return([]string{}, nil)
+			},
+
+			want: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: true,
+				},
+			},
+		},
+		{
+			name:       "[Windows] Pod requests IPv6 address",
+			enableIPv6: true,
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Object: runtime.RawExtension{
+						Raw:    windowsIPv6PodRaw,
+						Object: windowsIPv6Pod,
+					},
+				},
+			},
+			mockInvocation: func(mock Mock) {
+				mock.ConditionMock.EXPECT().IsWindowsIPAMEnabled().Return(true)
+				mock.ConditionMock.EXPECT().IsWindowsSecurityGroupsForPodsEnabled().Return(false)
+			},
+
+			want: admission.Response{
+				Patches: []jsonpatch.JsonPatchOperation{
+					{
+						Operation: "add",
+						Path:      firstContainerPatchRequestURI + ipResourceJsonPointer,
+						Value:     "1",
+					},
+					{
+						Operation: "add",
+						Path:      firstContainerPatchLimitURI + ipResourceJsonPointer,
+						Value:     "1",
+					},
+					{
+						Operation: "add",
+						Path:      firstContainerPatchRequestURI + ipv6ResourceJsonPointer,
+						Value:     "1",
+					},
+					{
+						Operation: "add",
+						Path:      firstContainerPatchLimitURI + ipv6ResourceJsonPointer,
+						Value:     "1",
+					},
+				},
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed:   true,
+					PatchType: &jsonPatchType,
+				},
+			},
+		},
 		{
 			name: "[Linux] Pod matches SG no existing resource limits",
 			req: admission.Request{
@@ -253,6 +403,27 @@ func TestPodMutationWebHook_Handle(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "[Linux] resolved security groups exceed the limit",
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Object: runtime.RawExtension{
+						Raw:    sgpPodRaw,
+						Object: sgpPod,
+					},
+				},
+			},
+			mockInvocation: func(mock Mock) {
+				mock.SGPMock.EXPECT().GetMatchingSecurityGroupForPods(gomock.AssignableToTypeOf(sgpPod)).Return(nil, limitErr)
+			},
+
+			want: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: false,
+					Result:  &metav1.Status{Message: limitErr.Error()},
+				},
+			},
+		},
 		{
 			name: "[Fargate] not matching any SG",
 			req: admission.Request{
@@ -376,6 +547,27 @@ func TestPodMutationWebHook_Handle(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "[Fargate] resolved security groups exceed the limit",
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Object: runtime.RawExtension{
+						Raw:    fargatePodRaw,
+						Object: fargatePod,
+					},
+				},
+			},
+			mockInvocation: func(mock Mock) {
+				mock.SGPMock.EXPECT().GetMatchingSecurityGroupForPods(gomock.AssignableToTypeOf(fargatePod)).Return(nil, limitErr)
+			},
+
+			want: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: false,
+					Result:  &metav1.Status{Message: limitErr.Error()},
+				},
+			},
+		},
 		{
 			name: "[HostNetwork] should be allowed",
 			req: admission.Request{
@@ -422,6 +614,7 @@ func TestPodMutationWebHook_Handle(t *testing.T) {
 			},
 			mockInvocation: func(mock Mock) {
 				mock.ConditionMock.EXPECT().IsWindowsIPAMEnabled().Return(true)
+				mock.ConditionMock.EXPECT().IsWindowsSecurityGroupsForPodsEnabled().Return(false)
 			},
 		},
 		{
@@ -454,6 +647,7 @@ func TestPodMutationWebHook_Handle(t *testing.T) {
 			},
 			mockInvocation: func(mock Mock) {
 				mock.ConditionMock.EXPECT().IsWindowsIPAMEnabled().Return(true)
+				mock.ConditionMock.EXPECT().IsWindowsSecurityGroupsForPodsEnabled().Return(false)
 			},
 		},
 		{
@@ -486,6 +680,97 @@ func TestPodMutationWebHook_Handle(t *testing.T) {
 			},
 			mockInvocation: func(mock Mock) {
 				mock.ConditionMock.EXPECT().IsWindowsIPAMEnabled().Return(true)
+				mock.ConditionMock.EXPECT().IsWindowsSecurityGroupsForPodsEnabled().Return(false)
+			},
+		},
+		{
+			name: "[Windows] with security groups for pods enabled and matching SGP, should inject pod-eni",
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Object: runtime.RawExtension{
+						Raw:    windowsPodRaw,
+						Object: windowsPod,
+					},
+				},
+			},
+			want: admission.Response{
+				Patches: []jsonpatch.JsonPatchOperation{
+					{
+						Operation: "add",
+						Path:      firstContainerPatchLimitURI + podENIResourceJsonPointer,
+						Value:     "1",
+					},
+					{
+						Operation: "add",
+						Path:      firstContainerPatchRequestURI + podENIResourceJsonPointer,
+						Value:     "1",
+					},
+				},
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed:   true,
+					PatchType: &jsonPatchType,
+				},
+			},
+			mockInvocation: func(mock Mock) {
+				mock.ConditionMock.EXPECT().IsWindowsIPAMEnabled().Return(true)
+				mock.ConditionMock.EXPECT().IsWindowsSecurityGroupsForPodsEnabled().Return(true)
+				mock.SGPMock.EXPECT().GetMatchingSecurityGroupForPods(gomock.AssignableToTypeOf(windowsPod)).Return(sgList, nil)
+			},
+		},
+		{
+			name: "[Windows] with security groups for pods enabled and not matching any SGP, should inject IPv4 address",
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Object: runtime.RawExtension{
+						Raw:    windowsPodRaw,
+						Object: windowsPod,
+					},
+				},
+			},
+			want: admission.Response{
+				Patches: []jsonpatch.JsonPatchOperation{
+					{
+						Operation: "add",
+						Path:      firstContainerPatchLimitURI + ipResourceJsonPointer,
+						Value:     "1",
+					},
+					{
+						Operation: "add",
+						Path:      firstContainerPatchRequestURI + ipResourceJsonPointer,
+						Value:     "1",
+					},
+				},
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed:   true,
+					PatchType: &jsonPatchType,
+				},
+			},
+			mockInvocation: func(mock Mock) {
+				mock.ConditionMock.EXPECT().IsWindowsIPAMEnabled().Return(true)
+				mock.ConditionMock.EXPECT().IsWindowsSecurityGroupsForPodsEnabled().Return(true)
+				mock.SGPMock.EXPECT().GetMatchingSecurityGroupForPods(gomock.AssignableToTypeOf(windowsPod)).Return([]string{}, nil)
+			},
+		},
+		{
+			name: "[Windows] with security groups for pods enabled and resolved security groups exceed the limit",
+			req: admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Object: runtime.RawExtension{
+						Raw:    windowsPodRaw,
+						Object: windowsPod,
+					},
+				},
+			},
+			want: admission.Response{
+				AdmissionResponse: admissionv1.AdmissionResponse{
+					Allowed: false,
+					Result:  &metav1.Status{Message: limitErr.Error()},
+				},
+			},
+			mockInvocation: func(mock Mock) {
+				mock.ConditionMock.EXPECT().IsWindowsIPAMEnabled().Return(true)
+				mock.ConditionMock.EXPECT().IsWindowsSecurityGroupsForPodsEnabled().Return(true)
+				mock.SGPMock.EXPECT().GetMatchingSecurityGroupForPods(gomock.AssignableToTypeOf(windowsPod)).Return(nil, limitErr)
 			},
 		},
 		{
@@ -520,10 +805,11 @@ func TestPodMutationWebHook_Handle(t *testing.T) {
 				ConditionMock: mock_condition.NewMockConditions(ctrl),
 			}
 			h := &PodMutationWebHook{
-				decoder:   decoder,
-				Log:       zap.New(),
-				SGPAPI:    mock.SGPMock,
-				Condition: mock.ConditionMock,
+				decoder:                    decoder,
+				Log:                        zap.New(),
+				SGPAPI:                     mock.SGPMock,
+				Condition:                  mock.ConditionMock,
+				EnableIPv6PrefixDelegation: tt.enableIPv6,
 			}
 
 			if tt.mockInvocation != nil {
@@ -535,6 +821,9 @@ func TestPodMutationWebHook_Handle(t *testing.T) {
 			assert.Equal(t, tt.want.Allowed, got.Allowed)
 			assert.ElementsMatch(t, tt.want.Patches, got.Patches)
 			assert.Equal(t, tt.want.PatchType, got.PatchType)
+			if tt.want.Result != nil {
+				assert.Equal(t, tt.want.Result.Message, got.Result.Message)
+			}
 		})
 	}
 }